@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Roles a token can hold, each allowed everything the ones before it are
+const (
+	RoleViewer   = "viewer"   // read node lists and system status
+	RoleOperator = "operator" // approve, reject and remove nodes
+	RoleAdmin    = "admin"    // run system updates, publish snapshots and manage tokens
+)
+
+// roleRank orders roles by privilege; unknown roles rank zero and are
+// allowed nothing
+var roleRank = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// tokenPrefix marks admin tokens so they are recognizable in configs
+const tokenPrefix = "gydsadm_"
+
+var errLastAdmin = errors.New("cannot revoke the last admin token")
+
+// tokenContextKey carries the authenticated token in a request context
+type tokenContextKey struct{}
+
+// APIToken is an issued admin API token. Only the SHA-256 of the secret is
+// stored; the secret itself is shown once, when the token is created.
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TokenRegistry holds the issued admin API tokens
+type TokenRegistry struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+// hashToken returns the hex SHA-256 a token is stored as. Tokens are 32
+// random bytes, so a fast hash is enough to make a leaked file useless.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAPIToken creates a token with a fresh secret, returned alongside it
+func newAPIToken(name, role string) (APIToken, string, error) {
+	if _, ok := roleRank[role]; !ok {
+		return APIToken{}, "", fmt.Errorf("unknown role %q", role)
+	}
+	buf := make([]byte, 40)
+	if _, err := rand.Read(buf); err != nil {
+		return APIToken{}, "", err
+	}
+	secret := tokenPrefix + hex.EncodeToString(buf[8:])
+	return APIToken{
+		ID:        hex.EncodeToString(buf[:8]),
+		Name:      name,
+		Role:      role,
+		Hash:      hashToken(secret),
+		CreatedAt: time.Now(),
+	}, secret, nil
+}
+
+// loadTokens reads the token file, creating it with one admin token when
+// it does not exist yet. The new token's secret is printed once so the
+// operator can store it.
+func (s *AdminServer) loadTokens() error {
+	data, err := ioutil.ReadFile(s.tokenFile)
+	if os.IsNotExist(err) {
+		token, secret, err := newAPIToken("initial", RoleAdmin)
+		if err != nil {
+			return err
+		}
+		s.tokens = &TokenRegistry{Tokens: []APIToken{token}}
+		if err := s.saveTokens(); err != nil {
+			return err
+		}
+		fmt.Printf("🔑 Initial admin API token (shown once, store it now): %s\n", secret)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.tokens = &TokenRegistry{}
+	return json.Unmarshal(data, s.tokens)
+}
+
+func (s *AdminServer) saveTokens() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.tokens, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.tokenFile, data, 0600)
+}
+
+// authenticate returns the token presented as a bearer token, if valid
+func (s *AdminServer) authenticate(r *http.Request) (*APIToken, bool) {
+	secret := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if secret == "" || !strings.HasPrefix(secret, tokenPrefix) {
+		return nil, false
+	}
+	hash := []byte(hashToken(secret))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.tokens.Tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(s.tokens.Tokens[i].Hash)) == 1 {
+			token := s.tokens.Tokens[i]
+			return &token, true
+		}
+	}
+	return nil, false
+}
+
+// require wraps a handler so it only runs for tokens holding at least role.
+// Requests that change state are logged with the token that made them.
+func (s *AdminServer) require(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gydschain-admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if roleRank[token.Role] < roleRank[role] {
+			log.Printf("Denied %s %s to token %s (%s): requires %s", r.Method, r.URL.Path, token.Name, token.Role, role)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet {
+			log.Printf("%s %s by token %s (%s)", r.Method, r.URL.Path, token.Name, token.Role)
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token)))
+	}
+}
+
+// hasRole reports whether the request's token holds at least role, for
+// routes whose methods need different roles
+func hasRole(r *http.Request, role string) bool {
+	token, ok := r.Context().Value(tokenContextKey{}).(*APIToken)
+	return ok && roleRank[token.Role] >= roleRank[role]
+}
+
+// actor names who made a request in audit records: the token's name, or
+// "node" for the open routes nodes call themselves
+func actor(r *http.Request) string {
+	if token, ok := r.Context().Value(tokenContextKey{}).(*APIToken); ok {
+		return token.Name
+	}
+	return "node"
+}
+
+// List issued tokens or create one; the new secret is only in this response
+func (s *AdminServer) handleTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		tokens := make([]APIToken, len(s.tokens.Tokens))
+		copy(tokens, s.tokens.Tokens)
+		s.mu.RUnlock()
+		for i := range tokens {
+			tokens[i].Hash = ""
+		}
+		json.NewEncoder(w).Encode(tokens)
+
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" {
+			http.Error(w, "name required", http.StatusBadRequest)
+			return
+		}
+		token, secret, err := newAPIToken(req.Name, req.Role)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		s.tokens.Tokens = append(s.tokens.Tokens, token)
+		s.mu.Unlock()
+		if err := s.saveTokens(); err != nil {
+			http.Error(w, "Failed to save token", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("API token created: %s (%s, %s)", token.ID, token.Name, token.Role)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status": "success",
+			"id":     token.ID,
+			"role":   token.Role,
+			"token":  secret,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Revoke a token by ID
+func (s *AdminServer) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[len("/tokens/"):]
+	if id == "" {
+		http.Error(w, "Token ID required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	err := s.revokeToken(id)
+	s.mu.Unlock()
+	if err != nil {
+		status := http.StatusNotFound
+		if err == errLastAdmin {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if err := s.saveTokens(); err != nil {
+		http.Error(w, "Failed to save tokens", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("API token revoked: %s", id)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Token revoked",
+	})
+}
+
+// revokeToken removes a token, keeping at least one admin token so the
+// server stays manageable; callers must hold s.mu
+func (s *AdminServer) revokeToken(id string) error {
+	found, admins := -1, 0
+	for i, token := range s.tokens.Tokens {
+		if token.ID == id {
+			found = i
+		}
+		if token.Role == RoleAdmin {
+			admins++
+		}
+	}
+	if found < 0 {
+		return errors.New("token not found")
+	}
+	if s.tokens.Tokens[found].Role == RoleAdmin && admins == 1 {
+		return errLastAdmin
+	}
+	s.tokens.Tokens = append(s.tokens.Tokens[:found], s.tokens.Tokens[found+1:]...)
+	return nil
+}
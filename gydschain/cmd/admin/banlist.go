@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reported bans older than this are ignored unless they set a shorter expiry
+const maxBanReportAge = 7 * 24 * time.Hour
+
+// BanReport is a peer ban one approved node reported
+type BanReport struct {
+	NodeID     string    `json:"node_id"`
+	Address    string    `json:"address"`
+	PeerID     string    `json:"peer_id,omitempty"`
+	Reason     string    `json:"reason"`
+	ReportedAt time.Time `json:"reported_at"`
+	Expires    time.Time `json:"expires"`
+}
+
+// GreylistEntry is an address refused network-wide, either because enough
+// nodes banned it or because an operator added it
+type GreylistEntry struct {
+	Address   string    `json:"address"`
+	Reason    string    `json:"reason"`
+	Reasons   []string  `json:"reasons"`
+	Reporters []string  `json:"reporters"`
+	BannedAt  time.Time `json:"banned_at"`
+	Expires   time.Time `json:"expires"`
+	Manual    bool      `json:"manual,omitempty"`
+}
+
+// BanRegistry holds reported bans and operator greylist entries
+type BanRegistry struct {
+	Reports []BanReport     `json:"reports"`
+	Manual  []GreylistEntry `json:"manual"`
+}
+
+func (s *AdminServer) loadBans() error {
+	data, err := ioutil.ReadFile(s.banFile)
+	if err != nil {
+		return err
+	}
+
+	s.bans = &BanRegistry{}
+	return json.Unmarshal(data, s.bans)
+}
+
+func (s *AdminServer) saveBans() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.bans, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.banFile, data, 0644)
+}
+
+// pruneBans drops expired reports and manual entries; callers must hold s.mu
+func (s *AdminServer) pruneBans(now time.Time) {
+	reports := s.bans.Reports[:0]
+	for _, report := range s.bans.Reports {
+		if now.Before(report.Expires) {
+			reports = append(reports, report)
+		}
+	}
+	s.bans.Reports = reports
+
+	manual := s.bans.Manual[:0]
+	for _, entry := range s.bans.Manual {
+		if entry.Expires.IsZero() || now.Before(entry.Expires) {
+			manual = append(manual, entry)
+		}
+	}
+	s.bans.Manual = manual
+}
+
+// greylist aggregates active reports into entries reported by at least
+// greylistThreshold distinct nodes, plus manual entries; callers must hold s.mu
+func (s *AdminServer) greylist(now time.Time) []GreylistEntry {
+	entries := make(map[string]*GreylistEntry)
+	for _, entry := range s.bans.Manual {
+		if !entry.Expires.IsZero() && !now.Before(entry.Expires) {
+			continue
+		}
+		e := entry
+		e.Reasons = append([]string{}, entry.Reasons...)
+		entries[e.Address] = &e
+	}
+
+	byAddress := make(map[string][]BanReport)
+	for _, report := range s.bans.Reports {
+		if now.Before(report.Expires) {
+			byAddress[report.Address] = append(byAddress[report.Address], report)
+		}
+	}
+
+	for addr, reports := range byAddress {
+		reporters := make(map[string]bool)
+		reasons := make(map[string]bool)
+		for _, report := range reports {
+			reporters[report.NodeID] = true
+			reasons[report.Reason] = true
+		}
+		if len(reporters) < s.greylistThreshold {
+			continue
+		}
+
+		entry, exists := entries[addr]
+		if !exists {
+			entry = &GreylistEntry{Address: addr, BannedAt: reports[0].ReportedAt}
+			entries[addr] = entry
+		}
+		for _, report := range reports {
+			if report.ReportedAt.Before(entry.BannedAt) {
+				entry.BannedAt = report.ReportedAt
+			}
+			if !entry.Manual && report.Expires.After(entry.Expires) {
+				entry.Expires = report.Expires
+			}
+		}
+		for id := range reporters {
+			entry.Reporters = append(entry.Reporters, id)
+		}
+		for reason := range reasons {
+			entry.Reasons = append(entry.Reasons, reason)
+		}
+		sort.Strings(entry.Reporters)
+		sort.Strings(entry.Reasons)
+		if entry.Reason == "" {
+			entry.Reason = strings.Join(entry.Reasons, "; ")
+		}
+	}
+
+	list := make([]GreylistEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, *entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Address < list[j].Address })
+	return list
+}
+
+// Accept ban reports from approved nodes
+func (s *AdminServer) handleReportBans(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		json.NewEncoder(w).Encode(s.bans.Reports)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		NodeID string `json:"node_id"`
+		Bans   []struct {
+			Address  string    `json:"address"`
+			PeerID   string    `json:"peer_id"`
+			Reason   string    `json:"reason"`
+			BannedAt time.Time `json:"banned_at"`
+			Expires  time.Time `json:"expires"`
+		} `json:"bans"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if !s.registry.IsApproved(req.NodeID) {
+		s.mu.Unlock()
+		http.Error(w, "Only approved nodes may report bans", http.StatusForbidden)
+		return
+	}
+
+	accepted := 0
+	for _, ban := range req.Bans {
+		addr := banHost(ban.Address)
+		if addr == "" {
+			continue
+		}
+		expires := ban.Expires
+		if expires.IsZero() || expires.After(now.Add(maxBanReportAge)) {
+			expires = now.Add(maxBanReportAge)
+		}
+
+		// One live report per node and address; a newer report replaces it
+		reports := s.bans.Reports[:0]
+		for _, report := range s.bans.Reports {
+			if report.NodeID != req.NodeID || report.Address != addr {
+				reports = append(reports, report)
+			}
+		}
+		s.bans.Reports = append(reports, BanReport{
+			NodeID:     req.NodeID,
+			Address:    addr,
+			PeerID:     ban.PeerID,
+			Reason:     ban.Reason,
+			ReportedAt: now,
+			Expires:    expires,
+		})
+		accepted++
+	}
+	s.pruneBans(now)
+	s.mu.Unlock()
+
+	s.saveBans()
+
+	if accepted > 0 {
+		log.Printf("Node %s reported %d peer bans", shortID(req.NodeID), accepted)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"accepted": accepted,
+	})
+}
+
+// Serve the aggregated greylist, or add a manual entry to it
+func (s *AdminServer) handleGreylist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		list := s.greylist(time.Now())
+		s.mu.RUnlock()
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var entry GreylistEntry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		entry.Address = banHost(entry.Address)
+		if entry.Address == "" || entry.Reason == "" {
+			http.Error(w, "address and reason required", http.StatusBadRequest)
+			return
+		}
+		entry.Manual = true
+		entry.BannedAt = time.Now()
+		entry.Reasons = []string{entry.Reason}
+		entry.Reporters = []string{}
+
+		s.mu.Lock()
+		manual := s.bans.Manual[:0]
+		for _, existing := range s.bans.Manual {
+			if existing.Address != entry.Address {
+				manual = append(manual, existing)
+			}
+		}
+		s.bans.Manual = append(manual, entry)
+		s.mu.Unlock()
+
+		s.saveBans()
+		log.Printf("Greylisted %s: %s", entry.Address, entry.Reason)
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Address greylisted",
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Clear an address from the greylist, dropping its reports and manual entry
+func (s *AdminServer) handleClearGreylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	addr := banHost(r.URL.Path[len("/peers/greylist/"):])
+	if addr == "" {
+		http.Error(w, "Address required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	cleared := 0
+	reports := s.bans.Reports[:0]
+	for _, report := range s.bans.Reports {
+		if report.Address == addr {
+			cleared++
+		} else {
+			reports = append(reports, report)
+		}
+	}
+	s.bans.Reports = reports
+
+	manual := s.bans.Manual[:0]
+	for _, entry := range s.bans.Manual {
+		if entry.Address == addr {
+			cleared++
+		} else {
+			manual = append(manual, entry)
+		}
+	}
+	s.bans.Manual = manual
+	s.mu.Unlock()
+
+	if cleared == 0 {
+		http.Error(w, "Address not found", http.StatusNotFound)
+		return
+	}
+
+	s.saveBans()
+	log.Printf("Cleared %s from greylist", addr)
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Address cleared from greylist",
+	})
+}
+
+// banHost normalizes a reported address to its host; bans cover all ports
+func banHost(address string) string {
+	address = strings.TrimSpace(address)
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
+}
+
+func shortID(id string) string {
+	if len(id) > 16 {
+		return id[:16]
+	}
+	return id
+}
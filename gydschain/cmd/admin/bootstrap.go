@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// BootstrapList is the current set of peers lite nodes should sync from
+type BootstrapList struct {
+	IssuedAt int64               `json:"issued_at"`
+	Nodes    []map[string]string `json:"nodes"`
+}
+
+// SignedBootstrapList carries a bootstrap list together with an ed25519
+// signature over the exact payload bytes, so clients verify before parsing
+type SignedBootstrapList struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+}
+
+// loadSigningKey reads the hex-encoded ed25519 seed at path, generating and
+// saving a new one if the file does not exist
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key in %s", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// signingPublicKey returns the hex public key lite nodes pin
+func (s *AdminServer) signingPublicKey() string {
+	return hex.EncodeToString(s.signingKey.Public().(ed25519.PublicKey))
+}
+
+// Serve the current bootstrap set signed with the admin key
+func (s *AdminServer) handleBootstrap(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	list := BootstrapList{
+		IssuedAt: time.Now().Unix(),
+		Nodes:    s.getBootstrapNodes(),
+	}
+	s.mu.RUnlock()
+
+	payload, err := json.Marshal(list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(SignedBootstrapList{
+		Payload:   payload,
+		Signature: hex.EncodeToString(ed25519.Sign(s.signingKey, payload)),
+	})
+}
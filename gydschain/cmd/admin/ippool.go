@@ -0,0 +1,217 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// DefaultVPNSubnet is the WireGuard network nodes are addressed in
+const DefaultVPNSubnet = "10.100.0.0/24"
+
+var errVPNExhausted = errors.New("VPN address pool exhausted")
+
+// ipPoolSchema tracks every address the pool ever handed out. A row with
+// released_at set is free again; allocating it reuses the row.
+const ipPoolSchema = `
+CREATE TABLE IF NOT EXISTS vpn_addresses (
+    address TEXT PRIMARY KEY,
+    node INTEGER REFERENCES nodes (id),
+    allocated_at DATETIME NOT NULL,
+    released_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_vpn_addresses_node ON vpn_addresses (node);
+`
+
+// IPPool hands out VPN addresses from a subnet. The first host address is
+// the WireGuard server's; nodes get the lowest free address after it, so
+// addresses released by removed nodes are reused first.
+type IPPool struct {
+	subnet *net.IPNet
+	prefix int
+	first  uint32 // lowest node address
+	last   uint32 // highest node address
+}
+
+// PoolStats reports how much of the pool is in use
+type PoolStats struct {
+	Subnet    string `json:"subnet"`
+	Server    string `json:"server"`
+	Size      int    `json:"size"`
+	Allocated int    `json:"allocated"`
+}
+
+// NewIPPool creates a pool for an IPv4 subnet in CIDR notation
+func NewIPPool(cidr string) (*IPPool, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	prefix, bits := subnet.Mask.Size()
+	if bits != 32 {
+		return nil, fmt.Errorf("VPN subnet %s is not IPv4", cidr)
+	}
+	if prefix > 29 {
+		return nil, fmt.Errorf("VPN subnet %s is too small", cidr)
+	}
+
+	network := binary.BigEndian.Uint32(subnet.IP.To4())
+	broadcast := network | ^binary.BigEndian.Uint32(net.IP(subnet.Mask).To4())
+	return &IPPool{
+		subnet: subnet,
+		prefix: prefix,
+		first:  network + 2, // network + 1 is the server
+		last:   broadcast - 1,
+	}, nil
+}
+
+// Subnet returns the pool's subnet in CIDR notation
+func (p *IPPool) Subnet() string {
+	return p.subnet.String()
+}
+
+// ServerAddress returns the WireGuard server's address in the subnet
+func (p *IPPool) ServerAddress() string {
+	return uint32ToIP(p.first - 1).String()
+}
+
+// Size returns the number of addresses available to nodes
+func (p *IPPool) Size() int {
+	return int(p.last - p.first + 1)
+}
+
+// Allocate gives the node with row ID node the lowest free address and
+// returns it with the subnet's prefix length, as written to wg0.conf
+func (p *IPPool) Allocate(tx *sql.Tx, node int64) (string, error) {
+	rows, err := tx.Query("SELECT address FROM vpn_addresses WHERE released_at IS NULL")
+	if err != nil {
+		return "", err
+	}
+	used := make(map[uint32]bool)
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			rows.Close()
+			return "", err
+		}
+		if ip := net.ParseIP(address).To4(); ip != nil {
+			used[binary.BigEndian.Uint32(ip)] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	for n := p.first; n <= p.last; n++ {
+		if used[n] {
+			continue
+		}
+		address := uint32ToIP(n).String()
+		_, err := tx.Exec(`
+			INSERT INTO vpn_addresses (address, node, allocated_at, released_at)
+			VALUES (?, ?, ?, NULL)
+			ON CONFLICT (address) DO UPDATE SET
+				node = excluded.node,
+				allocated_at = excluded.allocated_at,
+				released_at = NULL
+		`, address, node, time.Now())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s/%d", address, p.prefix), nil
+	}
+	return "", errVPNExhausted
+}
+
+// Release frees the addresses held by the node with row ID node
+func (p *IPPool) Release(tx *sql.Tx, node int64) error {
+	_, err := tx.Exec(
+		"UPDATE vpn_addresses SET released_at = ? WHERE node = ? AND released_at IS NULL",
+		time.Now(), node,
+	)
+	return err
+}
+
+// adopt records the addresses of approved nodes that predate the pool
+func (p *IPPool) adopt(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT n.id, n.vpn_address, COALESCE(n.approved_at, n.registered_at) FROM nodes n
+		WHERE n.status = ? AND n.vpn_address IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM vpn_addresses a WHERE a.node = n.id AND a.released_at IS NULL)
+	`, StatusApproved)
+	if err != nil {
+		return err
+	}
+	type held struct {
+		node    int64
+		address string
+		since   time.Time
+	}
+	var missing []held
+	for rows.Next() {
+		var h held
+		if err := rows.Scan(&h.node, &h.address, &h.since); err != nil {
+			rows.Close()
+			return err
+		}
+		missing = append(missing, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, h := range missing {
+		_, err := db.Exec(`
+			INSERT INTO vpn_addresses (address, node, allocated_at) VALUES (?, ?, ?)
+			ON CONFLICT (address) DO UPDATE SET
+				node = excluded.node,
+				allocated_at = excluded.allocated_at,
+				released_at = NULL
+		`, stripPrefix(h.address), h.node, h.since)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats reports the pool's size and current allocations within it
+func (p *IPPool) Stats(db *sql.DB) (PoolStats, error) {
+	stats := PoolStats{Subnet: p.Subnet(), Server: p.ServerAddress(), Size: p.Size()}
+
+	rows, err := db.Query("SELECT address FROM vpn_addresses WHERE released_at IS NULL")
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			return stats, err
+		}
+		if ip := net.ParseIP(address); ip != nil && p.subnet.Contains(ip) {
+			stats.Allocated++
+		}
+	}
+	return stats, rows.Err()
+}
+
+// stripPrefix returns an address without its /prefix suffix
+func stripPrefix(address string) string {
+	if i := strings.IndexByte(address, '/'); i >= 0 {
+		return address[:i]
+	}
+	return address
+}
+
+func uint32ToIP(n uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip
+}
@@ -1,32 +1,47 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/rpc"
 )
 
 // AdminServer manages node registrations and VPN configuration
 type AdminServer struct {
-	mu           sync.RWMutex
-	port         int
-	registryFile string
-	vpnConfigDir string
-	registry     *NodeRegistry
-}
-
-// NodeRegistry tracks all registered nodes
-type NodeRegistry struct {
-	Pending  []NodeInfo `json:"pending"`
-	Approved []NodeInfo `json:"approved"`
-	Rejected []NodeInfo `json:"rejected"`
+	mu                sync.RWMutex
+	vpnMu             sync.Mutex // serializes wg0.conf rewrites
+	port              int
+	registryFile      string
+	dbFile            string
+	vpnConfigDir      string
+	snapshotDir       string
+	publicURL         string
+	banFile           string
+	tokenFile         string
+	greylistThreshold int // distinct reporting nodes needed to greylist an address
+	registry          *NodeStore
+	vpnPool           *IPPool
+	snapshots         *SnapshotCatalog
+	bans              *BanRegistry
+	tokens            *TokenRegistry
+	signingKey        ed25519.PrivateKey // signs bootstrap lists served to lite nodes
+	nodeRPC           *rpc.NodeClient    // node validators are registered with
+	minStake          *big.Int           // GYDS a validator's operator must hold
+	notifier          *Notifier
+	updates           *UpdateManager
 }
 
 // NodeInfo represents a registered node
@@ -42,68 +57,165 @@ type NodeInfo struct {
 	VPNAddress       string    `json:"vpn_address,omitempty"`
 	LastSeen         time.Time `json:"last_seen,omitempty"`
 	SyncHeight       uint64    `json:"sync_height,omitempty"`
+	Rotations        []IdentityRotation `json:"rotations,omitempty"` // identities rotated away from, oldest first
+
+	// Validator nodes name the address holding their stake and their
+	// consensus key, and send the operator's signed stake transaction that
+	// registers the key on chain; the registry keeps the key's fingerprint
+	OperatorAddress         string `json:"operator_address,omitempty"`
+	ValidatorPubKey         string `json:"validator_pub_key,omitempty"`
+	ValidatorKeyFingerprint string `json:"validator_key_fingerprint,omitempty"`
+	RegistrationTx          string `json:"registration_tx,omitempty"`
+
+	Profile *NodeProfile `json:"profile,omitempty"` // set when the node is approved
 }
 
 func main() {
 	port := flag.Int("port", 9000, "Admin API port")
-	registryFile := flag.String("registry", "/opt/gydschain/config/node_registry.json", "Node registry file")
+	registryFile := flag.String("registry", "/opt/gydschain/config/node_registry.json", "Legacy JSON node registry, imported into the database on first start")
+	dbFile := flag.String("db", "/opt/gydschain/config/admin.db", "Node registry database")
 	vpnConfigDir := flag.String("vpn-dir", "/etc/wireguard", "WireGuard config directory")
+	vpnSubnet := flag.String("vpn-subnet", DefaultVPNSubnet, "VPN subnet; its first host is the server, the rest are handed to approved nodes")
+	snapshotDir := flag.String("snapshot-dir", "/opt/gydschain/snapshots", "Chain snapshot directory")
+	publicURL := flag.String("public-url", "", "Public base URL of this admin API (used in snapshot links)")
+	banFile := flag.String("banlist", "/opt/gydschain/config/banlist.json", "Reported peer bans and greylist file")
+	greylistThreshold := flag.Int("greylist-threshold", 2, "Distinct nodes that must ban an address before it is greylisted")
+	tokenFile := flag.String("tokens", "/opt/gydschain/config/admin_tokens.json", "Hashed admin API tokens (created with an initial admin token if missing)")
+	signingKeyFile := flag.String("signing-key", "/opt/gydschain/config/admin_signing.key", "ed25519 key signing bootstrap lists (generated if missing)")
+	nodeRPC := flag.String("node-rpc", "", "RPC URL of a node to verify stakes and register approved validators with")
+	nodeRPCToken := flag.String("node-rpc-token", "", "Admin API key or JWT for the node RPC")
+	minStake := flag.String("min-stake", DefaultMinStake, "Minimum GYDS, in base units, a validator's operator address must hold")
+	notifyFile := flag.String("notify", "/opt/gydschain/config/notifications.json", "Webhook and email notification targets (none if missing)")
+	nodeRPCPort := flag.Int("node-rpc-port", 8545, "RPC port approved nodes answer health checks on over the VPN")
+	healthInterval := flag.Duration("health-interval", time.Minute, "How often approved nodes are health checked")
+	offlineAfter := flag.Duration("offline-after", 5*time.Minute, "How long a node may not answer before it is reported offline")
+	installDir := flag.String("install-dir", "/opt/gydschain", "Install whose bin directory updates replace")
+	releaseURL := flag.String("release-url", "", "Signed release manifest URL updates install from by default")
+	releaseKey := flag.String("release-key", "", "Hex ed25519 public key release manifests must be signed with")
 	flag.Parse()
 
 	server := &AdminServer{
-		port:         *port,
-		registryFile: *registryFile,
-		vpnConfigDir: *vpnConfigDir,
-	}
-
-	// Load existing registry
-	if err := server.loadRegistry(); err != nil {
-		log.Printf("Creating new registry: %v", err)
-		server.registry = &NodeRegistry{
-			Pending:  []NodeInfo{},
-			Approved: []NodeInfo{},
-			Rejected: []NodeInfo{},
-		}
-		server.saveRegistry()
+		port:              *port,
+		registryFile:      *registryFile,
+		dbFile:            *dbFile,
+		vpnConfigDir:      *vpnConfigDir,
+		snapshotDir:       *snapshotDir,
+		publicURL:         strings.TrimSuffix(*publicURL, "/"),
+		banFile:           *banFile,
+		tokenFile:         *tokenFile,
+		greylistThreshold: *greylistThreshold,
 	}
 
-	// Setup routes
-	http.HandleFunc("/nodes/register", server.handleRegister)
-	http.HandleFunc("/nodes/pending", server.handleGetPending)
-	http.HandleFunc("/nodes/approved", server.handleGetApproved)
-	http.HandleFunc("/nodes/approve/", server.handleApprove)
-	http.HandleFunc("/nodes/reject/", server.handleReject)
-	http.HandleFunc("/nodes/remove/", server.handleRemove)
-	http.HandleFunc("/nodes/", server.handleGetNodeConfig)
-	http.HandleFunc("/system/update", server.handleSystemUpdate)
-	http.HandleFunc("/system/rebuild", server.handleRebuildFrontend)
-	http.HandleFunc("/system/status", server.handleSystemStatus)
-	http.HandleFunc("/health", server.handleHealth)
-
-	fmt.Printf("🔧 Admin API Server starting on port %d\n", *port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *port), nil))
-}
+	stake, err := parseMinStake(*minStake)
+	if err != nil {
+		log.Fatalf("Invalid -min-stake: %v", err)
+	}
+	server.minStake = stake
+	if *nodeRPC != "" {
+		server.nodeRPC = rpc.NewNodeClient(*nodeRPC)
+		server.nodeRPC.SetToken(*nodeRPCToken)
+	}
 
-func (s *AdminServer) loadRegistry() error {
-	data, err := ioutil.ReadFile(s.registryFile)
+	// Open the registry, importing the old JSON registry if there is one
+	pool, err := NewIPPool(*vpnSubnet)
+	if err != nil {
+		log.Fatalf("Invalid VPN subnet: %v", err)
+	}
+	server.vpnPool = pool
+	registry, err := OpenNodeStore(server.dbFile, pool)
 	if err != nil {
-		return err
+		log.Fatalf("Failed to open node registry: %v", err)
+	}
+	defer registry.Close()
+	server.registry = registry
+	if n, err := registry.ImportJSON(server.registryFile); err != nil {
+		log.Printf("Warning: Could not import %s: %v", server.registryFile, err)
+	} else if n > 0 {
+		log.Printf("Imported %d nodes from %s", n, server.registryFile)
 	}
 
-	s.registry = &NodeRegistry{}
-	return json.Unmarshal(data, s.registry)
-}
+	// Load snapshot catalog
+	if err := os.MkdirAll(server.snapshotDir, 0755); err != nil {
+		log.Printf("Warning: Could not create snapshot directory: %v", err)
+	}
+	if err := server.loadSnapshots(); err != nil {
+		server.snapshots = &SnapshotCatalog{Snapshots: []SnapshotInfo{}}
+	}
+
+	// Load reported peer bans
+	if err := server.loadBans(); err != nil {
+		server.bans = &BanRegistry{Reports: []BanReport{}, Manual: []GreylistEntry{}}
+	}
 
-func (s *AdminServer) saveRegistry() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// Load admin API tokens
+	if err := server.loadTokens(); err != nil {
+		log.Fatalf("Failed to load API tokens: %v", err)
+	}
 
-	data, err := json.MarshalIndent(s.registry, "", "  ")
+	// Bootstrap lists are signed so lite nodes can pin this server's key
+	signingKey, err := loadSigningKey(*signingKeyFile)
 	if err != nil {
-		return err
+		log.Fatalf("Failed to load signing key: %v", err)
 	}
+	server.signingKey = signingKey
+	log.Printf("Bootstrap signing key: %s", server.signingPublicKey())
 
-	return ioutil.WriteFile(s.registryFile, data, 0644)
+	// Notify operators of node and system events, and watch approved nodes
+	// so ones that go offline are reported
+	notifier, err := LoadNotifier(*notifyFile)
+	if err != nil {
+		log.Fatalf("Failed to load notification config: %v", err)
+	}
+	notifier.Start()
+	server.notifier = notifier
+	log.Printf("Notifications: %d targets", notifier.Targets())
+	NewHealthMonitor(server, *nodeRPCPort, *healthInterval, *offlineAfter).Start()
+
+	// Updates install signed releases and are recorded in the registry
+	// database
+	updates, err := NewUpdateManager(registry.db, *installDir, *releaseURL, *releaseKey)
+	if err != nil {
+		log.Fatalf("Failed to start update manager: %v", err)
+	}
+	updates.onFailure = func(message string, err error) {
+		server.notifyUpdateFailed(message, err, nil)
+	}
+	server.updates = updates
+
+	// Setup routes. Registration, config retrieval and signed rotation are
+	// called by the nodes themselves and stay open; everything operators
+	// use needs a token of the listed role.
+	http.HandleFunc("/nodes/register", server.handleRegister)
+	http.HandleFunc("/nodes/pending", server.require(RoleViewer, server.handleGetPending))
+	http.HandleFunc("/nodes/approved", server.require(RoleViewer, server.handleGetApproved))
+	http.HandleFunc("/nodes/approve/", server.require(RoleOperator, server.handleApprove))
+	http.HandleFunc("/nodes/reject/", server.require(RoleOperator, server.handleReject))
+	http.HandleFunc("/nodes/remove/", server.require(RoleOperator, server.handleRemove))
+	http.HandleFunc("/nodes/rotate", server.handleRotate)
+	http.HandleFunc("/nodes/history/", server.require(RoleViewer, server.handleNodeHistory))
+	http.HandleFunc("/nodes/profile/", server.require(RoleOperator, server.handleNodeProfile))
+	http.HandleFunc("/nodes/", server.handleGetNodeConfig)
+	http.HandleFunc("/bootstrap", server.handleBootstrap)
+	http.HandleFunc("/snapshots", server.handleListSnapshots)
+	http.HandleFunc("/snapshots/latest", server.handleLatestSnapshot)
+	http.HandleFunc("/snapshots/publish", server.require(RoleAdmin, server.handlePublishSnapshot))
+	http.Handle("/snapshots/files/", http.StripPrefix("/snapshots/files/", http.FileServer(http.Dir(server.snapshotDir))))
+	http.HandleFunc("/peers/bans", server.handleReportBans)
+	http.HandleFunc("/peers/greylist", server.handleGreylist)
+	http.HandleFunc("/peers/greylist/", server.handleClearGreylist)
+	http.HandleFunc("/system/update", server.require(RoleAdmin, server.handleSystemUpdate))
+	http.HandleFunc("/system/updates", server.require(RoleViewer, server.handleUpdates))
+	http.HandleFunc("/system/updates/rollback", server.require(RoleAdmin, server.handleUpdateRollback))
+	http.HandleFunc("/system/rebuild", server.require(RoleAdmin, server.handleRebuildFrontend))
+	http.HandleFunc("/system/status", server.require(RoleViewer, server.handleSystemStatus))
+	http.HandleFunc("/system/vpn/drift", server.require(RoleViewer, server.handleVPNDrift))
+	http.HandleFunc("/system/vpn/reconcile", server.require(RoleOperator, server.handleVPNReconcile))
+	http.HandleFunc("/tokens", server.require(RoleAdmin, server.handleTokens))
+	http.HandleFunc("/tokens/", server.require(RoleAdmin, server.handleRevokeToken))
+	http.HandleFunc("/health", server.handleHealth)
+
+	fmt.Printf("🔧 Admin API Server starting on port %d\n", *port)
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *port), nil))
 }
 
 // Handle node registration requests
@@ -118,28 +230,44 @@ func (s *AdminServer) handleRegister(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if node.NodeID == "" {
+		http.Error(w, "node_id required", http.StatusBadRequest)
+		return
+	}
 
-	node.Status = "pending"
-	node.RegisteredAt = time.Now()
-
-	s.mu.Lock()
-	// Check if node already exists
-	for _, existing := range s.registry.Pending {
-		if existing.NodeID == node.NodeID {
-			s.mu.Unlock()
-			json.NewEncoder(w).Encode(map[string]string{
-				"status":  "success",
-				"message": "Node already registered, pending approval",
-			})
+	if node.Type == NodeTypeValidator {
+		if err := checkValidatorRegistration(&node); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 	}
-	s.registry.Pending = append(s.registry.Pending, node)
-	s.mu.Unlock()
 
-	s.saveRegistry()
+	node.RegisteredAt = time.Now()
 
-	log.Printf("New node registered: %s (%s)", node.NodeID[:16], node.Hostname)
+	status, err := s.registry.Register(&node)
+	if err == errAlreadyExists {
+		message := "Node already registered, pending approval"
+		if status == StatusApproved {
+			message = "Node already approved"
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": message,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error registering node %s: %v", shortID(node.NodeID), err)
+		http.Error(w, "Failed to register node", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("New node registered: %s (%s)", shortID(node.NodeID), node.Hostname)
+	detail := map[string]string{"type": node.Type, "public_ip": node.PublicIP}
+	if node.ValidatorKeyFingerprint != "" {
+		detail["validator_key_fingerprint"] = node.ValidatorKeyFingerprint
+	}
+	s.notifyNode(EventNodeRegistered, &node, "Node registered, pending approval", detail)
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
@@ -150,18 +278,37 @@ func (s *AdminServer) handleRegister(w http.ResponseWriter, r *http.Request) {
 
 // Get pending nodes
 func (s *AdminServer) handleGetPending(w http.ResponseWriter, r *http.Request) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	json.NewEncoder(w).Encode(s.registry.Pending)
+	s.writeNodes(w, StatusPending)
 }
 
 // Get approved nodes
 func (s *AdminServer) handleGetApproved(w http.ResponseWriter, r *http.Request) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.writeNodes(w, StatusApproved)
+}
+
+// writeNodes writes the nodes with a status as a JSON array
+func (s *AdminServer) writeNodes(w http.ResponseWriter, status string) {
+	nodes, err := s.registry.List(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if nodes == nil {
+		nodes = []NodeInfo{}
+	}
+	json.NewEncoder(w).Encode(nodes)
+}
 
-	json.NewEncoder(w).Encode(s.registry.Approved)
+// registryError writes the response for a failed registry change
+func registryError(w http.ResponseWriter, err error) {
+	switch err {
+	case errNodeNotFound:
+		http.Error(w, "Node not found", http.StatusNotFound)
+	case errVPNExhausted:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
 
 // Approve a node
@@ -177,40 +324,68 @@ func (s *AdminServer) handleApprove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.mu.Lock()
-	var approvedNode *NodeInfo
-	var newPending []NodeInfo
+	node, err := s.registry.Get(nodeID)
+	if err != nil {
+		registryError(w, err)
+		return
+	}
+	if node.Status != StatusPending {
+		registryError(w, errNodeNotFound)
+		return
+	}
+	profile, err := decodeProfile(r, defaultProfile(node.Type), node.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	for _, node := range s.registry.Pending {
-		if node.NodeID == nodeID {
-			node.Status = "approved"
-			node.ApprovedAt = time.Now()
-			node.VPNAddress = s.allocateVPNAddress()
-			approvedNode = &node
-			s.registry.Approved = append(s.registry.Approved, node)
-		} else {
-			newPending = append(newPending, node)
+	// Validators are only let onto the network once their stake is
+	// verified and their registration is submitted to the chain
+	if node.Type == NodeTypeValidator {
+		if err := s.onboardValidator(node); err != nil {
+			log.Printf("Validator onboarding failed for %s: %v", shortID(nodeID), err)
+			status := http.StatusBadGateway
+			if errors.Is(err, errInsufficientStake) {
+				status = http.StatusConflict
+			} else if err == errNoNodeRPC {
+				status = http.StatusServiceUnavailable
+			}
+			http.Error(w, err.Error(), status)
+			return
 		}
+		log.Printf("Validator registration submitted: %s (key %s)", node.OperatorAddress, node.ValidatorKeyFingerprint)
 	}
-	s.registry.Pending = newPending
-	s.mu.Unlock()
 
-	if approvedNode == nil {
-		http.Error(w, "Node not found", http.StatusNotFound)
+	approvedNode, err := s.registry.Approve(nodeID, actor(r), profile)
+	if err != nil {
+		if node.Type == NodeTypeValidator {
+			log.Printf("Validator %s registration is submitted but approval failed; approve again once fixed", node.OperatorAddress)
+		}
+		registryError(w, err)
 		return
 	}
 
-	// Generate VPN config for the node
-	s.generateVPNConfig(approvedNode)
-	s.saveRegistry()
+	// Regenerate the server's peers to include the node
+	if err := s.syncVPN(); err != nil {
+		log.Printf("Error updating VPN config after approving %s: %v", shortID(approvedNode.NodeID), err)
+		http.Error(w, "Node approved but VPN update failed; run /system/vpn/reconcile", http.StatusInternalServerError)
+		return
+	}
 
-	log.Printf("Node approved: %s (%s)", approvedNode.NodeID[:16], approvedNode.Hostname)
+	log.Printf("Node approved: %s (%s)", shortID(approvedNode.NodeID), approvedNode.Hostname)
+	s.notifyNode(EventNodeApproved, approvedNode, "Node approved by "+actor(r), map[string]string{
+		"vpn_address": approvedNode.VPNAddress,
+	})
 
-	json.NewEncoder(w).Encode(map[string]string{
+	response := map[string]string{
 		"status":      "success",
 		"message":     "Node approved and VPN configured",
 		"vpn_address": approvedNode.VPNAddress,
-	})
+	}
+	if approvedNode.ValidatorKeyFingerprint != "" {
+		response["validator_key_fingerprint"] = approvedNode.ValidatorKeyFingerprint
+	}
+	json.NewEncoder(w).Encode(response)
 }
 
 // Reject a node
@@ -226,30 +401,14 @@ func (s *AdminServer) handleReject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.mu.Lock()
-	var rejectedNode *NodeInfo
-	var newPending []NodeInfo
-
-	for _, node := range s.registry.Pending {
-		if node.NodeID == nodeID {
-			node.Status = "rejected"
-			rejectedNode = &node
-			s.registry.Rejected = append(s.registry.Rejected, node)
-		} else {
-			newPending = append(newPending, node)
-		}
-	}
-	s.registry.Pending = newPending
-	s.mu.Unlock()
-
-	if rejectedNode == nil {
-		http.Error(w, "Node not found", http.StatusNotFound)
+	rejectedNode, err := s.registry.Reject(nodeID, actor(r))
+	if err != nil {
+		registryError(w, err)
 		return
 	}
 
-	s.saveRegistry()
-
-	log.Printf("Node rejected: %s", rejectedNode.NodeID[:16])
+	log.Printf("Node rejected: %s", shortID(rejectedNode.NodeID))
+	s.notifyNode(EventNodeRejected, rejectedNode, "Node rejected by "+actor(r), nil)
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
@@ -270,30 +429,20 @@ func (s *AdminServer) handleRemove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.mu.Lock()
-	var removedNode *NodeInfo
-	var newApproved []NodeInfo
-
-	for _, node := range s.registry.Approved {
-		if node.NodeID == nodeID {
-			removedNode = &node
-		} else {
-			newApproved = append(newApproved, node)
-		}
+	removedNode, err := s.registry.Remove(nodeID, actor(r))
+	if err != nil {
+		registryError(w, err)
+		return
 	}
-	s.registry.Approved = newApproved
-	s.mu.Unlock()
 
-	if removedNode == nil {
-		http.Error(w, "Node not found", http.StatusNotFound)
+	// Regenerate the server's peers without the node
+	if err := s.syncVPN(); err != nil {
+		log.Printf("Error updating VPN config after removing %s: %v", shortID(removedNode.NodeID), err)
+		http.Error(w, "Node removed but VPN update failed; run /system/vpn/reconcile", http.StatusInternalServerError)
 		return
 	}
 
-	// Remove from VPN config
-	s.removeFromVPN(removedNode)
-	s.saveRegistry()
-
-	log.Printf("Node removed: %s", removedNode.NodeID[:16])
+	log.Printf("Node removed: %s", shortID(removedNode.NodeID))
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
@@ -301,6 +450,27 @@ func (s *AdminServer) handleRemove(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Get a node's registration history, following it through rotations
+func (s *AdminServer) handleNodeHistory(w http.ResponseWriter, r *http.Request) {
+	nodeID := r.URL.Path[len("/nodes/history/"):]
+	if nodeID == "" {
+		http.Error(w, "Node ID required", http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.registry.History(nodeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(events) == 0 {
+		http.Error(w, "Node not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(events)
+}
+
 // Get node config (for lite nodes to retrieve their VPN config)
 func (s *AdminServer) handleGetNodeConfig(w http.ResponseWriter, r *http.Request) {
 	nodeID := r.URL.Path[len("/nodes/"):]
@@ -308,62 +478,54 @@ func (s *AdminServer) handleGetNodeConfig(w http.ResponseWriter, r *http.Request
 		nodeID = nodeID[:len(nodeID)-7]
 	}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	for _, node := range s.registry.Approved {
-		if node.NodeID == nodeID {
-			// Generate VPN client config
-			vpnConfig := s.generateClientVPNConfig(&node)
-			bootstrapNodes := s.getBootstrapNodes()
-
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"status":          "approved",
-				"vpn_config":      vpnConfig,
-				"bootstrap_nodes": bootstrapNodes,
-				"vpn_address":     node.VPNAddress,
-			})
-			return
-		}
+	node, err := s.registry.Get(nodeID)
+	if err != nil && err != errNodeNotFound {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	for _, node := range s.registry.Pending {
-		if node.NodeID == nodeID {
-			json.NewEncoder(w).Encode(map[string]string{
-				"status":  "pending",
-				"message": "Node awaiting approval",
-			})
-			return
-		}
+	switch {
+	case err == nil && node.Status == StatusApproved:
+		// Generate VPN client config
+		vpnConfig := s.generateClientVPNConfig(node)
+		bootstrapNodes := s.getBootstrapNodes()
+
+		s.mu.RLock()
+		snapshot := s.latestSnapshot()
+		s.mu.RUnlock()
+
+		profile := profileOf(node)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":          "approved",
+			"vpn_config":      vpnConfig,
+			"bootstrap_nodes": bootstrapNodes,
+			"bootstrap_key":   s.signingPublicKey(),
+			"vpn_address":     node.VPNAddress,
+			"snapshot":        snapshot,
+			"profile":         profile,
+			"node_config":     s.nodeConfig(node, profile, bootstrapNodes),
+		})
+
+	case err == nil && node.Status == StatusPending:
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "pending",
+			"message": "Node awaiting approval",
+		})
+
+	default:
+		http.Error(w, "Node not found", http.StatusNotFound)
 	}
-
-	http.Error(w, "Node not found", http.StatusNotFound)
 }
 
-// System update - pull from GitHub and rebuild
+// System update, kept for existing callers: installs the configured signed
+// release like POST /system/updates
 func (s *AdminServer) handleSystemUpdate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-
-	go func() {
-		log.Println("Starting system update from GitHub...")
-
-		// Run update script
-		cmd := exec.Command("bash", "/opt/gydschain/scripts/setup-ubuntu.sh", "--update")
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			log.Printf("Update failed: %v\nOutput: %s", err, output)
-		} else {
-			log.Printf("Update completed successfully\nOutput: %s", output)
-		}
-	}()
-
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "Update started in background",
-	})
+	s.startUpdate(w, r)
 }
 
 // Rebuild frontend only
@@ -388,6 +550,7 @@ func (s *AdminServer) handleRebuildFrontend(w http.ResponseWriter, r *http.Reque
 			output, err := cmd.CombinedOutput()
 			if err != nil {
 				log.Printf("Command failed: %v\nOutput: %s", err, output)
+				s.notifyUpdateFailed("Frontend rebuild failed at "+args[0], err, output)
 				return
 			}
 		}
@@ -407,8 +570,16 @@ func (s *AdminServer) handleRebuildFrontend(w http.ResponseWriter, r *http.Reque
 
 // Get system status
 func (s *AdminServer) handleSystemStatus(w http.ResponseWriter, r *http.Request) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	counts, err := s.registry.Counts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	pool, err := s.registry.PoolStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Check service statuses
 	services := []string{"gydschain-node", "gydschain-indexer", "nginx"}
@@ -421,9 +592,11 @@ func (s *AdminServer) handleSystemStatus(w http.ResponseWriter, r *http.Request)
 	}
 
 	status := map[string]interface{}{
-		"pending_nodes":  len(s.registry.Pending),
-		"approved_nodes": len(s.registry.Approved),
-		"rejected_nodes": len(s.registry.Rejected),
+		"pending_nodes":  counts[StatusPending],
+		"approved_nodes": counts[StatusApproved],
+		"rejected_nodes": counts[StatusRejected],
+		"removed_nodes":  counts[StatusRemoved],
+		"vpn_pool":       pool,
 		"services":       serviceStatus,
 		"uptime":         getUptime(),
 	}
@@ -436,36 +609,6 @@ func (s *AdminServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 // Helper functions
-func (s *AdminServer) allocateVPNAddress() string {
-	// Allocate next available VPN address
-	baseIP := "10.100.0."
-	nextID := len(s.registry.Approved) + 2 // Start from .2, .1 is server
-	return fmt.Sprintf("%s%d/24", baseIP, nextID)
-}
-
-func (s *AdminServer) generateVPNConfig(node *NodeInfo) {
-	// Add peer to WireGuard server config
-	peerConfig := fmt.Sprintf(`
-# Node: %s (%s)
-[Peer]
-PublicKey = %s
-AllowedIPs = %s
-`, node.NodeID[:16], node.Hostname, node.WireGuardPubKey, node.VPNAddress)
-
-	// Append to wg0.conf
-	f, err := os.OpenFile(s.vpnConfigDir+"/wg0.conf", os.O_APPEND|os.O_WRONLY, 0600)
-	if err != nil {
-		log.Printf("Error opening VPN config: %v", err)
-		return
-	}
-	defer f.Close()
-
-	f.WriteString(peerConfig)
-
-	// Reload WireGuard
-	exec.Command("wg", "syncconf", "wg0", s.vpnConfigDir+"/wg0.conf").Run()
-}
-
 func (s *AdminServer) generateClientVPNConfig(node *NodeInfo) string {
 	// Read server public key
 	serverPubKey, _ := ioutil.ReadFile(s.vpnConfigDir + "/server_public.key")
@@ -477,18 +620,23 @@ Address = %s
 [Peer]
 PublicKey = %s
 Endpoint = <SERVER_IP>:51820
-AllowedIPs = 10.100.0.0/24
+AllowedIPs = %s
 PersistentKeepalive = 25
-`, node.VPNAddress, string(serverPubKey))
+`, node.VPNAddress, string(serverPubKey), s.vpnPool.Subnet())
 }
 
 func (s *AdminServer) getBootstrapNodes() []map[string]string {
 	nodes := []map[string]string{}
 
-	for _, node := range s.registry.Approved {
+	approved, err := s.registry.List(StatusApproved)
+	if err != nil {
+		log.Printf("Error listing approved nodes: %v", err)
+		return nodes
+	}
+	for _, node := range approved {
 		if node.Type == "fullnode" || node.Type == "validator" {
 			nodes = append(nodes, map[string]string{
-				"address":   node.VPNAddress[:len(node.VPNAddress)-3] + ":30303",
+				"address":   stripPrefix(node.VPNAddress) + ":30303",
 				"node_id":   node.NodeID,
 				"public_ip": node.PublicIP,
 			})
@@ -498,13 +646,6 @@ func (s *AdminServer) getBootstrapNodes() []map[string]string {
 	return nodes
 }
 
-func (s *AdminServer) removeFromVPN(node *NodeInfo) {
-	// Remove peer from WireGuard (would need to rewrite config)
-	log.Printf("Removing node %s from VPN", node.NodeID[:16])
-	// Reload WireGuard
-	exec.Command("wg", "syncconf", "wg0", s.vpnConfigDir+"/wg0.conf").Run()
-}
-
 func getUptime() string {
 	data, err := ioutil.ReadFile("/proc/uptime")
 	if err != nil {
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// HealthMonitor polls approved nodes over the VPN, recording when each
+// last answered and its sync height, and notifies operators once when a
+// node has not answered for the offline threshold
+type HealthMonitor struct {
+	server       *AdminServer
+	rpcPort      int
+	interval     time.Duration
+	offlineAfter time.Duration
+	offline      map[string]bool // nodes already reported offline
+}
+
+// NewHealthMonitor creates a monitor calling nodes' RPC on rpcPort
+func NewHealthMonitor(server *AdminServer, rpcPort int, interval, offlineAfter time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		server:       server,
+		rpcPort:      rpcPort,
+		interval:     interval,
+		offlineAfter: offlineAfter,
+		offline:      make(map[string]bool),
+	}
+}
+
+// Start polls every interval until the process exits
+func (m *HealthMonitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.check()
+		}
+	}()
+}
+
+// check probes every approved node once
+func (m *HealthMonitor) check() {
+	nodes, err := m.server.registry.List(StatusApproved)
+	if err != nil {
+		log.Printf("Health check: %v", err)
+		return
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		answers = make(map[string]bool)
+	)
+	for i := range nodes {
+		node := &nodes[i]
+		if node.VPNAddress == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok := m.probe(node)
+			mu.Lock()
+			answers[node.NodeID] = ok
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	for i := range nodes {
+		node := &nodes[i]
+		ok, probed := answers[node.NodeID]
+		if !probed {
+			continue
+		}
+		if ok {
+			if m.offline[node.NodeID] {
+				log.Printf("Node back online: %s (%s)", shortID(node.NodeID), node.Hostname)
+				delete(m.offline, node.NodeID)
+			}
+			continue
+		}
+
+		since := node.LastSeen
+		if since.IsZero() {
+			since = node.ApprovedAt
+		}
+		if m.offline[node.NodeID] || now.Sub(since) < m.offlineAfter {
+			continue
+		}
+		m.offline[node.NodeID] = true
+		log.Printf("Node offline: %s (%s), last seen %s", shortID(node.NodeID), node.Hostname, since.Format(time.RFC3339))
+		m.server.notifyNode(EventNodeOffline, node, "Node is offline", map[string]string{
+			"last_seen":   since.Format(time.RFC3339),
+			"vpn_address": node.VPNAddress,
+		})
+	}
+
+	// Forget nodes that are no longer approved
+	for nodeID := range m.offline {
+		if _, ok := answers[nodeID]; !ok {
+			delete(m.offline, nodeID)
+		}
+	}
+}
+
+// probe asks a node for its block height, recording it if it answers
+func (m *HealthMonitor) probe(node *NodeInfo) bool {
+	host := net.JoinHostPort(stripPrefix(node.VPNAddress), strconv.Itoa(m.rpcPort))
+	height, err := rpc.NewNodeClient(fmt.Sprintf("http://%s", host)).GetBlockHeight()
+	if err != nil {
+		return false
+	}
+	if err := m.server.registry.Touch(node.NodeID, height, time.Now()); err != nil {
+		log.Printf("Health check: recording %s: %v", shortID(node.NodeID), err)
+	}
+	return true
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Events operators can be notified of
+const (
+	EventNodeRegistered = "node.registered"
+	EventNodeApproved   = "node.approved"
+	EventNodeRejected   = "node.rejected"
+	EventNodeOffline    = "node.offline"
+	EventUpdateFailed   = "system.update_failed"
+)
+
+// Webhook payload formats
+const (
+	FormatGeneric = "generic" // the Notification as JSON
+	FormatSlack   = "slack"   // a Slack incoming webhook message
+	FormatDiscord = "discord" // a Discord webhook message
+)
+
+// Deliveries are retried with doubling delays
+const (
+	notifyAttempts   = 5
+	notifyRetryDelay = 2 * time.Second
+	notifyQueueSize  = 256
+)
+
+// Headers of a signed webhook delivery
+const (
+	signatureHeader    = "X-Gydschain-Signature"
+	signatureTimestamp = "X-Gydschain-Timestamp"
+)
+
+// Notification is an admin event sent to the configured targets
+type Notification struct {
+	Event    string            `json:"event"`
+	NodeID   string            `json:"node_id,omitempty"`
+	Hostname string            `json:"hostname,omitempty"`
+	Message  string            `json:"message"`
+	Detail   map[string]string `json:"detail,omitempty"`
+	At       time.Time         `json:"at"`
+}
+
+// WebhookConfig is one webhook target. A non-empty secret signs every
+// payload: the signature header carries the hex HMAC-SHA256 of the
+// timestamp header, a dot and the body.
+type WebhookConfig struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Format string   `json:"format"`           // generic (default), slack or discord
+	Secret string   `json:"secret,omitempty"` // HMAC key for the signature header
+	Events []string `json:"events,omitempty"` // all events if empty
+}
+
+// EmailConfig sends notifications through an SMTP server
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Events   []string `json:"events,omitempty"` // all events if empty
+}
+
+// NotifyConfig lists where notifications go
+type NotifyConfig struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+	Email    *EmailConfig    `json:"email,omitempty"`
+}
+
+// Notifier delivers notifications in the background so handlers never
+// wait on a slow or unreachable target
+type Notifier struct {
+	config NotifyConfig
+	client *http.Client
+	queue  chan Notification
+}
+
+// LoadNotifier reads the notification config at path. A missing file
+// gives a notifier with no targets.
+func LoadNotifier(path string) (*Notifier, error) {
+	n := &Notifier{
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan Notification, notifyQueueSize),
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return n, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &n.config); err != nil {
+		return nil, err
+	}
+	for i, hook := range n.config.Webhooks {
+		switch hook.Format {
+		case "":
+			n.config.Webhooks[i].Format = FormatGeneric
+		case FormatGeneric, FormatSlack, FormatDiscord:
+		default:
+			return nil, fmt.Errorf("webhook %s: unknown format %q", hook.Name, hook.Format)
+		}
+	}
+	return n, nil
+}
+
+// Targets returns the number of configured webhooks and email recipients
+func (n *Notifier) Targets() int {
+	targets := len(n.config.Webhooks)
+	if n.config.Email != nil {
+		targets += len(n.config.Email.To)
+	}
+	return targets
+}
+
+// Start delivers queued notifications until the process exits
+func (n *Notifier) Start() {
+	go func() {
+		for note := range n.queue {
+			n.deliver(note)
+		}
+	}()
+}
+
+// Notify queues a notification, dropping it if the queue is full
+func (n *Notifier) Notify(note Notification) {
+	if n == nil || n.Targets() == 0 {
+		return
+	}
+	if note.At.IsZero() {
+		note.At = time.Now()
+	}
+	select {
+	case n.queue <- note:
+	default:
+		log.Printf("Notification queue full, dropping %s", note.Event)
+	}
+}
+
+// deliver sends a notification to every target subscribed to its event.
+// Targets are retried independently, so one failing target does not
+// delay or repeat deliveries to the others.
+func (n *Notifier) deliver(note Notification) {
+	for _, hook := range n.config.Webhooks {
+		if !subscribed(hook.Events, note.Event) {
+			continue
+		}
+		hook := hook
+		go retry("webhook "+hook.Name, func() error { return n.sendWebhook(hook, note) })
+	}
+	if email := n.config.Email; email != nil && len(email.To) > 0 && subscribed(email.Events, note.Event) {
+		go retry("email", func() error { return sendEmail(email, note) })
+	}
+}
+
+// retry calls send until it succeeds or notifyAttempts are used up
+func retry(target string, send func() error) {
+	delay := notifyRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := send()
+		if err == nil {
+			return
+		}
+		if attempt == notifyAttempts {
+			log.Printf("Notification to %s failed after %d attempts: %v", target, attempt, err)
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func subscribed(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// sendWebhook posts a notification in the hook's format
+func (n *Notifier) sendWebhook(hook WebhookConfig, note Notification) error {
+	var payload interface{} = note
+	switch hook.Format {
+	case FormatSlack:
+		payload = map[string]string{"text": note.text()}
+	case FormatDiscord:
+		payload = map[string]string{"content": note.text()}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(signatureTimestamp, timestamp)
+		req.Header.Set(signatureHeader, "sha256="+signPayload(hook.Secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// signPayload returns the hex HMAC-SHA256 of timestamp.body under secret.
+// Covering the timestamp lets receivers reject replayed deliveries.
+func signPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendEmail mails a notification to the configured recipients
+func sendEmail(config *EmailConfig, note Notification) error {
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [gydschain-admin] %s\r\n", note.Event)
+	fmt.Fprintf(&msg, "Date: %s\r\n", note.At.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(note.text() + "\r\n")
+	return smtp.SendMail(addr, auth, config.From, config.To, msg.Bytes())
+}
+
+// text renders a notification for chat and email
+func (note Notification) text() string {
+	var b strings.Builder
+	b.WriteString(note.Message)
+	if note.NodeID != "" {
+		fmt.Fprintf(&b, "\nNode: %s", shortID(note.NodeID))
+		if note.Hostname != "" {
+			fmt.Fprintf(&b, " (%s)", note.Hostname)
+		}
+	}
+	keys := make([]string, 0, len(note.Detail))
+	for key := range note.Detail {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n%s: %s", key, note.Detail[key])
+	}
+	return b.String()
+}
+
+// notifyNode sends a notification about a node
+func (s *AdminServer) notifyNode(event string, node *NodeInfo, message string, detail map[string]string) {
+	s.notifier.Notify(Notification{
+		Event:    event,
+		NodeID:   node.NodeID,
+		Hostname: node.Hostname,
+		Message:  message,
+		Detail:   detail,
+	})
+}
+
+// notifyUpdateFailed reports a failed update with the end of its output
+func (s *AdminServer) notifyUpdateFailed(message string, err error, output []byte) {
+	const maxOutput = 1000
+	if len(output) > maxOutput {
+		output = output[len(output)-maxOutput:]
+	}
+	detail := map[string]string{"error": err.Error()}
+	if len(output) > 0 {
+		detail["output"] = string(output)
+	}
+	s.notifier.Notify(Notification{
+		Event:   EventUpdateFailed,
+		Message: message,
+		Detail:  detail,
+	})
+}
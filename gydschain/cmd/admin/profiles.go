@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/gydschain/gydschain/internal/config"
+)
+
+// Sync modes a node can be provisioned with
+const (
+	SyncFull       = "full"       // replay the chain from genesis
+	SyncSnapshot   = "snapshot"   // restore the latest published snapshot, then follow the chain
+	SyncLight      = "light"      // lite node verifying every header
+	SyncUltralight = "ultralight" // lite node verifying from trusted checkpoints
+)
+
+// Where a node's RPC server listens
+const (
+	RPCNone   = "none"   // RPC disabled
+	RPCLocal  = "local"  // loopback only
+	RPCVPN    = "vpn"    // the node's VPN address, reachable by other nodes and health checks
+	RPCPublic = "public" // every interface
+)
+
+// State pruning policies
+const (
+	PruneArchive = "archive" // keep state for every height
+	PruneRecent  = "pruned"  // keep the state history window only
+)
+
+// p2pPort is the port nodes listen for peers on
+const p2pPort = "30303"
+
+// NodeProfile is the configuration an approved node provisions itself
+// with. It is chosen at approval, defaulting by node type, and can be
+// changed later; nodes pick it up from their config endpoint.
+type NodeProfile struct {
+	SyncMode     string   `json:"sync_mode"`
+	RPCExposure  string   `json:"rpc_exposure"`
+	RPCAPIs      []string `json:"rpc_apis,omitempty"` // namespaces served; the node default if empty
+	RPCReadOnly  bool     `json:"rpc_read_only,omitempty"`
+	Pruning      string   `json:"pruning"`
+	StateHistory uint64   `json:"state_history,omitempty"` // heights kept when pruned; the node default if zero
+	Seeds        []string `json:"seeds,omitempty"`         // host:port peers dialed besides approved full nodes
+}
+
+// isLiteNode reports whether a node type runs the lite node
+func isLiteNode(nodeType string) bool {
+	return nodeType == "litenode"
+}
+
+// defaultProfile returns the profile a node of nodeType is approved with
+// when the operator gives none
+func defaultProfile(nodeType string) NodeProfile {
+	if isLiteNode(nodeType) {
+		return NodeProfile{SyncMode: SyncLight, RPCExposure: RPCNone, Pruning: PruneRecent}
+	}
+	return NodeProfile{SyncMode: SyncSnapshot, RPCExposure: RPCVPN, Pruning: PruneRecent}
+}
+
+// validate checks a profile suits a node of nodeType
+func (p *NodeProfile) validate(nodeType string) error {
+	switch p.SyncMode {
+	case SyncFull, SyncSnapshot:
+		if isLiteNode(nodeType) {
+			return fmt.Errorf("sync_mode %s needs a full node", p.SyncMode)
+		}
+	case SyncLight, SyncUltralight:
+		if !isLiteNode(nodeType) {
+			return fmt.Errorf("sync_mode %s is only for lite nodes", p.SyncMode)
+		}
+	default:
+		return fmt.Errorf("unknown sync_mode %q", p.SyncMode)
+	}
+	switch p.RPCExposure {
+	case RPCNone, RPCLocal, RPCVPN, RPCPublic:
+	default:
+		return fmt.Errorf("unknown rpc_exposure %q", p.RPCExposure)
+	}
+	switch p.Pruning {
+	case PruneArchive:
+		if p.StateHistory != 0 {
+			return fmt.Errorf("state_history does not apply to archive nodes")
+		}
+	case PruneRecent:
+	default:
+		return fmt.Errorf("unknown pruning %q", p.Pruning)
+	}
+	for _, seed := range p.Seeds {
+		if _, _, err := net.SplitHostPort(seed); err != nil {
+			return fmt.Errorf("invalid seed %q: %w", seed, err)
+		}
+	}
+	return nil
+}
+
+// profileOf returns a node's profile, or its type's default for nodes
+// approved before profiles existed
+func profileOf(node *NodeInfo) NodeProfile {
+	if node.Profile != nil {
+		return *node.Profile
+	}
+	return defaultProfile(node.Type)
+}
+
+// decodeProfile reads an optional {"profile": {...}} request body over
+// base, so fields the operator leaves out keep base's values
+func decodeProfile(r *http.Request, base NodeProfile, nodeType string) (NodeProfile, error) {
+	req := struct {
+		Profile *NodeProfile `json:"profile"`
+	}{Profile: &base}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return base, fmt.Errorf("invalid request body: %w", err)
+	}
+	if req.Profile == nil {
+		return defaultProfile(nodeType), nil
+	}
+	if err := req.Profile.validate(nodeType); err != nil {
+		return base, err
+	}
+	return *req.Profile, nil
+}
+
+// nodeConfig renders the full node config an approved node starts with.
+// Lite nodes are configured from the profile and bootstrap list instead.
+func (s *AdminServer) nodeConfig(node *NodeInfo, profile NodeProfile, bootstrap []map[string]string) *config.Config {
+	if isLiteNode(node.Type) {
+		return nil
+	}
+	vpnIP := stripPrefix(node.VPNAddress)
+
+	cfg := config.DefaultConfig()
+	cfg.NodeID = node.NodeID
+	cfg.Network.ListenAddr = net.JoinHostPort("0.0.0.0", p2pPort)
+	cfg.Network.ExternalAddr = net.JoinHostPort(vpnIP, p2pPort)
+	cfg.Network.AdminURL = s.publicURL
+	for _, peer := range bootstrap {
+		if peer["node_id"] != node.NodeID {
+			cfg.Network.BootstrapPeers = append(cfg.Network.BootstrapPeers, peer["address"])
+		}
+	}
+	cfg.Network.BootstrapPeers = append(cfg.Network.BootstrapPeers, profile.Seeds...)
+
+	switch profile.RPCExposure {
+	case RPCNone:
+		cfg.RPC.Enabled = false
+	case RPCLocal:
+		cfg.RPC.HTTPAddr, cfg.RPC.WSAddr = "127.0.0.1", "127.0.0.1"
+	case RPCVPN:
+		cfg.RPC.HTTPAddr, cfg.RPC.WSAddr = vpnIP, vpnIP
+	case RPCPublic:
+		cfg.RPC.HTTPAddr, cfg.RPC.WSAddr = "0.0.0.0", "0.0.0.0"
+	}
+	if len(profile.RPCAPIs) > 0 {
+		cfg.RPC.EnabledAPIs = profile.RPCAPIs
+	}
+	cfg.RPC.ReadOnly = profile.RPCReadOnly
+
+	cfg.Chain.FastSync = profile.SyncMode == SyncSnapshot
+	cfg.Chain.Archive = profile.Pruning == PruneArchive
+	if profile.StateHistory > 0 {
+		cfg.Chain.StateHistory = profile.StateHistory
+	}
+
+	if node.Type == NodeTypeValidator {
+		cfg.Validator.Enabled = true
+		cfg.Validator.MinStake = s.minStake.String()
+	}
+	return cfg
+}
+
+// Get or change an approved node's provisioning profile. A PUT body is
+// {"profile": {...}}; fields left out keep their current values.
+func (s *AdminServer) handleNodeProfile(w http.ResponseWriter, r *http.Request) {
+	nodeID := r.URL.Path[len("/nodes/profile/"):]
+	if nodeID == "" {
+		http.Error(w, "Node ID required", http.StatusBadRequest)
+		return
+	}
+	node, err := s.registry.Get(nodeID)
+	if err == nil && node.Status != StatusApproved {
+		err = errNodeNotFound
+	}
+	if err != nil {
+		registryError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(profileOf(node))
+
+	case http.MethodPut:
+		profile, err := decodeProfile(r, profileOf(node), node.Type)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.registry.SetProfile(nodeID, profile, actor(r)); err != nil {
+			registryError(w, err)
+			return
+		}
+		log.Printf("Profile of %s changed by %s", shortID(nodeID), actor(r))
+		json.NewEncoder(w).Encode(profile)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
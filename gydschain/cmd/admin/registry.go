@@ -0,0 +1,684 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Node registration statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusRemoved  = "removed"
+)
+
+var (
+	errNodeNotFound  = errors.New("node not found")
+	errNodeExists    = errors.New("node ID already registered")
+	errAlreadyExists = errors.New("node already registered")
+)
+
+// registrySchema creates the node registry tables. Nodes keep a stable row
+// ID so rotations and history survive a change of node ID.
+const registrySchema = `
+CREATE TABLE IF NOT EXISTS nodes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    node_id TEXT NOT NULL UNIQUE,
+    hostname TEXT NOT NULL DEFAULT '',
+    public_ip TEXT NOT NULL DEFAULT '',
+    wireguard_public_key TEXT NOT NULL DEFAULT '',
+    node_type TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    vpn_address TEXT,
+    registered_at DATETIME NOT NULL,
+    approved_at DATETIME,
+    last_seen DATETIME,
+    sync_height INTEGER NOT NULL DEFAULT 0,
+    operator_address TEXT NOT NULL DEFAULT '',
+    validator_pub_key TEXT NOT NULL DEFAULT '',
+    validator_key_fingerprint TEXT NOT NULL DEFAULT '',
+    registration_tx TEXT NOT NULL DEFAULT '',
+    profile TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_nodes_status ON nodes (status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nodes_vpn_address ON nodes (vpn_address)
+    WHERE status = 'approved';
+
+CREATE TABLE IF NOT EXISTS node_rotations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    node INTEGER NOT NULL REFERENCES nodes (id),
+    node_id TEXT NOT NULL,
+    wireguard_public_key TEXT NOT NULL,
+    rotated_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_node_rotations_node ON node_rotations (node);
+
+CREATE TABLE IF NOT EXISTS node_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    node INTEGER NOT NULL REFERENCES nodes (id),
+    node_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_node_history_node ON node_history (node);
+`
+
+// registryColumns are columns added to nodes after its first release,
+// added to older databases when they are opened
+var registryColumns = []struct{ name, definition string }{
+	{"operator_address", "TEXT NOT NULL DEFAULT ''"},
+	{"validator_pub_key", "TEXT NOT NULL DEFAULT ''"},
+	{"validator_key_fingerprint", "TEXT NOT NULL DEFAULT ''"},
+	{"profile", "TEXT NOT NULL DEFAULT ''"},
+	{"registration_tx", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// NodeRegistry is the legacy JSON registry format, read once to import it
+type NodeRegistry struct {
+	Pending  []NodeInfo `json:"pending"`
+	Approved []NodeInfo `json:"approved"`
+	Rejected []NodeInfo `json:"rejected"`
+}
+
+// NodeEvent is an audit record of a change to a node's registration
+type NodeEvent struct {
+	NodeID string    `json:"node_id"` // node ID at the time of the change
+	Action string    `json:"action"`
+	Actor  string    `json:"actor"` // API token name, or "node" for self-service
+	Detail string    `json:"detail,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// NodeStore is the node registry, kept in an embedded SQLite database so
+// every change is one transaction with its audit record
+type NodeStore struct {
+	db   *sql.DB
+	pool *IPPool
+}
+
+// OpenNodeStore opens or creates the registry database at path, handing
+// approved nodes VPN addresses from pool
+func OpenNodeStore(path string, pool *IPPool) (*NodeStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	// One writer at a time; SQLite serializes them anyway
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(registrySchema + ipPoolSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create registry schema: %w", err)
+	}
+	if err := migrateRegistry(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate registry schema: %w", err)
+	}
+	if err := pool.adopt(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("adopt VPN addresses: %w", err)
+	}
+	return &NodeStore{db: db, pool: pool}, nil
+}
+
+// migrateRegistry adds the registryColumns an older database lacks
+func migrateRegistry(db *sql.DB) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info('nodes')")
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		have[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, col := range registryColumns {
+		if have[col.name] {
+			continue
+		}
+		if _, err := db.Exec("ALTER TABLE nodes ADD COLUMN " + col.name + " " + col.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (ns *NodeStore) Close() error {
+	return ns.db.Close()
+}
+
+// ImportJSON loads a legacy JSON registry into an empty store and renames
+// the file so it is not imported again. A missing file is not an error.
+func (ns *NodeStore) ImportJSON(path string) (int, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := ns.db.QueryRow("SELECT COUNT(*) FROM nodes").Scan(&count); err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		return 0, fmt.Errorf("registry database is not empty, leaving %s in place", path)
+	}
+
+	var legacy NodeRegistry
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return 0, err
+	}
+
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	for status, list := range map[string][]NodeInfo{
+		StatusPending:  legacy.Pending,
+		StatusApproved: legacy.Approved,
+		StatusRejected: legacy.Rejected,
+	} {
+		for i := range list {
+			node := list[i]
+			node.Status = status
+			id, err := insertNode(tx, &node)
+			if err != nil {
+				return 0, fmt.Errorf("import %s: %w", shortID(node.NodeID), err)
+			}
+			for _, rot := range node.Rotations {
+				if err := insertRotation(tx, id, rot); err != nil {
+					return 0, err
+				}
+			}
+			if err := recordEvent(tx, id, node.NodeID, "imported", "migration", status); err != nil {
+				return 0, err
+			}
+			imported++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	if err := ns.pool.adopt(ns.db); err != nil {
+		return 0, err
+	}
+	return imported, os.Rename(path, path+".imported")
+}
+
+// Register adds a pending node. A node that was rejected or removed is put
+// back to pending; one already pending or approved is left alone and
+// errAlreadyExists returned with its current status.
+func (ns *NodeStore) Register(node *NodeInfo) (string, error) {
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	id, status, err := lookupNode(tx, node.NodeID)
+	switch {
+	case err == errNodeNotFound:
+		node.Status = StatusPending
+		if id, err = insertNode(tx, node); err != nil {
+			return "", err
+		}
+		if err := recordEvent(tx, id, node.NodeID, "registered", "node", node.Hostname); err != nil {
+			return "", err
+		}
+	case err != nil:
+		return "", err
+	case status == StatusPending || status == StatusApproved:
+		return status, errAlreadyExists
+	default:
+		_, err := tx.Exec(`
+			UPDATE nodes SET status = ?, hostname = ?, public_ip = ?, wireguard_public_key = ?,
+			                 node_type = ?, registered_at = ?, vpn_address = NULL, approved_at = NULL, profile = '',
+			                 operator_address = ?, validator_pub_key = ?, validator_key_fingerprint = ?,
+			                 registration_tx = ?
+			WHERE id = ?
+		`, StatusPending, node.Hostname, node.PublicIP, node.WireGuardPubKey, node.Type, node.RegisteredAt,
+			node.OperatorAddress, node.ValidatorPubKey, node.ValidatorKeyFingerprint, node.RegistrationTx, id)
+		if err != nil {
+			return "", err
+		}
+		if err := recordEvent(tx, id, node.NodeID, "re-registered", "node", "was "+status); err != nil {
+			return "", err
+		}
+	}
+	return StatusPending, tx.Commit()
+}
+
+// Approve approves a pending node with a provisioning profile and gives
+// it a VPN address from the pool, failing with errVPNExhausted when none
+// is free. The audit record names the address and, for validators, the
+// validator key fingerprint.
+func (ns *NodeStore) Approve(nodeID, actor string, profile NodeProfile) (*NodeInfo, error) {
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, status, err := lookupNode(tx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if status != StatusPending {
+		return nil, errNodeNotFound
+	}
+	var fingerprint string
+	if err := tx.QueryRow("SELECT validator_key_fingerprint FROM nodes WHERE id = ?", id).Scan(&fingerprint); err != nil {
+		return nil, err
+	}
+	address, err := ns.pool.Allocate(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		"UPDATE nodes SET status = ?, vpn_address = ?, approved_at = ?, profile = ? WHERE id = ?",
+		StatusApproved, address, time.Now(), string(profileJSON), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	detail := address
+	if fingerprint != "" {
+		detail += " validator key " + fingerprint
+	}
+	if err := recordEvent(tx, id, nodeID, StatusApproved, actor, detail); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ns.Get(nodeID)
+}
+
+// SetProfile replaces an approved node's provisioning profile
+func (ns *NodeStore) SetProfile(nodeID string, profile NodeProfile, actor string) error {
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	id, status, err := lookupNode(tx, nodeID)
+	if err != nil {
+		return err
+	}
+	if status != StatusApproved {
+		return errNodeNotFound
+	}
+	if _, err := tx.Exec("UPDATE nodes SET profile = ? WHERE id = ?", string(profileJSON), id); err != nil {
+		return err
+	}
+	if err := recordEvent(tx, id, nodeID, "profile", actor, string(profileJSON)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reject rejects a pending node
+func (ns *NodeStore) Reject(nodeID, actor string) (*NodeInfo, error) {
+	return ns.transition(nodeID, StatusPending, StatusRejected, actor, nil)
+}
+
+// Remove takes an approved node off the network, returning its VPN address
+// to the pool. The row and its history are kept.
+func (ns *NodeStore) Remove(nodeID, actor string) (*NodeInfo, error) {
+	return ns.transition(nodeID, StatusApproved, StatusRemoved, actor, ns.pool.Release)
+}
+
+// transition moves a node from one status to another, running then, if
+// set, in the same transaction. It returns the node as it was before the
+// change.
+func (ns *NodeStore) transition(nodeID, from, to, actor string, then func(*sql.Tx, int64) error) (*NodeInfo, error) {
+	node, err := ns.Get(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, status, err := lookupNode(tx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if status != from {
+		return nil, errNodeNotFound
+	}
+	if _, err := tx.Exec("UPDATE nodes SET status = ? WHERE id = ?", to, id); err != nil {
+		return nil, err
+	}
+	if then != nil {
+		if err := then(tx, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordEvent(tx, id, nodeID, to, actor, ""); err != nil {
+		return nil, err
+	}
+	return node, tx.Commit()
+}
+
+// Rotate moves an approved node to a new node ID and WireGuard key,
+// recording the identity it leaves behind
+func (ns *NodeStore) Rotate(oldID, newID, newWireGuardPubKey string, at time.Time) error {
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	id, status, err := lookupNode(tx, oldID)
+	if err != nil {
+		return err
+	}
+	if status != StatusApproved {
+		return errNodeNotFound
+	}
+	if _, _, err := lookupNode(tx, newID); err == nil {
+		return errNodeExists
+	} else if err != errNodeNotFound {
+		return err
+	}
+
+	var oldKey string
+	if err := tx.QueryRow("SELECT wireguard_public_key FROM nodes WHERE id = ?", id).Scan(&oldKey); err != nil {
+		return err
+	}
+	rotation := IdentityRotation{NodeID: oldID, WireGuardPubKey: oldKey, RotatedAt: at}
+	if err := insertRotation(tx, id, rotation); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"UPDATE nodes SET node_id = ?, wireguard_public_key = ? WHERE id = ?",
+		newID, newWireGuardPubKey, id,
+	)
+	if err != nil {
+		return err
+	}
+	if err := recordEvent(tx, id, newID, "rotated", "node", "from "+oldID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns a node with its rotations, or errNodeNotFound
+func (ns *NodeStore) Get(nodeID string) (*NodeInfo, error) {
+	nodes, err := ns.query("WHERE n.node_id = ?", nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, errNodeNotFound
+	}
+	return &nodes[0], nil
+}
+
+// List returns the nodes with a status, oldest registration first
+func (ns *NodeStore) List(status string) ([]NodeInfo, error) {
+	return ns.query("WHERE n.status = ?", status)
+}
+
+// IsApproved reports whether nodeID is an approved node
+func (ns *NodeStore) IsApproved(nodeID string) bool {
+	var status string
+	err := ns.db.QueryRow("SELECT status FROM nodes WHERE node_id = ?", nodeID).Scan(&status)
+	return err == nil && status == StatusApproved
+}
+
+// Touch records that an approved node answered at a block height
+func (ns *NodeStore) Touch(nodeID string, height uint64, at time.Time) error {
+	_, err := ns.db.Exec(
+		"UPDATE nodes SET last_seen = ?, sync_height = ? WHERE node_id = ? AND status = ?",
+		at, height, nodeID, StatusApproved,
+	)
+	return err
+}
+
+// Exists reports whether any registration uses nodeID
+func (ns *NodeStore) Exists(nodeID string) bool {
+	var one int
+	return ns.db.QueryRow("SELECT 1 FROM nodes WHERE node_id = ?", nodeID).Scan(&one) == nil
+}
+
+// Counts returns the number of nodes per status
+func (ns *NodeStore) Counts() (map[string]int, error) {
+	rows, err := ns.db.Query("SELECT status, COUNT(*) FROM nodes GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{StatusPending: 0, StatusApproved: 0, StatusRejected: 0, StatusRemoved: 0}
+	for rows.Next() {
+		var (
+			status string
+			n      int
+		)
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// History returns a node's audit records, oldest first. It follows the
+// node through rotations, so an old or current node ID finds the same
+// history.
+func (ns *NodeStore) History(nodeID string) ([]NodeEvent, error) {
+	rows, err := ns.db.Query(`
+		SELECT node_id, action, actor, detail, at FROM node_history
+		WHERE node IN (
+			SELECT id FROM nodes WHERE node_id = ?
+			UNION
+			SELECT node FROM node_rotations WHERE node_id = ?
+		)
+		ORDER BY id
+	`, nodeID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []NodeEvent
+	for rows.Next() {
+		var e NodeEvent
+		if err := rows.Scan(&e.NodeID, &e.Action, &e.Actor, &e.Detail, &e.At); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// query returns the nodes matching where, with their rotations
+func (ns *NodeStore) query(where string, args ...interface{}) ([]NodeInfo, error) {
+	rows, err := ns.db.Query(`
+		SELECT n.id, n.node_id, n.hostname, n.public_ip, n.wireguard_public_key, n.node_type,
+		       n.status, COALESCE(n.vpn_address, ''), n.registered_at, n.approved_at,
+		       n.last_seen, n.sync_height, n.operator_address, n.validator_pub_key,
+		       n.validator_key_fingerprint, n.registration_tx, n.profile
+		FROM nodes n
+		`+where+`
+		ORDER BY n.registered_at, n.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		nodes []NodeInfo
+		ids   []int64
+	)
+	for rows.Next() {
+		var (
+			node               NodeInfo
+			id                 int64
+			approved, lastSeen sql.NullTime
+			profile            string
+		)
+		if err := rows.Scan(&id, &node.NodeID, &node.Hostname, &node.PublicIP, &node.WireGuardPubKey,
+			&node.Type, &node.Status, &node.VPNAddress, &node.RegisteredAt, &approved,
+			&lastSeen, &node.SyncHeight, &node.OperatorAddress, &node.ValidatorPubKey,
+			&node.ValidatorKeyFingerprint, &node.RegistrationTx, &profile); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if profile != "" {
+			node.Profile = &NodeProfile{}
+			if err := json.Unmarshal([]byte(profile), node.Profile); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("node %s profile: %w", shortID(node.NodeID), err)
+			}
+		}
+		node.ApprovedAt = approved.Time
+		node.LastSeen = lastSeen.Time
+		nodes = append(nodes, node)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		if nodes[i].Rotations, err = ns.rotations(id); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// rotations returns the identities a node rotated away from, oldest first
+func (ns *NodeStore) rotations(id int64) ([]IdentityRotation, error) {
+	rows, err := ns.db.Query(
+		"SELECT node_id, wireguard_public_key, rotated_at FROM node_rotations WHERE node = ? ORDER BY id", id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rotations []IdentityRotation
+	for rows.Next() {
+		var r IdentityRotation
+		if err := rows.Scan(&r.NodeID, &r.WireGuardPubKey, &r.RotatedAt); err != nil {
+			return nil, err
+		}
+		rotations = append(rotations, r)
+	}
+	return rotations, rows.Err()
+}
+
+// lookupNode returns a node's row ID and status, or errNodeNotFound
+func lookupNode(tx *sql.Tx, nodeID string) (int64, string, error) {
+	var (
+		id     int64
+		status string
+	)
+	err := tx.QueryRow("SELECT id, status FROM nodes WHERE node_id = ?", nodeID).Scan(&id, &status)
+	if err == sql.ErrNoRows {
+		return 0, "", errNodeNotFound
+	}
+	return id, status, err
+}
+
+func insertNode(tx *sql.Tx, node *NodeInfo) (int64, error) {
+	var profile string
+	if node.Profile != nil {
+		data, err := json.Marshal(node.Profile)
+		if err != nil {
+			return 0, err
+		}
+		profile = string(data)
+	}
+	res, err := tx.Exec(`
+		INSERT INTO nodes (node_id, hostname, public_ip, wireguard_public_key, node_type, status,
+		                   vpn_address, registered_at, approved_at, last_seen, sync_height,
+		                   operator_address, validator_pub_key, validator_key_fingerprint, registration_tx, profile)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		node.NodeID,
+		node.Hostname,
+		node.PublicIP,
+		node.WireGuardPubKey,
+		node.Type,
+		node.Status,
+		sql.NullString{String: node.VPNAddress, Valid: node.VPNAddress != ""},
+		node.RegisteredAt,
+		sql.NullTime{Time: node.ApprovedAt, Valid: !node.ApprovedAt.IsZero()},
+		sql.NullTime{Time: node.LastSeen, Valid: !node.LastSeen.IsZero()},
+		node.SyncHeight,
+		node.OperatorAddress,
+		node.ValidatorPubKey,
+		node.ValidatorKeyFingerprint,
+		node.RegistrationTx,
+		profile,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func insertRotation(tx *sql.Tx, id int64, r IdentityRotation) error {
+	_, err := tx.Exec(
+		"INSERT INTO node_rotations (node, node_id, wireguard_public_key, rotated_at) VALUES (?, ?, ?, ?)",
+		id, r.NodeID, r.WireGuardPubKey, r.RotatedAt,
+	)
+	return err
+}
+
+// recordEvent appends an audit record for the node with row ID id
+func recordEvent(tx *sql.Tx, id int64, nodeID, action, actor, detail string) error {
+	_, err := tx.Exec(
+		"INSERT INTO node_history (node, node_id, action, actor, detail, at) VALUES (?, ?, ?, ?, ?, ?)",
+		id, nodeID, action, actor, detail, time.Now(),
+	)
+	return err
+}
+
+// PoolStats reports the VPN address pool's usage
+func (ns *NodeStore) PoolStats() (PoolStats, error) {
+	return ns.pool.Stats(ns.db)
+}
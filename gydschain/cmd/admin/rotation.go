@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Rotation requests older or newer than this are refused so a captured
+// request cannot be replayed later
+const maxRotationSkew = 5 * time.Minute
+
+var errRotationSignature = errors.New("rotation request not signed by the node's current key")
+
+// IdentityRotation records an identity a node rotated away from
+type IdentityRotation struct {
+	NodeID          string    `json:"node_id"`
+	WireGuardPubKey string    `json:"wireguard_public_key"`
+	RotatedAt       time.Time `json:"rotated_at"`
+}
+
+// RotationRequest replaces a compromised node identity. Node IDs that can
+// rotate are hex ed25519 public keys; Signature is the current key's hex
+// signature over message(), so only its holder can move the node's
+// approval to a new key.
+type RotationRequest struct {
+	NodeID             string `json:"node_id"`
+	NewNodeID          string `json:"new_node_id"`
+	NewWireGuardPubKey string `json:"new_wireguard_public_key"`
+	Timestamp          int64  `json:"timestamp"`
+	Signature          string `json:"signature"`
+}
+
+// message returns the exact bytes the current key signs
+func (req *RotationRequest) message() []byte {
+	return []byte(fmt.Sprintf("gydschain-node-rotation:%s:%s:%s:%d",
+		req.NodeID, req.NewNodeID, req.NewWireGuardPubKey, req.Timestamp))
+}
+
+// verify checks the request is well formed, fresh and signed by the key
+// behind the current node ID
+func (req *RotationRequest) verify(now time.Time) error {
+	key, err := nodeIDKey(req.NodeID)
+	if err != nil {
+		return fmt.Errorf("node %s has no signing key and must register again", shortID(req.NodeID))
+	}
+	if _, err := nodeIDKey(req.NewNodeID); err != nil {
+		return errors.New("new node ID must be a hex ed25519 public key")
+	}
+	if req.NewNodeID == req.NodeID {
+		return errors.New("new node ID must differ from the current one")
+	}
+	if wg, err := base64.StdEncoding.DecodeString(req.NewWireGuardPubKey); err != nil || len(wg) != 32 {
+		return errors.New("invalid WireGuard public key")
+	}
+	if skew := now.Sub(time.Unix(req.Timestamp, 0)); skew > maxRotationSkew || skew < -maxRotationSkew {
+		return errors.New("rotation request expired")
+	}
+
+	sig, err := hex.DecodeString(req.Signature)
+	if err != nil || !ed25519.Verify(key, req.message(), sig) {
+		return errRotationSignature
+	}
+	return nil
+}
+
+// nodeIDKey parses a node ID as the ed25519 public key it encodes
+func nodeIDKey(id string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(id)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("node ID is not an ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Rotate an approved node to a new identity and WireGuard key. The node
+// keeps its VPN address, approval and ban reports, and the bootstrap list
+// serves the new ID from the next request.
+func (s *AdminServer) handleRotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if err := req.verify(now); err == errRotationSignature {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// s.mu serializes rotations so two cannot edit wg0.conf at once
+	s.mu.Lock()
+	old, err := s.registry.Get(req.NodeID)
+	if err == nil && old.Status != StatusApproved {
+		err = errNodeNotFound
+	}
+	if err != nil {
+		s.mu.Unlock()
+		if err == errNodeNotFound {
+			http.Error(w, "Approved node not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if s.registry.Exists(req.NewNodeID) {
+		s.mu.Unlock()
+		http.Error(w, "New node ID already registered", http.StatusConflict)
+		return
+	}
+
+	node := *old
+	node.NodeID = req.NewNodeID
+	node.WireGuardPubKey = req.NewWireGuardPubKey
+
+	// Only commit the new identity once the VPN accepts it, so a failed
+	// swap leaves the node reachable under its old key
+	if err := s.swapVPNPeer(old, &node); err != nil {
+		s.mu.Unlock()
+		log.Printf("Error rotating VPN peer for %s: %v", shortID(old.NodeID), err)
+		http.Error(w, "Failed to update VPN configuration", http.StatusInternalServerError)
+		return
+	}
+	if err := s.registry.Rotate(old.NodeID, node.NodeID, node.WireGuardPubKey, now); err != nil {
+		// Regenerate from the registry so the VPN matches it again
+		if err := s.syncVPN(); err != nil {
+			log.Printf("Error restoring VPN peers: %v", err)
+		}
+		s.mu.Unlock()
+		log.Printf("Error rotating node %s: %v", shortID(old.NodeID), err)
+		http.Error(w, "Failed to rotate node", http.StatusInternalServerError)
+		return
+	}
+	for i := range s.bans.Reports {
+		if s.bans.Reports[i].NodeID == old.NodeID {
+			s.bans.Reports[i].NodeID = node.NodeID
+		}
+	}
+	s.mu.Unlock()
+
+	s.saveBans()
+
+	log.Printf("Node %s rotated to %s (%s)", shortID(old.NodeID), shortID(node.NodeID), node.Hostname)
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":      "success",
+		"message":     "Node identity rotated",
+		"node_id":     node.NodeID,
+		"vpn_address": node.VPNAddress,
+	})
+}
+
+// swapVPNPeer regenerates wg0.conf with node's key in place of old's. The
+// config is applied in one step, so the old and new key are never both
+// accepted.
+func (s *AdminServer) swapVPNPeer(old, node *NodeInfo) error {
+	nodes, err := s.registry.List(StatusApproved)
+	if err != nil {
+		return err
+	}
+	for i := range nodes {
+		if nodes[i].NodeID == old.NodeID {
+			nodes[i] = *node
+		}
+	}
+	return s.writeVPNConfig(nodes)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SnapshotInfo describes a verified chain+state snapshot offered to new nodes
+type SnapshotInfo struct {
+	Height    uint64    `json:"height"`
+	BlockHash string    `json:"block_hash"`
+	StateRoot string    `json:"state_root"`
+	File      string    `json:"file,omitempty"`
+	URL       string    `json:"url"`
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SnapshotCatalog tracks published snapshots, newest last
+type SnapshotCatalog struct {
+	Snapshots []SnapshotInfo `json:"snapshots"`
+}
+
+func (s *AdminServer) catalogFile() string {
+	return filepath.Join(s.snapshotDir, "snapshots.json")
+}
+
+func (s *AdminServer) loadSnapshots() error {
+	data, err := ioutil.ReadFile(s.catalogFile())
+	if err != nil {
+		return err
+	}
+
+	s.snapshots = &SnapshotCatalog{}
+	return json.Unmarshal(data, s.snapshots)
+}
+
+func (s *AdminServer) saveSnapshots() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.snapshots, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.catalogFile(), data, 0644)
+}
+
+// latestSnapshot returns the highest published snapshot; callers must hold s.mu
+func (s *AdminServer) latestSnapshot() *SnapshotInfo {
+	if s.snapshots == nil || len(s.snapshots.Snapshots) == 0 {
+		return nil
+	}
+	latest := s.snapshots.Snapshots[len(s.snapshots.Snapshots)-1]
+	return &latest
+}
+
+// List published snapshots
+func (s *AdminServer) handleListSnapshots(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	json.NewEncoder(w).Encode(s.snapshots.Snapshots)
+}
+
+// Get the latest snapshot
+func (s *AdminServer) handleLatestSnapshot(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	latest := s.latestSnapshot()
+	s.mu.RUnlock()
+
+	if latest == nil {
+		http.Error(w, "No snapshots available", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(latest)
+}
+
+// Publish a snapshot, either a file in the snapshot directory or an external URL
+func (s *AdminServer) handlePublishSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var snap SnapshotInfo
+	if err := json.NewDecoder(r.Body).Decode(&snap); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if snap.BlockHash == "" || snap.StateRoot == "" {
+		http.Error(w, "block_hash and state_root required", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case snap.File != "":
+		// Hosted locally: checksum is computed here, never trusted from the caller
+		name := filepath.Base(snap.File)
+		sum, size, err := fileChecksum(filepath.Join(s.snapshotDir, name))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Snapshot file unreadable: %v", err), http.StatusBadRequest)
+			return
+		}
+		if snap.SHA256 != "" && snap.SHA256 != sum {
+			http.Error(w, "Checksum mismatch", http.StatusBadRequest)
+			return
+		}
+		snap.File = name
+		snap.SHA256 = sum
+		snap.Size = size
+		snap.URL = s.publicURL + "/snapshots/files/" + name
+	case snap.URL != "":
+		// Hosted elsewhere: the admin must vouch for the checksum
+		if len(snap.SHA256) != 64 {
+			http.Error(w, "sha256 required for external snapshots", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "file or url required", http.StatusBadRequest)
+		return
+	}
+
+	snap.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	s.snapshots.Snapshots = append(s.snapshots.Snapshots, snap)
+	sort.Slice(s.snapshots.Snapshots, func(i, j int) bool {
+		return s.snapshots.Snapshots[i].Height < s.snapshots.Snapshots[j].Height
+	})
+	s.mu.Unlock()
+
+	if err := s.saveSnapshots(); err != nil {
+		log.Printf("Error saving snapshot catalog: %v", err)
+	}
+
+	log.Printf("Snapshot published: height %d (%s)", snap.Height, snap.SHA256[:16])
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"message":  "Snapshot published",
+		"snapshot": snap,
+	})
+}
+
+// fileChecksum returns the hex SHA-256 and size of a file
+func fileChecksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,754 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Update statuses
+const (
+	UpdateRunning     = "running"
+	UpdateSucceeded   = "succeeded"
+	UpdateFailed      = "failed" // did not complete; installed artifacts were rolled back
+	UpdateRollingBack = "rolling_back"
+	UpdateRolledBack  = "rolled_back" // reverted by an operator after succeeding
+)
+
+// Update step statuses
+const (
+	StepPending    = "pending"
+	StepRunning    = "running"
+	StepDone       = "done"
+	StepFailed     = "failed"
+	StepRolledBack = "rolled_back"
+)
+
+// adminService is this server's own unit. It is restarted last, once the
+// update is recorded, since restarting it ends the update.
+const adminService = "gydschain-admin"
+
+// serviceSettle is how long a restarted service must stay up to count as
+// started
+const serviceSettle = 5 * time.Second
+
+var (
+	errUpdateRunning   = errors.New("an update is already running")
+	errNoReleaseKey    = errors.New("no release signing key configured")
+	errNoReleaseURL    = errors.New("no release manifest URL given or configured")
+	errBadSignature    = errors.New("release manifest signature does not verify")
+	errNothingToRevert = errors.New("no succeeded update to roll back")
+)
+
+// artifactName restricts artifact names to plain binary names, so a
+// manifest cannot write outside the install's bin directory
+var artifactName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// updateSchema records every update and rollback with its steps
+const updateSchema = `
+CREATE TABLE IF NOT EXISTS updates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version TEXT NOT NULL,
+    previous_version TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    manifest TEXT NOT NULL,
+    steps TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME
+);
+`
+
+// ReleaseArtifact is one binary of a release
+type ReleaseArtifact struct {
+	Name    string `json:"name"` // file name under the install's bin directory
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+	Service string `json:"service,omitempty"` // systemd unit restarted after installing
+}
+
+// Release is the payload of a signed release manifest. Artifacts are
+// installed and their services restarted in order.
+type Release struct {
+	Version   string            `json:"version"`
+	Published int64             `json:"published"`
+	Artifacts []ReleaseArtifact `json:"artifacts"`
+}
+
+// SignedRelease carries a release manifest together with an ed25519
+// signature over the exact payload bytes, as bootstrap lists are signed
+type SignedRelease struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+}
+
+// UpdateStep is one stage of an update and how far it got
+type UpdateStep struct {
+	Name   string    `json:"name"`
+	Status string    `json:"status"`
+	Detail string    `json:"detail,omitempty"`
+	At     time.Time `json:"at,omitempty"`
+}
+
+// UpdateRecord is an update's progress and outcome
+type UpdateRecord struct {
+	ID         int64        `json:"id"`
+	Version    string       `json:"version"`
+	Previous   string       `json:"previous_version,omitempty"`
+	Status     string       `json:"status"`
+	Actor      string       `json:"actor"`
+	Error      string       `json:"error,omitempty"`
+	Steps      []UpdateStep `json:"steps"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at,omitempty"`
+
+	release Release
+}
+
+// UpdateManager installs signed releases: it verifies the manifest,
+// downloads and checksums every artifact before touching the install,
+// then replaces binaries and restarts their services one at a time,
+// rolling all of them back if any service fails to come up. Replaced
+// binaries are kept so an operator can revert a completed update.
+type UpdateManager struct {
+	mu         sync.Mutex
+	db         *sql.DB
+	client     *http.Client
+	releaseURL string
+	releaseKey ed25519.PublicKey
+	installDir string
+	current    *UpdateRecord // the update or rollback in progress
+	onFailure  func(message string, err error)
+}
+
+// NewUpdateManager creates an update manager recording into db. Updates
+// that were running when the server stopped are marked failed.
+func NewUpdateManager(db *sql.DB, installDir, releaseURL, releaseKey string) (*UpdateManager, error) {
+	m := &UpdateManager{
+		db:         db,
+		client:     &http.Client{Timeout: 10 * time.Minute},
+		releaseURL: releaseURL,
+		installDir: installDir,
+	}
+	if releaseKey != "" {
+		key, err := hex.DecodeString(releaseKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid release key %q", releaseKey)
+		}
+		m.releaseKey = key
+	}
+	if _, err := db.Exec(updateSchema); err != nil {
+		return nil, fmt.Errorf("create update schema: %w", err)
+	}
+	_, err := db.Exec(
+		"UPDATE updates SET status = ?, error = ?, finished_at = ? WHERE status IN (?, ?)",
+		UpdateFailed, "interrupted by an admin server restart", time.Now(), UpdateRunning, UpdateRollingBack,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start verifies the release manifest at manifestURL, or the configured
+// one if empty, and installs it in the background
+func (m *UpdateManager) Start(manifestURL, actor string) (*UpdateRecord, error) {
+	if manifestURL == "" {
+		manifestURL = m.releaseURL
+	}
+	if manifestURL == "" {
+		return nil, errNoReleaseURL
+	}
+	release, err := m.fetchRelease(manifestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current != nil {
+		return nil, errUpdateRunning
+	}
+
+	rec := &UpdateRecord{
+		Version:   release.Version,
+		Previous:  m.installedVersion(),
+		Status:    UpdateRunning,
+		Actor:     actor,
+		StartedAt: time.Now(),
+		release:   *release,
+	}
+	for _, a := range release.Artifacts {
+		rec.Steps = append(rec.Steps, UpdateStep{Name: "download " + a.Name, Status: StepPending})
+	}
+	for _, a := range release.Artifacts {
+		rec.Steps = append(rec.Steps, UpdateStep{Name: "install " + a.Name, Status: StepPending})
+	}
+	if err := m.insert(rec); err != nil {
+		return nil, err
+	}
+	m.current = rec
+
+	go m.run(rec)
+	return m.snapshot(rec), nil
+}
+
+// Rollback restores the binaries the last succeeded update replaced and
+// restarts their services in the background
+func (m *UpdateManager) Rollback(actor string) (*UpdateRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current != nil {
+		return nil, errUpdateRunning
+	}
+
+	rec, err := m.lastSucceeded()
+	if err != nil {
+		return nil, err
+	}
+	rec.Status = UpdateRollingBack
+	rec.Steps = append(rec.Steps, UpdateStep{
+		Name:   "rollback requested",
+		Status: StepDone,
+		Detail: "by " + actor,
+		At:     time.Now(),
+	})
+	if err := m.save(rec); err != nil {
+		return nil, err
+	}
+	m.current = rec
+
+	go m.revert(rec)
+	return m.snapshot(rec), nil
+}
+
+// Current returns the update in progress, or nil
+func (m *UpdateManager) Current() *UpdateRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current == nil {
+		return nil
+	}
+	return m.snapshot(m.current)
+}
+
+// History returns the most recent updates, newest first
+func (m *UpdateManager) History(limit int) ([]UpdateRecord, error) {
+	return m.query("ORDER BY id DESC LIMIT ?", limit)
+}
+
+// fetchRelease downloads a signed manifest and verifies it against the
+// pinned release key
+func (m *UpdateManager) fetchRelease(url string) (*Release, error) {
+	if m.releaseKey == nil {
+		return nil, errNoReleaseKey
+	}
+	resp, err := m.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release manifest unavailable: %s", resp.Status)
+	}
+
+	var signed SignedRelease
+	if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil {
+		return nil, fmt.Errorf("invalid release manifest: %w", err)
+	}
+	signature, err := hex.DecodeString(signed.Signature)
+	if err != nil || !ed25519.Verify(m.releaseKey, signed.Payload, signature) {
+		return nil, errBadSignature
+	}
+
+	var release Release
+	if err := json.Unmarshal(signed.Payload, &release); err != nil {
+		return nil, fmt.Errorf("invalid release manifest: %w", err)
+	}
+	if release.Version == "" || len(release.Artifacts) == 0 {
+		return nil, errors.New("release manifest names no version or artifacts")
+	}
+	for _, a := range release.Artifacts {
+		if !artifactName.MatchString(a.Name) {
+			return nil, fmt.Errorf("invalid artifact name %q", a.Name)
+		}
+		if len(a.SHA256) != 2*sha256.Size {
+			return nil, fmt.Errorf("artifact %s has no valid sha256", a.Name)
+		}
+	}
+	return &release, nil
+}
+
+// run downloads every artifact, then installs them one service at a time
+func (m *UpdateManager) run(rec *UpdateRecord) {
+	artifacts := rec.release.Artifacts
+	stageDir := filepath.Join(m.installDir, "releases", rec.Version)
+	backupDir := m.backupDir(rec.ID)
+
+	for i, a := range artifacts {
+		m.step(rec, i, StepRunning, a.URL)
+		if err := m.download(a, filepath.Join(stageDir, a.Name)); err != nil {
+			m.step(rec, i, StepFailed, err.Error())
+			m.finish(rec, fmt.Errorf("download %s: %w", a.Name, err))
+			return
+		}
+		m.step(rec, i, StepDone, "sha256 verified")
+	}
+
+	var (
+		installed    []ReleaseArtifact
+		restartAdmin bool
+	)
+	for i, a := range artifacts {
+		step := len(artifacts) + i
+		m.step(rec, step, StepRunning, "")
+		if err := m.install(filepath.Join(stageDir, a.Name), a.Name, backupDir); err != nil {
+			m.step(rec, step, StepFailed, err.Error())
+			m.rollbackInstalled(rec, installed, backupDir)
+			m.finish(rec, fmt.Errorf("install %s: %w", a.Name, err))
+			return
+		}
+		installed = append(installed, a)
+
+		switch a.Service {
+		case "":
+			m.step(rec, step, StepDone, "installed")
+		case adminService:
+			restartAdmin = true
+			m.step(rec, step, StepDone, "installed; "+adminService+" restarts when the update completes")
+		default:
+			if err := restartService(a.Service); err != nil {
+				m.step(rec, step, StepFailed, err.Error())
+				m.rollbackInstalled(rec, installed, backupDir)
+				m.finish(rec, fmt.Errorf("restart %s: %w", a.Service, err))
+				return
+			}
+			m.step(rec, step, StepDone, a.Service+" restarted")
+		}
+	}
+
+	if err := ioutil.WriteFile(m.versionFile(), []byte(rec.Version+"\n"), 0644); err != nil {
+		log.Printf("Update %d: recording version: %v", rec.ID, err)
+	}
+	os.RemoveAll(stageDir)
+	m.finish(rec, nil)
+
+	if restartAdmin {
+		log.Printf("Update %d: restarting %s", rec.ID, adminService)
+		if err := exec.Command("systemctl", "restart", adminService).Run(); err != nil {
+			log.Printf("Update %d: restarting %s: %v", rec.ID, adminService, err)
+		}
+	}
+}
+
+// revert restores the binaries an update replaced, newest first
+func (m *UpdateManager) revert(rec *UpdateRecord) {
+	backupDir := m.backupDir(rec.ID)
+	artifacts := rec.release.Artifacts
+
+	var (
+		failed       error
+		restartAdmin bool
+	)
+	for i := len(artifacts) - 1; i >= 0; i-- {
+		if err := m.restore(artifacts[i], backupDir); err != nil && failed == nil {
+			failed = err
+		}
+		restartAdmin = restartAdmin || artifacts[i].Service == adminService
+	}
+
+	m.mu.Lock()
+	rec.FinishedAt = time.Now()
+	if failed != nil {
+		rec.Status = UpdateFailed
+		rec.Error = "rollback: " + failed.Error()
+	} else {
+		rec.Status = UpdateRolledBack
+		if err := ioutil.WriteFile(m.versionFile(), []byte(rec.Previous+"\n"), 0644); err != nil {
+			log.Printf("Update %d: recording version: %v", rec.ID, err)
+		}
+	}
+	if err := m.save(rec); err != nil {
+		log.Printf("Update %d: %v", rec.ID, err)
+	}
+	m.current = nil
+	m.mu.Unlock()
+
+	if failed != nil {
+		log.Printf("Rollback of update %d to %s failed: %v", rec.ID, rec.Previous, failed)
+		m.failure("Rollback to "+rec.Previous+" failed", failed)
+	} else {
+		log.Printf("Update %d rolled back to %s", rec.ID, rec.Previous)
+	}
+	if restartAdmin {
+		if err := exec.Command("systemctl", "restart", adminService).Run(); err != nil {
+			log.Printf("Update %d: restarting %s: %v", rec.ID, adminService, err)
+		}
+	}
+}
+
+// rollbackInstalled restores the artifacts a failed update installed,
+// newest first
+func (m *UpdateManager) rollbackInstalled(rec *UpdateRecord, installed []ReleaseArtifact, backupDir string) {
+	for i := len(installed) - 1; i >= 0; i-- {
+		if err := m.restore(installed[i], backupDir); err != nil {
+			log.Printf("Update %d: rolling back %s: %v", rec.ID, installed[i].Name, err)
+			continue
+		}
+		m.step(rec, len(rec.release.Artifacts)+i, StepRolledBack, "previous binary restored")
+	}
+}
+
+// restore puts an artifact's backed-up binary back and restarts its
+// service, except this server's own, which callers restart once they are
+// done. Artifacts new in the release are removed instead.
+func (m *UpdateManager) restore(a ReleaseArtifact, backupDir string) error {
+	target := filepath.Join(m.installDir, "bin", a.Name)
+	backup := filepath.Join(backupDir, a.Name)
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else if err := replaceFile(backup, target); err != nil {
+		return err
+	}
+
+	if a.Service == "" || a.Service == adminService {
+		return nil
+	}
+	return restartService(a.Service)
+}
+
+// download fetches an artifact to path, failing unless its SHA-256
+// matches the manifest
+func (m *UpdateManager) download(a ReleaseArtifact, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	resp, err := m.client.Get(a.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("artifact unavailable: %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, a.SHA256) {
+		os.Remove(path)
+		return fmt.Errorf("sha256 mismatch: got %s, manifest has %s", sum, a.SHA256)
+	}
+	return nil
+}
+
+// install backs up the current binary, if any, and replaces it with the
+// staged one
+func (m *UpdateManager) install(staged, name, backupDir string) error {
+	target := filepath.Join(m.installDir, "bin", name)
+	if _, err := os.Stat(target); err == nil {
+		if err := os.MkdirAll(backupDir, 0755); err != nil {
+			return err
+		}
+		if err := replaceFile(target, filepath.Join(backupDir, name)); err != nil {
+			return fmt.Errorf("back up: %w", err)
+		}
+	}
+	return replaceFile(staged, target)
+}
+
+// replaceFile copies src over dst through a temporary file renamed into
+// place, so a running binary is never half written
+func replaceFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// restartService restarts a unit and checks it is still active once it
+// has had time to settle
+func restartService(service string) error {
+	if output, err := exec.Command("systemctl", "restart", service).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	time.Sleep(serviceSettle)
+	if err := exec.Command("systemctl", "is-active", "--quiet", service).Run(); err != nil {
+		return fmt.Errorf("%s is not active after restart", service)
+	}
+	return nil
+}
+
+// step updates a step's status and saves the record
+func (m *UpdateManager) step(rec *UpdateRecord, i int, status, detail string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec.Steps[i].Status = status
+	rec.Steps[i].Detail = detail
+	rec.Steps[i].At = time.Now()
+	if err := m.save(rec); err != nil {
+		log.Printf("Update %d: %v", rec.ID, err)
+	}
+}
+
+// finish records an update's outcome and lets the next one start
+func (m *UpdateManager) finish(rec *UpdateRecord, err error) {
+	m.mu.Lock()
+	rec.FinishedAt = time.Now()
+	rec.Status = UpdateSucceeded
+	if err != nil {
+		rec.Status = UpdateFailed
+		rec.Error = err.Error()
+	}
+	if saveErr := m.save(rec); saveErr != nil {
+		log.Printf("Update %d: %v", rec.ID, saveErr)
+	}
+	m.current = nil
+	m.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Update %d to %s failed: %v", rec.ID, rec.Version, err)
+		m.failure("Update to "+rec.Version+" failed", err)
+		return
+	}
+	log.Printf("Update %d: installed %s", rec.ID, rec.Version)
+}
+
+func (m *UpdateManager) failure(message string, err error) {
+	if m.onFailure != nil {
+		m.onFailure(message, err)
+	}
+}
+
+// snapshot copies a record for callers outside the lock; callers must
+// hold m.mu
+func (m *UpdateManager) snapshot(rec *UpdateRecord) *UpdateRecord {
+	c := *rec
+	c.Steps = append([]UpdateStep(nil), rec.Steps...)
+	return &c
+}
+
+func (m *UpdateManager) backupDir(id int64) string {
+	return filepath.Join(m.installDir, "releases", "backup", fmt.Sprint(id))
+}
+
+func (m *UpdateManager) versionFile() string {
+	return filepath.Join(m.installDir, "VERSION")
+}
+
+// installedVersion returns the version the last update installed
+func (m *UpdateManager) installedVersion() string {
+	data, err := ioutil.ReadFile(m.versionFile())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func (m *UpdateManager) insert(rec *UpdateRecord) error {
+	manifest, err := json.Marshal(rec.release)
+	if err != nil {
+		return err
+	}
+	steps, err := json.Marshal(rec.Steps)
+	if err != nil {
+		return err
+	}
+	res, err := m.db.Exec(`
+		INSERT INTO updates (version, previous_version, status, actor, manifest, steps, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rec.Version, rec.Previous, rec.Status, rec.Actor, string(manifest), string(steps), rec.StartedAt)
+	if err != nil {
+		return err
+	}
+	rec.ID, err = res.LastInsertId()
+	return err
+}
+
+// save writes a record's progress; callers must hold m.mu
+func (m *UpdateManager) save(rec *UpdateRecord) error {
+	steps, err := json.Marshal(rec.Steps)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(
+		"UPDATE updates SET status = ?, error = ?, steps = ?, finished_at = ? WHERE id = ?",
+		rec.Status, rec.Error, string(steps),
+		sql.NullTime{Time: rec.FinishedAt, Valid: !rec.FinishedAt.IsZero()}, rec.ID,
+	)
+	return err
+}
+
+// lastSucceeded returns the newest update if it succeeded; only the
+// latest install can be reverted
+func (m *UpdateManager) lastSucceeded() (*UpdateRecord, error) {
+	recs, err := m.query("WHERE status IN (?, ?) ORDER BY id DESC LIMIT 1", UpdateSucceeded, UpdateRolledBack)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 || recs[0].Status != UpdateSucceeded {
+		return nil, errNothingToRevert
+	}
+	rec := recs[0]
+	rec.FinishedAt = time.Time{}
+	return &rec, nil
+}
+
+func (m *UpdateManager) query(clause string, args ...interface{}) ([]UpdateRecord, error) {
+	rows, err := m.db.Query(`
+		SELECT id, version, previous_version, status, actor, error, manifest, steps, started_at, finished_at
+		FROM updates
+		`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recs := []UpdateRecord{}
+	for rows.Next() {
+		var (
+			rec             UpdateRecord
+			manifest, steps string
+			finished        sql.NullTime
+		)
+		if err := rows.Scan(&rec.ID, &rec.Version, &rec.Previous, &rec.Status, &rec.Actor, &rec.Error,
+			&manifest, &steps, &rec.StartedAt, &finished); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(manifest), &rec.release); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(steps), &rec.Steps); err != nil {
+			return nil, err
+		}
+		rec.FinishedAt = finished.Time
+		recs = append(recs, rec)
+	}
+	return recs, rows.Err()
+}
+
+// updateError writes the response for an update that could not start
+func updateError(w http.ResponseWriter, err error) {
+	switch err {
+	case errUpdateRunning, errNothingToRevert:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errNoReleaseKey, errNoReleaseURL:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errBadSignature:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+// Report the update in progress and recent update history, or start an
+// update from a signed release manifest
+func (s *AdminServer) handleUpdates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		history, err := s.updates.History(20)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"installed_version": s.updates.installedVersion(),
+			"current":           s.updates.Current(),
+			"history":           history,
+		})
+
+	case http.MethodPost:
+		if !hasRole(r, RoleAdmin) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		s.startUpdate(w, r)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Start an update; the body may name a manifest URL other than the
+// configured one
+func (s *AdminServer) startUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ManifestURL string `json:"manifest_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := s.updates.Start(req.ManifestURL, actor(r))
+	if err != nil {
+		log.Printf("Update not started: %v", err)
+		updateError(w, err)
+		return
+	}
+	log.Printf("Update %d to %s started by %s", rec.ID, rec.Version, actor(r))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(rec)
+}
+
+// Roll back the last succeeded update
+func (s *AdminServer) handleUpdateRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rec, err := s.updates.Rollback(actor(r))
+	if err != nil {
+		updateError(w, err)
+		return
+	}
+	log.Printf("Rollback of update %d to %s started by %s", rec.ID, rec.Previous, actor(r))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(rec)
+}
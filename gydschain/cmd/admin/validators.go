@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// NodeTypeValidator is the node type that is onboarded as a validator
+// when approved
+const NodeTypeValidator = "validator"
+
+// DefaultMinStake is the node's default minimum validator stake, as in
+// its validator config
+const DefaultMinStake = "10000000000000000000000"
+
+var (
+	errNoNodeRPC         = errors.New("no node RPC configured for validator onboarding")
+	errInsufficientStake = errors.New("operator address holds less than the minimum stake")
+	errNoRegistrationTx  = errors.New("registration_tx required for validator nodes")
+)
+
+// validatorFingerprint identifies a validator key in the registry and in
+// logs: the first 16 bytes of the key's SHA-256, in hex
+func validatorFingerprint(pubKey string) (string, error) {
+	key, err := crypto.ParsePublicKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// checkValidatorRegistration validates the operator address and key a
+// validator node registers with and fills in the key's fingerprint. The
+// node relays its registration transaction, so it must send one.
+func checkValidatorRegistration(node *NodeInfo) error {
+	if err := crypto.ValidateAddress(node.OperatorAddress); err != nil {
+		return fmt.Errorf("invalid operator_address: %w", err)
+	}
+	fingerprint, err := validatorFingerprint(node.ValidatorPubKey)
+	if err != nil {
+		return fmt.Errorf("invalid validator_pub_key: %w", err)
+	}
+	if node.RegistrationTx == "" {
+		return errNoRegistrationTx
+	}
+	node.ValidatorKeyFingerprint = fingerprint
+	return nil
+}
+
+// onboardValidator verifies on chain that a validator node's operator
+// address holds the minimum stake and submits the operator's signed
+// registration, which bonds the stake when a block includes it. The node
+// makes registration idempotent, so approving again after a failure later
+// in the approval is safe.
+func (s *AdminServer) onboardValidator(node *NodeInfo) error {
+	if s.nodeRPC == nil {
+		return errNoNodeRPC
+	}
+
+	balance, err := s.nodeRPC.GetBalance(node.OperatorAddress, "GYDS")
+	if err != nil {
+		return fmt.Errorf("query operator balance: %w", err)
+	}
+	if balance.Cmp(s.minStake) < 0 {
+		return fmt.Errorf("%w: %s has %s, needs %s", errInsufficientStake, node.OperatorAddress, balance, s.minStake)
+	}
+
+	if _, err := s.nodeRPC.RegisterValidator(node.OperatorAddress, node.ValidatorPubKey, node.RegistrationTx); err != nil {
+		var rpcErr *rpc.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == rpc.ErrMinimumStake {
+			return fmt.Errorf("%w: %s", errInsufficientStake, rpcErr.Message)
+		}
+		return fmt.Errorf("register validator: %w", err)
+	}
+	return nil
+}
+
+// parseMinStake parses the -min-stake flag
+func parseMinStake(value string) (*big.Int, error) {
+	stake, ok := new(big.Int).SetString(value, 10)
+	if !ok || stake.Sign() < 0 {
+		return nil, fmt.Errorf("invalid minimum stake %q", value)
+	}
+	return stake, nil
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// vpnInterface is the WireGuard interface approved nodes are peers of
+const vpnInterface = "wg0"
+
+// peersMarker starts the generated part of wg0.conf. Everything above it
+// (the [Interface] section) is kept as the operator wrote it.
+const peersMarker = "# --- Peers generated by gydschain-admin from the node registry; edits below are overwritten ---"
+
+// peerTemplate renders one [Peer] section per approved node. Each peer is
+// routed only its own address.
+var peerTemplate = template.Must(template.New("peers").Funcs(template.FuncMap{
+	"shortID":   shortID,
+	"hostRoute": hostRoute,
+}).Parse(`{{range .}}
+# Node: {{shortID .NodeID}} ({{.Hostname}})
+[Peer]
+PublicKey = {{.WireGuardPubKey}}
+AllowedIPs = {{hostRoute .VPNAddress}}
+{{end}}`))
+
+// VPNPeer is a WireGuard peer as the registry, wg0.conf or the live
+// interface sees it
+type VPNPeer struct {
+	PublicKey  string `json:"public_key"`
+	AllowedIPs string `json:"allowed_ips"`
+	NodeID     string `json:"node_id,omitempty"`
+}
+
+// VPNDrift compares the peers approved nodes should have with the peers
+// one source actually has
+type VPNDrift struct {
+	InSync     bool      `json:"in_sync"`
+	Missing    []VPNPeer `json:"missing"`    // approved nodes without a peer
+	Stale      []VPNPeer `json:"stale"`      // peers of no approved node
+	Mismatched []VPNPeer `json:"mismatched"` // peers routed the wrong address
+	Error      string    `json:"error,omitempty"`
+}
+
+// hostRoute returns the single-address route of a node's VPN address
+func hostRoute(address string) string {
+	return stripPrefix(address) + "/32"
+}
+
+// vpnConfigPath returns the path of the server's wg0.conf
+func (s *AdminServer) vpnConfigPath() string {
+	return s.vpnConfigDir + "/" + vpnInterface + ".conf"
+}
+
+// syncVPN regenerates wg0.conf from the approved nodes and applies it
+func (s *AdminServer) syncVPN() error {
+	nodes, err := s.registry.List(StatusApproved)
+	if err != nil {
+		return err
+	}
+	return s.writeVPNConfig(nodes)
+}
+
+// writeVPNConfig replaces the peers in wg0.conf with nodes and loads the
+// result into the interface. The file is written beside the old one and
+// renamed over it, so a crash never leaves a half-written config.
+func (s *AdminServer) writeVPNConfig(nodes []NodeInfo) error {
+	s.vpnMu.Lock()
+	defer s.vpnMu.Unlock()
+
+	path := s.vpnConfigPath()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var peers []NodeInfo
+	for _, node := range nodes {
+		if node.WireGuardPubKey == "" || node.VPNAddress == "" {
+			log.Printf("Skipping VPN peer for %s: no WireGuard key or address", shortID(node.NodeID))
+			continue
+		}
+		peers = append(peers, node)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(strings.TrimRight(interfaceSection(string(data)), "\n"))
+	buf.WriteString("\n\n" + peersMarker + "\n")
+	if err := peerTemplate.Execute(&buf, peers); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return applyVPNConfig(path)
+}
+
+// applyVPNConfig loads a wg-quick config into the running interface
+// without dropping the sessions of unchanged peers
+func applyVPNConfig(path string) error {
+	stripped, err := exec.Command("wg-quick", "strip", path).Output()
+	if err != nil {
+		return fmt.Errorf("wg-quick strip: %w", err)
+	}
+	cmd := exec.Command("wg", "syncconf", vpnInterface, "/dev/stdin")
+	cmd.Stdin = bytes.NewReader(stripped)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("wg syncconf: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// interfaceSection returns the part of a config before its peers: up to
+// the generated-peers marker, or for configs written before it existed,
+// up to the first peer
+func interfaceSection(config string) string {
+	if i := strings.Index(config, peersMarker); i >= 0 {
+		return config[:i]
+	}
+	lines := strings.Split(config, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "[Peer]" || strings.HasPrefix(trimmed, "# Node:") {
+			return strings.Join(lines[:i], "\n")
+		}
+	}
+	return config
+}
+
+// configPeers parses the [Peer] sections of a wg0.conf
+func configPeers(config string) map[string]string {
+	peers := make(map[string]string)
+	inPeer := false
+	key := ""
+	scanner := bufio.NewScanner(strings.NewReader(config))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inPeer = line == "[Peer]"
+			key = ""
+			continue
+		}
+		if !inPeer {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "PublicKey":
+			key = strings.TrimSpace(value)
+			if _, seen := peers[key]; !seen {
+				peers[key] = ""
+			}
+		case "AllowedIPs":
+			if key != "" {
+				peers[key] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return peers
+}
+
+// interfacePeers reads the peers loaded into the running interface
+func interfacePeers() (map[string]string, error) {
+	output, err := exec.Command("wg", "show", vpnInterface, "dump").Output()
+	if err != nil {
+		return nil, fmt.Errorf("wg show: %w", err)
+	}
+	peers := make(map[string]string)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	// The first line describes the interface itself
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) >= 4 {
+			peers[fields[0]] = fields[3]
+		}
+	}
+	return peers, nil
+}
+
+// diffPeers compares the peers approved nodes need with the peers a
+// source has
+func diffPeers(nodes []NodeInfo, have map[string]string) VPNDrift {
+	drift := VPNDrift{Missing: []VPNPeer{}, Stale: []VPNPeer{}, Mismatched: []VPNPeer{}}
+	want := make(map[string]bool)
+	for _, node := range nodes {
+		if node.WireGuardPubKey == "" || node.VPNAddress == "" {
+			continue
+		}
+		want[node.WireGuardPubKey] = true
+		peer := VPNPeer{PublicKey: node.WireGuardPubKey, AllowedIPs: hostRoute(node.VPNAddress), NodeID: node.NodeID}
+		allowed, ok := have[node.WireGuardPubKey]
+		switch {
+		case !ok:
+			drift.Missing = append(drift.Missing, peer)
+		case allowed != peer.AllowedIPs:
+			drift.Mismatched = append(drift.Mismatched, VPNPeer{PublicKey: peer.PublicKey, AllowedIPs: allowed, NodeID: node.NodeID})
+		}
+	}
+	for key, allowed := range have {
+		if !want[key] {
+			drift.Stale = append(drift.Stale, VPNPeer{PublicKey: key, AllowedIPs: allowed})
+		}
+	}
+	sort.Slice(drift.Stale, func(i, j int) bool { return drift.Stale[i].PublicKey < drift.Stale[j].PublicKey })
+
+	drift.InSync = len(drift.Missing) == 0 && len(drift.Stale) == 0 && len(drift.Mismatched) == 0
+	return drift
+}
+
+// vpnDrift compares the registry with wg0.conf and with the live interface
+func (s *AdminServer) vpnDrift() (map[string]VPNDrift, error) {
+	nodes, err := s.registry.List(StatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make(map[string]VPNDrift)
+	if data, err := ioutil.ReadFile(s.vpnConfigPath()); err != nil {
+		report["config"] = VPNDrift{Error: err.Error()}
+	} else {
+		report["config"] = diffPeers(nodes, configPeers(string(data)))
+	}
+	if live, err := interfacePeers(); err != nil {
+		report["interface"] = VPNDrift{Error: err.Error()}
+	} else {
+		report["interface"] = diffPeers(nodes, live)
+	}
+	return report, nil
+}
+
+// Report drift between the registry, wg0.conf and the live interface
+func (s *AdminServer) handleVPNDrift(w http.ResponseWriter, r *http.Request) {
+	report, err := s.vpnDrift()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Regenerate wg0.conf from the registry and reload the interface,
+// reporting the drift that was fixed
+func (s *AdminServer) handleVPNReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	before, err := s.vpnDrift()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.syncVPN(); err != nil {
+		log.Printf("VPN reconcile failed: %v", err)
+		http.Error(w, "Failed to regenerate VPN configuration: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	after, err := s.vpnDrift()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("VPN reconciled by %s", actor(r))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"before": before,
+		"after":  after,
+	})
+}
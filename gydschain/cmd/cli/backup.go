@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// readSecret prompts for a secret without echoing it when stdin is a
+// terminal; piped input is read as is
+func readSecret(prompt string) string {
+	fmt.Print(prompt)
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Println()
+		}()
+	}
+	line, _ := stdin.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// promptPassphrase asks for the wallet passphrase (the "25th word"). New
+// wallets ask twice so a typo doesn't lock the funds away.
+func promptPassphrase(confirmIt bool) (string, error) {
+	passphrase := readSecret("Passphrase: ")
+	if confirmIt && readSecret("Repeat passphrase: ") != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// parseSharePolicy parses a Shamir backup policy: a comma-separated list of
+// "T-of-N" groups. With more than one group, groupThreshold of them must be
+// recovered; 0 means all of them.
+func parseSharePolicy(policy string, groupThreshold int) (int, []crypto.ShareGroup, error) {
+	var groups []crypto.ShareGroup
+	for _, part := range strings.Split(policy, ",") {
+		fields := strings.SplitN(strings.TrimSpace(part), "-of-", 2)
+		if len(fields) != 2 {
+			return 0, nil, fmt.Errorf("invalid share group %q, want T-of-N", part)
+		}
+		threshold, err1 := strconv.Atoi(fields[0])
+		count, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			return 0, nil, fmt.Errorf("invalid share group %q, want T-of-N", part)
+		}
+		groups = append(groups, crypto.ShareGroup{Threshold: threshold, Count: count})
+	}
+	if groupThreshold == 0 {
+		groupThreshold = len(groups)
+	}
+	return groupThreshold, groups, nil
+}
+
+// writeShares prints the share mnemonics, or saves them to output with one
+// share per line and a blank line between groups
+func writeShares(groups [][]string, groupThreshold int, output string) error {
+	if output != "" {
+		var b strings.Builder
+		for i, shares := range groups {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			for _, share := range shares {
+				b.WriteString(share + "\n")
+			}
+		}
+		if err := os.WriteFile(output, []byte(b.String()), 0600); err != nil {
+			return err
+		}
+		fmt.Printf("🔐 Wrote %d share groups to %s\n", len(groups), output)
+		fmt.Println("   Hand each share to a different trustee and delete this file")
+		return nil
+	}
+
+	fmt.Printf("🔐 Shamir backup: any %d of %d groups recover the wallet\n", groupThreshold, len(groups))
+	for i, shares := range groups {
+		for j, share := range shares {
+			fmt.Printf("\n   Group %d, share %d:\n   %s\n", i+1, j+1, share)
+		}
+	}
+	return nil
+}
+
+// readShares loads share mnemonics from file, one per line, or when file is
+// empty prompts for them until the wallet mnemonic can be recovered
+func readShares(file string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		var shares []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				shares = append(shares, line)
+			}
+		}
+		return crypto.CombineMnemonic(shares)
+	}
+
+	var shares []string
+	for {
+		share := readSecret(fmt.Sprintf("Share %d: ", len(shares)+1))
+		if strings.TrimSpace(share) == "" {
+			return "", crypto.ErrInsufficientShares
+		}
+		shares = append(shares, share)
+
+		mnemonic, err := crypto.CombineMnemonic(shares)
+		if err == nil {
+			return mnemonic, nil
+		}
+		switch err {
+		case crypto.ErrInsufficientShares:
+		case crypto.ErrInvalidShare, crypto.ErrShareChecksum, crypto.ErrShareMismatch:
+			fmt.Printf("   %v, enter it again\n", err)
+			shares = shares[:len(shares)-1]
+		default:
+			return "", err
+		}
+	}
+}
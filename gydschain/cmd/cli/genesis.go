@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Genesis templates init starts from
+const (
+	templateMainnet = "mainnet" // production parameters, no accounts or validators
+	templateDevnet  = "devnet"  // fast blocks and short unbonding for local networks
+)
+
+// GenTx is a validator's signed request to join the genesis validator
+// set. Each validator signs one with its own key and hands it to whoever
+// assembles the genesis, who can check it without the key.
+type GenTx struct {
+	ChainID   string                `json:"chain_id"`
+	Validator chain.ValidatorConfig `json:"validator"`
+	Signature string                `json:"signature"` // message signature over the chain ID and validator
+}
+
+// signedBytes returns what a gentx signature covers
+func (g *GenTx) signedBytes() ([]byte, error) {
+	return json.Marshal(struct {
+		ChainID   string                `json:"chain_id"`
+		Validator chain.ValidatorConfig `json:"validator"`
+	}{g.ChainID, g.Validator})
+}
+
+// verify checks a gentx was signed for chainID by its validator's key
+func (g *GenTx) verify(chainID string) error {
+	if g.ChainID != chainID {
+		return fmt.Errorf("gentx is for chain %q, genesis is %q", g.ChainID, chainID)
+	}
+	pubKey, err := crypto.ParsePublicKey(g.Validator.PubKey)
+	if err != nil {
+		return fmt.Errorf("invalid pub_key: %w", err)
+	}
+	if crypto.DeriveAddress(pubKey) != g.Validator.Address {
+		return fmt.Errorf("pub_key does not belong to %s", g.Validator.Address)
+	}
+	data, err := g.signedBytes()
+	if err != nil {
+		return err
+	}
+	return crypto.VerifyMessage(g.Validator.Address, data, g.Signature)
+}
+
+// genesisCmd assembles a genesis file: init it from a template, add
+// allocations and validator gentxs, then validate it and compute its hash
+// before handing it to node operators
+func genesisCmd() {
+	genesisFlags := flag.NewFlagSet("genesis", flag.ExitOnError)
+	action := genesisFlags.String("action", "", "Action: init, add-account, gentx, add-validator, validate, hash")
+	file := genesisFlags.String("genesis", "genesis.json", "Genesis file")
+	template := genesisFlags.String("template", templateMainnet, "Template for init: mainnet or devnet")
+	chainID := genesisFlags.String("chain-id", "", "Chain ID (default the template's)")
+	force := genesisFlags.Bool("force", false, "Overwrite an existing genesis file on init")
+	address := genesisFlags.String("address", "", "Account address")
+	gyds := genesisFlags.String("gyds", "0", "GYDS allocation, in base units")
+	gyd := genesisFlags.String("gyd", "0", "GYD allocation, in base units")
+	key := genesisFlags.String("key", "", "Hex validator private key to sign the gentx with")
+	name := genesisFlags.String("name", "", "Keystore wallet to sign the gentx with instead of --key")
+	keystore := genesisFlags.String("keystore", defaultKeystoreDir(), "Directory of encrypted wallet keys")
+	moniker := genesisFlags.String("moniker", "", "Validator name")
+	description := genesisFlags.String("description", "", "Validator description")
+	power := genesisFlags.Uint64("power", 1, "Validator voting power")
+	gentxs := genesisFlags.String("gentx", "", "Comma-separated gentx files to add")
+	output := genesisFlags.String("output", "", "File to write the gentx to instead of stdout")
+
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: gydscli genesis --action <init|add-account|gentx|add-validator|validate|hash> [options]")
+		return
+	}
+
+	genesisFlags.Parse(os.Args[2:])
+
+	switch *action {
+	case "init":
+		initGenesis(*file, *template, *chainID, *force)
+	case "add-account":
+		addGenesisAccount(*file, *address, *gyds, *gyd)
+	case "gentx":
+		createGenTx(*file, *key, *keystore, *name, *moniker, *description, *power, *output)
+	case "add-validator":
+		addGenesisValidators(*file, splitList(*gentxs))
+	case "validate":
+		validateGenesisFile(*file)
+	case "hash":
+		genesisHash(*file)
+	default:
+		fmt.Println("Unknown genesis action. Use: init, add-account, gentx, add-validator, validate, hash")
+	}
+}
+
+// genesisTemplate returns the genesis a template starts from. Templates
+// carry parameters only; accounts and validators are always added.
+func genesisTemplate(template string) (*chain.GenesisConfig, error) {
+	genesis := chain.DefaultGenesis()
+	genesis.Validators = []chain.ValidatorConfig{}
+	genesis.Alloc = []chain.AllocConfig{}
+
+	switch template {
+	case templateMainnet:
+	case templateDevnet:
+		genesis.ChainID = "gydschain-devnet"
+		genesis.Params.BlockTime = 1
+		genesis.Params.UnbondingTime = 60
+		genesis.Params.OracleUpdateFreq = 10
+		genesis.Params.MinStake = (*util.Big)(big.NewInt(1))
+	default:
+		return nil, fmt.Errorf("unknown template %q", template)
+	}
+	return genesis, nil
+}
+
+func initGenesis(file, template, chainID string, force bool) {
+	if _, err := os.Stat(file); err == nil && !force {
+		fmt.Printf("❌ %s already exists; use --force to overwrite it\n", file)
+		return
+	}
+	genesis, err := genesisTemplate(template)
+	if err != nil {
+		fmt.Printf("Error creating genesis: %v\n", err)
+		return
+	}
+	if chainID != "" {
+		genesis.ChainID = chainID
+	}
+	genesis.Timestamp = time.Now().Unix()
+	if err := genesis.Save(file); err != nil {
+		fmt.Printf("Error writing genesis: %v\n", err)
+		return
+	}
+	fmt.Printf("🌱 Genesis for %s written to %s (%s template)\n", genesis.ChainID, file, template)
+}
+
+func addGenesisAccount(file, address, gydsFlag, gydFlag string) {
+	genesis, err := chain.LoadGenesis(file)
+	if err != nil {
+		fmt.Printf("Error loading genesis: %v\n", err)
+		return
+	}
+	if err := crypto.ValidateAddress(address); err != nil {
+		fmt.Printf("Invalid --address: %v\n", err)
+		return
+	}
+	gydsAmount, err := util.ParseBig(gydsFlag)
+	if err != nil {
+		fmt.Printf("Invalid --gyds: %v\n", err)
+		return
+	}
+	gydAmount, err := util.ParseBig(gydFlag)
+	if err != nil {
+		fmt.Printf("Invalid --gyd: %v\n", err)
+		return
+	}
+	for _, alloc := range genesis.Alloc {
+		if alloc.Address == address {
+			fmt.Printf("❌ %s already has an allocation\n", address)
+			return
+		}
+	}
+
+	genesis.Alloc = append(genesis.Alloc, chain.AllocConfig{
+		Address:     address,
+		GYDSBalance: gydsAmount,
+		GYDBalance:  gydAmount,
+	})
+	if err := genesis.Save(file); err != nil {
+		fmt.Printf("Error writing genesis: %v\n", err)
+		return
+	}
+	fmt.Printf("💰 Allocated %s GYDS and %s GYD to %s\n", gydsAmount, gydAmount, address)
+}
+
+// createGenTx signs a gentx for the genesis's chain with a validator key
+// from --key or the keystore
+func createGenTx(file, key, keystore, name, moniker, description string, power uint64, output string) {
+	genesis, err := chain.LoadGenesis(file)
+	if err != nil {
+		fmt.Printf("Error loading genesis: %v\n", err)
+		return
+	}
+	if power == 0 {
+		fmt.Println("Invalid --power: must be positive")
+		return
+	}
+
+	var kp *crypto.KeyPair
+	switch {
+	case key != "":
+		privKey, err := crypto.ParsePrivateKey(key)
+		if err != nil {
+			fmt.Printf("Invalid --key: %v\n", err)
+			return
+		}
+		kp, _ = crypto.NewKeyPairFromPrivateKey(privKey)
+	case name != "":
+		if kp, _, err = unlockKeystore(keystore, name); err != nil {
+			fmt.Printf("❌ Unlock failed: %v\n", err)
+			return
+		}
+	default:
+		fmt.Println("Please provide --key or --name")
+		return
+	}
+
+	gentx := &GenTx{
+		ChainID: genesis.ChainID,
+		Validator: chain.ValidatorConfig{
+			Address:     kp.Address(),
+			PubKey:      kp.PublicKeyHex(),
+			Power:       power,
+			Name:        moniker,
+			Description: description,
+		},
+	}
+	data, err := gentx.signedBytes()
+	if err != nil {
+		fmt.Printf("Error signing gentx: %v\n", err)
+		return
+	}
+	if gentx.Signature, err = crypto.SignMessage(kp, data); err != nil {
+		fmt.Printf("Error signing gentx: %v\n", err)
+		return
+	}
+
+	encoded, _ := json.MarshalIndent(gentx, "", "  ")
+	if err := writeOutput(output, encoded); err != nil {
+		fmt.Printf("Error writing gentx: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "✍️  Gentx for %s signed; add it with: gydscli genesis --action add-validator --gentx <file>\n", kp.Address())
+}
+
+// addGenesisValidators verifies gentx files and adds their validators.
+// Nothing is written unless every gentx is valid.
+func addGenesisValidators(file string, files []string) {
+	if len(files) == 0 {
+		fmt.Println("Please provide --gentx")
+		return
+	}
+	genesis, err := chain.LoadGenesis(file)
+	if err != nil {
+		fmt.Printf("Error loading genesis: %v\n", err)
+		return
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Error reading gentx: %v\n", err)
+			return
+		}
+		var gentx GenTx
+		if err := json.Unmarshal(data, &gentx); err != nil {
+			fmt.Printf("❌ %s: invalid gentx: %v\n", path, err)
+			return
+		}
+		if err := gentx.verify(genesis.ChainID); err != nil {
+			fmt.Printf("❌ %s: %v\n", path, err)
+			return
+		}
+		for _, v := range genesis.Validators {
+			if v.Address == gentx.Validator.Address || v.PubKey == gentx.Validator.PubKey {
+				fmt.Printf("❌ %s: validator %s is already in the genesis\n", path, gentx.Validator.Address)
+				return
+			}
+		}
+		genesis.Validators = append(genesis.Validators, gentx.Validator)
+	}
+
+	if err := genesis.Save(file); err != nil {
+		fmt.Printf("Error writing genesis: %v\n", err)
+		return
+	}
+	fmt.Printf("🛡️  Added %d validator(s); the genesis has %d\n", len(files), len(genesis.Validators))
+}
+
+// checkGenesis runs the node's genesis validation plus the checks a node
+// would only trip over at runtime: malformed or duplicate addresses,
+// validator keys that do not match their address, unfunded validators and
+// allocations beyond the token supply
+func checkGenesis(genesis *chain.GenesisConfig) []error {
+	var errs []error
+	if err := genesis.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	allocated := make(map[string]*big.Int)
+	totalGYDS, totalGYD := new(big.Int), new(big.Int)
+	for _, alloc := range genesis.Alloc {
+		if err := crypto.ValidateAddress(alloc.Address); err != nil {
+			errs = append(errs, fmt.Errorf("alloc %s: %w", alloc.Address, err))
+		}
+		if _, dup := allocated[alloc.Address]; dup {
+			errs = append(errs, fmt.Errorf("alloc %s: duplicate allocation", alloc.Address))
+		}
+		allocated[alloc.Address] = util.CopyBig(alloc.GYDSBalance)
+		totalGYDS.Add(totalGYDS, util.CopyBig(alloc.GYDSBalance))
+		totalGYD.Add(totalGYD, util.CopyBig(alloc.GYDBalance))
+	}
+	if supply := genesis.GYDSConfig.TotalSupply.Int(); totalGYDS.Cmp(supply) > 0 {
+		errs = append(errs, fmt.Errorf("allocations hold %s GYDS, above the total supply of %s", totalGYDS, supply))
+	}
+	if supply := genesis.GYDConfig.TotalSupply.Int(); totalGYD.Cmp(supply) > 0 {
+		errs = append(errs, fmt.Errorf("allocations hold %s GYD, above the total supply of %s", totalGYD, supply))
+	}
+
+	if max := genesis.Params.MaxValidators; max > 0 && uint32(len(genesis.Validators)) > max {
+		errs = append(errs, fmt.Errorf("%d validators, above the maximum of %d", len(genesis.Validators), max))
+	}
+	minStake := genesis.Params.MinStake.Int()
+	seen := make(map[string]bool)
+	for _, v := range genesis.Validators {
+		pubKey, err := crypto.ParsePublicKey(v.PubKey)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("validator %s: invalid pub_key: %w", v.Address, err))
+		case crypto.DeriveAddress(pubKey) != v.Address:
+			errs = append(errs, fmt.Errorf("validator %s: pub_key does not belong to the address", v.Address))
+		}
+		if seen[v.Address] {
+			errs = append(errs, fmt.Errorf("validator %s: duplicate validator", v.Address))
+		}
+		seen[v.Address] = true
+		if v.Power == 0 {
+			errs = append(errs, fmt.Errorf("validator %s: zero power", v.Address))
+		}
+		if balance, ok := allocated[v.Address]; !ok || balance.Cmp(minStake) < 0 {
+			errs = append(errs, fmt.Errorf("validator %s: allocated less than the minimum stake of %s base units", v.Address, minStake))
+		}
+	}
+	return errs
+}
+
+func validateGenesisFile(file string) {
+	genesis, err := chain.LoadGenesis(file)
+	if err != nil {
+		fmt.Printf("Error loading genesis: %v\n", err)
+		return
+	}
+	errs := checkGenesis(genesis)
+	if len(errs) > 0 {
+		fmt.Printf("❌ %s is invalid:\n", file)
+		for _, err := range errs {
+			fmt.Printf("   %v\n", err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %s is valid: %d validator(s), %d account(s)\n", file, len(genesis.Validators), len(genesis.Alloc))
+}
+
+// genesisHash prints the hash of the genesis block a node builds from the
+// file, which every node of the network must agree on
+func genesisHash(file string) {
+	genesis, err := chain.LoadGenesis(file)
+	if err != nil {
+		fmt.Printf("Error loading genesis: %v\n", err)
+		return
+	}
+	hash, err := genesis.ToBlock().Hash()
+	if err != nil {
+		fmt.Printf("Error hashing genesis: %v\n", err)
+		return
+	}
+	fmt.Printf("Chain ID: %s\n", genesis.ChainID)
+	fmt.Printf("Genesis hash: %s\n", hash)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func haltCmd() {
+	haltFlags := flag.NewFlagSet("halt", flag.ExitOnError)
+	action := haltFlags.String("action", "status", "Action: status, vote, resume")
+	from := haltFlags.String("from", "", "Validator or guardian address casting the vote")
+	reason := haltFlags.String("reason", "", "Reason for halting the chain")
+	rpcURL := haltFlags.String("rpc", defaultRPCURL, "Node RPC URL")
+
+	haltFlags.Parse(os.Args[2:])
+
+	switch *action {
+	case "status":
+		haltStatus(*rpcURL)
+	case "vote":
+		haltVote(tx.TxTypeHaltVote, *from, *reason)
+	case "resume":
+		haltVote(tx.TxTypeResumeVote, *from, "")
+	default:
+		fmt.Println("Unknown halt action. Use: status, vote, resume")
+	}
+}
+
+func haltStatus(rpcURL string) {
+	var status struct {
+		Halted      bool     `json:"halted"`
+		Reason      string   `json:"reason"`
+		HaltedAt    uint64   `json:"halted_at"`
+		HaltVotes   []string `json:"halt_votes"`
+		ResumeVotes []string `json:"resume_votes"`
+		HaltPower   string   `json:"halt_power"`
+		ResumePower string   `json:"resume_power"`
+		TotalPower  string   `json:"total_power"`
+	}
+
+	if err := rpcCall(rpcURL, "chain_getHaltStatus", nil, &status); err != nil {
+		fmt.Printf("Error querying halt status: %v\n", err)
+		return
+	}
+
+	if status.Halted {
+		fmt.Println("🛑 Chain is HALTED")
+		fmt.Printf("   Reason: %s\n", status.Reason)
+		fmt.Printf("   Halted at height: %d\n", status.HaltedAt)
+		fmt.Printf("   Resume votes: %d (power %s / %s)\n", len(status.ResumeVotes), status.ResumePower, status.TotalPower)
+	} else {
+		fmt.Println("✅ Chain is running")
+		fmt.Printf("   Halt votes: %d (power %s / %s)\n", len(status.HaltVotes), status.HaltPower, status.TotalPower)
+	}
+}
+
+func haltVote(txType, from, reason string) {
+	if from == "" {
+		fmt.Println("Please provide --from")
+		return
+	}
+	if txType == tx.TxTypeHaltVote && reason == "" {
+		fmt.Println("Please provide --reason")
+		return
+	}
+
+	transaction := tx.NewTransaction(txType, from, from, new(big.Int), "GYDS")
+	transaction.SetFee(big.NewInt(21000)) // Default fee
+	if reason != "" {
+		if err := transaction.SetPayload(&tx.HaltVotePayload{Reason: reason}); err != nil {
+			fmt.Printf("Invalid halt vote: %v\n", err)
+			return
+		}
+	}
+
+	hash, _ := transaction.HashHex()
+
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"hash":   hash,
+		"type":   txType,
+		"from":   from,
+		"reason": reason,
+		"fee":    transaction.Fee.String(),
+		"status": "pending",
+	}, "", "  ")
+
+	fmt.Println("📤 Circuit breaker vote created:")
+	fmt.Println(string(data))
+	fmt.Println("\nNote: Transaction signing requires wallet private key")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// rootWallet opens the wallet behind a mnemonic, prompting for anything
+// not given on the command line
+func rootWallet(name, mnemonic string, withPassphrase bool) (*crypto.Wallet, error) {
+	if mnemonic == "" {
+		mnemonic = readSecret("Mnemonic: ")
+	}
+	passphrase := ""
+	if withPassphrase {
+		passphrase, _ = promptPassphrase(false)
+	}
+	return crypto.NewWalletFromMnemonic(name, mnemonic, passphrase)
+}
+
+// deriveAccount shows the address at one derivation path of a mnemonic:
+// the given path, or m/44'/coin'/account'/0'/index'
+func deriveAccount(name, mnemonic string, withPassphrase bool, account, index uint, path string, save bool, keystore string) {
+	root, err := rootWallet(name, mnemonic, withPassphrase)
+	if err != nil {
+		fmt.Printf("Error deriving account: %v\n", err)
+		return
+	}
+
+	derivation := crypto.AccountPath(uint32(account), uint32(index))
+	if path != "" {
+		if derivation, err = crypto.ParsePath(path); err != nil {
+			fmt.Printf("Invalid --path: %v\n", err)
+			return
+		}
+	}
+	wallet, err := root.DerivePath(derivation)
+	if err != nil {
+		fmt.Printf("Error deriving account: %v\n", err)
+		return
+	}
+
+	fmt.Println("✅ Account derived")
+	fmt.Printf("   Path: %s\n", wallet.Path)
+	fmt.Printf("   Address: %s\n", wallet.Address())
+	fmt.Printf("   Public Key: %s\n", wallet.KeyPair.PublicKeyHex())
+	if save {
+		if err := storeWallet(keystore, wallet); err != nil {
+			fmt.Printf("Error saving account: %v\n", err)
+		}
+	}
+}
+
+// listAccounts lists count consecutive derived addresses of one account
+func listAccounts(name, mnemonic string, withPassphrase bool, account, start uint, count int) {
+	if count <= 0 {
+		fmt.Println("Please provide a positive --count")
+		return
+	}
+	root, err := rootWallet(name, mnemonic, withPassphrase)
+	if err != nil {
+		fmt.Printf("Error listing accounts: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Derived addresses of account %d:\n", account)
+	for i := 0; i < count; i++ {
+		wallet, err := root.DerivePath(crypto.AccountPath(uint32(account), uint32(start)+uint32(i)))
+		if err != nil {
+			fmt.Printf("Error listing accounts: %v\n", err)
+			return
+		}
+		fmt.Printf("   %-24s %s\n", wallet.Path, wallet.Address())
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// defaultKeystoreDir is where encrypted wallet keys are kept
+func defaultKeystoreDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gydschain", "keystore")
+}
+
+// keystoreFile is a keystore and the file it was read from
+type keystoreFile struct {
+	path string
+	ks   *crypto.Keystore
+}
+
+// loadKeystores reads every keystore in dir, oldest first; a missing dir
+// holds none
+func loadKeystores(dir string) ([]*keystoreFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*keystoreFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var ks crypto.Keystore
+		if err := json.Unmarshal(data, &ks); err != nil || ks.Address == "" {
+			continue
+		}
+		files = append(files, &keystoreFile{path: path, ks: &ks})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// findKeystore returns the keystore whose wallet name or address is wallet
+func findKeystore(dir, wallet string) (*keystoreFile, error) {
+	if wallet == "" {
+		return nil, fmt.Errorf("provide the wallet with --name or --address")
+	}
+	files, err := loadKeystores(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.ks.Address == wallet || (f.ks.Name != "" && f.ks.Name == wallet) {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("no wallet %q in %s", wallet, dir)
+}
+
+// storeWallet encrypts the wallet's key under a new keystore password and
+// writes it to dir
+func storeWallet(dir string, wallet *crypto.Wallet) error {
+	files, err := loadKeystores(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.ks.Address == wallet.Address() {
+			return fmt.Errorf("wallet %s is already stored in %s", wallet.Address(), f.path)
+		}
+		if wallet.Name != "" && f.ks.Name == wallet.Name {
+			return fmt.Errorf("a wallet named %q already exists", wallet.Name)
+		}
+	}
+
+	password := readSecret("Keystore password: ")
+	if readSecret("Repeat keystore password: ") != password {
+		return fmt.Errorf("passwords do not match")
+	}
+	ks, err := crypto.EncryptKey(wallet.KeyPair, wallet.Name, password, crypto.StandardScryptN, crypto.StandardScryptP)
+	if err != nil {
+		return err
+	}
+	ks.Path = wallet.Path
+
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("UTC--%s--%s.json", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"), ks.Address)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("   Keystore: %s\n", path)
+	return nil
+}
+
+// unlockKeystore decrypts a stored wallet's key, prompting for its password
+func unlockKeystore(dir, wallet string) (*crypto.KeyPair, *crypto.Keystore, error) {
+	f, err := findKeystore(dir, wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+	kp, err := crypto.DecryptKey(f.ks, readSecret(fmt.Sprintf("Password for %s: ", f.ks.Address)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return kp, f.ks, nil
+}
+
+// unlockWallet checks a stored wallet's password, printing its private key
+// only when asked to
+func unlockWallet(dir, wallet string, showKey bool) {
+	kp, ks, err := unlockKeystore(dir, wallet)
+	if err != nil {
+		fmt.Printf("❌ Unlock failed: %v\n", err)
+		return
+	}
+
+	fmt.Println("🔓 Wallet unlocked")
+	if ks.Name != "" {
+		fmt.Printf("   Name: %s\n", ks.Name)
+	}
+	fmt.Printf("   Address: %s\n", kp.Address())
+	fmt.Printf("   Public Key: %s\n", kp.PublicKeyHex())
+	if showKey {
+		fmt.Printf("   Private Key: %s\n", kp.PrivateKeyHex())
+		fmt.Println("\n⚠️  Anyone with this key controls the wallet!")
+	}
+}
+
+// exportKeystore copies a stored wallet's encrypted keystore to output
+func exportKeystore(dir, wallet, output string) {
+	f, err := findKeystore(dir, wallet)
+	if err != nil {
+		fmt.Printf("Error exporting wallet: %v\n", err)
+		return
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		fmt.Printf("Error exporting wallet: %v\n", err)
+		return
+	}
+	if output == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		fmt.Printf("Error exporting wallet: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Encrypted keystore for %s written to %s\n", f.ks.Address, output)
+}
+
+func listWallets(dir string) {
+	files, err := loadKeystores(dir)
+	if err != nil {
+		fmt.Printf("Error reading keystore: %v\n", err)
+		return
+	}
+
+	fmt.Println("Saved wallets:")
+	if len(files) == 0 {
+		fmt.Printf("   (No wallets found in %s)\n", dir)
+		return
+	}
+	for _, f := range files {
+		name := f.ks.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Println(strings.TrimRight(fmt.Sprintf("   %-16s %s %s", name, f.ks.Address, f.ks.Path), " "))
+	}
+}
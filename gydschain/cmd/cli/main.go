@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/gydschain/gydschain/internal/crypto"
 	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 func main() {
@@ -28,6 +30,12 @@ func main() {
 		queryCmd()
 	case "stake":
 		stakeCmd()
+	case "halt":
+		haltCmd()
+	case "multisig":
+		multisigCmd()
+	case "genesis":
+		genesisCmd()
 	case "version":
 		fmt.Println("GYDS Chain CLI v1.0.0")
 	case "help":
@@ -47,29 +55,70 @@ Usage:
   gydscli <command> [arguments]
 
 Commands:
-  wallet    Wallet management (create, import, export, balance)
-  tx        Transaction operations (send, status)
+  wallet    Wallet management (create, import, export, unlock, balance, list, derive, accounts, sign-message, verify-message)
+  tx        Transaction operations (send, build, sign, broadcast, status, pending, rescue)
   query     Query blockchain data (block, tx, account)
   stake     Staking operations (delegate, undelegate, rewards)
+  halt      Emergency halt circuit breaker (status, vote, resume)
+  multisig  Multisig accounts (address, create, build, sign, combine)
+  genesis   Genesis builder (init, add-account, gentx, add-validator, validate, hash)
   version   Show version information
   help      Show this help message
 
 Examples:
   gydscli wallet create --name mywallet
+  gydscli wallet --action unlock --name mywallet
+  gydscli wallet create --name mywallet --passphrase --shares 3-of-5
+  gydscli wallet import --name mywallet --passphrase --share-file shares.txt
   gydscli wallet balance --address gyds1...
+  gydscli wallet --action accounts --count 10
+  gydscli wallet --action derive --account 1 --index 3
+  gydscli wallet --action sign-message --message "I own this address"
+  gydscli wallet --action verify-message --address gyds1... --message "..." --signature <sig>
   gydscli tx send --from mywallet --to gyds1... --amount 100 --asset GYDS
+  gydscli tx send --from mywallet --to gyds1... --amount 100 --memo 104729
+  gydscli tx --action rescue --key <hex> --max-fee 50000
+  gydscli tx --action build --from gyds1... --to gyds1... --amount 100 --nonce 7 --output tx.json
+  gydscli tx --action sign --tx tx.json --output signed.hex        (offline)
+  gydscli tx --action broadcast --signed-file signed.hex
   gydscli query block --height 1000
   gydscli stake delegate --validator gyds1... --amount 1000
+  gydscli halt --action vote --from gyds1... --reason "critical bug"
+  gydscli multisig --action create --from gyds1... --keys <hex>,<hex>,<hex> --threshold 2
+  gydscli multisig --action build --from gyds1multisig... --to gyds1... --amount 100 --output tx.json
+  gydscli multisig --action sign --tx tx.json --key <hex> --output sig1.json
+  gydscli multisig --action combine --tx tx.json --sigs sig1.json,sig2.json --submit
+  gydscli genesis --action init --template devnet --chain-id gydschain-test
+  gydscli genesis --action add-account --address gyds1... --gyds 1000000000000
+  gydscli genesis --action gentx --name myvalidator --moniker "My Validator" --output gentx.json
+  gydscli genesis --action add-validator --gentx gentx1.json,gentx2.json
+  gydscli genesis --action validate
+  gydscli genesis --action hash
 `)
 }
 
 func walletCmd() {
 	walletFlags := flag.NewFlagSet("wallet", flag.ExitOnError)
-	action := walletFlags.String("action", "", "Action: create, import, export, balance, list")
+	action := walletFlags.String("action", "", "Action: create, import, export, unlock, balance, list, derive, accounts, sign-message, verify-message")
 	name := walletFlags.String("name", "", "Wallet name")
 	address := walletFlags.String("address", "", "Wallet address")
-	mnemonic := walletFlags.String("mnemonic", "", "Mnemonic phrase for import")
+	mnemonic := walletFlags.String("mnemonic", "", "Mnemonic phrase for import or export")
 	output := walletFlags.String("output", "", "Output file for export")
+	keystore := walletFlags.String("keystore", defaultKeystoreDir(), "Directory of encrypted wallet keys")
+	showKey := walletFlags.Bool("show-key", false, "Print the private key when unlocking")
+	save := walletFlags.Bool("save", false, "Store the derived account in the keystore")
+	passphrase := walletFlags.Bool("passphrase", false, "Prompt for a wallet passphrase (25th word)")
+	shares := walletFlags.String("shares", "", "Shamir backup groups, e.g. 3-of-5 or 2-of-3,3-of-5")
+	groupThreshold := walletFlags.Int("group-threshold", 0, "Share groups needed to recover (default all)")
+	shareFile := walletFlags.String("share-file", "", "File of Shamir shares to import, one per line")
+	key := walletFlags.String("key", "", "Hex private key to import, or to sign with instead of the mnemonic")
+	message := walletFlags.String("message", "", "Message to sign or verify")
+	messageFile := walletFlags.String("message-file", "", "File holding the message to sign or verify")
+	signature := walletFlags.String("signature", "", "Message signature to verify")
+	account := walletFlags.Uint("account", 0, "HD account number (the account' level of the path)")
+	index := walletFlags.Uint("index", 0, "HD address index; the first address listed by accounts")
+	count := walletFlags.Int("count", 5, "Derived addresses to list")
+	path := walletFlags.String("path", "", "Full derivation path, e.g. m/44'/8888'/0'/0'/0'")
 	
 	if len(os.Args) < 3 {
 		fmt.Println("Usage: gydscli wallet --action <action> [options]")
@@ -80,59 +129,163 @@ func walletCmd() {
 
 	switch *action {
 	case "create":
-		createWallet(*name)
+		createWallet(*keystore, *name, *passphrase, *shares, *groupThreshold, *output)
 	case "import":
-		importWallet(*name, *mnemonic)
+		importWallet(*keystore, *name, *mnemonic, *key, *passphrase, *shares != "" || *shareFile != "", *shareFile)
 	case "export":
-		exportWallet(*address, *output)
+		if *shares == "" {
+			exportKeystore(*keystore, walletRef(*name, *address), *output)
+			return
+		}
+		exportWallet(*address, *mnemonic, *shares, *groupThreshold, *output)
+	case "unlock":
+		unlockWallet(*keystore, walletRef(*name, *address), *showKey)
 	case "balance":
 		showBalance(*address)
 	case "list":
-		listWallets()
+		listWallets(*keystore)
+	case "derive":
+		deriveAccount(*name, *mnemonic, *passphrase, *account, *index, *path, *save, *keystore)
+	case "accounts":
+		listAccounts(*name, *mnemonic, *passphrase, *account, *index, *count)
+	case "sign-message":
+		signMessage(*key, *mnemonic, *passphrase, *message, *messageFile)
+	case "verify-message":
+		verifyMessage(*address, *message, *messageFile, *signature)
 	default:
-		fmt.Println("Unknown wallet action. Use: create, import, export, balance, list")
+		fmt.Println("Unknown wallet action. Use: create, import, export, unlock, balance, list, derive, accounts, sign-message, verify-message")
 	}
 }
 
-func createWallet(name string) {
+// walletRef picks the stored wallet to act on: by name, else by address
+func walletRef(name, address string) string {
+	if name != "" {
+		return name
+	}
+	return address
+}
+
+func createWallet(keystore, name string, withPassphrase bool, shares string, groupThreshold int, output string) {
 	if name == "" {
 		name = "default"
 	}
 
-	wallet, err := crypto.NewWallet(name)
+	passphrase := ""
+	if withPassphrase {
+		var err error
+		if passphrase, err = promptPassphrase(true); err != nil {
+			fmt.Printf("Error creating wallet: %v\n", err)
+			return
+		}
+	}
+
+	mnemonic, err := crypto.GenerateMnemonic()
 	if err != nil {
 		fmt.Printf("Error creating wallet: %v\n", err)
 		return
 	}
+	wallet, err := crypto.NewWalletFromMnemonic(name, mnemonic, passphrase)
+	if err != nil {
+		fmt.Printf("Error creating wallet: %v\n", err)
+		return
+	}
+
+	if err := storeWallet(keystore, wallet); err != nil {
+		fmt.Printf("Error creating wallet: %v\n", err)
+		return
+	}
 
 	fmt.Println("✅ Wallet created successfully!")
 	fmt.Printf("   Name: %s\n", name)
 	fmt.Printf("   Address: %s\n", wallet.Address())
 	fmt.Printf("   Public Key: %s\n", wallet.KeyPair.PublicKeyHex())
-	fmt.Println("\n⚠️  Please backup your private key securely!")
-	fmt.Printf("   Private Key: %s\n", wallet.KeyPair.PrivateKeyHex())
+	if passphrase != "" {
+		fmt.Println("\n⚠️  The passphrase is not part of the backup; without it the wallet cannot be recovered!")
+	}
+
+	if shares != "" {
+		fmt.Println()
+		exportWallet(wallet.Address(), mnemonic, shares, groupThreshold, output)
+		return
+	}
+	fmt.Println("\n⚠️  Please backup your mnemonic securely!")
+	fmt.Printf("   Mnemonic: %s\n", mnemonic)
 }
 
-func importWallet(name, mnemonic string) {
+func importWallet(keystore, name, mnemonic, key string, withPassphrase, fromShares bool, shareFile string) {
+	if key != "" {
+		privKey, err := crypto.ParsePrivateKey(key)
+		if err != nil {
+			fmt.Printf("Invalid --key: %v\n", err)
+			return
+		}
+		kp, _ := crypto.NewKeyPairFromPrivateKey(privKey)
+		saveImported(keystore, &crypto.Wallet{KeyPair: kp, Name: name})
+		return
+	}
+	if fromShares {
+		recovered, err := readShares(shareFile)
+		if err != nil {
+			fmt.Printf("Error recovering mnemonic from shares: %v\n", err)
+			return
+		}
+		mnemonic = recovered
+	}
 	if mnemonic == "" {
-		fmt.Println("Please provide a mnemonic with --mnemonic")
+		fmt.Println("Please provide a mnemonic with --mnemonic, or Shamir shares with --shares or --share-file")
 		return
 	}
 
-	wallet, err := crypto.NewWalletFromMnemonic(name, mnemonic, "")
+	passphrase := ""
+	if withPassphrase {
+		passphrase, _ = promptPassphrase(false)
+	}
+
+	wallet, err := crypto.NewWalletFromMnemonic(name, mnemonic, passphrase)
 	if err != nil {
 		fmt.Printf("Error importing wallet: %v\n", err)
 		return
 	}
+	saveImported(keystore, wallet)
+}
+
+// saveImported stores an imported wallet in the keystore
+func saveImported(keystore string, wallet *crypto.Wallet) {
+	if err := storeWallet(keystore, wallet); err != nil {
+		fmt.Printf("Error importing wallet: %v\n", err)
+		return
+	}
 
 	fmt.Println("✅ Wallet imported successfully!")
-	fmt.Printf("   Name: %s\n", name)
+	fmt.Printf("   Name: %s\n", wallet.Name)
 	fmt.Printf("   Address: %s\n", wallet.Address())
 }
 
-func exportWallet(address, output string) {
-	fmt.Printf("Exporting wallet %s to %s\n", address, output)
-	// Implementation would save wallet data to file
+// exportWallet splits the wallet mnemonic into Shamir shares so the backup
+// can be spread across trustees
+func exportWallet(address, mnemonic, shares string, groupThreshold int, output string) {
+	if shares == "" {
+		fmt.Printf("Exporting wallet %s to %s\n", address, output)
+		// Implementation would save wallet data to file
+		return
+	}
+	if mnemonic == "" {
+		mnemonic = readSecret("Mnemonic: ")
+	}
+
+	groupThreshold, groups, err := parseSharePolicy(shares, groupThreshold)
+	if err != nil {
+		fmt.Printf("Error exporting shares: %v\n", err)
+		return
+	}
+	split, err := crypto.SplitMnemonic(mnemonic, groupThreshold, groups)
+	if err != nil {
+		fmt.Printf("Error exporting shares: %v\n", err)
+		return
+	}
+	if err := writeShares(split, groupThreshold, output); err != nil {
+		fmt.Printf("Error exporting shares: %v\n", err)
+	}
 }
 
 func showBalance(address string) {
@@ -148,19 +301,30 @@ func showBalance(address string) {
 	fmt.Println("\nNote: Connect to a node to see actual balance")
 }
 
-func listWallets() {
-	fmt.Println("Saved wallets:")
-	fmt.Println("   (No wallets found - wallet storage not implemented)")
-}
-
 func txCmd() {
 	txFlags := flag.NewFlagSet("tx", flag.ExitOnError)
-	action := txFlags.String("action", "send", "Action: send, status")
+	action := txFlags.String("action", "send", "Action: send, build, sign, broadcast, status, pending, rescue")
 	from := txFlags.String("from", "", "Sender address or wallet name")
 	to := txFlags.String("to", "", "Recipient address")
-	amount := txFlags.Uint64("amount", 0, "Amount to send")
+	amount := txFlags.String("amount", "0", "Amount to send, in base units")
 	asset := txFlags.String("asset", "GYDS", "Asset: GYDS or GYD")
-	hash := txFlags.String("hash", "", "Transaction hash for status")
+	hash := txFlags.String("hash", "", "Transaction hash for status or rescue")
+	key := txFlags.String("key", "", "Hex private key; signs, submits and tracks the transaction")
+	keystore := txFlags.String("keystore", defaultKeystoreDir(), "Directory of encrypted wallet keys")
+	keystoreFile := txFlags.String("keystore-file", "", "Single keystore file to sign with instead of --keystore")
+	txFile := txFlags.String("tx", "", "Unsigned transaction file written by build")
+	signed := txFlags.String("signed", "", "Hex signed transaction to broadcast")
+	signedFile := txFlags.String("signed-file", "", "File holding a signed transaction written by sign")
+	output := txFlags.String("output", "", "File to write the built or signed transaction to instead of stdout")
+	nonce := txFlags.Uint64("nonce", 0, "Sender nonce; send asks the node for the next free one when omitted")
+	fee := txFlags.String("fee", "21000", "Transaction fee, in base units")
+	memo := txFlags.String("memo", "", "Memo to attach, e.g. an exchange deposit tag")
+	rpcURL := txFlags.String("rpc", defaultRPCURL, "Node RPC URL")
+	store := txFlags.String("store", defaultTrackerPath(), "File tracking submitted transactions")
+	bump := txFlags.Uint64("bump", 10, "Fee increase in percent when replacing a stalled transaction")
+	maxFee := txFlags.Uint64("max-fee", 0, "Never sign a replacement paying more than this (0 = no limit)")
+	stall := txFlags.Duration("stall", defaultStallAfter, "Time in the mempool before a transaction counts as stalled")
+	yes := txFlags.Bool("yes", false, "Rescue without asking for confirmation")
 	
 	if len(os.Args) < 3 {
 		fmt.Println("Usage: gydscli tx --action send --from <addr> --to <addr> --amount <n> --asset <GYDS|GYD>")
@@ -168,43 +332,166 @@ func txCmd() {
 	}
 	
 	txFlags.Parse(os.Args[2:])
+	nonceSet := false
+	txFlags.Visit(func(f *flag.Flag) {
+		if f.Name == "nonce" {
+			nonceSet = true
+		}
+	})
 
 	switch *action {
 	case "send":
-		sendTx(*from, *to, *amount, *asset)
+		var explicitNonce *uint64
+		if nonceSet {
+			explicitNonce = nonce
+		}
+		sendTx(*from, *to, *amount, *asset, *fee, *memo, explicitNonce, *key, *keystore, *rpcURL, *store)
+	case "build":
+		buildTx(*from, *to, *amount, *asset, *fee, *memo, *nonce, *output)
+	case "sign":
+		signTxOffline(*txFile, *keystore, *keystoreFile, *output)
+	case "broadcast":
+		broadcastSigned(*signed, *signedFile, *rpcURL, *store)
 	case "status":
 		txStatus(*hash)
+	case "pending":
+		listTracked(*rpcURL, *store, *stall)
+	case "rescue":
+		privKey, err := decodeKey(*key)
+		if err != nil {
+			fmt.Printf("Invalid --key: %v\n", err)
+			return
+		}
+		rescueTxs(*rpcURL, *store, *hash, &rescueOptions{
+			key:    privKey,
+			bump:   *bump,
+			maxFee: *maxFee,
+			yes:    *yes,
+			stall:  *stall,
+		})
 	default:
-		fmt.Println("Unknown tx action. Use: send, status")
+		fmt.Println("Unknown tx action. Use: send, build, sign, broadcast, status, pending, rescue")
+	}
+}
+
+// decodeKey parses an optional hex private key
+func decodeKey(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
 	}
+	return hex.DecodeString(key)
 }
 
-func sendTx(from, to string, amount uint64, asset string) {
-	if from == "" || to == "" || amount == 0 {
+// sendTx builds a transfer and, given a key, signs and submits it. Without
+// an explicit nonce a submitted transfer takes the sender's pending nonce,
+// so it queues behind the sender's transactions already in the mempool.
+func sendTx(from, to, amountFlag, asset, feeFlag, memo string, nonce *uint64, key, keystore, rpcURL, store string) {
+	amount, err := util.ParseBig(amountFlag)
+	if err != nil {
+		fmt.Printf("Invalid --amount: %v\n", err)
+		return
+	}
+	fee, err := util.ParseBig(feeFlag)
+	if err != nil {
+		fmt.Printf("Invalid --fee: %v\n", err)
+		return
+	}
+	if from == "" || to == "" || amount.Sign() == 0 {
 		fmt.Println("Please provide --from, --to, and --amount")
 		return
 	}
 
+	// A sender stored in the keystore signs with its unlocked key
+	if key == "" {
+		if _, err := findKeystore(keystore, from); err == nil {
+			kp, _, err := unlockKeystore(keystore, from)
+			if err != nil {
+				fmt.Printf("❌ Unlock failed: %v\n", err)
+				return
+			}
+			from, key = kp.Address(), kp.PrivateKeyHex()
+		}
+	}
+
+	if nonce == nil {
+		var next uint64
+		if key != "" {
+			if next, err = pendingNonce(rpcURL, from); err != nil {
+				fmt.Printf("❌ Could not get the sender's nonce: %v\n", err)
+				fmt.Println("   Pass --nonce to set it yourself")
+				return
+			}
+		}
+		nonce = &next
+	}
+
 	transaction := tx.NewTransfer(from, to, amount, asset)
-	transaction.SetFee(21000) // Default fee
+	transaction.SetFee(fee)
+	transaction.SetNonce(*nonce)
+	transaction.SetMemo(memo)
+
+	if key != "" {
+		submitTracked(transaction, key, rpcURL, store)
+		return
+	}
 
 	hash, _ := transaction.HashHex()
 
-	data, _ := json.MarshalIndent(map[string]interface{}{
+	summary := map[string]interface{}{
 		"hash":   hash,
 		"from":   from,
 		"to":     to,
-		"amount": amount,
+		"amount": amount.String(),
 		"asset":  asset,
-		"fee":    transaction.Fee,
+		"fee":    transaction.Fee.String(),
 		"status": "pending",
-	}, "", "  ")
+	}
+	if memo != "" {
+		summary["memo"] = memo
+	}
+	data, _ := json.MarshalIndent(summary, "", "  ")
 
 	fmt.Println("📤 Transaction created:")
 	fmt.Println(string(data))
 	fmt.Println("\nNote: Transaction signing requires wallet private key")
 }
 
+// submitTracked signs and submits a transaction, then tracks it for rescue
+func submitTracked(transaction *tx.Transaction, key, rpcURL, store string) {
+	privKey, err := decodeKey(key)
+	if err != nil {
+		fmt.Printf("Invalid --key: %v\n", err)
+		return
+	}
+	if err := transaction.Sign(privKey); err != nil {
+		fmt.Printf("Error signing transaction: %v\n", err)
+		return
+	}
+	hash, err := transaction.HashHex()
+	if err != nil {
+		fmt.Printf("Error hashing transaction: %v\n", err)
+		return
+	}
+
+	tracker, err := loadTracker(store)
+	if err != nil {
+		fmt.Printf("Error loading tracked transactions: %v\n", err)
+		return
+	}
+
+	if _, err := broadcastTx(rpcURL, transaction); err != nil {
+		fmt.Printf("❌ Submission failed: %v\n", err)
+		fmt.Println("   The transaction is tracked; retry with: gydscli tx --action rescue --hash " + hash)
+	} else {
+		fmt.Printf("📤 Transaction submitted: %s\n", hash)
+	}
+
+	tracker.add(hash, transaction)
+	if err := tracker.save(); err != nil {
+		fmt.Printf("Error saving tracked transactions: %v\n", err)
+	}
+}
+
 func txStatus(hash string) {
 	if hash == "" {
 		fmt.Println("Please provide --hash")
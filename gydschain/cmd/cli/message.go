@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// readMessage returns the message given inline or, with file set, the
+// file's exact bytes so trailing newlines are signed as they are
+func readMessage(message, file string) ([]byte, error) {
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	if message == "" {
+		return nil, fmt.Errorf("provide the message with --message or --message-file")
+	}
+	return []byte(message), nil
+}
+
+// signMessage proves ownership of an address by signing an arbitrary
+// message with its key, taken from --key or derived from the mnemonic
+func signMessage(key, mnemonic string, withPassphrase bool, message, file string) {
+	data, err := readMessage(message, file)
+	if err != nil {
+		fmt.Printf("Error signing message: %v\n", err)
+		return
+	}
+
+	var kp *crypto.KeyPair
+	if key != "" {
+		privKey, err := crypto.ParsePrivateKey(key)
+		if err != nil {
+			fmt.Printf("Invalid --key: %v\n", err)
+			return
+		}
+		kp, _ = crypto.NewKeyPairFromPrivateKey(privKey)
+	} else {
+		if mnemonic == "" {
+			mnemonic = readSecret("Mnemonic: ")
+		}
+		passphrase := ""
+		if withPassphrase {
+			passphrase, _ = promptPassphrase(false)
+		}
+		wallet, err := crypto.NewWalletFromMnemonic("", mnemonic, passphrase)
+		if err != nil {
+			fmt.Printf("Error signing message: %v\n", err)
+			return
+		}
+		kp = wallet.KeyPair
+	}
+
+	signature, err := crypto.SignMessage(kp, data)
+	if err != nil {
+		fmt.Printf("Error signing message: %v\n", err)
+		return
+	}
+
+	fmt.Println("✍️  Message signed")
+	fmt.Printf("   Address: %s\n", kp.Address())
+	fmt.Printf("   Signature: %s\n", signature)
+}
+
+// verifyMessage checks a signature made by sign-message against an address
+func verifyMessage(address, message, file, signature string) {
+	if address == "" || signature == "" {
+		fmt.Println("Please provide --address and --signature")
+		return
+	}
+	data, err := readMessage(message, file)
+	if err != nil {
+		fmt.Printf("Error verifying message: %v\n", err)
+		return
+	}
+
+	if err := crypto.VerifyMessage(address, data, signature); err != nil {
+		fmt.Printf("❌ Signature invalid: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Message signed by %s\n", address)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// multisigCmd creates multisig accounts and builds, signs and combines
+// their transactions. Signing needs only the unsigned transaction file, so
+// each key holder can sign offline and hand back a partial signature.
+func multisigCmd() {
+	msFlags := flag.NewFlagSet("multisig", flag.ExitOnError)
+	action := msFlags.String("action", "", "Action: address, create, build, sign, combine")
+	keys := msFlags.String("keys", "", "Comma-separated hex public keys of the signers")
+	threshold := msFlags.Int("threshold", 0, "Signatures required to spend")
+	from := msFlags.String("from", "", "Sender: the funding address for create, the multisig address for build")
+	to := msFlags.String("to", "", "Recipient address")
+	amount := msFlags.String("amount", "0", "Amount, in base units")
+	asset := msFlags.String("asset", "GYDS", "Asset: GYDS or GYD")
+	fee := msFlags.String("fee", "21000", "Transaction fee, in base units")
+	nonce := msFlags.Uint64("nonce", 0, "Sender nonce")
+	memo := msFlags.String("memo", "", "Memo to attach")
+	key := msFlags.String("key", "", "Hex private key to sign with")
+	txFile := msFlags.String("tx", "", "Unsigned transaction file written by build")
+	sigs := msFlags.String("sigs", "", "Comma-separated partial signature files written by sign")
+	output := msFlags.String("output", "", "File to write the result to instead of stdout")
+	submit := msFlags.Bool("submit", false, "Broadcast the combined transaction")
+	rpcURL := msFlags.String("rpc", defaultRPCURL, "Node RPC URL")
+	store := msFlags.String("store", defaultTrackerPath(), "File tracking submitted transactions")
+
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: gydscli multisig --action <address|create|build|sign|combine> [options]")
+		return
+	}
+
+	msFlags.Parse(os.Args[2:])
+
+	switch *action {
+	case "address":
+		multisigAddress(splitList(*keys), *threshold)
+	case "create":
+		createMultisig(*from, splitList(*keys), *threshold, *amount, *fee, *nonce, *key, *rpcURL, *store)
+	case "build":
+		buildMultisigTx(*from, *to, *amount, *asset, *fee, *memo, *nonce, *output)
+	case "sign":
+		signMultisigTx(*txFile, *key, *output)
+	case "combine":
+		combineMultisigTx(*txFile, splitList(*sigs), *output, *submit, *rpcURL)
+	default:
+		fmt.Println("Unknown multisig action. Use: address, create, build, sign, combine")
+	}
+}
+
+// splitList splits a comma-separated flag, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// writeOutput writes data to file, or prints it when no file is given
+func writeOutput(file string, data []byte) error {
+	if file == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return os.WriteFile(file, append(data, '\n'), 0600)
+}
+
+// readTxFile loads a transaction written by build
+func readTxFile(file string) (*tx.Transaction, error) {
+	if file == "" {
+		return nil, fmt.Errorf("provide the transaction file with --tx")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var transaction tx.Transaction
+	if err := json.Unmarshal(data, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func multisigAddress(keys []string, threshold int) {
+	payload := &tx.CreateMultisigPayload{PubKeys: keys, Threshold: threshold}
+	if err := payload.Validate(); err != nil {
+		fmt.Printf("Invalid multisig: %v\n", err)
+		return
+	}
+	fmt.Printf("🔐 %d-of-%d multisig address: %s\n", threshold, len(keys), tx.MultisigAddress(keys, threshold))
+}
+
+func createMultisig(from string, keys []string, threshold int, amountFlag, feeFlag string, nonce uint64, key, rpcURL, store string) {
+	if from == "" {
+		fmt.Println("Please provide --from, --keys and --threshold")
+		return
+	}
+	amount, err := util.ParseBig(amountFlag)
+	if err != nil {
+		fmt.Printf("Invalid --amount: %v\n", err)
+		return
+	}
+	fee, err := util.ParseBig(feeFlag)
+	if err != nil {
+		fmt.Printf("Invalid --fee: %v\n", err)
+		return
+	}
+
+	transaction, err := tx.NewCreateMultisig(from, keys, threshold, amount)
+	if err != nil {
+		fmt.Printf("Invalid multisig: %v\n", err)
+		return
+	}
+	transaction.SetFee(fee)
+	transaction.SetNonce(nonce)
+
+	fmt.Printf("🔐 %d-of-%d multisig address: %s\n", threshold, len(keys), transaction.To)
+	if key != "" {
+		submitTracked(transaction, key, rpcURL, store)
+		return
+	}
+
+	data, _ := json.MarshalIndent(transaction, "", "  ")
+	fmt.Println(string(data))
+	fmt.Println("\nNote: Transaction signing requires wallet private key")
+}
+
+func buildMultisigTx(from, to, amountFlag, asset, feeFlag, memo string, nonce uint64, output string) {
+	if from == "" || to == "" {
+		fmt.Println("Please provide --from (the multisig address) and --to")
+		return
+	}
+	transaction := buildUnsignedTx(from, to, amountFlag, asset, feeFlag, memo, nonce, output)
+	if transaction == nil {
+		return
+	}
+	hash, _ := transaction.HashHex()
+	fmt.Fprintf(os.Stderr, "📝 Unsigned transaction %s built; have each signer run: gydscli multisig --action sign --tx <file> --key <hex>\n", hash)
+}
+
+func signMultisigTx(txFile, key, output string) {
+	transaction, err := readTxFile(txFile)
+	if err != nil {
+		fmt.Printf("Error reading transaction: %v\n", err)
+		return
+	}
+	privKey, err := crypto.ParsePrivateKey(key)
+	if err != nil {
+		fmt.Printf("Invalid --key: %v\n", err)
+		return
+	}
+	kp, err := crypto.NewKeyPairFromPrivateKey(privKey)
+	if err != nil {
+		fmt.Printf("Invalid --key: %v\n", err)
+		return
+	}
+
+	sig, err := transaction.SignPartial(kp)
+	if err != nil {
+		fmt.Printf("Error signing transaction: %v\n", err)
+		return
+	}
+	data, _ := json.MarshalIndent(sig, "", "  ")
+	if err := writeOutput(output, data); err != nil {
+		fmt.Printf("Error writing signature: %v\n", err)
+	}
+}
+
+func combineMultisigTx(txFile string, sigFiles []string, output string, submit bool, rpcURL string) {
+	transaction, err := readTxFile(txFile)
+	if err != nil {
+		fmt.Printf("Error reading transaction: %v\n", err)
+		return
+	}
+	if len(sigFiles) == 0 {
+		fmt.Println("Please provide partial signature files with --sigs")
+		return
+	}
+
+	for _, file := range sigFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("Error reading signature: %v\n", err)
+			return
+		}
+		var sig tx.MultisigSignature
+		if err := json.Unmarshal(data, &sig); err != nil {
+			fmt.Printf("Invalid signature in %s: %v\n", file, err)
+			return
+		}
+		transaction.AddSignatures(&sig)
+	}
+
+	if submit {
+		hash, err := broadcastTx(rpcURL, transaction)
+		if err != nil {
+			fmt.Printf("❌ Submission failed: %v\n", err)
+			return
+		}
+		fmt.Printf("📤 Transaction submitted: %s\n", hash)
+		return
+	}
+
+	signed, err := encodeSignedTx(transaction)
+	if err != nil {
+		fmt.Printf("Error encoding transaction: %v\n", err)
+		return
+	}
+	if err := writeOutput(output, []byte(signed)); err != nil {
+		fmt.Printf("Error writing transaction: %v\n", err)
+	}
+}
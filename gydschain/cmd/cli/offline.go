@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Air-gapped signing: build an unsigned transfer on an online machine, carry
+// the file to an offline one holding the keystore, sign it there with no
+// network access, and broadcast the signed blob from the online machine.
+
+// buildUnsignedTx writes an unsigned transfer as JSON to output (stdout when
+// empty) and returns it; errors are reported and give nil
+func buildUnsignedTx(from, to, amountFlag, asset, feeFlag, memo string, nonce uint64, output string) *tx.Transaction {
+	amount, err := util.ParseBig(amountFlag)
+	if err != nil {
+		fmt.Printf("Invalid --amount: %v\n", err)
+		return nil
+	}
+	fee, err := util.ParseBig(feeFlag)
+	if err != nil {
+		fmt.Printf("Invalid --fee: %v\n", err)
+		return nil
+	}
+
+	transaction := tx.NewTransfer(from, to, amount, asset)
+	transaction.SetFee(fee)
+	transaction.SetNonce(nonce)
+	transaction.SetMemo(memo)
+
+	data, err := json.MarshalIndent(transaction, "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding transaction: %v\n", err)
+		return nil
+	}
+	if err := writeOutput(output, data); err != nil {
+		fmt.Printf("Error writing transaction: %v\n", err)
+		return nil
+	}
+	return transaction
+}
+
+func buildTx(from, to, amountFlag, asset, feeFlag, memo string, nonce uint64, output string) {
+	if from == "" || to == "" {
+		fmt.Println("Please provide --from and --to")
+		return
+	}
+	transaction := buildUnsignedTx(from, to, amountFlag, asset, feeFlag, memo, nonce, output)
+	if transaction == nil {
+		return
+	}
+	hash, _ := transaction.HashHex()
+	fmt.Fprintf(os.Stderr, "📝 Unsigned transaction %s built; sign it offline with: gydscli tx --action sign --tx <file>\n", hash)
+}
+
+// signTxOffline signs an unsigned transaction file with a keystore key. It
+// never touches the network, so it can run on an air-gapped machine.
+func signTxOffline(txFile, keystore, keystoreFile, output string) {
+	transaction, err := readTxFile(txFile)
+	if err != nil {
+		fmt.Printf("Error reading transaction: %v\n", err)
+		return
+	}
+
+	var kp *crypto.KeyPair
+	if keystoreFile != "" {
+		kp, err = unlockKeystoreFile(keystoreFile)
+	} else {
+		kp, _, err = unlockKeystore(keystore, transaction.From)
+	}
+	if err != nil {
+		fmt.Printf("❌ Unlock failed: %v\n", err)
+		return
+	}
+	if kp.Address() != transaction.From {
+		fmt.Printf("❌ Key is for %s, but the transaction is from %s\n", kp.Address(), transaction.From)
+		return
+	}
+
+	if err := transaction.Sign(kp.PrivateKey); err != nil {
+		fmt.Printf("Error signing transaction: %v\n", err)
+		return
+	}
+	signed, err := encodeSignedTx(transaction)
+	if err != nil {
+		fmt.Printf("Error encoding transaction: %v\n", err)
+		return
+	}
+	if err := writeOutput(output, []byte(signed)); err != nil {
+		fmt.Printf("Error writing transaction: %v\n", err)
+		return
+	}
+	hash, _ := transaction.HashHex()
+	fmt.Fprintf(os.Stderr, "✍️  Transaction %s signed; broadcast it with: gydscli tx --action broadcast --signed-file <file>\n", hash)
+}
+
+// unlockKeystoreFile decrypts a single keystore file, e.g. one carried to
+// the signing machine on removable media
+func unlockKeystoreFile(path string) (*crypto.KeyPair, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ks crypto.Keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, crypto.ErrKeystoreFormat
+	}
+	return crypto.DecryptKey(&ks, readSecret(fmt.Sprintf("Password for %s: ", ks.Address)))
+}
+
+// broadcastSigned submits a transaction signed by sign, given inline or in
+// a file, and tracks it for rescue
+func broadcastSigned(signed, signedFile, rpcURL, store string) {
+	if signedFile != "" {
+		data, err := os.ReadFile(signedFile)
+		if err != nil {
+			fmt.Printf("Error reading signed transaction: %v\n", err)
+			return
+		}
+		signed = string(data)
+	}
+	if signed = strings.TrimSpace(signed); signed == "" {
+		fmt.Println("Please provide --signed or --signed-file")
+		return
+	}
+
+	data, err := hex.DecodeString(signed)
+	if err != nil {
+		fmt.Printf("Invalid signed transaction: %v\n", err)
+		return
+	}
+	var transaction tx.Transaction
+	if err := json.Unmarshal(data, &transaction); err != nil {
+		fmt.Printf("Invalid signed transaction: %v\n", err)
+		return
+	}
+	if len(transaction.Signature) == 0 && len(transaction.Signatures) == 0 {
+		fmt.Println("❌ Transaction is not signed")
+		return
+	}
+
+	hash, err := broadcastTx(rpcURL, &transaction)
+	if err != nil {
+		fmt.Printf("❌ Submission failed: %v\n", err)
+		return
+	}
+	fmt.Printf("📤 Transaction submitted: %s\n", hash)
+
+	tracker, err := loadTracker(store)
+	if err != nil {
+		fmt.Printf("Error loading tracked transactions: %v\n", err)
+		return
+	}
+	tracker.add(hash, &transaction)
+	if err := tracker.save(); err != nil {
+		fmt.Printf("Error saving tracked transactions: %v\n", err)
+	}
+}
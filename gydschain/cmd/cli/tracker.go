@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Tracked transaction states
+const (
+	trackPending   = "pending"
+	trackStalled   = "stalled"
+	trackDropped   = "dropped"
+	trackConfirmed = "confirmed"
+	trackReplaced  = "replaced"
+	trackUnknown   = "unknown"
+)
+
+// defaultStallAfter is how long a mempool tx may wait before it counts as stalled
+const defaultStallAfter = 10 * time.Minute
+
+// trackedTx is a locally submitted transaction kept until it confirms
+type trackedTx struct {
+	Hash          string          `json:"hash"`
+	From          string          `json:"from"`
+	Nonce         uint64          `json:"nonce"`
+	Fee           *util.Big       `json:"fee"`
+	Tx            *tx.Transaction `json:"tx"`
+	SubmittedAt   int64           `json:"submitted_at"`
+	LastBroadcast int64           `json:"last_broadcast"`
+	Rebroadcasts  int             `json:"rebroadcasts"`
+	ReplacedBy    string          `json:"replaced_by,omitempty"`
+	Status        string          `json:"status"`
+}
+
+// txTracker persists submitted transactions in a JSON file
+type txTracker struct {
+	path string
+	txs  map[string]*trackedTx
+}
+
+// defaultTrackerPath returns ~/.gydscli/txs.json
+func defaultTrackerPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gydscli", "txs.json")
+}
+
+// loadTracker reads the tracker file, starting empty if it doesn't exist
+func loadTracker(path string) (*txTracker, error) {
+	t := &txTracker{path: path, txs: make(map[string]*trackedTx)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.txs); err != nil {
+		return nil, fmt.Errorf("corrupt tracker file %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// save writes the tracker file atomically
+func (t *txTracker) save() error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t.txs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+// add records a newly submitted transaction
+func (t *txTracker) add(hash string, transaction *tx.Transaction) {
+	now := time.Now().Unix()
+	t.txs[hash] = &trackedTx{
+		Hash:          hash,
+		From:          transaction.From,
+		Nonce:         transaction.Nonce,
+		Fee:           (*util.Big)(util.CopyBig(transaction.Fee)),
+		Tx:            transaction,
+		SubmittedAt:   now,
+		LastBroadcast: now,
+		Status:        trackPending,
+	}
+}
+
+// open returns tracked transactions that have not confirmed or been replaced
+func (t *txTracker) open() []*trackedTx {
+	open := make([]*trackedTx, 0, len(t.txs))
+	for _, tracked := range t.txs {
+		if tracked.Status != trackConfirmed && tracked.Status != trackReplaced {
+			open = append(open, tracked)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].SubmittedAt < open[j].SubmittedAt })
+	return open
+}
+
+// encodeSignedTx encodes a signed transaction for tx_sendTransaction
+func encodeSignedTx(transaction *tx.Transaction) (string, error) {
+	data, err := json.Marshal(transaction)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// broadcastTx submits a signed transaction to the node
+func broadcastTx(rpcURL string, transaction *tx.Transaction) (string, error) {
+	signed, err := encodeSignedTx(transaction)
+	if err != nil {
+		return "", err
+	}
+	var hash string
+	if err := rpcCall(rpcURL, "tx_sendTransaction", map[string]string{"signedTx": signed}, &hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// pendingNonce asks the node for the nonce address's next transaction
+// should use, counting its transactions still in the mempool
+func pendingNonce(rpcURL, address string) (uint64, error) {
+	var nonce uint64
+	if err := rpcCall(rpcURL, "account_getPendingNonce", map[string]string{"address": address}, &nonce); err != nil {
+		return 0, err
+	}
+	return nonce, nil
+}
+
+// nodeView is what the node currently knows about our transactions
+type nodeView struct {
+	mempool map[string]bool
+	fee     func(*tx.Transaction) (*big.Int, bool)
+}
+
+// fetchNodeView loads the mempool contents and a fee estimator from the node
+func fetchNodeView(rpcURL string) (*nodeView, error) {
+	var pending []struct {
+		Hash string `json:"hash"`
+	}
+	if err := rpcCall(rpcURL, "tx_getPendingTransactions", nil, &pending); err != nil {
+		return nil, err
+	}
+
+	view := &nodeView{mempool: make(map[string]bool, len(pending))}
+	for _, p := range pending {
+		view.mempool[p.Hash] = true
+	}
+	view.fee = func(transaction *tx.Transaction) (*big.Int, bool) {
+		var estimate string
+		if err := rpcCall(rpcURL, "tx_estimateFee", map[string]interface{}{"tx": transaction}, &estimate); err != nil {
+			return nil, false
+		}
+		fee, err := util.ParseBig(estimate)
+		return fee, err == nil
+	}
+	return view, nil
+}
+
+// refreshStatus classifies a tracked tx as confirmed, pending, stalled or dropped
+func refreshStatus(rpcURL string, view *nodeView, tracked *trackedTx, stallAfter time.Duration) {
+	var receipt json.RawMessage
+	if err := rpcCall(rpcURL, "tx_getTransactionReceipt", map[string]string{"hash": tracked.Hash}, &receipt); err == nil &&
+		len(receipt) > 0 && string(receipt) != "null" {
+		tracked.Status = trackConfirmed
+		return
+	}
+
+	if view == nil {
+		tracked.Status = trackUnknown
+		return
+	}
+	if !view.mempool[tracked.Hash] {
+		tracked.Status = trackDropped
+		return
+	}
+
+	tracked.Status = trackPending
+	if time.Since(time.Unix(tracked.LastBroadcast, 0)) > stallAfter {
+		tracked.Status = trackStalled
+	} else if estimate, ok := view.fee(tracked.Tx); ok && tracked.Fee.Int().Cmp(estimate) < 0 {
+		tracked.Status = trackStalled
+	}
+}
+
+// listTracked shows every open tracked transaction with its current status
+func listTracked(rpcURL, store string, stallAfter time.Duration) {
+	tracker, err := loadTracker(store)
+	if err != nil {
+		fmt.Printf("Error loading tracked transactions: %v\n", err)
+		return
+	}
+
+	view, err := fetchNodeView(rpcURL)
+	if err != nil {
+		fmt.Printf("Warning: could not read node mempool: %v\n", err)
+	}
+
+	open := tracker.open()
+	if len(open) == 0 {
+		fmt.Println("No pending transactions tracked")
+		return
+	}
+
+	fmt.Println("Tracked transactions:")
+	for _, tracked := range open {
+		refreshStatus(rpcURL, view, tracked, stallAfter)
+		age := time.Since(time.Unix(tracked.SubmittedAt, 0)).Round(time.Second)
+		fmt.Printf("   %s  nonce %d  fee %s  %-9s  age %s  rebroadcasts %d\n",
+			tracked.Hash, tracked.Nonce, tracked.Fee.Int(), tracked.Status, age, tracked.Rebroadcasts)
+	}
+
+	if err := tracker.save(); err != nil {
+		fmt.Printf("Error saving tracked transactions: %v\n", err)
+	}
+}
+
+// rescueOptions bounds what rescue may do without asking again
+type rescueOptions struct {
+	key    []byte
+	bump   uint64 // fee increase in percent
+	maxFee uint64 // never sign a replacement paying more than this
+	yes    bool   // skip the confirmation prompt
+	stall  time.Duration
+}
+
+// rescueTxs re-broadcasts dropped transactions and fee-bumps stalled ones.
+// With hash empty every open transaction is considered.
+func rescueTxs(rpcURL, store, hash string, opts *rescueOptions) {
+	tracker, err := loadTracker(store)
+	if err != nil {
+		fmt.Printf("Error loading tracked transactions: %v\n", err)
+		return
+	}
+
+	view, err := fetchNodeView(rpcURL)
+	if err != nil {
+		fmt.Printf("Error reading node mempool: %v\n", err)
+		return
+	}
+
+	targets := tracker.open()
+	if hash != "" {
+		tracked, exists := tracker.txs[hash]
+		if !exists {
+			fmt.Printf("Transaction %s is not tracked\n", hash)
+			return
+		}
+		targets = []*trackedTx{tracked}
+	}
+
+	for _, tracked := range targets {
+		refreshStatus(rpcURL, view, tracked, opts.stall)
+
+		switch tracked.Status {
+		case trackDropped:
+			rebroadcast(rpcURL, tracked, opts)
+		case trackStalled:
+			bumpFee(rpcURL, tracker, tracked, view, opts)
+		default:
+			fmt.Printf("%s is %s; nothing to do\n", tracked.Hash, tracked.Status)
+		}
+	}
+
+	if err := tracker.save(); err != nil {
+		fmt.Printf("Error saving tracked transactions: %v\n", err)
+	}
+}
+
+// rebroadcast resubmits a dropped transaction unchanged
+func rebroadcast(rpcURL string, tracked *trackedTx, opts *rescueOptions) {
+	if !confirm(opts, fmt.Sprintf("Re-broadcast dropped tx %s (fee %s)?", tracked.Hash, tracked.Fee.Int())) {
+		return
+	}
+	if _, err := broadcastTx(rpcURL, tracked.Tx); err != nil {
+		fmt.Printf("❌ Re-broadcast of %s failed: %v\n", tracked.Hash, err)
+		return
+	}
+	tracked.Rebroadcasts++
+	tracked.LastBroadcast = time.Now().Unix()
+	tracked.Status = trackPending
+	fmt.Printf("📡 Re-broadcast %s\n", tracked.Hash)
+}
+
+// bumpFee replaces a stalled transaction with one paying a higher fee at the same nonce
+func bumpFee(rpcURL string, tracker *txTracker, tracked *trackedTx, view *nodeView, opts *rescueOptions) {
+	if len(opts.key) == 0 {
+		fmt.Printf("%s is stalled; pass --key to sign a fee-bumped replacement\n", tracked.Hash)
+		return
+	}
+
+	fee := tracked.Fee.Int()
+	increase := new(big.Int).Mul(fee, new(big.Int).SetUint64(opts.bump))
+	newFee := new(big.Int).Add(fee, increase.Div(increase, big.NewInt(100)))
+	if estimate, ok := view.fee(tracked.Tx); ok && estimate.Cmp(newFee) > 0 {
+		newFee = estimate
+	}
+	if opts.maxFee > 0 && newFee.Cmp(new(big.Int).SetUint64(opts.maxFee)) > 0 {
+		fmt.Printf("⚠️  %s needs fee %s, above --max-fee %d; not replacing\n", tracked.Hash, newFee, opts.maxFee)
+		return
+	}
+
+	if !confirm(opts, fmt.Sprintf("Replace stalled tx %s: fee %s -> %s?", tracked.Hash, tracked.Fee.Int(), newFee)) {
+		return
+	}
+
+	replacement := *tracked.Tx
+	replacement.SetFee(newFee)
+	replacement.Timestamp = time.Now().Unix()
+	if err := replacement.Sign(opts.key); err != nil {
+		fmt.Printf("❌ Signing replacement failed: %v\n", err)
+		return
+	}
+	newHash, err := replacement.HashHex()
+	if err != nil {
+		fmt.Printf("❌ Hashing replacement failed: %v\n", err)
+		return
+	}
+	if _, err := broadcastTx(rpcURL, &replacement); err != nil {
+		fmt.Printf("❌ Replacement of %s rejected: %v\n", tracked.Hash, err)
+		return
+	}
+
+	tracked.Status = trackReplaced
+	tracked.ReplacedBy = newHash
+	tracker.add(newHash, &replacement)
+	fmt.Printf("⛽ Replaced %s with %s (fee %s)\n", tracked.Hash, newHash, newFee)
+}
+
+// stdin is shared so piped answers aren't lost between prompts
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks the user to approve an action unless --yes was given
+func confirm(opts *rescueOptions, prompt string) bool {
+	if opts.yes {
+		return true
+	}
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,197 @@
+// Command errgen generates the error catalog served by rpc_errorCodes from
+// the JSON-RPC error code constants and the chain errors in internal/util.
+// It runs from internal/rpc via go generate.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errorCode is a JSON-RPC error code constant and its line comment
+type errorCode struct {
+	name        string
+	description string
+}
+
+// errorMessage is a sentinel error declared with errors.New
+type errorMessage struct {
+	name     string
+	category string
+	message  string
+}
+
+func main() {
+	typesFile := flag.String("types", "types.go", "File declaring the JSON-RPC error code constants")
+	errorsFile := flag.String("errors", "../util/errors.go", "File declaring the chain errors")
+	out := flag.String("out", "errorcatalog_gen.go", "Output file")
+	check := flag.Bool("check", false, "Verify the output file is up to date and exit")
+	flag.Parse()
+
+	codes, err := parseCodes(*typesFile)
+	if err != nil {
+		fail("parse %s: %v", *typesFile, err)
+	}
+	messages, err := parseMessages(*errorsFile)
+	if err != nil {
+		fail("parse %s: %v", *errorsFile, err)
+	}
+
+	src, err := generate(codes, messages)
+	if err != nil {
+		fail("generate: %v", err)
+	}
+
+	if *check {
+		current, err := os.ReadFile(*out)
+		if err != nil || !bytes.Equal(current, src) {
+			fail("%s is out of date; run go generate ./internal/rpc", *out)
+		}
+		fmt.Printf("Error catalog up to date (%d codes, %d messages)\n", len(codes), len(messages))
+		return
+	}
+
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fail("write %s: %v", *out, err)
+	}
+	fmt.Printf("Generated %s\n", *out)
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// parseCodes collects the constants of every const block whose doc comment
+// mentions error codes. Each one must carry a line comment describing it.
+func parseCodes(path string) ([]errorCode, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []errorCode
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST || gen.Doc == nil || !strings.Contains(gen.Doc.Text(), "error codes") {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for _, name := range value.Names {
+				if value.Comment == nil {
+					return nil, fmt.Errorf("error code %s has no description comment", name.Name)
+				}
+				codes = append(codes, errorCode{
+					name:        name.Name,
+					description: strings.TrimSpace(value.Comment.Text()),
+				})
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no error code constants found")
+	}
+	return codes, nil
+}
+
+// parseMessages collects package-level errors.New sentinels. A comment
+// such as "// Block errors" above a group sets the category of the errors
+// that follow it.
+func parseMessages(path string) ([]errorMessage, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []errorMessage
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		category := ""
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if value.Doc != nil {
+				category = categoryOf(value.Doc.Text())
+			}
+			for i, name := range value.Names {
+				if i >= len(value.Values) {
+					continue
+				}
+				message, ok := errorsNewArg(value.Values[i])
+				if !ok {
+					continue
+				}
+				messages = append(messages, errorMessage{
+					name:     strings.TrimPrefix(name.Name, "Err"),
+					category: category,
+					message:  message,
+				})
+			}
+		}
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no errors.New sentinels found")
+	}
+	return messages, nil
+}
+
+// categoryOf turns a group comment like "Block errors" into "block"
+func categoryOf(doc string) string {
+	fields := strings.Fields(doc)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// errorsNewArg returns the message of an errors.New("...") call
+func errorsNewArg(expr ast.Expr) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "New" {
+		return "", false
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "errors" {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	message, err := strconv.Unquote(lit.Value)
+	return message, err == nil
+}
+
+// generate renders the catalog as gofmt'd Go source
+func generate(codes []errorCode, messages []errorMessage) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by errgen from types.go and internal/util/errors.go. DO NOT EDIT.\n\n")
+	b.WriteString("package rpc\n\n")
+	b.WriteString("var errorCatalog = ErrorCatalog{\n")
+	b.WriteString("Codes: []ErrorCode{\n")
+	for _, c := range codes {
+		fmt.Fprintf(&b, "{Code: %s, Name: %q, Description: %q},\n", c.name, strings.TrimPrefix(c.name, "Err"), c.description)
+	}
+	b.WriteString("},\n")
+	b.WriteString("Messages: []ErrorMessage{\n")
+	for _, m := range messages {
+		fmt.Fprintf(&b, "{Name: %q, Category: %q, Message: %q},\n", m.name, m.category, m.message)
+	}
+	b.WriteString("},\n")
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
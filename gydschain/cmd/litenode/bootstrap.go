@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// LiteConfig is the optional lite node config file
+type LiteConfig struct {
+	BootstrapURL     string `json:"bootstrap_url"`     // signed bootstrap list, e.g. the admin server's /bootstrap
+	BootstrapKey     string `json:"bootstrap_key"`     // pinned hex ed25519 key the list must be signed with
+	BootstrapRefresh int    `json:"bootstrap_refresh"` // seconds between fetches
+}
+
+func loadLiteConfig(path string) (*LiteConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg LiteConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+const (
+	// defaultBootstrapRefresh applies when the config sets no refresh interval
+	defaultBootstrapRefresh = 5 * time.Minute
+
+	// maxBootstrapSkew bounds how far in the future a list may be issued, so
+	// one bad timestamp cannot lock out every later list
+	maxBootstrapSkew = 10 * time.Minute
+
+	// maxBootstrapSize bounds the signed list download
+	maxBootstrapSize = 1 << 20
+)
+
+// Bootstrap list errors
+var (
+	ErrBootstrapSignature = errors.New("bootstrap list signature invalid")
+	ErrBootstrapStale     = errors.New("bootstrap list older than the current one")
+	ErrBootstrapFuture    = errors.New("bootstrap list issued in the future")
+	ErrBootstrapEmpty     = errors.New("bootstrap list has no nodes")
+)
+
+// signedBootstrapList mirrors the admin server's signed envelope; the
+// signature covers the exact payload bytes
+type signedBootstrapList struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+}
+
+// bootstrapList is the signed payload
+type bootstrapList struct {
+	IssuedAt int64           `json:"issued_at"`
+	Nodes    []BootstrapNode `json:"nodes"`
+}
+
+// verifyBootstrapList checks a signed list against the pinned key and
+// returns its payload
+func verifyBootstrapList(data []byte, pubKey []byte) (*bootstrapList, error) {
+	var signed signedBootstrapList
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(signed.Signature)
+	if err != nil || !crypto.VerifySignature(pubKey, signed.Payload, signature) {
+		return nil, ErrBootstrapSignature
+	}
+
+	var list bootstrapList
+	if err := json.Unmarshal(signed.Payload, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Nodes) == 0 {
+		return nil, ErrBootstrapEmpty
+	}
+	if time.Unix(list.IssuedAt, 0).After(time.Now().Add(maxBootstrapSkew)) {
+		return nil, ErrBootstrapFuture
+	}
+	return &list, nil
+}
+
+// BootstrapFetcher periodically fetches the signed bootstrap list and
+// hot-swaps the lite node's peers when a newer one verifies
+type BootstrapFetcher struct {
+	node     *LiteNode
+	url      string
+	pubKey   []byte
+	interval time.Duration
+	path     string // last verified signed list, reused across restarts
+	client   *http.Client
+	issuedAt int64
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewBootstrapFetcher creates a fetcher for url pinned to pubKey
+func NewBootstrapFetcher(node *LiteNode, url string, pubKey []byte, interval time.Duration, path string) *BootstrapFetcher {
+	if interval <= 0 {
+		interval = defaultBootstrapRefresh
+	}
+	return &BootstrapFetcher{
+		node:     node,
+		url:      url,
+		pubKey:   pubKey,
+		interval: interval,
+		path:     path,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// LoadSaved applies the last verified list from disk, if any, so a restart
+// does not fall back to an older static bootstrap file
+func (f *BootstrapFetcher) LoadSaved() bool {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return false
+	}
+	list, err := verifyBootstrapList(data, f.pubKey)
+	if err != nil {
+		log.Printf("Warning: Ignoring saved bootstrap list: %v", err)
+		return false
+	}
+	f.issuedAt = list.IssuedAt
+	f.node.SetBootstrapNodes(list.Nodes)
+	return true
+}
+
+// Start fetches immediately and then every interval
+func (f *BootstrapFetcher) Start() {
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := f.Refresh(); err != nil {
+				log.Printf("Bootstrap list refresh failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-f.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends periodic fetching
+func (f *BootstrapFetcher) Stop() {
+	f.stopOnce.Do(func() { close(f.stopChan) })
+}
+
+// Refresh fetches and verifies the list, swapping peers if it is newer
+func (f *BootstrapFetcher) Refresh() error {
+	resp, err := f.client.Get(f.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bootstrap list fetch returned %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBootstrapSize))
+	if err != nil {
+		return err
+	}
+
+	list, err := verifyBootstrapList(data, f.pubKey)
+	if err != nil {
+		return err
+	}
+	if list.IssuedAt < f.issuedAt {
+		return ErrBootstrapStale
+	}
+
+	f.issuedAt = list.IssuedAt
+	if err := ioutil.WriteFile(f.path, data, 0644); err != nil {
+		log.Printf("Warning: Could not save bootstrap list: %v", err)
+	}
+	f.node.SetBootstrapNodes(list.Nodes)
+	return nil
+}
+
+// SetBootstrapNodes replaces the peers the node syncs from; syncs already
+// in progress finish against the old set
+func (n *LiteNode) SetBootstrapNodes(nodes []BootstrapNode) {
+	n.peersMu.Lock()
+	defer n.peersMu.Unlock()
+	if !sameBootstrapNodes(n.bootstrapNodes, nodes) {
+		log.Printf("Bootstrap peers updated: %d nodes", len(nodes))
+	}
+	n.bootstrapNodes = nodes
+}
+
+// BootstrapNodes returns the current peers
+func (n *LiteNode) BootstrapNodes() []BootstrapNode {
+	n.peersMu.RLock()
+	defer n.peersMu.RUnlock()
+	return n.bootstrapNodes
+}
+
+func sameBootstrapNodes(a, b []BootstrapNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// Checkpoint pins a block hash the lite node trusts without verifying the
+// headers before it, along with the validators trusted to sign after it.
+// Headers are checked against the validators of the latest checkpoint at
+// or below their height, so a validator set change needs a new checkpoint.
+type Checkpoint struct {
+	Height     uint64             `json:"height"`
+	Hash       string             `json:"hash"`
+	Validators []TrustedValidator `json:"validators,omitempty"`
+}
+
+// TrustedValidator is a validator address and its hex-encoded public key
+type TrustedValidator struct {
+	Address string `json:"address"`
+	PubKey  string `json:"pub_key"`
+}
+
+// Checkpoints is a height-ordered set of trusted checkpoints
+type Checkpoints struct {
+	list []*Checkpoint
+	keys []map[string][]byte // parsed validator keys, parallel to list
+}
+
+// loadCheckpoints reads a JSON array of checkpoints
+func loadCheckpoints(path string) (*Checkpoints, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Checkpoint
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return newCheckpoints(list)
+}
+
+func newCheckpoints(list []*Checkpoint) (*Checkpoints, error) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Height < list[j].Height })
+
+	c := &Checkpoints{list: list, keys: make([]map[string][]byte, len(list))}
+	for i, cp := range list {
+		if cp.Hash == "" {
+			return nil, fmt.Errorf("checkpoint %d has no hash", cp.Height)
+		}
+		if i > 0 && list[i-1].Height == cp.Height {
+			return nil, fmt.Errorf("duplicate checkpoint at height %d", cp.Height)
+		}
+		keys := make(map[string][]byte, len(cp.Validators))
+		for _, v := range cp.Validators {
+			key, err := crypto.ParsePublicKey(v.PubKey)
+			if err != nil {
+				return nil, fmt.Errorf("checkpoint %d validator %s: %v", cp.Height, v.Address, err)
+			}
+			keys[v.Address] = key
+		}
+		c.keys[i] = keys
+	}
+	return c, nil
+}
+
+// Latest returns the highest checkpoint, or nil if there are none
+func (c *Checkpoints) Latest() *Checkpoint {
+	if len(c.list) == 0 {
+		return nil
+	}
+	return c.list[len(c.list)-1]
+}
+
+// At returns the checkpoint pinned at height, if any
+func (c *Checkpoints) At(height uint64) *Checkpoint {
+	i := sort.Search(len(c.list), func(i int) bool { return c.list[i].Height >= height })
+	if i < len(c.list) && c.list[i].Height == height {
+		return c.list[i]
+	}
+	return nil
+}
+
+// ValidatorsAt returns the validators trusted to sign the header at height:
+// those of the latest checkpoint below it that lists any
+func (c *Checkpoints) ValidatorsAt(height uint64) map[string][]byte {
+	for i := len(c.list) - 1; i >= 0; i-- {
+		if c.list[i].Height < height && len(c.keys[i]) > 0 {
+			return c.keys[i]
+		}
+	}
+	return nil
+}
+
+// Header verification errors
+var (
+	ErrNoCheckpoint       = errors.New("no trusted checkpoint configured")
+	ErrCheckpointMismatch = errors.New("header does not match trusted checkpoint")
+	ErrHeaderHash         = errors.New("header hash mismatch")
+	ErrHeaderLink         = errors.New("header does not extend the verified chain")
+	ErrUnknownProposer    = errors.New("header proposer is not a trusted validator")
+	ErrFinalizedConflict  = errors.New("header conflicts with a finalized checkpoint")
+	ErrSnapshotState      = errors.New("header does not commit to the restored snapshot state")
+	ErrUntrustedSnapshot  = errors.New("snapshot head is neither checkpointed nor signed by a trusted validator")
+)
+
+// verifyHeader checks that next extends prev and is signed by one of the
+// trusted validators
+func verifyHeader(prev, next *rpc.HeaderResponse, validators map[string][]byte) error {
+	if err := checkHeaderHash(next); err != nil {
+		return err
+	}
+	if next.Header.Height != prev.Header.Height+1 || next.Header.ParentHash != prev.Hash {
+		return ErrHeaderLink
+	}
+	if next.Header.Timestamp < prev.Header.Timestamp {
+		return chain.ErrInvalidTimestamp
+	}
+
+	pubKey, ok := validators[next.Validator]
+	if !ok {
+		return ErrUnknownProposer
+	}
+	signature, err := hex.DecodeString(next.Signature)
+	if err != nil {
+		return chain.ErrInvalidBlockSignature
+	}
+	return chain.VerifyHeaderSignature(next.Header, signature, pubKey)
+}
+
+// checkHeaderHash checks that a header hashes to its claimed hash
+func checkHeaderHash(h *rpc.HeaderResponse) error {
+	if h.Header == nil {
+		return ErrHeaderHash
+	}
+	hash, err := h.Header.Hash()
+	if err != nil || hash != h.Hash {
+		return ErrHeaderHash
+	}
+	return nil
+}
+
+// HeaderStore keeps verified headers in memory and appends them to a JSONL
+// file in the data dir, so a restart resumes from the verified tip
+type HeaderStore struct {
+	mu       sync.RWMutex
+	file     *os.File
+	byHeight map[uint64]*rpc.HeaderResponse
+	tip      *rpc.HeaderResponse
+}
+
+// OpenHeaderStore loads the headers stored at path. A partial last line
+// left by a crash is truncated away.
+func OpenHeaderStore(path string) (*HeaderStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &HeaderStore{file: file, byHeight: make(map[uint64]*rpc.HeaderResponse)}
+
+	r := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		var h rpc.HeaderResponse
+		if json.Unmarshal(bytes.TrimSpace(line), &h) != nil || h.Header == nil {
+			break
+		}
+		s.add(&h)
+		offset += int64(len(line))
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Append stores a header verified to extend the tip
+func (s *HeaderStore) Append(h *rpc.HeaderResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tip != nil && h.Header.Height != s.tip.Header.Height+1 {
+		return ErrHeaderLink
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.add(h)
+	return nil
+}
+
+func (s *HeaderStore) add(h *rpc.HeaderResponse) {
+	s.byHeight[h.Header.Height] = h
+	s.tip = h
+}
+
+// Tip returns the highest verified header, or nil if none are stored
+func (s *HeaderStore) Tip() *rpc.HeaderResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tip
+}
+
+// Get returns the verified header at height, if stored
+func (s *HeaderStore) Get(height uint64) *rpc.HeaderResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.byHeight[height]
+}
+
+// Close closes the header file
+func (s *HeaderStore) Close() error {
+	return s.file.Close()
+}
+
+// syncFrom extends the verified header chain from a full node, anchoring
+// at the latest checkpoint if nothing is stored yet
+func (n *LiteNode) syncFrom(client *rpc.NodeClient) error {
+	n.syncMu.Lock()
+	defer n.syncMu.Unlock()
+
+	tip := n.headers.Tip()
+	if tip == nil {
+		anchor, err := n.fetchCheckpoint(client)
+		if err != nil {
+			return err
+		}
+		if err := n.headers.Append(anchor); err != nil {
+			return err
+		}
+		tip = anchor
+	}
+
+	if err := n.updateFinalized(client); err != nil {
+		return err
+	}
+
+	height, err := client.GetBlockHeight()
+	if err != nil {
+		return err
+	}
+	for tip.Header.Height < height {
+		batch, err := client.GetHeaders(tip.Header.Height+1, rpc.MaxHeaderBatch)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, h := range batch {
+			if err := verifyHeader(tip, h, n.checkpoints.ValidatorsAt(h.Header.Height)); err != nil {
+				return fmt.Errorf("header %d: %v", tip.Header.Height+1, err)
+			}
+			if cp := n.checkpoints.At(h.Header.Height); cp != nil && cp.Hash != h.Hash {
+				return fmt.Errorf("header %d: %v", h.Header.Height, ErrCheckpointMismatch)
+			}
+			if f := n.finalized; f != nil && f.Height == h.Header.Height && f.BlockHash != h.Hash {
+				return fmt.Errorf("header %d: %v", h.Header.Height, ErrFinalizedConflict)
+			}
+			// Headers carry their parent's state root, so the block after a
+			// snapshot is the first to vouch for the snapshot's state
+			if s := n.snapshot; s != nil && h.Header.Height == s.Height+1 && h.Header.StateRoot != s.StateRoot {
+				return fmt.Errorf("header %d: %v", h.Header.Height, ErrSnapshotState)
+			}
+			if err := n.headers.Append(h); err != nil {
+				return err
+			}
+			tip = h
+		}
+	}
+
+	if tip.Header.Height > n.CurrentHeight {
+		n.CurrentHeight = tip.Header.Height
+	}
+	n.LastSync = time.Now()
+	return nil
+}
+
+// fetchCheckpoint fetches the header at the latest checkpoint and checks it
+// against the pinned hash
+func (n *LiteNode) fetchCheckpoint(client *rpc.NodeClient) (*rpc.HeaderResponse, error) {
+	cp := n.checkpoints.Latest()
+	if cp == nil {
+		return nil, ErrNoCheckpoint
+	}
+	headers, err := client.GetHeaders(cp.Height, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("peer has no block at checkpoint height %d", cp.Height)
+	}
+	anchor := headers[0]
+	if err := checkHeaderHash(anchor); err != nil {
+		return nil, err
+	}
+	if anchor.Hash != cp.Hash || anchor.Header.Height != cp.Height {
+		return nil, ErrCheckpointMismatch
+	}
+	return anchor, nil
+}
+
+// updateFinalized fetches the node's latest signed checkpoint and adopts it
+// as the finalized height once it is verified against the trusted
+// validators. A node serving a checkpoint that conflicts with the verified
+// headers or an earlier finalized checkpoint is refused, so the lite node
+// never follows a reorg past a finalized block. Callers must hold n.syncMu.
+func (n *LiteNode) updateFinalized(client *rpc.NodeClient) error {
+	cp, err := client.GetLatestCheckpoint()
+	if err != nil {
+		// Nodes without checkpoints yet still serve headers
+		return nil
+	}
+	if f := n.finalized; f != nil && cp.Height <= f.Height {
+		if cp.Height == f.Height && cp.BlockHash != f.BlockHash {
+			return fmt.Errorf("checkpoint %d: %v", cp.Height, ErrFinalizedConflict)
+		}
+		return nil
+	}
+
+	trusted := n.checkpoints.ValidatorsAt(cp.Height)
+	if len(trusted) == 0 {
+		return fmt.Errorf("checkpoint %d: %v", cp.Height, chain.ErrUntrustedCheckpoint)
+	}
+	if err := cp.Verify(trusted); err != nil {
+		return fmt.Errorf("checkpoint %d: %v", cp.Height, err)
+	}
+	if h := n.headers.Get(cp.Height); h != nil && h.Hash != cp.BlockHash {
+		return fmt.Errorf("checkpoint %d: %v", cp.Height, ErrFinalizedConflict)
+	}
+
+	n.finalized = cp
+	return n.saveFinalized()
+}
+
+// finalizedPath is where the latest verified checkpoint is kept
+func (n *LiteNode) finalizedPath() string {
+	return filepath.Join(n.DataDir, "finalized.json")
+}
+
+// loadFinalized restores the checkpoint verified before a restart
+func (n *LiteNode) loadFinalized() {
+	data, err := ioutil.ReadFile(n.finalizedPath())
+	if err != nil {
+		return
+	}
+	var cp chain.Checkpoint
+	if err := json.Unmarshal(data, &cp); err == nil {
+		n.finalized = &cp
+	}
+}
+
+// saveFinalized writes the latest verified checkpoint to the data dir
+func (n *LiteNode) saveFinalized() error {
+	data, err := json.Marshal(n.finalized)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(n.finalizedPath(), data, 0644)
+}
@@ -9,20 +9,35 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/p2p"
+	"github.com/gydschain/gydschain/internal/rpc"
 )
 
 // LiteNode represents a light client that syncs with the network
 type LiteNode struct {
-	NodeID         string
-	DataDir        string
-	BootstrapNodes []string
-	SyncMode       string
-	CurrentHeight  uint64
-	PeerCount      int
-	Syncing        bool
-	LastSync       time.Time
+	NodeID        string
+	DataDir       string
+	SyncMode      string
+	RPCURL        string
+	CurrentHeight uint64
+	PeerCount     int
+	Syncing       bool
+	LastSync      time.Time
+
+	headers        *HeaderStore
+	checkpoints    *Checkpoints
+	finalized      *chain.Checkpoint  // latest verified signed checkpoint
+	snapshot       *p2p.AdminSnapshot // snapshot the headers were anchored at this run
+	syncMu         sync.Mutex
+	bootstrapNodes []BootstrapNode
+	peersMu        sync.RWMutex
 }
 
 // BootstrapNode represents a peer to sync from
@@ -37,6 +52,9 @@ func main() {
 	configPath := flag.String("config", "config/litenode.json", "Path to lite node config")
 	syncMode := flag.String("sync-mode", "light", "Sync mode: light or ultralight")
 	bootstrapFile := flag.String("bootstrap-nodes", "config/bootstrap.json", "Bootstrap nodes file")
+	snapshotFile := flag.String("snapshot", "config/snapshot.json", "Snapshot descriptor from the admin server")
+	checkpointFile := flag.String("checkpoints", "config/checkpoints.json", "Trusted checkpoints to verify headers from")
+	rpcURL := flag.String("rpc", "", "Full node RPC endpoint for verified account queries (default: first bootstrap node)")
 	flag.Parse()
 
 	fmt.Println("🌐 Starting GYDS Chain Lite Node...")
@@ -57,19 +75,56 @@ func main() {
 
 	// Initialize lite node
 	node := &LiteNode{
-		NodeID:         generateNodeID(),
-		DataDir:        *dataDir,
-		SyncMode:       *syncMode,
-		CurrentHeight:  0,
-		PeerCount:      0,
-		Syncing:        false,
+		NodeID:        generateNodeID(),
+		DataDir:       *dataDir,
+		SyncMode:      *syncMode,
+		RPCURL:        *rpcURL,
+		CurrentHeight: 0,
+		PeerCount:     0,
+		Syncing:       false,
+	}
+	node.SetBootstrapNodes(bootstrapNodes)
+
+	// Keep the bootstrap set current from a signed list, if configured
+	var fetcher *BootstrapFetcher
+	if cfg, err := loadLiteConfig(*configPath); err == nil && cfg.BootstrapURL != "" {
+		pubKey, err := crypto.ParsePublicKey(cfg.BootstrapKey)
+		if err != nil {
+			log.Fatalf("Invalid bootstrap_key in %s: %v", *configPath, err)
+		}
+		fetcher = NewBootstrapFetcher(node, cfg.BootstrapURL, pubKey,
+			time.Duration(cfg.BootstrapRefresh)*time.Second, filepath.Join(*dataDir, "bootstrap.signed.json"))
+		fetcher.LoadSaved()
+		fetcher.Start()
+	}
+
+	// Headers are only trusted from a checkpoint forward
+	checkpoints, err := loadCheckpoints(*checkpointFile)
+	if err != nil {
+		log.Printf("Warning: Could not load checkpoints, headers cannot be verified: %v", err)
+		checkpoints, _ = newCheckpoints(nil)
 	}
+	node.checkpoints = checkpoints
+
+	node.headers, err = OpenHeaderStore(filepath.Join(*dataDir, "headers.jsonl"))
+	if err != nil {
+		log.Fatalf("Failed to open header store: %v", err)
+	}
+	defer node.headers.Close()
 
 	// Load existing state
 	node.loadState()
+	node.loadFinalized()
+
+	// Cold start from a verified snapshot before switching to live sync
+	if snap, err := p2p.LoadAdminSnapshot(*snapshotFile); err == nil {
+		if err := node.restoreSnapshot(snap); err != nil {
+			log.Printf("Warning: Snapshot restore failed, syncing from peers: %v", err)
+		}
+	}
 
 	// Start syncing
-	go node.startSync(bootstrapNodes)
+	go node.startSync()
 
 	// Start health endpoint
 	go node.startHealthServer()
@@ -79,7 +134,7 @@ func main() {
 	fmt.Println("========================================")
 	fmt.Printf("   Node ID: %s\n", node.NodeID[:16]+"...")
 	fmt.Printf("   Current Height: %d\n", node.CurrentHeight)
-	fmt.Printf("   Bootstrap Peers: %d\n", len(bootstrapNodes))
+	fmt.Printf("   Bootstrap Peers: %d\n", len(node.BootstrapNodes()))
 	fmt.Println("========================================")
 	fmt.Println("\nPress Ctrl+C to stop the node...")
 
@@ -89,9 +144,11 @@ func main() {
 	<-sigChan
 
 	fmt.Println("\n🛑 Shutting down Lite Node...")
+	if fetcher != nil {
+		fetcher.Stop()
+	}
 	node.saveState()
 	fmt.Println("✅ Lite Node stopped successfully")
-	_ = configPath // config loading placeholder
 }
 
 func loadBootstrapNodes(path string) ([]BootstrapNode, error) {
@@ -153,15 +210,17 @@ func (n *LiteNode) saveState() {
 	ioutil.WriteFile(statePath, data, 0644)
 }
 
-func (n *LiteNode) startSync(bootstrapNodes []BootstrapNode) {
+func (n *LiteNode) startSync() {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		n.syncHeaders(bootstrapNodes)
+		n.syncHeaders(n.BootstrapNodes())
 	}
 }
 
+// syncHeaders extends the verified header chain from the first bootstrap
+// node that serves a valid one
 func (n *LiteNode) syncHeaders(bootstrapNodes []BootstrapNode) {
 	if len(bootstrapNodes) == 0 {
 		return
@@ -171,50 +230,31 @@ func (n *LiteNode) syncHeaders(bootstrapNodes []BootstrapNode) {
 	defer func() { n.Syncing = false }()
 
 	for _, peer := range bootstrapNodes {
-		// Fetch latest block height from peer
-		resp, err := http.Get(fmt.Sprintf("http://%s/rpc/block/latest", peer.Address))
-		if err != nil {
+		client := rpc.NewNodeClient(peerURL(peer))
+		if err := n.syncFrom(client); err != nil {
+			log.Printf("Header sync from %s failed: %v", peer.Address, err)
 			continue
 		}
-		defer resp.Body.Close()
-
-		var block struct {
-			Height uint64 `json:"height"`
-			Hash   string `json:"hash"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&block); err != nil {
-			continue
-		}
-
-		if block.Height > n.CurrentHeight {
-			// Sync headers
-			n.syncHeadersFromPeer(peer.Address, n.CurrentHeight, block.Height)
-			n.CurrentHeight = block.Height
-			n.LastSync = time.Now()
-			n.PeerCount = len(bootstrapNodes)
-			log.Printf("Synced to height %d from %s", block.Height, peer.Address)
-		}
+		n.PeerCount = len(bootstrapNodes)
 		break
 	}
 }
 
-func (n *LiteNode) syncHeadersFromPeer(peerAddr string, from, to uint64) {
-	// Light sync - only fetch block headers
-	batchSize := uint64(100)
-	for height := from; height < to; height += batchSize {
-		end := height + batchSize
-		if end > to {
-			end = to
-		}
+// peerURL returns the RPC endpoint of a bootstrap node
+func peerURL(peer BootstrapNode) string {
+	return fmt.Sprintf("http://%s/", peer.Address)
+}
 
-		url := fmt.Sprintf("http://%s/rpc/headers?from=%d&to=%d", peerAddr, height, end)
-		resp, err := http.Get(url)
-		if err != nil {
-			continue
-		}
-		resp.Body.Close()
+// rpcURL returns the full node queried for account proofs: the --rpc
+// endpoint, or else the first current bootstrap node
+func (n *LiteNode) rpcURL() string {
+	if n.RPCURL != "" {
+		return n.RPCURL
 	}
+	if peers := n.BootstrapNodes(); len(peers) > 0 {
+		return peerURL(peers[0])
+	}
+	return ""
 }
 
 func (n *LiteNode) startHealthServer() {
@@ -227,8 +267,19 @@ func (n *LiteNode) startHealthServer() {
 			"last_sync":      n.LastSync,
 			"sync_mode":      n.SyncMode,
 		}
+		if tip := n.headers.Tip(); tip != nil {
+			status["verified_height"] = tip.Header.Height
+			status["verified_hash"] = tip.Hash
+		}
+		if f := n.finalized; f != nil {
+			status["finalized_height"] = f.Height
+			status["finalized_hash"] = f.BlockHash
+		}
 		json.NewEncoder(w).Encode(status)
 	})
+	http.HandleFunc("/account", n.handleAccount)
+	http.HandleFunc("/balance", n.handleBalance)
+	http.HandleFunc("/tx", n.handleTx)
 
 	http.ListenAndServe(":8547", nil)
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/state"
+)
+
+// VerifiedAccount is an account proven against a block's state root
+type VerifiedAccount struct {
+	Address   string               `json:"address"`
+	Exists    bool                 `json:"exists"`
+	Account   *rpc.AccountResponse `json:"account,omitempty"`
+	Height    uint64               `json:"height"`
+	BlockHash string               `json:"block_hash"`
+	StateRoot string               `json:"state_root"`
+}
+
+// proofHeaderWait bounds how long a proof waits for the block committing to it
+const proofHeaderWait = 30 * time.Second
+
+// verifyAccount fetches an account proof from a full node and checks it
+// against the state root of the verified header that commits to it. A
+// block's StateRoot is the root left by its parent, so a proof at height h
+// is checked against header h+1, which is synced once the node produces it.
+func (n *LiteNode) verifyAccount(client *rpc.NodeClient, address string) (*VerifiedAccount, error) {
+	resp, err := client.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := n.waitHeader(client, resp.Height+1)
+	if err != nil {
+		return nil, fmt.Errorf("no verified header commits to state at height %d: %v", resp.Height, err)
+	}
+
+	proof, err := resp.StateProof()
+	if err != nil {
+		return nil, err
+	}
+	account, err := state.VerifyAccountProof(proof, header.Header.StateRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := &VerifiedAccount{
+		Address:   address,
+		Exists:    account != nil,
+		Height:    resp.Height,
+		BlockHash: header.Hash,
+		StateRoot: header.Header.StateRoot,
+	}
+	if account != nil {
+		verified.Account = &rpc.AccountResponse{
+			Address:  account.Address,
+			Nonce:    account.Nonce,
+			Balances: make(map[string]string, len(account.Balances)),
+			Contract: account.IsContract(),
+		}
+		for asset, balance := range account.Balances {
+			verified.Account.Balances[asset] = balance.String()
+		}
+	}
+	return verified, nil
+}
+
+// waitHeader returns the verified header at height, syncing from client
+// for up to proofHeaderWait while the chain has not reached it
+func (n *LiteNode) waitHeader(client *rpc.NodeClient, height uint64) (*rpc.HeaderResponse, error) {
+	deadline := time.Now().Add(proofHeaderWait)
+	for {
+		if header := n.headers.Get(height); header != nil {
+			return header, nil
+		}
+		if err := n.syncFrom(client); err != nil {
+			return nil, err
+		}
+		if header := n.headers.Get(height); header != nil {
+			return header, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for header %d", height)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// handleAccount serves accounts verified against a full node's state proofs
+func (n *LiteNode) handleAccount(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		http.Error(w, "address is required", http.StatusBadRequest)
+		return
+	}
+	client := n.queryClient(w)
+	if client == nil {
+		return
+	}
+
+	verified, err := n.verifyAccount(client, address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verified)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// VerifiedBalance is one asset balance and the nonce of a proven account
+type VerifiedBalance struct {
+	Address   string `json:"address"`
+	Asset     string `json:"asset"`
+	Balance   string `json:"balance"`
+	Nonce     uint64 `json:"nonce"`
+	Height    uint64 `json:"height"`
+	BlockHash string `json:"block_hash"`
+}
+
+// VerifiedTx is a transaction proven to be in a verified block. A full
+// node reporting no receipt cannot be proven, so Included is only ever
+// false as "not found", never as proof of absence.
+type VerifiedTx struct {
+	Hash        string          `json:"hash"`
+	Included    bool            `json:"included"`
+	BlockHash   string          `json:"block_hash,omitempty"`
+	Height      uint64          `json:"height,omitempty"`
+	Index       uint64          `json:"index"`
+	Status      uint64          `json:"status"` // reported by the full node, not proven
+	Transaction *tx.Transaction `json:"transaction,omitempty"`
+}
+
+// verifyTx fetches an inclusion proof and checks it against the TxRoot of
+// the verified header at the reported height
+func (n *LiteNode) verifyTx(client *rpc.NodeClient, hash string) (*VerifiedTx, error) {
+	resp, err := client.GetTxProof(hash)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return &VerifiedTx{Hash: hash}, nil
+	}
+	if resp.Hash != hash {
+		return nil, chain.ErrInvalidTxProof
+	}
+
+	header, err := n.waitHeader(client, resp.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("no verified header at height %d: %v", resp.BlockNumber, err)
+	}
+	if header.Hash != resp.BlockHash {
+		return nil, ErrHeaderHash
+	}
+	if err := resp.Verify(header.Header.TxRoot); err != nil {
+		return nil, err
+	}
+
+	return &VerifiedTx{
+		Hash:        hash,
+		Included:    true,
+		BlockHash:   header.Hash,
+		Height:      resp.BlockNumber,
+		Index:       resp.TxIndex,
+		Status:      resp.Status,
+		Transaction: resp.Transaction,
+	}, nil
+}
+
+// queryClient returns a client for the configured full node, writing an
+// error response if there is none
+func (n *LiteNode) queryClient(w http.ResponseWriter) *rpc.NodeClient {
+	url := n.rpcURL()
+	if url == "" {
+		http.Error(w, "no full node RPC endpoint configured", http.StatusServiceUnavailable)
+		return nil
+	}
+	return rpc.NewNodeClient(url)
+}
+
+// handleBalance serves an asset balance and nonce verified against a state proof
+func (n *LiteNode) handleBalance(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		http.Error(w, "address is required", http.StatusBadRequest)
+		return
+	}
+	asset := r.URL.Query().Get("asset")
+	if asset == "" {
+		asset = "GYDS"
+	}
+	client := n.queryClient(w)
+	if client == nil {
+		return
+	}
+
+	verified, err := n.verifyAccount(client, address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	balance := &VerifiedBalance{
+		Address:   address,
+		Asset:     asset,
+		Balance:   "0",
+		Height:    verified.Height,
+		BlockHash: verified.BlockHash,
+	}
+	if verified.Account != nil {
+		balance.Nonce = verified.Account.Nonce
+		if amount, ok := verified.Account.Balances[asset]; ok {
+			balance.Balance = amount
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+// handleTx serves a transaction verified against its block's TxRoot
+func (n *LiteNode) handleTx(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Query().Get("hash")
+	if hash == "" {
+		http.Error(w, "hash is required", http.StatusBadRequest)
+		return
+	}
+	client := n.queryClient(w)
+	if client == nil {
+		return
+	}
+
+	verified, err := n.verifyTx(client, hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verified)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/p2p"
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// restoreSnapshot downloads and verifies a snapshot, then anchors the
+// verified header chain at its head so live sync continues from there.
+// Nodes that already verify headers keep following them.
+func (n *LiteNode) restoreSnapshot(info *p2p.AdminSnapshot) error {
+	if n.CurrentHeight >= info.Height || n.headers.Tip() != nil {
+		return nil
+	}
+
+	log.Printf("Downloading snapshot at height %d from %s", info.Height, info.URL)
+
+	// The snapshot's state is checked against its state root and its
+	// blocks against its block hash before anything is trusted
+	snap, err := info.Download(filepath.Join(n.DataDir, "snapshot"))
+	if err != nil {
+		return err
+	}
+	head := snap.Blocks[len(snap.Blocks)-1]
+	anchor := &rpc.HeaderResponse{
+		Hash:      snap.BlockHash,
+		Header:    head.Header,
+		Validator: head.Validator,
+		Signature: hex.EncodeToString(head.Signature),
+	}
+	if err := n.checkSnapshotHead(anchor); err != nil {
+		return err
+	}
+	if err := n.headers.Append(anchor); err != nil {
+		return err
+	}
+
+	n.snapshot = info
+	n.CurrentHeight = snap.Height
+	n.LastSync = time.Now()
+	n.saveState()
+
+	log.Printf("Snapshot verified and restored at height %d (block %s)", snap.Height, snap.BlockHash)
+	return nil
+}
+
+// checkSnapshotHead checks a snapshot head against the trusted checkpoints.
+// A head pinned or finalized at its height must match it and is trusted;
+// any other head must be signed by one of the validators trusted at its
+// height. A head neither covers is refused, so a node without checkpoints
+// never anchors to a snapshot it cannot check.
+func (n *LiteNode) checkSnapshotHead(head *rpc.HeaderResponse) error {
+	height := head.Header.Height
+	if cp := n.checkpoints.At(height); cp != nil {
+		if cp.Hash != head.Hash {
+			return fmt.Errorf("snapshot head %d: %v", height, ErrCheckpointMismatch)
+		}
+		return nil
+	}
+	if f := n.finalized; f != nil && f.Height == height {
+		if f.BlockHash != head.Hash {
+			return fmt.Errorf("snapshot head %d: %v", height, ErrFinalizedConflict)
+		}
+		return nil
+	}
+
+	validators := n.checkpoints.ValidatorsAt(height)
+	if len(validators) == 0 {
+		return fmt.Errorf("snapshot head %d: %v", height, ErrUntrustedSnapshot)
+	}
+	pubKey, ok := validators[head.Validator]
+	if !ok {
+		return fmt.Errorf("snapshot head %d: %v", height, ErrUnknownProposer)
+	}
+	signature, err := hex.DecodeString(head.Signature)
+	if err != nil {
+		return chain.ErrInvalidBlockSignature
+	}
+	return chain.VerifyHeaderSignature(head.Header, signature, pubKey)
+}
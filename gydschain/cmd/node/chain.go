@@ -0,0 +1,237 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/config"
+)
+
+// runChainCommand handles `gydsnode chain <export|import|snapshot>`
+func runChainCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: gydsnode chain <export|import|snapshot> [flags]")
+	}
+
+	switch args[0] {
+	case "export":
+		return exportChain(args[1:])
+	case "import":
+		return importChain(args[1:])
+	case "snapshot":
+		return snapshotChain(args[1:])
+	default:
+		return fmt.Errorf("unknown chain command: %s", args[0])
+	}
+}
+
+// chainFlags are the flags every chain command needs to rebuild the chain
+// the way the node does
+type chainFlags struct {
+	configPath  *string
+	genesisPath *string
+	dataDir     *string
+	importPath  *string
+}
+
+func addChainFlags(fs *flag.FlagSet) *chainFlags {
+	return &chainFlags{
+		configPath:  fs.String("config", "config.json", "Path to configuration file"),
+		genesisPath: fs.String("genesis", "genesis.json", "Path to genesis file"),
+		dataDir:     fs.String("data", "./data", "Data directory holding blocks.jsonl"),
+		importPath:  fs.String("import-accounts", "", "JSONL account export the node was seeded with, if any"),
+	}
+}
+
+// load reads the config and genesis the chain is rebuilt from
+func (f *chainFlags) load() (*config.Config, *chain.GenesisConfig, error) {
+	cfg, err := config.LoadConfig(*f.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not load config, using defaults: %v\n", err)
+		cfg = config.DefaultConfig()
+	}
+	cfg.DataDir = *f.dataDir
+
+	genesis, err := chain.LoadGenesis(*f.genesisPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load genesis: %v", err)
+	}
+	return cfg, genesis, nil
+}
+
+// rebuildChain re-executes the data directory's block log from genesis
+func (f *chainFlags) rebuildChain() (*chain.Chain, error) {
+	cfg, genesis, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	_, blockchain, err := newReplayChain(cfg, genesis, *f.importPath)
+	if err != nil {
+		return nil, err
+	}
+	err = chain.ReadBlockLog(cfg.GetDataPath("blocks.jsonl"), func(block *chain.Block) error {
+		if err := blockchain.AddBlock(block); err != nil {
+			return fmt.Errorf("block %d: %v", block.Header.Height, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blockchain, nil
+}
+
+// exportChain writes the chain in a stopped node's data directory to a
+// compressed export. A running node exports with admin_exportChain.
+func exportChain(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	flags := addChainFlags(fs)
+	out := fs.String("out", "chain.json.gz", "Compressed chain export to write")
+	fs.Parse(args)
+
+	blockchain, err := flags.rebuildChain()
+	if err != nil {
+		return err
+	}
+	data, err := blockchain.Export()
+	if err != nil {
+		return err
+	}
+	if err := chain.WriteCompressed(*out, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d blocks to %s\n", blockchain.Height()+1, *out)
+	return printChecksum(*out)
+}
+
+// importChain re-executes an export into a fresh data directory. Every
+// block is validated as if received from a peer, so the export itself
+// does not need to be trusted. The imported head is also written as a
+// snapshot the node can start from.
+func importChain(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	flags := addChainFlags(fs)
+	in := fs.String("in", "", "Chain export to import, compressed or not (required)")
+	fs.Parse(args)
+
+	if *in == "" {
+		return fmt.Errorf("--in is required")
+	}
+	cfg, genesis, err := flags.load()
+	if err != nil {
+		return err
+	}
+	logPath := cfg.GetDataPath("blocks.jsonl")
+	if info, err := os.Stat(logPath); err == nil && info.Size() > 0 {
+		return fmt.Errorf("%s already holds blocks; import into a fresh data directory", cfg.DataDir)
+	}
+
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	export, err := chain.ReadExport(data)
+	if err != nil {
+		return fmt.Errorf("invalid chain export: %v", err)
+	}
+	if len(export.Blocks) == 0 {
+		return fmt.Errorf("chain export holds no blocks")
+	}
+
+	_, blockchain, err := newReplayChain(cfg, genesis, *flags.importPath)
+	if err != nil {
+		return err
+	}
+	genesisHash, _ := blockchain.Genesis().Hash()
+	if hash, err := export.Blocks[0].Hash(); err != nil || hash != genesisHash {
+		return fmt.Errorf("chain export starts from genesis %s, not %s", hash, genesisHash)
+	}
+
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		return err
+	}
+	blockLog, err := chain.OpenBlockLog(logPath)
+	if err != nil {
+		return err
+	}
+	defer blockLog.Close()
+	for _, block := range export.Blocks[1:] {
+		if err := blockchain.AddBlock(block); err != nil {
+			return fmt.Errorf("block %d: %v", block.Header.Height, err)
+		}
+		if err := blockLog.Append(block); err != nil {
+			return err
+		}
+	}
+	if err := blockLog.Close(); err != nil {
+		return err
+	}
+
+	snap, err := blockchain.Snapshot()
+	if err != nil {
+		return err
+	}
+	dir := cfg.GetDataPath("snapshots")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("chain-%d.json.gz", snap.Height))
+	if err := snap.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported and validated %d blocks into %s (height %d, state root %s)\n",
+		len(export.Blocks)-1, cfg.DataDir, snap.Height, snap.StateRoot)
+	fmt.Printf("Start the node with --restore-snapshot %s\n", path)
+	return nil
+}
+
+// snapshotChain writes a compressed snapshot of a stopped node's chain
+// head for new nodes to bootstrap from. A running node snapshots with
+// admin_createSnapshot.
+func snapshotChain(args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	flags := addChainFlags(fs)
+	out := fs.String("out", "", "Snapshot file to write (default snapshot-<height>.json.gz)")
+	fs.Parse(args)
+
+	blockchain, err := flags.rebuildChain()
+	if err != nil {
+		return err
+	}
+	snap, err := blockchain.Snapshot()
+	if err != nil {
+		return err
+	}
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("snapshot-%d.json.gz", snap.Height)
+	}
+	if err := snap.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Snapshot at height %d written to %s\n", snap.Height, path)
+	fmt.Printf("   Block hash: %s\n", snap.BlockHash)
+	fmt.Printf("   State root: %s\n", snap.StateRoot)
+	return printChecksum(path)
+}
+
+// printChecksum prints a written file's SHA-256 and size, as the admin
+// server needs them to publish it
+func printChecksum(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	fmt.Printf("   SHA-256: %s (%d bytes)\n", hex.EncodeToString(sum[:]), len(data))
+	return nil
+}
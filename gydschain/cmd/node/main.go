@@ -1,28 +1,68 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gydschain/gydschain/internal/chain"
 	"github.com/gydschain/gydschain/internal/config"
 	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/consensus/pow"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/logging"
 	"github.com/gydschain/gydschain/internal/p2p"
 	"github.com/gydschain/gydschain/internal/rpc"
 	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 func main() {
+	// Offline state tooling runs instead of the node
+	if len(os.Args) > 1 && os.Args[1] == "state" {
+		if err := runStateCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "chain" {
+		if err := runChainCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Parse command line flags
 	configPath := flag.String("config", "config.json", "Path to configuration file")
 	genesisPath := flag.String("genesis", "genesis.json", "Path to genesis file")
 	dataDir := flag.String("data", "./data", "Data directory")
 	rpcAddr := flag.String("rpc", "0.0.0.0:8545", "RPC listen address")
 	p2pAddr := flag.String("p2p", "0.0.0.0:26656", "P2P listen address")
+	readOnly := flag.Bool("read-only", false, "Disable tx submission, staking and mining RPC methods")
+	importPath := flag.String("import-accounts", "", "Seed state from a JSONL account export before genesis (forks, rescue networks)")
+	restorePath := flag.String("restore-snapshot", "", "Start from a chain snapshot instead of syncing from genesis")
+	snapshotPath := flag.String("snapshot", "", "Snapshot descriptor from the admin server to cold start from")
+	stateMode := flag.String("state-mode", "", "Historical state mode: archive keeps every height, pruned keeps the retention window (default from config)")
+	logLevel := flag.String("log-level", "", "Log level: debug, info, warn or error (default from config)")
+	logFormat := flag.String("log-format", "", "Log format: text or json (default from config)")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "Time allowed to stop subsystems and flush state on exit")
 	flag.Parse()
 
 	fmt.Println("🚀 Starting GYDS Chain Node...")
@@ -32,6 +72,11 @@ func main() {
 	fmt.Printf("   RPC: %s\n", *rpcAddr)
 	fmt.Printf("   P2P: %s\n", *p2pAddr)
 
+	// The root context is cancelled by SIGINT or SIGTERM and bounds every
+	// background task started below
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
 	// Load configuration
 	cfg, err := config.Load(*configPath)
 	if err != nil {
@@ -40,21 +85,73 @@ func main() {
 	}
 
 	// Override with command line flags
+	if *logLevel != "" {
+		cfg.LogLevel = *logLevel
+	}
+	if *logFormat != "" {
+		cfg.LogFormat = *logFormat
+	}
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat, os.Stderr); err != nil {
+		log.Fatalf("Invalid logging config: %v", err)
+	}
 	cfg.RPC.ListenAddr = *rpcAddr
 	cfg.P2P.ListenAddr = *p2pAddr
 	cfg.DataDir = *dataDir
+	switch *stateMode {
+	case "":
+	case "archive":
+		cfg.Chain.Archive = true
+	case "pruned":
+		cfg.Chain.Archive = false
+	default:
+		log.Fatalf("Invalid --state-mode %q (archive or pruned)", *stateMode)
+	}
 
 	// Initialize state database
 	stateDB := state.NewStateDB()
-	fmt.Println("✅ State database initialized")
+	if cfg.Chain.Archive {
+		stateDB.SetArchive(state.NewArchive(0))
+		fmt.Println("✅ State database initialized (archive)")
+	} else {
+		stateDB.SetArchive(state.NewArchive(cfg.Chain.StateHistory))
+		fmt.Printf("✅ State database initialized (pruned, %d heights)\n", cfg.Chain.StateHistory)
+	}
+
+	// Imported accounts are committed with genesis; genesis alloc wins on conflict
+	if *importPath != "" {
+		f, err := os.Open(*importPath)
+		if err != nil {
+			log.Fatalf("Failed to open account import: %v", err)
+		}
+		count, err := stateDB.ImportAccounts(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("Failed to import accounts: %v", err)
+		}
+		fmt.Printf("✅ Imported %d accounts from %s\n", count, *importPath)
+	}
 
 	// Initialize blockchain
 	chainConfig := chain.DefaultConfig()
+	chainConfig.BlockGasLimit = cfg.Chain.BlockGasLimit
+	chainConfig.BlockGasTarget = cfg.Chain.BlockGasTarget
 	blockchain, err := chain.NewChain(chainConfig, stateDB)
 	if err != nil {
 		log.Fatalf("Failed to create chain: %v", err)
 	}
 
+	// Index recent logs on-node so chain_getLogs works without the indexer
+	logIndex := chain.NewLogIndex(cfg.Chain.LogRetention)
+	logIndex.SetLimits(cfg.Chain.MaxLogRange, cfg.Chain.MaxLogResults)
+	blockchain.SetLogIndex(logIndex)
+
+	// Experimental subsystems ship dark unless enabled for this network
+	features := tx.NewFeatures(cfg.Experimental.Enabled()...)
+	blockchain.SetFeatures(features)
+	if names := features.Names(); len(names) > 0 {
+		log.Printf("Warning: Experimental features enabled: %s", strings.Join(names, ", "))
+	}
+
 	// Load genesis
 	genesis, err := chain.LoadGenesis(*genesisPath)
 	if err != nil {
@@ -62,19 +159,192 @@ func main() {
 		genesis = chain.DefaultGenesis()
 	}
 
-	if err := blockchain.InitGenesis(genesis); err != nil {
-		log.Fatalf("Failed to initialize genesis: %v", err)
-	}
-	fmt.Println("✅ Genesis block initialized")
-
 	// Initialize consensus engine
+	minStake, err := util.ParseBig(cfg.Consensus.MinStake)
+	if err != nil {
+		log.Fatalf("Invalid consensus min_stake: %v", err)
+	}
 	posEngine := pos.NewEngine(
-		cfg.Consensus.MinStake,
+		minStake,
 		uint32(cfg.Consensus.MaxValidators),
 		cfg.Consensus.BlockTime,
 	)
+	// Undelegated stake stays locked for the genesis unbonding period
+	if genesis.Params.UnbondingTime > 0 {
+		posEngine.SetUnbondingTime(time.Duration(genesis.Params.UnbondingTime) * time.Second)
+	}
+	blockchain.SetStaking(posEngine)
+	blockchain.SetRewardSource(posEngine)
+	blockchain.SetProposerSchedule(posEngine)
 	fmt.Println("✅ PoS consensus engine initialized")
 
+	// Check clock drift at startup and periodically; a drifting validator
+	// refuses to validate until its clock is fixed
+	var clockMonitor *util.ClockMonitor
+	if cfg.Network.ClockCheck {
+		clockMonitor = util.NewClockMonitor(
+			cfg.Network.NTPServers,
+			time.Duration(cfg.Network.MaxClockDrift)*time.Millisecond,
+			time.Duration(cfg.Network.ClockInterval)*time.Second,
+		)
+		clockMonitor.Start()
+		posEngine.SetClockMonitor(clockMonitor)
+		if status := clockMonitor.Status(); status != nil && status.Error == "" {
+			fmt.Printf("✅ Clock drift %dms (max %dms)\n", status.OffsetMs, status.MaxDriftMs)
+		}
+	}
+	// Slashed stake stays in escrow for the genesis appeal window, during
+	// which validators and guardians can vote to reverse the slash
+	slashingKeeper := pos.NewSlashingKeeper(posEngine, nil)
+	if genesis.Params.AppealWindow > 0 {
+		slashingKeeper.SetAppealWindow(time.Duration(genesis.Params.AppealWindow) * time.Second)
+	}
+	blockchain.SetSlashingKeeper(slashingKeeper)
+
+	// Double-sign evidence from competing blocks and peers is slashed when
+	// a block includes it
+	evidencePool := chain.NewEvidencePool(posEngine, slashingKeeper)
+	blockchain.SetEvidencePool(evidencePool)
+
+	// Emergency halt circuit breaker (guardians only during bootstrap)
+	blockchain.SetCircuitBreaker(pos.NewCircuitBreaker(posEngine, cfg.Chain.Guardians, cfg.Chain.GuardianThreshold))
+
+	// Validators vote GYDS and GYD prices every oracle update interval and
+	// the medians price the GYD vaults, where GYDS locked at the genesis
+	// reserve ratio mints GYD
+	oracleWindow := pos.OracleWindow(genesis.Params.OracleUpdateFreq, genesis.Params.BlockTime)
+	blockchain.SetOracle(pos.NewPriceOracle(posEngine, slashingKeeper, oracleWindow,
+		[]string{chain.CollateralAsset, chain.StablecoinAsset}))
+	blockchain.SetStablecoin(chain.NewStablecoin(
+		chainConfig.StablecoinPeg,
+		genesis.Params.StablecoinReserve,
+		chain.OracleMaxAge(oracleWindow),
+	))
+
+	// Commit-reveal randomness beacon, also reseeds leader selection each epoch
+	blockchain.SetBeacon(pos.NewRandomnessBeacon(posEngine, cfg.Chain.BeaconEpoch))
+
+	// Per-epoch validator performance summaries, aligned with beacon epochs
+	epochs := pos.NewEpochTracker(posEngine, slashingKeeper, cfg.Chain.BeaconEpoch)
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		log.Fatalf("Failed to create data dir: %v", err)
+	}
+	if err := epochs.Persist(cfg.GetDataPath("epochs.jsonl")); err != nil {
+		log.Fatalf("Failed to load epoch summaries: %v", err)
+	}
+	blockchain.SetEpochTracker(epochs)
+
+	// Snapshots carry the state of the components above, so the chain is
+	// restored once they are all attached
+	restored := false
+	if *restorePath != "" {
+		if *importPath != "" {
+			log.Fatalf("--restore-snapshot and --import-accounts cannot be combined")
+		}
+		snap, err := chain.LoadSnapshot(*restorePath)
+		if err != nil {
+			log.Fatalf("Failed to load snapshot: %v", err)
+		}
+		if err := blockchain.RestoreSnapshot(genesis, snap); err != nil {
+			log.Fatalf("Failed to restore snapshot: %v", err)
+		}
+		restored = true
+		fmt.Printf("✅ Restored snapshot at height %d (state root %s)\n", snap.Height, snap.StateRoot)
+	} else if *snapshotPath != "" {
+		if *importPath != "" {
+			log.Fatalf("--snapshot and --import-accounts cannot be combined")
+		}
+		// Cold start from the admin server's snapshot once it matches its
+		// checksum, block hash and state root, else start from genesis
+		info, err := p2p.LoadAdminSnapshot(*snapshotPath)
+		var snap *chain.ChainSnapshot
+		if err == nil {
+			snap, err = info.Download(cfg.GetDataPath("snapshot"))
+		}
+		if err == nil {
+			err = blockchain.RestoreSnapshot(genesis, snap)
+		}
+		if err != nil {
+			log.Printf("Warning: Admin snapshot not restored, starting from genesis: %v", err)
+		} else {
+			restored = true
+			fmt.Printf("✅ Restored admin snapshot at height %d (state root %s)\n", snap.Height, snap.StateRoot)
+		}
+	}
+	if !restored {
+		if err := blockchain.InitGenesis(genesis); err != nil {
+			log.Fatalf("Failed to initialize genesis: %v", err)
+		}
+		fmt.Println("✅ Genesis block initialized")
+	}
+
+	// Prevote/precommit round finalizing blocks with 2/3 stake quorums
+	finality := pos.NewFinality(posEngine)
+	var validatorKey *crypto.KeyPair
+	if cfg.Validator.Enabled && cfg.Validator.ValidatorKey != "" {
+		validatorKey, err = loadValidatorKey(cfg.Validator.ValidatorKey)
+		if err != nil {
+			log.Fatalf("Failed to load validator key: %v", err)
+		}
+		finality.SetSigner(validatorKey.Address(), validatorKey)
+	}
+	finality.OnCommit(func(cert *pos.CommitCertificate) {
+		if err := blockchain.AddCommit(cert); err != nil {
+			log.Printf("Warning: Commit certificate at height %d rejected: %v", cert.Height, err)
+		}
+	})
+	blockchain.SetFinality(finality)
+	blockchain.SetCheckpointInterval(cfg.Chain.CheckpointInterval)
+	var votes sync.WaitGroup // prevotes in flight, drained on shutdown
+	blockchain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+		if ctx.Err() != nil {
+			return
+		}
+		// Voting re-enters the chain once a quorum forms, so it runs
+		// outside the listener
+		votes.Add(1)
+		go func() {
+			defer votes.Done()
+			if err := finality.Prevote(block.Header.Height, 0, hash); err != nil {
+				log.Printf("Warning: Prevote at height %d failed: %v", block.Header.Height, err)
+			}
+		}()
+	})
+
+	// Pending transaction pool; applied blocks drop their txs and record
+	// time-to-inclusion
+	mempoolConfig := tx.DefaultMempoolConfig()
+	mempoolConfig.MaxMemoSize = cfg.Chain.MaxMemoSize
+	mempool := tx.NewMempool(mempoolConfig)
+	mempool.SetFeatures(features)
+	mempool.SetDustPolicy(blockchain.Dust())
+	blockchain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+		mempool.Update(block.Header.Height, block.Transactions)
+	})
+
+	// Every added block is logged so `gydsnode replay` can re-execute it
+	blockLog, err := chain.OpenBlockLog(cfg.GetDataPath("blocks.jsonl"))
+	if err != nil {
+		log.Fatalf("Failed to open block log: %v", err)
+	}
+	blockchain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+		if err := blockLog.Append(block); err != nil {
+			log.Printf("Warning: Block log append at height %d failed: %v", block.Header.Height, err)
+		}
+	})
+
+	// Periodic on-disk state snapshots, readable by `gydsnode state export-accounts`
+	var snapshots *state.Snapshotter
+	if cfg.Chain.SnapshotInterval > 0 {
+		snapshots = state.NewSnapshotter(cfg.GetDataPath("snapshots"), cfg.Chain.SnapshotInterval, cfg.Chain.SnapshotKeep)
+		blockchain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+			if _, err := snapshots.MaybeWrite(stateDB, block.Header.Height); err != nil {
+				log.Printf("Warning: State snapshot at height %d failed: %v", block.Header.Height, err)
+			}
+		})
+		fmt.Printf("✅ State snapshots every %d blocks\n", cfg.Chain.SnapshotInterval)
+	}
+
 	// Initialize P2P node
 	p2pConfig := &p2p.NodeConfig{
 		ListenAddr:   cfg.P2P.ListenAddr,
@@ -90,11 +360,138 @@ func main() {
 		log.Fatalf("Failed to create P2P node: %v", err)
 	}
 
+	// Refuse banned and greylisted peers; bans survive restarts
+	banList := p2p.NewBanList()
+	if err := banList.Load(cfg.GetDataPath("banlist.json")); err != nil {
+		log.Printf("Warning: Could not load ban list: %v", err)
+	}
+	p2pNode.SetBanList(banList)
+
+	// Relay proposals and votes from higher-stake validators first
+	p2pNode.SetStakeSource(posEngine)
+
+	// Relay transactions between peers and the mempool, and finality
+	// votes and double-sign evidence between validators
+	txGossip := p2p.NewTxGossip(p2pNode, mempool)
+	finality.OnVote(func(vote *pos.Vote) {
+		p2pNode.BroadcastFrom(p2p.MsgTypeVote, vote, vote.Validator, "")
+	})
+	evidencePool.OnEvidence(func(ev *chain.Evidence) {
+		p2pNode.Broadcast(p2p.MsgTypeEvidence, ev)
+	})
+
+	// Serve a snapshot of the chain head to peers at every snapshot
+	// interval; new nodes restore it instead of syncing from genesis
+	snapSync := p2p.NewSnapSync(p2pNode, blockchain, genesis, cfg.Chain.FastSyncPeers)
+	p2pNode.SetPeerConnectHandler(snapSync.PeerConnected)
+	if cfg.Chain.SnapshotInterval > 0 {
+		blockchain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+			if block.Header.Height%cfg.Chain.SnapshotInterval != 0 {
+				return
+			}
+			// Snapshotting takes the chain lock the listener runs under
+			go func() {
+				if err := snapSync.Publish(); err != nil {
+					log.Printf("Warning: Snapshot publish at height %d failed: %v", block.Header.Height, err)
+				}
+			}()
+		})
+	}
+
+	p2pNode.SetMessageHandler(func(peer *p2p.Peer, msg *p2p.Message) {
+		if txGossip.HandleMessage(peer, msg) || snapSync.HandleMessage(peer, msg) {
+			return
+		}
+		switch msg.Type {
+		case p2p.MsgTypeBlock:
+			var block chain.Block
+			if err := json.Unmarshal(msg.Payload, &block); err != nil {
+				return
+			}
+			if err := blockchain.AddBlock(&block); err != nil {
+				if err != chain.ErrDuplicateBlock {
+					log.Printf("Warning: Rejected block %d from %s: %v", block.Header.Height, peer.ID, err)
+				}
+				return
+			}
+			p2pNode.BroadcastExcept(p2p.MsgTypeBlock, &block, peer.ID)
+		case p2p.MsgTypeVote:
+			var vote pos.Vote
+			if err := json.Unmarshal(msg.Payload, &vote); err != nil {
+				return
+			}
+			if added, err := finality.AddVote(&vote); err == nil && added {
+				p2pNode.BroadcastFrom(p2p.MsgTypeVote, &vote, vote.Validator, peer.ID)
+			}
+		case p2p.MsgTypeEvidence:
+			// New evidence is relayed by the pool's listener
+			var ev chain.Evidence
+			if err := json.Unmarshal(msg.Payload, &ev); err != nil {
+				return
+			}
+			if _, err := evidencePool.Add(&ev); err != nil {
+				log.Printf("Warning: Rejected evidence from %s: %v", peer.ID, err)
+			}
+		}
+	})
+
 	if err := p2pNode.Start(); err != nil {
 		log.Fatalf("Failed to start P2P node: %v", err)
 	}
 	fmt.Printf("✅ P2P node started on %s\n", cfg.P2P.ListenAddr)
 
+	// Fast sync: restore the newest snapshot enough peers agree on, then
+	// fetch the blocks since it
+	if cfg.Chain.FastSync && !restored {
+		go func() {
+			if err := snapSync.Sync(ctx); err != nil {
+				log.Printf("Warning: Fast sync failed, following the chain from height %d: %v", blockchain.Height(), err)
+			}
+		}()
+		fmt.Printf("✅ Fast sync enabled (%d agreeing peers)\n", cfg.Chain.FastSyncPeers)
+	}
+
+	// Propose blocks on the slots this validator leads and gossip them
+	producerDone := make(chan struct{})
+	if validatorKey != nil {
+		producer := chain.NewProducer(blockchain, posEngine, mempool, validatorKey)
+		producer.OnPropose(func(block *chain.Block, hash string) {
+			p2pNode.Broadcast(p2p.MsgTypeBlock, block)
+		})
+		go func() {
+			defer close(producerDone)
+			producer.Run(ctx, func(round uint64, err error) {
+				log.Printf("Warning: Block proposal in round %d failed: %v", round, err)
+			})
+		}()
+		fmt.Printf("✅ Block producer started for %s (every %s)\n", validatorKey.Address(), posEngine.BlockTime())
+	} else {
+		close(producerDone)
+	}
+
+	// Share bans with the admin server and apply its network greylist
+	var greylist *p2p.GreylistSync
+	if cfg.Network.AdminURL != "" {
+		greylist = p2p.NewGreylistSync(p2pNode, cfg.Network.AdminURL, cfg.NodeID,
+			time.Duration(cfg.Network.GreylistSync)*time.Second)
+		greylist.Start()
+		fmt.Printf("✅ Greylist sync with %s\n", cfg.Network.AdminURL)
+	}
+
+	// Mining backend; the external backend serves GPU and pool miners over
+	// mining_getWork and mining_submitWork
+	var miner pow.Miner
+	if cfg.Mining.Enabled {
+		miner, err = pow.NewMiner(cfg.Mining.Backend, pow.BackendConfig{
+			Threads: cfg.Mining.Threads,
+			Devices: cfg.Mining.Devices,
+		})
+		if err != nil {
+			log.Fatalf("Invalid mining config: %v", err)
+		}
+		fmt.Printf("✅ Mining backend: %s\n", cfg.Mining.Backend)
+	}
+
 	// Initialize RPC server
 	rpcConfig := &rpc.Config{
 		ListenAddr:     cfg.RPC.ListenAddr,
@@ -103,9 +500,36 @@ func main() {
 	}
 
 	rpcServer := rpc.NewServer(rpcConfig, blockchain, posEngine, stateDB)
-	if err := rpcServer.Start(); err != nil {
-		log.Fatalf("Failed to start RPC server: %v", err)
+	rpcServer.SetBackend(&rpc.Backend{
+		Chain:    blockchain,
+		State:    stateDB,
+		Engine:   posEngine,
+		Slashing: slashingKeeper,
+		P2P:      p2pNode,
+		Mempool:  mempool,
+		Gossip:   txGossip,
+		Miner:    miner,
+
+		ExportDir: cfg.GetDataPath("exports"),
+	})
+	rpcServer.SetReadOnly(*readOnly || cfg.RPC.ReadOnly)
+	rpcServer.SetFeatures(features)
+	rpcServer.SetMaxBatchSize(cfg.RPC.MaxBatchSize)
+	rpcServer.SetMaxBodySize(cfg.RPC.MaxBodySize)
+	rpcServer.SetRateLimit(float64(cfg.RPC.RateLimit), cfg.RPC.RateBurst)
+	rpcServer.SetTrustProxy(cfg.RPC.TrustProxy)
+	rpcServer.SetEnabledAPIs(cfg.RPC.EnabledAPIs)
+	jwtSecret, err := hex.DecodeString(cfg.RPC.JWTSecret)
+	if err != nil {
+		log.Fatalf("Invalid rpc.jwt_secret: %v", err)
 	}
+	rpcServer.SetAuth(rpc.NewAuthenticator(cfg.RPC.APIKeys, jwtSecret, cfg.RPC.RequireAuth))
+	rpcErr := make(chan error, 1)
+	go func() {
+		if err := rpcServer.Start(); err != nil && err != http.ErrServerClosed {
+			rpcErr <- err
+		}
+	}()
 	fmt.Printf("✅ RPC server started on %s\n", cfg.RPC.ListenAddr)
 
 	// Print node info
@@ -120,16 +544,82 @@ func main() {
 	fmt.Println("========================================")
 	fmt.Println("\nPress Ctrl+C to stop the node...")
 
-	// Wait for shutdown signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+	// Subsystems stop in the reverse of this order: RPC and P2P first so
+	// no new work arrives, then in-flight votes drain, state is flushed
+	// once nothing can change it, and the logs close last
+	var stops shutdown
+	if clockMonitor != nil {
+		stops.add("clock monitor", 0, func(ctx context.Context) error {
+			clockMonitor.Stop()
+			return nil
+		})
+	}
+	stops.add("epoch summaries", 0, func(ctx context.Context) error {
+		return epochs.Close()
+	})
+	stops.add("block log", 0, func(ctx context.Context) error {
+		return blockLog.Close()
+	})
+	stops.add("state", 0, func(ctx context.Context) error {
+		if _, err := stateDB.Commit(); err != nil {
+			return err
+		}
+		if snapshots == nil {
+			return nil
+		}
+		_, err := snapshots.Write(stateDB, blockchain.Height())
+		return err
+	})
+	stops.add("mempool", 0, func(ctx context.Context) error {
+		mempool.Stop()
+		return nil
+	})
+	stops.add("consensus", 0, func(ctx context.Context) error {
+		return waitGroup(ctx, &votes)
+	})
+	stops.add("block producer", 0, func(ctx context.Context) error {
+		select {
+		case <-producerDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if miner != nil {
+		stops.add("miner", 0, func(ctx context.Context) error {
+			miner.Stop()
+			return nil
+		})
+	}
+	stops.add("P2P node", 0, func(ctx context.Context) error {
+		return p2pNode.Stop()
+	})
+	if greylist != nil {
+		stops.add("greylist sync", 0, func(ctx context.Context) error {
+			greylist.Stop()
+			return nil
+		})
+	}
+	stops.add("RPC server", 0, func(ctx context.Context) error {
+		return rpcServer.Stop(ctx)
+	})
+
+	// Wait for a shutdown signal, or for the RPC server to fail
+	select {
+	case <-ctx.Done():
+	case err := <-rpcErr:
+		log.Printf("Warning: RPC server failed: %v", err)
+	}
+	// A second signal kills the node without waiting for the shutdown
+	stopSignals()
 
 	fmt.Println("\n🛑 Shutting down GYDS Chain Node...")
-
-	// Graceful shutdown
-	rpcServer.Stop()
-	p2pNode.Stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := stops.run(shutdownCtx); err != nil {
+		log.Printf("Warning: Node stopped with errors: %v", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("✅ Node stopped successfully")
 }
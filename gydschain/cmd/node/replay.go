@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/config"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// errReplayDone stops reading the block log once --to is reached
+var errReplayDone = errors.New("replay done")
+
+// BlockTrace is the execution trace of one replayed block
+type BlockTrace struct {
+	Height          uint64           `json:"height"`
+	Hash            string           `json:"hash"`
+	ParentHash      string           `json:"parent_hash"`
+	Validator       string           `json:"validator"`
+	ParentStateRoot string           `json:"parent_state_root"` // claimed by the header
+	StateRoot       string           `json:"state_root"`        // computed by this replay
+	BaseFee         uint64           `json:"base_fee"`
+	GasUsed         uint64           `json:"gas_used"`
+	Burned          *util.Big        `json:"burned"`
+	Transactions    []*chain.TxTrace `json:"transactions"`
+	Error           string           `json:"error,omitempty"`
+}
+
+// runReplay handles `gydsnode replay`: it rebuilds state from genesis by
+// re-executing the logged blocks and writes a trace of every block in
+// [--from, --to], so traces from two node versions can be diffed to find
+// where they diverge
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "Path to configuration file")
+	genesisPath := fs.String("genesis", "genesis.json", "Path to genesis file")
+	dataDir := fs.String("data", "./data", "Data directory holding blocks.jsonl")
+	importPath := fs.String("import-accounts", "", "JSONL account export the node was seeded with, if any")
+	from := fs.Uint64("from", 1, "First height to trace")
+	to := fs.Uint64("to", 0, "Last height to replay (default: end of the block log)")
+	traceDir := fs.String("trace-dir", "", "Directory to write per-block traces to (required)")
+	fs.Parse(args)
+
+	if *traceDir == "" {
+		return fmt.Errorf("--trace-dir is required")
+	}
+	if *to != 0 && *to < *from {
+		return fmt.Errorf("--to %d is below --from %d", *to, *from)
+	}
+	if err := os.MkdirAll(*traceDir, 0755); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not load config, using defaults: %v\n", err)
+		cfg = config.DefaultConfig()
+	}
+	cfg.DataDir = *dataDir
+
+	genesis, err := chain.LoadGenesis(*genesisPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not load genesis, using default: %v\n", err)
+		genesis = chain.DefaultGenesis()
+	}
+
+	stateDB, blockchain, err := newReplayChain(cfg, genesis, *importPath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		current  *BlockTrace
+		replayed uint64
+		traced   uint64
+	)
+	blockchain.SetTracer(func(height uint64, trace *chain.TxTrace) {
+		if current != nil {
+			current.Transactions = append(current.Transactions, trace)
+		}
+	})
+
+	err = chain.ReadBlockLog(cfg.GetDataPath("blocks.jsonl"), func(block *chain.Block) error {
+		height := block.Header.Height
+		if *to != 0 && height > *to {
+			return errReplayDone
+		}
+
+		current = nil
+		if height >= *from {
+			current = &BlockTrace{
+				Height:          height,
+				ParentHash:      block.Header.ParentHash,
+				Validator:       block.Validator,
+				ParentStateRoot: block.Header.StateRoot,
+				BaseFee:         block.Header.BaseFee,
+				GasUsed:         blockchain.Gas().BlockGas(block),
+				Burned:          (*util.Big)(util.CopyBig(block.Header.Burned)),
+				Transactions:    []*chain.TxTrace{},
+			}
+			current.Hash, _ = block.Hash()
+		}
+
+		addErr := blockchain.AddBlock(block)
+		replayed++
+		if current == nil {
+			if addErr != nil {
+				return fmt.Errorf("block %d: %v", height, addErr)
+			}
+			return nil
+		}
+
+		current.StateRoot = stateDB.Root()
+		if addErr != nil {
+			current.Error = addErr.Error()
+		}
+		if err := writeBlockTrace(*traceDir, current); err != nil {
+			return err
+		}
+		traced++
+		fmt.Printf("%d %s %s\n", height, current.Hash, current.StateRoot)
+
+		if addErr == chain.ErrInvalidStateRoot {
+			return fmt.Errorf("block %d: header claims parent state root %s but replay computed %s",
+				height, block.Header.StateRoot, current.StateRoot)
+		}
+		if addErr != nil {
+			return fmt.Errorf("block %d: %v", height, addErr)
+		}
+		return nil
+	})
+	if err != nil && err != errReplayDone {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Replayed %d blocks, traced %d into %s\n", replayed, traced, *traceDir)
+	return nil
+}
+
+// newReplayChain builds a chain from genesis the way the node does. Only the
+// parts that affect execution are attached; they must match the node's.
+func newReplayChain(cfg *config.Config, genesis *chain.GenesisConfig, importPath string) (*state.StateDB, *chain.Chain, error) {
+	stateDB := state.NewStateDB()
+	if importPath != "" {
+		f, err := os.Open(importPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, err = stateDB.ImportAccounts(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	chainConfig := chain.DefaultConfig()
+	chainConfig.BlockGasLimit = cfg.Chain.BlockGasLimit
+	chainConfig.BlockGasTarget = cfg.Chain.BlockGasTarget
+	blockchain, err := chain.NewChain(chainConfig, stateDB)
+	if err != nil {
+		return nil, nil, err
+	}
+	features := tx.NewFeatures(cfg.Experimental.Enabled()...)
+	blockchain.SetFeatures(features)
+	if names := features.Names(); len(names) > 0 {
+		fmt.Fprintf(os.Stderr, "Experimental features enabled: %s\n", strings.Join(names, ", "))
+	}
+	if err := blockchain.InitGenesis(genesis); err != nil {
+		return nil, nil, err
+	}
+
+	// Consensus parameters come from genesis, which every node shares
+	params := genesis.Params
+	posEngine := pos.NewEngine(
+		util.CopyBig((*big.Int)(params.MinStake)),
+		params.MaxValidators,
+		time.Duration(params.BlockTime)*time.Second,
+	)
+	if params.UnbondingTime > 0 {
+		posEngine.SetUnbondingTime(time.Duration(params.UnbondingTime) * time.Second)
+	}
+	blockchain.SetStaking(posEngine)
+	blockchain.SetRewardSource(posEngine)
+	slashingKeeper := pos.NewSlashingKeeper(posEngine, nil)
+	if params.AppealWindow > 0 {
+		slashingKeeper.SetAppealWindow(time.Duration(params.AppealWindow) * time.Second)
+	}
+	blockchain.SetSlashingKeeper(slashingKeeper)
+	blockchain.SetEvidencePool(chain.NewEvidencePool(posEngine, slashingKeeper))
+	blockchain.SetCircuitBreaker(pos.NewCircuitBreaker(posEngine, cfg.Chain.Guardians, cfg.Chain.GuardianThreshold))
+	oracleWindow := pos.OracleWindow(params.OracleUpdateFreq, params.BlockTime)
+	blockchain.SetOracle(pos.NewPriceOracle(posEngine, slashingKeeper, oracleWindow,
+		[]string{chain.CollateralAsset, chain.StablecoinAsset}))
+	blockchain.SetStablecoin(chain.NewStablecoin(
+		chainConfig.StablecoinPeg,
+		params.StablecoinReserve,
+		chain.OracleMaxAge(oracleWindow),
+	))
+	blockchain.SetBeacon(pos.NewRandomnessBeacon(posEngine, cfg.Chain.BeaconEpoch))
+	blockchain.SetEpochTracker(pos.NewEpochTracker(posEngine, slashingKeeper, cfg.Chain.BeaconEpoch))
+
+	return stateDB, blockchain, nil
+}
+
+// writeBlockTrace writes trace to block-<height>.json in dir
+func writeBlockTrace(dir string, trace *BlockTrace) error {
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("block-%d.json", trace.Height))
+	return ioutil.WriteFile(path, data, 0644)
+}
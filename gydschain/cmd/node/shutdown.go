@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultStepTimeout bounds one shutdown step that sets no timeout
+const defaultStepTimeout = 10 * time.Second
+
+// shutdownStep stops one subsystem
+type shutdownStep struct {
+	name    string
+	timeout time.Duration
+	stop    func(ctx context.Context) error
+}
+
+// shutdown stops subsystems in the reverse of the order they started, so
+// nothing is torn down while a later subsystem still depends on it
+type shutdown struct {
+	steps []shutdownStep
+}
+
+// add registers the stop function of a subsystem that has just started
+func (s *shutdown) add(name string, timeout time.Duration, stop func(ctx context.Context) error) {
+	if timeout <= 0 {
+		timeout = defaultStepTimeout
+	}
+	s.steps = append(s.steps, shutdownStep{name: name, timeout: timeout, stop: stop})
+}
+
+// run executes every step, each bounded by its own timeout and all by ctx.
+// A failed or timed-out step is reported and the rest still run, so one
+// stuck subsystem cannot keep state from being flushed.
+func (s *shutdown) run(ctx context.Context) error {
+	var errs []error
+	for i := len(s.steps) - 1; i >= 0; i-- {
+		step := s.steps[i]
+		start := time.Now()
+		if err := runStep(ctx, step); err != nil {
+			log.Printf("Warning: Stopping %s failed: %v", step.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			continue
+		}
+		fmt.Printf("   Stopped %s (%s)\n", step.name, time.Since(start).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
+
+// runStep runs step.stop, giving up when its timeout or ctx expires
+func runStep(ctx context.Context, step shutdownStep) error {
+	ctx, cancel := context.WithTimeout(ctx, step.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- step.stop(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitGroup waits for wg until ctx expires
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
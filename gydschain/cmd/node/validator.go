@@ -0,0 +1,25 @@
+package main
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// loadValidatorKey reads the validator's hex ed25519 seed or private key
+func loadValidatorKey(path string) (*crypto.KeyPair, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 32 {
+		return crypto.NewKeyPairFromSeed(key)
+	}
+	return crypto.NewKeyPairFromPrivateKey(key)
+}
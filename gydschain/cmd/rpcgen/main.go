@@ -0,0 +1,549 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// Schema is the machine-readable description of the node JSON-RPC API
+type Schema struct {
+	Name    string             `json:"name"`
+	Version string             `json:"version"`
+	Types   map[string][]Field `json:"types"`
+	Methods []Method           `json:"methods"`
+}
+
+// Field describes a struct field or method parameter
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Method describes a single RPC method
+type Method struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Params      []Field `json:"params,omitempty"`
+	Returns     string  `json:"returns"`
+}
+
+func main() {
+	schemaFile := flag.String("schema", "api/rpc/methods.json", "Path to the RPC method schema")
+	outDir := flag.String("out", "api/rpc/clients", "Output directory for generated clients")
+	goOut := flag.String("go-out", "internal/rpc/client/methods_gen.go", "Output file for the generated Go client methods")
+	lang := flag.String("lang", "all", "Client language to generate (ts, py, go, all)")
+	check := flag.Bool("check", false, "Verify the schema matches the node's registered methods and exit")
+	flag.Parse()
+
+	schema, err := loadSchema(*schemaFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := checkSchema(schema); err != nil {
+		fmt.Fprintf(os.Stderr, "Schema out of sync with node: %v\n", err)
+		os.Exit(1)
+	}
+	if *check {
+		fmt.Printf("Schema matches node (%d methods)\n", len(schema.Methods))
+		return
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputs := map[string]func(*Schema) (string, error){}
+	switch *lang {
+	case "ts":
+		outputs[filepath.Join(*outDir, "gydschain.ts")] = generateTypeScript
+	case "py":
+		outputs[filepath.Join(*outDir, "gydschain.py")] = generatePython
+	case "go":
+		outputs[*goOut] = generateGo
+	case "all":
+		outputs[filepath.Join(*outDir, "gydschain.ts")] = generateTypeScript
+		outputs[filepath.Join(*outDir, "gydschain.py")] = generatePython
+		outputs[*goOut] = generateGo
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown language: %s\n", *lang)
+		os.Exit(1)
+	}
+
+	for path, gen := range outputs {
+		code, err := gen(schema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated %s\n", path)
+	}
+}
+
+// loadSchema reads and parses the schema file
+func loadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// checkSchema compares the schema against the methods registered by the node
+func checkSchema(schema *Schema) error {
+	declared := make(map[string]bool)
+	for _, m := range schema.Methods {
+		declared[m.Name] = true
+	}
+
+	// The schema documents experimental namespaces too
+	methods := rpc.NewMethods()
+	methods.SetFeatures(tx.NewFeatures(tx.ExperimentalFeatures...))
+
+	registered := make(map[string]bool)
+	var missing []string
+	for _, name := range methods.List() {
+		registered[name] = true
+		if !declared[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	var unknown []string
+	for _, m := range schema.Methods {
+		if !registered[m.Name] {
+			unknown = append(unknown, m.Name)
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing from schema: "+strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		problems = append(problems, "not registered by node: "+strings.Join(unknown, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// typeNames returns the schema type names in sorted order
+func typeNames(schema *Schema) []string {
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tsType maps a schema type to a TypeScript type
+func tsType(t string) string {
+	if strings.HasSuffix(t, "[]") {
+		return tsType(strings.TrimSuffix(t, "[]")) + "[]"
+	}
+	if strings.HasPrefix(t, "map<") && strings.HasSuffix(t, ">") {
+		return "Record<string, " + tsType(t[4:len(t)-1]) + ">"
+	}
+	switch t {
+	case "string":
+		return "string"
+	case "uint64", "int64", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "object":
+		return "Record<string, unknown>"
+	case "", "any":
+		return "unknown"
+	}
+	return t
+}
+
+// pyType maps a schema type to a Python type hint
+func pyType(t string) string {
+	if strings.HasSuffix(t, "[]") {
+		return "List[" + pyType(strings.TrimSuffix(t, "[]")) + "]"
+	}
+	if strings.HasPrefix(t, "map<") && strings.HasSuffix(t, ">") {
+		return "Dict[str, " + pyType(t[4:len(t)-1]) + "]"
+	}
+	switch t {
+	case "string":
+		return "str"
+	case "uint64", "int64":
+		return "int"
+	case "float64":
+		return "float"
+	case "bool":
+		return "bool"
+	case "object":
+		return "Dict[str, Any]"
+	case "", "any":
+		return "Any"
+	}
+	return "\"" + t + "\""
+}
+
+// camelName converts a method name like chain_getBlock to chainGetBlock
+func camelName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// snakeName converts a method name like chain_getBlock to chain_get_block
+func snakeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 && name[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// generateTypeScript emits a fetch-based TypeScript client
+func generateTypeScript(schema *Schema) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by rpcgen from %s %s. DO NOT EDIT.\n\n", schema.Name, schema.Version)
+
+	for _, name := range typeNames(schema) {
+		fmt.Fprintf(&b, "export interface %s {\n", name)
+		for _, f := range schema.Types[name] {
+			opt := ""
+			if f.Optional {
+				opt = "?"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", f.Name, opt, tsType(f.Type))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	b.WriteString(`export class RpcError extends Error {
+  constructor(public code: number, message: string, public data?: unknown) {
+    super(message);
+    this.name = "RpcError";
+  }
+}
+
+export class GydsClient {
+  private nextId = 1;
+
+  /** token is an API key or JWT, needed for admin methods */
+  constructor(private url: string = "http://localhost:8545", private token?: string) {}
+
+  async call<T>(method: string, params: Record<string, unknown> = {}): Promise<T> {
+    const headers: Record<string, string> = { "Content-Type": "application/json" };
+    if (this.token) {
+      headers["Authorization"] = ` + "`Bearer ${this.token}`" + `;
+    }
+    const res = await fetch(this.url, {
+      method: "POST",
+      headers,
+      body: JSON.stringify({ jsonrpc: "2.0", method, params, id: this.nextId++ }),
+    });
+    const body = await res.json();
+    if (body.error) {
+      throw new RpcError(body.error.code, body.error.message, body.error.data);
+    }
+    return body.result as T;
+  }
+`)
+
+	for _, m := range schema.Methods {
+		var args, keys []string
+		for _, p := range m.Params {
+			opt := ""
+			if p.Optional {
+				opt = "?"
+			}
+			args = append(args, fmt.Sprintf("%s%s: %s", p.Name, opt, tsType(p.Type)))
+			keys = append(keys, p.Name)
+		}
+		params := ""
+		if len(keys) > 0 {
+			params = ", { " + strings.Join(keys, ", ") + " }"
+		}
+		fmt.Fprintf(&b, "\n  /** %s */\n", m.Description)
+		fmt.Fprintf(&b, "  %s(%s): Promise<%s> {\n", camelName(m.Name), strings.Join(args, ", "), tsType(m.Returns))
+		fmt.Fprintf(&b, "    return this.call(%q%s);\n", m.Name, params)
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+// generatePython emits a urllib-based Python client
+func generatePython(schema *Schema) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Code generated by rpcgen from %s %s. DO NOT EDIT.\n\n", schema.Name, schema.Version)
+	b.WriteString(`from __future__ import annotations
+
+import json
+import urllib.request
+from typing import Any, Dict, List, Optional, TypedDict
+
+`)
+
+	for _, name := range typeNames(schema) {
+		// Functional syntax allows keyword field names such as "from"
+		fmt.Fprintf(&b, "%s = TypedDict(\"%s\", {\n", name, name)
+		for _, f := range schema.Types[name] {
+			fmt.Fprintf(&b, "    %q: %s,\n", f.Name, pyType(f.Type))
+		}
+		b.WriteString("}, total=False)\n\n")
+	}
+
+	b.WriteString(`
+class RpcError(Exception):
+    def __init__(self, code: int, message: str, data: Any = None):
+        super().__init__(message)
+        self.code = code
+        self.data = data
+
+
+class GydsClient:
+    def __init__(self, url: str = "http://localhost:8545", timeout: float = 30.0, token: Optional[str] = None):
+        """token is an API key or JWT, needed for admin methods"""
+        self.url = url
+        self.timeout = timeout
+        self.token = token
+        self._next_id = 1
+
+    def call(self, method: str, params: Optional[Dict[str, Any]] = None) -> Any:
+        payload = {"jsonrpc": "2.0", "method": method, "params": params or {}, "id": self._next_id}
+        self._next_id += 1
+        headers = {"Content-Type": "application/json"}
+        if self.token:
+            headers["Authorization"] = "Bearer " + self.token
+        req = urllib.request.Request(self.url, data=json.dumps(payload).encode(), headers=headers)
+        with urllib.request.urlopen(req, timeout=self.timeout) as res:
+            body = json.loads(res.read())
+        if body.get("error"):
+            err = body["error"]
+            raise RpcError(err.get("code", 0), err.get("message", ""), err.get("data"))
+        return body.get("result")
+`)
+
+	for _, m := range schema.Methods {
+		args := []string{"self"}
+		var required, optional []string
+		for _, p := range m.Params {
+			if p.Optional {
+				args = append(args, fmt.Sprintf("%s: Optional[%s] = None", p.Name, pyType(p.Type)))
+				optional = append(optional, p.Name)
+			} else {
+				args = append(args, fmt.Sprintf("%s: %s", p.Name, pyType(p.Type)))
+				required = append(required, p.Name)
+			}
+		}
+
+		fmt.Fprintf(&b, "\n    def %s(%s) -> %s:\n", snakeName(m.Name), strings.Join(args, ", "), pyType(m.Returns))
+		fmt.Fprintf(&b, "        \"\"\"%s\"\"\"\n", m.Description)
+		if len(m.Params) == 0 {
+			fmt.Fprintf(&b, "        return self.call(%q)\n", m.Name)
+			continue
+		}
+
+		var pairs []string
+		for _, name := range required {
+			pairs = append(pairs, fmt.Sprintf("%q: %s", name, name))
+		}
+		fmt.Fprintf(&b, "        params: Dict[str, Any] = {%s}\n", strings.Join(pairs, ", "))
+		for _, name := range optional {
+			fmt.Fprintf(&b, "        if %s is not None:\n", name)
+			fmt.Fprintf(&b, "            params[%q] = %s\n", name, name)
+		}
+		fmt.Fprintf(&b, "        return self.call(%q, params)\n", m.Name)
+	}
+
+	return b.String(), nil
+}
+
+// goKeywords are reserved words a parameter name cannot use in Go, plus
+// the names generated methods use for their own variables
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	"c": true, "ctx": true, "args": true, "result": true, "err": true,
+}
+
+// goInitialisms are name parts written in upper case in Go identifiers
+var goInitialisms = map[string]string{
+	"id": "ID", "ip": "IP", "url": "URL", "api": "API", "rpc": "RPC",
+	"json": "JSON", "http": "HTTP", "sha256": "SHA256",
+}
+
+// goName converts a schema name like block_hash, chainId or
+// chain_getBlock to an exported Go identifier: BlockHash, ChainID,
+// ChainGetBlock
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if upper, ok := goInitialisms[part]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		if strings.HasSuffix(part, "Id") {
+			part = strings.TrimSuffix(part, "Id") + "ID"
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// goParamName converts a schema parameter name to an unexported Go name
+func goParamName(name string) string {
+	exported := goName(name)
+	// Lower the leading run of upper case, keeping the last letter of an
+	// initialism that starts a longer word
+	runes := []rune(exported)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i > 1 && i < len(runes) {
+		i--
+	}
+	param := strings.ToLower(string(runes[:i])) + string(runes[i:])
+	if goKeywords[param] {
+		param += "Arg"
+	}
+	return param
+}
+
+// goType maps a schema type to a Go type. Schema types are pointers so
+// optional fields and results can be absent.
+func goType(t string) string {
+	if strings.HasSuffix(t, "[]") {
+		return "[]" + goType(strings.TrimSuffix(t, "[]"))
+	}
+	if strings.HasPrefix(t, "map<") && strings.HasSuffix(t, ">") {
+		return "map[string]" + goType(t[4:len(t)-1])
+	}
+	switch t {
+	case "string", "bool", "int", "int64", "uint64", "float64":
+		return t
+	case "object":
+		return "map[string]interface{}"
+	case "", "any":
+		return "json.RawMessage"
+	}
+	return "*" + t
+}
+
+// nillable reports whether a Go type can be nil, so an optional parameter
+// of that type needs no pointer
+func nillable(goType string) bool {
+	return strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") ||
+		strings.HasPrefix(goType, "map[") || goType == "json.RawMessage"
+}
+
+// generateGo emits the schema types and a typed method for every RPC
+// method, on the Client in internal/rpc/client
+func generateGo(schema *Schema) (string, error) {
+	var b strings.Builder
+
+	for _, name := range typeNames(schema) {
+		fmt.Fprintf(&b, "// %s is the %s type of the node API\n", name, name)
+		fmt.Fprintf(&b, "type %s struct {\n", name)
+		for _, f := range schema.Types[name] {
+			tag := f.Name
+			if f.Optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", goName(f.Name), goType(f.Type), tag)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	for _, m := range schema.Methods {
+		args := []string{"ctx context.Context"}
+		for _, p := range m.Params {
+			t := goType(p.Type)
+			if p.Optional && !nillable(t) {
+				t = "*" + t
+			}
+			args = append(args, goParamName(p.Name)+" "+t)
+		}
+		result := goType(m.Returns)
+
+		fmt.Fprintf(&b, "// %s calls %s: %s\n", goName(m.Name), m.Name, m.Description)
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) (%s, error) {\n", goName(m.Name), strings.Join(args, ", "), result)
+		params := "nil"
+		if len(m.Params) > 0 {
+			params = "args"
+			b.WriteString("\targs := map[string]interface{}{}\n")
+			for _, p := range m.Params {
+				arg := goParamName(p.Name)
+				switch {
+				case !p.Optional:
+					fmt.Fprintf(&b, "\targs[%q] = %s\n", p.Name, arg)
+				case nillable(goType(p.Type)):
+					fmt.Fprintf(&b, "\tif %s != nil {\n\t\targs[%q] = %s\n\t}\n", arg, p.Name, arg)
+				default:
+					fmt.Fprintf(&b, "\tif %s != nil {\n\t\targs[%q] = *%s\n\t}\n", arg, p.Name, arg)
+				}
+			}
+		}
+		fmt.Fprintf(&b, "\tvar result %s\n", result)
+		fmt.Fprintf(&b, "\terr := c.Call(ctx, %q, %s, &result)\n", m.Name, params)
+		b.WriteString("\treturn result, err\n}\n\n")
+	}
+
+	imports := "\"context\""
+	if strings.Contains(b.String(), "json.RawMessage") {
+		imports += "\n\t\"encoding/json\""
+	}
+	header := fmt.Sprintf("// Code generated by rpcgen from %s %s. DO NOT EDIT.\n\npackage client\n\nimport (\n\t%s\n)\n\n",
+		schema.Name, schema.Version, imports)
+
+	code, err := format.Source([]byte(header + b.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(code), nil
+}
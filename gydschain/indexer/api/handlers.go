@@ -156,8 +156,9 @@ type StatsResponse struct {
 
 // SearchResult represents a search result
 type SearchResult struct {
-	Type    string      `json:"type"` // block, transaction, account, asset
+	Type    string      `json:"type"` // block, transaction, account, asset, validator
 	ID      string      `json:"id"`
+	Score   int         `json:"score"` // relevance, higher first
 	Preview interface{} `json:"preview"`
 }
 
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxCachedResponses bounds the responses kept for maintenance mode; the
+// oldest is evicted first
+const maxCachedResponses = 2048
+
+// maintenanceWarning is sent with every cached response served during
+// maintenance (RFC 7234 warn-code 110, Response is Stale)
+const maintenanceWarning = `110 - "Response is Stale: indexer under maintenance"`
+
+// hotRoutes are the explorer-facing routes whose last good responses are
+// cached and replayed while the API is in maintenance mode
+var hotRoutes = map[string]bool{
+	"/blocks":                          true,
+	"/blocks/{number}":                 true,
+	"/blocks/{number}/transactions":    true,
+	"/transactions":                    true,
+	"/transactions/{hash}":             true,
+	"/transactions/{hash}/receipt":     true,
+	"/accounts/top":                    true,
+	"/accounts/{address}":              true,
+	"/accounts/{address}/transactions": true,
+	"/accounts/{address}/balance":      true,
+	"/assets":                          true,
+	"/assets/{id}":                     true,
+	"/validators":                      true,
+	"/validators/{address}":            true,
+	"/epochs":                          true,
+	"/epochs/{number}":                 true,
+	"/stats":                           true,
+	"/stats/daily":                     true,
+	"/stats/burn":                      true,
+}
+
+// MaintenanceStatus reports whether the API is in maintenance mode
+type MaintenanceStatus struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	Since   int64  `json:"since,omitempty"`
+	Cached  int    `json:"cached_responses"`
+}
+
+// cachedResponse is the last successful response for one request
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	stored      time.Time
+}
+
+// maintenance holds the maintenance switch and the response cache it
+// serves from
+type maintenance struct {
+	mu        sync.RWMutex
+	enabled   bool
+	reason    string
+	since     time.Time
+	responses map[string]*cachedResponse
+}
+
+func newMaintenance() *maintenance {
+	return &maintenance{responses: make(map[string]*cachedResponse)}
+}
+
+// set switches maintenance mode and returns the resulting status
+func (m *maintenance) set(enabled bool, reason string) *MaintenanceStatus {
+	m.mu.Lock()
+	if enabled && !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled, m.reason = enabled, reason
+	if !enabled {
+		m.reason, m.since = "", time.Time{}
+	}
+	m.mu.Unlock()
+	return m.status()
+}
+
+func (m *maintenance) status() *MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := &MaintenanceStatus{Enabled: m.enabled, Reason: m.reason, Cached: len(m.responses)}
+	if m.enabled {
+		status.Since = m.since.Unix()
+	}
+	return status
+}
+
+func (m *maintenance) active() (bool, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled, m.reason
+}
+
+func (m *maintenance) get(key string) *cachedResponse {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.responses[key]
+}
+
+func (m *maintenance) store(key string, resp *cachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.responses[key]; !exists && len(m.responses) >= maxCachedResponses {
+		var oldest string
+		for k, r := range m.responses {
+			if oldest == "" || r.stored.Before(m.responses[oldest].stored) {
+				oldest = k
+			}
+		}
+		delete(m.responses, oldest)
+	}
+	m.responses[key] = resp
+}
+
+// cacheKey identifies a request by path and query, leaving out the API key
+// so every caller shares the cached response
+func cacheKey(r *http.Request) string {
+	query := r.URL.Query()
+	query.Del("api_key")
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(r.URL.Path)
+	for i, k := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(k + "=" + strings.Join(query[k], ","))
+	}
+	return b.String()
+}
+
+// recorder captures a response so a successful one can be cached
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+// isHotRoute reports whether r is a GET on a route cached for maintenance
+func isHotRoute(r *http.Request) bool {
+	if r.Method != "GET" {
+		return false
+	}
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	template, err := route.GetPathTemplate()
+	return err == nil && hotRoutes[template]
+}
+
+// maintenanceMiddleware caches successful responses from hot routes and,
+// in maintenance mode, serves them with a Warning header instead of
+// touching the database. Other routes answer 503 during maintenance; health
+// checks, status and the admin API keep working.
+func (s *Server) maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || r.URL.Path == "/status" || strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		hot := isHotRoute(r)
+		if enabled, reason := s.maintenance.active(); enabled {
+			if cached := s.maintenance.get(cacheKey(r)); hot && cached != nil {
+				w.Header().Set("Content-Type", cached.contentType)
+				w.Header().Set("Warning", maintenanceWarning)
+				w.Header().Set("Age", strconv.Itoa(int(time.Since(cached.stored).Seconds())))
+				w.Write(cached.body)
+				return
+			}
+			message := "indexer under maintenance"
+			if reason != "" {
+				message += ": " + reason
+			}
+			w.Header().Set("Retry-After", "60")
+			s.errorResponse(w, 503, message)
+			return
+		}
+
+		if !hot {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &recorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusOK {
+			s.maintenance.store(cacheKey(r), &cachedResponse{
+				contentType: w.Header().Get("Content-Type"),
+				body:        append([]byte(nil), rec.body.Bytes()...),
+				stored:      time.Now(),
+			})
+		}
+	})
+}
+
+// SetMaintenance switches maintenance mode, e.g. around a migration or
+// backfill run from the same process
+func (s *Server) SetMaintenance(enabled bool, reason string) {
+	s.maintenance.set(enabled, reason)
+}
+
+// Maintenance admin handlers
+
+func (s *Server) handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, s.maintenance.status())
+}
+
+func (s *Server) handleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled bool   `json:"enabled"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, 400, "invalid request body")
+		return
+	}
+
+	s.jsonResponse(w, s.maintenance.set(req.Enabled, req.Reason))
+}
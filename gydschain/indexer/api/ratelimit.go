@@ -0,0 +1,172 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/indexer/service"
+)
+
+// idleBucket is how long an unused bucket is kept before it is swept
+const idleBucket = 10 * time.Minute
+
+// bucket is a token bucket for one API consumer
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps a token bucket per API key or anonymous IP
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token from the client's bucket. If it is empty it returns
+// false and how long until the next token.
+func (l *rateLimiter) allow(client string, limits service.Tier) (bool, time.Duration) {
+	if limits.RateLimit <= 0 {
+		return true, 0
+	}
+	burst := float64(limits.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > idleBucket {
+		for id, b := range l.buckets {
+			if now.Sub(b.last) > idleBucket {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limits.RateLimit)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limits.RateLimit * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// limitMiddleware applies per-key rate limits and monthly quotas, and the
+// anonymous limits to requests without a key. Health checks and the admin
+// API are not limited.
+func (s *Server) limitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" || r.URL.Path == "/health" || strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limits := s.anonymous
+		client := "ip:" + s.clientIP(r)
+		key, err := s.requestKey(r)
+		if err != nil {
+			// The key table may be mid-migration; limit the caller as
+			// anonymous rather than fail requests maintenance mode can serve
+			if enabled, _ := s.maintenance.active(); !enabled {
+				s.errorResponse(w, 500, err.Error())
+				return
+			}
+			key = nil
+		} else if key == nil && apiKeyOf(r) != "" {
+			s.errorResponse(w, 401, "invalid api key")
+			return
+		}
+		if key != nil {
+			limits = key.Limits()
+			client = "key:" + strconv.FormatInt(key.ID, 10)
+		}
+
+		if limits.RateLimit > 0 {
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatFloat(limits.RateLimit, 'f', -1, 64))
+		}
+		if ok, wait := s.limiter.allow(client, limits); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			s.errorResponse(w, 429, "rate limit exceeded")
+			return
+		}
+
+		if key != nil {
+			used, err := s.keys.Use(key)
+			if limits.MonthlyQuota > 0 {
+				remaining := limits.MonthlyQuota - used
+				if remaining < 0 {
+					remaining = 0
+				}
+				w.Header().Set("X-Quota-Limit", strconv.FormatInt(limits.MonthlyQuota, 10))
+				w.Header().Set("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+			}
+			if err == service.ErrQuotaExceeded {
+				s.errorResponse(w, 429, err.Error())
+				return
+			}
+			if enabled, _ := s.maintenance.active(); err != nil && !enabled {
+				s.errorResponse(w, 500, err.Error())
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiKeyOf returns the API key presented in the X-API-Key header or the
+// api_key query parameter
+func apiKeyOf(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// requestKey returns the active key presented with a request, or nil
+func (s *Server) requestKey(r *http.Request) (*service.APIKey, error) {
+	secret := apiKeyOf(r)
+	if secret == "" {
+		return nil, nil
+	}
+	return s.keys.Lookup(secret)
+}
+
+// clientIP identifies an anonymous caller, using the first X-Forwarded-For
+// address when the server runs behind a trusted proxy
+func (s *Server) clientIP(r *http.Request) string {
+	if s.trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -2,16 +2,22 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gydschain/gydschain/indexer/service"
+	"github.com/gydschain/gydschain/internal/logging"
 )
 
+// logger is the indexer API module logger
+var logger = logging.Module("indexer/api")
+
 // Server represents the indexer API server
 type Server struct {
 	addr    string
@@ -21,21 +27,58 @@ type Server struct {
 	indexer *service.Indexer
 	
 	// Sub-handlers
-	accounts *service.AccountIndexer
-	assets   *service.AssetIndexer
-	txs      *service.TransactionIndexer
+	accounts   *service.AccountIndexer
+	assets     *service.AssetIndexer
+	txs        *service.TransactionIndexer
+	labels     *service.LabelIndexer
+	burns      *service.BurnIndexer
+	nfts       *service.NFTIndexer
+	epochs     *service.EpochIndexer
+	events     *service.EventIndexer
+	portfolio  *service.PortfolioIndexer
+	search     *service.SearchIndexer
+	validators *service.ValidatorIndexer
+	chainStats *service.StatsAggregator
+	mempool    *service.MempoolIndexer
+	
+	// Bearer token required by /admin routes; empty disables them
+	adminToken string
+	
+	// API keys, per-key and anonymous rate limits
+	keys       *service.APIKeyManager
+	limiter    *rateLimiter
+	anonymous  service.Tier
+	trustProxy bool
+	
+	// Maintenance mode and the cached responses it serves
+	maintenance *maintenance
 }
 
 // NewServer creates a new API server
 func NewServer(addr string, db *sql.DB, indexer *service.Indexer) *Server {
 	s := &Server{
-		addr:     addr,
-		router:   mux.NewRouter(),
-		db:       db,
-		indexer:  indexer,
-		accounts: service.NewAccountIndexer(db),
-		assets:   service.NewAssetIndexer(db),
-		txs:      service.NewTransactionIndexer(db),
+		addr:       addr,
+		router:     mux.NewRouter(),
+		db:         db,
+		indexer:    indexer,
+		accounts:   service.NewAccountIndexer(db),
+		assets:     service.NewAssetIndexer(db),
+		txs:        service.NewTransactionIndexer(db),
+		labels:     service.NewLabelIndexer(db),
+		burns:      service.NewBurnIndexer(db, service.DefaultFeeBurnRate),
+		nfts:       service.NewNFTIndexer(db),
+		epochs:     service.NewEpochIndexer(db),
+		events:     service.NewEventIndexer(db),
+		portfolio:  service.NewPortfolioIndexer(db),
+		search:     service.NewSearchIndexer(db),
+		validators: service.NewValidatorIndexer(db),
+		chainStats: service.NewStatsAggregator(db),
+		mempool:    service.NewMempoolIndexer(db, service.DefaultMempoolTTL),
+		keys:       service.NewAPIKeyManager(db),
+		limiter:    newRateLimiter(),
+		anonymous:  service.Tiers[service.TierAnonymous],
+		
+		maintenance: newMaintenance(),
 	}
 	s.setupRoutes()
 	return s
@@ -55,11 +98,21 @@ func (s *Server) setupRoutes() {
 	// Transactions
 	s.router.HandleFunc("/transactions", s.handleGetTransactions).Methods("GET")
 	s.router.HandleFunc("/transactions/{hash}", s.handleGetTransaction).Methods("GET")
+	s.router.HandleFunc("/transactions/{hash}/receipt", s.handleGetTransactionReceipt).Methods("GET")
 	
 	// Accounts
+	s.router.HandleFunc("/accounts/top", s.handleGetTopAccounts).Methods("GET")
 	s.router.HandleFunc("/accounts/{address}", s.handleGetAccount).Methods("GET")
 	s.router.HandleFunc("/accounts/{address}/transactions", s.handleGetAccountTransactions).Methods("GET")
 	s.router.HandleFunc("/accounts/{address}/balance", s.handleGetAccountBalance).Methods("GET")
+	s.router.HandleFunc("/accounts/{address}/pending", s.handleGetAccountPending).Methods("GET")
+	s.router.HandleFunc("/accounts/{address}/events", s.handleGetAccountEvents).Methods("GET")
+	
+	// Mempool
+	s.router.HandleFunc("/mempool", s.handleGetMempool).Methods("GET")
+	
+	// Multi-address wallet portfolio
+	s.router.HandleFunc("/portfolio", s.handleGetPortfolio).Methods("POST")
 	
 	// Assets
 	s.router.HandleFunc("/assets", s.handleGetAssets).Methods("GET")
@@ -67,20 +120,81 @@ func (s *Server) setupRoutes() {
 	s.router.HandleFunc("/assets/{id}/holders", s.handleGetAssetHolders).Methods("GET")
 	s.router.HandleFunc("/assets/{id}/transfers", s.handleGetAssetTransfers).Methods("GET")
 	
+	// NFTs
+	s.router.HandleFunc("/nfts", s.handleGetNFTCollections).Methods("GET")
+	s.router.HandleFunc("/nfts/{collection}/{token}", s.handleGetNFT).Methods("GET")
+	s.router.HandleFunc("/nfts/{collection}/{token}/transfers", s.handleGetNFTTransfers).Methods("GET")
+	
 	// Validators
 	s.router.HandleFunc("/validators", s.handleGetValidators).Methods("GET")
 	s.router.HandleFunc("/validators/{address}", s.handleGetValidator).Methods("GET")
+	s.router.HandleFunc("/validators/{address}/epochs", s.handleGetValidatorEpochs).Methods("GET")
+	s.router.HandleFunc("/validators/{address}/delegators", s.handleGetValidatorDelegators).Methods("GET")
+	
+	// Epochs
+	s.router.HandleFunc("/epochs", s.handleGetEpochs).Methods("GET")
+	s.router.HandleFunc("/epochs/{number}", s.handleGetEpoch).Methods("GET")
 	
 	// Stats
 	s.router.HandleFunc("/stats", s.handleGetStats).Methods("GET")
 	s.router.HandleFunc("/stats/daily", s.handleGetDailyStats).Methods("GET")
+	s.router.HandleFunc("/stats/hourly", s.handleGetHourlyStats).Methods("GET")
+	s.router.HandleFunc("/stats/active-addresses", s.handleGetActiveAddresses).Methods("GET")
+	s.router.HandleFunc("/stats/fee-burn", s.handleGetFeeBurn).Methods("GET")
+	s.router.HandleFunc("/stats/inclusion", s.handleGetInclusionStats).Methods("GET")
+	s.router.HandleFunc("/stats/burn", s.handleGetBurnStats).Methods("GET")
+	
+	// Address labels
+	s.router.HandleFunc("/labels", s.handleGetLabels).Methods("GET")
+	s.router.HandleFunc("/labels/{address}", s.handleGetLabel).Methods("GET")
+	
+	// Admin curation
+	admin := s.router.PathPrefix("/admin").Subrouter()
+	admin.Use(s.adminMiddleware)
+	admin.HandleFunc("/labels/{address}", s.handleSetLabel).Methods("PUT")
+	admin.HandleFunc("/labels/{address}", s.handleDeleteLabel).Methods("DELETE")
+	admin.HandleFunc("/dead-letters", s.handleGetDeadLetters).Methods("GET")
+	admin.HandleFunc("/dead-letters/{number}/replay", s.handleReplayDeadLetter).Methods("POST")
+	
+	// API key issuance and usage
+	admin.HandleFunc("/api-keys", s.handleIssueAPIKey).Methods("POST")
+	admin.HandleFunc("/api-keys", s.handleGetAPIKeys).Methods("GET")
+	admin.HandleFunc("/api-keys/{id}", s.handleRevokeAPIKey).Methods("DELETE")
+	admin.HandleFunc("/api-keys/{id}/usage", s.handleGetAPIKeyUsage).Methods("GET")
+	
+	// Maintenance mode during migrations and backfills
+	admin.HandleFunc("/maintenance", s.handleGetMaintenance).Methods("GET")
+	admin.HandleFunc("/maintenance", s.handleSetMaintenance).Methods("PUT")
 	
 	// Search
 	s.router.HandleFunc("/search", s.handleSearch).Methods("GET")
 	
+	// Usage of the presented API key
+	s.router.HandleFunc("/usage", s.handleGetUsage).Methods("GET")
+	
 	// Apply middleware
 	s.router.Use(corsMiddleware)
+	s.router.Use(logging.RequestIDMiddleware)
 	s.router.Use(loggingMiddleware)
+	s.router.Use(s.limitMiddleware)
+	s.router.Use(s.maintenanceMiddleware)
+}
+
+// SetAdminToken sets the bearer token for the admin API
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// SetAnonymousLimits sets the limits applied per IP to requests without an
+// API key; a zero rate limit disables anonymous rate limiting
+func (s *Server) SetAnonymousLimits(limits service.Tier) {
+	s.anonymous = limits
+}
+
+// SetTrustProxy identifies anonymous callers by X-Forwarded-For; only
+// enable it behind a proxy that sets the header
+func (s *Server) SetTrustProxy(trust bool) {
+	s.trustProxy = trust
 }
 
 // Start starts the API server
@@ -89,13 +203,18 @@ func (s *Server) Start() error {
 		Addr:    s.addr,
 		Handler: s.router,
 	}
-	fmt.Printf("Indexer API server starting on %s\n", s.addr)
+	s.keys.Start()
+	logger.Info("indexer API server starting", "addr", s.addr)
 	return s.server.ListenAndServe()
 }
 
-// Stop stops the API server
+// Stop stops the API server and persists API key usage
 func (s *Server) Stop(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	err := s.server.Shutdown(ctx)
+	if flushErr := s.keys.Stop(); err == nil {
+		err = flushErr
+	}
+	return err
 }
 
 // Response helpers
@@ -121,6 +240,10 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, map[string]interface{}{
 		"status":             "running",
 		"last_indexed_block": s.indexer.GetLastIndexedBlock(),
+		"finalized_height":   s.indexer.GetFinalizedHeight(),
+		"pipeline":           s.indexer.GetPipelineStats(),
+		"backfill":           s.indexer.GetBackfillProgress(),
+		"nodes":              s.indexer.GetFailoverStats(),
 	})
 }
 
@@ -190,6 +313,12 @@ func (s *Server) handleGetBlockTransactions(w http.ResponseWriter, r *http.Reque
 func (s *Server) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 	limit := s.getIntParam(r, "limit", 20)
 	
+	fromDate, toDate := r.URL.Query().Get("from_date"), r.URL.Query().Get("to_date")
+	if fromDate != "" || toDate != "" {
+		s.getTransactionsByDate(w, r, fromDate, toDate, limit)
+		return
+	}
+	
 	txs, err := s.txs.GetRecentTransactions(limit)
 	if err != nil {
 		s.errorResponse(w, 500, err.Error())
@@ -199,6 +328,44 @@ func (s *Server) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, txs)
 }
 
+// getTransactionsByDate responds with transactions between two dates. An
+// open end defaults to genesis or today
+func (s *Server) getTransactionsByDate(w http.ResponseWriter, r *http.Request, fromDate, toDate string, limit int) {
+	offset := s.getIntParam(r, "offset", 0)
+	
+	from := time.Unix(0, 0).UTC()
+	if fromDate != "" {
+		t, err := time.Parse("2006-01-02", fromDate)
+		if err != nil {
+			s.errorResponse(w, 400, "from_date must be YYYY-MM-DD")
+			return
+		}
+		from = t
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if toDate != "" {
+		t, err := time.Parse("2006-01-02", toDate)
+		if err != nil {
+			s.errorResponse(w, 400, "to_date must be YYYY-MM-DD")
+			return
+		}
+		to = t
+	}
+	if to.Before(from) {
+		s.errorResponse(w, 400, "to_date is before from_date")
+		return
+	}
+	
+	// to_date is inclusive, so the range ends at the start of the next day
+	txs, err := s.txs.GetTransactionsByDateRange(from, to.AddDate(0, 0, 1), limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, txs)
+}
+
 func (s *Server) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	hash := vars["hash"]
@@ -216,6 +383,23 @@ func (s *Server) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, txn)
 }
 
+func (s *Server) handleGetTransactionReceipt(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hash := vars["hash"]
+	
+	receipt, err := s.txs.GetReceipt(hash)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if receipt == nil {
+		s.errorResponse(w, 404, "transaction not found")
+		return
+	}
+	
+	s.jsonResponse(w, receipt)
+}
+
 // Account handlers
 
 func (s *Server) handleGetAccount(w http.ResponseWriter, r *http.Request) {
@@ -235,6 +419,22 @@ func (s *Server) handleGetAccount(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, account)
 }
 
+func (s *Server) handleGetTopAccounts(w http.ResponseWriter, r *http.Request) {
+	asset := r.URL.Query().Get("asset")
+	if asset == "" {
+		asset = "GYDS"
+	}
+	limit := s.getIntParam(r, "limit", 100)
+	
+	accounts, err := s.accounts.GetTopAccounts(asset, limit)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, accounts)
+}
+
 func (s *Server) handleGetAccountTransactions(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
@@ -271,6 +471,86 @@ func (s *Server) handleGetAccountBalance(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// Mempool handlers
+
+func (s *Server) handleGetMempool(w http.ResponseWriter, r *http.Request) {
+	limit := s.getIntParam(r, "limit", 50)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	count, err := s.mempool.GetPendingCount()
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	txs, err := s.mempool.GetPending(limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, map[string]interface{}{
+		"count":        count,
+		"transactions": txs,
+	})
+}
+
+func (s *Server) handleGetAccountPending(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	limit := s.getIntParam(r, "limit", 50)
+	
+	txs, err := s.mempool.GetPendingByAddress(address, limit)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, txs)
+}
+
+func (s *Server) handleGetAccountEvents(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	eventType := r.URL.Query().Get("type")
+	limit := s.getIntParam(r, "limit", 20)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	events, err := s.events.GetAccountEvents(address, eventType, limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, events)
+}
+
+// Portfolio handlers
+
+func (s *Server) handleGetPortfolio(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Addresses []string `json:"addresses"`
+		Activity  int      `json:"activity"` // recent transactions to include
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, 400, "invalid request body")
+		return
+	}
+	if req.Activity <= 0 || req.Activity > 100 {
+		req.Activity = 20
+	}
+	
+	portfolio, err := s.portfolio.GetPortfolio(req.Addresses, req.Activity)
+	switch err {
+	case nil:
+	case service.ErrNoPortfolioAddresses, service.ErrTooManyPortfolioAddresses:
+		s.errorResponse(w, 400, err.Error())
+		return
+	default:
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, portfolio)
+}
+
 // Asset handlers
 
 func (s *Server) handleGetAssets(w http.ResponseWriter, r *http.Request) {
@@ -330,16 +610,151 @@ func (s *Server) handleGetAssetTransfers(w http.ResponseWriter, r *http.Request)
 	s.jsonResponse(w, transfers)
 }
 
+// NFT handlers
+
+func (s *Server) handleGetNFTCollections(w http.ResponseWriter, r *http.Request) {
+	limit := s.getIntParam(r, "limit", 20)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	collections, err := s.nfts.GetCollections(limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, collections)
+}
+
+func (s *Server) handleGetNFT(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	token, err := s.nfts.GetToken(vars["collection"], vars["token"])
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if token == nil {
+		s.errorResponse(w, 404, "NFT not found")
+		return
+	}
+	
+	s.jsonResponse(w, token)
+}
+
+func (s *Server) handleGetNFTTransfers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	limit := s.getIntParam(r, "limit", 20)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	transfers, err := s.nfts.GetTransfers(vars["collection"], vars["token"], limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, transfers)
+}
+
 // Validator handlers
 
 func (s *Server) handleGetValidators(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement
-	s.jsonResponse(w, []interface{}{})
+	limit := s.getIntParam(r, "limit", 100)
+	offset := s.getIntParam(r, "offset", 0)
+	activeOnly := r.URL.Query().Get("active") == "true"
+	
+	validators, err := s.validators.GetValidators(activeOnly, limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, validators)
 }
 
 func (s *Server) handleGetValidator(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement
-	s.jsonResponse(w, nil)
+	address := mux.Vars(r)["address"]
+	
+	validator, err := s.validators.GetValidator(address)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if validator == nil {
+		s.errorResponse(w, 404, "validator not found")
+		return
+	}
+	slashes, err := s.validators.GetSlashes(address, 20)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, struct {
+		*service.Validator
+		Slashes []*service.ValidatorSlash `json:"slashes"`
+	}{validator, slashes})
+}
+
+func (s *Server) handleGetValidatorDelegators(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	limit := s.getIntParam(r, "limit", 50)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	delegations, err := s.validators.GetDelegators(address, limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, delegations)
+}
+
+func (s *Server) handleGetValidatorEpochs(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	limit := s.getIntParam(r, "limit", 30)
+	
+	epochs, err := s.epochs.GetValidatorEpochs(address, limit)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, epochs)
+}
+
+// Epoch handlers
+
+func (s *Server) handleGetEpochs(w http.ResponseWriter, r *http.Request) {
+	limit := s.getIntParam(r, "limit", 20)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	epochs, err := s.epochs.GetEpochs(limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, epochs)
+}
+
+func (s *Server) handleGetEpoch(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.ParseUint(mux.Vars(r)["number"], 10, 64)
+	if err != nil {
+		s.errorResponse(w, 400, "invalid epoch number")
+		return
+	}
+	
+	summary, err := s.epochs.GetEpoch(number)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if summary == nil {
+		s.errorResponse(w, 404, "epoch not found")
+		return
+	}
+	
+	s.jsonResponse(w, summary)
 }
 
 // Stats handlers
@@ -356,7 +771,55 @@ func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleGetDailyStats(w http.ResponseWriter, r *http.Request) {
 	days := s.getIntParam(r, "days", 7)
 	
-	stats, err := s.txs.GetDailyTransactionStats(days)
+	stats, err := s.chainStats.GetDailyStats(days)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, stats)
+}
+
+func (s *Server) handleGetHourlyStats(w http.ResponseWriter, r *http.Request) {
+	hours := s.getIntParam(r, "hours", 24)
+	
+	stats, err := s.chainStats.GetHourlyStats(hours)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, stats)
+}
+
+func (s *Server) handleGetActiveAddresses(w http.ResponseWriter, r *http.Request) {
+	days := s.getIntParam(r, "days", 30)
+	
+	points, err := s.chainStats.GetActiveAddresses(days)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, points)
+}
+
+func (s *Server) handleGetFeeBurn(w http.ResponseWriter, r *http.Request) {
+	days := s.getIntParam(r, "days", 30)
+	
+	points, err := s.chainStats.GetFeeBurn(days)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, points)
+}
+
+func (s *Server) handleGetInclusionStats(w http.ResponseWriter, r *http.Request) {
+	days := s.getIntParam(r, "days", 7)
+	
+	stats, err := s.txs.GetInclusionStats(days)
 	if err != nil {
 		s.errorResponse(w, 500, err.Error())
 		return
@@ -365,6 +828,95 @@ func (s *Server) handleGetDailyStats(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, stats)
 }
 
+func (s *Server) handleGetBurnStats(w http.ResponseWriter, r *http.Request) {
+	asset := r.URL.Query().Get("asset")
+	days := s.getIntParam(r, "days", 30)
+	
+	totals, err := s.burns.GetBurnTotals()
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	daily, err := s.burns.GetDailyBurns(asset, days)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, map[string]interface{}{
+		"totals": totals,
+		"daily":  daily,
+	})
+}
+
+// Label handlers
+
+func (s *Server) handleGetLabels(w http.ResponseWriter, r *http.Request) {
+	category := r.URL.Query().Get("category")
+	limit := s.getIntParam(r, "limit", 100)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	labels, err := s.labels.GetLabels(category, limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, labels)
+}
+
+func (s *Server) handleGetLabel(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	
+	label, err := s.labels.GetLabel(address)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if label == nil {
+		s.errorResponse(w, 404, "label not found")
+		return
+	}
+	
+	s.jsonResponse(w, label)
+}
+
+func (s *Server) handleSetLabel(w http.ResponseWriter, r *http.Request) {
+	var label service.Label
+	if err := json.NewDecoder(r.Body).Decode(&label); err != nil {
+		s.errorResponse(w, 400, "invalid label body")
+		return
+	}
+	label.Address = mux.Vars(r)["address"]
+	
+	if err := label.Validate(); err != nil {
+		s.errorResponse(w, 400, err.Error())
+		return
+	}
+	if err := s.labels.SetLabel(&label); err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, label)
+}
+
+func (s *Server) handleDeleteLabel(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	
+	deleted, err := s.labels.DeleteLabel(address)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if !deleted {
+		s.errorResponse(w, 404, "label not found")
+		return
+	}
+	
+	s.jsonResponse(w, map[string]string{"deleted": address})
+}
+
 // Search handler
 
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -373,14 +925,22 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, 400, "query required")
 		return
 	}
+	limit := s.getIntParam(r, "limit", 10)
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
 	
-	// Try to match query to block, tx, or account
-	// TODO: Implement search logic
+	matches, err := s.search.Search(query, limit)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
 	
-	s.jsonResponse(w, map[string]interface{}{
-		"query":   query,
-		"results": []interface{}{},
-	})
+	results := make([]SearchResult, len(matches))
+	for i, m := range matches {
+		results[i] = SearchResult{Type: m.Type, ID: m.ID, Score: m.Score, Preview: m.Preview}
+	}
+	s.jsonResponse(w, SearchResponse{Query: query, Results: results, Total: len(results)})
 }
 
 // Helpers
@@ -402,8 +962,8 @@ func (s *Server) getIntParam(r *http.Request, name string, defaultVal int) int {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 		
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -414,9 +974,162 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminMiddleware requires the configured bearer token
+func (s *Server) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			s.errorResponse(w, 403, "admin API disabled")
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			s.errorResponse(w, 401, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loggingMiddleware logs each request with its request ID and duration
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Printf("%s %s\n", r.Method, r.URL.Path)
+		start := time.Now()
 		next.ServeHTTP(w, r)
+		logger.InfoContext(r.Context(), "request", "method", r.Method, "path", r.URL.Path, "duration", time.Since(start))
 	})
 }
+
+// Dead-letter handlers
+
+func (s *Server) handleGetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := s.getIntParam(r, "limit", 100)
+	
+	letters, err := s.indexer.GetDeadLetters(limit)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, letters)
+}
+
+func (s *Server) handleReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.ParseUint(mux.Vars(r)["number"], 10, 64)
+	if err != nil {
+		s.errorResponse(w, 400, "invalid block number")
+		return
+	}
+	
+	if err := s.indexer.ReplayDeadLetter(number); err != nil {
+		if err == service.ErrNotDeadLettered {
+			s.errorResponse(w, 404, err.Error())
+			return
+		}
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, map[string]uint64{"replayed": number})
+}
+
+// API key handlers
+
+func (s *Server) handleIssueAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req service.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, 400, "invalid api key body")
+		return
+	}
+	
+	key, err := s.keys.Issue(&req)
+	switch err {
+	case nil:
+	case service.ErrInvalidAPIKeyName, service.ErrInvalidAPIKeyTier, service.ErrInvalidAPIKeyLimit:
+		s.errorResponse(w, 400, err.Error())
+		return
+	default:
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, key)
+}
+
+func (s *Server) handleGetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	limit := s.getIntParam(r, "limit", 100)
+	offset := s.getIntParam(r, "offset", 0)
+	
+	keys, err := s.keys.List(limit, offset)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, keys)
+}
+
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		s.errorResponse(w, 400, "invalid api key id")
+		return
+	}
+	
+	revoked, err := s.keys.Revoke(id)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if !revoked {
+		s.errorResponse(w, 404, service.ErrAPIKeyNotFound.Error())
+		return
+	}
+	
+	s.jsonResponse(w, map[string]int64{"revoked": id})
+}
+
+func (s *Server) handleGetAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		s.errorResponse(w, 400, "invalid api key id")
+		return
+	}
+	
+	s.writeUsage(w, id, r.URL.Query().Get("month"))
+}
+
+func (s *Server) handleGetUsage(w http.ResponseWriter, r *http.Request) {
+	key, err := s.requestKey(r)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	if key == nil {
+		s.errorResponse(w, 401, "api key required")
+		return
+	}
+	
+	s.writeUsage(w, key.ID, r.URL.Query().Get("month"))
+}
+
+// writeUsage responds with a key's request count for a month
+func (s *Server) writeUsage(w http.ResponseWriter, id int64, month string) {
+	if month != "" {
+		if _, err := time.Parse("2006-01", month); err != nil {
+			s.errorResponse(w, 400, "month must be YYYY-MM")
+			return
+		}
+	}
+	
+	usage, err := s.keys.Usage(id, month)
+	if err == service.ErrAPIKeyNotFound {
+		s.errorResponse(w, 404, err.Error())
+		return
+	}
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	
+	s.jsonResponse(w, usage)
+}
@@ -26,12 +26,15 @@ CREATE TABLE IF NOT EXISTS blocks (
     INDEX idx_blocks_validator (validator)
 );
 
--- Transactions table
+-- Transactions table, partitioned by month of block time so date-range
+-- queries only scan the months they cover. The indexer creates partitions
+-- as blocks arrive and compresses cold ones (see service/archive.go).
 CREATE TABLE IF NOT EXISTS transactions (
-    id SERIAL PRIMARY KEY,
-    hash VARCHAR(66) NOT NULL UNIQUE,
-    block_number BIGINT NOT NULL REFERENCES blocks(number),
+    id BIGSERIAL,
+    hash VARCHAR(66) NOT NULL,
+    block_number BIGINT NOT NULL,
     block_hash VARCHAR(66) NOT NULL,
+    block_time TIMESTAMP WITH TIME ZONE NOT NULL, -- partition key
     tx_index INT NOT NULL,
     from_address VARCHAR(42) NOT NULL,
     to_address VARCHAR(42),
@@ -40,17 +43,37 @@ CREATE TABLE IF NOT EXISTS transactions (
     fee VARCHAR(78) NOT NULL,
     nonce BIGINT NOT NULL,
     data BYTEA,
+    memo TEXT, -- sender's memo, e.g. an exchange deposit tag; NULL when absent
+    payload JSONB, -- decoded typed payload, NULL for types without one
     signature VARCHAR(130) NOT NULL,
     tx_type VARCHAR(20) NOT NULL DEFAULT 'transfer',
     status SMALLINT NOT NULL DEFAULT 1,
     gas_used BIGINT NOT NULL DEFAULT 0,
+    logs JSONB, -- receipt logs, NULL when the node had no receipt
+    submitted_at BIGINT, -- sender's tx timestamp (unix seconds)
+    inclusion_latency_secs BIGINT, -- block timestamp minus submitted_at
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     
+    -- Unique keys on a partitioned table must include the partition key
+    PRIMARY KEY (id, block_time),
+    UNIQUE (hash, block_time),
+    
+    INDEX idx_tx_hash (hash),
     INDEX idx_tx_from (from_address),
     INDEX idx_tx_to (to_address),
     INDEX idx_tx_block (block_number),
+    INDEX idx_tx_block_time (block_time),
     INDEX idx_tx_asset (asset),
     INDEX idx_tx_type (tx_type)
+) PARTITION BY RANGE (block_time);
+
+-- Monthly transaction partitions and when each was compressed
+CREATE TABLE IF NOT EXISTS transaction_partitions (
+    name VARCHAR(63) PRIMARY KEY,
+    range_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    range_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    compressed_at TIMESTAMP WITH TIME ZONE, -- NULL while the partition is hot
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
 -- Accounts table
@@ -106,13 +129,15 @@ CREATE TABLE IF NOT EXISTS assets (
 CREATE TABLE IF NOT EXISTS validators (
     id SERIAL PRIMARY KEY,
     address VARCHAR(42) NOT NULL UNIQUE,
+    moniker VARCHAR(64),
     stake VARCHAR(78) NOT NULL,
-    commission SMALLINT NOT NULL DEFAULT 0,
+    commission SMALLINT NOT NULL DEFAULT 0, -- basis points
     active BOOLEAN NOT NULL DEFAULT TRUE,
     jailed BOOLEAN NOT NULL DEFAULT FALSE,
     jailed_until BIGINT,
     blocks_proposed BIGINT NOT NULL DEFAULT 0,
     blocks_signed BIGINT NOT NULL DEFAULT 0,
+    blocks_missed BIGINT NOT NULL DEFAULT 0, -- summed from epoch summaries
     slashing_events INT NOT NULL DEFAULT 0,
     delegator_count INT NOT NULL DEFAULT 0,
     total_delegations VARCHAR(78) NOT NULL DEFAULT '0',
@@ -155,7 +180,7 @@ CREATE TABLE IF NOT EXISTS slashing_events (
 -- Token transfers table (for detailed transfer history)
 CREATE TABLE IF NOT EXISTS token_transfers (
     id SERIAL PRIMARY KEY,
-    tx_hash VARCHAR(66) NOT NULL REFERENCES transactions(hash),
+    tx_hash VARCHAR(66) NOT NULL, -- transactions is partitioned, so no foreign key
     from_address VARCHAR(42) NOT NULL,
     to_address VARCHAR(42) NOT NULL,
     asset VARCHAR(42) NOT NULL,
@@ -170,6 +195,115 @@ CREATE TABLE IF NOT EXISTS token_transfers (
     INDEX idx_transfers_block (block_number)
 );
 
+-- NFT collections table
+CREATE TABLE IF NOT EXISTS nft_collections (
+    id SERIAL PRIMARY KEY,
+    collection VARCHAR(12) NOT NULL UNIQUE,
+    creator VARCHAR(42) NOT NULL,
+    token_count INT NOT NULL DEFAULT 0,
+    created_block BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    INDEX idx_nft_collections_creator (creator)
+);
+
+-- NFT tokens table (current owner and mint metadata)
+CREATE TABLE IF NOT EXISTS nft_tokens (
+    id SERIAL PRIMARY KEY,
+    collection VARCHAR(12) NOT NULL,
+    token_id VARCHAR(64) NOT NULL,
+    owner VARCHAR(42) NOT NULL,
+    name VARCHAR(100),
+    token_uri TEXT,
+    minted_block BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    UNIQUE(collection, token_id),
+    INDEX idx_nft_tokens_owner (owner)
+);
+
+-- NFT transfers table; mints have no sender
+CREATE TABLE IF NOT EXISTS nft_transfers (
+    id SERIAL PRIMARY KEY,
+    tx_hash VARCHAR(66) NOT NULL,
+    collection VARCHAR(12) NOT NULL,
+    token_id VARCHAR(64) NOT NULL,
+    from_address VARCHAR(42),
+    to_address VARCHAR(42) NOT NULL,
+    block_number BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    INDEX idx_nft_transfers_token (collection, token_id),
+    INDEX idx_nft_transfers_from (from_address),
+    INDEX idx_nft_transfers_to (to_address),
+    INDEX idx_nft_transfers_block (block_number)
+);
+
+-- Epoch summaries table
+CREATE TABLE IF NOT EXISTS epochs (
+    epoch BIGINT PRIMARY KEY,
+    start_height BIGINT NOT NULL,
+    end_height BIGINT NOT NULL,
+    total_stake VARCHAR(78) NOT NULL DEFAULT '0',
+    rewards_minted VARCHAR(78) NOT NULL DEFAULT '0',
+    blocks_produced BIGINT NOT NULL DEFAULT 0,
+    blocks_missed BIGINT NOT NULL DEFAULT 0,
+    slashes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Per-validator rows of each epoch summary
+CREATE TABLE IF NOT EXISTS epoch_validators (
+    id SERIAL PRIMARY KEY,
+    epoch BIGINT NOT NULL REFERENCES epochs(epoch),
+    address VARCHAR(42) NOT NULL,
+    stake VARCHAR(78) NOT NULL DEFAULT '0',
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    blocks_produced BIGINT NOT NULL DEFAULT 0,
+    blocks_missed BIGINT NOT NULL DEFAULT 0,
+    rewards VARCHAR(78) NOT NULL DEFAULT '0',
+    
+    UNIQUE(epoch, address),
+    INDEX idx_epoch_validators_address (address)
+);
+
+-- Burns table (fee burns and explicit burn transactions)
+CREATE TABLE IF NOT EXISTS burns (
+    id SERIAL PRIMARY KEY,
+    block_number BIGINT NOT NULL REFERENCES blocks(number),
+    block_timestamp BIGINT NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    asset VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('fee', 'burn')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    INDEX idx_burns_asset (asset),
+    INDEX idx_burns_block (block_number)
+);
+
+-- Typed account events decoded from receipt logs, plus system events
+-- (fee burns, epoch rewards, slashes) that no transaction transfer shows
+CREATE TABLE IF NOT EXISTS events (
+    id SERIAL PRIMARY KEY,
+    block_number BIGINT NOT NULL,
+    block_timestamp BIGINT NOT NULL,
+    tx_hash VARCHAR(66), -- NULL for system events
+    log_index INTEGER,
+    event_type VARCHAR(30) NOT NULL,
+    address VARCHAR(42) NOT NULL, -- account the event acts on
+    counterparty VARCHAR(42),
+    asset VARCHAR(42),
+    amount VARCHAR(78) NOT NULL DEFAULT '0',
+    data JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    INDEX idx_events_address (address, block_number),
+    INDEX idx_events_counterparty (counterparty, block_number),
+    INDEX idx_events_type (event_type),
+    INDEX idx_events_block (block_number)
+);
+
 -- Mining rewards table
 CREATE TABLE IF NOT EXISTS mining_rewards (
     id SERIAL PRIMARY KEY,
@@ -199,6 +333,86 @@ CREATE TABLE IF NOT EXISTS stablecoin_peg_history (
     INDEX idx_peg_block (block_number)
 );
 
+-- Address labels table (curated via the admin API)
+CREATE TABLE IF NOT EXISTS address_labels (
+    address VARCHAR(42) PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('exchange', 'foundation', 'treasury', 'bridge', 'other')),
+    description TEXT,
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    INDEX idx_labels_category (category)
+);
+
+-- Blocks the indexer skipped after repeated failures
+CREATE TABLE IF NOT EXISTS dead_letter_blocks (
+    block_number BIGINT PRIMARY KEY,
+    block_hash VARCHAR(66) NOT NULL,
+    attempts INT NOT NULL,
+    error TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- API keys for the public explorer API (issued via the admin API); only
+-- a hash of each key is stored. Zero limits fall back to the tier's.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(16) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    tier VARCHAR(20) NOT NULL CHECK (tier IN ('free', 'pro', 'enterprise')),
+    rate_limit DOUBLE PRECISION NOT NULL DEFAULT 0,
+    monthly_quota BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Requests made with each API key per calendar month (YYYY-MM)
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id INT NOT NULL REFERENCES api_keys(id),
+    month CHAR(7) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, month)
+);
+
+-- Chain statistics aggregated per day and per hour by the stats worker
+CREATE TABLE IF NOT EXISTS chain_stats (
+    period VARCHAR(4) NOT NULL CHECK (period IN ('day', 'hour')),
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL, -- start of the day or hour (UTC)
+    tx_count BIGINT NOT NULL DEFAULT 0,
+    total_value VARCHAR(78) NOT NULL DEFAULT '0',
+    total_fees VARCHAR(78) NOT NULL DEFAULT '0',
+    fees_burned VARCHAR(78) NOT NULL DEFAULT '0', -- GYDS fee burns
+    gas_used BIGINT NOT NULL DEFAULT 0,
+    active_addresses BIGINT NOT NULL DEFAULT 0,
+    new_accounts BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    
+    PRIMARY KEY (period, bucket)
+);
+
+-- Pending transactions seen in the node's mempool, dropped once they are
+-- included, replaced or expire
+CREATE TABLE IF NOT EXISTS pending_transactions (
+    hash VARCHAR(66) PRIMARY KEY,
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42),
+    value VARCHAR(78) NOT NULL,
+    asset VARCHAR(42) NOT NULL DEFAULT 'GYDS',
+    fee VARCHAR(78) NOT NULL,
+    nonce BIGINT NOT NULL,
+    tx_type VARCHAR(20) NOT NULL DEFAULT 'transfer',
+    memo TEXT,
+    first_seen TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    
+    INDEX idx_pending_from (from_address),
+    INDEX idx_pending_to (to_address),
+    INDEX idx_pending_expires (expires_at)
+);
+
 -- Indexer state table
 CREATE TABLE IF NOT EXISTS indexer_state (
     id SERIAL PRIMARY KEY,
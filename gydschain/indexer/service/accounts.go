@@ -112,6 +112,9 @@ func (ai *AccountIndexer) GetAccount(address string) (*Account, error) {
 		account.Balances[asset] = balance
 	}
 	
+	if err := attachAccountLabels(ai.db, []*Account{account}); err != nil {
+		return nil, err
+	}
 	return account, nil
 }
 
@@ -155,6 +158,9 @@ func (ai *AccountIndexer) GetTopAccounts(asset string, limit int) ([]*Account, e
 		accounts = append(accounts, acc)
 	}
 	
+	if err := attachAccountLabels(ai.db, accounts); err != nil {
+		return nil, err
+	}
 	return accounts, nil
 }
 
@@ -185,12 +191,16 @@ func (ai *AccountIndexer) GetAccountTransactions(address string, limit, offset i
 		txs = append(txs, txn)
 	}
 	
+	if err := attachRecordLabels(ai.db, txs); err != nil {
+		return nil, err
+	}
 	return txs, nil
 }
 
 // Account represents an indexed account
 type Account struct {
 	Address        string            `json:"address"`
+	Label          *Label            `json:"label,omitempty"`
 	Nonce          uint64            `json:"nonce"`
 	TxCount        uint64            `json:"tx_count"`
 	FirstSeenBlock uint64            `json:"first_seen_block"`
@@ -204,7 +214,9 @@ type TransactionRecord struct {
 	BlockNumber uint64 `json:"block_number"`
 	TxIndex     int    `json:"tx_index"`
 	From        string `json:"from"`
+	FromLabel   *Label `json:"from_label,omitempty"`
 	To          string `json:"to"`
+	ToLabel     *Label `json:"to_label,omitempty"`
 	Value       string `json:"value"`
 	Asset       string `json:"asset"`
 	Fee         string `json:"fee"`
@@ -212,3 +224,60 @@ type TransactionRecord struct {
 	Status      int    `json:"status"`
 	CreatedAt   string `json:"created_at"`
 }
+
+// Rewind reverses the account activity of transactions from fromBlock on.
+// It must run before those transactions are deleted, as it replays them
+// backwards: senders are refunded, recipients debited and tx counts and
+// last-seen blocks restored. Accounts first seen in the range are removed.
+func (ai *AccountIndexer) Rewind(dbTx *sql.Tx, fromBlock uint64) error {
+	_, err := dbTx.Exec(`
+		UPDATE account_balances b
+		SET balance = (CAST(b.balance AS NUMERIC) + d.delta)::TEXT,
+			updated_at = NOW()
+		FROM (
+			SELECT address, asset, SUM(delta) AS delta FROM (
+				SELECT from_address AS address, asset, CAST(value AS NUMERIC) AS delta
+				FROM transactions WHERE block_number >= $1
+				UNION ALL
+				SELECT to_address, asset, -CAST(value AS NUMERIC)
+				FROM transactions WHERE block_number >= $1 AND COALESCE(to_address, '') <> ''
+			) moves GROUP BY address, asset
+		) d
+		WHERE b.address = d.address AND b.asset = d.asset
+	`, fromBlock)
+	if err != nil {
+		return fmt.Errorf("rewind balances: %w", err)
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE accounts a
+		SET tx_count = a.tx_count - d.txs,
+			last_seen_block = COALESCE((
+				SELECT MAX(t.block_number) FROM transactions t
+				WHERE t.block_number < $1 AND (t.from_address = a.address OR t.to_address = a.address)
+			), a.first_seen_block),
+			updated_at = NOW()
+		FROM (
+			SELECT address, COUNT(*) AS txs FROM (
+				SELECT from_address AS address FROM transactions WHERE block_number >= $1
+				UNION ALL
+				SELECT to_address FROM transactions WHERE block_number >= $1 AND COALESCE(to_address, '') <> ''
+			) seen GROUP BY address
+		) d
+		WHERE a.address = d.address
+	`, fromBlock)
+	if err != nil {
+		return fmt.Errorf("rewind accounts: %w", err)
+	}
+
+	stmts := []string{
+		"DELETE FROM account_balances WHERE address IN (SELECT address FROM accounts WHERE first_seen_block >= $1)",
+		"DELETE FROM accounts WHERE first_seen_block >= $1",
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt, fromBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
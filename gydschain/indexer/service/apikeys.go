@@ -0,0 +1,409 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API key tiers; anonymous applies to requests without a key
+const (
+	TierAnonymous  = "anonymous"
+	TierFree       = "free"
+	TierPro        = "pro"
+	TierEnterprise = "enterprise"
+)
+
+// apiKeyPrefix marks explorer API keys so leaked keys are easy to spot
+const apiKeyPrefix = "gyds_"
+
+// DefaultUsageFlushInterval is how often request counts are written to the DB
+const DefaultUsageFlushInterval = 30 * time.Second
+
+// Tier is the default limits for a class of API consumer
+type Tier struct {
+	Name         string  `json:"name"`
+	RateLimit    float64 `json:"rate_limit"`    // sustained requests per second
+	Burst        int     `json:"burst"`         // requests allowed at once
+	MonthlyQuota int64   `json:"monthly_quota"` // requests per calendar month; 0 is unlimited
+}
+
+// Tiers are the default limits per tier. Anonymous callers are limited per
+// IP and have no monthly quota since their usage is not accounted.
+var Tiers = map[string]Tier{
+	TierAnonymous:  {Name: TierAnonymous, RateLimit: 2, Burst: 10},
+	TierFree:       {Name: TierFree, RateLimit: 5, Burst: 20, MonthlyQuota: 100000},
+	TierPro:        {Name: TierPro, RateLimit: 25, Burst: 100, MonthlyQuota: 5000000},
+	TierEnterprise: {Name: TierEnterprise, RateLimit: 100, Burst: 400},
+}
+
+// API key errors
+var (
+	ErrInvalidAPIKeyTier  = errors.New("api key tier must be free, pro or enterprise")
+	ErrInvalidAPIKeyName  = errors.New("api key name must be 1-100 characters")
+	ErrInvalidAPIKeyLimit = errors.New("api key rate limit and quota must not be negative")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrQuotaExceeded      = errors.New("monthly request quota exceeded")
+)
+
+// APIKey is an issued explorer API key. Only a hash of the key is stored;
+// the key itself is returned once, when issued.
+type APIKey struct {
+	ID           int64   `json:"id"`
+	Key          string  `json:"key,omitempty"`
+	Prefix       string  `json:"prefix"`
+	Name         string  `json:"name"`
+	Tier         string  `json:"tier"`
+	RateLimit    float64 `json:"rate_limit,omitempty"`    // overrides the tier when set
+	MonthlyQuota int64   `json:"monthly_quota,omitempty"` // overrides the tier when set
+	CreatedAt    string  `json:"created_at,omitempty"`
+	RevokedAt    string  `json:"revoked_at,omitempty"`
+}
+
+// Validate checks a key request before it is issued
+func (k *APIKey) Validate() error {
+	name := strings.TrimSpace(k.Name)
+	if name == "" || len(name) > 100 {
+		return ErrInvalidAPIKeyName
+	}
+	switch k.Tier {
+	case TierFree, TierPro, TierEnterprise:
+	default:
+		return ErrInvalidAPIKeyTier
+	}
+	if k.RateLimit < 0 || k.MonthlyQuota < 0 {
+		return ErrInvalidAPIKeyLimit
+	}
+	return nil
+}
+
+// Limits returns the tier limits with the key's overrides applied
+func (k *APIKey) Limits() Tier {
+	limits := Tiers[k.Tier]
+	if k.RateLimit > 0 {
+		limits.RateLimit = k.RateLimit
+		if burst := int(k.RateLimit * 4); burst > limits.Burst {
+			limits.Burst = burst
+		}
+	}
+	if k.MonthlyQuota > 0 {
+		limits.MonthlyQuota = k.MonthlyQuota
+	}
+	return limits
+}
+
+// APIKeyUsage is the request count of a key for one month
+type APIKeyUsage struct {
+	KeyID    int64  `json:"key_id"`
+	Month    string `json:"month"`
+	Requests int64  `json:"requests"`
+	Quota    int64  `json:"quota"` // 0 is unlimited
+}
+
+// keyUsage counts a key's requests in the current month; pending requests
+// have not been written to the DB yet
+type keyUsage struct {
+	month   string
+	used    int64
+	pending int64
+}
+
+// APIKeyManager issues API keys and accounts their usage. Request counts
+// are kept in memory and flushed to the DB periodically.
+type APIKeyManager struct {
+	db *sql.DB
+
+	mu    sync.Mutex
+	keys  map[string]*APIKey // active keys by key hash
+	usage map[int64]*keyUsage
+
+	interval time.Duration
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewAPIKeyManager creates a new API key manager
+func NewAPIKeyManager(db *sql.DB) *APIKeyManager {
+	return &APIKeyManager{
+		db:       db,
+		keys:     make(map[string]*APIKey),
+		usage:    make(map[int64]*keyUsage),
+		interval: DefaultUsageFlushInterval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Issue creates a key and returns it with the plaintext key set
+func (m *APIKeyManager) Issue(req *APIKey) (*APIKey, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := &APIKey{
+		Key:          apiKeyPrefix + hex.EncodeToString(secret),
+		Name:         strings.TrimSpace(req.Name),
+		Tier:         req.Tier,
+		RateLimit:    req.RateLimit,
+		MonthlyQuota: req.MonthlyQuota,
+	}
+	key.Prefix = key.Key[:len(apiKeyPrefix)+8]
+
+	err := m.db.QueryRow(`
+		INSERT INTO api_keys (key_hash, prefix, name, tier, rate_limit, monthly_quota)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, hashAPIKey(key.Key), key.Prefix, key.Name, key.Tier, key.RateLimit, key.MonthlyQuota).Scan(&key.ID)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Revoke disables a key; it reports false if no active key has the id
+func (m *APIKeyManager) Revoke(id int64) (bool, error) {
+	res, err := m.db.Exec(
+		"UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	for hash, key := range m.keys {
+		if key.ID == id {
+			delete(m.keys, hash)
+		}
+	}
+	m.mu.Unlock()
+	return n > 0, nil
+}
+
+// List returns issued keys, newest first, without their secrets
+func (m *APIKeyManager) List(limit, offset int) ([]*APIKey, error) {
+	rows, err := m.db.Query(`
+		SELECT id, prefix, name, tier, rate_limit, monthly_quota, created_at, revoked_at
+		FROM api_keys
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		key := &APIKey{}
+		var revokedAt sql.NullString
+		if err := rows.Scan(&key.ID, &key.Prefix, &key.Name, &key.Tier, &key.RateLimit,
+			&key.MonthlyQuota, &key.CreatedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		key.RevokedAt = revokedAt.String
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Lookup returns the active key for a presented secret, or nil if it is
+// unknown or revoked. Found keys are cached until revoked.
+func (m *APIKeyManager) Lookup(secret string) (*APIKey, error) {
+	hash := hashAPIKey(secret)
+
+	m.mu.Lock()
+	key, cached := m.keys[hash]
+	m.mu.Unlock()
+	if cached {
+		return key, nil
+	}
+
+	key = &APIKey{}
+	err := m.db.QueryRow(`
+		SELECT id, prefix, name, tier, rate_limit, monthly_quota
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`, hash).Scan(&key.ID, &key.Prefix, &key.Name, &key.Tier, &key.RateLimit, &key.MonthlyQuota)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.keys[hash] = key
+	m.mu.Unlock()
+	return key, nil
+}
+
+// Use counts a request against the key's monthly quota. It returns the
+// requests used this month including this one, or ErrQuotaExceeded.
+func (m *APIKeyManager) Use(key *APIKey) (int64, error) {
+	month := usageMonth(time.Now())
+
+	m.mu.Lock()
+	usage := m.usage[key.ID]
+	m.mu.Unlock()
+
+	// Load the persisted count outside the lock the first time a key is
+	// seen in a month
+	if usage == nil || usage.month != month {
+		used, err := m.persistedUsage(key.ID, month)
+		if err != nil {
+			return 0, err
+		}
+		m.mu.Lock()
+		if current := m.usage[key.ID]; current != nil && current.month == month {
+			usage = current
+		} else {
+			if current != nil && current.pending > 0 {
+				// Last month's unflushed requests are written before the
+				// counter is reset
+				if err := m.flushLocked(key.ID, current); err != nil {
+					logger.Error("API key usage flush failed", "err", err)
+				}
+			}
+			usage = &keyUsage{month: month, used: used}
+			m.usage[key.ID] = usage
+		}
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	quota := key.Limits().MonthlyQuota
+	if quota > 0 && usage.used >= quota {
+		return usage.used, ErrQuotaExceeded
+	}
+	usage.used++
+	usage.pending++
+	return usage.used, nil
+}
+
+// Usage returns a key's request count for a month (YYYY-MM; default the
+// current month), including requests not yet flushed
+func (m *APIKeyManager) Usage(id int64, month string) (*APIKeyUsage, error) {
+	if month == "" {
+		month = usageMonth(time.Now())
+	}
+
+	var key APIKey
+	err := m.db.QueryRow(
+		"SELECT id, tier, rate_limit, monthly_quota FROM api_keys WHERE id = $1", id,
+	).Scan(&key.ID, &key.Tier, &key.RateLimit, &key.MonthlyQuota)
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := m.persistedUsage(id, month)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	if usage := m.usage[id]; usage != nil && usage.month == month {
+		requests += usage.pending
+	}
+	m.mu.Unlock()
+
+	return &APIKeyUsage{
+		KeyID:    id,
+		Month:    month,
+		Requests: requests,
+		Quota:    key.Limits().MonthlyQuota,
+	}, nil
+}
+
+// Start flushes usage to the DB every interval until Stop
+func (m *APIKeyManager) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case <-ticker.C:
+				if err := m.Flush(); err != nil {
+					logger.Error("API key usage flush failed", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends periodic flushing and writes any remaining usage
+func (m *APIKeyManager) Stop() error {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+	return m.Flush()
+}
+
+// Flush writes pending request counts to the DB
+func (m *APIKeyManager) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, usage := range m.usage {
+		if usage.pending == 0 {
+			continue
+		}
+		if err := m.flushLocked(id, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushLocked adds a key's pending requests to its stored monthly count
+func (m *APIKeyManager) flushLocked(id int64, usage *keyUsage) error {
+	_, err := m.db.Exec(`
+		INSERT INTO api_key_usage (key_id, month, requests)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key_id, month) DO UPDATE SET
+			requests = api_key_usage.requests + $3
+	`, id, usage.month, usage.pending)
+	if err != nil {
+		return err
+	}
+	usage.pending = 0
+	return nil
+}
+
+// persistedUsage returns the flushed request count of a key for a month
+func (m *APIKeyManager) persistedUsage(id int64, month string) (int64, error) {
+	var requests int64
+	err := m.db.QueryRow(
+		"SELECT requests FROM api_key_usage WHERE key_id = $1 AND month = $2", id, month,
+	).Scan(&requests)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return requests, err
+}
+
+// hashAPIKey returns the stored form of a key
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// usageMonth returns the accounting month of t
+func usageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// transactionIndexes are created on a partitioned transactions table;
+// Postgres cascades them to every partition
+var transactionIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_tx_hash ON transactions (hash)",
+	"CREATE INDEX IF NOT EXISTS idx_tx_from ON transactions (from_address)",
+	"CREATE INDEX IF NOT EXISTS idx_tx_to ON transactions (to_address)",
+	"CREATE INDEX IF NOT EXISTS idx_tx_block ON transactions (block_number)",
+	"CREATE INDEX IF NOT EXISTS idx_tx_block_time ON transactions (block_time)",
+	"CREATE INDEX IF NOT EXISTS idx_tx_asset ON transactions (asset)",
+	"CREATE INDEX IF NOT EXISTS idx_tx_type ON transactions (tx_type)",
+}
+
+// recentTransactionsView is recreated after a migration drops it
+const recentTransactionsView = `
+	CREATE OR REPLACE VIEW recent_transactions AS
+	SELECT
+		t.*,
+		b.timestamp as block_timestamp
+	FROM transactions t
+	JOIN blocks b ON t.block_number = b.number
+	ORDER BY t.id DESC
+	LIMIT 100
+`
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// TransactionArchiver keeps the transactions table partitioned by month of
+// block time. Partitions are created as blocks for a new month arrive, and
+// once a month leaves the hot window its partition is compressed. Cold
+// months are read-only from then on, since reorgs never reach that deep.
+type TransactionArchiver struct {
+	db        *sql.DB
+	hotMonths int
+
+	mu    sync.Mutex
+	known map[string]bool // partitions known to exist
+}
+
+// NewTransactionArchiver creates an archiver that keeps the last hotMonths
+// months uncompressed
+func NewTransactionArchiver(db *sql.DB, hotMonths int) *TransactionArchiver {
+	return &TransactionArchiver{
+		db:        db,
+		hotMonths: hotMonths,
+		known:     make(map[string]bool),
+	}
+}
+
+// monthStart truncates t to the first instant of its UTC month
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName names the partition holding the month starting at start
+func partitionName(start time.Time) string {
+	return fmt.Sprintf("transactions_y%04dm%02d", start.Year(), start.Month())
+}
+
+// EnsurePartition creates the partition for the month of blockTime if it
+// does not exist yet
+func (ta *TransactionArchiver) EnsurePartition(blockTime time.Time) error {
+	start := monthStart(blockTime)
+	name := partitionName(start)
+
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	if ta.known[name] {
+		return nil
+	}
+	if err := createPartition(ta.db, start); err != nil {
+		return err
+	}
+	ta.known[name] = true
+	return nil
+}
+
+// createPartition creates and registers the partition for the month
+// starting at start
+func createPartition(db execer, start time.Time) error {
+	end := start.AddDate(0, 1, 0)
+	name := partitionName(start)
+
+	// DDL takes no bind parameters; the name and bounds are generated above
+	_, err := db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF transactions FOR VALUES FROM ('%s') TO ('%s')",
+		name, start.Format(time.RFC3339), end.Format(time.RFC3339),
+	))
+	if err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO transaction_partitions (name, range_start, range_end)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO NOTHING
+	`, name, start, end)
+	return err
+}
+
+// CompressColdPartitions compresses every partition that ended before the
+// hot window, returning how many were compressed
+func (ta *TransactionArchiver) CompressColdPartitions(now time.Time) (int, error) {
+	cutoff := monthStart(now).AddDate(0, -ta.hotMonths, 0)
+
+	rows, err := ta.db.Query(`
+		SELECT name FROM transaction_partitions
+		WHERE range_end <= $1 AND compressed_at IS NULL
+		ORDER BY range_start ASC
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var columnar bool
+	if err := ta.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM pg_am WHERE amname = 'columnar')",
+	).Scan(&columnar); err != nil {
+		return 0, err
+	}
+
+	for i, name := range names {
+		if err := ta.compress(name, columnar); err != nil {
+			return i, fmt.Errorf("compress partition %s: %w", name, err)
+		}
+		if _, err := ta.db.Exec(
+			"UPDATE transaction_partitions SET compressed_at = NOW() WHERE name = $1", name,
+		); err != nil {
+			return i, err
+		}
+	}
+	return len(names), nil
+}
+
+// compress rewrites a cold partition compactly. With the columnar access
+// method installed the partition is converted to column storage; otherwise
+// its large columns switch to lz4 and the table is repacked with no free
+// space left for updates that will not come.
+func (ta *TransactionArchiver) compress(name string, columnar bool) error {
+	if columnar {
+		_, err := ta.db.Exec(fmt.Sprintf("ALTER TABLE %s SET ACCESS METHOD columnar", name))
+		return err
+	}
+
+	stmts := []string{
+		fmt.Sprintf(`ALTER TABLE %s
+			ALTER COLUMN data SET COMPRESSION lz4,
+			ALTER COLUMN payload SET COMPRESSION lz4,
+			ALTER COLUMN logs SET COMPRESSION lz4,
+			SET (fillfactor = 100)`, name),
+		fmt.Sprintf("VACUUM FULL %s", name),
+	}
+	for _, stmt := range stmts {
+		if _, err := ta.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migrate converts a transactions table created before partitioning into
+// the partitioned layout, taking each row's block time from its block.
+// It does nothing when the table is already partitioned.
+func (ta *TransactionArchiver) Migrate() error {
+	var kind string
+	err := ta.db.QueryRow(
+		"SELECT relkind FROM pg_class WHERE oid = to_regclass('transactions')",
+	).Scan(&kind)
+	if err == sql.ErrNoRows || (err == nil && kind == "p") {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dbTx, err := ta.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	stmts := []string{
+		"ALTER TABLE transactions RENAME TO transactions_legacy",
+		"DROP VIEW IF EXISTS recent_transactions",
+		"ALTER TABLE token_transfers DROP CONSTRAINT IF EXISTS token_transfers_tx_hash_fkey",
+		"ALTER SEQUENCE transactions_id_seq OWNED BY NONE",
+		`CREATE TABLE transactions (
+			LIKE transactions_legacy INCLUDING DEFAULTS,
+			block_time TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (id, block_time),
+			UNIQUE (hash, block_time)
+		) PARTITION BY RANGE (block_time)`,
+	}
+	stmts = append(stmts, transactionIndexes...)
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate transactions: %w", err)
+		}
+	}
+
+	// Partition every month the legacy rows span
+	var first, last sql.NullInt64
+	if err := dbTx.QueryRow(`
+		SELECT MIN(b.timestamp), MAX(b.timestamp)
+		FROM transactions_legacy t
+		JOIN blocks b ON t.block_number = b.number
+	`).Scan(&first, &last); err != nil {
+		return err
+	}
+	if first.Valid {
+		end := time.Unix(last.Int64, 0)
+		for month := monthStart(time.Unix(first.Int64, 0)); !month.After(end); month = month.AddDate(0, 1, 0) {
+			if err := createPartition(dbTx, month); err != nil {
+				return err
+			}
+		}
+	}
+
+	stmts = []string{
+		`INSERT INTO transactions
+		 SELECT t.*, to_timestamp(b.timestamp)
+		 FROM transactions_legacy t
+		 JOIN blocks b ON t.block_number = b.number`,
+		"ALTER SEQUENCE transactions_id_seq OWNED BY transactions.id",
+		"DROP TABLE transactions_legacy",
+		recentTransactionsView,
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate transactions: %w", err)
+		}
+	}
+
+	return dbTx.Commit()
+}
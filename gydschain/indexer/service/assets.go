@@ -17,46 +17,62 @@ func NewAssetIndexer(db *sql.DB) *AssetIndexer {
 }
 
 // UpdateFromTransaction updates asset data from a transaction
-func (ai *AssetIndexer) UpdateFromTransaction(dbTx *sql.Tx, txn *tx.Transaction) error {
+func (ai *AssetIndexer) UpdateFromTransaction(dbTx *sql.Tx, txn *tx.Transaction, blockNumber uint64) error {
 	// Handle asset creation transactions
-	if txn.Type == tx.TxTypeAssetCreate {
-		return ai.indexNewAsset(dbTx, txn)
+	if txn.Type == tx.TxTypeCreateAsset {
+		return ai.indexNewAsset(dbTx, txn, blockNumber)
 	}
 	
 	// Handle mint transactions
-	if txn.Type == tx.TxTypeAssetMint {
-		return ai.updateSupply(dbTx, txn.Asset, txn.Value.String(), true)
+	if txn.Type == tx.TxTypeMint {
+		return ai.updateSupply(dbTx, txn.Asset, txn.Amount.String(), true)
 	}
 	
 	// Handle burn transactions
-	if txn.Type == tx.TxTypeAssetBurn {
-		return ai.updateSupply(dbTx, txn.Asset, txn.Value.String(), false)
+	if txn.Type == tx.TxTypeBurn {
+		return ai.updateSupply(dbTx, txn.Asset, txn.Amount.String(), false)
 	}
 	
 	return nil
 }
 
-// indexNewAsset indexes a newly created asset
-func (ai *AssetIndexer) indexNewAsset(dbTx *sql.Tx, txn *tx.Transaction) error {
-	// Parse asset data from transaction data
-	// This is simplified - in production you'd parse the actual asset data
-	_, err := dbTx.Exec(`
-		INSERT INTO assets (asset_id, symbol, name, decimals, total_supply, creator, 
-		                    is_native, is_stablecoin, mintable, burnable, created_block)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+// indexNewAsset indexes the asset defined by a create_asset transaction.
+// The chain registers it under its symbol with the tx amount as the
+// initial supply.
+func (ai *AssetIndexer) indexNewAsset(dbTx *sql.Tx, txn *tx.Transaction, blockNumber uint64) error {
+	payload, err := tx.DecodePayload(txn)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.CreateAssetPayload)
+	
+	var maxSupply, peg sql.NullString
+	if p.MaxSupply.Int().Sign() > 0 {
+		maxSupply = sql.NullString{String: p.MaxSupply.Int().String(), Valid: true}
+	}
+	if p.Stablecoin {
+		peg = sql.NullString{String: p.Peg, Valid: true}
+	}
+	
+	_, err = dbTx.Exec(`
+		INSERT INTO assets (asset_id, symbol, name, decimals, total_supply, max_supply, creator,
+		                    is_native, is_stablecoin, peg_target, mintable, burnable, created_block)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (asset_id) DO NOTHING
 	`,
-		txn.Asset,
-		txn.Asset, // Symbol - would parse from data
-		txn.Asset, // Name - would parse from data
-		18,        // Decimals - would parse from data
-		"0",       // Initial supply
+		p.Symbol,
+		p.Symbol,
+		p.Name,
+		p.Decimals,
+		txn.Amount.String(),
+		maxSupply,
 		txn.From,
 		false,
-		false,
-		true,
-		true,
-		0, // Block number - would get from context
+		p.Stablecoin,
+		peg,
+		p.Mintable,
+		p.Burnable,
+		blockNumber,
 	)
 	return err
 }
@@ -78,19 +94,52 @@ func (ai *AssetIndexer) updateSupply(dbTx *sql.Tx, assetID, amount string, isMin
 	return err
 }
 
+// Rewind reverses the asset activity of transactions from fromBlock on: it
+// undoes their mints and burns, then drops their token transfers and the
+// assets they created. It must run before those transactions are deleted.
+func (ai *AssetIndexer) Rewind(dbTx *sql.Tx, fromBlock uint64) error {
+	_, err := dbTx.Exec(`
+		UPDATE assets a
+		SET total_supply = (CAST(a.total_supply AS NUMERIC) - d.minted)::TEXT
+		FROM (
+			SELECT asset, SUM(CASE WHEN tx_type = $2 THEN CAST(value AS NUMERIC)
+			                       ELSE -CAST(value AS NUMERIC) END) AS minted
+			FROM transactions
+			WHERE block_number >= $1 AND tx_type IN ($2, $3)
+			GROUP BY asset
+		) d
+		WHERE a.asset_id = d.asset
+	`, fromBlock, tx.TxTypeMint, tx.TxTypeBurn)
+	if err != nil {
+		return err
+	}
+
+	stmts := []string{
+		"DELETE FROM token_transfers WHERE block_number >= $1",
+		"DELETE FROM assets WHERE created_block >= $1",
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt, fromBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetAsset retrieves an asset by ID
 func (ai *AssetIndexer) GetAsset(assetID string) (*Asset, error) {
 	asset := &Asset{}
 	
 	err := ai.db.QueryRow(`
 		SELECT asset_id, symbol, name, decimals, total_supply, max_supply,
-		       creator, is_native, is_stablecoin, peg_target, mintable, burnable, created_block
+		       creator, is_native, is_stablecoin, peg_target, mintable, burnable, created_block,
+		       COALESCE((SELECT SUM(CAST(amount AS NUMERIC)) FROM burns b WHERE b.asset = assets.asset_id), 0)::TEXT
 		FROM assets WHERE asset_id = $1
 	`, assetID).Scan(
 		&asset.ID, &asset.Symbol, &asset.Name, &asset.Decimals,
 		&asset.TotalSupply, &asset.MaxSupply, &asset.Creator,
 		&asset.IsNative, &asset.IsStablecoin, &asset.PegTarget,
-		&asset.Mintable, &asset.Burnable, &asset.CreatedBlock,
+		&asset.Mintable, &asset.Burnable, &asset.CreatedBlock, &asset.Burned,
 	)
 	
 	if err == sql.ErrNoRows {
@@ -103,7 +152,8 @@ func (ai *AssetIndexer) GetAsset(assetID string) (*Asset, error) {
 func (ai *AssetIndexer) GetAllAssets() ([]*Asset, error) {
 	rows, err := ai.db.Query(`
 		SELECT asset_id, symbol, name, decimals, total_supply, max_supply,
-		       creator, is_native, is_stablecoin, peg_target, mintable, burnable, created_block
+		       creator, is_native, is_stablecoin, peg_target, mintable, burnable, created_block,
+		       COALESCE((SELECT SUM(CAST(amount AS NUMERIC)) FROM burns b WHERE b.asset = assets.asset_id), 0)::TEXT
 		FROM assets
 		ORDER BY is_native DESC, symbol ASC
 	`)
@@ -119,7 +169,7 @@ func (ai *AssetIndexer) GetAllAssets() ([]*Asset, error) {
 			&asset.ID, &asset.Symbol, &asset.Name, &asset.Decimals,
 			&asset.TotalSupply, &asset.MaxSupply, &asset.Creator,
 			&asset.IsNative, &asset.IsStablecoin, &asset.PegTarget,
-			&asset.Mintable, &asset.Burnable, &asset.CreatedBlock,
+			&asset.Mintable, &asset.Burnable, &asset.CreatedBlock, &asset.Burned,
 		); err != nil {
 			return nil, err
 		}
@@ -152,6 +202,9 @@ func (ai *AssetIndexer) GetAssetHolders(assetID string, limit, offset int) ([]*A
 		holders = append(holders, holder)
 	}
 	
+	if err := attachHolderLabels(ai.db, holders); err != nil {
+		return nil, err
+	}
 	return holders, nil
 }
 
@@ -236,12 +289,14 @@ type Asset struct {
 	PegTarget    *string `json:"peg_target,omitempty"`
 	Mintable     bool    `json:"mintable"`
 	Burnable     bool    `json:"burnable"`
+	Burned       string  `json:"burned"` // fee and explicit burns to date
 	CreatedBlock uint64  `json:"created_block"`
 }
 
 // AssetHolder represents an asset holder
 type AssetHolder struct {
 	Address string `json:"address"`
+	Label   *Label `json:"label,omitempty"`
 	Balance string `json:"balance"`
 }
 
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// errStopped ends a backfill when the indexer is stopped
+var errStopped = errors.New("indexer stopped")
+
+// BackfillProgress reports a parallel historical sync
+type BackfillProgress struct {
+	Running      bool      `json:"running"`
+	From         uint64    `json:"from"`
+	Target       uint64    `json:"target"`
+	Indexed      uint64    `json:"indexed"` // highest block backfilled so far
+	BlocksPerSec float64   `json:"blocks_per_sec"`
+	ETASecs      int64     `json:"eta_secs"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// fetchedBlock is a block fetched ahead of indexing with its receipts
+type fetchedBlock struct {
+	block    *chain.Block
+	receipts []*rpc.TransactionReceiptResponse
+}
+
+// Backfill catches up when the indexer is at least BackfillThreshold blocks
+// behind the node's safe height. Blocks and receipts are fetched by
+// BackfillWorkers in parallel, BatchSize at a time, and written in height
+// order; last_indexed_block is saved after every batch, so a restarted
+// indexer resumes from the last complete batch. It returns nil when there
+// is nothing to backfill or the indexer is stopped.
+func (idx *Indexer) Backfill(ctx context.Context) error {
+	workers := idx.config.BackfillWorkers
+	if workers <= 0 {
+		return nil
+	}
+	height, err := idx.nodes.GetBlockHeight()
+	if err != nil {
+		return fmt.Errorf("get block height: %w", err)
+	}
+	if height < uint64(idx.config.ConfirmBlocks) {
+		return nil
+	}
+	target := height - uint64(idx.config.ConfirmBlocks)
+	from := idx.GetLastIndexedBlock()
+	if target <= from || target-from < idx.config.BackfillThreshold {
+		return nil
+	}
+
+	batch := uint64(idx.config.BatchSize)
+	if batch == 0 {
+		batch = uint64(workers)
+	}
+
+	started := time.Now()
+	idx.mu.Lock()
+	idx.backfill = BackfillProgress{Running: true, From: from, Target: target, Indexed: from, StartedAt: started}
+	idx.mu.Unlock()
+	defer func() {
+		idx.mu.Lock()
+		idx.backfill.Running = false
+		idx.mu.Unlock()
+	}()
+	logger.Info("backfill started", "from", from, "target", target, "workers", workers)
+
+	for next := from + 1; next <= target; {
+		end := next + batch - 1
+		if end > target {
+			end = target
+		}
+
+		blocks, err := idx.fetchBatch(ctx, next, end, workers)
+		if err == errStopped || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, fb := range blocks {
+			if err := idx.writeBlock(fb.block, fb.receipts); err != nil {
+				// Fall back to the live path, which retries and dead-letters
+				if !idx.processWithRetry(ctx, fb.block) {
+					return nil
+				}
+				continue
+			}
+			idx.mu.Lock()
+			idx.stats.Indexed++
+			idx.mu.Unlock()
+			idx.advance(fb.block.Header.Height)
+		}
+		if err := idx.saveState(); err != nil {
+			return fmt.Errorf("save state: %w", err)
+		}
+
+		idx.reportBackfill(end, started)
+		next = end + 1
+	}
+
+	logger.Info("backfill finished", "blocks", target-from, "took", time.Since(started).Round(time.Second))
+	return nil
+}
+
+// reportBackfill updates the backfill progress after a batch
+func (idx *Indexer) reportBackfill(indexed uint64, started time.Time) {
+	idx.mu.Lock()
+	p := &idx.backfill
+	p.Indexed = indexed
+	if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+		p.BlocksPerSec = float64(indexed-p.From) / elapsed
+	}
+	if p.BlocksPerSec > 0 {
+		p.ETASecs = int64(float64(p.Target-indexed) / p.BlocksPerSec)
+	}
+	progress := *p
+	idx.mu.Unlock()
+
+	logger.Info("backfill progress", "indexed", progress.Indexed, "target", progress.Target,
+		"blocks_per_sec", fmt.Sprintf("%.1f", progress.BlocksPerSec), "eta_secs", progress.ETASecs)
+}
+
+// fetchBatch fetches blocks from through to with their receipts using
+// workers goroutines, returning them in height order
+func (idx *Indexer) fetchBatch(ctx context.Context, from, to uint64, workers int) ([]fetchedBlock, error) {
+	results := make([]fetchedBlock, to-from+1)
+	heights := make(chan uint64)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range heights {
+				if failed() {
+					continue
+				}
+				fb, err := idx.fetchBlock(number)
+				if err != nil {
+					fail(fmt.Errorf("fetch block %d: %w", number, err))
+					continue
+				}
+				results[number-from] = fb
+			}
+		}()
+	}
+
+feed:
+	for number := from; number <= to && !failed(); number++ {
+		select {
+		case heights <- number:
+		case <-ctx.Done():
+			fail(ctx.Err())
+			break feed
+		case <-idx.stop:
+			fail(errStopped)
+			break feed
+		}
+	}
+	close(heights)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// fetchBlock fetches a block and its receipts
+func (idx *Indexer) fetchBlock(number uint64) (fetchedBlock, error) {
+	block, err := idx.nodes.GetBlockByNumber(number)
+	if err != nil {
+		return fetchedBlock{}, err
+	}
+	receipts, err := idx.fetchReceipts(block)
+	if err != nil {
+		return fetchedBlock{}, err
+	}
+	return fetchedBlock{block: block, receipts: receipts}, nil
+}
+
+// GetBackfillProgress returns the progress of the current or last backfill
+func (idx *Indexer) GetBackfillProgress() BackfillProgress {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.backfill
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"database/sql"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// Burn sources
+const (
+	BurnSourceFee = "fee"  // share of a transaction fee removed from supply
+	BurnSourceTx  = "burn" // explicit burn transaction
+)
+
+// DefaultFeeBurnRate is the share of fees burned in basis points; the chain
+// pays no fees out to validators, so all of it leaves supply
+const DefaultFeeBurnRate = 10000
+
+// BurnIndexer records supply removed by fee burns and burn transactions
+type BurnIndexer struct {
+	db          *sql.DB
+	feeBurnRate uint64
+}
+
+// NewBurnIndexer creates a new burn indexer
+func NewBurnIndexer(db *sql.DB, feeBurnRate uint64) *BurnIndexer {
+	return &BurnIndexer{db: db, feeBurnRate: feeBurnRate}
+}
+
+// UpdateFromTransaction records the burns caused by a transaction
+func (bi *BurnIndexer) UpdateFromTransaction(dbTx *sql.Tx, block *chain.Block, txn *tx.Transaction) error {
+	hash, err := txn.HashHex()
+	if err != nil {
+		return err
+	}
+
+	if burned := tx.CalculateBurnAmount(txn.Fee, bi.feeBurnRate); burned.Sign() > 0 {
+		if err := bi.insertBurn(dbTx, block, hash, txn.Asset, burned, BurnSourceFee); err != nil {
+			return err
+		}
+	}
+	if txn.Type == tx.TxTypeBurn && txn.Amount != nil && txn.Amount.Sign() > 0 {
+		return bi.insertBurn(dbTx, block, hash, txn.Asset, txn.Amount, BurnSourceTx)
+	}
+	return nil
+}
+
+func (bi *BurnIndexer) insertBurn(dbTx *sql.Tx, block *chain.Block, txHash, asset string, amount *big.Int, source string) error {
+	_, err := dbTx.Exec(`
+		INSERT INTO burns (block_number, block_timestamp, tx_hash, asset, amount, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, block.Header.Height, block.Header.Timestamp, txHash, asset,
+		amount.String(), source)
+	return err
+}
+
+// GetBurnTotals returns all-time burned amounts per asset
+func (bi *BurnIndexer) GetBurnTotals() ([]*BurnTotal, error) {
+	rows, err := bi.db.Query(`
+		SELECT
+			asset,
+			COALESCE(SUM(CAST(amount AS NUMERIC)) FILTER (WHERE source = 'fee'), 0)::TEXT,
+			COALESCE(SUM(CAST(amount AS NUMERIC)) FILTER (WHERE source = 'burn'), 0)::TEXT,
+			SUM(CAST(amount AS NUMERIC))::TEXT
+		FROM burns
+		GROUP BY asset
+		ORDER BY asset ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*BurnTotal
+	for rows.Next() {
+		total := &BurnTotal{}
+		if err := rows.Scan(&total.Asset, &total.FeeBurned, &total.TxBurned, &total.Total); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// GetDailyBurns returns the per-day burn series for the last days, with a
+// running total that includes burns from before the window
+func (bi *BurnIndexer) GetDailyBurns(asset string, days int) ([]*DailyBurn, error) {
+	rows, err := bi.db.Query(`
+		WITH daily AS (
+			SELECT
+				DATE(TO_TIMESTAMP(block_timestamp)) AS date,
+				asset,
+				COALESCE(SUM(CAST(amount AS NUMERIC)) FILTER (WHERE source = 'fee'), 0) AS fee_burned,
+				COALESCE(SUM(CAST(amount AS NUMERIC)) FILTER (WHERE source = 'burn'), 0) AS tx_burned,
+				SUM(CAST(amount AS NUMERIC)) AS total
+			FROM burns
+			WHERE $1 = '' OR asset = $1
+			GROUP BY DATE(TO_TIMESTAMP(block_timestamp)), asset
+		), series AS (
+			SELECT *, SUM(total) OVER (PARTITION BY asset ORDER BY date) AS cumulative
+			FROM daily
+		)
+		SELECT date::TEXT, asset, fee_burned::TEXT, tx_burned::TEXT, total::TEXT, cumulative::TEXT
+		FROM series
+		WHERE date >= CURRENT_DATE - $2::INT
+		ORDER BY asset ASC, date ASC
+	`, asset, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var series []*DailyBurn
+	for rows.Next() {
+		day := &DailyBurn{}
+		if err := rows.Scan(&day.Date, &day.Asset, &day.FeeBurned, &day.TxBurned, &day.Total, &day.Cumulative); err != nil {
+			return nil, err
+		}
+		series = append(series, day)
+	}
+	return series, nil
+}
+
+// BurnTotal is the all-time amount of an asset removed from supply
+type BurnTotal struct {
+	Asset     string `json:"asset"`
+	FeeBurned string `json:"fee_burned"`
+	TxBurned  string `json:"tx_burned"`
+	Total     string `json:"total"`
+}
+
+// DailyBurn is one day of the burned-supply series for an asset
+type DailyBurn struct {
+	Date       string `json:"date"`
+	Asset      string `json:"asset"`
+	FeeBurned  string `json:"fee_burned"`
+	TxBurned   string `json:"tx_burned"`
+	Total      string `json:"total"`
+	Cumulative string `json:"cumulative"`
+}
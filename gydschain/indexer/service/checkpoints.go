@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// DefaultCheckpointPoll is how often the node's latest checkpoint is fetched
+const DefaultCheckpointPoll = 30 * time.Second
+
+// Checkpoint errors
+var (
+	ErrReorgPastCheckpoint = errors.New("reorg past a finalized checkpoint")
+	ErrCheckpointConflict  = errors.New("checkpoint conflicts with the indexed block")
+)
+
+// parseTrustedValidators parses the configured validator keys checkpoints
+// are verified against
+func parseTrustedValidators(keys map[string]string) (map[string][]byte, error) {
+	trusted := make(map[string][]byte, len(keys))
+	for address, hexKey := range keys {
+		key, err := crypto.ParsePublicKey(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("trusted validator %s: %w", address, err)
+		}
+		trusted[address] = key
+	}
+	return trusted, nil
+}
+
+// followCheckpoints periodically adopts the node's latest verified
+// checkpoint as the finalized height
+func (idx *Indexer) followCheckpoints(ctx context.Context) {
+	ticker := time.NewTicker(idx.config.CheckpointPoll)
+	defer ticker.Stop()
+
+	for {
+		if err := idx.updateFinalized(); err != nil {
+			logger.Warn("checkpoint not adopted", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-idx.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateFinalized fetches the node's latest checkpoint, verifies its
+// commit and adopts it if it is higher than the current one. Without
+// trusted validators configured only the certificate's own consistency is
+// checked. A checkpoint for a block other than the one indexed at its
+// height is refused: the node is on a branch the index never followed.
+func (idx *Indexer) updateFinalized() error {
+	cp, err := idx.nodes.GetLatestCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.RLock()
+	current := idx.finalized
+	idx.mu.RUnlock()
+	if current != nil && cp.Height <= current.Height {
+		if cp.Height == current.Height && cp.BlockHash != current.BlockHash {
+			return fmt.Errorf("%w at height %d", ErrCheckpointConflict, cp.Height)
+		}
+		return nil
+	}
+
+	if err := cp.Verify(idx.trusted); err != nil {
+		return fmt.Errorf("checkpoint %d: %w", cp.Height, err)
+	}
+	stored, err := idx.storedHash(cp.Height)
+	if err != nil {
+		return err
+	}
+	if stored != "" && stored != cp.BlockHash {
+		return fmt.Errorf("%w at height %d", ErrCheckpointConflict, cp.Height)
+	}
+
+	idx.mu.Lock()
+	idx.finalized = cp
+	idx.mu.Unlock()
+	logger.Info("checkpoint finalized", "height", cp.Height, "hash", cp.BlockHash)
+	return nil
+}
+
+// GetFinalizedHeight returns the height of the latest verified checkpoint,
+// below which the index is never rolled back
+func (idx *Indexer) GetFinalizedHeight() uint64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.finalized == nil {
+		return 0
+	}
+	return idx.finalized.Height
+}
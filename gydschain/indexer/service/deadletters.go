@@ -0,0 +1,91 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/chain"
+)
+
+// ErrNotDeadLettered is returned when replaying a block that is not in the log
+var ErrNotDeadLettered = errors.New("block is not in the dead-letter log")
+
+// DeadLetter is a block the indexer gave up on after repeated failures
+type DeadLetter struct {
+	BlockNumber uint64 `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// DeadLetterLog records blocks skipped by the sync pipeline so the gap
+// they leave is visible and can be replayed
+type DeadLetterLog struct {
+	db *sql.DB
+}
+
+// NewDeadLetterLog creates a new dead-letter log
+func NewDeadLetterLog(db *sql.DB) *DeadLetterLog {
+	return &DeadLetterLog{db: db}
+}
+
+// Record logs a block that failed attempts times with cause
+func (dl *DeadLetterLog) Record(block *chain.Block, attempts int, cause error) error {
+	hash, err := block.Hash()
+	if err != nil {
+		return err
+	}
+	_, err = dl.db.Exec(`
+		INSERT INTO dead_letter_blocks (block_number, block_hash, attempts, error)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (block_number) DO UPDATE SET
+			block_hash = $2,
+			attempts = dead_letter_blocks.attempts + $3,
+			error = $4,
+			created_at = NOW()
+	`, block.Header.Height, hash, attempts, cause.Error())
+	return err
+}
+
+// Remove drops a block from the log once it has been indexed
+func (dl *DeadLetterLog) Remove(number uint64) error {
+	_, err := dl.db.Exec("DELETE FROM dead_letter_blocks WHERE block_number = $1", number)
+	return err
+}
+
+// Contains reports whether a block is in the log
+func (dl *DeadLetterLog) Contains(number uint64) (bool, error) {
+	var n uint64
+	err := dl.db.QueryRow(
+		"SELECT block_number FROM dead_letter_blocks WHERE block_number = $1", number,
+	).Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List returns logged blocks, oldest first
+func (dl *DeadLetterLog) List(limit int) ([]*DeadLetter, error) {
+	rows, err := dl.db.Query(`
+		SELECT block_number, block_hash, attempts, error, created_at
+		FROM dead_letter_blocks
+		ORDER BY block_number
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []*DeadLetter
+	for rows.Next() {
+		letter := &DeadLetter{}
+		if err := rows.Scan(&letter.BlockNumber, &letter.BlockHash, &letter.Attempts, &letter.Error, &letter.CreatedAt); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// EpochIndexer stores per-epoch validator performance summaries
+type EpochIndexer struct {
+	db *sql.DB
+}
+
+// NewEpochIndexer creates a new epoch indexer
+func NewEpochIndexer(db *sql.DB) *EpochIndexer {
+	return &EpochIndexer{db: db}
+}
+
+// IndexEpoch stores a summary reported by the node, replacing any earlier copy
+func (ei *EpochIndexer) IndexEpoch(dbTx *sql.Tx, summary *pos.EpochSummary) error {
+	slashes, err := json.Marshal(summary.Slashes)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbTx.Exec(`
+		INSERT INTO epochs (epoch, start_height, end_height, total_stake, rewards_minted,
+		                    blocks_produced, blocks_missed, slashes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (epoch) DO UPDATE SET
+			start_height = EXCLUDED.start_height,
+			end_height = EXCLUDED.end_height,
+			total_stake = EXCLUDED.total_stake,
+			rewards_minted = EXCLUDED.rewards_minted,
+			blocks_produced = EXCLUDED.blocks_produced,
+			blocks_missed = EXCLUDED.blocks_missed,
+			slashes = EXCLUDED.slashes
+	`,
+		summary.Epoch, summary.StartHeight, summary.EndHeight, summary.TotalStake,
+		summary.RewardsMinted, summary.BlocksProduced, summary.BlocksMissed, slashes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := dbTx.Exec(`DELETE FROM epoch_validators WHERE epoch = $1`, summary.Epoch); err != nil {
+		return err
+	}
+	for _, v := range summary.Validators {
+		_, err := dbTx.Exec(`
+			INSERT INTO epoch_validators (epoch, address, stake, active, blocks_produced,
+			                              blocks_missed, rewards)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, summary.Epoch, v.Address, v.Stake, v.Active, v.BlocksProduced, v.BlocksMissed, v.Rewards)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEpoch retrieves the summary for an epoch
+func (ei *EpochIndexer) GetEpoch(epoch uint64) (*pos.EpochSummary, error) {
+	summary := &pos.EpochSummary{Epoch: epoch}
+	var slashes []byte
+
+	err := ei.db.QueryRow(`
+		SELECT start_height, end_height, total_stake, rewards_minted,
+		       blocks_produced, blocks_missed, slashes
+		FROM epochs WHERE epoch = $1
+	`, epoch).Scan(
+		&summary.StartHeight, &summary.EndHeight, &summary.TotalStake, &summary.RewardsMinted,
+		&summary.BlocksProduced, &summary.BlocksMissed, &slashes,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(slashes, &summary.Slashes); err != nil {
+		return nil, err
+	}
+
+	rows, err := ei.db.Query(`
+		SELECT address, stake, active, blocks_produced, blocks_missed, rewards
+		FROM epoch_validators
+		WHERE epoch = $1
+		ORDER BY CAST(stake AS NUMERIC) DESC, address ASC
+	`, epoch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary.Validators = make([]*pos.EpochValidator, 0)
+	for rows.Next() {
+		v := &pos.EpochValidator{}
+		if err := rows.Scan(&v.Address, &v.Stake, &v.Active, &v.BlocksProduced, &v.BlocksMissed, &v.Rewards); err != nil {
+			return nil, err
+		}
+		summary.Validators = append(summary.Validators, v)
+	}
+	return summary, nil
+}
+
+// GetEpochs lists recent epoch totals, newest first, without per-validator rows
+func (ei *EpochIndexer) GetEpochs(limit, offset int) ([]*pos.EpochSummary, error) {
+	rows, err := ei.db.Query(`
+		SELECT epoch, start_height, end_height, total_stake, rewards_minted,
+		       blocks_produced, blocks_missed, slashes
+		FROM epochs
+		ORDER BY epoch DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*pos.EpochSummary
+	for rows.Next() {
+		summary := &pos.EpochSummary{}
+		var slashes []byte
+		if err := rows.Scan(
+			&summary.Epoch, &summary.StartHeight, &summary.EndHeight, &summary.TotalStake,
+			&summary.RewardsMinted, &summary.BlocksProduced, &summary.BlocksMissed, &slashes,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(slashes, &summary.Slashes); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// GetValidatorEpochs returns a validator's per-epoch performance, newest first
+func (ei *EpochIndexer) GetValidatorEpochs(address string, limit int) ([]*ValidatorEpoch, error) {
+	rows, err := ei.db.Query(`
+		SELECT epoch, stake, active, blocks_produced, blocks_missed, rewards
+		FROM epoch_validators
+		WHERE address = $1
+		ORDER BY epoch DESC
+		LIMIT $2
+	`, address, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var epochs []*ValidatorEpoch
+	for rows.Next() {
+		e := &ValidatorEpoch{}
+		if err := rows.Scan(&e.Epoch, &e.Stake, &e.Active, &e.BlocksProduced, &e.BlocksMissed, &e.Rewards); err != nil {
+			return nil, err
+		}
+		epochs = append(epochs, e)
+	}
+	return epochs, nil
+}
+
+// ValidatorEpoch is one validator's row of an epoch summary
+type ValidatorEpoch struct {
+	Epoch          uint64    `json:"epoch"`
+	Stake          *util.Big `json:"stake"`
+	Active         bool      `json:"active"`
+	BlocksProduced uint64    `json:"blocks_produced"`
+	BlocksMissed   uint64    `json:"blocks_missed"`
+	Rewards        *util.Big `json:"rewards"`
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Event types beyond the transaction types receipt logs carry as-is
+const (
+	EventTransfer         = tx.TxTypeTransfer
+	EventStake            = tx.TxTypeStake
+	EventUnstake          = tx.TxTypeUnstake
+	EventMint             = tx.TxTypeMint
+	EventBurn             = tx.TxTypeBurn
+	EventRewardWithdrawal = "reward_withdrawal"
+	EventFeeBurn          = "fee_burn"
+	EventReward           = "reward"
+	EventSlash            = "slash"
+)
+
+// Event is a typed account event. Address is the account the event acts
+// on; Counterparty, where there is one, is the other side: the recipient
+// of a transfer, the validator of a stake or the issuer of a mint.
+type Event struct {
+	ID           uint64          `json:"id"`
+	Type         string          `json:"type"`
+	BlockNumber  uint64          `json:"block_number"`
+	Timestamp    int64           `json:"timestamp"`
+	TxHash       string          `json:"tx_hash,omitempty"` // empty for system events
+	LogIndex     *int            `json:"log_index,omitempty"`
+	Address      string          `json:"address"`
+	Counterparty string          `json:"counterparty,omitempty"`
+	Asset        string          `json:"asset,omitempty"`
+	Amount       string          `json:"amount"`
+	Data         json.RawMessage `json:"data,omitempty"`
+}
+
+// EventIndexer indexes receipt logs and system events so stakes, reward
+// payouts, slashes, mints and burns show up in account history
+type EventIndexer struct {
+	db *sql.DB
+}
+
+// NewEventIndexer creates a new event indexer
+func NewEventIndexer(db *sql.DB) *EventIndexer {
+	return &EventIndexer{db: db}
+}
+
+// IndexBlock stores the events of a block's receipt logs and its fee
+// burns. It runs after the block's transactions and burns are stored and
+// is safe to repeat for the same block.
+func (ei *EventIndexer) IndexBlock(dbTx *sql.Tx, block *chain.Block, receipts []*rpc.TransactionReceiptResponse) error {
+	height := block.Header.Height
+	if _, err := dbTx.Exec(
+		"DELETE FROM events WHERE block_number = $1 AND tx_hash IS NOT NULL", height,
+	); err != nil {
+		return err
+	}
+
+	for _, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
+		for i := range receipt.Logs {
+			e := decodeLog(&receipt.Logs[i])
+			if e == nil {
+				continue
+			}
+			e.TxHash = receipt.TransactionHash
+			if err := ei.insert(dbTx, height, block.Header.Timestamp, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Fee burns are charged to the sender and never appear in a log
+	_, err := dbTx.Exec(`
+		INSERT INTO events (block_number, block_timestamp, tx_hash, event_type, address, asset, amount)
+		SELECT b.block_number, b.block_timestamp, b.tx_hash, $2, t.from_address, b.asset, b.amount
+		FROM burns b
+		JOIN transactions t ON t.hash = b.tx_hash
+		WHERE b.block_number = $1 AND b.source = 'fee'
+	`, height, EventFeeBurn)
+	return err
+}
+
+// IndexEpoch stores an epoch's system events: the rewards each validator
+// earned, at the epoch's last block, and its slashes. It is safe to repeat
+// for the same epoch.
+func (ei *EventIndexer) IndexEpoch(dbTx *sql.Tx, summary *pos.EpochSummary) error {
+	_, err := dbTx.Exec(`
+		DELETE FROM events
+		WHERE tx_hash IS NULL AND block_number BETWEEN $1 AND $2 AND event_type IN ($3, $4)
+	`, summary.StartHeight, summary.EndHeight, EventReward, EventSlash)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range summary.Validators {
+		if v.Rewards.Int().Sign() <= 0 {
+			continue
+		}
+		e := &Event{Type: EventReward, Address: v.Address, Asset: "GYDS", Amount: v.Rewards.Int().String()}
+		e.Data, _ = json.Marshal(map[string]uint64{"epoch": summary.Epoch})
+		if err := ei.insertAt(dbTx, summary.EndHeight, e); err != nil {
+			return err
+		}
+	}
+	for _, s := range summary.Slashes {
+		e := &Event{
+			Type:    EventSlash,
+			Address: s.ValidatorAddress,
+			Asset:   "GYDS",
+			Amount:  util.CopyBig(s.Amount).String(),
+		}
+		e.Data, _ = json.Marshal(map[string]string{"reason": string(s.Reason), "escrow_id": s.EscrowID})
+		if err := ei.insert(dbTx, s.Height, s.Timestamp, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeLog turns a receipt log into a typed event. Logs carry the
+// transaction type, sender and recipient as topics and the amount as a
+// decimal string; logs of any other shape are skipped.
+func decodeLog(l *rpc.LogResponse) *Event {
+	if len(l.Topics) < 2 || l.Topics[1] == "" {
+		return nil
+	}
+	logIndex := int(l.LogIndex)
+	e := &Event{
+		Type:     l.Topics[0],
+		LogIndex: &logIndex,
+		Address:  l.Topics[1],
+		Asset:    l.Address,
+		Amount:   "0",
+	}
+	if len(l.Topics) > 2 {
+		e.Counterparty = l.Topics[2]
+	}
+	if raw, err := hex.DecodeString(l.Data); err == nil {
+		if amount, ok := new(big.Int).SetString(string(raw), 10); ok {
+			e.Amount = amount.String()
+		}
+	}
+
+	switch e.Type {
+	case tx.TxTypeMint:
+		// A mint credits the recipient; the sender is the issuer
+		if e.Counterparty != "" {
+			e.Address, e.Counterparty = e.Counterparty, e.Address
+		}
+	case tx.TxTypeWithdrawRewards:
+		e.Type = EventRewardWithdrawal
+		e.Counterparty = ""
+	case tx.TxTypeBurn:
+		e.Counterparty = ""
+	}
+	e.Data, _ = json.Marshal(map[string]interface{}{"topics": l.Topics, "data": l.Data})
+	return e
+}
+
+// insertAt stores an event at a block, taking its timestamp from the
+// indexed block
+func (ei *EventIndexer) insertAt(dbTx *sql.Tx, number uint64, e *Event) error {
+	var timestamp int64
+	err := dbTx.QueryRow("SELECT timestamp FROM blocks WHERE number = $1", number).Scan(&timestamp)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return ei.insert(dbTx, number, timestamp, e)
+}
+
+func (ei *EventIndexer) insert(dbTx *sql.Tx, number uint64, timestamp int64, e *Event) error {
+	var data interface{}
+	if len(e.Data) > 0 {
+		data = string(e.Data)
+	}
+	_, err := dbTx.Exec(`
+		INSERT INTO events (block_number, block_timestamp, tx_hash, log_index, event_type,
+		                    address, counterparty, asset, amount, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		number,
+		timestamp,
+		sql.NullString{String: e.TxHash, Valid: e.TxHash != ""},
+		e.LogIndex,
+		e.Type,
+		e.Address,
+		sql.NullString{String: e.Counterparty, Valid: e.Counterparty != ""},
+		sql.NullString{String: e.Asset, Valid: e.Asset != ""},
+		e.Amount,
+		data,
+	)
+	return err
+}
+
+// Rewind deletes the events from fromBlock on
+func (ei *EventIndexer) Rewind(dbTx *sql.Tx, fromBlock uint64) error {
+	_, err := dbTx.Exec("DELETE FROM events WHERE block_number >= $1", fromBlock)
+	return err
+}
+
+// GetAccountEvents returns the events an address took part in, newest
+// first, optionally only those of eventType
+func (ei *EventIndexer) GetAccountEvents(address, eventType string, limit, offset int) ([]*Event, error) {
+	rows, err := ei.db.Query(`
+		SELECT id, event_type, block_number, block_timestamp, COALESCE(tx_hash, ''), log_index,
+		       address, COALESCE(counterparty, ''), COALESCE(asset, ''), amount, data
+		FROM events
+		WHERE (address = $1 OR counterparty = $1) AND ($2 = '' OR event_type = $2)
+		ORDER BY block_number DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, address, eventType, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var (
+			e        = &Event{}
+			logIndex sql.NullInt64
+			data     []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.BlockNumber, &e.Timestamp, &e.TxHash, &logIndex,
+			&e.Address, &e.Counterparty, &e.Asset, &e.Amount, &data); err != nil {
+			return nil, err
+		}
+		if logIndex.Valid {
+			i := int(logIndex.Int64)
+			e.LogIndex = &i
+		}
+		if len(data) > 0 {
+			e.Data = json.RawMessage(data)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// ErrNoHealthyNode is returned when no endpoint has reached the indexed height
+var ErrNoHealthyNode = errors.New("no healthy node endpoint at the indexed height")
+
+// NodeEndpoint is one node RPC endpoint and the result of its last check
+type NodeEndpoint struct {
+	URL       string    `json:"url"`
+	Healthy   bool      `json:"healthy"`
+	Height    uint64    `json:"height"`
+	LastCheck time.Time `json:"last_check"`
+	LastError string    `json:"last_error,omitempty"`
+	Failures  uint64    `json:"failures"`
+
+	client *rpc.NodeClient
+}
+
+// FailoverStats reports the node endpoints and how often the indexer
+// switched between them
+type FailoverStats struct {
+	Active      string         `json:"active"`
+	Switchovers uint64         `json:"switchovers"`
+	LastSwitch  time.Time      `json:"last_switch,omitempty"`
+	LastReason  string         `json:"last_reason,omitempty"`
+	Endpoints   []NodeEndpoint `json:"endpoints"`
+}
+
+// NodePool sends the indexer's RPC calls to one active node endpoint and
+// fails over to another when it errors, falls behind or stops responding.
+// An endpoint is only switched to once it has reached the last indexed
+// block, so the indexer never reads from a node that is behind it.
+type NodePool struct {
+	mu          sync.RWMutex
+	endpoints   []*NodeEndpoint // in order of preference
+	active      int
+	floor       func() uint64 // last indexed block
+	maxLag      uint64        // blocks the active endpoint may trail the best one
+	switchovers uint64
+	lastSwitch  time.Time
+	lastReason  string
+}
+
+// NewNodePool creates a pool over clients, using the first until it fails
+func NewNodePool(clients ...*rpc.NodeClient) *NodePool {
+	p := &NodePool{
+		floor: func() uint64 { return 0 },
+	}
+	for _, client := range clients {
+		p.endpoints = append(p.endpoints, &NodeEndpoint{
+			URL:     client.URL(),
+			Healthy: true,
+			client:  client,
+		})
+	}
+	return p
+}
+
+// current returns the active endpoint's client and index
+func (p *NodePool) current() (*rpc.NodeClient, int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.endpoints) == 0 {
+		return nil, -1
+	}
+	return p.endpoints[p.active].client, p.active
+}
+
+// call runs fn against the active endpoint. A transport failure fails over
+// and retries once on the new endpoint; errors the node itself returned are
+// passed through unchanged.
+func (p *NodePool) call(fn func(*rpc.NodeClient) error) error {
+	client, index := p.current()
+	if client == nil {
+		return ErrNoHealthyNode
+	}
+
+	err := fn(client)
+	var rpcErr *rpc.RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		return err
+	}
+
+	p.record(index, 0, err)
+	if !p.failover(index, fmt.Sprintf("request failed: %v", err)) {
+		return err
+	}
+	client, _ = p.current()
+	return fn(client)
+}
+
+// record stores the result of a check or request against an endpoint
+func (p *NodePool) record(index int, height uint64, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	endpoint := p.endpoints[index]
+	endpoint.LastCheck = time.Now()
+	if err != nil {
+		endpoint.Healthy = false
+		endpoint.LastError = err.Error()
+		endpoint.Failures++
+		return
+	}
+	endpoint.Healthy = true
+	endpoint.Height = height
+	endpoint.LastError = ""
+}
+
+// probe checks an endpoint's height and records the result
+func (p *NodePool) probe(index int) (uint64, error) {
+	height, err := p.endpoints[index].client.GetBlockHeight()
+	p.record(index, height, err)
+	return height, err
+}
+
+// failover switches away from endpoint from to the first other endpoint, in
+// order of preference, that answers and has reached the last indexed block.
+// It reports whether the active endpoint changed, including when another
+// caller already moved it.
+func (p *NodePool) failover(from int, reason string) bool {
+	floor := p.floor()
+	for i := range p.endpoints {
+		if i == from {
+			continue
+		}
+		height, err := p.probe(i)
+		if err != nil || height < floor {
+			continue
+		}
+		return p.switchTo(from, i, reason)
+	}
+	logger.Error("node failover failed", "from", p.endpoints[from].URL, "err", ErrNoHealthyNode)
+	return false
+}
+
+// switchTo makes endpoint to active unless another caller already switched
+// away from endpoint from
+func (p *NodePool) switchTo(from, to int, reason string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active != from {
+		return true
+	}
+	p.active = to
+	p.switchovers++
+	p.lastSwitch = time.Now()
+	p.lastReason = reason
+	logger.Warn("node failover", "from", p.endpoints[from].URL, "to", p.endpoints[to].URL, "reason", reason)
+	return true
+}
+
+// CheckHealth probes every endpoint and fails over if the active one is
+// down, below the indexed height or more than maxLag blocks behind the best
+func (p *NodePool) CheckHealth() {
+	if len(p.endpoints) == 0 {
+		return
+	}
+
+	var best uint64
+	for i := range p.endpoints {
+		if height, err := p.probe(i); err == nil && height > best {
+			best = height
+		}
+	}
+
+	_, active := p.current()
+	p.mu.RLock()
+	endpoint := *p.endpoints[active]
+	maxLag := p.maxLag
+	p.mu.RUnlock()
+
+	switch {
+	case !endpoint.Healthy:
+		p.failover(active, fmt.Sprintf("health check failed: %s", endpoint.LastError))
+	case endpoint.Height < p.floor():
+		p.failover(active, fmt.Sprintf("height %d below indexed block %d", endpoint.Height, p.floor()))
+	case maxLag > 0 && endpoint.Height+maxLag < best:
+		p.failover(active, fmt.Sprintf("height %d lags best endpoint at %d", endpoint.Height, best))
+	}
+}
+
+// Stats returns a snapshot of the endpoints and switchovers
+func (p *NodePool) Stats() FailoverStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := FailoverStats{
+		Switchovers: p.switchovers,
+		LastSwitch:  p.lastSwitch,
+		LastReason:  p.lastReason,
+		Endpoints:   make([]NodeEndpoint, len(p.endpoints)),
+	}
+	for i, endpoint := range p.endpoints {
+		stats.Endpoints[i] = *endpoint
+	}
+	if len(p.endpoints) > 0 {
+		stats.Active = p.endpoints[p.active].URL
+	}
+	return stats
+}
+
+// monitor runs health checks every interval until stopped
+func (p *NodePool) monitor(ctx context.Context, stop <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.CheckHealth()
+		}
+	}
+}
+
+// GetBlockHeight returns the active node's latest block height
+func (p *NodePool) GetBlockHeight() (uint64, error) {
+	var height uint64
+	err := p.call(func(c *rpc.NodeClient) error {
+		var err error
+		height, err = c.GetBlockHeight()
+		return err
+	})
+	return height, err
+}
+
+// GetBlockByNumber returns the block at number from the active node
+func (p *NodePool) GetBlockByNumber(number uint64) (*rpc.BlockResponse, error) {
+	var block *rpc.BlockResponse
+	err := p.call(func(c *rpc.NodeClient) error {
+		var err error
+		block, err = c.GetBlockByNumber(number)
+		return err
+	})
+	return block, err
+}
+
+// GetTransactionReceipt returns a receipt from the active node
+func (p *NodePool) GetTransactionReceipt(hash string) (*rpc.TransactionReceiptResponse, error) {
+	var receipt *rpc.TransactionReceiptResponse
+	err := p.call(func(c *rpc.NodeClient) error {
+		var err error
+		receipt, err = c.GetTransactionReceipt(hash)
+		return err
+	})
+	return receipt, err
+}
+
+// GetEpoch returns an epoch summary from the active node
+func (p *NodePool) GetEpoch(epoch uint64) (*pos.EpochSummary, error) {
+	var summary *pos.EpochSummary
+	err := p.call(func(c *rpc.NodeClient) error {
+		var err error
+		summary, err = c.GetEpoch(epoch)
+		return err
+	})
+	return summary, err
+}
+
+// GetLatestCheckpoint returns the active node's latest signed checkpoint
+func (p *NodePool) GetLatestCheckpoint() (*chain.Checkpoint, error) {
+	var cp *chain.Checkpoint
+	err := p.call(func(c *rpc.NodeClient) error {
+		var err error
+		cp, err = c.GetLatestCheckpoint()
+		return err
+	})
+	return cp, err
+}
+
+// GetPendingTransactions returns the active node's mempool
+func (p *NodePool) GetPendingTransactions() ([]*rpc.TransactionResponse, error) {
+	var txs []*rpc.TransactionResponse
+	err := p.call(func(c *rpc.NodeClient) error {
+		var err error
+		txs, err = c.GetPendingTransactions()
+		return err
+	})
+	return txs, err
+}
+
+// SubscribePendingTransactions streams the active node's new mempool
+// transactions to fn until ctx ends or the stream fails. A failover does
+// not move an open stream; the caller resubscribes once it ends.
+func (p *NodePool) SubscribePendingTransactions(ctx context.Context, fn func(*rpc.TransactionResponse)) error {
+	client, _ := p.current()
+	if client == nil {
+		return ErrNoHealthyNode
+	}
+	return client.SubscribePendingTransactions(ctx, fn)
+}
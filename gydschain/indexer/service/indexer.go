@@ -3,18 +3,26 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/logging"
 	"github.com/gydschain/gydschain/internal/rpc"
 )
 
+// logger is the indexer module logger
+var logger = logging.Module("indexer")
+
+// ErrReorgTooDeep is returned when a fork reaches further back than ReorgDepth
+var ErrReorgTooDeep = errors.New("reorg deeper than the configured reorg depth")
+
 // Indexer processes blocks and indexes data
 type Indexer struct {
 	db        *sql.DB
-	rpcClient *rpc.Client
+	nodes     *NodePool
 	
 	// State
 	lastBlock   uint64
@@ -26,6 +34,23 @@ type Indexer struct {
 	assets      *AssetIndexer
 	txs         *TransactionIndexer
 	validators  *ValidatorIndexer
+	burns       *BurnIndexer
+	nfts        *NFTIndexer
+	epochs      *EpochIndexer
+	events      *EventIndexer
+	deadLetters *DeadLetterLog
+	archive     *TransactionArchiver
+	aggregates  *StatsAggregator
+	mempool     *MempoolIndexer
+	
+	// Finality
+	finalized   *chain.Checkpoint // latest verified checkpoint; never rolled back past
+	trusted     map[string][]byte
+	
+	// Pipeline
+	fetched     uint64 // highest block handed to the processor
+	stats       PipelineStats
+	backfill    BackfillProgress
 	
 	// Channels
 	blocks      chan *chain.Block
@@ -37,65 +62,166 @@ type Indexer struct {
 
 // IndexerConfig contains indexer configuration
 type IndexerConfig struct {
-	BatchSize       int           `json:"batch_size"`
-	PollInterval    time.Duration `json:"poll_interval"`
-	ConfirmBlocks   int           `json:"confirm_blocks"`
-	StartBlock      uint64        `json:"start_block"`
-	ReorgDepth      int           `json:"reorg_depth"`
+	BatchSize         int               `json:"batch_size"`
+	PollInterval      time.Duration     `json:"poll_interval"`
+	ConfirmBlocks     int               `json:"confirm_blocks"`
+	StartBlock        uint64            `json:"start_block"`
+	ReorgDepth        int               `json:"reorg_depth"`
+	FeeBurnRate       uint64            `json:"fee_burn_rate"` // basis points of each fee burned
+	EpochLength       uint64            `json:"epoch_length"`  // must match the node's epoch length
+	QueueSize         int               `json:"queue_size"`    // fetched blocks waiting to be processed
+	MaxRetries        int               `json:"max_retries"`   // attempts before a block is dead-lettered
+	RetryBackoff      time.Duration     `json:"retry_backoff"` // first retry delay, doubled per attempt
+	MaxBackoff        time.Duration     `json:"max_backoff"`
+	HealthInterval    time.Duration     `json:"health_interval"`    // between node endpoint health checks
+	MaxNodeLag        uint64            `json:"max_node_lag"`       // blocks the active node may trail the best before failover
+	HotMonths         int               `json:"hot_months"`         // months of transactions kept uncompressed
+	ArchiveInterval   time.Duration     `json:"archive_interval"`   // between cold partition compression runs
+	BackfillWorkers   int               `json:"backfill_workers"`   // parallel fetchers during backfill, 0 disables it
+	BackfillThreshold uint64            `json:"backfill_threshold"` // blocks behind the node that trigger a backfill
+	StatsInterval     time.Duration     `json:"stats_interval"`     // between chain stats refreshes, 0 disables them
+	IndexMempool      bool              `json:"index_mempool"`      // follow the node's pending transactions
+	MempoolTTL        time.Duration     `json:"mempool_ttl"`        // how long an unconfirmed transaction is kept
+	CheckpointPoll    time.Duration     `json:"checkpoint_poll"`    // between signed checkpoint fetches, 0 disables them
+	TrustedValidators map[string]string `json:"trusted_validators"` // address -> hex public key checkpoints must be signed by
+}
+
+// PipelineStats reports the state of the fetch/process pipeline
+type PipelineStats struct {
+	Queued       int    `json:"queued"`
+	QueueSize    int    `json:"queue_size"`
+	Fetched      uint64 `json:"fetched"`
+	Indexed      uint64 `json:"indexed"`
+	Stalls       uint64 `json:"stalls"`  // polls cut short by a full queue
+	Retries      uint64 `json:"retries"`
+	DeadLettered uint64 `json:"dead_lettered"`
 }
 
 // DefaultIndexerConfig returns default configuration
 func DefaultIndexerConfig() IndexerConfig {
 	return IndexerConfig{
-		BatchSize:     100,
-		PollInterval:  time.Second,
-		ConfirmBlocks: 6,
-		StartBlock:    0,
-		ReorgDepth:    100,
+		BatchSize:         100,
+		PollInterval:      time.Second,
+		ConfirmBlocks:     6,
+		StartBlock:        0,
+		ReorgDepth:        100,
+		FeeBurnRate:       DefaultFeeBurnRate,
+		EpochLength:       100,
+		QueueSize:         100,
+		MaxRetries:        5,
+		RetryBackoff:      time.Second,
+		MaxBackoff:        30 * time.Second,
+		HealthInterval:    10 * time.Second,
+		MaxNodeLag:        10,
+		HotMonths:         3,
+		ArchiveInterval:   6 * time.Hour,
+		BackfillWorkers:   8,
+		BackfillThreshold: 1000,
+		StatsInterval:     DefaultStatsInterval,
+		IndexMempool:      true,
+		MempoolTTL:        DefaultMempoolTTL,
+		CheckpointPoll:    DefaultCheckpointPoll,
 	}
 }
 
-// NewIndexer creates a new indexer
-func NewIndexer(db *sql.DB, rpcClient *rpc.Client, config IndexerConfig) *Indexer {
+// NewIndexer creates a new indexer reading from the endpoints in nodes
+func NewIndexer(db *sql.DB, nodes *NodePool, config IndexerConfig) *Indexer {
 	idx := &Indexer{
 		db:        db,
-		rpcClient: rpcClient,
+		nodes:     nodes,
 		config:    config,
-		blocks:    make(chan *chain.Block, 100),
+		blocks:    make(chan *chain.Block, config.QueueSize),
 		stop:      make(chan struct{}),
 	}
 	
+	// Fail over only to nodes that have reached what is already indexed
+	nodes.floor = idx.GetLastIndexedBlock
+	nodes.maxLag = config.MaxNodeLag
+	
 	// Initialize sub-services
 	idx.accounts = NewAccountIndexer(db)
 	idx.assets = NewAssetIndexer(db)
 	idx.txs = NewTransactionIndexer(db)
 	idx.validators = NewValidatorIndexer(db)
+	idx.burns = NewBurnIndexer(db, config.FeeBurnRate)
+	idx.nfts = NewNFTIndexer(db)
+	idx.epochs = NewEpochIndexer(db)
+	idx.events = NewEventIndexer(db)
+	idx.deadLetters = NewDeadLetterLog(db)
+	idx.archive = NewTransactionArchiver(db, config.HotMonths)
+	idx.aggregates = NewStatsAggregator(db)
+	idx.mempool = NewMempoolIndexer(db, config.MempoolTTL)
 	
 	return idx
 }
 
 // Start starts the indexer
 func (idx *Indexer) Start(ctx context.Context) error {
+	trusted, err := parseTrustedValidators(idx.config.TrustedValidators)
+	if err != nil {
+		return err
+	}
+	
 	idx.mu.Lock()
 	if idx.isRunning {
 		idx.mu.Unlock()
 		return fmt.Errorf("indexer already running")
 	}
 	idx.isRunning = true
+	idx.trusted = trusted
 	idx.mu.Unlock()
 	
 	// Load last indexed block
 	if err := idx.loadState(); err != nil {
 		return fmt.Errorf("failed to load state: %w", err)
 	}
+	idx.fetched = idx.lastBlock
 	
-	fmt.Printf("Starting indexer from block %d\n", idx.lastBlock)
+	// Move a pre-partitioning transactions table to the partitioned layout
+	if err := idx.archive.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate transactions: %w", err)
+	}
 	
-	// Start block processor
-	go idx.processBlocks(ctx)
+	logger.Info("starting indexer", "from", idx.lastBlock)
 	
-	// Start block fetcher
-	go idx.fetchBlocks(ctx)
+	// Catch up far-behind history in parallel, then follow the chain
+	go func() {
+		if err := idx.Backfill(ctx); err != nil {
+			logger.Error("backfill stopped", "err", err)
+		}
+		idx.rewind(idx.GetLastIndexedBlock())
+		
+		// Start block processor
+		go idx.processBlocks(ctx)
+		
+		// Start block fetcher
+		idx.fetchBlocks(ctx)
+	}()
+	
+	// Watch node endpoints and fail over between them
+	if idx.config.HealthInterval > 0 {
+		go idx.nodes.monitor(ctx, idx.stop, idx.config.HealthInterval)
+	}
+	
+	// Compress transaction partitions that have gone cold
+	if idx.config.ArchiveInterval > 0 {
+		go idx.archiveTransactions(ctx)
+	}
+	
+	// Keep the daily and hourly chain statistics current
+	if idx.config.StatsInterval > 0 {
+		go idx.aggregates.Run(ctx, idx.stop, idx.config.StatsInterval)
+	}
+	
+	// Follow signed checkpoints so reorgs stop at finalized blocks
+	if idx.config.CheckpointPoll > 0 {
+		go idx.followCheckpoints(ctx)
+	}
+	
+	// Mirror the node's pending transactions until they confirm
+	if idx.config.IndexMempool {
+		go idx.mempool.Run(ctx, idx.stop, idx.nodes)
+	}
 	
 	return nil
 }
@@ -141,6 +267,29 @@ func (idx *Indexer) saveState() error {
 	return err
 }
 
+// archiveTransactions periodically compresses cold transaction partitions
+func (idx *Indexer) archiveTransactions(ctx context.Context) {
+	ticker := time.NewTicker(idx.config.ArchiveInterval)
+	defer ticker.Stop()
+	
+	for {
+		n, err := idx.archive.CompressColdPartitions(time.Now())
+		if err != nil {
+			logger.Error("compressing transaction partitions failed", "err", err)
+		} else if n > 0 {
+			logger.Info("compressed transaction partitions", "count", n)
+		}
+		
+		select {
+		case <-ctx.Done():
+			return
+		case <-idx.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // fetchBlocks fetches blocks from the node
 func (idx *Indexer) fetchBlocks(ctx context.Context) {
 	ticker := time.NewTicker(idx.config.PollInterval)
@@ -158,43 +307,56 @@ func (idx *Indexer) fetchBlocks(ctx context.Context) {
 	}
 }
 
-// fetchNewBlocks fetches new blocks
+// fetchNewBlocks queues blocks past the fetch cursor. A full queue ends the
+// poll instead of blocking it; the next poll resumes from the same cursor
 func (idx *Indexer) fetchNewBlocks() {
 	// Get current chain height
-	height, err := idx.rpcClient.GetBlockHeight()
+	height, err := idx.nodes.GetBlockHeight()
 	if err != nil {
-		fmt.Printf("Error getting block height: %v\n", err)
+		logger.Error("getting block height failed", "err", err)
 		return
 	}
 	
 	// Calculate safe height (accounting for reorgs)
+	if height < uint64(idx.config.ConfirmBlocks) {
+		return
+	}
 	safeHeight := height - uint64(idx.config.ConfirmBlocks)
 	
 	idx.mu.RLock()
-	lastBlock := idx.lastBlock
+	next := idx.fetched + 1
 	idx.mu.RUnlock()
 	
-	if safeHeight <= lastBlock {
-		return
-	}
-	
-	// Fetch blocks in batches
-	for blockNum := lastBlock + 1; blockNum <= safeHeight; blockNum++ {
-		block, err := idx.rpcClient.GetBlockByNumber(blockNum)
+	for blockNum := next; blockNum <= safeHeight; blockNum++ {
+		if len(idx.blocks) == cap(idx.blocks) {
+			idx.mu.Lock()
+			idx.stats.Stalls++
+			idx.mu.Unlock()
+			return
+		}
+		
+		block, err := idx.nodes.GetBlockByNumber(blockNum)
 		if err != nil {
-			fmt.Printf("Error fetching block %d: %v\n", blockNum, err)
+			logger.Error("fetching block failed", "block", blockNum, "err", err)
 			return
 		}
 		
-		select {
-		case idx.blocks <- block:
-		case <-idx.stop:
+		// The processor or a reorg may have moved the cursor meanwhile
+		idx.mu.Lock()
+		if idx.fetched != blockNum-1 {
+			idx.mu.Unlock()
 			return
 		}
+		idx.fetched = blockNum
+		idx.stats.Fetched++
+		idx.mu.Unlock()
+		
+		// Only this goroutine sends, so the capacity check above holds
+		idx.blocks <- block
 	}
 }
 
-// processBlocks processes blocks from the channel
+// processBlocks processes blocks from the channel strictly in sequence
 func (idx *Indexer) processBlocks(ctx context.Context) {
 	for {
 		select {
@@ -203,16 +365,201 @@ func (idx *Indexer) processBlocks(ctx context.Context) {
 		case <-idx.stop:
 			return
 		case block := <-idx.blocks:
-			if err := idx.processBlock(block); err != nil {
-				fmt.Printf("Error processing block %d: %v\n", block.Number, err)
+			idx.mu.RLock()
+			expected := idx.lastBlock + 1
+			idx.mu.RUnlock()
+			
+			switch {
+			case block.Header.Height < expected:
+				// Left over from before a reorg or a refetch
+				continue
+			case block.Header.Height > expected:
+				// Out of sequence: drop the queue and refetch from the gap
+				idx.rewind(expected - 1)
+				continue
+			}
+			
+			// A block that does not extend the indexed tip means the node
+			// switched branches; roll back to the fork and refetch
+			reorged, err := idx.detectReorg(block)
+			if err != nil {
+				logger.Error("reorg check failed", "block", block.Header.Height, "err", err)
+				idx.rewind(expected - 1)
 				continue
 			}
+			if reorged {
+				continue
+			}
+			
+			if !idx.processWithRetry(ctx, block) {
+				return
+			}
+		}
+	}
+}
+
+// processWithRetry indexes a block, backing off between failed attempts.
+// A block that fails MaxRetries times is dead-lettered and skipped so the
+// blocks behind it are not held up. It returns false if stopped meanwhile
+func (idx *Indexer) processWithRetry(ctx context.Context, block *chain.Block) bool {
+	backoff := idx.config.RetryBackoff
+	attempts := 0
+	for {
+		attempts++
+		err := idx.processBlock(block)
+		if err == nil {
+			idx.mu.Lock()
+			idx.stats.Indexed++
+			idx.mu.Unlock()
+			idx.advance(block.Header.Height)
+			return true
+		}
+		logger.Warn("processing block failed", "block", block.Header.Height,
+			"attempt", attempts, "max_retries", idx.config.MaxRetries, "err", err)
+		
+		if attempts >= idx.config.MaxRetries {
+			idx.deadLetter(block, attempts, err)
+			return true
+		}
+		
+		idx.mu.Lock()
+		idx.stats.Retries++
+		idx.mu.Unlock()
+		
+		select {
+		case <-ctx.Done():
+			return false
+		case <-idx.stop:
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > idx.config.MaxBackoff {
+			backoff = idx.config.MaxBackoff
 		}
 	}
 }
 
+// deadLetter records a block that kept failing and moves past it
+func (idx *Indexer) deadLetter(block *chain.Block, attempts int, cause error) {
+	if err := idx.deadLetters.Record(block, attempts, cause); err != nil {
+		logger.Error("dead-lettering block failed", "block", block.Header.Height, "err", err)
+	}
+	logger.Warn("dead-lettered block", "block", block.Header.Height, "attempts", attempts)
+	
+	idx.mu.Lock()
+	idx.stats.DeadLettered++
+	idx.mu.Unlock()
+	idx.advance(block.Header.Height)
+	idx.saveState()
+}
+
+// advance moves the pipeline past a handled block
+func (idx *Indexer) advance(number uint64) {
+	idx.mu.Lock()
+	idx.lastBlock = number
+	idx.mu.Unlock()
+	
+	// Save state periodically
+	if number%100 == 0 {
+		idx.saveState()
+	}
+}
+
+// rewind discards queued blocks and moves the fetch cursor back to
+// lastBlock so the fetcher refills the queue from there
+func (idx *Indexer) rewind(lastBlock uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	
+	for {
+		select {
+		case <-idx.blocks:
+			continue
+		default:
+		}
+		break
+	}
+	idx.fetched = lastBlock
+}
+
+// ReplayDeadLetter refetches and indexes a dead-lettered block, removing
+// it from the log on success
+func (idx *Indexer) ReplayDeadLetter(number uint64) error {
+	logged, err := idx.deadLetters.Contains(number)
+	if err != nil {
+		return err
+	}
+	if !logged {
+		return ErrNotDeadLettered
+	}
+	
+	block, err := idx.nodes.GetBlockByNumber(number)
+	if err != nil {
+		return err
+	}
+	
+	if err := idx.processBlock(block); err != nil {
+		return err
+	}
+	return idx.deadLetters.Remove(number)
+}
+
+// GetDeadLetters returns blocks the pipeline gave up on
+func (idx *Indexer) GetDeadLetters(limit int) ([]*DeadLetter, error) {
+	return idx.deadLetters.List(limit)
+}
+
+// GetFailoverStats returns the node endpoints and switchovers
+func (idx *Indexer) GetFailoverStats() FailoverStats {
+	return idx.nodes.Stats()
+}
+
+// GetPipelineStats returns a snapshot of the sync pipeline
+func (idx *Indexer) GetPipelineStats() PipelineStats {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	
+	stats := idx.stats
+	stats.Queued = len(idx.blocks)
+	stats.QueueSize = cap(idx.blocks)
+	return stats
+}
+
 // processBlock processes a single block
 func (idx *Indexer) processBlock(block *chain.Block) error {
+	receipts, err := idx.fetchReceipts(block)
+	if err != nil {
+		return err
+	}
+	return idx.writeBlock(block, receipts)
+}
+
+// fetchReceipts fetches the receipts of a block's transactions, in order
+func (idx *Indexer) fetchReceipts(block *chain.Block) ([]*rpc.TransactionReceiptResponse, error) {
+	receipts := make([]*rpc.TransactionReceiptResponse, len(block.Transactions))
+	for i, txn := range block.Transactions {
+		hash, err := txn.HashHex()
+		if err != nil {
+			return nil, fmt.Errorf("hash transaction: %w", err)
+		}
+		receipt, err := idx.nodes.GetTransactionReceipt(hash)
+		if err != nil {
+			return nil, fmt.Errorf("fetch receipt: %w", err)
+		}
+		receipts[i] = receipt
+	}
+	return receipts, nil
+}
+
+// writeBlock indexes a block and its fetched receipts in one database
+// transaction
+func (idx *Indexer) writeBlock(block *chain.Block, receipts []*rpc.TransactionReceiptResponse) error {
+	// Partitions are created outside the block transaction so a failed
+	// block does not roll back a partition other blocks rely on
+	if err := idx.archive.EnsurePartition(time.Unix(block.Header.Timestamp, 0)); err != nil {
+		return fmt.Errorf("ensure partition: %w", err)
+	}
+	
 	tx, err := idx.db.Begin()
 	if err != nil {
 		return err
@@ -224,9 +571,9 @@ func (idx *Indexer) processBlock(block *chain.Block) error {
 		return fmt.Errorf("index block: %w", err)
 	}
 	
-	// Index transactions
+	// Index transactions with their receipts
 	for i, txn := range block.Transactions {
-		if err := idx.txs.IndexTransaction(tx, block, txn, i); err != nil {
+		if err := idx.txs.IndexTransaction(tx, block, txn, i, receipts[i]); err != nil {
 			return fmt.Errorf("index transaction: %w", err)
 		}
 		
@@ -236,9 +583,34 @@ func (idx *Indexer) processBlock(block *chain.Block) error {
 		}
 		
 		// Update assets
-		if err := idx.assets.UpdateFromTransaction(tx, txn); err != nil {
+		if err := idx.assets.UpdateFromTransaction(tx, txn, block.Number); err != nil {
 			return fmt.Errorf("update assets: %w", err)
 		}
+		
+		// Update validator stake and delegations
+		if err := idx.validators.UpdateFromTransaction(tx, txn, block.Number); err != nil {
+			return fmt.Errorf("update validator stake: %w", err)
+		}
+		
+		// Update NFT ownership and transfers
+		if err := idx.nfts.UpdateFromTransaction(tx, txn, block.Number); err != nil {
+			return fmt.Errorf("update nfts: %w", err)
+		}
+		
+		// Record fee and explicit burns
+		if err := idx.burns.UpdateFromTransaction(tx, block, txn); err != nil {
+			return fmt.Errorf("update burns: %w", err)
+		}
+	}
+	
+	// Decode receipt logs and fee burns into account events
+	if err := idx.events.IndexBlock(tx, block, receipts); err != nil {
+		return fmt.Errorf("index events: %w", err)
+	}
+	
+	// Drop the pending transactions this block settled
+	if err := idx.mempool.Confirm(tx, block.Number); err != nil {
+		return fmt.Errorf("confirm pending transactions: %w", err)
 	}
 	
 	// Update validator stats
@@ -246,22 +618,26 @@ func (idx *Indexer) processBlock(block *chain.Block) error {
 		return fmt.Errorf("update validators: %w", err)
 	}
 	
+	// Store the node's summary when this block closes an epoch
+	if idx.config.EpochLength > 0 && (block.Number+1)%idx.config.EpochLength == 0 {
+		epoch := block.Number / idx.config.EpochLength
+		if summary, err := idx.nodes.GetEpoch(epoch); err != nil {
+			logger.Error("fetching epoch summary failed", "epoch", epoch, "err", err)
+		} else if err := idx.epochs.IndexEpoch(tx, summary); err != nil {
+			return fmt.Errorf("index epoch: %w", err)
+		} else if err := idx.validators.UpdateFromEpoch(tx, summary); err != nil {
+			return fmt.Errorf("update validators from epoch: %w", err)
+		} else if err := idx.events.IndexEpoch(tx, summary); err != nil {
+			return fmt.Errorf("index epoch events: %w", err)
+		}
+	}
+	
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return err
 	}
 	
-	// Update state
-	idx.mu.Lock()
-	idx.lastBlock = block.Number
-	idx.mu.Unlock()
-	
-	// Save state periodically
-	if block.Number%100 == 0 {
-		idx.saveState()
-	}
-	
-	fmt.Printf("Indexed block %d with %d transactions\n", block.Number, len(block.Transactions))
+	logger.Debug("indexed block", "block", block.Number, "txs", len(block.Transactions))
 	return nil
 }
 
@@ -301,23 +677,125 @@ func (idx *Indexer) GetLastIndexedBlock() uint64 {
 	return idx.lastBlock
 }
 
-// HandleReorg handles chain reorganizations
+// storedHash returns the hash indexed at a height, or "" if there is none
+func (idx *Indexer) storedHash(number uint64) (string, error) {
+	var hash string
+	err := idx.db.QueryRow("SELECT hash FROM blocks WHERE number = $1", number).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return hash, err
+}
+
+// detectReorg compares a block's parent hash with the hash indexed below it.
+// On a mismatch it finds the fork point and rolls the index back to it,
+// reporting whether it did
+func (idx *Indexer) detectReorg(block *chain.Block) (bool, error) {
+	if block.Header.Height == 0 {
+		return false, nil
+	}
+	parent, err := idx.storedHash(block.Header.Height - 1)
+	if err != nil {
+		return false, err
+	}
+	if parent == "" || parent == block.Header.ParentHash {
+		return false, nil
+	}
+	
+	fork, err := idx.findForkPoint(block.Header.Height - 1)
+	if err != nil {
+		return false, err
+	}
+	logger.Warn("reorg detected", "block", block.Header.Height, "fork", fork,
+		"depth", block.Header.Height-1-fork)
+	if err := idx.HandleReorg(fork + 1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// findForkPoint walks back from a height whose indexed hash no longer
+// matches the node's and returns the highest height where they agree
+func (idx *Indexer) findForkPoint(from uint64) (uint64, error) {
+	for depth := 0; depth <= idx.config.ReorgDepth && depth <= int(from); depth++ {
+		number := from - uint64(depth)
+		stored, err := idx.storedHash(number)
+		if err != nil {
+			return 0, err
+		}
+		block, err := idx.nodes.GetBlockByNumber(number)
+		if err != nil {
+			return 0, err
+		}
+		if stored == "" || stored == block.Hash {
+			return number, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: no common block within %d of %d", ErrReorgTooDeep, idx.config.ReorgDepth, from)
+}
+
+// HandleReorg rolls the index back to just before fromBlock: balances,
+// token transfers, asset supply and validator stats are reversed before
+// the events, transactions, burns, NFT activity and blocks from there on are
+// deleted. The fetcher then re-indexes the node's branch from fromBlock.
 func (idx *Indexer) HandleReorg(fromBlock uint64) error {
+	if fromBlock == 0 {
+		return fmt.Errorf("%w: cannot roll back genesis", ErrReorgTooDeep)
+	}
+	idx.mu.RLock()
+	last := idx.lastBlock
+	finalized := idx.finalized
+	idx.mu.RUnlock()
+	if finalized != nil && fromBlock <= finalized.Height {
+		return fmt.Errorf("%w: block %d is finalized", ErrReorgPastCheckpoint, finalized.Height)
+	}
+	if last >= fromBlock && last-fromBlock >= uint64(idx.config.ReorgDepth) {
+		return fmt.Errorf("%w: %d blocks", ErrReorgTooDeep, last-fromBlock+1)
+	}
+	
 	tx, err := idx.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 	
-	// Delete blocks from the reorg point
+	// Reverse derived state while the rows it derives from still exist
+	if err := idx.validators.Rewind(tx, fromBlock); err != nil {
+		return fmt.Errorf("rewind validators: %w", err)
+	}
+	if err := idx.accounts.Rewind(tx, fromBlock); err != nil {
+		return fmt.Errorf("rewind accounts: %w", err)
+	}
+	if err := idx.assets.Rewind(tx, fromBlock); err != nil {
+		return fmt.Errorf("rewind assets: %w", err)
+	}
+	if err := idx.nfts.Rewind(tx, fromBlock); err != nil {
+		return fmt.Errorf("rewind nfts: %w", err)
+	}
+	
+	// Delete the abandoned branch
+	if err := idx.events.Rewind(tx, fromBlock); err != nil {
+		return fmt.Errorf("rewind events: %w", err)
+	}
+	if err := idx.txs.Rewind(tx, fromBlock); err != nil {
+		return fmt.Errorf("rewind transactions: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM burns WHERE block_number >= $1", fromBlock); err != nil {
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM blocks WHERE number >= $1", fromBlock); err != nil {
 		return err
 	}
 	
-	// Reset state
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	
+	// Reset state and drop blocks queued from the abandoned branch
 	idx.mu.Lock()
 	idx.lastBlock = fromBlock - 1
 	idx.mu.Unlock()
+	idx.rewind(fromBlock - 1)
 	
-	return tx.Commit()
+	return idx.saveState()
 }
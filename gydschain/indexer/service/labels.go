@@ -0,0 +1,248 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Label categories curated by indexer admins
+const (
+	LabelExchange   = "exchange"
+	LabelFoundation = "foundation"
+	LabelTreasury   = "treasury"
+	LabelBridge     = "bridge"
+	LabelOther      = "other"
+)
+
+// maxLabelName bounds the display name shown by explorers
+const maxLabelName = 64
+
+// Label errors
+var (
+	ErrInvalidLabelCategory = errors.New("label category must be exchange, foundation, treasury, bridge or other")
+	ErrInvalidLabelName     = errors.New("label name must be 1-64 characters")
+	ErrMissingLabelAddress  = errors.New("label address required")
+)
+
+// Label is a human readable tag for an address
+type Label struct {
+	Address     string `json:"address"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description,omitempty"`
+	UpdatedBy   string `json:"updated_by,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+// Validate checks a label before it is stored
+func (l *Label) Validate() error {
+	if l.Address == "" {
+		return ErrMissingLabelAddress
+	}
+	name := strings.TrimSpace(l.Name)
+	if name == "" || len(name) > maxLabelName {
+		return ErrInvalidLabelName
+	}
+	switch l.Category {
+	case LabelExchange, LabelFoundation, LabelTreasury, LabelBridge, LabelOther:
+		return nil
+	}
+	return ErrInvalidLabelCategory
+}
+
+// LabelIndexer stores the address label registry
+type LabelIndexer struct {
+	db *sql.DB
+}
+
+// NewLabelIndexer creates a new label indexer
+func NewLabelIndexer(db *sql.DB) *LabelIndexer {
+	return &LabelIndexer{db: db}
+}
+
+// SetLabel creates or replaces the label for an address
+func (li *LabelIndexer) SetLabel(label *Label) error {
+	if err := label.Validate(); err != nil {
+		return err
+	}
+	_, err := li.db.Exec(`
+		INSERT INTO address_labels (address, name, category, description, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (address) DO UPDATE SET
+			name = $2,
+			category = $3,
+			description = $4,
+			updated_by = $5,
+			updated_at = NOW()
+	`, label.Address, strings.TrimSpace(label.Name), label.Category, label.Description, label.UpdatedBy)
+	return err
+}
+
+// DeleteLabel removes an address label and reports whether one existed
+func (li *LabelIndexer) DeleteLabel(address string) (bool, error) {
+	result, err := li.db.Exec(`DELETE FROM address_labels WHERE address = $1`, address)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetLabel retrieves the label for an address
+func (li *LabelIndexer) GetLabel(address string) (*Label, error) {
+	labels, err := lookupLabels(li.db, address)
+	if err != nil {
+		return nil, err
+	}
+	return labels[address], nil
+}
+
+// GetLabels lists labels, optionally restricted to one category
+func (li *LabelIndexer) GetLabels(category string, limit, offset int) ([]*Label, error) {
+	rows, err := li.db.Query(`
+		SELECT address, name, category, description, updated_by, updated_at
+		FROM address_labels
+		WHERE $1 = '' OR category = $1
+		ORDER BY category ASC, name ASC
+		LIMIT $2 OFFSET $3
+	`, category, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var labels []*Label
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// lookupLabels loads labels for the given addresses keyed by address
+func lookupLabels(db *sql.DB, addresses ...string) (map[string]*Label, error) {
+	labels := make(map[string]*Label)
+	if len(addresses) == 0 {
+		return labels, nil
+	}
+
+	list, args := inList(addresses, 1)
+	rows, err := db.Query(`
+		SELECT address, name, category, description, updated_by, updated_at
+		FROM address_labels
+		WHERE address IN (`+list+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels[label.Address] = label
+	}
+	return labels, nil
+}
+
+// inList builds a "$n, $n+1, ..." placeholder list for values starting at
+// parameter first, with the matching query arguments
+func inList(values []string, first int) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		placeholders[i] = fmt.Sprintf("$%d", first+i)
+		args[i] = v
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+func scanLabel(rows *sql.Rows) (*Label, error) {
+	label := &Label{}
+	var description, updatedBy sql.NullString
+	if err := rows.Scan(&label.Address, &label.Name, &label.Category,
+		&description, &updatedBy, &label.UpdatedAt); err != nil {
+		return nil, err
+	}
+	label.Description = description.String
+	label.UpdatedBy = updatedBy.String
+	return label, nil
+}
+
+// attachTransactionLabels fills From/To labels on indexed transactions
+func attachTransactionLabels(db *sql.DB, txs []*IndexedTransaction) error {
+	addresses := make([]string, 0, len(txs)*2)
+	for _, txn := range txs {
+		addresses = append(addresses, txn.From)
+		if txn.To != nil {
+			addresses = append(addresses, *txn.To)
+		}
+	}
+	labels, err := lookupLabels(db, addresses...)
+	if err != nil {
+		return err
+	}
+	for _, txn := range txs {
+		txn.FromLabel = labels[txn.From]
+		if txn.To != nil {
+			txn.ToLabel = labels[*txn.To]
+		}
+	}
+	return nil
+}
+
+// attachRecordLabels fills From/To labels on account transaction records
+func attachRecordLabels(db *sql.DB, txs []*TransactionRecord) error {
+	addresses := make([]string, 0, len(txs)*2)
+	for _, txn := range txs {
+		addresses = append(addresses, txn.From, txn.To)
+	}
+	labels, err := lookupLabels(db, addresses...)
+	if err != nil {
+		return err
+	}
+	for _, txn := range txs {
+		txn.FromLabel = labels[txn.From]
+		txn.ToLabel = labels[txn.To]
+	}
+	return nil
+}
+
+// attachAccountLabels fills labels on accounts
+func attachAccountLabels(db *sql.DB, accounts []*Account) error {
+	addresses := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		addresses = append(addresses, account.Address)
+	}
+	labels, err := lookupLabels(db, addresses...)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		account.Label = labels[account.Address]
+	}
+	return nil
+}
+
+// attachHolderLabels fills labels on asset holders
+func attachHolderLabels(db *sql.DB, holders []*AssetHolder) error {
+	addresses := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		addresses = append(addresses, holder.Address)
+	}
+	labels, err := lookupLabels(db, addresses...)
+	if err != nil {
+		return err
+	}
+	for _, holder := range holders {
+		holder.Label = labels[holder.Address]
+	}
+	return nil
+}
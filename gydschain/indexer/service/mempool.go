@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+// Mempool indexing defaults
+const (
+	DefaultMempoolTTL   = time.Hour // how long an unconfirmed transaction is shown
+	mempoolPruneEvery   = time.Minute
+	mempoolRetryBackoff = 5 * time.Second
+)
+
+// PendingTransaction is a mempool transaction not yet included in a block
+type PendingTransaction struct {
+	Hash      string `json:"hash"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Value     string `json:"value"`
+	Asset     string `json:"asset"`
+	Fee       string `json:"fee"`
+	Nonce     uint64 `json:"nonce"`
+	Type      string `json:"type"`
+	Memo      string `json:"memo,omitempty"`
+	FirstSeen string `json:"first_seen"`
+}
+
+// MempoolIndexer mirrors the node's pending transactions so explorers can
+// show them before confirmation
+type MempoolIndexer struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewMempoolIndexer creates a mempool indexer keeping transactions for ttl
+func NewMempoolIndexer(db *sql.DB, ttl time.Duration) *MempoolIndexer {
+	if ttl <= 0 {
+		ttl = DefaultMempoolTTL
+	}
+	return &MempoolIndexer{db: db, ttl: ttl}
+}
+
+// Add stores a pending transaction, extending its expiry if already known
+func (mi *MempoolIndexer) Add(t *rpc.TransactionResponse) error {
+	_, err := mi.db.Exec(`
+		INSERT INTO pending_transactions (hash, from_address, to_address, value, asset, fee,
+		                                  nonce, tx_type, memo, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (hash) DO UPDATE SET expires_at = EXCLUDED.expires_at
+	`,
+		t.Hash,
+		t.From,
+		sql.NullString{String: t.To, Valid: t.To != ""},
+		t.Value,
+		t.Asset,
+		t.Fee,
+		t.Nonce,
+		t.Type,
+		sql.NullString{String: t.Memo, Valid: t.Memo != ""},
+		time.Now().Add(mi.ttl),
+	)
+	return err
+}
+
+// Confirm removes the pending transactions a block settled: those it
+// included and any other transaction of the same senders with a nonce it
+// used up, which can no longer be included
+func (mi *MempoolIndexer) Confirm(dbTx *sql.Tx, blockNumber uint64) error {
+	_, err := dbTx.Exec(`
+		DELETE FROM pending_transactions
+		WHERE hash IN (SELECT hash FROM transactions WHERE block_number = $1)
+		   OR EXISTS (
+			SELECT 1 FROM transactions t
+			WHERE t.block_number = $1
+			  AND t.from_address = pending_transactions.from_address
+			  AND t.nonce >= pending_transactions.nonce
+		)
+	`, blockNumber)
+	return err
+}
+
+// Prune removes transactions past their expiry
+func (mi *MempoolIndexer) Prune() (int64, error) {
+	res, err := mi.db.Exec("DELETE FROM pending_transactions WHERE expires_at < $1", time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Run follows the active node's pending-transaction stream until ctx or
+// stop ends, resubscribing after a dropped connection. Each (re)connection
+// first loads the node's current mempool so nothing missed is lost.
+func (mi *MempoolIndexer) Run(ctx context.Context, stop <-chan struct{}, nodes *NodePool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go mi.pruneLoop(ctx)
+
+	for {
+		if err := mi.sync(nodes); err != nil {
+			logger.Warn("loading node mempool failed", "err", err)
+		}
+		err := nodes.SubscribePendingTransactions(ctx, func(t *rpc.TransactionResponse) {
+			if err := mi.Add(t); err != nil {
+				logger.Error("storing pending transaction failed", "hash", t.Hash, "err", err)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("pending transaction stream ended, resubscribing", "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(mempoolRetryBackoff):
+		}
+	}
+}
+
+// sync stores every transaction currently in the node's mempool
+func (mi *MempoolIndexer) sync(nodes *NodePool) error {
+	pending, err := nodes.GetPendingTransactions()
+	if err != nil {
+		return err
+	}
+	for _, t := range pending {
+		if err := mi.Add(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneLoop drops expired transactions until ctx ends
+func (mi *MempoolIndexer) pruneLoop(ctx context.Context) {
+	ticker := time.NewTicker(mempoolPruneEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := mi.Prune(); err != nil {
+				logger.Error("pruning pending transactions failed", "err", err)
+			} else if n > 0 {
+				logger.Debug("pruned pending transactions", "count", n)
+			}
+		}
+	}
+}
+
+// GetPending returns pending transactions, newest first
+func (mi *MempoolIndexer) GetPending(limit, offset int) ([]*PendingTransaction, error) {
+	rows, err := mi.db.Query(`
+		SELECT hash, from_address, COALESCE(to_address, ''), value, asset, fee, nonce, tx_type,
+		       COALESCE(memo, ''), first_seen
+		FROM pending_transactions
+		WHERE expires_at >= $1
+		ORDER BY first_seen DESC
+		LIMIT $2 OFFSET $3
+	`, time.Now(), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanPending(rows)
+}
+
+// GetPendingByAddress returns the pending transactions sent or received by
+// address, in nonce order
+func (mi *MempoolIndexer) GetPendingByAddress(address string, limit int) ([]*PendingTransaction, error) {
+	rows, err := mi.db.Query(`
+		SELECT hash, from_address, COALESCE(to_address, ''), value, asset, fee, nonce, tx_type,
+		       COALESCE(memo, ''), first_seen
+		FROM pending_transactions
+		WHERE (from_address = $1 OR to_address = $1) AND expires_at >= $2
+		ORDER BY from_address, nonce
+		LIMIT $3
+	`, address, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPending(rows)
+}
+
+// GetPendingCount returns how many unexpired transactions are pending
+func (mi *MempoolIndexer) GetPendingCount() (uint64, error) {
+	var count uint64
+	err := mi.db.QueryRow(
+		"SELECT COUNT(*) FROM pending_transactions WHERE expires_at >= $1", time.Now(),
+	).Scan(&count)
+	return count, err
+}
+
+// scanPending reads pending transaction rows
+func scanPending(rows *sql.Rows) ([]*PendingTransaction, error) {
+	defer rows.Close()
+
+	var txs []*PendingTransaction
+	for rows.Next() {
+		t := &PendingTransaction{}
+		if err := rows.Scan(&t.Hash, &t.From, &t.To, &t.Value, &t.Asset, &t.Fee, &t.Nonce,
+			&t.Type, &t.Memo, &t.FirstSeen); err != nil {
+			return nil, err
+		}
+		txs = append(txs, t)
+	}
+	return txs, rows.Err()
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// NFTIndexer indexes NFT collections, token ownership and transfers
+type NFTIndexer struct {
+	db *sql.DB
+}
+
+// NewNFTIndexer creates a new NFT indexer
+func NewNFTIndexer(db *sql.DB) *NFTIndexer {
+	return &NFTIndexer{db: db}
+}
+
+// UpdateFromTransaction indexes NFT mints and transfers
+func (ni *NFTIndexer) UpdateFromTransaction(dbTx *sql.Tx, txn *tx.Transaction, blockNumber uint64) error {
+	if txn.Type != tx.TxTypeNFTMint && txn.Type != tx.TxTypeNFTTransfer {
+		return nil
+	}
+
+	hash, err := txn.HashHex()
+	if err != nil {
+		return err
+	}
+	payload, err := tx.DecodePayload(txn)
+	if err != nil {
+		return err
+	}
+
+	if txn.Type == tx.TxTypeNFTMint {
+		p := payload.(*tx.NFTMintPayload)
+		if err := ni.indexMint(dbTx, txn, p, blockNumber); err != nil {
+			return err
+		}
+		return ni.insertTransfer(dbTx, hash, p.Collection, p.TokenID, sql.NullString{}, txn.To, blockNumber)
+	}
+
+	p := payload.(*tx.NFTTransferPayload)
+	if _, err := dbTx.Exec(`
+		UPDATE nft_tokens SET owner = $1 WHERE collection = $2 AND token_id = $3
+	`, txn.To, p.Collection, p.TokenID); err != nil {
+		return err
+	}
+	from := sql.NullString{String: txn.From, Valid: true}
+	return ni.insertTransfer(dbTx, hash, p.Collection, p.TokenID, from, txn.To, blockNumber)
+}
+
+// indexMint records a minted token, creating its collection on first mint
+func (ni *NFTIndexer) indexMint(dbTx *sql.Tx, txn *tx.Transaction, p *tx.NFTMintPayload, blockNumber uint64) error {
+	if _, err := dbTx.Exec(`
+		INSERT INTO nft_collections (collection, creator, token_count, created_block)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (collection) DO UPDATE SET token_count = nft_collections.token_count + 1
+	`, p.Collection, txn.From, blockNumber); err != nil {
+		return err
+	}
+
+	_, err := dbTx.Exec(`
+		INSERT INTO nft_tokens (collection, token_id, owner, name, token_uri, minted_block)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (collection, token_id) DO NOTHING
+	`, p.Collection, p.TokenID, txn.To, p.Name, p.TokenURI, blockNumber)
+	return err
+}
+
+func (ni *NFTIndexer) insertTransfer(dbTx *sql.Tx, txHash, collection, tokenID string, from sql.NullString, to string, blockNumber uint64) error {
+	_, err := dbTx.Exec(`
+		INSERT INTO nft_transfers (tx_hash, collection, token_id, from_address, to_address, block_number)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, txHash, collection, tokenID, from, to, blockNumber)
+	return err
+}
+
+// Rewind removes NFT activity from fromBlock on and restores each token's
+// owner to the recipient of its last remaining transfer
+func (ni *NFTIndexer) Rewind(dbTx *sql.Tx, fromBlock uint64) error {
+	stmts := []string{
+		"DELETE FROM nft_transfers WHERE block_number >= $1",
+		"DELETE FROM nft_tokens WHERE minted_block >= $1",
+		"DELETE FROM nft_collections WHERE created_block >= $1",
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt, fromBlock); err != nil {
+			return err
+		}
+	}
+
+	_, err := dbTx.Exec(`
+		UPDATE nft_tokens t SET owner = (
+			SELECT x.to_address FROM nft_transfers x
+			WHERE x.collection = t.collection AND x.token_id = t.token_id
+			ORDER BY x.block_number DESC, x.id DESC
+			LIMIT 1
+		)
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = dbTx.Exec(`
+		UPDATE nft_collections c SET token_count = (
+			SELECT COUNT(*) FROM nft_tokens t WHERE t.collection = c.collection
+		)
+	`)
+	return err
+}
+
+// GetCollections returns NFT collections, largest first
+func (ni *NFTIndexer) GetCollections(limit, offset int) ([]*NFTCollection, error) {
+	rows, err := ni.db.Query(`
+		SELECT collection, creator, token_count, created_block
+		FROM nft_collections
+		ORDER BY token_count DESC, collection ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []*NFTCollection
+	for rows.Next() {
+		c := &NFTCollection{}
+		if err := rows.Scan(&c.Collection, &c.Creator, &c.TokenCount, &c.CreatedBlock); err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// GetToken returns a token, or nil if it was never minted
+func (ni *NFTIndexer) GetToken(collection, tokenID string) (*NFTToken, error) {
+	token := &NFTToken{}
+	var name, uri sql.NullString
+	err := ni.db.QueryRow(`
+		SELECT collection, token_id, owner, name, token_uri, minted_block
+		FROM nft_tokens WHERE collection = $1 AND token_id = $2
+	`, collection, tokenID).Scan(
+		&token.Collection, &token.TokenID, &token.Owner, &name, &uri, &token.MintedBlock,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token.Name, token.TokenURI = name.String, uri.String
+	return token, nil
+}
+
+// GetTransfers returns a token's transfers, newest first; the mint is the
+// transfer with no sender
+func (ni *NFTIndexer) GetTransfers(collection, tokenID string, limit, offset int) ([]*NFTTransfer, error) {
+	rows, err := ni.db.Query(`
+		SELECT tx_hash, from_address, to_address, block_number
+		FROM nft_transfers
+		WHERE collection = $1 AND token_id = $2
+		ORDER BY block_number DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, collection, tokenID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*NFTTransfer
+	for rows.Next() {
+		t := &NFTTransfer{}
+		var from sql.NullString
+		if err := rows.Scan(&t.TxHash, &from, &t.To, &t.BlockNumber); err != nil {
+			return nil, err
+		}
+		t.From = from.String
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
+
+// NFTCollection summarizes an NFT collection
+type NFTCollection struct {
+	Collection   string `json:"collection"`
+	Creator      string `json:"creator"`
+	TokenCount   int    `json:"token_count"`
+	CreatedBlock uint64 `json:"created_block"`
+}
+
+// NFTToken is a minted NFT and its current owner
+type NFTToken struct {
+	Collection  string `json:"collection"`
+	TokenID     string `json:"token_id"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name,omitempty"`
+	TokenURI    string `json:"token_uri,omitempty"`
+	MintedBlock uint64 `json:"minted_block"`
+}
+
+// NFTTransfer is one change of an NFT's owner
+type NFTTransfer struct {
+	TxHash      string `json:"tx_hash"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to"`
+	BlockNumber uint64 `json:"block_number"`
+}
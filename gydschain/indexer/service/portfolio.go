@@ -0,0 +1,221 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// MaxPortfolioAddresses bounds how many addresses one portfolio query covers
+const MaxPortfolioAddresses = 100
+
+// Portfolio errors
+var (
+	ErrNoPortfolioAddresses      = errors.New("at least one address required")
+	ErrTooManyPortfolioAddresses = errors.New("too many addresses (max 100)")
+)
+
+// PortfolioIndexer aggregates balances and activity across addresses
+type PortfolioIndexer struct {
+	db *sql.DB
+}
+
+// NewPortfolioIndexer creates a new portfolio indexer
+func NewPortfolioIndexer(db *sql.DB) *PortfolioIndexer {
+	return &PortfolioIndexer{db: db}
+}
+
+// GetPortfolio returns combined holdings, staking and the most recent
+// activityLimit transactions for a set of addresses
+func (pi *PortfolioIndexer) GetPortfolio(addresses []string, activityLimit int) (*Portfolio, error) {
+	addresses = dedupAddresses(addresses)
+	if len(addresses) == 0 {
+		return nil, ErrNoPortfolioAddresses
+	}
+	if len(addresses) > MaxPortfolioAddresses {
+		return nil, ErrTooManyPortfolioAddresses
+	}
+
+	portfolio := &Portfolio{
+		Addresses:   addresses,
+		Balances:    make(map[string]string),
+		Accounts:    make([]*PortfolioAccount, 0, len(addresses)),
+		Delegations: make([]*PortfolioDelegation, 0),
+	}
+	if err := pi.loadBalances(portfolio); err != nil {
+		return nil, err
+	}
+	if err := pi.loadStaking(portfolio); err != nil {
+		return nil, err
+	}
+	activity, err := pi.recentActivity(addresses, activityLimit)
+	if err != nil {
+		return nil, err
+	}
+	portfolio.Activity = activity
+
+	return portfolio, nil
+}
+
+// loadBalances fills per-address and combined balances
+func (pi *PortfolioIndexer) loadBalances(portfolio *Portfolio) error {
+	list, args := inList(portfolio.Addresses, 1)
+	rows, err := pi.db.Query(`
+		SELECT address, asset, balance
+		FROM account_balances
+		WHERE address IN (`+list+`)
+		ORDER BY address ASC, asset ASC
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	accounts := make(map[string]*PortfolioAccount)
+	for _, addr := range portfolio.Addresses {
+		account := &PortfolioAccount{Address: addr, Balances: make(map[string]string)}
+		accounts[addr] = account
+		portfolio.Accounts = append(portfolio.Accounts, account)
+	}
+	for rows.Next() {
+		var address, asset, balance string
+		if err := rows.Scan(&address, &asset, &balance); err != nil {
+			return err
+		}
+		if account, ok := accounts[address]; ok {
+			account.Balances[asset] = balance
+		}
+	}
+
+	// Sum in SQL so large balances keep full precision
+	rows, err = pi.db.Query(`
+		SELECT asset, SUM(CAST(balance AS NUMERIC))::TEXT
+		FROM account_balances
+		WHERE address IN (`+list+`)
+		GROUP BY asset
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var asset, total string
+		if err := rows.Scan(&asset, &total); err != nil {
+			return err
+		}
+		portfolio.Balances[asset] = total
+	}
+
+	labels, err := lookupLabels(pi.db, portfolio.Addresses...)
+	if err != nil {
+		return err
+	}
+	for _, account := range portfolio.Accounts {
+		account.Label = labels[account.Address]
+	}
+	return nil
+}
+
+// loadStaking fills delegations, validator self-stake and pending rewards
+func (pi *PortfolioIndexer) loadStaking(portfolio *Portfolio) error {
+	list, args := inList(portfolio.Addresses, 1)
+	rows, err := pi.db.Query(`
+		SELECT delegator, validator, amount, rewards
+		FROM delegations
+		WHERE delegator IN (`+list+`)
+		ORDER BY CAST(amount AS NUMERIC) DESC
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d := &PortfolioDelegation{}
+		if err := rows.Scan(&d.Delegator, &d.Validator, &d.Amount, &d.Rewards); err != nil {
+			return err
+		}
+		portfolio.Delegations = append(portfolio.Delegations, d)
+	}
+
+	return pi.db.QueryRow(`
+		SELECT
+			(COALESCE((SELECT SUM(CAST(amount AS NUMERIC)) FROM delegations WHERE delegator IN (`+list+`)), 0) +
+			 COALESCE((SELECT SUM(CAST(stake AS NUMERIC)) FROM validators WHERE address IN (`+list+`)), 0))::TEXT,
+			COALESCE((SELECT SUM(CAST(rewards AS NUMERIC)) FROM delegations WHERE delegator IN (`+list+`)), 0)::TEXT
+	`, args...).Scan(&portfolio.Staked, &portfolio.PendingRewards)
+}
+
+// recentActivity returns the latest transactions touching any address
+func (pi *PortfolioIndexer) recentActivity(addresses []string, limit int) ([]*TransactionRecord, error) {
+	list, args := inList(addresses, 2)
+	rows, err := pi.db.Query(`
+		SELECT hash, block_number, tx_index, from_address, to_address,
+		       value, asset, fee, tx_type, status, created_at
+		FROM transactions
+		WHERE from_address IN (`+list+`) OR to_address IN (`+list+`)
+		ORDER BY block_number DESC, tx_index DESC
+		LIMIT $1
+	`, append([]interface{}{limit}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]*TransactionRecord, 0)
+	for rows.Next() {
+		txn := &TransactionRecord{}
+		if err := rows.Scan(
+			&txn.Hash, &txn.BlockNumber, &txn.TxIndex, &txn.From, &txn.To,
+			&txn.Value, &txn.Asset, &txn.Fee, &txn.Type, &txn.Status, &txn.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		txs = append(txs, txn)
+	}
+
+	if err := attachRecordLabels(pi.db, txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// dedupAddresses drops empty and repeated addresses, keeping order
+func dedupAddresses(addresses []string) []string {
+	seen := make(map[string]bool, len(addresses))
+	out := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		out = append(out, addr)
+	}
+	return out
+}
+
+// Portfolio is the combined view of a wallet's addresses
+type Portfolio struct {
+	Addresses      []string               `json:"addresses"`
+	Balances       map[string]string      `json:"balances"` // asset -> total across addresses
+	Accounts       []*PortfolioAccount    `json:"accounts"`
+	Staked         string                 `json:"staked"` // delegations plus validator self-stake
+	PendingRewards string                 `json:"pending_rewards"`
+	Delegations    []*PortfolioDelegation `json:"delegations"`
+	Activity       []*TransactionRecord   `json:"activity"`
+}
+
+// PortfolioAccount is one address's share of a portfolio
+type PortfolioAccount struct {
+	Address  string            `json:"address"`
+	Label    *Label            `json:"label,omitempty"`
+	Balances map[string]string `json:"balances"`
+}
+
+// PortfolioDelegation is a delegation held by a portfolio address
+type PortfolioDelegation struct {
+	Delegator string `json:"delegator"`
+	Validator string `json:"validator"`
+	Amount    string `json:"amount"`
+	Rewards   string `json:"rewards"`
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// Search result types
+const (
+	SearchBlock       = "block"
+	SearchTransaction = "transaction"
+	SearchAccount     = "account"
+	SearchAsset       = "asset"
+	SearchValidator   = "validator"
+)
+
+// Relevance scores; exact matches rank above prefix and name matches
+const (
+	scoreExact  = 100
+	scoreSymbol = 90
+	scoreLabel  = 70
+	scorePrefix = 50
+	scoreName   = 40
+)
+
+// minHashPrefix is the shortest hex prefix searched as a partial hash
+const minHashPrefix = 4
+
+// SearchResult is an entity matching a search query
+type SearchResult struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id"`
+	Score   int         `json:"score"`
+	Preview interface{} `json:"preview"`
+}
+
+// BlockPreview summarizes a matching block
+type BlockPreview struct {
+	Number    uint64 `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+	TxCount   int    `json:"tx_count"`
+	Validator string `json:"validator"`
+}
+
+// TransactionPreview summarizes a matching transaction
+type TransactionPreview struct {
+	Hash        string `json:"hash"`
+	BlockNumber uint64 `json:"block_number"`
+	From        string `json:"from"`
+	To          string `json:"to,omitempty"`
+	Value       string `json:"value"`
+	Asset       string `json:"asset"`
+	Type        string `json:"type"`
+}
+
+// AccountPreview summarizes a matching account
+type AccountPreview struct {
+	Address string `json:"address"`
+	TxCount uint64 `json:"tx_count"`
+	Label   string `json:"label,omitempty"`
+}
+
+// AssetPreview summarizes a matching asset
+type AssetPreview struct {
+	ID          string `json:"id"`
+	Symbol      string `json:"symbol"`
+	Name        string `json:"name"`
+	TotalSupply string `json:"total_supply"`
+}
+
+// ValidatorPreview summarizes a matching validator
+type ValidatorPreview struct {
+	Address string `json:"address"`
+	Stake   string `json:"stake"`
+	Active  bool   `json:"active"`
+}
+
+// SearchIndexer resolves explorer search queries across indexed entities
+type SearchIndexer struct {
+	db *sql.DB
+}
+
+// NewSearchIndexer creates a new search indexer
+func NewSearchIndexer(db *sql.DB) *SearchIndexer {
+	return &SearchIndexer{db: db}
+}
+
+// Search matches query by its shape: a number is a block height, a full
+// 0x hash a block or transaction, a shorter 0x prefix a partial hash, a
+// gyds1 string a full or partial address and anything else an asset
+// symbol or name or an address label. Results are ordered by relevance.
+func (si *SearchIndexer) Search(query string, limit int) ([]*SearchResult, error) {
+	query = strings.TrimSpace(query)
+	var (
+		results []*SearchResult
+		err     error
+	)
+	switch {
+	case query == "":
+		return nil, nil
+	case isDigits(query):
+		results, err = si.searchHeight(query)
+	case strings.HasPrefix(strings.ToLower(query), "0x"):
+		results, err = si.searchHash(strings.ToLower(query), limit)
+	case strings.HasPrefix(strings.ToLower(query), crypto.AddressPrefix):
+		results, err = si.searchAddress(strings.ToLower(query), limit)
+	default:
+		results, err = si.searchName(query, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// searchHeight matches a block by number
+func (si *SearchIndexer) searchHeight(query string) ([]*SearchResult, error) {
+	number, err := strconv.ParseUint(query, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	return si.queryBlocks("WHERE number = $1", scoreExact, 1, number)
+}
+
+// searchHash matches a full block or transaction hash, or a hash prefix
+func (si *SearchIndexer) searchHash(query string, limit int) ([]*SearchResult, error) {
+	digits := query[2:]
+	if !isHex(digits) || len(digits) > 64 {
+		return nil, nil
+	}
+	if len(digits) == 64 {
+		blocks, err := si.queryBlocks("WHERE hash = $1", scoreExact, 1, query)
+		if err != nil {
+			return nil, err
+		}
+		txs, err := si.queryTransactions("WHERE hash = $1", scoreExact, 1, query)
+		if err != nil {
+			return nil, err
+		}
+		return append(blocks, txs...), nil
+	}
+	if len(digits) < minHashPrefix {
+		return nil, nil
+	}
+
+	blocks, err := si.queryBlocks("WHERE hash LIKE $1", scorePrefix, limit, query+"%")
+	if err != nil {
+		return nil, err
+	}
+	txs, err := si.queryTransactions("WHERE hash LIKE $1", scorePrefix, limit, query+"%")
+	if err != nil {
+		return nil, err
+	}
+	return append(blocks, txs...), nil
+}
+
+// searchAddress matches an account and validator by full address, or
+// accounts by address prefix
+func (si *SearchIndexer) searchAddress(query string, limit int) ([]*SearchResult, error) {
+	if crypto.ValidateAddress(query) == nil {
+		accounts, err := si.queryAccounts("WHERE a.address = $1", scoreExact, 1, query)
+		if err != nil {
+			return nil, err
+		}
+		validators, err := si.queryValidators(query)
+		if err != nil {
+			return nil, err
+		}
+		return append(accounts, validators...), nil
+	}
+	if !isAlnum(query) || len(query) <= len(crypto.AddressPrefix) {
+		return nil, nil
+	}
+	return si.queryAccounts("WHERE a.address LIKE $1", scorePrefix, limit, query+"%")
+}
+
+// searchName matches assets by symbol or name and accounts by label
+func (si *SearchIndexer) searchName(query string, limit int) ([]*SearchResult, error) {
+	// LIKE wildcards in the query are matched literally by dropping them
+	query = strings.NewReplacer("%", "", "_", "").Replace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	rows, err := si.db.Query(`
+		SELECT asset_id, symbol, name, total_supply FROM assets
+		WHERE LOWER(symbol) LIKE LOWER($1) OR LOWER(name) LIKE LOWER($2)
+		ORDER BY symbol
+		LIMIT $3
+	`, query+"%", "%"+query+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		p := &AssetPreview{}
+		if err := rows.Scan(&p.ID, &p.Symbol, &p.Name, &p.TotalSupply); err != nil {
+			return nil, err
+		}
+		score := scoreName
+		switch {
+		case strings.EqualFold(p.Symbol, query):
+			score = scoreSymbol
+		case strings.HasPrefix(strings.ToLower(p.Symbol), strings.ToLower(query)):
+			score = scorePrefix
+		}
+		results = append(results, &SearchResult{Type: SearchAsset, ID: p.ID, Score: score, Preview: p})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	labeled, err := si.queryAccounts("WHERE LOWER(l.name) LIKE LOWER($1)", scoreLabel, limit, "%"+query+"%")
+	if err != nil {
+		return nil, err
+	}
+	return append(results, labeled...), nil
+}
+
+// queryBlocks returns blocks matching where
+func (si *SearchIndexer) queryBlocks(where string, score, limit int, arg interface{}) ([]*SearchResult, error) {
+	rows, err := si.db.Query(`
+		SELECT number, hash, timestamp, tx_count, validator FROM blocks
+		`+where+`
+		ORDER BY number DESC
+		LIMIT $2
+	`, arg, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		p := &BlockPreview{}
+		if err := rows.Scan(&p.Number, &p.Hash, &p.Timestamp, &p.TxCount, &p.Validator); err != nil {
+			return nil, err
+		}
+		results = append(results, &SearchResult{Type: SearchBlock, ID: p.Hash, Score: score, Preview: p})
+	}
+	return results, rows.Err()
+}
+
+// queryTransactions returns transactions matching where
+func (si *SearchIndexer) queryTransactions(where string, score, limit int, arg interface{}) ([]*SearchResult, error) {
+	rows, err := si.db.Query(`
+		SELECT hash, block_number, from_address, COALESCE(to_address, ''), value, asset, tx_type
+		FROM transactions
+		`+where+`
+		ORDER BY block_number DESC
+		LIMIT $2
+	`, arg, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		p := &TransactionPreview{}
+		if err := rows.Scan(&p.Hash, &p.BlockNumber, &p.From, &p.To, &p.Value, &p.Asset, &p.Type); err != nil {
+			return nil, err
+		}
+		results = append(results, &SearchResult{Type: SearchTransaction, ID: p.Hash, Score: score, Preview: p})
+	}
+	return results, rows.Err()
+}
+
+// queryAccounts returns accounts matching where, with their labels
+func (si *SearchIndexer) queryAccounts(where string, score, limit int, arg interface{}) ([]*SearchResult, error) {
+	rows, err := si.db.Query(`
+		SELECT a.address, a.tx_count, COALESCE(l.name, '') FROM accounts a
+		LEFT JOIN address_labels l ON l.address = a.address
+		`+where+`
+		ORDER BY a.tx_count DESC
+		LIMIT $2
+	`, arg, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		p := &AccountPreview{}
+		if err := rows.Scan(&p.Address, &p.TxCount, &p.Label); err != nil {
+			return nil, err
+		}
+		results = append(results, &SearchResult{Type: SearchAccount, ID: p.Address, Score: score, Preview: p})
+	}
+	return results, rows.Err()
+}
+
+// queryValidators returns the validator registered at address, if any
+func (si *SearchIndexer) queryValidators(address string) ([]*SearchResult, error) {
+	p := &ValidatorPreview{Address: address}
+	err := si.db.QueryRow(
+		"SELECT stake, active FROM validators WHERE address = $1", address,
+	).Scan(&p.Stake, &p.Active)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*SearchResult{{Type: SearchValidator, ID: address, Score: scoreExact, Preview: p}}, nil
+}
+
+// isDigits reports whether s is a non-empty run of decimal digits
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// isHex reports whether s is a non-empty run of lowercase hex digits
+func isHex(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// isAlnum reports whether s holds only lowercase letters and digits
+func isAlnum(s string) bool {
+	return strings.Trim(s, "0123456789abcdefghijklmnopqrstuvwxyz") == ""
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Aggregation periods of chain_stats
+const (
+	PeriodDay  = "day"
+	PeriodHour = "hour"
+)
+
+// DefaultStatsInterval is how often the stats worker refreshes aggregates
+const DefaultStatsInterval = 5 * time.Minute
+
+// DailyStats is one day of aggregated chain activity
+type DailyStats struct {
+	Date            string `json:"date"`
+	TxCount         uint64 `json:"tx_count"`
+	TotalValue      string `json:"total_value"`
+	TotalFees       string `json:"total_fees"`
+	FeesBurned      string `json:"fees_burned"`
+	GasUsed         uint64 `json:"gas_used"`
+	ActiveAddresses uint64 `json:"active_addresses"`
+	NewAccounts     uint64 `json:"new_accounts"`
+}
+
+// HourlyStats is one hour of aggregated chain activity
+type HourlyStats struct {
+	Hour            string `json:"hour"`
+	TxCount         uint64 `json:"tx_count"`
+	TotalFees       string `json:"total_fees"`
+	GasUsed         uint64 `json:"gas_used"`
+	ActiveAddresses uint64 `json:"active_addresses"`
+}
+
+// ActiveAddressPoint is one day of the active-addresses chart
+type ActiveAddressPoint struct {
+	Date            string `json:"date"`
+	ActiveAddresses uint64 `json:"active_addresses"`
+	NewAccounts     uint64 `json:"new_accounts"`
+}
+
+// FeeBurnPoint is one day of the fee-burn chart
+type FeeBurnPoint struct {
+	Date       string  `json:"date"`
+	TotalFees  string  `json:"total_fees"`
+	FeesBurned string  `json:"fees_burned"`
+	BurnRatio  float64 `json:"burn_ratio"` // burned share of fees, 0-1
+}
+
+// StatsAggregator materializes daily and hourly chain statistics into
+// chain_stats so charts read a few rows instead of grouping transactions
+type StatsAggregator struct {
+	db *sql.DB
+}
+
+// NewStatsAggregator creates a new stats aggregator
+func NewStatsAggregator(db *sql.DB) *StatsAggregator {
+	return &StatsAggregator{db: db}
+}
+
+// Refresh recomputes the day and hour buckets from since on. Buckets are
+// replaced whole, so since is rounded down to the start of its day.
+func (sa *StatsAggregator) Refresh(since time.Time) error {
+	since = since.UTC().Truncate(24 * time.Hour)
+	for _, period := range []string{PeriodDay, PeriodHour} {
+		if err := sa.refreshPeriod(period, since); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshPeriod aggregates one period's buckets from since on
+func (sa *StatsAggregator) refreshPeriod(period string, since time.Time) error {
+	_, err := sa.db.Exec(`
+		WITH txs AS (
+			SELECT DATE_TRUNC($1, block_time) AS bucket,
+			       COUNT(*) AS tx_count,
+			       COALESCE(SUM(CAST(value AS NUMERIC)), 0) AS total_value,
+			       COALESCE(SUM(CAST(fee AS NUMERIC)), 0) AS total_fees,
+			       COALESCE(SUM(gas_used), 0) AS gas_used
+			FROM transactions
+			WHERE block_time >= $2
+			GROUP BY 1
+		), active AS (
+			SELECT bucket, COUNT(DISTINCT address) AS active_addresses FROM (
+				SELECT DATE_TRUNC($1, block_time) AS bucket, from_address AS address
+				FROM transactions WHERE block_time >= $2
+				UNION ALL
+				SELECT DATE_TRUNC($1, block_time), to_address
+				FROM transactions WHERE block_time >= $2 AND COALESCE(to_address, '') <> ''
+			) a
+			GROUP BY bucket
+		), fresh AS (
+			SELECT DATE_TRUNC($1, TO_TIMESTAMP(b.timestamp)) AS bucket, COUNT(*) AS new_accounts
+			FROM accounts a
+			JOIN blocks b ON b.number = a.first_seen_block
+			WHERE b.timestamp >= EXTRACT(EPOCH FROM $2::TIMESTAMPTZ)
+			GROUP BY 1
+		), burned AS (
+			SELECT DATE_TRUNC($1, TO_TIMESTAMP(block_timestamp)) AS bucket,
+			       SUM(CAST(amount AS NUMERIC)) AS fees_burned
+			FROM burns
+			WHERE source = 'fee' AND asset = 'GYDS'
+			  AND block_timestamp >= EXTRACT(EPOCH FROM $2::TIMESTAMPTZ)
+			GROUP BY 1
+		)
+		INSERT INTO chain_stats (period, bucket, tx_count, total_value, total_fees, fees_burned,
+		                         gas_used, active_addresses, new_accounts, updated_at)
+		SELECT $1, txs.bucket, txs.tx_count, txs.total_value::TEXT, txs.total_fees::TEXT,
+		       COALESCE(burned.fees_burned, 0)::TEXT, txs.gas_used,
+		       COALESCE(active.active_addresses, 0), COALESCE(fresh.new_accounts, 0), NOW()
+		FROM txs
+		LEFT JOIN active USING (bucket)
+		LEFT JOIN fresh USING (bucket)
+		LEFT JOIN burned USING (bucket)
+		ON CONFLICT (period, bucket) DO UPDATE SET
+			tx_count = EXCLUDED.tx_count,
+			total_value = EXCLUDED.total_value,
+			total_fees = EXCLUDED.total_fees,
+			fees_burned = EXCLUDED.fees_burned,
+			gas_used = EXCLUDED.gas_used,
+			active_addresses = EXCLUDED.active_addresses,
+			new_accounts = EXCLUDED.new_accounts,
+			updated_at = NOW()
+	`, period, since)
+	if err != nil {
+		return err
+	}
+
+	// Buckets whose transactions were rolled back by a reorg
+	_, err = sa.db.Exec(`
+		DELETE FROM chain_stats s
+		WHERE s.period = $1 AND s.bucket >= $2 AND NOT EXISTS (
+			SELECT 1 FROM transactions t
+			WHERE t.block_time >= s.bucket AND t.block_time < s.bucket + ('1 ' || $1)::INTERVAL
+		)
+	`, period, since)
+	return err
+}
+
+// lastBucket returns the newest aggregated day, or false if none is
+func (sa *StatsAggregator) lastBucket() (time.Time, bool, error) {
+	var last sql.NullTime
+	err := sa.db.QueryRow(
+		"SELECT MAX(bucket) FROM chain_stats WHERE period = $1", PeriodDay,
+	).Scan(&last)
+	return last.Time, last.Valid, err
+}
+
+// Run refreshes the aggregates every interval until ctx or stop ends. The
+// first run aggregates all history; later runs redo yesterday and today,
+// which also picks up reorgs and late blocks.
+func (sa *StatsAggregator) Run(ctx context.Context, stop <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		since := time.Now().Add(-24 * time.Hour)
+		if last, ok, err := sa.lastBucket(); err != nil {
+			logger.Error("reading chain stats failed", "err", err)
+		} else {
+			if !ok {
+				since = time.Unix(0, 0)
+			} else if last.Before(since) {
+				since = last
+			}
+			start := time.Now()
+			if err := sa.Refresh(since); err != nil {
+				logger.Error("refreshing chain stats failed", "err", err)
+			} else {
+				logger.Debug("refreshed chain stats", "since", since.Format("2006-01-02"), "took", time.Since(start))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetDailyStats returns the aggregates of the last days, newest first
+func (sa *StatsAggregator) GetDailyStats(days int) ([]*DailyStats, error) {
+	rows, err := sa.db.Query(`
+		SELECT TO_CHAR(bucket, 'YYYY-MM-DD'), tx_count, total_value, total_fees, fees_burned,
+		       gas_used, active_addresses, new_accounts
+		FROM chain_stats
+		WHERE period = $1 AND bucket >= CURRENT_DATE - $2::INT
+		ORDER BY bucket DESC
+	`, PeriodDay, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*DailyStats
+	for rows.Next() {
+		s := &DailyStats{}
+		if err := rows.Scan(&s.Date, &s.TxCount, &s.TotalValue, &s.TotalFees, &s.FeesBurned,
+			&s.GasUsed, &s.ActiveAddresses, &s.NewAccounts); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// GetHourlyStats returns the aggregates of the last hours, newest first
+func (sa *StatsAggregator) GetHourlyStats(hours int) ([]*HourlyStats, error) {
+	rows, err := sa.db.Query(`
+		SELECT TO_CHAR(bucket, 'YYYY-MM-DD"T"HH24:00:00"Z"'), tx_count, total_fees, gas_used, active_addresses
+		FROM chain_stats
+		WHERE period = $1 AND bucket >= NOW() - INTERVAL '1 hour' * $2
+		ORDER BY bucket DESC
+	`, PeriodHour, hours)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*HourlyStats
+	for rows.Next() {
+		s := &HourlyStats{}
+		if err := rows.Scan(&s.Hour, &s.TxCount, &s.TotalFees, &s.GasUsed, &s.ActiveAddresses); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// GetActiveAddresses returns the daily active-address series of the last
+// days, oldest first
+func (sa *StatsAggregator) GetActiveAddresses(days int) ([]*ActiveAddressPoint, error) {
+	daily, err := sa.GetDailyStats(days)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]*ActiveAddressPoint, len(daily))
+	for i, s := range daily {
+		points[len(daily)-1-i] = &ActiveAddressPoint{
+			Date:            s.Date,
+			ActiveAddresses: s.ActiveAddresses,
+			NewAccounts:     s.NewAccounts,
+		}
+	}
+	return points, nil
+}
+
+// GetFeeBurn returns the daily fees and fee burns of the last days, oldest
+// first
+func (sa *StatsAggregator) GetFeeBurn(days int) ([]*FeeBurnPoint, error) {
+	rows, err := sa.db.Query(`
+		SELECT TO_CHAR(bucket, 'YYYY-MM-DD'), total_fees, fees_burned,
+		       COALESCE(CAST(fees_burned AS NUMERIC) / NULLIF(CAST(total_fees AS NUMERIC), 0), 0)::FLOAT
+		FROM chain_stats
+		WHERE period = $1 AND bucket >= CURRENT_DATE - $2::INT
+		ORDER BY bucket ASC
+	`, PeriodDay, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*FeeBurnPoint
+	for rows.Next() {
+		p := &FeeBurnPoint{}
+		if err := rows.Scan(&p.Date, &p.TotalFees, &p.FeesBurned, &p.BurnRatio); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
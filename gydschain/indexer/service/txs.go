@@ -2,8 +2,11 @@ package service
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/rpc"
 	"github.com/gydschain/gydschain/internal/tx"
 )
 
@@ -17,18 +20,34 @@ func NewTransactionIndexer(db *sql.DB) *TransactionIndexer {
 	return &TransactionIndexer{db: db}
 }
 
-// IndexTransaction indexes a transaction
-func (ti *TransactionIndexer) IndexTransaction(dbTx *sql.Tx, block *chain.Block, txn *tx.Transaction, txIndex int) error {
-	_, err := dbTx.Exec(`
-		INSERT INTO transactions (hash, block_number, block_hash, tx_index, from_address,
-		                         to_address, value, asset, fee, nonce, data, signature,
-		                         tx_type, status, gas_used)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
-		ON CONFLICT (hash) DO NOTHING
+// IndexTransaction indexes a transaction with the receipt the node produced
+// for it; without a receipt it is stored as successful with no gas or logs
+func (ti *TransactionIndexer) IndexTransaction(dbTx *sql.Tx, block *chain.Block, txn *tx.Transaction, txIndex int, receipt *rpc.TransactionReceiptResponse) error {
+	payload, err := encodeIndexedPayload(txn)
+	if err != nil {
+		return err
+	}
+	
+	status, gasUsed := uint64(tx.ReceiptSuccess), uint64(0)
+	var logs []byte
+	if receipt != nil {
+		status, gasUsed = receipt.Status, receipt.GasUsed
+		if logs, err = json.Marshal(receipt.Logs); err != nil {
+			return err
+		}
+	}
+
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (hash, block_number, block_hash, block_time, tx_index, from_address,
+		                         to_address, value, asset, fee, nonce, data, memo, payload, signature,
+		                         tx_type, status, gas_used, logs, submitted_at, inclusion_latency_secs)
+		VALUES ($1, $2, $3, to_timestamp($4), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		ON CONFLICT (hash, block_time) DO NOTHING
 	`,
 		txn.Hash(),
 		block.Number,
 		block.Hash(),
+		block.Header.Timestamp,
 		txIndex,
 		txn.From,
 		txn.To,
@@ -37,39 +56,111 @@ func (ti *TransactionIndexer) IndexTransaction(dbTx *sql.Tx, block *chain.Block,
 		txn.Fee.String(),
 		txn.Nonce,
 		txn.Data,
+		sql.NullString{String: txn.Memo, Valid: txn.Memo != ""},
+		payload,
 		txn.Signature,
 		txn.Type.String(),
-		1, // Status - would come from receipt
-		0, // Gas used - would come from receipt
+		status,
+		gasUsed,
+		logs,
+		txn.Timestamp,
+		inclusionLatency(block, txn),
 	)
 	return err
 }
 
+// inclusionLatency is the seconds between the sender's timestamp and the
+// including block; NULL when the tx carries no usable timestamp
+func inclusionLatency(block *chain.Block, txn *tx.Transaction) *int64 {
+	if txn.Timestamp <= 0 || block.Header.Timestamp < txn.Timestamp {
+		return nil
+	}
+	latency := block.Header.Timestamp - txn.Timestamp
+	return &latency
+}
+
+// encodeIndexedPayload decodes a transaction's typed payload for the JSONB
+// payload column; types without a payload are stored as NULL
+func encodeIndexedPayload(txn *tx.Transaction) ([]byte, error) {
+	payload, err := tx.DecodePayload(txn)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
+// Rewind deletes the transactions indexed from fromBlock on
+func (ti *TransactionIndexer) Rewind(dbTx *sql.Tx, fromBlock uint64) error {
+	_, err := dbTx.Exec("DELETE FROM transactions WHERE block_number >= $1", fromBlock)
+	return err
+}
+
 // GetTransaction retrieves a transaction by hash
 func (ti *TransactionIndexer) GetTransaction(hash string) (*IndexedTransaction, error) {
 	txn := &IndexedTransaction{}
 	
 	err := ti.db.QueryRow(`
 		SELECT hash, block_number, block_hash, tx_index, from_address, to_address,
-		       value, asset, fee, nonce, data, signature, tx_type, status, gas_used, created_at
+		       value, asset, fee, nonce, data, COALESCE(memo, ''), payload, signature, tx_type,
+		       status, gas_used, inclusion_latency_secs, created_at
 		FROM transactions WHERE hash = $1
 	`, hash).Scan(
 		&txn.Hash, &txn.BlockNumber, &txn.BlockHash, &txn.TxIndex,
 		&txn.From, &txn.To, &txn.Value, &txn.Asset, &txn.Fee, &txn.Nonce,
-		&txn.Data, &txn.Signature, &txn.Type, &txn.Status, &txn.GasUsed, &txn.CreatedAt,
+		&txn.Data, &txn.Memo, &txn.Payload, &txn.Signature, &txn.Type, &txn.Status, &txn.GasUsed,
+		&txn.InclusionLatency, &txn.CreatedAt,
 	)
 	
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return txn, err
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := attachTransactionLabels(ti.db, []*IndexedTransaction{txn}); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// GetReceipt rebuilds the node receipt of an indexed transaction
+func (ti *TransactionIndexer) GetReceipt(hash string) (*rpc.TransactionReceiptResponse, error) {
+	receipt := &rpc.TransactionReceiptResponse{TransactionHash: hash}
+	var to sql.NullString
+	var logs []byte
+	
+	err := ti.db.QueryRow(`
+		SELECT block_number, block_hash, tx_index, from_address, to_address,
+		       status, gas_used, logs
+		FROM transactions WHERE hash = $1
+	`, hash).Scan(
+		&receipt.BlockNumber, &receipt.BlockHash, &receipt.TxIndex, &receipt.From, &to,
+		&receipt.Status, &receipt.GasUsed, &logs,
+	)
+	
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	receipt.To = to.String
+	receipt.Logs = make([]rpc.LogResponse, 0)
+	if len(logs) > 0 {
+		if err := json.Unmarshal(logs, &receipt.Logs); err != nil {
+			return nil, err
+		}
+	}
+	return receipt, nil
 }
 
 // GetTransactionsByBlock retrieves transactions for a block
 func (ti *TransactionIndexer) GetTransactionsByBlock(blockNumber uint64) ([]*IndexedTransaction, error) {
 	rows, err := ti.db.Query(`
 		SELECT hash, block_number, block_hash, tx_index, from_address, to_address,
-		       value, asset, fee, nonce, tx_type, status, gas_used, created_at
+		       value, asset, fee, nonce, COALESCE(memo, ''), tx_type, status, gas_used, created_at
 		FROM transactions
 		WHERE block_number = $1
 		ORDER BY tx_index ASC
@@ -86,7 +177,7 @@ func (ti *TransactionIndexer) GetTransactionsByBlock(blockNumber uint64) ([]*Ind
 func (ti *TransactionIndexer) GetRecentTransactions(limit int) ([]*IndexedTransaction, error) {
 	rows, err := ti.db.Query(`
 		SELECT hash, block_number, block_hash, tx_index, from_address, to_address,
-		       value, asset, fee, nonce, tx_type, status, gas_used, created_at
+		       value, asset, fee, nonce, COALESCE(memo, ''), tx_type, status, gas_used, created_at
 		FROM transactions
 		ORDER BY block_number DESC, tx_index DESC
 		LIMIT $1
@@ -99,11 +190,31 @@ func (ti *TransactionIndexer) GetRecentTransactions(limit int) ([]*IndexedTransa
 	return ti.scanTransactions(rows)
 }
 
+// GetTransactionsByDateRange retrieves transactions included in blocks
+// timestamped in [from, to), newest first. Filtering on block_time lets
+// Postgres skip every monthly partition outside the range
+func (ti *TransactionIndexer) GetTransactionsByDateRange(from, to time.Time, limit, offset int) ([]*IndexedTransaction, error) {
+	rows, err := ti.db.Query(`
+		SELECT hash, block_number, block_hash, tx_index, from_address, to_address,
+		       value, asset, fee, nonce, COALESCE(memo, ''), tx_type, status, gas_used, created_at
+		FROM transactions
+		WHERE block_time >= $1 AND block_time < $2
+		ORDER BY block_time DESC, block_number DESC, tx_index DESC
+		LIMIT $3 OFFSET $4
+	`, from, to, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	return ti.scanTransactions(rows)
+}
+
 // GetTransactionsByType retrieves transactions by type
 func (ti *TransactionIndexer) GetTransactionsByType(txType string, limit, offset int) ([]*IndexedTransaction, error) {
 	rows, err := ti.db.Query(`
 		SELECT hash, block_number, block_hash, tx_index, from_address, to_address,
-		       value, asset, fee, nonce, tx_type, status, gas_used, created_at
+		       value, asset, fee, nonce, COALESCE(memo, ''), tx_type, status, gas_used, created_at
 		FROM transactions
 		WHERE tx_type = $1
 		ORDER BY block_number DESC, tx_index DESC
@@ -134,28 +245,37 @@ func (ti *TransactionIndexer) GetTransactionCountByAddress(address string) (uint
 	return count, err
 }
 
-// GetDailyTransactionStats returns daily transaction statistics
-func (ti *TransactionIndexer) GetDailyTransactionStats(days int) ([]*DailyStats, error) {
+// GetInclusionStats returns time-to-inclusion percentiles per fee quartile
+// so fee estimates can be checked against how fast each tier confirmed
+func (ti *TransactionIndexer) GetInclusionStats(days int) ([]*InclusionStats, error) {
 	rows, err := ti.db.Query(`
-		SELECT 
-			DATE(created_at) as date,
-			COUNT(*) as tx_count,
-			SUM(CAST(value AS NUMERIC)) as total_value,
-			SUM(CAST(fee AS NUMERIC)) as total_fees
-		FROM transactions
-		WHERE created_at >= NOW() - INTERVAL '1 day' * $1
-		GROUP BY DATE(created_at)
-		ORDER BY date DESC
+		SELECT
+			quartile,
+			COUNT(*) AS tx_count,
+			MIN(fee_num)::TEXT AS min_fee,
+			MAX(fee_num)::TEXT AS max_fee,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY inclusion_latency_secs) AS p50,
+			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY inclusion_latency_secs) AS p95,
+			MAX(inclusion_latency_secs) AS max_latency
+		FROM (
+			SELECT CAST(fee AS NUMERIC) AS fee_num, inclusion_latency_secs,
+			       NTILE(4) OVER (ORDER BY CAST(fee AS NUMERIC)) AS quartile
+			FROM transactions
+			WHERE inclusion_latency_secs IS NOT NULL
+			  AND created_at >= NOW() - INTERVAL '1 day' * $1
+		) t
+		GROUP BY quartile
+		ORDER BY quartile ASC
 	`, days)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	
-	var stats []*DailyStats
+	var stats []*InclusionStats
 	for rows.Next() {
-		s := &DailyStats{}
-		if err := rows.Scan(&s.Date, &s.TxCount, &s.TotalValue, &s.TotalFees); err != nil {
+		s := &InclusionStats{}
+		if err := rows.Scan(&s.FeeQuartile, &s.TxCount, &s.MinFee, &s.MaxFee, &s.P50, &s.P95, &s.Max); err != nil {
 			return nil, err
 		}
 		stats = append(stats, s)
@@ -172,12 +292,16 @@ func (ti *TransactionIndexer) scanTransactions(rows *sql.Rows) ([]*IndexedTransa
 		if err := rows.Scan(
 			&txn.Hash, &txn.BlockNumber, &txn.BlockHash, &txn.TxIndex,
 			&txn.From, &txn.To, &txn.Value, &txn.Asset, &txn.Fee, &txn.Nonce,
-			&txn.Type, &txn.Status, &txn.GasUsed, &txn.CreatedAt,
+			&txn.Memo, &txn.Type, &txn.Status, &txn.GasUsed, &txn.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
 		txs = append(txs, txn)
 	}
+	
+	if err := attachTransactionLabels(ti.db, txs); err != nil {
+		return nil, err
+	}
 	return txs, nil
 }
 
@@ -188,23 +312,31 @@ type IndexedTransaction struct {
 	BlockHash   string  `json:"block_hash"`
 	TxIndex     int     `json:"tx_index"`
 	From        string  `json:"from"`
+	FromLabel   *Label  `json:"from_label,omitempty"`
 	To          *string `json:"to,omitempty"`
+	ToLabel     *Label  `json:"to_label,omitempty"`
 	Value       string  `json:"value"`
 	Asset       string  `json:"asset"`
 	Fee         string  `json:"fee"`
 	Nonce       uint64  `json:"nonce"`
 	Data        []byte  `json:"data,omitempty"`
+	Memo        string  `json:"memo,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
 	Signature   string  `json:"signature"`
 	Type        string  `json:"type"`
 	Status      int     `json:"status"`
 	GasUsed     uint64  `json:"gas_used"`
+	InclusionLatency *int64 `json:"inclusion_latency_secs,omitempty"`
 	CreatedAt   string  `json:"created_at"`
 }
 
-// DailyStats represents daily transaction statistics
-type DailyStats struct {
-	Date       string `json:"date"`
-	TxCount    uint64 `json:"tx_count"`
-	TotalValue string `json:"total_value"`
-	TotalFees  string `json:"total_fees"`
+// InclusionStats summarizes inclusion latency (seconds) for one fee quartile
+type InclusionStats struct {
+	FeeQuartile int     `json:"fee_quartile"` // 1 = cheapest
+	TxCount     uint64  `json:"tx_count"`
+	MinFee      string  `json:"min_fee"`
+	MaxFee      string  `json:"max_fee"`
+	P50         float64 `json:"p50_secs"`
+	P95         float64 `json:"p95_secs"`
+	Max         int64   `json:"max_secs"`
 }
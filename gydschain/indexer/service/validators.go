@@ -0,0 +1,406 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Validator is an indexed validator with its block and stake statistics
+type Validator struct {
+	Address          string    `json:"address"`
+	Moniker          string    `json:"moniker,omitempty"`
+	Stake            *util.Big `json:"stake"`
+	Commission       uint64    `json:"commission"` // basis points
+	Active           bool      `json:"active"`
+	Jailed           bool      `json:"jailed"`
+	JailedUntil      int64     `json:"jailed_until,omitempty"` // height
+	BlocksProposed   uint64    `json:"blocks_proposed"`
+	BlocksSigned     uint64    `json:"blocks_signed"`
+	BlocksMissed     uint64    `json:"blocks_missed"`
+	UptimePercentage float64   `json:"uptime_percentage"`
+	SlashingEvents   int       `json:"slashing_events"`
+	DelegatorCount   int       `json:"delegator_count"`
+	TotalDelegations *util.Big `json:"total_delegations"`
+	CreatedBlock     uint64    `json:"created_block"`
+}
+
+// Delegation is stake a delegator has bonded to a validator
+type Delegation struct {
+	Delegator    string    `json:"delegator"`
+	Validator    string    `json:"validator"`
+	Amount       *util.Big `json:"amount"`
+	CreatedBlock uint64    `json:"created_block"`
+}
+
+// ValidatorSlash is a slashing event recorded against a validator
+type ValidatorSlash struct {
+	BlockNumber uint64    `json:"block_number"`
+	Reason      string    `json:"reason"`
+	Amount      *util.Big `json:"amount"`
+	Jailed      bool      `json:"jailed"`
+}
+
+// ValidatorIndexer keeps per-validator block statistics, stake,
+// delegations and slashing events
+type ValidatorIndexer struct {
+	db *sql.DB
+}
+
+// NewValidatorIndexer creates a new validator indexer
+func NewValidatorIndexer(db *sql.DB) *ValidatorIndexer {
+	return &ValidatorIndexer{db: db}
+}
+
+// UpdateFromBlock credits the block's proposer, and its signature when the
+// block carries one
+func (vi *ValidatorIndexer) UpdateFromBlock(dbTx *sql.Tx, block *chain.Block) error {
+	signed := 0
+	if len(block.Signature) > 0 {
+		signed = 1
+	}
+	_, err := dbTx.Exec(`
+		UPDATE validators
+		SET blocks_proposed = blocks_proposed + 1,
+			blocks_signed = blocks_signed + $2,
+			updated_at = NOW()
+		WHERE address = $1
+	`, block.Header.Validator, signed)
+	return err
+}
+
+// UpdateFromTransaction applies stake and unstake transactions. Staking to
+// oneself (or to no one) bonds the sender as a validator and may set its
+// commission and moniker; staking to another address delegates to it.
+func (vi *ValidatorIndexer) UpdateFromTransaction(dbTx *sql.Tx, txn *tx.Transaction, blockNumber uint64) error {
+	if txn.Type != tx.TxTypeStake && txn.Type != tx.TxTypeUnstake {
+		return nil
+	}
+	bond := txn.Type == tx.TxTypeStake
+	validator := txn.To
+	if validator == "" {
+		validator = txn.From
+	}
+
+	_, err := dbTx.Exec(`
+		INSERT INTO validators (address, stake, created_block)
+		VALUES ($1, '0', $2)
+		ON CONFLICT (address) DO NOTHING
+	`, validator, blockNumber)
+	if err != nil {
+		return err
+	}
+
+	if validator == txn.From {
+		if bond {
+			if err := vi.updateProfile(dbTx, txn); err != nil {
+				return err
+			}
+		}
+		return vi.addStake(dbTx, validator, txn.Amount.String(), bond)
+	}
+	return vi.delegate(dbTx, txn.From, validator, txn.Amount.String(), blockNumber, bond)
+}
+
+// updateProfile sets the commission and moniker carried by a self-bond
+func (vi *ValidatorIndexer) updateProfile(dbTx *sql.Tx, txn *tx.Transaction) error {
+	payload, err := tx.DecodePayload(txn)
+	if err != nil || payload == nil {
+		return err
+	}
+	p := payload.(*tx.StakePayload)
+	_, err = dbTx.Exec(`
+		UPDATE validators
+		SET commission = $2, moniker = COALESCE(NULLIF($3, ''), moniker), updated_at = NOW()
+		WHERE address = $1
+	`, txn.From, p.Commission, p.Moniker)
+	return err
+}
+
+// addStake adds amount to (or takes it from) a validator's stake
+func (vi *ValidatorIndexer) addStake(dbTx *sql.Tx, validator, amount string, bond bool) error {
+	operator := "-"
+	if bond {
+		operator = "+"
+	}
+	_, err := dbTx.Exec(`
+		UPDATE validators
+		SET stake = (CAST(stake AS NUMERIC) `+operator+` CAST($2 AS NUMERIC))::TEXT,
+			updated_at = NOW()
+		WHERE address = $1
+	`, validator, amount)
+	return err
+}
+
+// delegate bonds or unbonds a delegator's stake with a validator. A
+// delegation unbonded to zero is removed.
+func (vi *ValidatorIndexer) delegate(dbTx *sql.Tx, delegator, validator, amount string, blockNumber uint64, bond bool) error {
+	operator := "-"
+	if bond {
+		operator = "+"
+	}
+	stmts := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO delegations (delegator, validator, amount, created_block)
+		  VALUES ($1, $2, '0', $3)
+		  ON CONFLICT (delegator, validator) DO NOTHING`,
+			[]interface{}{delegator, validator, blockNumber}},
+		{`UPDATE delegations
+		  SET amount = (CAST(amount AS NUMERIC) ` + operator + ` CAST($3 AS NUMERIC))::TEXT, updated_at = NOW()
+		  WHERE delegator = $1 AND validator = $2`,
+			[]interface{}{delegator, validator, amount}},
+		{`DELETE FROM delegations
+		  WHERE delegator = $1 AND validator = $2 AND CAST(amount AS NUMERIC) <= 0`,
+			[]interface{}{delegator, validator}},
+		{`UPDATE validators
+		  SET stake = (CAST(stake AS NUMERIC) ` + operator + ` CAST($2 AS NUMERIC))::TEXT,
+		      total_delegations = (CAST(total_delegations AS NUMERIC) ` + operator + ` CAST($2 AS NUMERIC))::TEXT,
+		      delegator_count = (SELECT COUNT(*) FROM delegations WHERE validator = $1),
+		      updated_at = NOW()
+		  WHERE address = $1`,
+			[]interface{}{validator, amount}},
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateFromEpoch applies an epoch summary: each validator's stake and
+// active flag, its missed blocks summed over the stored epochs, and the
+// epoch's slashing events. It runs after the summary is stored and is
+// safe to repeat for the same epoch.
+func (vi *ValidatorIndexer) UpdateFromEpoch(dbTx *sql.Tx, summary *pos.EpochSummary) error {
+	for _, v := range summary.Validators {
+		_, err := dbTx.Exec(`
+			UPDATE validators
+			SET stake = $2, active = $3,
+				blocks_missed = (SELECT COALESCE(SUM(blocks_missed), 0) FROM epoch_validators WHERE address = $1),
+				updated_at = NOW()
+			WHERE address = $1
+		`, v.Address, v.Stake, v.Active)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := dbTx.Exec(
+		"DELETE FROM slashing_events WHERE block_number BETWEEN $1 AND $2",
+		summary.StartHeight, summary.EndHeight,
+	)
+	if err != nil {
+		return err
+	}
+	params := pos.DefaultSlashingParams()
+	for _, s := range summary.Slashes {
+		var jail uint64
+		switch s.Reason {
+		case pos.SlashReasonDoubleSign:
+			jail = params.DoubleSignJailBlocks
+		case pos.SlashReasonDowntime:
+			jail = params.DowntimeJailBlocks
+		}
+		_, err := dbTx.Exec(`
+			INSERT INTO slashing_events (validator, block_number, reason, amount, jailed)
+			SELECT $1, $2, $3, $4, $5
+			WHERE EXISTS (SELECT 1 FROM validators WHERE address = $1)
+		`, s.ValidatorAddress, s.Height, string(s.Reason), util.CopyBig(s.Amount).String(), jail > 0)
+		if err != nil {
+			return err
+		}
+		if jail > 0 {
+			_, err := dbTx.Exec(`
+				UPDATE validators SET jailed = TRUE, jailed_until = $2, updated_at = NOW()
+				WHERE address = $1
+			`, s.ValidatorAddress, int64(s.Height+jail))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE validators v
+		SET slashing_events = (SELECT COUNT(*) FROM slashing_events s WHERE s.validator = v.address)
+	`)
+	return err
+}
+
+// Rewind reverses the validator activity of blocks from fromBlock on:
+// proposals, signatures, stake changes and delegations. It must run before
+// those blocks and their transactions are deleted.
+func (vi *ValidatorIndexer) Rewind(dbTx *sql.Tx, fromBlock uint64) error {
+	_, err := dbTx.Exec(`
+		UPDATE validators v
+		SET blocks_proposed = GREATEST(v.blocks_proposed - d.proposed, 0),
+			blocks_signed = GREATEST(v.blocks_signed - d.proposed, 0),
+			updated_at = NOW()
+		FROM (
+			SELECT validator, COUNT(*) AS proposed FROM blocks
+			WHERE number >= $1 GROUP BY validator
+		) d
+		WHERE v.address = d.validator
+	`, fromBlock)
+	if err != nil {
+		return err
+	}
+
+	// Net stake bonded per (staker, validator) in the abandoned blocks
+	bonded := `
+		SELECT from_address AS staker, COALESCE(NULLIF(to_address, ''), from_address) AS validator,
+		       SUM(CASE WHEN tx_type = $2 THEN CAST(value AS NUMERIC) ELSE -CAST(value AS NUMERIC) END) AS net
+		FROM transactions
+		WHERE block_number >= $1 AND tx_type IN ($2, $3)
+		GROUP BY 1, 2`
+	stmts := []string{
+		`UPDATE validators v
+		 SET stake = (CAST(v.stake AS NUMERIC) - d.net)::TEXT, updated_at = NOW()
+		 FROM (SELECT validator, SUM(net) AS net FROM (` + bonded + `) b GROUP BY validator) d
+		 WHERE v.address = d.validator`,
+		`UPDATE validators v
+		 SET total_delegations = (CAST(v.total_delegations AS NUMERIC) - d.net)::TEXT
+		 FROM (SELECT validator, SUM(net) AS net FROM (` + bonded + `) b
+		       WHERE staker <> validator GROUP BY validator) d
+		 WHERE v.address = d.validator`,
+		`UPDATE delegations x
+		 SET amount = (CAST(x.amount AS NUMERIC) - b.net)::TEXT, updated_at = NOW()
+		 FROM (` + bonded + `) b
+		 WHERE x.delegator = b.staker AND x.validator = b.validator`,
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt, fromBlock, tx.TxTypeStake, tx.TxTypeUnstake); err != nil {
+			return err
+		}
+	}
+
+	stmts = []string{
+		"DELETE FROM delegations WHERE created_block >= $1 OR CAST(amount AS NUMERIC) <= 0",
+		"DELETE FROM slashing_events WHERE block_number >= $1",
+		"DELETE FROM delegations WHERE validator IN (SELECT address FROM validators WHERE created_block >= $1)",
+		"DELETE FROM validators WHERE created_block >= $1",
+	}
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt, fromBlock); err != nil {
+			return err
+		}
+	}
+	_, err = dbTx.Exec(`
+		UPDATE validators v
+		SET delegator_count = (SELECT COUNT(*) FROM delegations x WHERE x.validator = v.address),
+			slashing_events = (SELECT COUNT(*) FROM slashing_events s WHERE s.validator = v.address)
+	`)
+	return err
+}
+
+// validatorColumns are the columns scanValidator reads
+const validatorColumns = `address, COALESCE(moniker, ''), stake, commission, active, jailed,
+	COALESCE(jailed_until, 0), blocks_proposed, blocks_signed, blocks_missed, slashing_events,
+	delegator_count, total_delegations, created_block`
+
+// scanValidator reads a validatorColumns row
+func scanValidator(row interface{ Scan(...interface{}) error }) (*Validator, error) {
+	v := &Validator{Stake: new(util.Big), TotalDelegations: new(util.Big)}
+	err := row.Scan(&v.Address, &v.Moniker, v.Stake, &v.Commission, &v.Active, &v.Jailed,
+		&v.JailedUntil, &v.BlocksProposed, &v.BlocksSigned, &v.BlocksMissed, &v.SlashingEvents,
+		&v.DelegatorCount, v.TotalDelegations, &v.CreatedBlock)
+	if err != nil {
+		return nil, err
+	}
+	if expected := v.BlocksProposed + v.BlocksMissed; expected > 0 {
+		v.UptimePercentage = float64(v.BlocksSigned) / float64(expected) * 100
+	}
+	return v, nil
+}
+
+// GetValidators lists validators by stake, optionally only active ones
+func (vi *ValidatorIndexer) GetValidators(activeOnly bool, limit, offset int) ([]*Validator, error) {
+	rows, err := vi.db.Query(`
+		SELECT `+validatorColumns+`
+		FROM validators
+		WHERE active = TRUE OR NOT $1
+		ORDER BY CAST(stake AS NUMERIC) DESC, address
+		LIMIT $2 OFFSET $3
+	`, activeOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var validators []*Validator
+	for rows.Next() {
+		v, err := scanValidator(rows)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, v)
+	}
+	return validators, rows.Err()
+}
+
+// GetValidator retrieves a validator by address; nil if it is not indexed
+func (vi *ValidatorIndexer) GetValidator(address string) (*Validator, error) {
+	v, err := scanValidator(vi.db.QueryRow(
+		"SELECT "+validatorColumns+" FROM validators WHERE address = $1", address,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return v, err
+}
+
+// GetDelegators lists the delegations to a validator, largest first
+func (vi *ValidatorIndexer) GetDelegators(validator string, limit, offset int) ([]*Delegation, error) {
+	rows, err := vi.db.Query(`
+		SELECT delegator, validator, amount, created_block
+		FROM delegations
+		WHERE validator = $1
+		ORDER BY CAST(amount AS NUMERIC) DESC, delegator
+		LIMIT $2 OFFSET $3
+	`, validator, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var delegations []*Delegation
+	for rows.Next() {
+		d := &Delegation{Amount: new(util.Big)}
+		if err := rows.Scan(&d.Delegator, &d.Validator, d.Amount, &d.CreatedBlock); err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, d)
+	}
+	return delegations, rows.Err()
+}
+
+// GetSlashes lists a validator's slashing events, newest first
+func (vi *ValidatorIndexer) GetSlashes(validator string, limit int) ([]*ValidatorSlash, error) {
+	rows, err := vi.db.Query(`
+		SELECT block_number, reason, amount, jailed
+		FROM slashing_events
+		WHERE validator = $1
+		ORDER BY block_number DESC
+		LIMIT $2
+	`, validator, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slashes []*ValidatorSlash
+	for rows.Next() {
+		s := &ValidatorSlash{Amount: new(util.Big)}
+		if err := rows.Scan(&s.BlockNumber, &s.Reason, s.Amount, &s.Jailed); err != nil {
+			return nil, err
+		}
+		slashes = append(slashes, s)
+	}
+	return slashes, rows.Err()
+}
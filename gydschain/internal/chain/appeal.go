@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrAppealNotConfigured is returned for appeal votes on a chain without a
+// circuit breaker to count them or a slashing keeper to act on them
+var ErrAppealNotConfigured = errors.New("slash appeals require the circuit breaker and slashing keeper")
+
+// processSlashAppeal records a validator or guardian vote to reverse an
+// escrowed slash; the slash is reversed once the votes meet the breaker's
+// quorum within its appeal window
+func (c *Chain) processSlashAppeal(transaction *tx.Transaction, height uint64) error {
+	if c.breaker == nil || c.slashing == nil {
+		return ErrAppealNotConfigured
+	}
+
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.SlashAppealPayload)
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if _, err := c.slashing.VoteReverse(c.breaker, transaction.From, p.EscrowID, height); err != nil {
+		return err
+	}
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// processAppealWindowVote records a validator or guardian vote on how many
+// blocks slashes stay reversible
+func (c *Chain) processAppealWindowVote(transaction *tx.Transaction) error {
+	if c.breaker == nil || c.slashing == nil {
+		return ErrAppealNotConfigured
+	}
+
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.AppealWindowPayload)
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if _, err := c.slashing.VoteAppealWindow(c.breaker, transaction.From, p.Blocks); err != nil {
+		return err
+	}
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// settleSlashes burns the stake of slashes whose appeal window has closed:
+// it comes off the delegations the validator and its delegators bonded and
+// out of the GYDS supply
+func (c *Chain) settleSlashes(escrows []*pos.SlashEscrow) {
+	for _, escrow := range escrows {
+		c.burnDelegation(escrow.ValidatorAddress, escrow.ValidatorAddress, escrow.SelfAmount)
+		for delegator, amount := range escrow.DelegatorAmounts {
+			c.burnDelegation(delegator, escrow.ValidatorAddress, amount)
+		}
+		c.burnSupply("GYDS", escrow.Total)
+	}
+}
+
+// burnDelegation removes up to amount of delegator's stake with validator
+// without returning it to the balance
+func (c *Chain) burnDelegation(delegator, validator string, amount *big.Int) {
+	account := c.stateDB.GetAccount(delegator)
+	if account == nil {
+		return
+	}
+	if delegated := account.GetDelegation(validator); delegated.Cmp(amount) < 0 {
+		amount = delegated
+	}
+	if amount.Sign() == 0 {
+		return
+	}
+	account.Unbond(validator, amount)
+	c.stateDB.SetAccount(delegator, account)
+}
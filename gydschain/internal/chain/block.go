@@ -4,15 +4,19 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"math/big"
 	"time"
 
+	"github.com/gydschain/gydschain/internal/crypto"
 	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // Block represents a complete block in the GYDS blockchain
 type Block struct {
 	Header       *Header          `json:"header"`
 	Transactions []*tx.Transaction `json:"transactions"`
+	Evidence     []*Evidence       `json:"evidence,omitempty"`
 	Validator    string           `json:"validator"`
 	Signature    []byte           `json:"signature"`
 }
@@ -79,9 +83,81 @@ func (b *Block) Verify() error {
 		return ErrInvalidTxRoot
 	}
 	
+	// Verify evidence root
+	if b.CalculateEvidenceRoot() != b.Header.EvidenceRoot {
+		return ErrInvalidEvidenceRoot
+	}
+	
+	return nil
+}
+
+// Sign signs the block hash with the proposing validator's key
+func (b *Block) Sign(key *crypto.KeyPair) error {
+	hash, err := b.Hash()
+	if err != nil {
+		return err
+	}
+	digest, err := hex.DecodeString(hash)
+	if err != nil {
+		return err
+	}
+	signature, err := key.Sign(digest)
+	if err != nil {
+		return err
+	}
+	b.Signature = signature
 	return nil
 }
 
+// VerifyHeaderSignature checks that signature is pubKey's signature of the
+// header hash, so light clients can check a proposer without the block body
+func VerifyHeaderSignature(header *Header, signature, pubKey []byte) error {
+	hash, err := header.Hash()
+	if err != nil {
+		return err
+	}
+	digest, err := hex.DecodeString(hash)
+	if err != nil {
+		return err
+	}
+	if !crypto.VerifySignature(pubKey, digest, signature) {
+		return ErrInvalidBlockSignature
+	}
+	return nil
+}
+
+// ProposerSchedule decides which validator may propose each round's block
+// and resolves the key it must sign with
+type ProposerSchedule interface {
+	ValidatorKeys
+	IsActive(address string) bool
+	LeaderAt(round uint64) (string, error)
+	BlockTime() time.Duration
+}
+
+// VerifyProposer checks that the block is signed by an active validator
+// leading the round in its header, and that the round is the slot its
+// timestamp falls in so a validator cannot pick a round it leads
+func (b *Block) VerifyProposer(proposers ProposerSchedule) error {
+	if len(b.Signature) == 0 {
+		return ErrUnsignedBlock
+	}
+	if !inRound(b.Header.Timestamp, b.Header.Round, proposers.BlockTime()) {
+		return ErrInvalidRound
+	}
+	pubKey, err := proposers.ValidatorPubKey(b.Validator)
+	if err != nil {
+		return ErrUnknownProposer
+	}
+	if !proposers.IsActive(b.Validator) {
+		return ErrInactiveProposer
+	}
+	if leader, err := proposers.LeaderAt(b.Header.Round); err != nil || leader != b.Validator {
+		return ErrNotLeader
+	}
+	return VerifyHeaderSignature(b.Header, b.Signature, pubKey)
+}
+
 // Size returns the approximate size of the block in bytes
 func (b *Block) Size() int {
 	data, _ := json.Marshal(b)
@@ -138,35 +214,85 @@ func merkleRoot(hashes [][]byte) []byte {
 	return merkleRoot(newLevel)
 }
 
+// TxProof returns the sibling hashes, leaf first, proving that the
+// transaction at index is committed by the block's TxRoot
+func (b *Block) TxProof(index int) ([][]byte, error) {
+	if index < 0 || index >= len(b.Transactions) {
+		return nil, ErrTxNotInBlock
+	}
+
+	level := make([][]byte, len(b.Transactions))
+	for i, transaction := range b.Transactions {
+		hash, err := transaction.Hash()
+		if err != nil {
+			return nil, err
+		}
+		level[i] = hash
+	}
+
+	// Mirror merkleRoot: odd levels repeat their last hash
+	var proof [][]byte
+	for len(level) > 1 {
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+		proof = append(proof, level[index^1])
+
+		next := make([][]byte, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			hash := sha256.Sum256(append(append([]byte{}, level[i]...), level[i+1]...))
+			next = append(next, hash[:])
+		}
+		level = next
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyTxProof checks that txHash at index is committed by txRoot
+func VerifyTxProof(txHash []byte, index int, proof [][]byte, txRoot string) error {
+	// The index must fit the proof depth or several indices verify alike
+	root, err := hex.DecodeString(txRoot)
+	if err != nil || index < 0 || index>>uint(len(proof)) != 0 {
+		return ErrInvalidTxProof
+	}
+	if !crypto.VerifyMerkleProof(txHash, proof, root, index) {
+		return ErrInvalidTxProof
+	}
+	return nil
+}
+
 // BlockReward contains reward information for a block
 type BlockReward struct {
-	Validator    string `json:"validator"`
-	GYDSReward   uint64 `json:"gyds_reward"`
-	GYDReward    uint64 `json:"gyd_reward"`
-	TotalFees    uint64 `json:"total_fees"`
-	MinerReward  uint64 `json:"miner_reward"`
-	BlockHeight  uint64 `json:"block_height"`
+	Validator    string    `json:"validator"`
+	GYDSReward   *util.Big `json:"gyds_reward"`
+	GYDReward    *util.Big `json:"gyd_reward"`
+	TotalFees    *util.Big `json:"total_fees"`
+	MinerReward  *util.Big `json:"miner_reward"`
+	BlockHeight  uint64    `json:"block_height"`
 }
 
 // CalculateReward computes the block reward
 func (b *Block) CalculateReward() *BlockReward {
-	baseReward := uint64(10 * 1e8) // 10 GYDS in smallest unit
+	baseReward := big.NewInt(10 * 1e8) // 10 GYDS in smallest unit
 	
 	// Calculate total fees
-	var totalFees uint64
+	totalFees := new(big.Int)
 	for _, tx := range b.Transactions {
-		totalFees += tx.Fee
+		totalFees.Add(totalFees, util.CopyBig(tx.Fee))
 	}
 	
 	// 80% to validator, 20% to miners
-	validatorReward := (totalFees * 80) / 100
-	minerReward := totalFees - validatorReward
+	validatorReward := new(big.Int).Mul(totalFees, big.NewInt(80))
+	validatorReward.Quo(validatorReward, big.NewInt(100))
+	minerReward := new(big.Int).Sub(totalFees, validatorReward)
 	
 	return &BlockReward{
 		Validator:   b.Validator,
-		GYDSReward:  baseReward + validatorReward,
-		TotalFees:   totalFees,
-		MinerReward: minerReward,
+		GYDSReward:  (*util.Big)(baseReward.Add(baseReward, validatorReward)),
+		GYDReward:   new(util.Big),
+		TotalFees:   (*util.Big)(totalFees),
+		MinerReward: (*util.Big)(minerReward),
 		BlockHeight: b.Header.Height,
 	}
 }
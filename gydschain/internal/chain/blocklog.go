@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// maxBlockLogLine bounds one encoded block in the log
+const maxBlockLogLine = 64 * 1024 * 1024
+
+// BlockLog appends every added block to a JSONL file so the chain can be
+// re-executed offline with `gydsnode replay`
+type BlockLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenBlockLog opens or creates the block log at path for appending
+func OpenBlockLog(path string) (*BlockLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockLog{f: f}, nil
+}
+
+// Append writes block as one line
+func (l *BlockLog) Append(block *Block) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return os.ErrClosed
+	}
+	_, err = l.f.Write(append(data, '\n'))
+	return err
+}
+
+// Close syncs the log to disk and closes it
+func (l *BlockLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Sync()
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
+
+// ReadBlockLog calls fn with each logged block in the order they were added,
+// stopping at the first error fn returns
+func ReadBlockLog(path string, fn func(*Block) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBlockLogLine)
+	for scanner.Scan() {
+		var block Block
+		if err := json.Unmarshal(scanner.Bytes(), &block); err != nil {
+			return err
+		}
+		if err := fn(&block); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -1,12 +1,17 @@
 package chain
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"sync"
+	"time"
 
+	"github.com/gydschain/gydschain/internal/consensus/pos"
 	"github.com/gydschain/gydschain/internal/state"
 	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 var (
@@ -15,6 +20,9 @@ var (
 	ErrInvalidParent     = errors.New("invalid parent block")
 	ErrDuplicateBlock    = errors.New("duplicate block")
 	ErrChainNotReady     = errors.New("chain not initialized")
+	ErrChainHalted       = errors.New("chain halted: only system transactions accepted")
+	ErrReceiptNotFound   = errors.New("receipt not found")
+	ErrUnsupportedTxType = errors.New("transaction type not supported by this node")
 )
 
 // Chain represents the blockchain state manager
@@ -22,20 +30,65 @@ type Chain struct {
 	mu           sync.RWMutex
 	blocks       map[string]*Block
 	heights      map[uint64]string
+	receipts     map[string]*tx.TransactionReceipt // by tx hash
 	latestHash   string
 	latestHeight uint64
 	genesis      *Block
 	stateDB      *state.StateDB
 	config       *ChainConfig
+	logIndex     *LogIndex
+	breaker      *pos.CircuitBreaker
+	beacon       *pos.RandomnessBeacon
+	oracle       *pos.PriceOracle
+	epochs       *pos.EpochTracker
+	finality     *pos.Finality
+	commits      map[string]*pos.CommitCertificate // by block hash
+	finalized    *FinalizedHead
+	checkpoints  map[uint64]*Checkpoint // finalized blocks every cpInterval blocks
+	cpInterval   uint64
+	dust         *DustThresholds
+	unbonding    *pos.UnbondingQueue
+	staking      Staking
+	blockReward  *big.Int // GYDS paid to stakers every block
+	rewards      RewardSource
+	evidence     *EvidencePool
+	slashing     *pos.SlashingKeeper
+	proposers    ProposerSchedule // decides who proposes each round; nil skips proposer checks
+	stablecoin   *Stablecoin
+	features     tx.Features
+	gas          *GasController
+	applyLatency *util.LatencyTracker
+	listeners    []BlockListener
+	tracer       TxTracer
 }
 
+// Revertible is a component blocks change outside the state DB. Snapshot
+// captures its state and returns a function restoring it.
+type Revertible interface {
+	Snapshot() func()
+}
+
+// Persistent is a Revertible whose state chain snapshots carry, so a node
+// restoring one resumes with it. Every component attached to a chain that
+// serves snapshots must be Persistent.
+type Persistent interface {
+	Revertible
+	ExportState() (json.RawMessage, error)
+	RestoreState(data json.RawMessage) error
+}
+
+// BlockListener is notified after a block is added to the chain; it runs
+// with the chain locked and must not block or call back into the chain
+type BlockListener func(block *Block, hash string, logs []*IndexedLog)
+
 // ChainConfig holds chain configuration
 type ChainConfig struct {
 	ChainID          string `json:"chain_id"`
 	NetworkID        uint64 `json:"network_id"`
 	BlockTime        uint64 `json:"block_time"`
 	MaxBlockSize     uint64 `json:"max_block_size"`
-	MaxTxPerBlock    uint64 `json:"max_tx_per_block"`
+	BlockGasLimit    uint64 `json:"block_gas_limit"`  // hard cap; blocks use up to twice the gas target
+	BlockGasTarget   uint64 `json:"block_gas_target"` // starting and minimum gas target
 	GYDSDecimals     uint8  `json:"gyds_decimals"`
 	GYDDecimals      uint8  `json:"gyd_decimals"`
 	StablecoinPeg    string `json:"stablecoin_peg"`
@@ -44,14 +97,15 @@ type ChainConfig struct {
 // DefaultConfig returns the default chain configuration
 func DefaultConfig() *ChainConfig {
 	return &ChainConfig{
-		ChainID:       "gydschain-1",
-		NetworkID:     1,
-		BlockTime:     5,
-		MaxBlockSize:  1024 * 1024, // 1MB
-		MaxTxPerBlock: 1000,
-		GYDSDecimals:  8,
-		GYDDecimals:   8,
-		StablecoinPeg: "USD",
+		ChainID:        "gydschain-1",
+		NetworkID:      1,
+		BlockTime:      5,
+		MaxBlockSize:   1024 * 1024, // 1MB
+		BlockGasLimit:  DefaultBlockGasLimit,
+		BlockGasTarget: DefaultBlockGasTarget,
+		GYDSDecimals:   8,
+		GYDDecimals:    8,
+		StablecoinPeg:  "USD",
 	}
 }
 
@@ -62,10 +116,18 @@ func NewChain(config *ChainConfig, stateDB *state.StateDB) (*Chain, error) {
 	}
 	
 	chain := &Chain{
-		blocks:  make(map[string]*Block),
-		heights: make(map[uint64]string),
-		stateDB: stateDB,
-		config:  config,
+		blocks:       make(map[string]*Block),
+		heights:      make(map[uint64]string),
+		receipts:     make(map[string]*tx.TransactionReceipt),
+		commits:      make(map[string]*pos.CommitCertificate),
+		checkpoints:  make(map[uint64]*Checkpoint),
+		cpInterval:   DefaultCheckpointInterval,
+		dust:         NewDustThresholds(),
+		blockReward:  new(big.Int),
+		stateDB:      stateDB,
+		config:       config,
+		gas:          NewGasController(config, nil),
+		applyLatency: util.NewLatencyTracker(util.DefaultLatencySamples),
 	}
 	
 	return chain, nil
@@ -87,33 +149,94 @@ func (c *Chain) InitGenesis(genesis *GenesisConfig) error {
 	c.heights[0] = hash
 	c.latestHash = hash
 	c.latestHeight = 0
+	c.finalized = &FinalizedHead{Height: 0, Hash: hash}
+	for asset, amount := range genesis.Params.MinTransfer {
+		c.dust.set(asset, amount.Int())
+	}
+	c.blockReward = genesis.BlockReward()
 	
 	// Initialize genesis accounts
 	for _, alloc := range genesis.Alloc {
 		account := state.NewAccount(alloc.Address)
 		account.SetBalance("GYDS", alloc.GYDSBalance)
 		account.SetBalance("GYD", alloc.GYDBalance)
+		if alloc.Vesting != nil {
+			schedule := alloc.Vesting.Schedule()
+			if err := schedule.Validate(); err != nil {
+				return err
+			}
+			account.SetVesting(schedule)
+		}
 		c.stateDB.SetAccount(alloc.Address, account)
 	}
 	
+	if _, err := c.stateDB.CommitAt(0); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
+// txError fails a block because of the transaction at index
+type txError struct {
+	index int
+	err   error
+}
+
+func (e *txError) Error() string {
+	return e.err.Error()
+}
+
 // AddBlock adds a validated block to the chain
 func (c *Chain) AddBlock(block *Block) error {
+	if err := c.addBlock(block); err != nil {
+		if failed, ok := err.(*txError); ok {
+			return failed.err
+		}
+		return err
+	}
+	return nil
+}
+
+// addBlock adds a block as AddBlock does, but reports a block failed by
+// one of its transactions as a *txError so the proposer can drop it
+func (c *Chain) addBlock(block *Block) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
+	start := time.Now()
+	
 	// Verify block
 	if err := block.Verify(); err != nil {
 		return err
 	}
 	
-	// Verify parent exists
+	// Every block after genesis must be signed by the round's leader
+	if c.proposers != nil && block.Header.Height > 0 {
+		if err := block.VerifyProposer(c.proposers); err != nil {
+			return err
+		}
+	}
+	
+	// Verify parent exists and the difficulty is retargeted from it
 	if block.Header.Height > 0 {
-		if _, exists := c.blocks[block.Header.ParentHash]; !exists {
+		parent, exists := c.blocks[block.Header.ParentHash]
+		if !exists {
 			return ErrInvalidParent
 		}
+		// Each round has one leader, so rounds advance with every block
+		if c.proposers != nil && block.Header.Round <= parent.Header.Round {
+			return ErrInvalidRound
+		}
+		if err := block.Header.ValidateDifficulty(parent.Header, c.parentHeader(parent), c.blockTime()); err != nil {
+			return err
+		}
+	}
+	
+	// The state root commits to the state left by the parent block, so it
+	// is checked before any of this block's transactions run
+	if block.Header.StateRoot != c.stateDB.Root() {
+		return ErrInvalidStateRoot
 	}
 	
 	// Check for duplicate
@@ -126,16 +249,44 @@ func (c *Chain) AddBlock(block *Block) error {
 		return ErrDuplicateBlock
 	}
 	
-	// Process transactions
-	for _, transaction := range block.Transactions {
-		if err := c.processTransaction(transaction); err != nil {
-			return err
-		}
+	// Blocks are sized by gas rather than transaction count
+	gasUsed := c.gas.BlockGas(block)
+	if gasUsed > c.gas.GasLimit() {
+		return ErrBlockGasExceeded
+	}
+	
+	// Blocks are built at the current base fee
+	baseFee := c.gas.BaseFee()
+	if block.Header.BaseFee != baseFee {
+		return ErrInvalidBaseFee
+	}
+	
+	// Evidence is checked up front so a block with bad evidence changes
+	// nothing
+	evidenceHashes, err := c.checkEvidence(block)
+	if err != nil {
+		return err
+	}
+	
+	// A block either applies whole or not at all: everything it may change
+	// is captured first and restored if any step fails
+	restore := c.snapshot()
+	receipts, burned, err := c.applyBlock(block, hash, baseFee, evidenceHashes)
+	if err != nil {
+		restore()
+		return err
 	}
 	
-	// Store block
+	if c.logIndex != nil {
+		c.logIndex.IndexBlock(block.Header.Height, hash, receipts)
+	}
+	
+	// Store block and its receipts
 	c.blocks[hash] = block
 	c.heights[block.Header.Height] = hash
+	for _, receipt := range receipts {
+		c.receipts[receipt.TxHash] = receipt
+	}
 	
 	// Update latest
 	if block.Header.Height > c.latestHeight {
@@ -143,11 +294,285 @@ func (c *Chain) AddBlock(block *Block) error {
 		c.latestHash = hash
 	}
 	
+	// Catch the proposer signing a competing block at this height
+	if c.evidence != nil {
+		c.evidence.Observe(block.Header, block.Signature, block.Validator)
+	}
+	
+	// A certificate may arrive before the block it finalizes
+	if cert, exists := c.commits[hash]; exists {
+		c.advanceFinalized(cert)
+	}
+	
+	c.gas.Record(block.Header.Height, gasUsed, burned)
+	c.applyLatency.Record(time.Since(start))
+	
+	if len(c.listeners) > 0 {
+		logs := blockLogs(block.Header.Height, hash, receipts)
+		for _, fn := range c.listeners {
+			fn(block, hash, logs)
+		}
+	}
 	return nil
 }
 
-// processTransaction executes a transaction and updates state
-func (c *Chain) processTransaction(transaction *tx.Transaction) error {
+// applyBlock runs a checked block's transactions and end-of-block updates
+// and commits the resulting state. It leaves partial changes behind on
+// failure; AddBlock restores them.
+func (c *Chain) applyBlock(block *Block, hash string, baseFee uint64, evidenceHashes []string) ([]*tx.TransactionReceipt, *big.Int, error) {
+	// Slashes the block triggers are stamped with its time
+	if c.slashing != nil {
+		c.slashing.SetBlockTime(block.Header.Timestamp)
+	}
+	
+	// Process transactions, burning the base fee share of each fee and
+	// paying the rest to the block's validator
+	receipts := make([]*tx.TransactionReceipt, 0, len(block.Transactions))
+	burned := new(big.Int)
+	for i, transaction := range block.Transactions {
+		base := tx.FeeForGas(c.gas.TxGas(transaction), baseFee)
+		if util.CopyBig(transaction.Fee).Cmp(base) < 0 {
+			return nil, nil, &txError{i, ErrFeeBelowBaseFee}
+		}
+		receipt, err := c.processTransaction(transaction, hash, block.Header, uint32(i))
+		if err != nil {
+			c.traceTx(block.Header.Height, uint32(i), transaction, err)
+			return nil, nil, &txError{i, err}
+		}
+		c.settleFee(transaction, base, block.Validator)
+		c.traceTx(block.Header.Height, uint32(i), transaction, nil)
+		if transaction.Asset == "GYDS" {
+			burned.Add(burned, base)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if util.CopyBig(block.Header.Burned).Cmp(burned) != 0 {
+		return nil, nil, ErrInvalidBurn
+	}
+	
+	// Slash the validators the block's evidence convicts of double signing
+	if len(block.Evidence) > 0 {
+		if err := c.evidence.commit(block.Evidence, evidenceHashes, block.Header.Height); err != nil {
+			return nil, nil, err
+		}
+	}
+	
+	// Pay the block reward to validators and their delegators by stake
+	if c.staking != nil && c.blockReward.Sign() > 0 {
+		c.staking.ProcessRewards(c.blockReward)
+	}
+	
+	// Return stake whose unbonding period ends at this height
+	c.releaseUnbondings(block.Header.Height)
+	
+	// Close escrowed slashes whose appeal window ends at this height; their
+	// stake already left the validator when it was slashed and is now
+	// burned from the delegations it was bonded as
+	if c.slashing != nil {
+		c.settleSlashes(c.slashing.SettleExpired(block.Header.Height))
+	}
+	
+	// Close the beacon epoch on its last block
+	if c.beacon != nil {
+		c.beacon.EndBlock(block.Header.Height)
+	}
+	
+	// Commit the oracle's median prices on the last block of its window
+	if c.oracle != nil {
+		if prices := c.oracle.EndBlock(block.Header.Height); len(prices) > 0 && c.stablecoin != nil {
+			c.stablecoin.commitPrices(prices, block.Header.Timestamp)
+		}
+	}
+	
+	// Record the epoch summary on its last block
+	if c.epochs != nil {
+		c.epochs.EndBlock(block.Header.Height)
+	}
+	
+	// Commit state so it can be queried as of this height
+	if _, err := c.stateDB.CommitAt(block.Header.Height); err != nil {
+		return nil, nil, err
+	}
+	return receipts, burned, nil
+}
+
+// snapshot captures the state and every component blocks change outside it,
+// and returns a function restoring them all
+func (c *Chain) snapshot() func() {
+	stateSnapshot := c.stateDB.Snapshot()
+	restores := []func(){func() { c.stateDB.Revert(stateSnapshot) }}
+	for _, comp := range c.components() {
+		restores = append(restores, comp.Snapshot())
+	}
+	
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+// component is a Revertible attached to the chain, with the name chain
+// snapshots carry its state under
+type component struct {
+	name string
+	Revertible
+}
+
+// components returns every component blocks change outside the state DB,
+// each once
+func (c *Chain) components() []component {
+	components := make([]component, 0, 10)
+	if c.breaker != nil {
+		components = append(components, component{"breaker", c.breaker})
+	}
+	if c.beacon != nil {
+		components = append(components, component{"beacon", c.beacon})
+	}
+	if c.oracle != nil {
+		components = append(components, component{"oracle", c.oracle})
+	}
+	if c.epochs != nil {
+		components = append(components, component{"epochs", c.epochs})
+	}
+	if c.unbonding != nil {
+		components = append(components, component{"unbonding", c.unbonding})
+	}
+	if c.stablecoin != nil {
+		components = append(components, component{"stablecoin", c.stablecoin})
+	}
+	if c.dust != nil {
+		components = append(components, component{"dust", c.dust})
+	}
+	if c.slashing != nil {
+		components = append(components, component{"slashing", c.slashing})
+	}
+	// The consensus engine holds stake and rewards; it is reached through
+	// the staking engine, the reward source and the evidence pool's keys
+	// and slasher
+	sources := []interface{}{c.staking, c.rewards}
+	if c.evidence != nil {
+		components = append(components, component{"evidence", c.evidence})
+		sources = append(sources, c.evidence.keys, c.evidence.slasher)
+	}
+	for _, source := range sources {
+		if r, ok := source.(Revertible); ok {
+			components = append(components, component{"engine", r})
+		}
+	}
+	
+	seen := make(map[Revertible]bool, len(components))
+	unique := components[:0]
+	for _, comp := range components {
+		if !seen[comp.Revertible] {
+			seen[comp.Revertible] = true
+			unique = append(unique, comp)
+		}
+	}
+	return unique
+}
+
+// OnBlock registers a listener for newly added blocks
+func (c *Chain) OnBlock(fn BlockListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// processTransaction executes a transaction and returns its receipt
+func (c *Chain) processTransaction(transaction *tx.Transaction, blockHash string, header *Header, index uint32) (*tx.TransactionReceipt, error) {
+	height := header.Height
+	if err := c.applyTransaction(transaction, height, header.Timestamp); err != nil {
+		return nil, err
+	}
+	
+	txHash, err := transaction.HashHex()
+	if err != nil {
+		return nil, err
+	}
+	receipt := tx.NewReceipt(txHash, blockHash, height, tx.ReceiptSuccess)
+	receipt.Index = index
+	receipt.From = transaction.From
+	receipt.To = transaction.To
+	receipt.GasUsed = c.gas.TxGas(transaction)
+	receipt.Logs = append(receipt.Logs, tx.Log{
+		Address: transaction.Asset,
+		Topics:  []string{transaction.Type, transaction.From, transaction.To},
+		Data:    []byte(util.CopyBig(transaction.Amount).String()),
+	})
+	return receipt, nil
+}
+
+// applyTransaction executes a transaction in the block at height, made at
+// blockTime, and updates state
+func (c *Chain) applyTransaction(transaction *tx.Transaction, height uint64, blockTime int64) error {
+	if c.breaker != nil && c.breaker.IsHalted() && !transaction.IsSystem() {
+		return ErrChainHalted
+	}
+	if err := c.features.Check(transaction); err != nil {
+		return err
+	}
+	if err := tx.CheckDust(c.dust, transaction); err != nil {
+		return err
+	}
+	if err := c.checkMultisig(transaction); err != nil {
+		return err
+	}
+	if !c.assetExists(transaction.Asset) {
+		return tx.ErrInvalidAsset
+	}
+	
+	switch transaction.Type {
+	case tx.TxTypeSetPolicy:
+		return c.processSetPolicy(transaction)
+	case tx.TxTypeHaltVote, tx.TxTypeResumeVote:
+		return c.processHaltVote(transaction, height)
+	case tx.TxTypeBeaconCommit, tx.TxTypeBeaconReveal:
+		return c.processBeacon(transaction, height)
+	case tx.TxTypeStake, tx.TxTypeUnstake:
+		return c.processStake(transaction, height)
+	case tx.TxTypeColdStake, tx.TxTypeColdUnstake:
+		return c.processColdStake(transaction, height)
+	case tx.TxTypeDustVote:
+		return c.processDustVote(transaction)
+	case tx.TxTypeSlashAppeal:
+		return c.processSlashAppeal(transaction, height)
+	case tx.TxTypeAppealWindow:
+		return c.processAppealWindowVote(transaction)
+	case tx.TxTypeWithdrawRewards:
+		return c.processWithdrawRewards(transaction)
+	case tx.TxTypeSetPayoutSplit:
+		return c.processSetPayoutSplit(transaction)
+	case tx.TxTypeUpdateOracle:
+		return c.processOracleUpdate(transaction, height)
+	case tx.TxTypeVaultLock:
+		// Collateral minting spendable GYD must come from vested GYDS
+		if sender := c.stateDB.GetAccount(transaction.From); sender != nil {
+			if err := checkVested(sender, transaction, blockTime); err != nil {
+				return err
+			}
+		}
+		return c.processVaultLock(transaction, height)
+	case tx.TxTypeVaultUnlock:
+		return c.processVaultUnlock(transaction, height)
+	case tx.TxTypeVaultLiquidate:
+		return c.processVaultLiquidate(transaction, height)
+	case tx.TxTypeCreateAsset:
+		return c.processCreateAsset(transaction)
+	case tx.TxTypeNFTMint:
+		return c.processNFTMint(transaction)
+	case tx.TxTypeNFTTransfer:
+		return c.processNFTTransfer(transaction)
+	case tx.TxTypeCreateMultisig:
+		return c.processCreateMultisig(transaction)
+	}
+	
+	// Enabled experimental types without a processor must not fall through
+	// to the transfer path
+	if tx.FeatureOf(transaction.Type) != "" {
+		return ErrUnsupportedTxType
+	}
+	
 	// Get sender account
 	sender := c.stateDB.GetAccount(transaction.From)
 	if sender == nil {
@@ -156,10 +581,30 @@ func (c *Chain) processTransaction(transaction *tx.Transaction) error {
 	
 	// Check balance
 	balance := sender.GetBalance(transaction.Asset)
-	if balance < transaction.Amount+transaction.Fee {
+	debit := new(big.Int).Add(transaction.Amount, util.CopyBig(transaction.Fee))
+	if balance.Cmp(debit) < 0 {
 		return errors.New("insufficient balance")
 	}
 	
+	// Unvested tokens cannot leave a vesting account
+	if err := checkVested(sender, transaction, blockTime); err != nil {
+		return err
+	}
+	
+	// Enforce the asset's transfer policy (whitelist, transfer tax)
+	policyFee := new(big.Int)
+	var feeSink string
+	if asset := c.stateDB.GetAsset(transaction.Asset); asset != nil {
+		fee, err := asset.ApplyTransferPolicy(transaction.From, transaction.To, transaction.Amount)
+		if err != nil {
+			return err
+		}
+		if fee.Sign() > 0 {
+			policyFee = fee
+			feeSink = asset.Policy.FeeSink
+		}
+	}
+	
 	// Get or create receiver account
 	receiver := c.stateDB.GetAccount(transaction.To)
 	if receiver == nil {
@@ -167,8 +612,8 @@ func (c *Chain) processTransaction(transaction *tx.Transaction) error {
 	}
 	
 	// Update balances
-	sender.SetBalance(transaction.Asset, balance-transaction.Amount-transaction.Fee)
-	receiver.SetBalance(transaction.Asset, receiver.GetBalance(transaction.Asset)+transaction.Amount)
+	sender.SubBalance(transaction.Asset, debit)
+	receiver.AddBalance(transaction.Asset, new(big.Int).Sub(transaction.Amount, policyFee))
 	
 	// Increment sender nonce
 	sender.IncrementNonce()
@@ -177,9 +622,388 @@ func (c *Chain) processTransaction(transaction *tx.Transaction) error {
 	c.stateDB.SetAccount(transaction.From, sender)
 	c.stateDB.SetAccount(transaction.To, receiver)
 	
+	// Route the transfer tax to the asset's fee sink
+	if policyFee.Sign() > 0 {
+		sink := c.stateDB.GetAccount(feeSink)
+		if sink == nil {
+			sink = state.NewAccount(feeSink)
+		}
+		sink.AddBalance(transaction.Asset, policyFee)
+		c.stateDB.SetAccount(feeSink, sink)
+	}
+	
+	return nil
+}
+
+// checkVested rejects a transaction whose amount and fee exceed the
+// sender's spendable balance at blockTime, leaving unvested tokens in place
+func checkVested(sender *state.Account, transaction *tx.Transaction, blockTime int64) error {
+	if sender.Vesting == nil {
+		return nil
+	}
+	debit := new(big.Int).Add(util.CopyBig(transaction.Amount), util.CopyBig(transaction.Fee))
+	if sender.Spendable(transaction.Asset, blockTime).Cmp(debit) < 0 {
+		return state.ErrVestingLocked
+	}
+	return nil
+}
+
+// assetExists reports whether id is a native asset or one created on chain
+func (c *Chain) assetExists(id string) bool {
+	return id == CollateralAsset || id == StablecoinAsset || c.stateDB.GetAsset(id) != nil
+}
+
+// processSetPolicy updates an asset's transfer policy. The target asset ID is
+// carried in To and the JSON policy in Data; empty Data clears the policy.
+func (c *Chain) processSetPolicy(transaction *tx.Transaction) error {
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	
+	asset := c.stateDB.GetAsset(transaction.To)
+	if asset == nil {
+		return state.ErrAssetNotFound
+	}
+	
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	policy, _ := payload.(*state.TransferPolicy)
+	
+	updated := asset.Copy()
+	if err := updated.SetTransferPolicy(transaction.From, policy, transaction.Timestamp); err != nil {
+		return err
+	}
+	
+	c.stateDB.SetAccount(transaction.From, sender)
+	c.stateDB.SetAsset(updated.ID, updated)
+	
+	return nil
+}
+
+// processCreateAsset registers the asset described by a CreateAssetPayload
+// under its symbol, owned by the sender, and credits the sender with Amount
+// of it as the initial supply. The fee is paid in the transaction's Asset.
+func (c *Chain) processCreateAsset(transaction *tx.Transaction) error {
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.CreateAssetPayload)
+
+	if p.Symbol == CollateralAsset || p.Symbol == StablecoinAsset || c.stateDB.GetAsset(p.Symbol) != nil {
+		return state.ErrAssetExists
+	}
+	maxSupply := p.MaxSupply.Int()
+	if maxSupply.Sign() > 0 && transaction.Amount.Cmp(maxSupply) > 0 {
+		return state.ErrExceedsMaxSupply
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+
+	var asset *state.Asset
+	if p.Stablecoin {
+		asset = state.NewStablecoin(p.Symbol, p.Name, p.Symbol, transaction.From)
+		asset.Metadata = &state.AssetMetadata{Properties: map[string]string{"peg": p.Peg}}
+	} else {
+		asset = state.NewFungibleAsset(p.Symbol, p.Name, p.Symbol, p.Decimals, transaction.From)
+	}
+	asset.Decimals = p.Decimals
+	asset.TotalSupply = util.CopyBig(transaction.Amount)
+	asset.MaxSupply = maxSupply
+	asset.Mintable = p.Mintable
+	asset.Burnable = p.Burnable
+	asset.Pausable = p.Pausable
+	// Every node must derive the same asset, so stamp it with the
+	// transaction's time rather than the local clock
+	asset.CreatedAt = transaction.Timestamp
+	asset.UpdatedAt = transaction.Timestamp
+
+	sender.AddBalance(asset.ID, transaction.Amount)
+	c.stateDB.SetAccount(transaction.From, sender)
+	c.stateDB.SetAsset(asset.ID, asset)
+
+	return nil
+}
+
+// processHaltVote records a validator or guardian vote on the circuit breaker.
+// Halt votes carry the reason in a HaltVotePayload.
+func (c *Chain) processHaltVote(transaction *tx.Transaction, height uint64) error {
+	if c.breaker == nil {
+		return errors.New("circuit breaker not configured")
+	}
+	
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	
+	if transaction.Type == tx.TxTypeHaltVote {
+		var payload tx.Payload
+		if payload, err = tx.DecodePayload(transaction); err == nil {
+			_, err = c.breaker.VoteHalt(transaction.From, payload.(*tx.HaltVotePayload).Reason, height)
+		}
+	} else {
+		_, err = c.breaker.VoteResume(transaction.From)
+	}
+	if err != nil {
+		return err
+	}
+	
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// processBeacon records a validator's randomness commitment or reveal
+func (c *Chain) processBeacon(transaction *tx.Transaction, height uint64) error {
+	if c.beacon == nil {
+		return errors.New("randomness beacon not configured")
+	}
+	
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	
+	switch p := payload.(type) {
+	case *tx.BeaconCommitPayload:
+		err = c.beacon.Commit(transaction.From, p.Epoch, p.Commitment, height)
+	case *tx.BeaconRevealPayload:
+		secret, _ := hex.DecodeString(p.Secret)
+		err = c.beacon.Reveal(transaction.From, p.Epoch, secret, height)
+	}
+	if err != nil {
+		return err
+	}
+	
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// processOracleUpdate records a validator's price vote
+func (c *Chain) processOracleUpdate(transaction *tx.Transaction, height uint64) error {
+	if c.oracle == nil {
+		return errors.New("price oracle not configured")
+	}
+	
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.OracleUpdatePayload)
+	
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if err := c.oracle.Vote(transaction.From, p.AssetID, p.Window, p.Price, height); err != nil {
+		return err
+	}
+	
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// processColdStake delegates or undelegates a cold address's funds on behalf
+// of the hot key that signed the transaction. The hot key pays the fee; the
+// stake only ever moves between the cold address's balance and delegations,
+// passing through the unbonding queue on the way back.
+func (c *Chain) processColdStake(transaction *tx.Transaction, height uint64) error {
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	auth := payload.(*tx.ColdStakePayload).Authorization
+	if err := auth.Permits(transaction.From, transaction.To, transaction.Amount, height); err != nil {
+		return err
+	}
+	
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	
+	cold := c.stateDB.GetAccount(auth.Cold)
+	if cold == nil {
+		return errors.New("cold account not found")
+	}
+	
+	if transaction.Type == tx.TxTypeColdStake {
+		err = c.bond(cold, auth.Cold, transaction.To, transaction.Amount)
+	} else {
+		err = c.unbond(cold, auth.Cold, transaction.To, transaction.Amount, height)
+	}
+	if err != nil {
+		return err
+	}
+	
+	c.stateDB.SetAccount(transaction.From, sender)
+	c.stateDB.SetAccount(auth.Cold, cold)
 	return nil
 }
 
+// chargeFee deducts the fee and bumps the nonce for transactions that move no
+// funds. The caller saves the returned account once the transaction succeeds.
+func (c *Chain) chargeFee(transaction *tx.Transaction) (*state.Account, error) {
+	sender := c.stateDB.GetAccount(transaction.From)
+	if sender == nil {
+		return nil, errors.New("sender account not found")
+	}
+	
+	if !sender.SubBalance(transaction.Asset, transaction.Fee) {
+		return nil, errors.New("insufficient balance")
+	}
+	sender.IncrementNonce()
+	return sender, nil
+}
+
+// settleFee burns base from the fee's asset supply and credits the rest of
+// the fee to the block's validator
+func (c *Chain) settleFee(transaction *tx.Transaction, base *big.Int, validator string) {
+	c.burnSupply(transaction.Asset, base)
+	
+	tip := new(big.Int).Sub(util.CopyBig(transaction.Fee), base)
+	if tip.Sign() <= 0 || validator == "" {
+		return
+	}
+	account := c.stateDB.GetAccount(validator)
+	if account == nil {
+		account = state.NewAccount(validator)
+	}
+	account.AddBalance(transaction.Asset, tip)
+	c.stateDB.SetAccount(validator, account)
+}
+
+// burnSupply removes amount from the asset's recorded supply, if it has one
+func (c *Chain) burnSupply(assetID string, amount *big.Int) {
+	asset := c.stateDB.GetAsset(assetID)
+	if asset == nil {
+		return
+	}
+	supply := new(big.Int).Sub(util.CopyBig(asset.TotalSupply), amount)
+	if supply.Sign() < 0 {
+		supply.SetUint64(0)
+	}
+	asset.TotalSupply = supply
+	c.stateDB.SetAsset(assetID, asset)
+}
+
+// SetLogIndex attaches an on-node log index that is updated as blocks are added
+func (c *Chain) SetLogIndex(index *LogIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logIndex = index
+}
+
+// SetProposerSchedule attaches the schedule blocks are checked against;
+// once set, AddBlock rejects blocks not signed by their round's leader
+func (c *Chain) SetProposerSchedule(proposers ProposerSchedule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.proposers = proposers
+}
+
+// SetCircuitBreaker attaches the emergency halt circuit breaker
+func (c *Chain) SetCircuitBreaker(breaker *pos.CircuitBreaker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.breaker = breaker
+}
+
+// SetSlashingKeeper attaches the keeper whose escrowed slashes blocks settle
+// and appeal votes reverse
+func (c *Chain) SetSlashingKeeper(keeper *pos.SlashingKeeper) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slashing = keeper
+}
+
+// SlashingKeeper returns the attached slashing keeper, if any
+func (c *Chain) SlashingKeeper() *pos.SlashingKeeper {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.slashing
+}
+
+// SetBeacon attaches the randomness beacon fed by beacon transactions
+func (c *Chain) SetBeacon(beacon *pos.RandomnessBeacon) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.beacon = beacon
+}
+
+// Beacon returns the attached randomness beacon, if any
+func (c *Chain) Beacon() *pos.RandomnessBeacon {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.beacon
+}
+
+// SetOracle attaches the price oracle fed by validator oracle votes
+func (c *Chain) SetOracle(oracle *pos.PriceOracle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.oracle = oracle
+}
+
+// Oracle returns the attached price oracle, if any
+func (c *Chain) Oracle() *pos.PriceOracle {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.oracle
+}
+
+// SetEpochTracker attaches the tracker that summarizes each epoch
+func (c *Chain) SetEpochTracker(epochs *pos.EpochTracker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epochs = epochs
+}
+
+// EpochTracker returns the attached epoch tracker, if any
+func (c *Chain) EpochTracker() *pos.EpochTracker {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.epochs
+}
+
+// SetFeatures sets the experimental features whose transactions are
+// executed; every node on a network must enable the same set
+func (c *Chain) SetFeatures(features tx.Features) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.features = features
+}
+
+// Gas returns the controller sizing blocks and setting the base fee
+func (c *Chain) Gas() *GasController {
+	return c.gas
+}
+
+// CircuitBreaker returns the attached circuit breaker, if any
+func (c *Chain) CircuitBreaker() *pos.CircuitBreaker {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.breaker
+}
+
+// LogIndex returns the attached log index, if any
+func (c *Chain) LogIndex() *LogIndex {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.logIndex
+}
+
 // GetBlock returns a block by hash
 func (c *Chain) GetBlock(hash string) (*Block, error) {
 	c.mu.RLock()
@@ -206,6 +1030,19 @@ func (c *Chain) GetBlockByHeight(height uint64) (*Block, error) {
 	return c.blocks[hash], nil
 }
 
+// GetReceipt returns the receipt of an executed transaction by its hash
+func (c *Chain) GetReceipt(txHash string) (*tx.TransactionReceipt, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	receipt, exists := c.receipts[txHash]
+	if !exists {
+		return nil, ErrReceiptNotFound
+	}
+	
+	return receipt, nil
+}
+
 // LatestBlock returns the most recent block
 func (c *Chain) LatestBlock() (*Block, error) {
 	c.mu.RLock()
@@ -242,17 +1079,24 @@ func (c *Chain) Export() ([]byte, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
-	export := struct {
-		Config *ChainConfig   `json:"config"`
-		Blocks []*Block       `json:"blocks"`
-	}{
-		Config: c.config,
-		Blocks: make([]*Block, 0, len(c.blocks)),
+	export := &ChainExport{
+		Config:   c.config,
+		Blocks:   make([]*Block, 0, len(c.blocks)),
+		Receipts: make([]*tx.TransactionReceipt, 0, len(c.receipts)),
 	}
 	
 	for i := uint64(0); i <= c.latestHeight; i++ {
-		if hash, exists := c.heights[i]; exists {
-			export.Blocks = append(export.Blocks, c.blocks[hash])
+		hash, exists := c.heights[i]
+		if !exists {
+			continue
+		}
+		block := c.blocks[hash]
+		export.Blocks = append(export.Blocks, block)
+		for _, transaction := range block.Transactions {
+			txHash, _ := transaction.HashHex()
+			if receipt, ok := c.receipts[txHash]; ok {
+				export.Receipts = append(export.Receipts, receipt)
+			}
 		}
 	}
 	
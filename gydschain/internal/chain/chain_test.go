@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// newTestChain returns a chain past genesis in which each address holds
+// 1e12 GYDS, with a mempool to propose its blocks from
+func newTestChain(t *testing.T, addresses ...string) (*Chain, *tx.Mempool) {
+	t.Helper()
+	c, err := NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(testGenesis(addresses...)); err != nil {
+		t.Fatal(err)
+	}
+	mempool := tx.NewMempool(nil)
+	t.Cleanup(mempool.Stop)
+	return c, mempool
+}
+
+// testGenesis returns the default genesis without dust limits, funding
+// each address with GYDS
+func testGenesis(addresses ...string) *GenesisConfig {
+	genesis := DefaultGenesis()
+	genesis.Params.MinTransfer = nil
+	genesis.Alloc = make([]AllocConfig, len(addresses))
+	for i, address := range addresses {
+		genesis.Alloc[i] = AllocConfig{Address: address, GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)}
+	}
+	return genesis
+}
+
+// testTx returns a signed GYDS transaction paying the standard test fee
+func testTx(txType, from, to string, amount int64, nonce uint64) *tx.Transaction {
+	transaction := tx.NewTransaction(txType, from, to, big.NewInt(amount), "GYDS")
+	transaction.Nonce = nonce
+	transaction.Fee = big.NewInt(1e9)
+	transaction.Sign([]byte("key"))
+	return transaction
+}
+
+// addTestBlock proposes a block from mempool holding txs and adds it to c
+func addTestBlock(t *testing.T, c *Chain, mempool *tx.Mempool, txs ...*tx.Transaction) error {
+	t.Helper()
+	for _, transaction := range txs {
+		if err := mempool.AddTx(transaction); err != nil {
+			t.Fatal(err)
+		}
+	}
+	block := c.ProposeBlock(mempool, "gyds1validator")
+	if err := c.AddBlock(block); err != nil {
+		for _, transaction := range txs {
+			hash, _ := transaction.HashHex()
+			mempool.RemoveTx(hash)
+		}
+		return err
+	}
+	mempool.Update(block.Header.Height, block.Transactions)
+	return nil
+}
@@ -0,0 +1,121 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// DefaultCheckpointInterval is the number of blocks between checkpoints
+const DefaultCheckpointInterval = 100
+
+// Checkpoint errors
+var (
+	ErrCheckpointNotFound  = errors.New("checkpoint not found")
+	ErrInvalidCheckpoint   = errors.New("invalid checkpoint")
+	ErrUntrustedCheckpoint = errors.New("checkpoint is not signed by 2/3 of the trusted validators")
+)
+
+// Checkpoint is a finalized block every CheckpointInterval blocks, with
+// the commit certificate that finalized it and the validator set that
+// signed it. Clients that only hold headers verify it themselves and never
+// roll back past it.
+type Checkpoint struct {
+	Height     uint64                 `json:"height"`
+	BlockHash  string                 `json:"block_hash"`
+	Commit     *pos.CommitCertificate `json:"commit"`
+	Validators []*pos.ValidatorKey    `json:"validators"`
+}
+
+// Verify checks the checkpoint's commit against its validator set. If
+// trusted is given, the set must agree with those keys and more than 2/3
+// of the trusted validators must have signed, so a node cannot vouch for
+// a checkpoint with a validator set of its own making.
+func (cp *Checkpoint) Verify(trusted map[string][]byte) error {
+	if cp.Commit == nil || cp.Commit.Height != cp.Height || cp.Commit.BlockHash != cp.BlockHash {
+		return ErrInvalidCheckpoint
+	}
+	if err := pos.VerifyCommitWith(cp.Commit, cp.Validators); err != nil {
+		return err
+	}
+	if len(trusted) == 0 {
+		return nil
+	}
+
+	for _, v := range cp.Validators {
+		key, known := trusted[v.Address]
+		if !known {
+			continue
+		}
+		listed, err := crypto.ParsePublicKey(v.PubKey)
+		if err != nil || !bytes.Equal(listed, key) {
+			return ErrUntrustedCheckpoint
+		}
+	}
+	signed := 0
+	for _, vote := range cp.Commit.Precommits {
+		if _, known := trusted[vote.Validator]; known {
+			signed++
+		}
+	}
+	if signed*3 <= len(trusted)*2 {
+		return ErrUntrustedCheckpoint
+	}
+	return nil
+}
+
+// SetCheckpointInterval sets the blocks between checkpoints; 0 disables
+// them
+func (c *Chain) SetCheckpointInterval(interval uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cpInterval = interval
+}
+
+// recordCheckpoint keeps cert's block as a checkpoint if it falls on the
+// interval; callers must hold c.mu
+func (c *Chain) recordCheckpoint(cert *pos.CommitCertificate) {
+	if c.cpInterval == 0 || cert.Height == 0 || cert.Height%c.cpInterval != 0 || c.finality == nil {
+		return
+	}
+	if _, exists := c.checkpoints[cert.Height]; exists {
+		return
+	}
+	c.checkpoints[cert.Height] = &Checkpoint{
+		Height:     cert.Height,
+		BlockHash:  cert.BlockHash,
+		Commit:     cert,
+		Validators: c.finality.ValidatorKeys(),
+	}
+}
+
+// GetCheckpoint returns the checkpoint at height
+func (c *Chain) GetCheckpoint(height uint64) (*Checkpoint, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cp, exists := c.checkpoints[height]
+	if !exists {
+		return nil, ErrCheckpointNotFound
+	}
+	return cp, nil
+}
+
+// LatestCheckpoint returns the highest checkpoint
+func (c *Chain) LatestCheckpoint() (*Checkpoint, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var latest *Checkpoint
+	for _, cp := range c.checkpoints {
+		if latest == nil || cp.Height > latest.Height {
+			latest = cp
+		}
+	}
+	if latest == nil {
+		return nil, ErrCheckpointNotFound
+	}
+	return latest, nil
+}
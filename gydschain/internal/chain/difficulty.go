@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/consensus/pow"
+)
+
+// ErrInvalidDifficulty is returned for a header whose difficulty is not
+// the one retargeted from its parents
+var ErrInvalidDifficulty = errors.New("invalid block difficulty")
+
+// ExpectedDifficulty returns the difficulty of the block after parent,
+// retargeted towards blockTime from the interval between parent and
+// grandparent. The block after genesis, which has no grandparent, keeps
+// the genesis difficulty.
+func ExpectedDifficulty(parent, grandparent *Header, blockTime time.Duration) uint64 {
+	difficulty := parent.Difficulty
+	if difficulty < 1 {
+		difficulty = 1
+	}
+	if grandparent == nil {
+		return difficulty
+	}
+	interval := time.Duration(parent.Timestamp-grandparent.Timestamp) * time.Second
+	if interval < 0 {
+		interval = 0
+	}
+	return pow.DifficultyAdjustment(difficulty, interval, blockTime)
+}
+
+// ValidateDifficulty checks the header's difficulty against the one
+// retargeted from its parent and grandparent
+func (h *Header) ValidateDifficulty(parent, grandparent *Header, blockTime time.Duration) error {
+	if h.Difficulty != ExpectedDifficulty(parent, grandparent, blockTime) {
+		return ErrInvalidDifficulty
+	}
+	return nil
+}
+
+// DifficultyInfo reports the difficulty of the chain head and of the
+// block that will follow it
+type DifficultyInfo struct {
+	Height         uint64 `json:"height"`
+	Difficulty     uint64 `json:"difficulty"`
+	NextDifficulty uint64 `json:"next_difficulty"`
+}
+
+// Difficulty returns the current and next block difficulty
+func (c *Chain) Difficulty() *DifficultyInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	head := c.blocks[c.latestHash]
+	if head == nil {
+		return &DifficultyInfo{}
+	}
+	return &DifficultyInfo{
+		Height:         c.latestHeight,
+		Difficulty:     head.Header.Difficulty,
+		NextDifficulty: c.expectedDifficulty(head),
+	}
+}
+
+// expectedDifficulty returns the difficulty of the block after parent; the
+// caller holds c.mu
+func (c *Chain) expectedDifficulty(parent *Block) uint64 {
+	return ExpectedDifficulty(parent.Header, c.parentHeader(parent), c.blockTime())
+}
+
+// parentHeader returns the header of b's parent, or nil for genesis; the
+// caller holds c.mu
+func (c *Chain) parentHeader(b *Block) *Header {
+	if b.Header.Height == 0 {
+		return nil
+	}
+	if parent := c.blocks[b.Header.ParentHash]; parent != nil {
+		return parent.Header
+	}
+	return nil
+}
+
+// blockTime returns the configured target interval between blocks
+func (c *Chain) blockTime() time.Duration {
+	return time.Duration(c.config.BlockTime) * time.Second
+}
@@ -0,0 +1,230 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// ErrDustVoteNotConfigured is returned for dust votes on a chain without a
+// circuit breaker to count them
+var ErrDustVoteNotConfigured = errors.New("dust threshold voting requires the circuit breaker")
+
+// DustProposal is a proposed minimum for an asset and who has voted for it
+type DustProposal struct {
+	Asset     string    `json:"asset"`
+	MinAmount *util.Big `json:"min_amount"`
+	Voters    []string  `json:"voters"`
+}
+
+// DustStatus reports the minimum transfer amounts in force and the
+// proposals still short of a quorum
+type DustStatus struct {
+	Minimums  map[string]*util.Big `json:"minimums"`
+	Proposals []*DustProposal      `json:"proposals"`
+}
+
+// DustThresholds holds the per-asset minimum transfer amounts. They start
+// from genesis and change when validators or guardians reach the same
+// quorum as the circuit breaker on a dust vote.
+type DustThresholds struct {
+	mu       sync.RWMutex
+	minimums map[string]*big.Int
+	votes    map[string]map[string]string // asset -> voter -> proposed minimum
+}
+
+// NewDustThresholds creates thresholds with no minimums
+func NewDustThresholds() *DustThresholds {
+	return &DustThresholds{
+		minimums: make(map[string]*big.Int),
+		votes:    make(map[string]map[string]string),
+	}
+}
+
+// MinTransfer returns the minimum transfer amount for asset, or nil
+func (d *DustThresholds) MinTransfer(asset string) *big.Int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.minimums[asset]
+}
+
+// set replaces the minimum for asset; zero removes it
+func (d *DustThresholds) set(asset string, amount *big.Int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.setLocked(asset, amount)
+}
+
+func (d *DustThresholds) setLocked(asset string, amount *big.Int) {
+	if amount == nil || amount.Sign() == 0 {
+		delete(d.minimums, asset)
+		return
+	}
+	d.minimums[asset] = util.CopyBig(amount)
+}
+
+// vote records voter's proposed minimum for asset and applies it once the
+// voters for that exact amount meet the breaker's quorum. It returns true
+// if this vote changed the minimum.
+func (d *DustThresholds) vote(breaker *pos.CircuitBreaker, voter, asset string, amount *big.Int) (bool, error) {
+	if !breaker.CanVote(voter) {
+		return false, pos.ErrNotHaltVoter
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	votes := d.votes[asset]
+	if votes == nil {
+		votes = make(map[string]string)
+		d.votes[asset] = votes
+	}
+	proposed := amount.String()
+	votes[voter] = proposed
+
+	voters := make([]string, 0, len(votes))
+	for v, value := range votes {
+		if value == proposed {
+			voters = append(voters, v)
+		}
+	}
+	if !breaker.QuorumMet(voters) {
+		return false, nil
+	}
+
+	d.setLocked(asset, amount)
+	delete(d.votes, asset)
+	return true, nil
+}
+
+// Snapshot captures the minimums and votes and returns a function restoring
+// them
+func (d *DustThresholds) Snapshot() func() {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	minimums := make(map[string]*big.Int, len(d.minimums))
+	for asset, amount := range d.minimums {
+		minimums[asset] = amount
+	}
+	votes := make(map[string]map[string]string, len(d.votes))
+	for asset, byVoter := range d.votes {
+		votes[asset] = make(map[string]string, len(byVoter))
+		for voter, value := range byVoter {
+			votes[asset][voter] = value
+		}
+	}
+
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.minimums, d.votes = minimums, votes
+	}
+}
+
+// dustState is the thresholds' exported state
+type dustState struct {
+	Minimums util.BigMap                  `json:"minimums"`
+	Votes    map[string]map[string]string `json:"votes"`
+}
+
+// ExportState encodes the minimums and votes for chain snapshots
+func (d *DustThresholds) ExportState() (json.RawMessage, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return json.Marshal(dustState{util.BigMap(d.minimums), d.votes})
+}
+
+// RestoreState replaces the minimums and votes with exported ones
+func (d *DustThresholds) RestoreState(data json.RawMessage) error {
+	var state dustState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Minimums == nil {
+		state.Minimums = make(util.BigMap)
+	}
+	if state.Votes == nil {
+		state.Votes = make(map[string]map[string]string)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.minimums, d.votes = state.Minimums, state.Votes
+	return nil
+}
+
+// Status returns the minimums in force and the pending proposals
+func (d *DustThresholds) Status() *DustStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	status := &DustStatus{
+		Minimums:  make(map[string]*util.Big, len(d.minimums)),
+		Proposals: make([]*DustProposal, 0),
+	}
+	for asset, amount := range d.minimums {
+		status.Minimums[asset] = (*util.Big)(util.CopyBig(amount))
+	}
+
+	for asset, votes := range d.votes {
+		byAmount := make(map[string]*DustProposal)
+		for voter, value := range votes {
+			proposal := byAmount[value]
+			if proposal == nil {
+				amount, _ := new(big.Int).SetString(value, 10)
+				proposal = &DustProposal{Asset: asset, MinAmount: (*util.Big)(amount)}
+				byAmount[value] = proposal
+				status.Proposals = append(status.Proposals, proposal)
+			}
+			proposal.Voters = append(proposal.Voters, voter)
+		}
+	}
+	for _, proposal := range status.Proposals {
+		sort.Strings(proposal.Voters)
+	}
+	sort.Slice(status.Proposals, func(i, j int) bool {
+		a, b := status.Proposals[i], status.Proposals[j]
+		if a.Asset != b.Asset {
+			return a.Asset < b.Asset
+		}
+		return a.MinAmount.Int().Cmp(b.MinAmount.Int()) < 0
+	})
+	return status
+}
+
+// processDustVote records a validator or guardian vote on an asset's
+// minimum transfer amount
+func (c *Chain) processDustVote(transaction *tx.Transaction) error {
+	if c.breaker == nil {
+		return ErrDustVoteNotConfigured
+	}
+
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.DustVotePayload)
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if _, err := c.dust.vote(c.breaker, transaction.From, p.Asset, p.MinAmount.Int()); err != nil {
+		return err
+	}
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// Dust returns the per-asset minimum transfer amounts
+func (c *Chain) Dust() *DustThresholds {
+	return c.dust
+}
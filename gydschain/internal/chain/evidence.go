@@ -0,0 +1,469 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// MaxEvidenceAge is how many blocks after a double sign its evidence may
+// still be included; older evidence is rejected
+const MaxEvidenceAge = 100000
+
+// MaxBlockEvidence bounds the evidence a proposer includes in one block
+const MaxBlockEvidence = 16
+
+// Evidence errors
+var (
+	ErrInvalidEvidence       = errors.New("invalid double-sign evidence")
+	ErrEvidenceTooOld        = errors.New("evidence too old")
+	ErrEvidenceCommitted     = errors.New("evidence already committed")
+	ErrInvalidEvidenceRoot   = errors.New("invalid evidence root")
+	ErrEvidenceNotConfigured = errors.New("block evidence requires an evidence pool")
+)
+
+// ValidatorKeys resolves a validator address to its ed25519 public key
+type ValidatorKeys interface {
+	ValidatorPubKey(address string) ([]byte, error)
+}
+
+// DoubleSignSlasher punishes a validator proven to have double signed at
+// infraction by evidence in the block at height
+type DoubleSignSlasher interface {
+	HandleDoubleSign(address string, infraction, height uint64) error
+}
+
+// Evidence proves a validator signed two different headers at one height.
+// HeaderA always hashes below HeaderB so each double sign has one form.
+type Evidence struct {
+	Validator  string  `json:"validator"`
+	Height     uint64  `json:"height"`
+	HeaderA    *Header `json:"header_a"`
+	SignatureA []byte  `json:"signature_a"`
+	HeaderB    *Header `json:"header_b"`
+	SignatureB []byte  `json:"signature_b"`
+}
+
+// NewEvidence pairs two signed headers from validator in canonical order
+func NewEvidence(validator string, a *Header, sigA []byte, b *Header, sigB []byte) (*Evidence, error) {
+	hashA, err := a.Hash()
+	if err != nil {
+		return nil, err
+	}
+	hashB, err := b.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if hashB < hashA {
+		a, sigA, b, sigB = b, sigB, a, sigA
+	}
+	return &Evidence{
+		Validator:  validator,
+		Height:     a.Height,
+		HeaderA:    a,
+		SignatureA: sigA,
+		HeaderB:    b,
+		SignatureB: sigB,
+	}, nil
+}
+
+// Hash identifies the evidence by validator, height and the two header hashes
+func (e *Evidence) Hash() (string, error) {
+	if e.HeaderA == nil || e.HeaderB == nil {
+		return "", ErrInvalidEvidence
+	}
+	hashA, err := e.HeaderA.Hash()
+	if err != nil {
+		return "", err
+	}
+	hashB, err := e.HeaderB.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	var height [8]byte
+	binary.BigEndian.PutUint64(height[:], e.Height)
+	h := sha256.New()
+	h.Write([]byte(e.Validator))
+	h.Write(height[:])
+	h.Write([]byte(hashA))
+	h.Write([]byte(hashB))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks that both headers are at the evidence height, differ, are
+// in canonical order and carry the validator's signature
+func (e *Evidence) Verify(keys ValidatorKeys) error {
+	if e.HeaderA == nil || e.HeaderB == nil ||
+		e.HeaderA.Height != e.Height || e.HeaderB.Height != e.Height {
+		return ErrInvalidEvidence
+	}
+	hashA, err := e.HeaderA.Hash()
+	if err != nil {
+		return err
+	}
+	hashB, err := e.HeaderB.Hash()
+	if err != nil {
+		return err
+	}
+	if hashA >= hashB {
+		return ErrInvalidEvidence
+	}
+
+	pubKey, err := keys.ValidatorPubKey(e.Validator)
+	if err != nil {
+		return ErrInvalidEvidence
+	}
+	if VerifyHeaderSignature(e.HeaderA, e.SignatureA, pubKey) != nil ||
+		VerifyHeaderSignature(e.HeaderB, e.SignatureB, pubKey) != nil {
+		return ErrInvalidEvidence
+	}
+	return nil
+}
+
+// signedHeader is a header seen from its proposer
+type signedHeader struct {
+	header    *Header
+	hash      string
+	signature []byte
+}
+
+// EvidencePool collects verified double-sign evidence from peers and from
+// conflicting blocks, hands it to proposers and slashes it when a block
+// commits it. Committed evidence is remembered until it is too old to be
+// included again.
+type EvidencePool struct {
+	mu        sync.Mutex
+	keys      ValidatorKeys
+	slasher   DoubleSignSlasher
+	pending   map[string]*Evidence
+	committed map[string]uint64                   // hash -> evidence height
+	seen      map[uint64]map[string]*signedHeader // height -> validator -> first header
+	listeners []func(*Evidence)
+}
+
+// NewEvidencePool creates a pool checking signatures against keys and
+// punishing through slasher
+func NewEvidencePool(keys ValidatorKeys, slasher DoubleSignSlasher) *EvidencePool {
+	return &EvidencePool{
+		keys:      keys,
+		slasher:   slasher,
+		pending:   make(map[string]*Evidence),
+		committed: make(map[string]uint64),
+		seen:      make(map[uint64]map[string]*signedHeader),
+	}
+}
+
+// OnEvidence registers a listener for newly found evidence, e.g. to gossip
+// it; listeners must not block
+func (p *EvidencePool) OnEvidence(fn func(*Evidence)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, fn)
+}
+
+// Add verifies evidence and queues it for inclusion. It returns true if the
+// evidence was new, so the caller relays it.
+func (p *EvidencePool) Add(ev *Evidence) (bool, error) {
+	if err := ev.Verify(p.keys); err != nil {
+		return false, err
+	}
+	hash, err := ev.Hash()
+	if err != nil {
+		return false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.committed[hash]; ok {
+		return false, nil
+	}
+	if _, ok := p.pending[hash]; ok {
+		return false, nil
+	}
+	p.pending[hash] = ev
+	for _, fn := range p.listeners {
+		fn(ev)
+	}
+	return true, nil
+}
+
+// Observe records a signed header from its proposer and, when the proposer
+// already signed a different header at that height, queues the evidence
+func (p *EvidencePool) Observe(header *Header, signature []byte, validator string) {
+	pubKey, err := p.keys.ValidatorPubKey(validator)
+	if err != nil || VerifyHeaderSignature(header, signature, pubKey) != nil {
+		return
+	}
+	hash, err := header.Hash()
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	byValidator := p.seen[header.Height]
+	if byValidator == nil {
+		byValidator = make(map[string]*signedHeader)
+		p.seen[header.Height] = byValidator
+	}
+	first := byValidator[validator]
+	if first == nil {
+		byValidator[validator] = &signedHeader{header: header, hash: hash, signature: signature}
+	}
+	p.mu.Unlock()
+
+	if first == nil || first.hash == hash {
+		return
+	}
+	ev, err := NewEvidence(validator, first.header, first.signature, header, signature)
+	if err != nil {
+		return
+	}
+	p.Add(ev)
+}
+
+// Pending returns up to max pending evidence items, oldest height first
+func (p *EvidencePool) Pending(max int) []*Evidence {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hashes := make([]string, 0, len(p.pending))
+	for hash := range p.pending {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		a, b := p.pending[hashes[i]], p.pending[hashes[j]]
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		return hashes[i] < hashes[j]
+	})
+	if len(hashes) > max {
+		hashes = hashes[:max]
+	}
+
+	evidence := make([]*Evidence, len(hashes))
+	for i, hash := range hashes {
+		evidence[i] = p.pending[hash]
+	}
+	return evidence
+}
+
+// check verifies evidence included in a block at height
+func (p *EvidencePool) check(ev *Evidence, height uint64) (string, error) {
+	if ev.Height >= height {
+		return "", ErrInvalidEvidence
+	}
+	if height-ev.Height > MaxEvidenceAge {
+		return "", ErrEvidenceTooOld
+	}
+	if err := ev.Verify(p.keys); err != nil {
+		return "", err
+	}
+	hash, err := ev.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.committed[hash]; ok {
+		return "", ErrEvidenceCommitted
+	}
+	return hash, nil
+}
+
+// commit slashes the validator behind each evidence item of a block at
+// height and forgets what is now too old to matter
+func (p *EvidencePool) commit(evidence []*Evidence, hashes []string, height uint64) error {
+	for i, ev := range evidence {
+		if err := p.slasher.HandleDoubleSign(ev.Validator, ev.Height, height); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.committed[hashes[i]] = ev.Height
+		delete(p.pending, hashes[i])
+		p.mu.Unlock()
+	}
+
+	if height <= MaxEvidenceAge {
+		return nil
+	}
+	cutoff := height - MaxEvidenceAge
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for hash, h := range p.committed {
+		if h < cutoff {
+			delete(p.committed, hash)
+		}
+	}
+	for hash, ev := range p.pending {
+		if ev.Height < cutoff {
+			delete(p.pending, hash)
+		}
+	}
+	for h := range p.seen {
+		if h < cutoff {
+			delete(p.seen, h)
+		}
+	}
+	return nil
+}
+
+// Snapshot captures the pending and committed evidence and returns a
+// function restoring them. The validators the slasher punishes are
+// captured separately.
+func (p *EvidencePool) Snapshot() func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending := make(map[string]*Evidence, len(p.pending))
+	for hash, ev := range p.pending {
+		pending[hash] = ev
+	}
+	committed := make(map[string]uint64, len(p.committed))
+	for hash, h := range p.committed {
+		committed[hash] = h
+	}
+	seen := make(map[uint64]map[string]*signedHeader, len(p.seen))
+	for h, byValidator := range p.seen {
+		seen[h] = make(map[string]*signedHeader, len(byValidator))
+		for v, header := range byValidator {
+			seen[h][v] = header
+		}
+	}
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.pending, p.committed, p.seen = pending, committed, seen
+	}
+}
+
+// evidenceState is the pool's exported state
+type evidenceState struct {
+	Pending   map[string]*Evidence                   `json:"pending"`
+	Committed map[string]uint64                      `json:"committed"`
+	Seen      map[uint64]map[string]*seenHeaderState `json:"seen"`
+}
+
+// seenHeaderState is the first header seen from a validator at a height
+type seenHeaderState struct {
+	Header    *Header `json:"header"`
+	Hash      string  `json:"hash"`
+	Signature []byte  `json:"signature"`
+}
+
+// ExportState encodes the pending and committed evidence and the headers
+// conflicting ones are checked against, for chain snapshots
+func (p *EvidencePool) ExportState() (json.RawMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seen := make(map[uint64]map[string]*seenHeaderState, len(p.seen))
+	for h, byValidator := range p.seen {
+		seen[h] = make(map[string]*seenHeaderState, len(byValidator))
+		for v, header := range byValidator {
+			seen[h][v] = &seenHeaderState{header.header, header.hash, header.signature}
+		}
+	}
+	return json.Marshal(evidenceState{p.pending, p.committed, seen})
+}
+
+// RestoreState replaces the evidence and seen headers with exported ones
+func (p *EvidencePool) RestoreState(data json.RawMessage) error {
+	var state evidenceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Pending == nil {
+		state.Pending = make(map[string]*Evidence)
+	}
+	if state.Committed == nil {
+		state.Committed = make(map[string]uint64)
+	}
+	for _, ev := range state.Pending {
+		if ev == nil {
+			return ErrInvalidSnapshot
+		}
+	}
+	seen := make(map[uint64]map[string]*signedHeader, len(state.Seen))
+	for h, byValidator := range state.Seen {
+		seen[h] = make(map[string]*signedHeader, len(byValidator))
+		for v, header := range byValidator {
+			if header == nil || header.Header == nil {
+				return ErrInvalidSnapshot
+			}
+			seen[h][v] = &signedHeader{header: header.Header, hash: header.Hash, signature: header.Signature}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending, p.committed, p.seen = state.Pending, state.Committed, seen
+	return nil
+}
+
+// CalculateEvidenceRoot computes the merkle root of the block's evidence,
+// or "" for a block without any so older headers hash the same
+func (b *Block) CalculateEvidenceRoot() string {
+	if len(b.Evidence) == 0 {
+		return ""
+	}
+
+	hashes := make([][]byte, 0, len(b.Evidence))
+	for _, ev := range b.Evidence {
+		hash, _ := ev.Hash()
+		raw, _ := hex.DecodeString(hash)
+		hashes = append(hashes, raw)
+	}
+	return hex.EncodeToString(merkleRoot(hashes))
+}
+
+// SetEvidencePool attaches the pool that verifies and slashes block
+// evidence; without one, blocks carrying evidence are rejected
+func (c *Chain) SetEvidencePool(pool *EvidencePool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evidence = pool
+}
+
+// EvidencePool returns the attached evidence pool, if any
+func (c *Chain) EvidencePool() *EvidencePool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.evidence
+}
+
+// checkEvidence verifies a block's evidence before any of it is applied,
+// returning the evidence hashes; callers must hold c.mu
+func (c *Chain) checkEvidence(block *Block) ([]string, error) {
+	if len(block.Evidence) == 0 {
+		return nil, nil
+	}
+	if c.evidence == nil {
+		return nil, ErrEvidenceNotConfigured
+	}
+	if len(block.Evidence) > MaxBlockEvidence {
+		return nil, ErrInvalidEvidence
+	}
+
+	hashes := make([]string, len(block.Evidence))
+	seen := make(map[string]bool, len(block.Evidence))
+	for i, ev := range block.Evidence {
+		hash, err := c.evidence.check(ev, block.Header.Height)
+		if err != nil {
+			return nil, err
+		}
+		if seen[hash] {
+			return nil, ErrEvidenceCommitted
+		}
+		seen[hash] = true
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
@@ -0,0 +1,96 @@
+package chain
+
+import (
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+)
+
+// Finality errors
+var (
+	ErrFinalityNotConfigured = errors.New("finality not configured")
+	ErrCommitNotFound        = errors.New("commit certificate not found")
+)
+
+// FinalizedHead is the highest block with a commit certificate. Genesis is
+// final without one.
+type FinalizedHead struct {
+	Height uint64                 `json:"height"`
+	Hash   string                 `json:"hash"`
+	Commit *pos.CommitCertificate `json:"commit,omitempty"`
+}
+
+// SetFinality attaches the prevote/precommit tracker whose certificates
+// finalize blocks
+func (c *Chain) SetFinality(finality *pos.Finality) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finality = finality
+}
+
+// Finality returns the attached finality tracker, if any
+func (c *Chain) Finality() *pos.Finality {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.finality
+}
+
+// AddCommit verifies a commit certificate and stores it with the block it
+// finalizes. A certificate for a block not yet added is kept until the
+// block arrives.
+func (c *Chain) AddCommit(cert *pos.CommitCertificate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.finality == nil {
+		return ErrFinalityNotConfigured
+	}
+	if err := c.finality.Verify(cert); err != nil {
+		return err
+	}
+	if _, exists := c.commits[cert.BlockHash]; exists {
+		return nil
+	}
+
+	c.commits[cert.BlockHash] = cert
+	if _, exists := c.blocks[cert.BlockHash]; exists {
+		c.advanceFinalized(cert)
+	}
+	return nil
+}
+
+// advanceFinalized moves the finalized head to cert's block if it is the
+// canonical block at a higher height; callers must hold c.mu
+func (c *Chain) advanceFinalized(cert *pos.CommitCertificate) {
+	if c.heights[cert.Height] != cert.BlockHash {
+		return
+	}
+	if c.finalized != nil && cert.Height <= c.finalized.Height {
+		return
+	}
+	c.finalized = &FinalizedHead{Height: cert.Height, Hash: cert.BlockHash, Commit: cert}
+	c.recordCheckpoint(cert)
+}
+
+// GetCommit returns the commit certificate stored with a block
+func (c *Chain) GetCommit(blockHash string) (*pos.CommitCertificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cert, exists := c.commits[blockHash]
+	if !exists {
+		return nil, ErrCommitNotFound
+	}
+	return cert, nil
+}
+
+// FinalizedHead returns the highest finalized block
+func (c *Chain) FinalizedHead() (*FinalizedHead, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.finalized == nil {
+		return nil, ErrChainNotReady
+	}
+	return c.finalized, nil
+}
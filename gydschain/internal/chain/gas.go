@@ -0,0 +1,321 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Block gas defaults
+const (
+	DefaultBlockGasLimit  = 10000000 // hard cap on gas per block
+	DefaultBlockGasTarget = 2500000  // starting gas per block the base fee steers towards
+	DefaultGasWindow      = 64       // recent blocks averaged when adapting the target
+	DefaultFeeHistory     = 1024     // recent blocks kept for fee history
+)
+
+const (
+	gasTargetChangeDenom = 1024 // the target moves at most 1/1024 per block
+	baseFeeChangeDenom   = 8    // the base fee moves at most 1/8 per block
+)
+
+// Block gas and fee errors
+var (
+	ErrBlockGasExceeded = errors.New("block exceeds gas limit")
+	ErrInvalidBaseFee   = errors.New("block base fee does not match the chain")
+	ErrFeeBelowBaseFee  = errors.New("transaction fee below base fee")
+	ErrInvalidBurn      = errors.New("block burned amount does not match its base fees")
+)
+
+// GasController sizes blocks by gas. A block may use up to twice the current
+// target, capped at the configured limit. The base fee rises when a block
+// runs above target and falls when it runs below, pricing short bursts; the
+// target itself drifts up under sustained demand and back down to the
+// configured target when blocks empty out. The base fee share of every fee
+// is burned.
+type GasController struct {
+	mu        sync.RWMutex
+	fees      *tx.FeeConfig
+	maxLimit  uint64
+	minTarget uint64
+	target    uint64
+	baseFee   uint64
+	recent    []uint64 // gas used by the last DefaultGasWindow blocks
+	history   []*FeeHistoryEntry
+}
+
+// NewGasController creates a controller from the chain's gas settings; zero
+// settings select the defaults
+func NewGasController(config *ChainConfig, fees *tx.FeeConfig) *GasController {
+	if fees == nil {
+		fees = tx.DefaultFeeConfig()
+	}
+	maxLimit := config.BlockGasLimit
+	if maxLimit == 0 {
+		maxLimit = DefaultBlockGasLimit
+	}
+	target := config.BlockGasTarget
+	if target == 0 {
+		target = DefaultBlockGasTarget
+	}
+	if target > maxLimit/2 {
+		target = maxLimit / 2
+	}
+	return &GasController{
+		fees:      fees,
+		maxLimit:  maxLimit,
+		minTarget: target,
+		target:    target,
+		baseFee:   fees.BaseFee,
+		recent:    make([]uint64, 0, DefaultGasWindow),
+	}
+}
+
+// TxGas returns the gas a transaction consumes
+func (g *GasController) TxGas(t *tx.Transaction) uint64 {
+	return g.fees.Gas(t)
+}
+
+// BlockGas returns the total gas used by a block's transactions
+func (g *GasController) BlockGas(block *Block) uint64 {
+	var used uint64
+	for _, t := range block.Transactions {
+		used += g.fees.Gas(t)
+	}
+	return used
+}
+
+// GasLimit returns the most gas the next block may use
+func (g *GasController) GasLimit() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.gasLimit()
+}
+
+func (g *GasController) gasLimit() uint64 {
+	if limit := g.target * 2; limit < g.maxLimit {
+		return limit
+	}
+	return g.maxLimit
+}
+
+// Target returns the current gas target
+func (g *GasController) Target() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.target
+}
+
+// BaseFee returns the minimum gas price for inclusion in the next block
+func (g *GasController) BaseFee() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.baseFee
+}
+
+// Record adjusts the base fee and target after the block at height used
+// gasUsed and burned GYDS
+func (g *GasController) Record(height, gasUsed uint64, burned *big.Int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry := &FeeHistoryEntry{
+		Height:   height,
+		BaseFee:  g.baseFee,
+		GasUsed:  gasUsed,
+		GasLimit: g.gasLimit(),
+		Burned:   (*util.Big)(util.CopyBig(burned)),
+	}
+	entry.GasUsedRatio = float64(gasUsed) / float64(entry.GasLimit)
+	if len(g.history) == DefaultFeeHistory {
+		g.history = g.history[1:]
+	}
+	g.history = append(g.history, entry)
+
+	// Base fee follows this block's fullness relative to the target
+	if gasUsed > g.target {
+		delta := g.baseFee * (gasUsed - g.target) / g.target / baseFeeChangeDenom
+		if delta == 0 {
+			delta = 1
+		}
+		g.baseFee += delta
+		if g.baseFee > g.fees.MaxGasPrice {
+			g.baseFee = g.fees.MaxGasPrice
+		}
+	} else if gasUsed < g.target {
+		delta := g.baseFee * (g.target - gasUsed) / g.target / baseFeeChangeDenom
+		if g.baseFee-delta < g.fees.BaseFee {
+			g.baseFee = g.fees.BaseFee
+		} else {
+			g.baseFee -= delta
+		}
+	}
+
+	// The target follows average fullness over the recent window
+	if len(g.recent) == DefaultGasWindow {
+		g.recent = g.recent[1:]
+	}
+	g.recent = append(g.recent, gasUsed)
+
+	var sum uint64
+	for _, used := range g.recent {
+		sum += used
+	}
+	avg := sum / uint64(len(g.recent))
+
+	step := g.target / gasTargetChangeDenom
+	if step == 0 {
+		step = 1
+	}
+	switch {
+	case avg > g.target && g.target+step <= g.maxLimit/2:
+		g.target += step
+	case avg < g.target/2 && g.target-step >= g.minTarget:
+		g.target -= step
+	}
+}
+
+// Status returns the current gas settings for RPC consumers
+func (g *GasController) Status() *GasStatus {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	status := &GasStatus{
+		GasTarget:    g.target,
+		GasLimit:     g.gasLimit(),
+		MaxGasLimit:  g.maxLimit,
+		BaseFee:      g.baseFee,
+		RecentBlocks: len(g.recent),
+	}
+	if len(g.recent) > 0 {
+		var sum uint64
+		for _, used := range g.recent {
+			sum += used
+		}
+		status.AvgGasUsed = sum / uint64(len(g.recent))
+	}
+	return status
+}
+
+// GasState is the controller's adaptive state, carried in chain snapshots
+// so a restored node prices the next block as the rest of the network does
+type GasState struct {
+	Target  uint64   `json:"target"`
+	BaseFee uint64   `json:"base_fee"`
+	Recent  []uint64 `json:"recent"`
+}
+
+// State returns the current target, base fee and recent block gas
+func (g *GasController) State() *GasState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return &GasState{
+		Target:  g.target,
+		BaseFee: g.baseFee,
+		Recent:  append([]uint64{}, g.recent...),
+	}
+}
+
+// Restore resumes from a state returned by State, keeping the target
+// within this node's configured bounds
+func (g *GasController) Restore(state *GasState) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.target = state.Target
+	if g.target < g.minTarget {
+		g.target = g.minTarget
+	}
+	if g.target > g.maxLimit/2 {
+		g.target = g.maxLimit / 2
+	}
+	g.baseFee = state.BaseFee
+	recent := state.Recent
+	if len(recent) > DefaultGasWindow {
+		recent = recent[len(recent)-DefaultGasWindow:]
+	}
+	g.recent = append(make([]uint64, 0, DefaultGasWindow), recent...)
+}
+
+// FeeHistory returns up to count of the most recent blocks' fee entries,
+// oldest first, and the base fee the next block will use
+func (g *GasController) FeeHistory(count int) *FeeHistory {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if count <= 0 || count > len(g.history) {
+		count = len(g.history)
+	}
+	history := &FeeHistory{
+		Blocks:      make([]*FeeHistoryEntry, 0, count),
+		NextBaseFee: g.baseFee,
+	}
+	for _, entry := range g.history[len(g.history)-count:] {
+		c := *entry
+		history.Blocks = append(history.Blocks, &c)
+	}
+	return history
+}
+
+// FeeHistoryEntry records one block's base fee, fullness and burn
+type FeeHistoryEntry struct {
+	Height       uint64    `json:"height"`
+	BaseFee      uint64    `json:"base_fee"`
+	GasUsed      uint64    `json:"gas_used"`
+	GasLimit     uint64    `json:"gas_limit"`
+	GasUsedRatio float64   `json:"gas_used_ratio"`
+	Burned       *util.Big `json:"burned"` // GYDS
+}
+
+// FeeHistory is the fee record of recent blocks
+type FeeHistory struct {
+	Blocks      []*FeeHistoryEntry `json:"blocks"`
+	NextBaseFee uint64             `json:"next_base_fee"`
+}
+
+// GasStatus reports block sizing and the current base fee
+type GasStatus struct {
+	GasTarget    uint64 `json:"gas_target"`
+	GasLimit     uint64 `json:"gas_limit"`
+	MaxGasLimit  uint64 `json:"max_gas_limit"`
+	BaseFee      uint64 `json:"base_fee"`
+	AvgGasUsed   uint64 `json:"avg_gas_used"` // over the recent window
+	RecentBlocks int    `json:"recent_blocks"`
+}
+
+// ProposeBlock builds the next block from the mempool, filled up to the
+// current gas limit with transactions paying at least the base fee. Its
+// state root is the root committed by the parent block.
+func (c *Chain) ProposeBlock(mempool *tx.Mempool, validator string) *Block {
+	c.mu.RLock()
+	parentHash, height := c.latestHash, c.latestHeight+1
+	stateRoot := c.stateDB.Root()
+	var difficulty uint64
+	if parent := c.blocks[parentHash]; parent != nil {
+		difficulty = c.expectedDifficulty(parent)
+	}
+	c.mu.RUnlock()
+
+	limit, baseFee := c.gas.GasLimit(), c.gas.BaseFee()
+	txs := mempool.ReapGas(limit, baseFee, c.gas.TxGas)
+
+	block := NewBlock(parentHash, height, txs, validator)
+	if pool := c.EvidencePool(); pool != nil {
+		block.Evidence = pool.Pending(MaxBlockEvidence)
+		block.Header.EvidenceRoot = block.CalculateEvidenceRoot()
+	}
+	block.Header.GasLimit = limit
+	block.Header.GasUsed = c.gas.BlockGas(block)
+	block.Header.BaseFee = baseFee
+	block.Header.StateRoot = stateRoot
+	block.Header.Difficulty = difficulty
+	for _, t := range txs {
+		if t.Asset == "GYDS" {
+			block.Header.Burned.Add(block.Header.Burned, tx.FeeForGas(c.gas.TxGas(t), baseFee))
+		}
+	}
+	return block
+}
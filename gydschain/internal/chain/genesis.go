@@ -2,8 +2,12 @@ package chain
 
 import (
 	"encoding/json"
+	"math/big"
 	"os"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // GenesisConfig represents the genesis block configuration
@@ -28,42 +32,85 @@ type ValidatorConfig struct {
 
 // AllocConfig represents a genesis account allocation
 type AllocConfig struct {
-	Address     string `json:"address"`
-	GYDSBalance uint64 `json:"gyds_balance"`
-	GYDBalance  uint64 `json:"gyd_balance"`
+	Address     string         `json:"address"`
+	GYDSBalance *big.Int       `json:"gyds_balance"`
+	GYDBalance  *big.Int       `json:"gyd_balance"`
 	Vesting     *VestingConfig `json:"vesting,omitempty"`
 }
 
+// MarshalJSON encodes the balances as decimal strings
+func (a AllocConfig) MarshalJSON() ([]byte, error) {
+	type plain AllocConfig
+	return json.Marshal(struct {
+		*plain
+		GYDSBalance *util.Big `json:"gyds_balance"`
+		GYDBalance  *util.Big `json:"gyd_balance"`
+	}{(*plain)(&a), (*util.Big)(a.GYDSBalance), (*util.Big)(a.GYDBalance)})
+}
+
+// UnmarshalJSON decodes the balances from strings or numbers
+func (a *AllocConfig) UnmarshalJSON(data []byte) error {
+	type plain AllocConfig
+	dec := struct {
+		*plain
+		GYDSBalance *util.Big `json:"gyds_balance"`
+		GYDBalance  *util.Big `json:"gyd_balance"`
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	a.GYDSBalance, a.GYDBalance = dec.GYDSBalance.Int(), dec.GYDBalance.Int()
+	return nil
+}
+
 // VestingConfig represents token vesting configuration
 type VestingConfig struct {
-	StartTime    int64  `json:"start_time"`
-	EndTime      int64  `json:"end_time"`
-	CliffTime    int64  `json:"cliff_time"`
-	TotalAmount  uint64 `json:"total_amount"`
-	VestedAmount uint64 `json:"vested_amount"`
+	StartTime    int64     `json:"start_time"`
+	EndTime      int64     `json:"end_time"`
+	CliffTime    int64     `json:"cliff_time"`
+	TotalAmount  *util.Big `json:"total_amount"`
+	VestedAmount *util.Big `json:"vested_amount"`
+}
+
+// Schedule converts the config into the schedule enforced on the account.
+// VestedAmount is spendable from the start; no cliff time means none.
+func (v *VestingConfig) Schedule() *state.VestingSchedule {
+	cliff := v.CliffTime
+	if cliff == 0 {
+		cliff = v.StartTime
+	}
+	return &state.VestingSchedule{
+		Total:   util.CopyBig(v.TotalAmount.Int()),
+		Initial: util.CopyBig(v.VestedAmount.Int()),
+		Start:   v.StartTime,
+		Cliff:   cliff,
+		End:     v.EndTime,
+	}
 }
 
 // TokenConfig represents token configuration
 type TokenConfig struct {
-	Name        string `json:"name"`
-	Symbol      string `json:"symbol"`
-	Decimals    uint8  `json:"decimals"`
-	TotalSupply uint64 `json:"total_supply"`
-	MaxSupply   uint64 `json:"max_supply"`
-	Mintable    bool   `json:"mintable"`
-	Burnable    bool   `json:"burnable"`
+	Name        string    `json:"name"`
+	Symbol      string    `json:"symbol"`
+	Decimals    uint8     `json:"decimals"`
+	TotalSupply *util.Big `json:"total_supply"`
+	MaxSupply   *util.Big `json:"max_supply"` // zero for no cap
+	Mintable    bool      `json:"mintable"`
+	Burnable    bool      `json:"burnable"`
 }
 
 // ChainParams represents chain-wide parameters
 type ChainParams struct {
-	BlockTime           uint64 `json:"block_time"`
-	MaxValidators       uint32 `json:"max_validators"`
-	MinStake            uint64 `json:"min_stake"`
-	UnbondingTime       uint64 `json:"unbonding_time"`
-	SlashingPenalty     uint64 `json:"slashing_penalty"`
-	InflationRate       uint64 `json:"inflation_rate"`
-	StablecoinReserve   uint64 `json:"stablecoin_reserve"`
-	OracleUpdateFreq    uint64 `json:"oracle_update_freq"`
+	BlockTime           uint64    `json:"block_time"`
+	MaxValidators       uint32    `json:"max_validators"`
+	MinStake            *util.Big `json:"min_stake"`
+	UnbondingTime       uint64    `json:"unbonding_time"`
+	SlashingPenalty     uint64    `json:"slashing_penalty"`
+	InflationRate       uint64    `json:"inflation_rate"`
+	StablecoinReserve   uint64    `json:"stablecoin_reserve"`
+	OracleUpdateFreq    uint64    `json:"oracle_update_freq"`
+	MinTransfer         map[string]*util.Big `json:"min_transfer,omitempty"` // smallest transfer per asset, in base units
+	AppealWindow        uint64    `json:"appeal_window,omitempty"` // seconds a slash stays reversible, until governance votes another
 }
 
 // DefaultGenesis returns a default genesis configuration
@@ -83,21 +130,21 @@ func DefaultGenesis() *GenesisConfig {
 		Alloc: []AllocConfig{
 			{
 				Address:     "gyds1foundation00000000000000000000000000001",
-				GYDSBalance: 100000000 * 1e8, // 100M GYDS
-				GYDBalance:  10000000 * 1e8,  // 10M GYD
+				GYDSBalance: units(100000000), // 100M GYDS
+				GYDBalance:  units(10000000),  // 10M GYD
 			},
 			{
 				Address:     "gyds1treasury0000000000000000000000000000001",
-				GYDSBalance: 50000000 * 1e8,  // 50M GYDS
-				GYDBalance:  5000000 * 1e8,   // 5M GYD
+				GYDSBalance: units(50000000), // 50M GYDS
+				GYDBalance:  units(5000000),  // 5M GYD
 			},
 		},
 		GYDSConfig: TokenConfig{
 			Name:        "GYDS Token",
 			Symbol:      "GYDS",
 			Decimals:    8,
-			TotalSupply: 1000000000 * 1e8, // 1B GYDS
-			MaxSupply:   2000000000 * 1e8, // 2B GYDS max
+			TotalSupply: (*util.Big)(units(1000000000)), // 1B GYDS
+			MaxSupply:   (*util.Big)(units(2000000000)), // 2B GYDS max
 			Mintable:    true,
 			Burnable:    true,
 		},
@@ -105,24 +152,34 @@ func DefaultGenesis() *GenesisConfig {
 			Name:        "GYD Stablecoin",
 			Symbol:      "GYD",
 			Decimals:    8,
-			TotalSupply: 100000000 * 1e8, // 100M GYD
-			MaxSupply:   0,               // No max (collateral-backed)
+			TotalSupply: (*util.Big)(units(100000000)), // 100M GYD
+			MaxSupply:   new(util.Big),                 // No max (collateral-backed)
 			Mintable:    true,
 			Burnable:    true,
 		},
 		Params: ChainParams{
 			BlockTime:         5,
 			MaxValidators:     100,
-			MinStake:          10000 * 1e8, // 10,000 GYDS
+			MinStake:          (*util.Big)(units(10000)), // 10,000 GYDS
 			UnbondingTime:     21 * 24 * 60 * 60, // 21 days
 			SlashingPenalty:   5, // 5%
 			InflationRate:     5, // 5% annual
 			StablecoinReserve: 150, // 150% collateralization
 			OracleUpdateFreq:  60, // 60 seconds
+			AppealWindow:      7 * 24 * 60 * 60, // 7 days
+			MinTransfer: map[string]*util.Big{
+				"GYDS": (*util.Big)(big.NewInt(1000)), // 0.00001 GYDS
+				"GYD":  (*util.Big)(big.NewInt(1000)), // 0.00001 GYD
+			},
 		},
 	}
 }
 
+// units converts whole tokens to the smallest unit at 8 decimals
+func units(tokens int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(tokens), big.NewInt(1e8))
+}
+
 // LoadGenesis loads genesis config from a file
 func LoadGenesis(path string) (*GenesisConfig, error) {
 	data, err := os.ReadFile(path)
@@ -159,6 +216,7 @@ func (g *GenesisConfig) ToBlock() *Block {
 		StateRoot:  "0x0000000000000000000000000000000000000000000000000000000000000000",
 		Difficulty: 1,
 		GasLimit:   10000000,
+		Burned:     new(big.Int),
 	}
 	
 	return &Block{
@@ -168,6 +226,23 @@ func (g *GenesisConfig) ToBlock() *Block {
 	}
 }
 
+// secondsPerYear is the year the inflation rate is paid out over
+const secondsPerYear = 365 * 24 * 60 * 60
+
+// BlockReward returns the GYDS each block pays stakers: the annual
+// inflation rate of the GYDS supply spread over a year of blocks
+func (g *GenesisConfig) BlockReward() *big.Int {
+	blocks := uint64(0)
+	if g.Params.BlockTime > 0 {
+		blocks = secondsPerYear / g.Params.BlockTime
+	}
+	if blocks == 0 {
+		return new(big.Int)
+	}
+	reward := new(big.Int).Mul(g.GYDSConfig.TotalSupply.Int(), new(big.Int).SetUint64(g.Params.InflationRate))
+	return reward.Quo(reward, new(big.Int).SetUint64(100*blocks))
+}
+
 // Validate checks the genesis configuration
 func (g *GenesisConfig) Validate() error {
 	if g.ChainID == "" {
@@ -178,10 +253,24 @@ func (g *GenesisConfig) Validate() error {
 		return ErrNoValidators
 	}
 	
-	if g.GYDSConfig.TotalSupply == 0 {
+	if g.GYDSConfig.TotalSupply.Int().Sign() == 0 {
 		return ErrInvalidTokenConfig
 	}
 	
+	// Vesting can only lock GYDS the account is allocated
+	for _, alloc := range g.Alloc {
+		if alloc.Vesting == nil {
+			continue
+		}
+		schedule := alloc.Vesting.Schedule()
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+		if schedule.Total.Cmp(util.CopyBig(alloc.GYDSBalance)) > 0 {
+			return state.ErrInvalidVesting
+		}
+	}
+	
 	return nil
 }
 
@@ -5,31 +5,68 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 var (
-	ErrInvalidHeight    = errors.New("invalid block height")
-	ErrInvalidTimestamp = errors.New("invalid timestamp")
-	ErrInvalidTxRoot    = errors.New("invalid transaction root")
-	ErrInvalidStateRoot = errors.New("invalid state root")
+	ErrInvalidHeight         = errors.New("invalid block height")
+	ErrInvalidTimestamp      = errors.New("invalid timestamp")
+	ErrInvalidTxRoot         = errors.New("invalid transaction root")
+	ErrInvalidStateRoot      = errors.New("invalid state root")
+	ErrInvalidBlockSignature = errors.New("invalid block signature")
+	ErrUnsignedBlock         = errors.New("block is not signed by its proposer")
+	ErrUnknownProposer       = errors.New("block proposer is not a known validator")
+	ErrInactiveProposer      = errors.New("block proposer is not an active validator")
+	ErrInvalidRound          = errors.New("block round does not match its timestamp")
+	ErrInvalidTxProof        = errors.New("invalid transaction proof")
+	ErrTxNotInBlock          = errors.New("transaction not in block")
 )
 
 // Header represents the block header
 type Header struct {
-	Version      uint32 `json:"version"`
-	Height       uint64 `json:"height"`
-	Timestamp    int64  `json:"timestamp"`
-	ParentHash   string `json:"parent_hash"`
-	TxRoot       string `json:"tx_root"`
-	StateRoot    string `json:"state_root"`
-	ReceiptRoot  string `json:"receipt_root"`
-	ValidatorSet string `json:"validator_set"`
-	Difficulty   uint64 `json:"difficulty"`
-	Nonce        uint64 `json:"nonce"`
-	ExtraData    []byte `json:"extra_data"`
-	GasLimit     uint64 `json:"gas_limit"`
-	GasUsed      uint64 `json:"gas_used"`
+	Version      uint32   `json:"version"`
+	Height       uint64   `json:"height"`
+	Timestamp    int64    `json:"timestamp"`
+	ParentHash   string   `json:"parent_hash"`
+	TxRoot       string   `json:"tx_root"`
+	StateRoot    string   `json:"state_root"` // state trie root committed by the parent block
+	ReceiptRoot  string   `json:"receipt_root"`
+	ValidatorSet string   `json:"validator_set"`
+	Difficulty   uint64   `json:"difficulty"`
+	Nonce        uint64   `json:"nonce"`
+	ExtraData    []byte   `json:"extra_data"`
+	GasLimit     uint64   `json:"gas_limit"`
+	GasUsed      uint64   `json:"gas_used"`
+	BaseFee      uint64   `json:"base_fee"` // minimum gas price; this share of each fee is burned
+	Burned       *big.Int `json:"burned"`   // GYDS burned by this block's base fees
+	EvidenceRoot string   `json:"evidence_root,omitempty"`
+	Round        uint64   `json:"round,omitempty"` // consensus round the proposer led
+}
+
+// MarshalJSON encodes the burned amount as a decimal string
+func (h Header) MarshalJSON() ([]byte, error) {
+	type plain Header
+	return json.Marshal(struct {
+		*plain
+		Burned *util.Big `json:"burned"`
+	}{(*plain)(&h), (*util.Big)(h.Burned)})
+}
+
+// UnmarshalJSON decodes the burned amount from a string or number
+func (h *Header) UnmarshalJSON(data []byte) error {
+	type plain Header
+	dec := struct {
+		*plain
+		Burned *util.Big `json:"burned"`
+	}{plain: (*plain)(h)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	h.Burned = dec.Burned.Int()
+	return nil
 }
 
 // NewHeader creates a new block header
@@ -41,6 +78,7 @@ func NewHeader(parentHash string, height uint64) *Header {
 		ParentHash: parentHash,
 		Difficulty: 1000,
 		GasLimit:   10000000,
+		Burned:     new(big.Int),
 	}
 }
 
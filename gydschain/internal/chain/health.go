@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// BlockTimeStats compares recent block production against the target
+type BlockTimeStats struct {
+	Window        int     `json:"window"` // number of block intervals measured
+	AverageSecs   float64 `json:"average_secs"`
+	TargetSecs    uint64  `json:"target_secs"`
+	LastBlockTime int64   `json:"last_block_time"`
+	LastBlockAge  int64   `json:"last_block_age_secs"`
+}
+
+// BlockTimeStats measures the average block interval over the last window blocks
+func (c *Chain) BlockTimeStats(window int) *BlockTimeStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := &BlockTimeStats{TargetSecs: c.config.BlockTime}
+
+	latest, exists := c.blocks[c.latestHash]
+	if !exists {
+		return stats
+	}
+	stats.LastBlockTime = latest.Header.Timestamp
+	stats.LastBlockAge = time.Now().Unix() - latest.Header.Timestamp
+
+	if window <= 0 || uint64(window) > c.latestHeight {
+		window = int(c.latestHeight)
+	}
+	if window == 0 {
+		return stats
+	}
+
+	first, exists := c.blocks[c.heights[c.latestHeight-uint64(window)]]
+	if !exists {
+		return stats
+	}
+
+	stats.Window = window
+	stats.AverageSecs = float64(latest.Header.Timestamp-first.Header.Timestamp) / float64(window)
+	return stats
+}
+
+// FinalizedHeight returns the highest final block. PoS blocks are final once
+// committed, so this is the latest height.
+func (c *Chain) FinalizedHeight() uint64 {
+	return c.Height()
+}
+
+// ApplyLatency returns percentiles for block execution and storage time
+func (c *Chain) ApplyLatency() *util.LatencyPercentiles {
+	return c.applyLatency.Percentiles()
+}
@@ -0,0 +1,388 @@
+package chain
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// Log index defaults
+const (
+	DefaultLogRetention  = 10000 // recent blocks kept in the log index
+	DefaultMaxLogRange   = 5000  // blocks a single query may span
+	DefaultMaxLogResults = 10000 // logs a single query may return
+)
+
+var (
+	ErrLogRangePruned   = errors.New("block range outside log index retention")
+	ErrLogRangeInvalid  = errors.New("invalid block range")
+	ErrLogRangeTooLarge = errors.New("block range exceeds the query limit; split the query")
+	ErrTooManyLogs      = errors.New("query matches more logs than the result limit; narrow the range or filter")
+)
+
+// IndexedLog is a log entry with its position in the chain
+type IndexedLog struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        []byte   `json:"data"`
+	BlockHeight uint64   `json:"block_height"`
+	BlockHash   string   `json:"block_hash"`
+	TxHash      string   `json:"tx_hash"`
+	TxIndex     uint32   `json:"tx_index"`
+	LogIndex    uint32   `json:"log_index"`
+}
+
+// LogFilter selects logs by block range, address and topics.
+// Topics are positional: each position matches any of its values,
+// and an empty position matches anything.
+// Limit, if set, returns only the first Limit matching logs instead of
+// failing when more match.
+type LogFilter struct {
+	FromBlock uint64     `json:"fromBlock"`
+	ToBlock   uint64     `json:"toBlock"`
+	Addresses []string   `json:"addresses,omitempty"`
+	Topics    [][]string `json:"topics,omitempty"`
+	Limit     int        `json:"limit,omitempty"`
+}
+
+// LogIndex keeps an in-memory index of logs by address and topic for the
+// most recent blocks so log queries don't need an external indexer
+type LogIndex struct {
+	mu         sync.RWMutex
+	retention  uint64
+	maxRange   uint64
+	maxResults int
+	blocks     map[uint64][]*IndexedLog
+	byAddress  map[string]map[uint64]struct{}
+	byTopic    map[string]map[uint64]struct{}
+	oldest     uint64
+	latest     uint64
+	empty      bool
+}
+
+// NewLogIndex creates a log index retaining the given number of blocks
+func NewLogIndex(retention uint64) *LogIndex {
+	if retention == 0 {
+		retention = DefaultLogRetention
+	}
+
+	return &LogIndex{
+		retention:  retention,
+		maxRange:   DefaultMaxLogRange,
+		maxResults: DefaultMaxLogResults,
+		blocks:     make(map[uint64][]*IndexedLog),
+		byAddress:  make(map[string]map[uint64]struct{}),
+		byTopic:    make(map[string]map[uint64]struct{}),
+		empty:      true,
+	}
+}
+
+// SetLimits bounds the blocks a query may span and the logs it may
+// return; 0 keeps the default
+func (li *LogIndex) SetLimits(maxRange uint64, maxResults int) {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+
+	if maxRange > 0 {
+		li.maxRange = maxRange
+	}
+	if maxResults > 0 {
+		li.maxResults = maxResults
+	}
+}
+
+// IndexBlock adds the logs from a block's receipts and prunes old blocks
+func (li *LogIndex) IndexBlock(height uint64, blockHash string, receipts []*tx.TransactionReceipt) {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+
+	// Re-indexing a height (e.g. after a reorg) replaces the old entries
+	li.removeBlock(height)
+
+	logs := blockLogs(height, blockHash, receipts)
+	for _, entry := range logs {
+		addToSet(li.byAddress, entry.Address, height)
+		for _, topic := range entry.Topics {
+			addToSet(li.byTopic, topic, height)
+		}
+	}
+	li.blocks[height] = logs
+
+	if li.empty || height < li.oldest {
+		li.oldest = height
+	}
+	if li.empty || height > li.latest {
+		li.latest = height
+	}
+	li.empty = false
+
+	li.prune()
+}
+
+// blockLogs flattens a block's receipts into positioned log entries
+func blockLogs(height uint64, blockHash string, receipts []*tx.TransactionReceipt) []*IndexedLog {
+	var logs []*IndexedLog
+	for _, receipt := range receipts {
+		for i, l := range receipt.Logs {
+			logs = append(logs, &IndexedLog{
+				Address:     l.Address,
+				Topics:      l.Topics,
+				Data:        l.Data,
+				BlockHeight: height,
+				BlockHash:   blockHash,
+				TxHash:      receipt.TxHash,
+				TxIndex:     receipt.Index,
+				LogIndex:    uint32(i),
+			})
+		}
+	}
+	return logs
+}
+
+// MatchLog reports whether a log satisfies the filter's addresses and
+// topics; the block range is not checked
+func MatchLog(entry *IndexedLog, filter *LogFilter) bool {
+	return matchLog(entry, filter)
+}
+
+// Query returns logs matching the filter in chain order. A range wider
+// than the query limit is refused, as is one matching more logs than the
+// result limit unless the filter sets its own smaller Limit.
+func (li *LogIndex) Query(filter *LogFilter) ([]*IndexedLog, error) {
+	if filter.ToBlock < filter.FromBlock || filter.Limit < 0 {
+		return nil, ErrLogRangeInvalid
+	}
+
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+
+	if li.empty {
+		return []*IndexedLog{}, nil
+	}
+	if filter.FromBlock < li.oldest {
+		return nil, ErrLogRangePruned
+	}
+
+	to := filter.ToBlock
+	if to > li.latest {
+		to = li.latest
+	}
+	if to >= filter.FromBlock && to-filter.FromBlock >= li.maxRange {
+		return nil, ErrLogRangeTooLarge
+	}
+
+	limit := li.maxResults
+	if filter.Limit > 0 && filter.Limit < limit {
+		limit = filter.Limit
+	}
+
+	results := make([]*IndexedLog, 0)
+	for _, height := range li.candidateHeights(filter, filter.FromBlock, to) {
+		for _, entry := range li.blocks[height] {
+			if !matchLog(entry, filter) {
+				continue
+			}
+			if len(results) == limit {
+				if filter.Limit > 0 && filter.Limit <= li.maxResults {
+					return results, nil
+				}
+				return nil, ErrTooManyLogs
+			}
+			results = append(results, entry)
+		}
+	}
+
+	return results, nil
+}
+
+// Page returns logs matching the filter in chain order for a client that
+// pages through a range: it starts after the first skip matches in
+// FromBlock, spans at most the query's block limit and stops at the result
+// limit. It returns where the next page starts: a block and the matches in
+// it already returned.
+func (li *LogIndex) Page(filter *LogFilter, skip int) ([]*IndexedLog, uint64, int, error) {
+	if filter.ToBlock < filter.FromBlock {
+		return nil, 0, 0, ErrLogRangeInvalid
+	}
+
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+
+	if li.empty {
+		return []*IndexedLog{}, filter.ToBlock + 1, 0, nil
+	}
+	if filter.FromBlock < li.oldest {
+		return nil, 0, 0, ErrLogRangePruned
+	}
+
+	to := filter.ToBlock
+	if to > li.latest {
+		to = li.latest
+	}
+	if to < filter.FromBlock {
+		return []*IndexedLog{}, filter.FromBlock, skip, nil
+	}
+	if to-filter.FromBlock >= li.maxRange {
+		to = filter.FromBlock + li.maxRange - 1
+	}
+
+	results := make([]*IndexedLog, 0)
+	for _, height := range li.candidateHeights(filter, filter.FromBlock, to) {
+		matched := 0
+		for _, entry := range li.blocks[height] {
+			if !matchLog(entry, filter) {
+				continue
+			}
+			matched++
+			if height == filter.FromBlock && matched <= skip {
+				continue
+			}
+			results = append(results, entry)
+			if len(results) == li.maxResults {
+				return results, height, matched, nil
+			}
+		}
+	}
+
+	return results, to + 1, 0, nil
+}
+
+// Range returns the oldest and latest indexed heights
+func (li *LogIndex) Range() (uint64, uint64) {
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+	return li.oldest, li.latest
+}
+
+// candidateHeights narrows the block range using the address and topic indexes
+func (li *LogIndex) candidateHeights(filter *LogFilter, from, to uint64) []uint64 {
+	var sets []map[uint64]struct{}
+
+	if len(filter.Addresses) > 0 {
+		sets = append(sets, unionSets(li.byAddress, filter.Addresses))
+	}
+	for _, position := range filter.Topics {
+		if len(position) > 0 {
+			sets = append(sets, unionSets(li.byTopic, position))
+		}
+	}
+
+	heights := make([]uint64, 0)
+	if len(sets) == 0 {
+		for h := from; h <= to; h++ {
+			if _, ok := li.blocks[h]; ok {
+				heights = append(heights, h)
+			}
+		}
+		return heights
+	}
+
+	// Iterate the smallest set and check membership in the others
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	for h := range sets[0] {
+		if h < from || h > to {
+			continue
+		}
+		inAll := true
+		for _, set := range sets[1:] {
+			if _, ok := set[h]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			heights = append(heights, h)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	return heights
+}
+
+// prune drops blocks that fall outside the retention window
+func (li *LogIndex) prune() {
+	if li.latest-li.oldest < li.retention {
+		return
+	}
+
+	cutoff := li.latest - li.retention + 1
+	for h := li.oldest; h < cutoff; h++ {
+		li.removeBlock(h)
+	}
+	li.oldest = cutoff
+}
+
+// removeBlock deletes a block's logs from all indexes
+func (li *LogIndex) removeBlock(height uint64) {
+	logs, exists := li.blocks[height]
+	if !exists {
+		return
+	}
+
+	for _, entry := range logs {
+		removeFromSet(li.byAddress, entry.Address, height)
+		for _, topic := range entry.Topics {
+			removeFromSet(li.byTopic, topic, height)
+		}
+	}
+	delete(li.blocks, height)
+}
+
+// matchLog checks a log against the filter's addresses and topics
+func matchLog(entry *IndexedLog, filter *LogFilter) bool {
+	if len(filter.Addresses) > 0 && !containsString(filter.Addresses, entry.Address) {
+		return false
+	}
+
+	for i, position := range filter.Topics {
+		if len(position) == 0 {
+			continue
+		}
+		if i >= len(entry.Topics) || !containsString(position, entry.Topics[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func addToSet(index map[string]map[uint64]struct{}, key string, height uint64) {
+	set, exists := index[key]
+	if !exists {
+		set = make(map[uint64]struct{})
+		index[key] = set
+	}
+	set[height] = struct{}{}
+}
+
+func removeFromSet(index map[string]map[uint64]struct{}, key string, height uint64) {
+	set, exists := index[key]
+	if !exists {
+		return
+	}
+	delete(set, height)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+func unionSets(index map[string]map[uint64]struct{}, keys []string) map[uint64]struct{} {
+	result := make(map[uint64]struct{})
+	for _, key := range keys {
+		for h := range index[key] {
+			result[h] = struct{}{}
+		}
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
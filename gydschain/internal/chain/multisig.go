@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrMultisigAddressMismatch is returned when a create_multisig recipient is
+// not the address its keys and threshold derive
+var ErrMultisigAddressMismatch = errors.New("multisig recipient does not match its keys and threshold")
+
+// checkMultisig requires transactions from a multisig account to carry
+// enough signatures from its registered keys
+func (c *Chain) checkMultisig(transaction *tx.Transaction) error {
+	sender := c.stateDB.GetAccount(transaction.From)
+	if sender == nil {
+		return nil
+	}
+	config := sender.GetMultisig()
+	if config == nil {
+		return nil
+	}
+	return transaction.VerifyMultisig(config.PubKeys, config.Threshold)
+}
+
+// processCreateMultisig registers the recipient as a multisig account and
+// funds it with the transaction amount
+func (c *Chain) processCreateMultisig(transaction *tx.Transaction) error {
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.CreateMultisigPayload)
+
+	if tx.MultisigAddress(p.PubKeys, p.Threshold) != transaction.To {
+		return ErrMultisigAddressMismatch
+	}
+	account := c.stateDB.GetAccount(transaction.To)
+	if account == nil {
+		account = state.NewAccount(transaction.To)
+	} else if account.GetMultisig() != nil {
+		return state.ErrMultisigExists
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if !sender.SubBalance(transaction.Asset, transaction.Amount) {
+		return state.ErrInsufficientBalance
+	}
+
+	account.SetMultisig(&state.MultisigConfig{PubKeys: p.PubKeys, Threshold: p.Threshold})
+	account.AddBalance(transaction.Asset, transaction.Amount)
+	if account.CreatedAt == 0 {
+		account.CreatedAt = transaction.Timestamp
+	}
+	account.UpdatedAt = transaction.Timestamp
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	c.stateDB.SetAccount(transaction.To, account)
+	return nil
+}
@@ -0,0 +1,113 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// processNFTMint mints a token to the recipient, creating the collection on
+// its first mint. Only the collection owner may mint.
+func (c *Chain) processNFTMint(transaction *tx.Transaction) error {
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.NFTMintPayload)
+
+	collection := c.stateDB.GetAsset(p.Collection)
+	switch {
+	case collection == nil:
+		if p.Collection == CollateralAsset || p.Collection == StablecoinAsset {
+			return state.ErrAssetExists
+		}
+		collection = state.NewNFTCollection(p.Collection, p.Collection, transaction.From)
+		collection.CreatedAt = transaction.Timestamp
+	case collection.Type != state.AssetTypeNFT:
+		return state.ErrNotNFT
+	case collection.Owner != transaction.From:
+		return state.ErrNotAssetOwner
+	default:
+		collection = collection.Copy()
+	}
+
+	id := state.NFTAssetID(p.Collection, p.TokenID)
+	if c.stateDB.GetAsset(id) != nil {
+		return state.ErrAssetExists
+	}
+	if err := collection.Mint(big.NewInt(1)); err != nil {
+		return err
+	}
+	collection.UpdatedAt = transaction.Timestamp
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+
+	name := p.Name
+	if name == "" {
+		name = id
+	}
+	token := state.NewNFT(id, name, transaction.To, &state.AssetMetadata{
+		Description: p.Description,
+		Image:       p.Image,
+		TokenURI:    p.TokenURI,
+		Properties:  p.Properties,
+	})
+	token.Symbol = p.Collection
+	token.CreatedAt = transaction.Timestamp
+	token.UpdatedAt = transaction.Timestamp
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	c.creditNFT(transaction.To, id)
+	c.stateDB.SetAsset(collection.ID, collection)
+	c.stateDB.SetAsset(id, token)
+
+	return nil
+}
+
+// processNFTTransfer moves one of the sender's tokens to the recipient
+func (c *Chain) processNFTTransfer(transaction *tx.Transaction) error {
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.NFTTransferPayload)
+
+	token := c.stateDB.GetNFT(p.Collection, p.TokenID)
+	if token == nil {
+		return state.ErrNFTNotFound
+	}
+	if collection := c.stateDB.GetAsset(p.Collection); collection != nil && collection.Paused {
+		return state.ErrAssetPaused
+	}
+	token = token.Copy()
+	if err := token.TransferNFT(transaction.From, transaction.To); err != nil {
+		return err
+	}
+	token.UpdatedAt = transaction.Timestamp
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	sender.SubBalance(token.ID, big.NewInt(1))
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	c.creditNFT(transaction.To, token.ID)
+	c.stateDB.SetAsset(token.ID, token)
+
+	return nil
+}
+
+// creditNFT records a token in its new owner's balances
+func (c *Chain) creditNFT(address, id string) {
+	account := c.stateDB.GetAccount(address)
+	if account == nil {
+		account = state.NewAccount(address)
+	}
+	account.AddBalance(id, big.NewInt(1))
+	c.stateDB.SetAccount(address, account)
+}
@@ -0,0 +1,173 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrNotLeader is returned when this node's validator does not lead the round
+var ErrNotLeader = errors.New("not the leader for this round")
+
+// Producer proposes blocks for a validator. Each BlockTime slot is one
+// round: when the engine selects this validator to lead it, the producer
+// builds a block from the mempool, executes it, signs it and hands it to
+// its listeners for gossip. Rounds follow the wall clock rather than the
+// height, so an offline leader costs only its own slot.
+type Producer struct {
+	chain     *Chain
+	engine    *pos.Engine
+	mempool   *tx.Mempool
+	key       *crypto.KeyPair
+	address   string
+	interval  time.Duration
+	mu        sync.Mutex
+	lastRound uint64
+	listeners []func(block *Block, hash string)
+}
+
+// NewProducer creates a producer proposing as key's validator every
+// BlockTime of engine
+func NewProducer(c *Chain, engine *pos.Engine, mempool *tx.Mempool, key *crypto.KeyPair) *Producer {
+	return &Producer{
+		chain:    c,
+		engine:   engine,
+		mempool:  mempool,
+		key:      key,
+		address:  key.Address(),
+		interval: engine.BlockTime(),
+	}
+}
+
+// OnPropose registers a listener for blocks this producer adds
+func (p *Producer) OnPropose(fn func(block *Block, hash string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, fn)
+}
+
+// Round returns the round of the slot containing t
+func (p *Producer) Round(t time.Time) uint64 {
+	return roundAt(t, p.interval)
+}
+
+// roundAt returns the round of the interval-long slot containing t
+func roundAt(t time.Time, interval time.Duration) uint64 {
+	if interval <= 0 {
+		return uint64(t.Unix())
+	}
+	return uint64(t.UnixNano() / int64(interval))
+}
+
+// inRound reports whether a header timestamp, in whole seconds, falls in
+// round's slot. The slot start is rounded down to its second, since a block
+// built just after the slot begins is stamped with that second.
+func inRound(timestamp int64, round uint64, interval time.Duration) bool {
+	if interval <= 0 {
+		return timestamp >= 0 && uint64(timestamp) == round
+	}
+	if round > uint64(math.MaxInt64/int64(interval))-1 {
+		return false
+	}
+	start := time.Unix(0, int64(round)*int64(interval))
+	return timestamp >= start.Unix() && time.Unix(timestamp, 0).Before(start.Add(interval))
+}
+
+// Run proposes a block on every slot this validator leads until ctx ends.
+// Rounds it does not lead and failed proposals are reported to onError,
+// which may be nil.
+func (p *Producer) Run(ctx context.Context, onError func(round uint64, err error)) {
+	interval := p.interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			round := p.Round(now)
+			if _, err := p.Propose(round); err != nil && err != ErrNotLeader && onError != nil {
+				onError(round, err)
+			}
+		}
+	}
+}
+
+// Propose builds, executes and signs the next block if this validator
+// leads round; it returns ErrNotLeader otherwise. Each round is proposed at
+// most once. A block that fails to apply changes nothing; when one of its
+// transactions failed it, that transaction is dropped from the mempool and
+// the block is rebuilt from the rest, so one bad transaction neither stalls
+// production nor costs the valid ones their place.
+func (p *Producer) Propose(round uint64) (*Block, error) {
+	p.mu.Lock()
+	if round <= p.lastRound && p.lastRound != 0 {
+		p.mu.Unlock()
+		return nil, ErrNotLeader
+	}
+	p.mu.Unlock()
+
+	if err := p.engine.CanValidate(); err != nil {
+		return nil, err
+	}
+	leader, err := p.engine.SelectLeader(round)
+	if err != nil {
+		return nil, err
+	}
+	if leader.Address != p.address {
+		return nil, ErrNotLeader
+	}
+
+	block, hash, err := p.build(round)
+	for err != nil {
+		failed, ok := err.(*txError)
+		if !ok {
+			return nil, err
+		}
+		txHash, herr := block.Transactions[failed.index].HashHex()
+		if herr != nil {
+			return nil, failed.err
+		}
+		p.mempool.RemoveTx(txHash)
+		block, hash, err = p.build(round)
+	}
+
+	p.mu.Lock()
+	p.lastRound = round
+	listeners := append([]func(*Block, string){}, p.listeners...)
+	p.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(block, hash)
+	}
+	return block, nil
+}
+
+// build proposes, signs and adds a block for round from the mempool. A
+// round that ended while the block was built cannot be proposed without
+// the block failing its round check.
+func (p *Producer) build(round uint64) (*Block, string, error) {
+	block := p.chain.ProposeBlock(p.mempool, p.address)
+	block.Header.Round = round
+	if !inRound(block.Header.Timestamp, round, p.interval) {
+		return nil, "", ErrInvalidRound
+	}
+	if err := block.Sign(p.key); err != nil {
+		return nil, "", err
+	}
+	hash, err := block.Hash()
+	if err != nil {
+		return nil, "", err
+	}
+	return block, hash, p.chain.addBlock(block)
+}
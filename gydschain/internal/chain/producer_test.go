@@ -0,0 +1,52 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestProposeDropsOnlyTheFailingTx(t *testing.T) {
+	c, mempool := newTestChain(t, "gyds1alice")
+	key, err := crypto.NewKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, time.Hour)
+	if err := engine.RegisterValidator(key.Address(), key.PublicKeyHex(), big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	producer := NewProducer(c, engine, mempool, key)
+
+	// The middle transaction's sender has no account, so it fails the block
+	txs := []*tx.Transaction{
+		testTx(tx.TxTypeTransfer, "gyds1alice", "gyds1bob", 100, 0),
+		testTx(tx.TxTypeTransfer, "gyds1nobody", "gyds1bob", 100, 0),
+		testTx(tx.TxTypeTransfer, "gyds1alice", "gyds1carol", 100, 1),
+	}
+	for _, transaction := range txs {
+		if err := mempool.AddTx(transaction); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	block, err := producer.Propose(producer.Round(time.Now()))
+	if err != nil {
+		t.Fatalf("propose: %v", err)
+	}
+	if len(block.Transactions) != 2 {
+		t.Fatalf("expected the two valid transactions proposed, got %d", len(block.Transactions))
+	}
+	for _, transaction := range block.Transactions {
+		if transaction.From == "gyds1nobody" {
+			t.Error("expected the failing transaction left out")
+		}
+	}
+	if hash, _ := txs[1].HashHex(); mempool.GetTx(hash) != nil {
+		t.Error("expected the failing transaction dropped from the mempool")
+	}
+}
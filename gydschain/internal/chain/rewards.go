@@ -0,0 +1,86 @@
+package chain
+
+import (
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrRewardsNotConfigured is returned for reward withdrawals on a chain
+// without a reward source
+var ErrRewardsNotConfigured = errors.New("reward withdrawal requires a staking engine")
+
+// RewardSource pays out staking rewards accrued by the consensus engine and
+// keeps each validator's payout split
+type RewardSource interface {
+	WithdrawPayouts(delegator, validator string) ([]*pos.Payout, error)
+	SetPayoutSplit(validator string, split []pos.PayoutShare) error
+}
+
+// SetRewardSource attaches the engine whose accrued staking rewards
+// withdraw_rewards transactions pay out
+func (c *Chain) SetRewardSource(source RewardSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rewards = source
+}
+
+// processWithdrawRewards credits the sender's rewards accrued with the
+// validator in To to its GYDS balance, or to the beneficiaries of the
+// validator's payout split when it withdraws its own rewards
+func (c *Chain) processWithdrawRewards(transaction *tx.Transaction) error {
+	if c.rewards == nil {
+		return ErrRewardsNotConfigured
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	payouts, err := c.rewards.WithdrawPayouts(transaction.From, transaction.To)
+	if err != nil {
+		return err
+	}
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	for _, payout := range payouts {
+		account := c.stateDB.GetAccount(payout.Address)
+		if account == nil {
+			account = state.NewAccount(payout.Address)
+		}
+		account.AddBalance("GYDS", payout.Amount.Int())
+		c.stateDB.SetAccount(payout.Address, account)
+	}
+	return nil
+}
+
+// processSetPayoutSplit sets the sending validator's reward payout split
+func (c *Chain) processSetPayoutSplit(transaction *tx.Transaction) error {
+	if c.rewards == nil {
+		return ErrRewardsNotConfigured
+	}
+
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	p := payload.(*tx.PayoutSplitPayload)
+
+	split := make([]pos.PayoutShare, len(p.Beneficiaries))
+	for i, b := range p.Beneficiaries {
+		split[i] = pos.PayoutShare{Address: b.Address, Share: b.Share}
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if err := c.rewards.SetPayoutSplit(transaction.From, split); err != nil {
+		return err
+	}
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
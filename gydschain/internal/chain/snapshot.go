@@ -0,0 +1,367 @@
+package chain
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// SnapshotVersion is the chain snapshot format written by this node.
+// Version 2 added the state of the components blocks change outside the
+// state DB.
+const SnapshotVersion = 2
+
+// Chain export and snapshot errors
+var (
+	ErrInvalidSnapshot = errors.New("invalid chain snapshot")
+	ErrSnapshotGenesis = errors.New("chain snapshot is from a different genesis")
+	ErrSnapshotVersion = errors.New("unsupported chain snapshot version")
+	ErrChainHasBlocks  = errors.New("chain already has blocks past genesis")
+
+	ErrSnapshotUnsupported = errors.New("chain has components whose state snapshots cannot carry")
+	ErrSnapshotComponents  = errors.New("chain snapshot components do not match this node's")
+)
+
+// ChainExport is the full chain as written by Export: every canonical
+// block from genesis with its receipts. Importing it re-executes every
+// block, so an export only has to be trusted to be complete.
+type ChainExport struct {
+	Config   *ChainConfig             `json:"config"`
+	Blocks   []*Block                 `json:"blocks"`
+	Receipts []*tx.TransactionReceipt `json:"receipts"`
+}
+
+// ReadExport decodes a chain export, gzip-compressed or not
+func ReadExport(data []byte) (*ChainExport, error) {
+	data, err := gunzip(data)
+	if err != nil {
+		return nil, err
+	}
+	var export ChainExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ChainSnapshot is the state at one height together with the blocks the
+// next block is checked against. A new node restores it instead of
+// replaying the chain from genesis; the head is trusted like genesis, and
+// the first block added on top checks the restored state root. Components
+// holds the state of the components blocks change outside the state DB,
+// by name; the node restoring it must run the same ones.
+type ChainSnapshot struct {
+	Version     int                        `json:"version"`
+	ChainID     string                     `json:"chain_id"`
+	GenesisHash string                     `json:"genesis_hash"`
+	Height      uint64                     `json:"height"`
+	BlockHash   string                     `json:"block_hash"`
+	StateRoot   string                     `json:"state_root"`
+	Blocks      []*Block                   `json:"blocks"` // the head and its parent, oldest first
+	Gas         *GasState                  `json:"gas"`
+	State       json.RawMessage            `json:"state"`
+	Components  map[string]json.RawMessage `json:"components,omitempty"`
+}
+
+// Snapshot captures the chain head, the state committed by it and the
+// state of the attached components. It fails if any component is not
+// Persistent, rather than write a snapshot that would restore without it.
+func (c *Chain) Snapshot() (*ChainSnapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.genesis == nil {
+		return nil, ErrChainNotReady
+	}
+	genesisHash, err := c.genesis.Hash()
+	if err != nil {
+		return nil, err
+	}
+	head := c.blocks[c.latestHash]
+	blocks := []*Block{head}
+	if parent, exists := c.blocks[head.Header.ParentHash]; exists && head.Header.Height > 0 {
+		blocks = []*Block{parent, head}
+	}
+	stateData, err := c.stateDB.Export()
+	if err != nil {
+		return nil, err
+	}
+	components, err := c.exportComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChainSnapshot{
+		Version:     SnapshotVersion,
+		ChainID:     c.config.ChainID,
+		GenesisHash: genesisHash,
+		Height:      c.latestHeight,
+		BlockHash:   c.latestHash,
+		StateRoot:   c.stateDB.Root(),
+		Blocks:      blocks,
+		Gas:         c.gas.State(),
+		State:       stateData,
+		Components:  components,
+	}, nil
+}
+
+// exportComponents encodes the state of every component by name, failing
+// if any cannot be carried
+func (c *Chain) exportComponents() (map[string]json.RawMessage, error) {
+	components := c.components()
+	if len(components) == 0 {
+		return nil, nil
+	}
+	exported := make(map[string]json.RawMessage, len(components))
+	for _, comp := range components {
+		p, ok := comp.Revertible.(Persistent)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSnapshotUnsupported, comp.name)
+		}
+		if _, exists := exported[comp.name]; exists {
+			return nil, fmt.Errorf("%w: more than one %s", ErrSnapshotUnsupported, comp.name)
+		}
+		data, err := p.ExportState()
+		if err != nil {
+			return nil, err
+		}
+		exported[comp.name] = data
+	}
+	return exported, nil
+}
+
+// checkComponents checks a snapshot carries the state of exactly the
+// components attached to the chain
+func checkComponents(components []component, exported map[string]json.RawMessage) error {
+	if len(components) != len(exported) {
+		return ErrSnapshotComponents
+	}
+	for _, comp := range components {
+		if _, exists := exported[comp.name]; !exists {
+			return ErrSnapshotComponents
+		}
+		if _, ok := comp.Revertible.(Persistent); !ok {
+			return fmt.Errorf("%w: %s", ErrSnapshotUnsupported, comp.name)
+		}
+	}
+	return nil
+}
+
+// restoreComponents restores components checked by checkComponents. A
+// component that fails to restore leaves them all as they were.
+func (c *Chain) restoreComponents(components []component, exported map[string]json.RawMessage) error {
+	restore := c.snapshot()
+	for _, comp := range components {
+		if err := comp.Revertible.(Persistent).RestoreState(exported[comp.name]); err != nil {
+			restore()
+			return fmt.Errorf("%w: %s: %v", ErrInvalidSnapshot, comp.name, err)
+		}
+	}
+	return nil
+}
+
+// RestoreSnapshot initializes a chain that has no blocks past genesis from
+// a snapshot. The snapshot must descend from genesis, its blocks must link
+// up to its head and its state must hash to its state root.
+func (c *Chain) RestoreSnapshot(genesis *GenesisConfig, snap *ChainSnapshot) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A node fast-syncing from peers restores over its genesis-only chain
+	if c.latestHeight > 0 {
+		return ErrChainHasBlocks
+	}
+	if snap.Version != SnapshotVersion {
+		return ErrSnapshotVersion
+	}
+	genesisBlock := genesis.ToBlock()
+	genesisHash, err := genesisBlock.Hash()
+	if err != nil {
+		return err
+	}
+	if snap.GenesisHash != genesisHash {
+		return ErrSnapshotGenesis
+	}
+
+	if snap.Gas == nil {
+		return ErrInvalidSnapshot
+	}
+	hashes, err := snap.blockHashes()
+	if err != nil {
+		return err
+	}
+	components := c.components()
+	if err := checkComponents(components, snap.Components); err != nil {
+		return err
+	}
+
+	if err := c.stateDB.Restore(snap.State, snap.Height); err != nil {
+		return err
+	}
+	if c.stateDB.Root() != snap.StateRoot {
+		return ErrInvalidSnapshot
+	}
+	// The snapshot's dust thresholds replace the genesis ones
+	if err := c.restoreComponents(components, snap.Components); err != nil {
+		return err
+	}
+
+	c.genesis = genesisBlock
+	c.blocks[genesisHash] = genesisBlock
+	c.heights[0] = genesisHash
+	for i, block := range snap.Blocks {
+		c.blocks[hashes[i]] = block
+		c.heights[block.Header.Height] = hashes[i]
+	}
+	c.latestHash = snap.BlockHash
+	c.latestHeight = snap.Height
+	c.finalized = &FinalizedHead{Height: snap.Height, Hash: snap.BlockHash}
+	c.blockReward = genesis.BlockReward()
+	c.gas.Restore(snap.Gas)
+	return nil
+}
+
+// Verify checks that the snapshot's blocks link up to its head and that
+// its state hashes to its state root, for nodes that take the snapshot's
+// head on trust without restoring a chain from it
+func (s *ChainSnapshot) Verify() error {
+	if s.Version != SnapshotVersion {
+		return ErrSnapshotVersion
+	}
+	if _, err := s.blockHashes(); err != nil {
+		return err
+	}
+	stateDB := state.NewStateDB()
+	if err := stateDB.Restore(s.State, s.Height); err != nil {
+		return err
+	}
+	if stateDB.Root() != s.StateRoot {
+		return ErrInvalidSnapshot
+	}
+	return nil
+}
+
+// blockHashes returns the hashes of the snapshot's blocks, checking they
+// link up to its head
+func (s *ChainSnapshot) blockHashes() ([]string, error) {
+	if len(s.Blocks) == 0 {
+		return nil, ErrInvalidSnapshot
+	}
+	hashes := make([]string, len(s.Blocks))
+	for i, block := range s.Blocks {
+		if block == nil || block.Header == nil {
+			return nil, ErrInvalidSnapshot
+		}
+		hash, err := block.Hash()
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+		if i > 0 && (block.Header.ParentHash != hashes[i-1] || block.Header.Height != s.Blocks[i-1].Header.Height+1) {
+			return nil, ErrInvalidSnapshot
+		}
+	}
+	head := s.Blocks[len(s.Blocks)-1]
+	if hashes[len(hashes)-1] != s.BlockHash || head.Header.Height != s.Height {
+		return nil, ErrInvalidSnapshot
+	}
+	return hashes, nil
+}
+
+// Encode returns the snapshot gzip-compressed, as it is saved and sent
+// to peers
+func (s *ChainSnapshot) Encode() ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return compress(data)
+}
+
+// DecodeSnapshot decodes a snapshot returned by Encode
+func DecodeSnapshot(data []byte) (*ChainSnapshot, error) {
+	data, err := gunzip(data)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+	var snap ChainSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+	return &snap, nil
+}
+
+// Save writes the encoded snapshot to path
+func (s *ChainSnapshot) Save(path string) error {
+	data, err := s.Encode()
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
+
+// LoadSnapshot reads a snapshot written by Save
+func LoadSnapshot(path string) (*ChainSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSnapshot(data)
+}
+
+// WriteCompressed writes data gzip-compressed to path
+func WriteCompressed(path string, data []byte) error {
+	compressed, err := compress(data)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, compressed)
+}
+
+// writeAtomic writes data under a temporary name and renames it to path,
+// so a crash never leaves a partial file
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// compress gzips data
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzip decompresses gzip data and passes anything else through
+func gunzip(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,82 @@
+package chain
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestSnapshotCarriesComponents(t *testing.T) {
+	// attach wires a fresh engine and slashing keeper to c
+	attach := func(c *Chain) (*pos.Engine, *pos.SlashingKeeper) {
+		engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+		engine.SetUnbondingTime(100 * time.Second)
+		c.SetStaking(engine)
+		c.SetRewardSource(engine)
+		keeper := pos.NewSlashingKeeper(engine, nil)
+		c.SetSlashingKeeper(keeper)
+		return engine, keeper
+	}
+
+	c, mempool := newTestChain(t, "gyds1alice")
+	engine, keeper := attach(c)
+	if err := engine.RegisterValidator("gyds1validator1", "gyds1validator1_pubkey", big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeStake, "gyds1alice", "gyds1validator1", 1000, 0)); err != nil {
+		t.Fatalf("stake: %v", err)
+	}
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeUnstake, "gyds1alice", "gyds1validator1", 400, 1)); err != nil {
+		t.Fatalf("unstake: %v", err)
+	}
+	if err := keeper.HandleDoubleSign("gyds1validator1", c.Height(), c.Height()); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := c.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := snap.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A node without the same components cannot restore the snapshot
+	restored, restoredPool := newTestChain(t, "gyds1alice")
+	if err := restored.RestoreSnapshot(testGenesis("gyds1alice"), decoded); err != ErrSnapshotComponents {
+		t.Fatalf("expected ErrSnapshotComponents, got %v", err)
+	}
+
+	restoredEngine, restoredKeeper := attach(restored)
+	if err := restored.RestoreSnapshot(testGenesis("gyds1alice"), decoded); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	want, _ := engine.ExportState()
+	if got, _ := restoredEngine.ExportState(); !bytes.Equal(got, want) {
+		t.Errorf("expected the engine restored\ngot  %s\nwant %s", got, want)
+	}
+	if entries := restoredEngine.Unbonding().Delegations("gyds1alice"); len(entries) != 1 {
+		t.Errorf("expected the unbonding entry restored, got %d", len(entries))
+	}
+	escrows := restoredKeeper.GetPendingEscrows()
+	if len(escrows) != 1 || escrows[0].ID != keeper.GetPendingEscrows()[0].ID {
+		t.Errorf("expected the pending escrow restored, got %+v", escrows)
+	}
+	if restoredEngine.IsActive("gyds1validator1") {
+		t.Error("expected the validator still jailed after the restore")
+	}
+
+	// The restored chain goes on applying blocks
+	if err := addTestBlock(t, restored, restoredPool, testTx(tx.TxTypeUnstake, "gyds1alice", "gyds1validator1", 100, 2)); err != nil {
+		t.Fatalf("block after restore: %v", err)
+	}
+}
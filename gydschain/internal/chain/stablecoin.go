@@ -0,0 +1,540 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Assets backing and backed by the vaults
+const (
+	CollateralAsset = "GYDS"
+	StablecoinAsset = "GYD"
+)
+
+// Stablecoin parameters
+const (
+	// LiquidationPenalty is the bonus, in percent of the repaid debt, a
+	// liquidator receives in collateral
+	LiquidationPenalty = 13
+
+	// PegTolerance is how far, in basis points, GYD may trade below its peg
+	// before minting pauses so new supply doesn't push it further down
+	PegTolerance = 100
+
+	// oracleStaleFactor is how many oracle windows without a committed
+	// price make the last one stale
+	oracleStaleFactor = 10
+
+	// oracleUnit is a price of exactly one peg unit at tx.OraclePriceDecimals
+	oracleUnit = 100000000
+)
+
+// Stablecoin errors
+var (
+	ErrStablecoinNotConfigured = errors.New("stablecoin vaults are not configured")
+	ErrNoPrice                 = errors.New("no fresh oracle price for the collateral")
+	ErrVaultNotFound           = errors.New("vault not found")
+	ErrUndercollateralized     = errors.New("vault would fall below the minimum collateral ratio")
+	ErrVaultSafe               = errors.New("vault is not below the liquidation ratio")
+	ErrRepayExceedsDebt        = errors.New("repayment exceeds vault debt")
+	ErrWithdrawExceedsVault    = errors.New("withdrawal exceeds vault collateral")
+	ErrPegBroken               = errors.New("minting paused while the stablecoin trades below its peg")
+)
+
+// Vault is an owner's locked collateral and the stablecoin minted against
+// it. Ratio is the collateral's value over the debt in percent, or zero
+// without debt or a fresh price.
+type Vault struct {
+	Owner      string    `json:"owner"`
+	Collateral *util.Big `json:"collateral"`
+	Debt       *util.Big `json:"debt"`
+	Ratio      uint64    `json:"ratio"`
+}
+
+// PegStatus reports the oracle prices, the peg and the vault system's
+// collateralization. Prices are in the peg currency at
+// tx.OraclePriceDecimals; zero means no fresh price.
+type PegStatus struct {
+	Peg             string                  `json:"peg"`
+	CollateralPrice uint64                  `json:"collateral_price"`
+	StablecoinPrice uint64                  `json:"stablecoin_price"`
+	Deviation       int64                   `json:"deviation"` // basis points from the peg
+	MintingPaused   bool                    `json:"minting_paused"`
+	MinRatio        uint64                  `json:"min_ratio"`
+	TotalCollateral *util.Big               `json:"total_collateral"`
+	TotalDebt       *util.Big               `json:"total_debt"`
+	SystemRatio     uint64                  `json:"system_ratio"`
+	Vaults          int                     `json:"vaults"`
+	Oracle          *state.StablecoinOracle `json:"oracle"`
+}
+
+type vault struct {
+	collateral *big.Int
+	debt       *big.Int
+}
+
+// Stablecoin keeps GYD collateralized: owners lock GYDS in vaults to mint
+// GYD up to the minimum collateral ratio, and anyone may liquidate a vault
+// that falls below it. Prices are the medians the validator oracle
+// commits, aged in blocks so every node agrees on freshness. It assumes
+// GYDS and GYD share decimals.
+type Stablecoin struct {
+	mu       sync.RWMutex
+	peg      string
+	minRatio uint64 // percent
+	maxAge   uint64 // blocks
+	prices   map[string]*pos.OraclePrice
+	oracle   *state.StablecoinOracle
+	vaults   map[string]*vault
+}
+
+// NewStablecoin creates the vault system for a stablecoin pegged to peg,
+// requiring minRatio percent collateral and ignoring oracle prices
+// committed more than maxAge blocks ago
+func NewStablecoin(peg string, minRatio, maxAge uint64) *Stablecoin {
+	return &Stablecoin{
+		peg:      peg,
+		minRatio: minRatio,
+		maxAge:   maxAge,
+		prices:   make(map[string]*pos.OraclePrice),
+		oracle:   state.NewStablecoinOracle(StablecoinAsset, peg),
+		vaults:   make(map[string]*vault),
+	}
+}
+
+// OracleMaxAge returns the age, in blocks, after which a committed price
+// is stale because the oracle stopped committing new ones
+func OracleMaxAge(window uint64) uint64 {
+	return oracleStaleFactor * window
+}
+
+// commitPrices takes the prices the oracle committed in the block at
+// timestamp
+func (s *Stablecoin) commitPrices(prices []*pos.OraclePrice, timestamp int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range prices {
+		s.prices[p.Asset] = p
+		if p.Asset == StablecoinAsset {
+			s.oracle.UpdatePrice(float64(p.Price)/oracleUnit, p.Voters, timestamp)
+		}
+	}
+}
+
+// price returns asset's committed price if still fresh at height, or zero;
+// callers must hold s.mu
+func (s *Stablecoin) price(asset string, height uint64) uint64 {
+	p := s.prices[asset]
+	if p == nil || p.Height+s.maxAge < height {
+		return 0
+	}
+	return p.Price
+}
+
+// ratio returns collateral's value at price over debt in percent
+func ratio(collateral, debt *big.Int, price uint64) uint64 {
+	if debt.Sign() == 0 || price == 0 {
+		return 0
+	}
+	value := new(big.Int).Mul(collateral, new(big.Int).SetUint64(price))
+	value.Mul(value, big.NewInt(100))
+	value.Quo(value, new(big.Int).Mul(debt, big.NewInt(oracleUnit)))
+	if !value.IsUint64() {
+		return ^uint64(0)
+	}
+	return value.Uint64()
+}
+
+// mintingPaused reports whether GYD trades too far below its peg; callers
+// must hold s.mu
+func (s *Stablecoin) mintingPaused(height uint64) bool {
+	price := s.price(StablecoinAsset, height)
+	return price > 0 && deviation(price) < -PegTolerance
+}
+
+// Snapshot captures the vaults and committed prices and returns a function
+// restoring them
+func (s *Stablecoin) Snapshot() func() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prices := make(map[string]*pos.OraclePrice, len(s.prices))
+	for asset, p := range s.prices {
+		prices[asset] = p
+	}
+	vaults := make(map[string]*vault, len(s.vaults))
+	for owner, v := range s.vaults {
+		copy := *v
+		vaults[owner] = &copy
+	}
+	oracle := *s.oracle
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.prices, s.vaults = prices, vaults
+		*s.oracle = oracle
+	}
+}
+
+// stablecoinState is the vault system's exported state
+type stablecoinState struct {
+	Prices map[string]*pos.OraclePrice `json:"prices"`
+	Vaults map[string]*vaultState      `json:"vaults"`
+	Oracle *state.StablecoinOracle     `json:"oracle"`
+}
+
+// vaultState is one vault's exported state
+type vaultState struct {
+	Collateral *util.Big `json:"collateral"`
+	Debt       *util.Big `json:"debt"`
+}
+
+// ExportState encodes the vaults and committed prices for chain snapshots
+func (s *Stablecoin) ExportState() (json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vaults := make(map[string]*vaultState, len(s.vaults))
+	for owner, v := range s.vaults {
+		vaults[owner] = &vaultState{(*util.Big)(v.collateral), (*util.Big)(v.debt)}
+	}
+	return json.Marshal(stablecoinState{s.prices, vaults, s.oracle})
+}
+
+// RestoreState replaces the vaults and committed prices with exported ones
+func (s *Stablecoin) RestoreState(data json.RawMessage) error {
+	var exported stablecoinState
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return err
+	}
+	if exported.Oracle == nil {
+		return ErrInvalidSnapshot
+	}
+	if exported.Prices == nil {
+		exported.Prices = make(map[string]*pos.OraclePrice)
+	}
+	vaults := make(map[string]*vault, len(exported.Vaults))
+	for owner, v := range exported.Vaults {
+		if v == nil {
+			return ErrInvalidSnapshot
+		}
+		vaults[owner] = &vault{collateral: v.Collateral.Int(), debt: v.Debt.Int()}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices, s.vaults = exported.Prices, vaults
+	*s.oracle = *exported.Oracle
+	return nil
+}
+
+// deviation is price's distance from the peg in basis points
+func deviation(price uint64) int64 {
+	return (int64(price) - oracleUnit) * 10000 / oracleUnit
+}
+
+// lock adds collateral to owner's vault and mints against it
+func (s *Stablecoin) lock(owner string, collateral, mint *big.Int, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.vaults[owner]
+	if v == nil {
+		v = &vault{collateral: new(big.Int), debt: new(big.Int)}
+	}
+	newCollateral := new(big.Int).Add(v.collateral, collateral)
+	newDebt := new(big.Int).Add(v.debt, mint)
+
+	if mint.Sign() > 0 {
+		if s.mintingPaused(height) {
+			return ErrPegBroken
+		}
+		price := s.price(CollateralAsset, height)
+		if price == 0 {
+			return ErrNoPrice
+		}
+		if ratio(newCollateral, newDebt, price) < s.minRatio {
+			return ErrUndercollateralized
+		}
+	}
+
+	v.collateral, v.debt = newCollateral, newDebt
+	s.vaults[owner] = v
+	return nil
+}
+
+// unlock repays debt on owner's vault and then withdraws collateral
+func (s *Stablecoin) unlock(owner string, withdraw, repay *big.Int, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.vaults[owner]
+	if v == nil {
+		return ErrVaultNotFound
+	}
+	if repay.Cmp(v.debt) > 0 {
+		return ErrRepayExceedsDebt
+	}
+	if withdraw.Cmp(v.collateral) > 0 {
+		return ErrWithdrawExceedsVault
+	}
+	newCollateral := new(big.Int).Sub(v.collateral, withdraw)
+	newDebt := new(big.Int).Sub(v.debt, repay)
+
+	if newDebt.Sign() > 0 && withdraw.Sign() > 0 {
+		price := s.price(CollateralAsset, height)
+		if price == 0 {
+			return ErrNoPrice
+		}
+		if ratio(newCollateral, newDebt, price) < s.minRatio {
+			return ErrUndercollateralized
+		}
+	}
+
+	if newCollateral.Sign() == 0 && newDebt.Sign() == 0 {
+		delete(s.vaults, owner)
+		return nil
+	}
+	v.collateral, v.debt = newCollateral, newDebt
+	return nil
+}
+
+// liquidation works out what liquidating owner's vault at height would
+// repay, seize for the liquidator and refund to the owner
+func (s *Stablecoin) liquidation(owner string, height uint64) (debt, seized, refund *big.Int, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v := s.vaults[owner]
+	if v == nil || v.debt.Sign() == 0 {
+		return nil, nil, nil, ErrVaultNotFound
+	}
+	price := s.price(CollateralAsset, height)
+	if price == 0 {
+		return nil, nil, nil, ErrNoPrice
+	}
+	if ratio(v.collateral, v.debt, price) >= s.minRatio {
+		return nil, nil, nil, ErrVaultSafe
+	}
+
+	seized = new(big.Int).Mul(v.debt, big.NewInt(100+LiquidationPenalty))
+	seized.Mul(seized, big.NewInt(oracleUnit/100))
+	seized.Quo(seized, new(big.Int).SetUint64(price))
+	if seized.Cmp(v.collateral) > 0 {
+		seized.Set(v.collateral)
+	}
+	return util.CopyBig(v.debt), seized, new(big.Int).Sub(v.collateral, seized), nil
+}
+
+// close removes a liquidated vault
+func (s *Stablecoin) close(owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vaults, owner)
+}
+
+// Vault returns owner's vault valued at the prices fresh at height
+func (s *Stablecoin) Vault(owner string, height uint64) (*Vault, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v := s.vaults[owner]
+	if v == nil {
+		return nil, ErrVaultNotFound
+	}
+	price := s.price(CollateralAsset, height)
+	return &Vault{
+		Owner:      owner,
+		Collateral: (*util.Big)(util.CopyBig(v.collateral)),
+		Debt:       (*util.Big)(util.CopyBig(v.debt)),
+		Ratio:      ratio(v.collateral, v.debt, price),
+	}, nil
+}
+
+// Status returns the peg and collateralization as of height
+func (s *Stablecoin) Status(height uint64) *PegStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	collateralPrice := s.price(CollateralAsset, height)
+	stablePrice := s.price(StablecoinAsset, height)
+	totalCollateral, totalDebt := new(big.Int), new(big.Int)
+	for _, v := range s.vaults {
+		totalCollateral.Add(totalCollateral, v.collateral)
+		totalDebt.Add(totalDebt, v.debt)
+	}
+
+	oracle := *s.oracle
+	oracle.Sources = append([]string(nil), s.oracle.Sources...)
+	status := &PegStatus{
+		Peg:             s.peg,
+		CollateralPrice: collateralPrice,
+		StablecoinPrice: stablePrice,
+		MintingPaused:   s.mintingPaused(height),
+		MinRatio:        s.minRatio,
+		TotalCollateral: (*util.Big)(totalCollateral),
+		TotalDebt:       (*util.Big)(totalDebt),
+		SystemRatio:     ratio(totalCollateral, totalDebt, collateralPrice),
+		Vaults:          len(s.vaults),
+		Oracle:          &oracle,
+	}
+	if stablePrice > 0 {
+		status.Deviation = deviation(stablePrice)
+	}
+	return status
+}
+
+// SetStablecoin attaches the vault system; without one, vault transactions
+// are rejected
+func (c *Chain) SetStablecoin(s *Stablecoin) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stablecoin = s
+}
+
+// Stablecoin returns the attached vault system, if any
+func (c *Chain) Stablecoin() *Stablecoin {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stablecoin
+}
+
+// processVaultLock moves Amount GYDS from the sender into its vault and
+// mints the payload's GYD to the sender
+func (c *Chain) processVaultLock(transaction *tx.Transaction, height uint64) error {
+	if c.stablecoin == nil {
+		return ErrStablecoinNotConfigured
+	}
+
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	mint := new(big.Int)
+	if p, ok := payload.(*tx.VaultLockPayload); ok && p.Mint != nil {
+		mint = p.Mint.Int()
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if !sender.SubBalance(CollateralAsset, transaction.Amount) {
+		return errors.New("insufficient balance")
+	}
+	if err := c.stablecoin.lock(transaction.From, transaction.Amount, mint, height); err != nil {
+		return err
+	}
+
+	if mint.Sign() > 0 {
+		if err := c.adjustStableSupply(mint); err != nil {
+			return err
+		}
+		sender.AddBalance(StablecoinAsset, mint)
+	}
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// processVaultUnlock burns the payload's GYD from the sender against its
+// vault debt and returns Amount GYDS of collateral
+func (c *Chain) processVaultUnlock(transaction *tx.Transaction, height uint64) error {
+	if c.stablecoin == nil {
+		return ErrStablecoinNotConfigured
+	}
+
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+	repay := new(big.Int)
+	if p, ok := payload.(*tx.VaultUnlockPayload); ok && p.Repay != nil {
+		repay = p.Repay.Int()
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if !sender.SubBalance(StablecoinAsset, repay) {
+		return errors.New("insufficient balance")
+	}
+	if err := c.stablecoin.unlock(transaction.From, transaction.Amount, repay, height); err != nil {
+		return err
+	}
+
+	if repay.Sign() > 0 {
+		if err := c.adjustStableSupply(new(big.Int).Neg(repay)); err != nil {
+			return err
+		}
+	}
+	sender.AddBalance(CollateralAsset, transaction.Amount)
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// processVaultLiquidate has the sender repay the undercollateralized vault
+// of To in full. The sender gets collateral worth the debt plus the
+// liquidation penalty and the owner keeps whatever collateral is left.
+func (c *Chain) processVaultLiquidate(transaction *tx.Transaction, height uint64) error {
+	if c.stablecoin == nil {
+		return ErrStablecoinNotConfigured
+	}
+
+	debt, seized, refund, err := c.stablecoin.liquidation(transaction.To, height)
+	if err != nil {
+		return err
+	}
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	if !sender.SubBalance(StablecoinAsset, debt) {
+		return errors.New("insufficient balance")
+	}
+	if err := c.adjustStableSupply(new(big.Int).Neg(debt)); err != nil {
+		return err
+	}
+	c.stablecoin.close(transaction.To)
+
+	sender.AddBalance(CollateralAsset, seized)
+	c.stateDB.SetAccount(transaction.From, sender)
+	if refund.Sign() > 0 {
+		owner := c.stateDB.GetAccount(transaction.To)
+		if owner == nil {
+			owner = state.NewAccount(transaction.To)
+		}
+		owner.AddBalance(CollateralAsset, refund)
+		c.stateDB.SetAccount(transaction.To, owner)
+	}
+	return nil
+}
+
+// adjustStableSupply mints (positive) or burns (negative) GYD supply when
+// the asset is registered in state
+func (c *Chain) adjustStableSupply(delta *big.Int) error {
+	asset := c.stateDB.GetAsset(StablecoinAsset)
+	if asset == nil {
+		return nil
+	}
+	var err error
+	if delta.Sign() > 0 {
+		err = asset.Mint(delta)
+	} else {
+		err = asset.Burn(new(big.Int).Neg(delta))
+	}
+	if err != nil {
+		return err
+	}
+	c.stateDB.SetAsset(StablecoinAsset, asset)
+	return nil
+}
@@ -0,0 +1,127 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// Staking is the consensus engine's record of bonded stake, which stake
+// transactions keep in step with the delegations on accounts and every
+// block pays its reward across
+type Staking interface {
+	Delegate(delegator, validator string, amount *big.Int) error
+	Undelegate(delegator, validator string, amount *big.Int, height uint64) (*pos.UnbondingEntry, error)
+	Unbonding() *pos.UnbondingQueue
+	ProcessRewards(blockReward *big.Int)
+	RegisterValidator(address, pubKey string, stake *big.Int) error
+	SetCommission(address string, commission uint64) error
+}
+
+// Staking errors
+var (
+	ErrStakingNotConfigured = errors.New("validator registration requires a staking engine")
+	ErrValidatorSelfStake   = errors.New("validator registration must stake to the sender")
+)
+
+// SetStaking attaches the engine stake transactions bond to. Its unbonding
+// queue becomes the chain's, so stake the engine releases is credited back
+// when the queue matures it.
+func (c *Chain) SetStaking(staking Staking) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.staking = staking
+	c.unbonding = staking.Unbonding()
+}
+
+// processStake bonds the sender's GYDS to the validator in To, or for an
+// unstake starts unbonding it. A stake whose payload names a public key
+// registers the sender as a validator instead, bonded with the stake.
+func (c *Chain) processStake(transaction *tx.Transaction, height uint64) error {
+	if transaction.Asset != "GYDS" {
+		return tx.ErrInvalidAsset
+	}
+	payload, err := tx.DecodePayload(transaction)
+	if err != nil {
+		return err
+	}
+
+	sender, err := c.chargeFee(transaction)
+	if err != nil {
+		return err
+	}
+	switch p, _ := payload.(*tx.StakePayload); {
+	case transaction.Type == tx.TxTypeUnstake:
+		err = c.unbond(sender, transaction.From, transaction.To, transaction.Amount, height)
+	case p != nil && p.PubKey != "":
+		err = c.registerValidator(sender, transaction, p)
+	default:
+		err = c.bond(sender, transaction.From, transaction.To, transaction.Amount)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.stateDB.SetAccount(transaction.From, sender)
+	return nil
+}
+
+// registerValidator bonds the sender's stake to itself and registers it
+// with the engine as a validator under the payload's key
+func (c *Chain) registerValidator(account *state.Account, transaction *tx.Transaction, p *tx.StakePayload) error {
+	if c.staking == nil {
+		return ErrStakingNotConfigured
+	}
+	if transaction.To != transaction.From {
+		return ErrValidatorSelfStake
+	}
+
+	if !account.Delegate(transaction.From, transaction.Amount) {
+		return errors.New("insufficient balance")
+	}
+	if err := c.staking.RegisterValidator(transaction.From, p.PubKey, transaction.Amount); err != nil {
+		return err
+	}
+	if p.Commission > 0 {
+		return c.staking.SetCommission(transaction.From, p.Commission)
+	}
+	return nil
+}
+
+// bond moves amount of account's GYDS balance into its delegation to
+// validator and adds it to the validator's stake in the engine
+func (c *Chain) bond(account *state.Account, delegator, validator string, amount *big.Int) error {
+	if !account.Delegate(validator, amount) {
+		return errors.New("insufficient balance")
+	}
+	if c.staking != nil {
+		return c.staking.Delegate(delegator, validator, amount)
+	}
+	return nil
+}
+
+// unbond takes amount off account's delegation to validator. With an
+// unbonding queue the stake waits out the unbonding period there, queued
+// by the engine when one is attached; without one it returns to the
+// balance at once.
+func (c *Chain) unbond(account *state.Account, delegator, validator string, amount *big.Int, height uint64) error {
+	if c.unbonding == nil {
+		if !account.Undelegate(validator, amount) {
+			return errors.New("insufficient delegation")
+		}
+		return nil
+	}
+
+	if !account.Unbond(validator, amount) {
+		return errors.New("insufficient delegation")
+	}
+	if c.staking != nil {
+		_, err := c.staking.Undelegate(delegator, validator, amount, height)
+		return err
+	}
+	c.unbonding.Add(delegator, validator, amount, height)
+	return nil
+}
@@ -0,0 +1,173 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+func TestStakeAndUnstake(t *testing.T) {
+	c, mempool := newTestChain(t, "gyds1alice")
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	engine.SetUnbondingTime(10 * time.Second)
+	if err := engine.RegisterValidator("gyds1validator1", "", big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	c.SetStaking(engine)
+
+	balance := func() *big.Int { return c.stateDB.GetAccount("gyds1alice").GetBalance("GYDS") }
+	stake := func() *big.Int {
+		v, err := engine.GetValidator("gyds1validator1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.TotalStake
+	}
+	before := balance()
+
+	// Staking debits the balance and bonds it on the account and in the engine
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeStake, "gyds1alice", "gyds1validator1", 1000, 0)); err != nil {
+		t.Fatalf("stake: %v", err)
+	}
+	spent := new(big.Int).Sub(before, balance())
+	if spent.Cmp(big.NewInt(1000+1e9)) != 0 {
+		t.Errorf("expected the stake and fee debited, got %s", spent)
+	}
+	if got := c.stateDB.GetAccount("gyds1alice").GetDelegation("gyds1validator1"); got.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected a delegation of 1000, got %s", got)
+	}
+	if got := stake(); got.Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("expected the validator's stake to reach 2000, got %s", got)
+	}
+
+	// More than is bonded cannot be unstaked
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeUnstake, "gyds1alice", "gyds1validator1", 1001, 1)); err == nil {
+		t.Error("expected unstaking more than the delegation to fail")
+	}
+
+	// Unstaking leaves the engine at once and waits in its unbonding queue
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeUnstake, "gyds1alice", "gyds1validator1", 400, 1)); err != nil {
+		t.Fatalf("unstake: %v", err)
+	}
+	unstaked := c.Height()
+	if got := stake(); got.Cmp(big.NewInt(1600)) != 0 {
+		t.Errorf("expected the validator's stake to drop to 1600, got %s", got)
+	}
+	if entries := engine.Unbonding().Delegations("gyds1alice"); len(entries) != 1 {
+		t.Fatalf("expected one unbonding entry, got %d", len(entries))
+	}
+	held := balance()
+
+	for c.Height() < unstaked+engine.Unbonding().Period() {
+		if err := addTestBlock(t, c, mempool); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := new(big.Int).Sub(balance(), held); got.Cmp(big.NewInt(400)) != 0 {
+		t.Errorf("expected 400 returned once unbonded, got %s", got)
+	}
+	if got := c.stateDB.GetAccount("gyds1alice").GetDelegation("gyds1validator1"); got.Cmp(big.NewInt(600)) != 0 {
+		t.Errorf("expected a delegation of 600 left, got %s", got)
+	}
+}
+
+func TestStakingRewards(t *testing.T) {
+	c, mempool := newTestChain(t, "gyds1alice")
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	if err := engine.RegisterValidator("gyds1validator1", "", big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	c.SetStaking(engine)
+	c.SetRewardSource(engine)
+	if c.blockReward.Sign() == 0 {
+		t.Fatal("expected the genesis to pay a block reward")
+	}
+
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeStake, "gyds1alice", "gyds1validator1", 1000, 0)); err != nil {
+		t.Fatalf("stake: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := addTestBlock(t, c, mempool); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every block since the stake paid the delegator half of its reward,
+	// less the validator's commission
+	pending := engine.PendingRewards("gyds1alice")
+	if len(pending) != 1 || pending[0].Pending.Int().Sign() == 0 {
+		t.Fatalf("expected rewards accrued with the validator, got %+v", pending)
+	}
+	owed := util.CopyBig(pending[0].Pending.Int())
+	if max := new(big.Int).Mul(c.blockReward, big.NewInt(2)); owed.Cmp(max) > 0 {
+		t.Errorf("expected at most half of four block rewards, got %s", owed)
+	}
+
+	before := c.stateDB.GetAccount("gyds1alice").GetBalance("GYDS")
+	if err := addTestBlock(t, c, mempool, testTx(tx.TxTypeWithdrawRewards, "gyds1alice", "gyds1validator1", 0, 1)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	gained := new(big.Int).Sub(c.stateDB.GetAccount("gyds1alice").GetBalance("GYDS"), before)
+	if want := new(big.Int).Sub(owed, big.NewInt(1e9)); gained.Cmp(want) != 0 {
+		t.Errorf("expected the accrued %s paid out less the fee, got %s", owed, gained)
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	c, mempool := newTestChain(t, "gyds1bob")
+	engine := pos.NewEngine(big.NewInt(1000), 10, 5*time.Second)
+	c.SetStaking(engine)
+
+	register := func(stake int64, commission, nonce uint64) *tx.Transaction {
+		transaction := tx.NewStake("gyds1bob", big.NewInt(stake), "gyds1bob")
+		payload := &tx.StakePayload{PubKey: "gyds1bob_pubkey", Commission: commission}
+		if err := transaction.SetPayload(payload); err != nil {
+			t.Fatal(err)
+		}
+		transaction.Nonce = nonce
+		transaction.Fee = big.NewInt(1e9)
+		transaction.Sign([]byte("key"))
+		return transaction
+	}
+	balance := func() *big.Int { return c.stateDB.GetAccount("gyds1bob").GetBalance("GYDS") }
+	before := balance()
+
+	// A stake below the engine's minimum registers nothing
+	if err := addTestBlock(t, c, mempool, register(999, 0, 0)); err == nil {
+		t.Error("expected a registration below the minimum stake to fail")
+	}
+	if balance().Cmp(before) != 0 {
+		t.Errorf("expected a failed registration to leave the balance, got %s", balance())
+	}
+
+	// Registering bonds the stake from the balance to the new validator
+	if err := addTestBlock(t, c, mempool, register(1000, 500, 0)); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	spent := new(big.Int).Sub(before, balance())
+	if spent.Cmp(big.NewInt(1000+1e9)) != 0 {
+		t.Errorf("expected the stake and fee debited, got %s", spent)
+	}
+	if got := c.stateDB.GetAccount("gyds1bob").GetDelegation("gyds1bob"); got.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected a self-delegation of 1000, got %s", got)
+	}
+	v, err := engine.GetValidator("gyds1bob")
+	if err != nil {
+		t.Fatalf("expected the validator registered: %v", err)
+	}
+	if v.PubKey != "gyds1bob_pubkey" || v.SelfStake.Cmp(big.NewInt(1000)) != 0 || v.Commission != 500 {
+		t.Errorf("unexpected validator %+v", v)
+	}
+
+	// Only the sender can be registered
+	other := register(1000, 0, 1)
+	other.To = "gyds1carol"
+	other.Sign([]byte("key"))
+	if err := addTestBlock(t, c, mempool, other); err != ErrValidatorSelfStake {
+		t.Errorf("expected ErrValidatorSelfStake, got %v", err)
+	}
+}
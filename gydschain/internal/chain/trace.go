@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// TxTrace records how one transaction executed while a block was added
+type TxTrace struct {
+	Index     uint32    `json:"index"`
+	Hash      string    `json:"hash"`
+	Type      string    `json:"type"`
+	From      string    `json:"from"`
+	To        string    `json:"to,omitempty"`
+	Asset     string    `json:"asset"`
+	Amount    *util.Big `json:"amount"`
+	Fee       *util.Big `json:"fee"`
+	GasUsed   uint64    `json:"gas_used"`
+	StateRoot string    `json:"state_root,omitempty"` // uncommitted root after the transaction
+	Error     string    `json:"error,omitempty"`
+}
+
+// TxTracer receives a trace for every transaction executed at height. It
+// runs with the chain locked and must not call back into the chain.
+type TxTracer func(height uint64, trace *TxTrace)
+
+// SetTracer enables per-transaction tracing; nil disables it. Tracing
+// computes a state root after every transaction, so it is meant for replay
+// rather than a live node.
+func (c *Chain) SetTracer(fn TxTracer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracer = fn
+}
+
+// traceTx reports a transaction to the tracer, if any; callers must hold c.mu
+func (c *Chain) traceTx(height uint64, index uint32, transaction *tx.Transaction, execErr error) {
+	if c.tracer == nil {
+		return
+	}
+
+	trace := &TxTrace{
+		Index:   index,
+		Type:    transaction.Type,
+		From:    transaction.From,
+		To:      transaction.To,
+		Asset:   transaction.Asset,
+		Amount:  (*util.Big)(util.CopyBig(transaction.Amount)),
+		Fee:     (*util.Big)(util.CopyBig(transaction.Fee)),
+		GasUsed: c.gas.TxGas(transaction),
+	}
+	trace.Hash, _ = transaction.HashHex()
+	if execErr != nil {
+		trace.Error = execErr.Error()
+	} else if root, err := c.stateDB.IntermediateRoot(); err == nil {
+		trace.StateRoot = root
+	} else {
+		trace.Error = err.Error()
+	}
+	c.tracer(height, trace)
+}
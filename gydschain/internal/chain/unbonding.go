@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+)
+
+// SetUnbonding attaches the queue that holds undelegated stake until the
+// unbonding period has passed. Without one, undelegated stake is returned
+// to the balance at once.
+func (c *Chain) SetUnbonding(queue *pos.UnbondingQueue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unbonding = queue
+}
+
+// Unbonding returns the attached unbonding queue, if any
+func (c *Chain) Unbonding() *pos.UnbondingQueue {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.unbonding
+}
+
+// releaseUnbondings credits every unbonding that matures at height back to
+// its delegator's GYDS balance; callers must hold c.mu
+func (c *Chain) releaseUnbondings(height uint64) {
+	if c.unbonding == nil {
+		return
+	}
+
+	for _, entry := range c.unbonding.Mature(height) {
+		account := c.stateDB.GetAccount(entry.Delegator)
+		if account == nil {
+			account = state.NewAccount(entry.Delegator)
+		}
+		account.AddBalance("GYDS", entry.Amount.Int())
+		c.stateDB.SetAccount(entry.Delegator, account)
+	}
+}
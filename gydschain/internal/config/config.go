@@ -9,9 +9,10 @@ import (
 // Config represents the node configuration
 type Config struct {
 	// Node identity
-	NodeID   string `json:"node_id"`
-	DataDir  string `json:"data_dir"`
-	LogLevel string `json:"log_level"`
+	NodeID    string `json:"node_id"`
+	DataDir   string `json:"data_dir"`
+	LogLevel  string `json:"log_level"`  // debug, info, warn or error
+	LogFormat string `json:"log_format"` // text or json
 
 	// Network configuration
 	Network NetworkConfig `json:"network"`
@@ -30,6 +31,9 @@ type Config struct {
 
 	// Database configuration
 	Database DatabaseConfig `json:"database"`
+
+	// Experimental feature flags
+	Experimental ExperimentalConfig `json:"experimental"`
 }
 
 // NetworkConfig contains P2P network settings
@@ -41,17 +45,37 @@ type NetworkConfig struct {
 	MinPeers       int      `json:"min_peers"`
 	EnableNAT      bool     `json:"enable_nat"`
 	EnableUPnP     bool     `json:"enable_upnp"`
+	ClockCheck     bool     `json:"clock_check"`     // SNTP drift check at startup and periodically
+	NTPServers     []string `json:"ntp_servers"`
+	MaxClockDrift  int      `json:"max_clock_drift"` // milliseconds before validation is refused
+	ClockInterval  int      `json:"clock_interval"`  // seconds between drift checks
+	AdminURL       string   `json:"admin_url"`       // admin server to report bans to and pull the greylist from
+	GreylistSync   int      `json:"greylist_sync"`   // seconds between ban exchanges with the admin server
 }
 
 // ChainConfig contains blockchain settings
 type ChainConfig struct {
-	ChainID         string `json:"chain_id"`
-	NetworkID       uint64 `json:"network_id"`
-	GenesisFile     string `json:"genesis_file"`
-	BlockTime       uint64 `json:"block_time"`       // seconds
-	BlockGasLimit   uint64 `json:"block_gas_limit"`
-	MinGasPrice     string `json:"min_gas_price"`
-	MaxTxPerBlock   int    `json:"max_tx_per_block"`
+	ChainID            string   `json:"chain_id"`
+	NetworkID          uint64   `json:"network_id"`
+	GenesisFile        string   `json:"genesis_file"`
+	BlockTime          uint64   `json:"block_time"` // seconds
+	BlockGasLimit      uint64   `json:"block_gas_limit"`
+	BlockGasTarget     uint64   `json:"block_gas_target"` // minimum gas target; grows toward half the limit under load
+	MinGasPrice        string   `json:"min_gas_price"`
+	LogRetention       uint64   `json:"log_retention"`       // blocks kept in the on-node log index
+	MaxLogRange        uint64   `json:"max_log_range"`       // blocks a single log query may span
+	MaxLogResults      int      `json:"max_log_results"`     // logs a single query may return
+	Guardians          []string `json:"guardians"`           // bootstrap halt multisig
+	GuardianThreshold  int      `json:"guardian_threshold"`  // guardian votes needed to halt/resume
+	Archive            bool     `json:"archive"`             // keep state for every height
+	StateHistory       uint64   `json:"state_history"`       // heights of state kept when not archiving
+	SnapshotInterval   uint64   `json:"snapshot_interval"`   // blocks between on-disk state snapshots; 0 disables
+	SnapshotKeep       int      `json:"snapshot_keep"`       // on-disk state snapshots retained
+	FastSync           bool     `json:"fast_sync"`           // restore a peer snapshot at startup instead of syncing from genesis
+	FastSyncPeers      int      `json:"fast_sync_peers"`     // peers that must offer the same snapshot
+	CheckpointInterval uint64   `json:"checkpoint_interval"` // blocks between signed checkpoints; 0 disables
+	BeaconEpoch        uint64   `json:"beacon_epoch"`        // blocks per randomness beacon epoch
+	MaxMemoSize        int      `json:"max_memo_size"`       // bytes of memo the mempool accepts per tx
 }
 
 // RPCConfig contains RPC server settings
@@ -63,8 +87,15 @@ type RPCConfig struct {
 	WSPort        int      `json:"ws_port"`
 	CORSOrigins   []string `json:"cors_origins"`
 	EnabledAPIs   []string `json:"enabled_apis"`
-	RateLimit     int      `json:"rate_limit"`      // requests per second
+	RateLimit     int      `json:"rate_limit"`      // requests per second per client IP; 0 disables
+	RateBurst     int      `json:"rate_burst"`      // requests a client IP may send at once
+	MaxBodySize   int64    `json:"max_body_size"`   // bytes of an HTTP body or WebSocket message
+	TrustProxy    bool     `json:"trust_proxy"`     // identify clients by X-Forwarded-For
 	MaxBatchSize  int      `json:"max_batch_size"`
+	ReadOnly      bool     `json:"read_only"`       // refuse tx submission, staking and mining
+	APIKeys       []string `json:"api_keys"`        // static keys accepted as credentials
+	JWTSecret     string   `json:"jwt_secret"`      // hex HS256 secret for bearer JWTs
+	RequireAuth   bool     `json:"require_auth"`    // refuse unauthenticated callers for every method
 }
 
 // MiningConfig contains mining settings
@@ -75,6 +106,8 @@ type MiningConfig struct {
 	ExtraData    string `json:"extra_data"`
 	PoolMode     bool   `json:"pool_mode"`
 	PoolAddr     string `json:"pool_addr"`
+	Backend      string `json:"backend"` // cpu, external (getwork/stratum miners) or a registered GPU backend
+	Devices      []int  `json:"devices"` // GPU device indices; empty uses every device
 }
 
 // ValidatorConfig contains validator settings
@@ -94,12 +127,38 @@ type DatabaseConfig struct {
 	Compression bool   `json:"compression"`
 }
 
+// ExperimentalConfig enables subsystems that ship dark. A feature gates
+// both its transaction types and its RPC namespace, and every node on a
+// network must enable the same set.
+type ExperimentalConfig struct {
+	Governance bool `json:"governance"`
+	DEX        bool `json:"dex"`
+	WASM       bool `json:"wasm"`
+}
+
+// Enabled returns the names of the enabled features, matching the
+// tx.Feature constants
+func (e ExperimentalConfig) Enabled() []string {
+	var names []string
+	if e.Governance {
+		names = append(names, "governance")
+	}
+	if e.DEX {
+		names = append(names, "dex")
+	}
+	if e.WASM {
+		names = append(names, "wasm")
+	}
+	return names
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		NodeID:   "",
-		DataDir:  "./data",
-		LogLevel: "info",
+		NodeID:    "",
+		DataDir:   "./data",
+		LogLevel:  "info",
+		LogFormat: "text",
 		Network: NetworkConfig{
 			ListenAddr:     "0.0.0.0:30303",
 			ExternalAddr:   "",
@@ -108,15 +167,30 @@ func DefaultConfig() *Config {
 			MinPeers:       10,
 			EnableNAT:      true,
 			EnableUPnP:     true,
+			ClockCheck:     true,
+			NTPServers:     []string{"pool.ntp.org", "time.google.com", "time.cloudflare.com"},
+			MaxClockDrift:  500,
+			ClockInterval:  600,
+			GreylistSync:   300,
 		},
 		Chain: ChainConfig{
-			ChainID:       "gydschain-1",
-			NetworkID:     1,
-			GenesisFile:   "./genesis.json",
-			BlockTime:     5,
-			BlockGasLimit: 10000000,
-			MinGasPrice:   "1000000000", // 1 gwei
-			MaxTxPerBlock: 1000,
+			ChainID:            "gydschain-1",
+			NetworkID:          1,
+			GenesisFile:        "./genesis.json",
+			BlockTime:          5,
+			BlockGasLimit:      10000000,
+			BlockGasTarget:     2500000,
+			MinGasPrice:        "1000000000", // 1 gwei
+			LogRetention:       10000,
+			MaxLogRange:        5000,
+			MaxLogResults:      10000,
+			StateHistory:       128,
+			SnapshotInterval:   10000,
+			SnapshotKeep:       2,
+			FastSyncPeers:      2,
+			CheckpointInterval: 100,
+			BeaconEpoch:        100,
+			MaxMemoSize:        256,
 		},
 		RPC: RPCConfig{
 			Enabled:      true,
@@ -125,8 +199,11 @@ func DefaultConfig() *Config {
 			WSAddr:       "127.0.0.1",
 			WSPort:       8546,
 			CORSOrigins:  []string{"*"},
-			EnabledAPIs:  []string{"chain", "account", "tx", "net"},
+			EnabledAPIs:  []string{"chain", "account", "state", "tx", "beacon", "oracle", "validator",
+				"staking", "stablecoin", "asset", "nft", "net", "node", "rpc", "mining"},
 			RateLimit:    100,
+			RateBurst:    200,
+			MaxBodySize:  1 << 20,
 			MaxBatchSize: 100,
 		},
 		Mining: MiningConfig{
@@ -136,6 +213,7 @@ func DefaultConfig() *Config {
 			ExtraData:    "",
 			PoolMode:     false,
 			PoolAddr:     "",
+			Backend:      "cpu",
 		},
 		Validator: ValidatorConfig{
 			Enabled:      false,
@@ -12,6 +12,7 @@ type Flags struct {
 	ConfigFile string
 	DataDir    string
 	LogLevel   string
+	LogFormat  string
 	Version    bool
 	Help       bool
 
@@ -28,11 +29,13 @@ type Flags struct {
 	WSAddr      string
 	WSPort      int
 	CORSOrigins string
+	RPCReadOnly bool
 
 	// Mining
 	MiningEnabled bool
 	MinerAddress  string
 	MiningThreads int
+	MinerBackend  string
 
 	// Validator
 	ValidatorEnabled bool
@@ -53,6 +56,7 @@ func ParseFlags() *Flags {
 	flag.StringVar(&f.ConfigFile, "config", "", "Path to configuration file")
 	flag.StringVar(&f.DataDir, "datadir", "./data", "Data directory path")
 	flag.StringVar(&f.LogLevel, "loglevel", "info", "Log level (debug, info, warn, error)")
+	flag.StringVar(&f.LogFormat, "logformat", "", "Log format (text, json)")
 	flag.BoolVar(&f.Version, "version", false, "Print version and exit")
 	flag.BoolVar(&f.Help, "help", false, "Print help and exit")
 
@@ -69,11 +73,13 @@ func ParseFlags() *Flags {
 	flag.StringVar(&f.WSAddr, "wsaddr", "127.0.0.1", "WebSocket listen address")
 	flag.IntVar(&f.WSPort, "wsport", 8546, "WebSocket port")
 	flag.StringVar(&f.CORSOrigins, "cors", "*", "Comma-separated CORS origins")
+	flag.BoolVar(&f.RPCReadOnly, "read-only", false, "Disable tx submission, staking and mining RPC methods (public gateways)")
 
 	// Mining flags
 	flag.BoolVar(&f.MiningEnabled, "mine", false, "Enable mining")
 	flag.StringVar(&f.MinerAddress, "miner", "", "Miner address for rewards")
 	flag.IntVar(&f.MiningThreads, "threads", 1, "Number of mining threads")
+	flag.StringVar(&f.MinerBackend, "miner-backend", "", "Mining backend: cpu, external or a GPU backend")
 
 	// Validator flags
 	flag.BoolVar(&f.ValidatorEnabled, "validator", false, "Run as validator")
@@ -108,6 +114,7 @@ func PrintUsage() {
 	fmt.Println("  gydschain --datadir ./node1 --rpcport 8545")
 	fmt.Println("  gydschain --validator --validatorkey ./validator.key")
 	fmt.Println("  gydschain --mine --miner 0x1234... --threads 4")
+	fmt.Println("  gydschain --read-only --rpcaddr 0.0.0.0")
 }
 
 // ApplyToConfig applies flags to a configuration
@@ -118,6 +125,9 @@ func (f *Flags) ApplyToConfig(c *Config) {
 	if f.LogLevel != "" {
 		c.LogLevel = f.LogLevel
 	}
+	if f.LogFormat != "" {
+		c.LogFormat = f.LogFormat
+	}
 
 	// Network
 	if f.ListenAddr != "" {
@@ -141,6 +151,9 @@ func (f *Flags) ApplyToConfig(c *Config) {
 	if f.WSPort > 0 {
 		c.RPC.WSPort = f.WSPort
 	}
+	if f.RPCReadOnly {
+		c.RPC.ReadOnly = true
+	}
 
 	// Mining
 	c.Mining.Enabled = f.MiningEnabled
@@ -150,6 +163,9 @@ func (f *Flags) ApplyToConfig(c *Config) {
 	if f.MiningThreads > 0 {
 		c.Mining.Threads = f.MiningThreads
 	}
+	if f.MinerBackend != "" {
+		c.Mining.Backend = f.MinerBackend
+	}
 
 	// Validator
 	c.Validator.Enabled = f.ValidatorEnabled
@@ -180,6 +196,9 @@ func (f *Flags) Validate() error {
 	if f.ValidatorEnabled && f.ValidatorKey == "" {
 		return fmt.Errorf("validator key required when validator mode is enabled")
 	}
+	if f.RPCReadOnly && (f.MiningEnabled || f.ValidatorEnabled) {
+		return fmt.Errorf("read-only mode cannot be combined with mining or validating")
+	}
 	return nil
 }
 
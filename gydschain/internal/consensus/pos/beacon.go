@@ -0,0 +1,276 @@
+package pos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+// Randomness beacon defaults
+const (
+	DefaultBeaconEpochLength = 100  // blocks; first half commits, second half reveals
+	DefaultBeaconHistory     = 1024 // finalized epochs kept for queries
+)
+
+// Beacon phases within an epoch
+const (
+	BeaconPhaseCommit = "commit"
+	BeaconPhaseReveal = "reveal"
+)
+
+// BeaconEpoch is the finalized randomness for one epoch
+type BeaconEpoch struct {
+	Epoch           uint64   `json:"epoch"`
+	Randomness      string   `json:"randomness"`
+	Contributors    []string `json:"contributors"`
+	Unrevealed      []string `json:"unrevealed"` // committed but never revealed
+	FinalizedHeight uint64   `json:"finalized_height"`
+}
+
+// BeaconStatus describes the epoch currently collecting contributions
+type BeaconStatus struct {
+	Epoch       uint64       `json:"epoch"`
+	Phase       string       `json:"phase"`
+	EpochLength uint64       `json:"epoch_length"`
+	PhaseEnds   uint64       `json:"phase_ends"` // last height of the phase
+	Commits     int          `json:"commits"`
+	Reveals     int          `json:"reveals"`
+	Latest      *BeaconEpoch `json:"latest,omitempty"`
+}
+
+// RandomnessBeacon derives unbiased per-epoch randomness from validator
+// commit-reveal contributions. Each epoch's output chains the previous one,
+// so a validator can only bias it by withholding its reveal, which is
+// recorded in Unrevealed for slashing or reputation.
+type RandomnessBeacon struct {
+	mu          sync.RWMutex
+	engine      *Engine
+	epochLength uint64
+	history     uint64
+	commits     map[uint64]map[string]string // epoch -> validator -> commitment
+	reveals     map[uint64]map[string][]byte // epoch -> validator -> secret
+	finalized   map[uint64]*BeaconEpoch
+	latest      *BeaconEpoch
+}
+
+// NewRandomnessBeacon creates a beacon; zero epochLength selects the default
+func NewRandomnessBeacon(engine *Engine, epochLength uint64) *RandomnessBeacon {
+	if epochLength < 2 {
+		epochLength = DefaultBeaconEpochLength
+	}
+	return &RandomnessBeacon{
+		engine:      engine,
+		epochLength: epochLength,
+		history:     DefaultBeaconHistory,
+		commits:     make(map[uint64]map[string]string),
+		reveals:     make(map[uint64]map[string][]byte),
+		finalized:   make(map[uint64]*BeaconEpoch),
+	}
+}
+
+// BeaconCommitment returns the commitment a validator publishes for a
+// secret; binding the address stops others replaying the commitment
+func BeaconCommitment(secret []byte, validator string) string {
+	h := sha256.New()
+	h.Write(secret)
+	h.Write([]byte(validator))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EpochOf returns the epoch containing height
+func (b *RandomnessBeacon) EpochOf(height uint64) uint64 {
+	return height / b.epochLength
+}
+
+// phaseOf returns the phase of height within its epoch
+func (b *RandomnessBeacon) phaseOf(height uint64) string {
+	if height%b.epochLength < b.epochLength/2 {
+		return BeaconPhaseCommit
+	}
+	return BeaconPhaseReveal
+}
+
+// Commit records a validator's commitment for the epoch at height
+func (b *RandomnessBeacon) Commit(validator string, epoch uint64, commitment string, height uint64) error {
+	// Check membership before locking so the beacon never holds its lock
+	// while waiting on the engine
+	if !b.isActive(validator) {
+		return ErrNotBeaconContributor
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if epoch != b.EpochOf(height) || b.phaseOf(height) != BeaconPhaseCommit {
+		return ErrBeaconWrongPhase
+	}
+	if _, exists := b.commits[epoch][validator]; exists {
+		return ErrBeaconDuplicate
+	}
+
+	if b.commits[epoch] == nil {
+		b.commits[epoch] = make(map[string]string)
+	}
+	b.commits[epoch][validator] = commitment
+	return nil
+}
+
+// Reveal records a validator's secret if it matches its commitment
+func (b *RandomnessBeacon) Reveal(validator string, epoch uint64, secret []byte, height uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if epoch != b.EpochOf(height) || b.phaseOf(height) != BeaconPhaseReveal {
+		return ErrBeaconWrongPhase
+	}
+	commitment, exists := b.commits[epoch][validator]
+	if !exists {
+		return ErrBeaconNoCommit
+	}
+	if _, exists := b.reveals[epoch][validator]; exists {
+		return ErrBeaconDuplicate
+	}
+	if BeaconCommitment(secret, validator) != commitment {
+		return ErrBeaconMismatch
+	}
+
+	if b.reveals[epoch] == nil {
+		b.reveals[epoch] = make(map[string][]byte)
+	}
+	b.reveals[epoch][validator] = append([]byte(nil), secret...)
+	return nil
+}
+
+// EndBlock finalizes the epoch when height is its last block and reseeds
+// leader selection with the new output
+func (b *RandomnessBeacon) EndBlock(height uint64) *BeaconEpoch {
+	if (height+1)%b.epochLength != 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	result := b.finalize(b.EpochOf(height), height)
+	b.mu.Unlock()
+
+	seed, _ := hex.DecodeString(result.Randomness)
+	b.engine.SetLeaderSeed(seed)
+	return result
+}
+
+// finalize mixes the revealed secrets into the chained output; callers
+// must hold b.mu
+func (b *RandomnessBeacon) finalize(epoch, height uint64) *BeaconEpoch {
+	h := sha256.New()
+	if b.latest != nil {
+		prev, _ := hex.DecodeString(b.latest.Randomness)
+		h.Write(prev)
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], epoch)
+	h.Write(buf[:])
+
+	result := &BeaconEpoch{
+		Epoch:           epoch,
+		Contributors:    make([]string, 0, len(b.reveals[epoch])),
+		Unrevealed:      make([]string, 0),
+		FinalizedHeight: height,
+	}
+	for validator := range b.reveals[epoch] {
+		result.Contributors = append(result.Contributors, validator)
+	}
+	sort.Strings(result.Contributors)
+	for _, validator := range result.Contributors {
+		h.Write([]byte(validator))
+		h.Write(b.reveals[epoch][validator])
+	}
+	for validator := range b.commits[epoch] {
+		if _, revealed := b.reveals[epoch][validator]; !revealed {
+			result.Unrevealed = append(result.Unrevealed, validator)
+		}
+	}
+	sort.Strings(result.Unrevealed)
+	result.Randomness = hex.EncodeToString(h.Sum(nil))
+
+	b.finalized[epoch] = result
+	b.latest = result
+	delete(b.commits, epoch)
+	delete(b.reveals, epoch)
+	if epoch >= b.history {
+		delete(b.finalized, epoch-b.history)
+	}
+	return result
+}
+
+// Randomness returns the finalized output for an epoch
+func (b *RandomnessBeacon) Randomness(epoch uint64) (*BeaconEpoch, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	result, exists := b.finalized[epoch]
+	if !exists {
+		return nil, ErrBeaconNotFinalized
+	}
+	return copyBeaconEpoch(result), nil
+}
+
+// Latest returns the most recently finalized output, or nil before the
+// first epoch ends
+func (b *RandomnessBeacon) Latest() *BeaconEpoch {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.latest == nil {
+		return nil
+	}
+	return copyBeaconEpoch(b.latest)
+}
+
+// Status returns progress of the epoch containing height
+func (b *RandomnessBeacon) Status(height uint64) *BeaconStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	epoch := b.EpochOf(height)
+	status := &BeaconStatus{
+		Epoch:       epoch,
+		Phase:       b.phaseOf(height),
+		EpochLength: b.epochLength,
+		Commits:     len(b.commits[epoch]),
+		Reveals:     len(b.reveals[epoch]),
+	}
+	start := epoch * b.epochLength
+	if status.Phase == BeaconPhaseCommit {
+		status.PhaseEnds = start + b.epochLength/2 - 1
+	} else {
+		status.PhaseEnds = start + b.epochLength - 1
+	}
+	if b.latest != nil {
+		status.Latest = copyBeaconEpoch(b.latest)
+	}
+	return status
+}
+
+// isActive returns true for active validators
+func (b *RandomnessBeacon) isActive(validator string) bool {
+	v, err := b.engine.GetValidator(validator)
+	return err == nil && v.Active
+}
+
+func copyBeaconEpoch(e *BeaconEpoch) *BeaconEpoch {
+	c := *e
+	c.Contributors = append([]string(nil), e.Contributors...)
+	c.Unrevealed = append([]string(nil), e.Unrevealed...)
+	return &c
+}
+
+// Randomness beacon errors
+var (
+	ErrNotBeaconContributor = &ValidatorError{"only active validators contribute to the beacon"}
+	ErrBeaconWrongPhase     = &ValidatorError{"beacon contribution outside its epoch phase"}
+	ErrBeaconDuplicate      = &ValidatorError{"beacon contribution already recorded"}
+	ErrBeaconNoCommit       = &ValidatorError{"no beacon commitment for this epoch"}
+	ErrBeaconMismatch       = &ValidatorError{"beacon reveal does not match commitment"}
+	ErrBeaconNotFinalized   = &ValidatorError{"beacon epoch not finalized or pruned"}
+)
@@ -0,0 +1,233 @@
+package pos
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Epoch summary defaults
+const (
+	DefaultEpochLength  = 100  // blocks summarized per epoch
+	DefaultEpochHistory = 1024 // summaries kept in memory; the persisted log keeps all
+)
+
+// EpochValidator is one validator's performance during an epoch
+type EpochValidator struct {
+	Address        string    `json:"address"`
+	Stake          *util.Big `json:"stake"`
+	Active         bool      `json:"active"`
+	BlocksProduced uint64    `json:"blocks_produced"`
+	BlocksMissed   uint64    `json:"blocks_missed"`
+	Rewards        *util.Big `json:"rewards"`
+}
+
+// EpochSummary is the performance report for a finished epoch
+type EpochSummary struct {
+	Epoch          uint64            `json:"epoch"`
+	StartHeight    uint64            `json:"start_height"`
+	EndHeight      uint64            `json:"end_height"`
+	Validators     []*EpochValidator `json:"validators"`
+	TotalStake     *util.Big         `json:"total_stake"`
+	RewardsMinted  *util.Big         `json:"rewards_minted"`
+	Slashes        []SlashingEvent   `json:"slashes"`
+	BlocksProduced uint64            `json:"blocks_produced"`
+	BlocksMissed   uint64            `json:"blocks_missed"`
+}
+
+// validatorCounters are the cumulative per-validator totals an epoch is
+// measured against
+type validatorCounters struct {
+	produced uint64
+	missed   uint64
+	rewards  *big.Int
+}
+
+// EpochTracker records a summary at each epoch boundary
+type EpochTracker struct {
+	mu          sync.RWMutex
+	engine      *Engine
+	keeper      *SlashingKeeper
+	epochLength uint64
+	history     uint64
+	baseline    map[string]validatorCounters // counters at the start of the current epoch
+	summaries   map[uint64]*EpochSummary
+	latest      *EpochSummary
+	log         *os.File
+}
+
+// NewEpochTracker creates a tracker; keeper may be nil and zero
+// epochLength selects the default
+func NewEpochTracker(engine *Engine, keeper *SlashingKeeper, epochLength uint64) *EpochTracker {
+	if epochLength == 0 {
+		epochLength = DefaultEpochLength
+	}
+	return &EpochTracker{
+		engine:      engine,
+		keeper:      keeper,
+		epochLength: epochLength,
+		history:     DefaultEpochHistory,
+		baseline:    make(map[string]validatorCounters),
+		summaries:   make(map[uint64]*EpochSummary),
+	}
+}
+
+// EpochOf returns the epoch containing height
+func (t *EpochTracker) EpochOf(height uint64) uint64 {
+	return height / t.epochLength
+}
+
+// Persist loads summaries saved at path and appends new ones to it
+func (t *EpochTracker) Persist(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var summary EpochSummary
+		if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+			continue
+		}
+		t.store(&summary)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return err
+	}
+
+	t.log = f
+	return nil
+}
+
+// Close closes the persisted summary log
+func (t *EpochTracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.log == nil {
+		return nil
+	}
+	err := t.log.Close()
+	t.log = nil
+	return err
+}
+
+// EndBlock records the epoch summary when height is the epoch's last block
+func (t *EpochTracker) EndBlock(height uint64) *EpochSummary {
+	if (height+1)%t.epochLength != 0 {
+		return nil
+	}
+
+	epoch := t.EpochOf(height)
+	summary := &EpochSummary{
+		Epoch:       epoch,
+		StartHeight: epoch * t.epochLength,
+		EndHeight:   height,
+		Validators:  make([]*EpochValidator, 0),
+		TotalStake:  (*util.Big)(t.engine.GetTotalStake()),
+		Slashes:     make([]SlashingEvent, 0),
+	}
+	if t.keeper != nil {
+		summary.Slashes = t.keeper.SlashesBetween(summary.StartHeight, height)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	minted := new(big.Int)
+	summary.RewardsMinted = (*util.Big)(minted)
+	current := make(map[string]validatorCounters)
+	for _, v := range t.engine.AllValidators() {
+		counters := validatorCounters{v.BlocksProduced, v.BlocksMissed, v.RewardsEarned}
+		current[v.Address] = counters
+		base := t.baseline[v.Address]
+
+		rewards := new(big.Int).Sub(counters.rewards, util.CopyBig(base.rewards))
+		ev := &EpochValidator{
+			Address:        v.Address,
+			Stake:          (*util.Big)(v.TotalStake),
+			Active:         v.Active,
+			BlocksProduced: counters.produced - base.produced,
+			BlocksMissed:   counters.missed - base.missed,
+			Rewards:        (*util.Big)(rewards),
+		}
+		if !ev.Active && ev.BlocksProduced == 0 && ev.BlocksMissed == 0 && rewards.Sign() == 0 {
+			continue
+		}
+		summary.Validators = append(summary.Validators, ev)
+		summary.BlocksProduced += ev.BlocksProduced
+		summary.BlocksMissed += ev.BlocksMissed
+		minted.Add(minted, rewards)
+	}
+	t.baseline = current
+
+	t.store(summary)
+	if t.log != nil {
+		if data, err := json.Marshal(summary); err == nil {
+			t.log.Write(append(data, '\n'))
+		}
+	}
+	return copyEpochSummary(summary)
+}
+
+// store indexes a summary; callers must hold t.mu
+func (t *EpochTracker) store(summary *EpochSummary) {
+	t.summaries[summary.Epoch] = summary
+	if t.latest == nil || summary.Epoch >= t.latest.Epoch {
+		t.latest = summary
+	}
+	if t.latest.Epoch >= t.history {
+		for epoch := range t.summaries {
+			if epoch <= t.latest.Epoch-t.history {
+				delete(t.summaries, epoch)
+			}
+		}
+	}
+}
+
+// Summary returns the recorded summary for an epoch
+func (t *EpochTracker) Summary(epoch uint64) (*EpochSummary, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	summary, exists := t.summaries[epoch]
+	if !exists {
+		return nil, ErrEpochNotFound
+	}
+	return copyEpochSummary(summary), nil
+}
+
+// Latest returns the most recent epoch summary, or nil before the first
+// epoch ends
+func (t *EpochTracker) Latest() *EpochSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.latest == nil {
+		return nil
+	}
+	return copyEpochSummary(t.latest)
+}
+
+func copyEpochSummary(s *EpochSummary) *EpochSummary {
+	c := *s
+	c.Validators = make([]*EpochValidator, len(s.Validators))
+	for i, v := range s.Validators {
+		ev := *v
+		c.Validators[i] = &ev
+	}
+	c.Slashes = append([]SlashingEvent{}, s.Slashes...)
+	return &c
+}
+
+// ErrEpochNotFound is returned for epochs that have not ended or were not recorded
+var ErrEpochNotFound = &ValidatorError{"epoch summary not found"}
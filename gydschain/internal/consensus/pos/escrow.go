@@ -0,0 +1,383 @@
+package pos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// EscrowStatus represents the lifecycle of escrowed slashed funds
+type EscrowStatus string
+
+const (
+	EscrowPending  EscrowStatus = "pending"
+	EscrowReversed EscrowStatus = "reversed"
+	EscrowSettled  EscrowStatus = "settled"
+)
+
+// SlashEscrow holds slashed stake during the appeal window. Funds are only
+// destroyed once the window passes without the slash being reversed.
+type SlashEscrow struct {
+	ID               string              `json:"id"`
+	ValidatorAddress string              `json:"validator_address"`
+	Height           uint64              `json:"height"` // height of the infraction
+	Reason           SlashingReason      `json:"reason"`
+	SelfAmount       *big.Int            `json:"self_amount"`
+	DelegatorAmounts map[string]*big.Int `json:"delegator_amounts"`
+	Total            *big.Int            `json:"total"`
+	SlashedHeight    uint64              `json:"slashed_height"`
+	ReleaseHeight    uint64              `json:"release_height"`    // first height the slash can no longer be reversed
+	Appeals          []string            `json:"appeals,omitempty"` // voters backing a reversal
+	Status           EscrowStatus        `json:"status"`
+}
+
+// MarshalJSON encodes the escrowed amounts as decimal strings
+func (s SlashEscrow) MarshalJSON() ([]byte, error) {
+	type plain SlashEscrow
+	return json.Marshal(struct {
+		*plain
+		SelfAmount       *util.Big   `json:"self_amount"`
+		DelegatorAmounts util.BigMap `json:"delegator_amounts"`
+		Total            *util.Big   `json:"total"`
+	}{(*plain)(&s), (*util.Big)(s.SelfAmount), util.BigMap(s.DelegatorAmounts), (*util.Big)(s.Total)})
+}
+
+// UnmarshalJSON decodes the escrowed amounts from strings or numbers
+func (s *SlashEscrow) UnmarshalJSON(data []byte) error {
+	type plain SlashEscrow
+	dec := struct {
+		*plain
+		SelfAmount       *util.Big   `json:"self_amount"`
+		DelegatorAmounts util.BigMap `json:"delegator_amounts"`
+		Total            *util.Big   `json:"total"`
+	}{plain: (*plain)(s)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	s.SelfAmount, s.Total = dec.SelfAmount.Int(), dec.Total.Int()
+	s.DelegatorAmounts = dec.DelegatorAmounts
+	if s.DelegatorAmounts == nil {
+		s.DelegatorAmounts = make(map[string]*big.Int)
+	}
+	return nil
+}
+
+// Copy creates a deep copy of the escrow
+func (s *SlashEscrow) Copy() *SlashEscrow {
+	copy := *s
+	copy.DelegatorAmounts = make(map[string]*big.Int, len(s.DelegatorAmounts))
+	for k, v := range s.DelegatorAmounts {
+		copy.DelegatorAmounts[k] = v
+	}
+	copy.Appeals = append([]string(nil), s.Appeals...)
+	return &copy
+}
+
+// slashValidator slashes the live validator and records what was taken from
+// the validator and each delegator so the slash can be reversed
+func (e *Engine) slashValidator(address string, percentage uint64, reason SlashingReason, height uint64) (*SlashEscrow, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	v, exists := e.validators[address]
+	if !exists {
+		return nil, ErrValidatorNotFound
+	}
+
+	before := v.Copy()
+	total := v.Slash(percentage, string(reason), height)
+	after := v.Copy()
+
+	escrow := &SlashEscrow{
+		ValidatorAddress: address,
+		Height:           height,
+		Reason:           reason,
+		SelfAmount:       new(big.Int).Sub(before.SelfStake, after.SelfStake),
+		DelegatorAmounts: make(map[string]*big.Int),
+		Total:            total,
+		Status:           EscrowPending,
+	}
+	for delegator, amount := range before.Delegations {
+		if diff := new(big.Int).Sub(amount, util.CopyBig(after.Delegations[delegator])); diff.Sign() > 0 {
+			escrow.DelegatorAmounts[delegator] = diff
+		}
+	}
+
+	e.totalStake = new(big.Int).Sub(e.totalStake, total)
+	e.updateValidatorList()
+
+	return escrow, nil
+}
+
+// jailValidator jails the live validator until the given height and removes
+// it from the active set
+func (e *Engine) jailValidator(address string, until uint64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	v, exists := e.validators[address]
+	if !exists {
+		return ErrValidatorNotFound
+	}
+
+	v.Jail(until)
+	e.updateValidatorList()
+	return nil
+}
+
+// restoreSlash returns escrowed stake to the validator and its delegators
+func (e *Engine) restoreSlash(escrow *SlashEscrow) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	v, exists := e.validators[escrow.ValidatorAddress]
+	if !exists {
+		return ErrValidatorNotFound
+	}
+
+	v.mu.Lock()
+	v.SelfStake = new(big.Int).Add(v.SelfStake, escrow.SelfAmount)
+	for delegator, amount := range escrow.DelegatorAmounts {
+		v.Delegations[delegator] = new(big.Int).Add(util.CopyBig(v.Delegations[delegator]), amount)
+	}
+	v.TotalStake = new(big.Int).Add(v.TotalStake, escrow.Total)
+	v.UpdatedAt = time.Now().Unix()
+	v.mu.Unlock()
+
+	e.totalStake = new(big.Int).Add(e.totalStake, escrow.Total)
+	e.updateValidatorList()
+	return nil
+}
+
+// releaseValidator lifts a jail immediately, regardless of its remaining term
+func (e *Engine) releaseValidator(address string) error {
+	return e.unjailValidator(address, math.MaxUint64)
+}
+
+// unjailValidator lifts a jail that has ended by height and returns the
+// validator to the active set
+func (e *Engine) unjailValidator(address string, height uint64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	v, exists := e.validators[address]
+	if !exists {
+		return ErrValidatorNotFound
+	}
+	if err := v.Unjail(height); err != nil {
+		return err
+	}
+	e.updateValidatorList()
+	return nil
+}
+
+// jailAndEscrow slashes a validator for an infraction into escrow at
+// height, the block applying the slash, where it stays until the appeal
+// window passes, and jails the validator for jailBlocks from there. Callers
+// must hold k.mu.
+func (k *SlashingKeeper) jailAndEscrow(address string, penalty uint64, reason SlashingReason, infraction, height, jailBlocks uint64) error {
+	escrow, err := k.engine.slashValidator(address, penalty/100, reason, infraction)
+	if err != nil {
+		return err
+	}
+	// Number escrows in the order they open, since a validator can be
+	// slashed twice for the same reason at one height
+	k.escrowSeq++
+	escrow.ID = fmt.Sprintf("%s-%d", address, k.escrowSeq)
+	escrow.SlashedHeight = height
+	escrow.ReleaseHeight = height + k.params.AppealWindow
+	k.escrows[escrow.ID] = escrow
+
+	until := height + jailBlocks
+	if err := k.engine.jailValidator(address, until); err != nil {
+		return err
+	}
+	k.getOrCreateSigningInfo(address).JailedUntil = until
+
+	k.slashingEvents = append(k.slashingEvents, SlashingEvent{
+		ValidatorAddress: address,
+		Height:           infraction,
+		Reason:           reason,
+		Amount:           escrow.Total,
+		Timestamp:        k.blockTime,
+		EscrowID:         escrow.ID,
+	})
+	return nil
+}
+
+// ReverseSlash undoes an escrowed slash at height, typically after a
+// governance vote finds it was caused by a bug. The validator is also
+// unjailed and, for double signs, un-tombstoned.
+func (k *SlashingKeeper) ReverseSlash(id string, height uint64) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	escrow, err := k.openEscrow(id, height)
+	if err != nil {
+		return err
+	}
+	return k.reverse(escrow)
+}
+
+// VoteReverse records voter's appeal against an escrowed slash at height
+// and reverses the slash once the appeals meet the breaker's quorum. It
+// returns true if this vote reversed the slash.
+func (k *SlashingKeeper) VoteReverse(breaker *CircuitBreaker, voter, id string, height uint64) (bool, error) {
+	if !breaker.CanVote(voter) {
+		return false, ErrNotHaltVoter
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	escrow, err := k.openEscrow(id, height)
+	if err != nil {
+		return false, err
+	}
+	for _, appeal := range escrow.Appeals {
+		if appeal == voter {
+			return false, nil
+		}
+	}
+	escrow.Appeals = append(escrow.Appeals, voter)
+
+	if !breaker.QuorumMet(escrow.Appeals) {
+		return false, nil
+	}
+	return true, k.reverse(escrow)
+}
+
+// openEscrow returns the escrow if it can still be reversed at height;
+// callers must hold k.mu
+func (k *SlashingKeeper) openEscrow(id string, height uint64) (*SlashEscrow, error) {
+	escrow, exists := k.escrows[id]
+	if !exists {
+		return nil, ErrEscrowNotFound
+	}
+	if escrow.Status != EscrowPending {
+		return nil, ErrEscrowClosed
+	}
+	if height >= escrow.ReleaseHeight {
+		return nil, ErrAppealWindowClosed
+	}
+	return escrow, nil
+}
+
+// reverse restores an escrowed slash; callers must hold k.mu
+func (k *SlashingKeeper) reverse(escrow *SlashEscrow) error {
+	if err := k.engine.restoreSlash(escrow); err != nil {
+		return err
+	}
+	escrow.Status = EscrowReversed
+
+	if info, exists := k.signingInfo[escrow.ValidatorAddress]; exists {
+		if escrow.Reason == SlashReasonDoubleSign {
+			info.Tombstoned = false
+		}
+		info.JailedUntil = 0
+	}
+
+	return k.engine.releaseValidator(escrow.ValidatorAddress)
+}
+
+// SettleExpired finalizes escrows whose appeal window has passed by height
+// and returns them; the settled totals are the amounts to burn
+func (k *SlashingKeeper) SettleExpired(height uint64) []*SlashEscrow {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	settled := make([]*SlashEscrow, 0)
+	for _, escrow := range k.escrows {
+		if escrow.Status == EscrowPending && height >= escrow.ReleaseHeight {
+			escrow.Status = EscrowSettled
+			settled = append(settled, escrow.Copy())
+		}
+	}
+
+	sort.Slice(settled, func(i, j int) bool { return settled[i].Height < settled[j].Height })
+	return settled
+}
+
+// SetAppealWindow sets how long slashes opened from now on stay reversible,
+// rounded up to whole blocks
+func (k *SlashingKeeper) SetAppealWindow(window time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.setAppealWindow(UnbondingBlocks(window, k.engine.BlockTime()))
+}
+
+// setAppealWindow replaces the params rather than modifying them, since
+// GetParams hands them out; callers must hold k.mu
+func (k *SlashingKeeper) setAppealWindow(blocks uint64) {
+	params := *k.params
+	params.AppealWindow = blocks
+	k.params = &params
+}
+
+// VoteAppealWindow records voter's proposed appeal window in blocks and
+// applies it once the voters for that exact window meet the breaker's
+// quorum. Escrows already open keep their release height. It returns true
+// if this vote changed the window.
+func (k *SlashingKeeper) VoteAppealWindow(breaker *CircuitBreaker, voter string, blocks uint64) (bool, error) {
+	if !breaker.CanVote(voter) {
+		return false, ErrNotHaltVoter
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.windowVotes[voter] = blocks
+	voters := make([]string, 0, len(k.windowVotes))
+	for v, proposed := range k.windowVotes {
+		if proposed == blocks {
+			voters = append(voters, v)
+		}
+	}
+	if !breaker.QuorumMet(voters) {
+		return false, nil
+	}
+
+	k.setAppealWindow(blocks)
+	k.windowVotes = make(map[string]uint64)
+	return true, nil
+}
+
+// GetEscrow returns an escrow by ID
+func (k *SlashingKeeper) GetEscrow(id string) (*SlashEscrow, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	escrow, exists := k.escrows[id]
+	if !exists {
+		return nil, ErrEscrowNotFound
+	}
+	return escrow.Copy(), nil
+}
+
+// GetPendingEscrows returns escrows still within their appeal window
+func (k *SlashingKeeper) GetPendingEscrows() []*SlashEscrow {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	pending := make([]*SlashEscrow, 0)
+	for _, escrow := range k.escrows {
+		if escrow.Status == EscrowPending {
+			pending = append(pending, escrow.Copy())
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Height < pending[j].Height })
+	return pending
+}
+
+// Escrow errors
+var (
+	ErrEscrowNotFound     = &SlashingError{"slash escrow not found"}
+	ErrEscrowClosed       = &SlashingError{"slash escrow already reversed or settled"}
+	ErrAppealWindowClosed = &SlashingError{"appeal window has closed"}
+)
@@ -0,0 +1,372 @@
+package pos
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// The ExportState and RestoreState methods below carry a component's state
+// in chain snapshots, so a node restoring one resumes with the stake,
+// escrows and votes the blocks before the snapshot left behind. Settings
+// the node is configured with, like the minimum stake or the epoch length,
+// are not carried.
+
+// ErrInvalidState is returned when exported component state cannot be
+// restored
+var ErrInvalidState = errors.New("invalid consensus component state")
+
+// engineState is the engine's exported state
+type engineState struct {
+	Validators []*Validator `json:"validators"` // by address
+	TotalStake *util.Big    `json:"total_stake"`
+	LeaderSeed []byte       `json:"leader_seed,omitempty"`
+	Rewards    rewardState  `json:"rewards"`
+}
+
+// rewardState is the reward ledger's exported state
+type rewardState struct {
+	Index   util.BigMap            `json:"index"`
+	Start   map[string]util.BigMap `json:"start"`
+	Pending map[string]util.BigMap `json:"pending"`
+}
+
+// ExportState encodes the validator set, stake and reward ledger
+func (e *Engine) ExportState() (json.RawMessage, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	state := engineState{
+		Validators: make([]*Validator, 0, len(e.validators)),
+		TotalStake: (*util.Big)(e.totalStake),
+		LeaderSeed: e.leaderSeed,
+		Rewards: rewardState{
+			Index:   util.BigMap(e.rewards.index),
+			Start:   exportNestedBigMap(e.rewards.start),
+			Pending: exportNestedBigMap(e.rewards.pending),
+		},
+	}
+	for _, v := range e.validators {
+		state.Validators = append(state.Validators, v.Copy())
+	}
+	sort.Slice(state.Validators, func(i, j int) bool {
+		return state.Validators[i].Address < state.Validators[j].Address
+	})
+	return json.Marshal(state)
+}
+
+// RestoreState replaces the validator set, stake and reward ledger with
+// exported ones. Validators the engine already has are restored in place
+// since other components hold pointers to them.
+func (e *Engine) RestoreState(data json.RawMessage) error {
+	var state engineState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	validators := make(map[string]*Validator, len(state.Validators))
+	for _, v := range state.Validators {
+		if v == nil || v.Address == "" {
+			return ErrInvalidState
+		}
+		if live, exists := e.validators[v.Address]; exists {
+			live.restore(v)
+			v = live
+		}
+		validators[v.Address] = v
+	}
+	e.validators = validators
+	e.totalStake = state.TotalStake.Int()
+	e.leaderSeed = state.LeaderSeed
+	e.rewards = &rewardLedger{
+		index:   map[string]*big.Int(state.Rewards.Index),
+		start:   restoreNestedBigMap(state.Rewards.Start),
+		pending: restoreNestedBigMap(state.Rewards.Pending),
+	}
+	if e.rewards.index == nil {
+		e.rewards.index = make(map[string]*big.Int)
+	}
+	e.updateValidatorList()
+	return nil
+}
+
+func exportNestedBigMap(m map[string]map[string]*big.Int) map[string]util.BigMap {
+	out := make(map[string]util.BigMap, len(m))
+	for k, inner := range m {
+		out[k] = util.BigMap(inner)
+	}
+	return out
+}
+
+func restoreNestedBigMap(m map[string]util.BigMap) map[string]map[string]*big.Int {
+	out := make(map[string]map[string]*big.Int, len(m))
+	for k, inner := range m {
+		out[k] = map[string]*big.Int(inner)
+	}
+	return out
+}
+
+// ExportState encodes the queued unbondings
+func (q *UnbondingQueue) ExportState() (json.RawMessage, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return json.Marshal(q.entries)
+}
+
+// RestoreState replaces the queued unbondings with exported ones
+func (q *UnbondingQueue) RestoreState(data json.RawMessage) error {
+	entries := make(map[string][]*UnbondingEntry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for _, queued := range entries {
+		for _, entry := range queued {
+			if entry == nil || entry.Amount == nil {
+				return ErrInvalidState
+			}
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.entries = entries
+	return nil
+}
+
+// breakerState is the circuit breaker's exported state
+type breakerState struct {
+	Halted      bool              `json:"halted"`
+	Reason      string            `json:"reason,omitempty"`
+	HaltedAt    uint64            `json:"halted_at,omitempty"`
+	HaltedSince int64             `json:"halted_since,omitempty"`
+	HaltVotes   map[string]string `json:"halt_votes"`
+	ResumeVotes map[string]bool   `json:"resume_votes"`
+}
+
+// ExportState encodes the halt state and outstanding votes
+func (cb *CircuitBreaker) ExportState() (json.RawMessage, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return json.Marshal(breakerState{
+		Halted:      cb.halted,
+		Reason:      cb.reason,
+		HaltedAt:    cb.haltedAt,
+		HaltedSince: cb.haltedSince,
+		HaltVotes:   cb.haltVotes,
+		ResumeVotes: cb.resumeVotes,
+	})
+}
+
+// RestoreState replaces the halt state and votes with exported ones
+func (cb *CircuitBreaker) RestoreState(data json.RawMessage) error {
+	var state breakerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.HaltVotes == nil {
+		state.HaltVotes = make(map[string]string)
+	}
+	if state.ResumeVotes == nil {
+		state.ResumeVotes = make(map[string]bool)
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.halted, cb.reason, cb.haltedAt, cb.haltedSince = state.Halted, state.Reason, state.HaltedAt, state.HaltedSince
+	cb.haltVotes, cb.resumeVotes = state.HaltVotes, state.ResumeVotes
+	return nil
+}
+
+// beaconState is the randomness beacon's exported state
+type beaconState struct {
+	Commits   map[uint64]map[string]string `json:"commits"`
+	Reveals   map[uint64]map[string][]byte `json:"reveals"`
+	Finalized map[uint64]*BeaconEpoch      `json:"finalized"`
+	Latest    *BeaconEpoch                 `json:"latest,omitempty"`
+}
+
+// ExportState encodes the commitments, reveals and finalized epochs
+func (b *RandomnessBeacon) ExportState() (json.RawMessage, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return json.Marshal(beaconState{b.commits, b.reveals, b.finalized, b.latest})
+}
+
+// RestoreState replaces the commitments, reveals and finalized epochs with
+// exported ones
+func (b *RandomnessBeacon) RestoreState(data json.RawMessage) error {
+	var state beaconState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Commits == nil {
+		state.Commits = make(map[uint64]map[string]string)
+	}
+	if state.Reveals == nil {
+		state.Reveals = make(map[uint64]map[string][]byte)
+	}
+	if state.Finalized == nil {
+		state.Finalized = make(map[uint64]*BeaconEpoch)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.commits, b.reveals, b.finalized, b.latest = state.Commits, state.Reveals, state.Finalized, state.Latest
+	return nil
+}
+
+// oracleState is the price oracle's exported state
+type oracleState struct {
+	Votes  map[uint64]map[string]map[string]uint64 `json:"votes"`
+	Prices map[string]*OraclePrice                 `json:"prices"`
+	Misses map[string]uint64                       `json:"misses"`
+}
+
+// ExportState encodes the price votes, committed prices and missed windows
+func (o *PriceOracle) ExportState() (json.RawMessage, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return json.Marshal(oracleState{o.votes, o.prices, o.misses})
+}
+
+// RestoreState replaces the votes, prices and missed windows with exported
+// ones
+func (o *PriceOracle) RestoreState(data json.RawMessage) error {
+	var state oracleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Votes == nil {
+		state.Votes = make(map[uint64]map[string]map[string]uint64)
+	}
+	if state.Prices == nil {
+		state.Prices = make(map[string]*OraclePrice)
+	}
+	if state.Misses == nil {
+		state.Misses = make(map[string]uint64)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.votes, o.prices, o.misses = state.Votes, state.Prices, state.Misses
+	return nil
+}
+
+// epochState is the epoch tracker's exported state
+type epochState struct {
+	Baseline  map[string]*EpochValidator `json:"baseline"` // counters only
+	Summaries map[uint64]*EpochSummary   `json:"summaries"`
+	Latest    *EpochSummary              `json:"latest,omitempty"`
+}
+
+// ExportState encodes the epoch baseline and summaries
+func (t *EpochTracker) ExportState() (json.RawMessage, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	state := epochState{
+		Baseline:  make(map[string]*EpochValidator, len(t.baseline)),
+		Summaries: t.summaries,
+		Latest:    t.latest,
+	}
+	for v, counters := range t.baseline {
+		state.Baseline[v] = &EpochValidator{
+			Address:        v,
+			BlocksProduced: counters.produced,
+			BlocksMissed:   counters.missed,
+			Rewards:        (*util.Big)(counters.rewards),
+		}
+	}
+	return json.Marshal(state)
+}
+
+// RestoreState replaces the epoch baseline and summaries with exported ones
+func (t *EpochTracker) RestoreState(data json.RawMessage) error {
+	var state epochState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	baseline := make(map[string]validatorCounters, len(state.Baseline))
+	for v, counters := range state.Baseline {
+		if counters == nil {
+			return ErrInvalidState
+		}
+		baseline[v] = validatorCounters{counters.BlocksProduced, counters.BlocksMissed, counters.Rewards.Int()}
+	}
+	if state.Summaries == nil {
+		state.Summaries = make(map[uint64]*EpochSummary)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.baseline, t.summaries, t.latest = baseline, state.Summaries, state.Latest
+	return nil
+}
+
+// slashingState is the slashing keeper's exported state
+type slashingState struct {
+	Params      *SlashingParams                  `json:"params"`
+	SigningInfo map[string]*ValidatorSigningInfo `json:"signing_info"`
+	Events      []SlashingEvent                  `json:"events"`
+	Escrows     map[string]*SlashEscrow          `json:"escrows"`
+	EscrowSeq   uint64                           `json:"escrow_seq"`
+	WindowVotes map[string]uint64                `json:"window_votes"`
+}
+
+// ExportState encodes the signing info, slashing events, escrows and the
+// appeal window and its votes
+func (k *SlashingKeeper) ExportState() (json.RawMessage, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return json.Marshal(slashingState{
+		Params:      k.params,
+		SigningInfo: k.signingInfo,
+		Events:      k.slashingEvents,
+		Escrows:     k.escrows,
+		EscrowSeq:   k.escrowSeq,
+		WindowVotes: k.windowVotes,
+	})
+}
+
+// RestoreState replaces the signing info, events, escrows and appeal
+// window with exported ones
+func (k *SlashingKeeper) RestoreState(data json.RawMessage) error {
+	var state slashingState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Params == nil {
+		return ErrInvalidState
+	}
+	for _, info := range state.SigningInfo {
+		if info == nil {
+			return ErrInvalidState
+		}
+	}
+	for _, escrow := range state.Escrows {
+		if escrow == nil {
+			return ErrInvalidState
+		}
+	}
+	if state.SigningInfo == nil {
+		state.SigningInfo = make(map[string]*ValidatorSigningInfo)
+	}
+	if state.Escrows == nil {
+		state.Escrows = make(map[string]*SlashEscrow)
+	}
+	if state.WindowVotes == nil {
+		state.WindowVotes = make(map[string]uint64)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.params, k.signingInfo, k.slashingEvents = state.Params, state.SigningInfo, state.Events
+	k.escrows, k.escrowSeq, k.windowVotes = state.Escrows, state.EscrowSeq, state.WindowVotes
+	return nil
+}
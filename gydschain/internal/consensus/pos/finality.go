@@ -0,0 +1,430 @@
+package pos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Finality errors
+var (
+	ErrInvalidVote       = errors.New("invalid vote")
+	ErrVoteSignature     = errors.New("invalid vote signature")
+	ErrConflictingVote   = errors.New("validator voted for two blocks in the same round")
+	ErrInvalidCommit     = errors.New("invalid commit certificate")
+	ErrInsufficientPower = errors.New("commit certificate lacks a 2/3 stake quorum")
+)
+
+// VoteType is the step of the voting round a vote belongs to
+type VoteType uint8
+
+const (
+	VotePrevote VoteType = iota + 1
+	VotePrecommit
+)
+
+// Vote is a validator's signed prevote or precommit for a block
+type Vote struct {
+	Type      VoteType `json:"type"`
+	Height    uint64   `json:"height"`
+	Round     uint64   `json:"round"`
+	BlockHash string   `json:"block_hash"`
+	Validator string   `json:"validator"`
+	Signature []byte   `json:"signature"`
+}
+
+// SignBytes returns the digest a validator signs; it binds the step, height,
+// round and block so a vote cannot be replayed elsewhere
+func (v *Vote) SignBytes() []byte {
+	var buf [17]byte
+	buf[0] = byte(v.Type)
+	binary.BigEndian.PutUint64(buf[1:9], v.Height)
+	binary.BigEndian.PutUint64(buf[9:17], v.Round)
+
+	h := sha256.New()
+	h.Write([]byte("gyds-vote"))
+	h.Write(buf[:])
+	h.Write([]byte(v.BlockHash))
+	return h.Sum(nil)
+}
+
+// Sign signs the vote with the validator's key
+func (v *Vote) Sign(key *crypto.KeyPair) error {
+	signature, err := key.Sign(v.SignBytes())
+	if err != nil {
+		return err
+	}
+	v.Signature = signature
+	return nil
+}
+
+// CommitCertificate proves a block final: precommits for it in one round
+// from validators holding more than 2/3 of the active stake
+type CommitCertificate struct {
+	Height     uint64    `json:"height"`
+	Round      uint64    `json:"round"`
+	BlockHash  string    `json:"block_hash"`
+	Precommits []*Vote   `json:"precommits"`
+	Power      *util.Big `json:"power"`
+	TotalPower *util.Big `json:"total_power"`
+}
+
+// VerifyVote checks that vote is well formed and signed by an active validator
+func (e *Engine) VerifyVote(vote *Vote) error {
+	if vote == nil || vote.BlockHash == "" || (vote.Type != VotePrevote && vote.Type != VotePrecommit) {
+		return ErrInvalidVote
+	}
+
+	validator, err := e.GetValidator(vote.Validator)
+	if err != nil || !validator.Active {
+		return ErrNotValidator
+	}
+
+	pubKey, err := crypto.ParsePublicKey(validator.PubKey)
+	if err != nil || !crypto.VerifySignature(pubKey, vote.SignBytes(), vote.Signature) {
+		return ErrVoteSignature
+	}
+	return nil
+}
+
+// VerifyCommit checks every precommit in cert and that together they hold
+// more than 2/3 of the active stake
+func (e *Engine) VerifyCommit(cert *CommitCertificate) error {
+	if cert == nil || len(cert.Precommits) == 0 {
+		return ErrInvalidCommit
+	}
+
+	power := new(big.Int)
+	seen := make(map[string]bool, len(cert.Precommits))
+	for _, vote := range cert.Precommits {
+		if vote.Type != VotePrecommit || vote.Height != cert.Height ||
+			vote.Round != cert.Round || vote.BlockHash != cert.BlockHash || seen[vote.Validator] {
+			return ErrInvalidCommit
+		}
+		if err := e.VerifyVote(vote); err != nil {
+			return err
+		}
+		seen[vote.Validator] = true
+		if stake := e.ValidatorStake(vote.Validator); stake != nil {
+			power.Add(power, stake)
+		}
+	}
+
+	if !hasQuorum(power, e.GetTotalStake()) {
+		return ErrInsufficientPower
+	}
+	return nil
+}
+
+// ValidatorKey is an active validator's public key and stake: enough for a
+// client that does not track staking to check a commit certificate
+type ValidatorKey struct {
+	Address string    `json:"address"`
+	PubKey  string    `json:"pub_key"`
+	Stake   *util.Big `json:"stake"`
+}
+
+// ValidatorKeys returns the keys and stakes of the active validators
+func (e *Engine) ValidatorKeys() []*ValidatorKey {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	keys := make([]*ValidatorKey, len(e.validatorList))
+	for i, v := range e.validatorList {
+		keys[i] = &ValidatorKey{Address: v.Address, PubKey: v.PubKey, Stake: (*util.Big)(util.CopyBig(v.TotalStake))}
+	}
+	return keys
+}
+
+// VerifyCommitWith checks cert against a given validator set instead of
+// the engine's: every precommit must be signed by a listed validator and
+// together they must hold more than 2/3 of the listed stake
+func VerifyCommitWith(cert *CommitCertificate, validators []*ValidatorKey) error {
+	if cert == nil || len(cert.Precommits) == 0 {
+		return ErrInvalidCommit
+	}
+	byAddress := make(map[string]*ValidatorKey, len(validators))
+	total := new(big.Int)
+	for _, v := range validators {
+		if v.Stake == nil || byAddress[v.Address] != nil {
+			return ErrInvalidCommit
+		}
+		byAddress[v.Address] = v
+		total.Add(total, v.Stake.Int())
+	}
+
+	power := new(big.Int)
+	seen := make(map[string]bool, len(cert.Precommits))
+	for _, vote := range cert.Precommits {
+		if vote.Type != VotePrecommit || vote.Height != cert.Height ||
+			vote.Round != cert.Round || vote.BlockHash != cert.BlockHash || seen[vote.Validator] {
+			return ErrInvalidCommit
+		}
+		v, ok := byAddress[vote.Validator]
+		if !ok {
+			return ErrNotValidator
+		}
+		pubKey, err := crypto.ParsePublicKey(v.PubKey)
+		if err != nil || !crypto.VerifySignature(pubKey, vote.SignBytes(), vote.Signature) {
+			return ErrVoteSignature
+		}
+		seen[vote.Validator] = true
+		power.Add(power, v.Stake.Int())
+	}
+
+	if !hasQuorum(power, total) {
+		return ErrInsufficientPower
+	}
+	return nil
+}
+
+// hasQuorum reports whether power is more than 2/3 of total
+func hasQuorum(power, total *big.Int) bool {
+	if total.Sign() == 0 {
+		return false
+	}
+	lhs := new(big.Int).Mul(power, big.NewInt(3))
+	rhs := new(big.Int).Mul(total, big.NewInt(2))
+	return lhs.Cmp(rhs) > 0
+}
+
+// voteKey identifies the votes of one step in one round of a height
+type voteKey struct {
+	height uint64
+	round  uint64
+	step   VoteType
+}
+
+// Finality runs the prevote/precommit round that makes blocks final. Each
+// validator prevotes for the block it added at a height; once prevotes for
+// one block reach a 2/3 stake quorum it precommits, and a 2/3 quorum of
+// precommits forms the block's commit certificate. A validator never
+// precommits two blocks at one height, so two conflicting certificates need
+// more than 1/3 of the stake to equivocate.
+type Finality struct {
+	mu           sync.Mutex
+	engine       *Engine
+	signer       *crypto.KeyPair
+	address      string
+	votes        map[voteKey]map[string]*Vote // step -> validator -> vote
+	precommitted map[uint64]string            // height -> block the local validator precommitted
+	finalized    *CommitCertificate
+	broadcast    func(*Vote)
+	onCommit     func(*CommitCertificate)
+}
+
+// NewFinality creates a finality tracker for the engine's validator set
+func NewFinality(engine *Engine) *Finality {
+	return &Finality{
+		engine:       engine,
+		votes:        make(map[voteKey]map[string]*Vote),
+		precommitted: make(map[uint64]string),
+	}
+}
+
+// SetSigner makes the node vote as the validator at address
+func (f *Finality) SetSigner(address string, key *crypto.KeyPair) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.address = address
+	f.signer = key
+}
+
+// OnVote sets the callback that sends the local validator's votes to peers
+func (f *Finality) OnVote(fn func(*Vote)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broadcast = fn
+}
+
+// OnCommit sets the callback that receives each new commit certificate
+func (f *Finality) OnCommit(fn func(*CommitCertificate)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onCommit = fn
+}
+
+// Prevote casts the local validator's prevote for the block it added at
+// height; nodes without a signer only count the votes of others
+func (f *Finality) Prevote(height, round uint64, blockHash string) error {
+	f.mu.Lock()
+	if f.signer == nil || f.finalizedHeight() >= height {
+		f.mu.Unlock()
+		return nil
+	}
+	vote := &Vote{Type: VotePrevote, Height: height, Round: round, BlockHash: blockHash, Validator: f.address}
+	f.mu.Unlock()
+
+	if err := vote.Sign(f.signer); err != nil {
+		return err
+	}
+	_, err := f.AddVote(vote)
+	return err
+}
+
+// AddVote records a verified vote from the local validator or a peer and
+// reports whether it was new, so callers relay each vote once. A prevote
+// quorum triggers the local precommit and a precommit quorum finalizes the
+// block.
+func (f *Finality) AddVote(vote *Vote) (bool, error) {
+	if err := f.engine.VerifyVote(vote); err != nil {
+		return false, err
+	}
+
+	f.mu.Lock()
+	if vote.Height <= f.finalizedHeight() {
+		f.mu.Unlock()
+		return false, nil
+	}
+
+	key := voteKey{vote.Height, vote.Round, vote.Type}
+	votes := f.votes[key]
+	if votes == nil {
+		votes = make(map[string]*Vote)
+		f.votes[key] = votes
+	}
+	if existing, ok := votes[vote.Validator]; ok {
+		f.mu.Unlock()
+		if existing.BlockHash != vote.BlockHash {
+			return false, ErrConflictingVote
+		}
+		return false, nil
+	}
+	votes[vote.Validator] = vote
+
+	var (
+		precommit *Vote
+		cert      *CommitCertificate
+		local     = vote.Validator == f.address
+		broadcast = f.broadcast
+		onCommit  = f.onCommit
+	)
+	power, total := f.power(votes, vote.BlockHash)
+	if hasQuorum(power, total) {
+		switch vote.Type {
+		case VotePrevote:
+			precommit = f.precommit(vote)
+		case VotePrecommit:
+			cert = f.commit(vote, votes, power, total)
+		}
+	}
+	f.mu.Unlock()
+
+	if local && broadcast != nil {
+		broadcast(vote)
+	}
+	if cert != nil && onCommit != nil {
+		onCommit(cert)
+	}
+	if precommit != nil {
+		if err := precommit.Sign(f.signer); err != nil {
+			return true, err
+		}
+		if _, err := f.AddVote(precommit); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// precommit returns the local validator's precommit for the block that
+// reached a prevote quorum, or nil if it has already precommitted at this
+// height; callers must hold f.mu
+func (f *Finality) precommit(prevote *Vote) *Vote {
+	if f.signer == nil {
+		return nil
+	}
+	if _, done := f.precommitted[prevote.Height]; done {
+		return nil
+	}
+	f.precommitted[prevote.Height] = prevote.BlockHash
+	return &Vote{
+		Type:      VotePrecommit,
+		Height:    prevote.Height,
+		Round:     prevote.Round,
+		BlockHash: prevote.BlockHash,
+		Validator: f.address,
+	}
+}
+
+// commit builds the certificate for a precommit quorum, records it as the
+// finalized head and drops votes at or below its height; callers must hold
+// f.mu
+func (f *Finality) commit(vote *Vote, votes map[string]*Vote, power, total *big.Int) *CommitCertificate {
+	cert := &CommitCertificate{
+		Height:     vote.Height,
+		Round:      vote.Round,
+		BlockHash:  vote.BlockHash,
+		Precommits: make([]*Vote, 0, len(votes)),
+		Power:      (*util.Big)(power),
+		TotalPower: (*util.Big)(total),
+	}
+	for _, v := range votes {
+		if v.BlockHash == vote.BlockHash {
+			cert.Precommits = append(cert.Precommits, v)
+		}
+	}
+	sort.Slice(cert.Precommits, func(i, j int) bool {
+		return cert.Precommits[i].Validator < cert.Precommits[j].Validator
+	})
+
+	f.finalized = cert
+	for key := range f.votes {
+		if key.height <= cert.Height {
+			delete(f.votes, key)
+		}
+	}
+	for height := range f.precommitted {
+		if height <= cert.Height {
+			delete(f.precommitted, height)
+		}
+	}
+	return cert
+}
+
+// power sums the stake voting for blockHash; callers must hold f.mu
+func (f *Finality) power(votes map[string]*Vote, blockHash string) (*big.Int, *big.Int) {
+	power := new(big.Int)
+	for addr, v := range votes {
+		if v.BlockHash != blockHash {
+			continue
+		}
+		if stake := f.engine.ValidatorStake(addr); stake != nil {
+			power.Add(power, stake)
+		}
+	}
+	return power, f.engine.GetTotalStake()
+}
+
+// finalizedHeight returns the height of the latest certificate; callers must
+// hold f.mu
+func (f *Finality) finalizedHeight() uint64 {
+	if f.finalized == nil {
+		return 0
+	}
+	return f.finalized.Height
+}
+
+// Verify checks a certificate against the current validator set
+func (f *Finality) Verify(cert *CommitCertificate) error {
+	return f.engine.VerifyCommit(cert)
+}
+
+// ValidatorKeys returns the keys and stakes of the validators the tracker
+// verifies votes against
+func (f *Finality) ValidatorKeys() []*ValidatorKey {
+	return f.engine.ValidatorKeys()
+}
+
+// Finalized returns the latest commit certificate, or nil before the first
+func (f *Finality) Finalized() *CommitCertificate {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.finalized
+}
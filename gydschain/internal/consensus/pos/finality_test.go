@@ -0,0 +1,92 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+func TestFinalityQuorum(t *testing.T) {
+	engine := newTestEngine(t)
+	keys := make([]*crypto.KeyPair, 4)
+	nodes := make([]*Finality, 4)
+	certs := make(chan *CommitCertificate, 16)
+	for i := range keys {
+		key, err := crypto.NewKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+		if err := engine.RegisterValidator(key.Address(), key.PublicKeyHex(), big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every validator delivers its votes to the others directly
+	for i := range nodes {
+		nodes[i] = NewFinality(engine)
+		nodes[i].SetSigner(keys[i].Address(), keys[i])
+		nodes[i].OnCommit(func(cert *CommitCertificate) { certs <- cert })
+	}
+	for i := range nodes {
+		from := i
+		nodes[i].OnVote(func(vote *Vote) {
+			for j, peer := range nodes {
+				if j != from {
+					peer.AddVote(vote)
+				}
+			}
+		})
+	}
+
+	// Two of four validators are not a 2/3 quorum
+	for _, node := range nodes[:2] {
+		if err := node.Prevote(1, 0, "blockA"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(certs) != 0 {
+		t.Fatal("block finalized without a 2/3 quorum")
+	}
+
+	if err := nodes[2].Prevote(1, 0, "blockA"); err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) == 0 {
+		t.Fatal("expected a commit certificate once 3 of 4 prevoted")
+	}
+	cert := <-certs
+	if cert.Height != 1 || cert.BlockHash != "blockA" {
+		t.Fatalf("unexpected certificate for %d/%s", cert.Height, cert.BlockHash)
+	}
+	if err := engine.VerifyCommit(cert); err != nil {
+		t.Fatalf("certificate does not verify: %v", err)
+	}
+
+	// A certificate stripped below the quorum is rejected
+	weak := *cert
+	weak.Precommits = cert.Precommits[:2]
+	if err := engine.VerifyCommit(&weak); err != ErrInsufficientPower {
+		t.Fatalf("expected ErrInsufficientPower, got %v", err)
+	}
+
+	// A validator cannot vote for two blocks in one round
+	vote := &Vote{Type: VotePrevote, Height: 2, BlockHash: "blockB", Validator: keys[3].Address()}
+	vote.Sign(keys[3])
+	if _, err := nodes[0].AddVote(vote); err != nil {
+		t.Fatal(err)
+	}
+	conflict := &Vote{Type: VotePrevote, Height: 2, BlockHash: "blockC", Validator: keys[3].Address()}
+	conflict.Sign(keys[3])
+	if _, err := nodes[0].AddVote(conflict); err != ErrConflictingVote {
+		t.Fatalf("expected ErrConflictingVote, got %v", err)
+	}
+
+	// Votes signed by someone else are rejected
+	forged := &Vote{Type: VotePrecommit, Height: 2, BlockHash: "blockB", Validator: keys[3].Address()}
+	forged.Sign(keys[0])
+	if _, err := nodes[1].AddVote(forged); err != ErrVoteSignature {
+		t.Fatalf("expected ErrVoteSignature, got %v", err)
+	}
+}
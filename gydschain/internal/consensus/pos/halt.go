@@ -0,0 +1,212 @@
+package pos
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// HaltStatus describes the current state of the circuit breaker
+type HaltStatus struct {
+	Halted       bool      `json:"halted"`
+	Reason       string    `json:"reason,omitempty"`
+	HaltedAt     uint64    `json:"halted_at,omitempty"` // block height
+	HaltedSince  int64     `json:"halted_since,omitempty"`
+	HaltVotes    []string  `json:"halt_votes"`
+	ResumeVotes  []string  `json:"resume_votes"`
+	HaltPower    *util.Big `json:"halt_power"`
+	ResumePower  *util.Big `json:"resume_power"`
+	TotalPower   *util.Big `json:"total_power"`
+	GuardianMode bool      `json:"guardian_mode"`
+}
+
+// CircuitBreaker halts block production for non-system transactions when
+// a supermajority (>2/3 of active stake) of validators votes for it. During
+// bootstrap a guardian multisig can trip or reset it instead.
+type CircuitBreaker struct {
+	mu                sync.RWMutex
+	engine            *Engine
+	guardians         map[string]bool
+	guardianThreshold int
+	halted            bool
+	reason            string
+	haltedAt          uint64
+	haltedSince       int64
+	haltVotes         map[string]string // voter -> reason
+	resumeVotes       map[string]bool
+}
+
+// NewCircuitBreaker creates a circuit breaker. Guardians may be empty once
+// the validator set is decentralized enough to rely on stake alone.
+func NewCircuitBreaker(engine *Engine, guardians []string, guardianThreshold int) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		engine:            engine,
+		guardians:         make(map[string]bool),
+		guardianThreshold: guardianThreshold,
+		haltVotes:         make(map[string]string),
+		resumeVotes:       make(map[string]bool),
+	}
+	for _, g := range guardians {
+		cb.guardians[g] = true
+	}
+	if cb.guardianThreshold <= 0 || cb.guardianThreshold > len(cb.guardians) {
+		cb.guardianThreshold = len(cb.guardians)/2 + 1
+	}
+	return cb
+}
+
+// VoteHalt records a halt vote and trips the breaker once the threshold is met.
+// It returns true if this vote caused the chain to halt.
+func (cb *CircuitBreaker) VoteHalt(voter, reason string, height uint64) (bool, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.canVote(voter) {
+		return false, ErrNotHaltVoter
+	}
+	if cb.halted {
+		return false, nil
+	}
+
+	cb.haltVotes[voter] = reason
+	if !cb.thresholdMet(cb.haltVoters()) {
+		return false, nil
+	}
+
+	cb.halted = true
+	cb.reason = reason
+	cb.haltedAt = height
+	cb.haltedSince = time.Now().Unix()
+	cb.haltVotes = make(map[string]string)
+	cb.resumeVotes = make(map[string]bool)
+	return true, nil
+}
+
+// VoteResume records a resume vote and resets the breaker once the threshold
+// is met. It returns true if this vote resumed the chain.
+func (cb *CircuitBreaker) VoteResume(voter string) (bool, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.canVote(voter) {
+		return false, ErrNotHaltVoter
+	}
+	if !cb.halted {
+		return false, ErrNotHalted
+	}
+
+	cb.resumeVotes[voter] = true
+	voters := make([]string, 0, len(cb.resumeVotes))
+	for v := range cb.resumeVotes {
+		voters = append(voters, v)
+	}
+	if !cb.thresholdMet(voters) {
+		return false, nil
+	}
+
+	cb.halted = false
+	cb.reason = ""
+	cb.haltedAt = 0
+	cb.haltedSince = 0
+	cb.resumeVotes = make(map[string]bool)
+	return true, nil
+}
+
+// IsHalted returns true if the breaker is tripped
+func (cb *CircuitBreaker) IsHalted() bool {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.halted
+}
+
+// Status returns a snapshot of the breaker state
+func (cb *CircuitBreaker) Status() *HaltStatus {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	status := &HaltStatus{
+		Halted:       cb.halted,
+		Reason:       cb.reason,
+		HaltedAt:     cb.haltedAt,
+		HaltedSince:  cb.haltedSince,
+		HaltVotes:    cb.haltVoters(),
+		ResumeVotes:  make([]string, 0, len(cb.resumeVotes)),
+		TotalPower:   (*util.Big)(cb.engine.GetTotalStake()),
+		GuardianMode: len(cb.guardians) > 0,
+	}
+	for v := range cb.resumeVotes {
+		status.ResumeVotes = append(status.ResumeVotes, v)
+	}
+	status.HaltPower = (*util.Big)(cb.votingPower(status.HaltVotes))
+	status.ResumePower = (*util.Big)(cb.votingPower(status.ResumeVotes))
+
+	return status
+}
+
+// CanVote returns true if voter may vote on network-wide changes: guardians
+// and active validators
+func (cb *CircuitBreaker) CanVote(voter string) bool {
+	return cb.canVote(voter)
+}
+
+// QuorumMet returns true if voters meet the guardian quorum or hold more
+// than 2/3 of active stake, the threshold for network-wide changes
+func (cb *CircuitBreaker) QuorumMet(voters []string) bool {
+	return cb.thresholdMet(voters)
+}
+
+// canVote returns true for guardians and active validators
+func (cb *CircuitBreaker) canVote(voter string) bool {
+	if cb.guardians[voter] {
+		return true
+	}
+	v, err := cb.engine.GetValidator(voter)
+	return err == nil && v.Active
+}
+
+// thresholdMet checks the guardian quorum or the 2/3 stake supermajority
+func (cb *CircuitBreaker) thresholdMet(voters []string) bool {
+	guardianVotes := 0
+	for _, v := range voters {
+		if cb.guardians[v] {
+			guardianVotes++
+		}
+	}
+	if len(cb.guardians) > 0 && guardianVotes >= cb.guardianThreshold {
+		return true
+	}
+
+	total := cb.engine.GetTotalStake()
+	if total.Sign() == 0 {
+		return false
+	}
+	power := cb.votingPower(voters)
+	return power.Mul(power, big.NewInt(3)).Cmp(total.Mul(total, big.NewInt(2))) > 0
+}
+
+// votingPower sums the stake of the validators among voters
+func (cb *CircuitBreaker) votingPower(voters []string) *big.Int {
+	power := new(big.Int)
+	for _, addr := range voters {
+		if v, err := cb.engine.GetValidator(addr); err == nil && v.Active {
+			power.Add(power, v.TotalStake)
+		}
+	}
+	return power
+}
+
+func (cb *CircuitBreaker) haltVoters() []string {
+	voters := make([]string, 0, len(cb.haltVotes))
+	for v := range cb.haltVotes {
+		voters = append(voters, v)
+	}
+	return voters
+}
+
+// Circuit breaker errors
+var (
+	ErrNotHaltVoter = &ValidatorError{"not an active validator or guardian"}
+	ErrNotHalted    = &ValidatorError{"chain is not halted"}
+)
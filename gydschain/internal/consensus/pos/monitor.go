@@ -0,0 +1,115 @@
+package pos
+
+import (
+	"sort"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Proposal search bounds for monitoring reports. The search runs under the
+// engine lock for every validator, so callers cannot ask for more.
+const (
+	DefaultProposalLookahead = 1000
+	MaxProposalLookahead     = 10000
+)
+
+// ValidatorReport is the per-validator view served to external monitoring
+// and alerting tools. Field names are part of a stable format; add fields
+// rather than renaming them.
+type ValidatorReport struct {
+	Address               string    `json:"address"`
+	Name                  string    `json:"name,omitempty"`
+	VotingPower           *util.Big `json:"voting_power"`
+	Active                bool      `json:"active"`
+	Jailed                bool      `json:"jailed"`
+	JailedUntil           uint64    `json:"jailed_until,omitempty"` // height
+	Tombstoned            bool      `json:"tombstoned"`
+	MissedBlocks          uint64    `json:"missed_blocks"` // within the signing window
+	MissedStreak          uint64    `json:"missed_streak"`
+	SignedBlocksWindow    uint64    `json:"signed_blocks_window"`
+	LastSeenHeight        uint64    `json:"last_seen_height"`
+	BlocksProduced        uint64    `json:"blocks_produced"`
+	Uptime                float64   `json:"uptime"`
+	NextProposalRound     uint64    `json:"next_proposal_round,omitempty"`
+	NextProposalScheduled bool      `json:"next_proposal_scheduled"`
+}
+
+// AllValidators returns copies of every registered validator, including
+// jailed and unbonding ones, sorted by stake
+func (e *Engine) AllValidators() []*Validator {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	validators := make([]*Validator, 0, len(e.validators))
+	for _, v := range e.validators {
+		validators = append(validators, v.Copy())
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		if cmp := validators[i].TotalStake.Cmp(validators[j].TotalStake); cmp != 0 {
+			return cmp > 0
+		}
+		return validators[i].Address < validators[j].Address
+	})
+	return validators
+}
+
+// NextProposal returns the first round after the current one in which the
+// validator would be selected as leader, searching up to lookahead rounds.
+// It mirrors SelectLeader without changing engine state.
+func (e *Engine) NextProposal(address string, lookahead uint64) (uint64, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if len(e.validatorList) == 0 || e.totalStake.Sign() == 0 {
+		return 0, false
+	}
+
+	for round := e.currentRound + 1; round <= e.currentRound+lookahead; round++ {
+		if e.leaderAt(round) == address {
+			return round, true
+		}
+	}
+	return 0, false
+}
+
+// MonitoringReport builds a report for every registered validator
+func (k *SlashingKeeper) MonitoringReport(lookahead uint64) []ValidatorReport {
+	if lookahead == 0 {
+		lookahead = DefaultProposalLookahead
+	}
+	if lookahead > MaxProposalLookahead {
+		lookahead = MaxProposalLookahead
+	}
+
+	validators := k.engine.AllValidators()
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	reports := make([]ValidatorReport, 0, len(validators))
+	for _, v := range validators {
+		report := ValidatorReport{
+			Address:            v.Address,
+			Name:               v.Name,
+			VotingPower:        (*util.Big)(v.TotalStake),
+			Active:             v.Active,
+			Jailed:             v.Status == StatusJailed,
+			JailedUntil:        v.JailedUntil,
+			SignedBlocksWindow: k.params.SignedBlocksWindow,
+			BlocksProduced:     v.BlocksProduced,
+			Uptime:             v.Uptime,
+		}
+		if info, exists := k.signingInfo[v.Address]; exists {
+			report.Tombstoned = info.Tombstoned
+			report.MissedBlocks = info.MissedBlocksCounter
+			report.MissedStreak = info.MissedStreak
+			report.LastSeenHeight = info.LastHeight
+		}
+		if v.Active {
+			report.NextProposalRound, report.NextProposalScheduled = k.engine.NextProposal(v.Address, lookahead)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports
+}
@@ -0,0 +1,273 @@
+package pos
+
+import (
+	"sort"
+	"sync"
+)
+
+// Price oracle defaults
+const (
+	DefaultOracleWindow    = 60 // blocks per voting window
+	DefaultOracleMaxMisses = 3  // consecutive missed windows before a slash
+)
+
+// OraclePrice is the median of the validators' votes for an asset,
+// committed when a voting window closes
+type OraclePrice struct {
+	Asset  string   `json:"asset"`
+	Price  uint64   `json:"price"` // fixed point, tx.OraclePriceDecimals
+	Window uint64   `json:"window"`
+	Height uint64   `json:"height"` // block that closed the window
+	Voters []string `json:"voters"`
+}
+
+// OracleStatus describes the window currently collecting votes
+type OracleStatus struct {
+	Window       uint64            `json:"window"`
+	WindowLength uint64            `json:"window_length"`
+	WindowEnds   uint64            `json:"window_ends"` // last height of the window
+	Votes        map[string]int    `json:"votes"`       // asset -> votes so far
+	Prices       []*OraclePrice    `json:"prices"`      // latest committed, by asset
+	Misses       map[string]uint64 `json:"misses"`      // consecutive windows missed
+}
+
+// PriceOracle commits asset prices voted by the active validators. Each
+// window every validator votes once per asset; when the window closes the
+// median vote is committed, so a minority of validators cannot move the
+// price. Validators that miss MaxMisses windows in a row, without voting
+// on every asset, are slashed and jailed like downtime.
+type PriceOracle struct {
+	mu        sync.RWMutex
+	engine    *Engine
+	slashing  *SlashingKeeper
+	window    uint64
+	maxMisses uint64
+	assets    []string
+	votes     map[uint64]map[string]map[string]uint64 // window -> asset -> validator -> price
+	prices    map[string]*OraclePrice
+	misses    map[string]uint64
+}
+
+// NewPriceOracle creates an oracle for assets; zero window selects the
+// default. Without a slashing keeper missed windows are only counted.
+func NewPriceOracle(engine *Engine, slashing *SlashingKeeper, window uint64, assets []string) *PriceOracle {
+	if window == 0 {
+		window = DefaultOracleWindow
+	}
+	return &PriceOracle{
+		engine:    engine,
+		slashing:  slashing,
+		window:    window,
+		maxMisses: DefaultOracleMaxMisses,
+		assets:    append([]string(nil), assets...),
+		votes:     make(map[uint64]map[string]map[string]uint64),
+		prices:    make(map[string]*OraclePrice),
+		misses:    make(map[string]uint64),
+	}
+}
+
+// OracleWindow converts the genesis oracle update interval, in seconds,
+// into a voting window in blocks
+func OracleWindow(updateFreq, blockTime uint64) uint64 {
+	if blockTime == 0 {
+		blockTime = 1
+	}
+	return (updateFreq + blockTime - 1) / blockTime
+}
+
+// WindowLength returns the blocks per voting window
+func (o *PriceOracle) WindowLength() uint64 {
+	return o.window
+}
+
+// WindowOf returns the voting window containing height
+func (o *PriceOracle) WindowOf(height uint64) uint64 {
+	return height / o.window
+}
+
+// Vote records a validator's price for asset in the window at height
+func (o *PriceOracle) Vote(validator, asset string, window, price, height uint64) error {
+	// Check membership before locking so the oracle never holds its lock
+	// while waiting on the engine
+	if !o.isActive(validator) {
+		return ErrNotOracleVoter
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.tracks(asset) {
+		return ErrOracleUnknownAsset
+	}
+	if window != o.WindowOf(height) {
+		return ErrOracleWrongWindow
+	}
+	if _, exists := o.votes[window][asset][validator]; exists {
+		return ErrOracleDuplicate
+	}
+
+	if o.votes[window] == nil {
+		o.votes[window] = make(map[string]map[string]uint64)
+	}
+	if o.votes[window][asset] == nil {
+		o.votes[window][asset] = make(map[string]uint64)
+	}
+	o.votes[window][asset][validator] = price
+	return nil
+}
+
+// EndBlock commits the median prices when height is the last block of a
+// window and penalizes validators that keep missing votes. It returns the
+// prices committed, or nil mid-window.
+func (o *PriceOracle) EndBlock(height uint64) []*OraclePrice {
+	if (height+1)%o.window != 0 {
+		return nil
+	}
+	validators := o.engine.GetValidators()
+
+	o.mu.Lock()
+	committed, offenders := o.commit(o.WindowOf(height), height, validators)
+	o.mu.Unlock()
+
+	if o.slashing != nil {
+		for _, validator := range offenders {
+			o.slashing.HandleOracleMiss(validator, height)
+		}
+	}
+	return committed
+}
+
+// commit closes window, returning the new prices and the validators that
+// reached the miss limit; callers must hold o.mu
+func (o *PriceOracle) commit(window, height uint64, validators []*Validator) ([]*OraclePrice, []string) {
+	votes := o.votes[window]
+	delete(o.votes, window)
+
+	var committed []*OraclePrice
+	for _, asset := range o.assets {
+		if len(votes[asset]) == 0 {
+			continue
+		}
+		price := &OraclePrice{
+			Asset:  asset,
+			Window: window,
+			Height: height,
+			Voters: make([]string, 0, len(votes[asset])),
+		}
+		prices := make([]uint64, 0, len(votes[asset]))
+		for validator, p := range votes[asset] {
+			price.Voters = append(price.Voters, validator)
+			prices = append(prices, p)
+		}
+		sort.Strings(price.Voters)
+		price.Price = medianPrice(prices)
+
+		o.prices[asset] = price
+		committed = append(committed, copyOraclePrice(price))
+	}
+
+	var offenders []string
+	for _, v := range validators {
+		if !v.Active {
+			continue
+		}
+		voted := true
+		for _, asset := range o.assets {
+			if _, ok := votes[asset][v.Address]; !ok {
+				voted = false
+				break
+			}
+		}
+		if voted {
+			delete(o.misses, v.Address)
+			continue
+		}
+		o.misses[v.Address]++
+		if o.misses[v.Address] >= o.maxMisses {
+			offenders = append(offenders, v.Address)
+			delete(o.misses, v.Address)
+		}
+	}
+	sort.Strings(offenders)
+	return committed, offenders
+}
+
+// medianPrice returns the median of prices, averaging the middle two of an
+// even count without overflowing
+func medianPrice(prices []uint64) uint64 {
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	mid := len(prices) / 2
+	if len(prices)%2 == 1 {
+		return prices[mid]
+	}
+	return prices[mid-1]/2 + prices[mid]/2 + (prices[mid-1]%2+prices[mid]%2)/2
+}
+
+// Price returns the latest committed price for asset, or nil before the
+// first window with votes for it closes
+func (o *PriceOracle) Price(asset string) *OraclePrice {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	price, exists := o.prices[asset]
+	if !exists {
+		return nil
+	}
+	return copyOraclePrice(price)
+}
+
+// Status returns progress of the window containing height
+func (o *PriceOracle) Status(height uint64) *OracleStatus {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	window := o.WindowOf(height)
+	status := &OracleStatus{
+		Window:       window,
+		WindowLength: o.window,
+		WindowEnds:   (window+1)*o.window - 1,
+		Votes:        make(map[string]int),
+		Prices:       make([]*OraclePrice, 0, len(o.prices)),
+		Misses:       make(map[string]uint64),
+	}
+	for _, asset := range o.assets {
+		status.Votes[asset] = len(o.votes[window][asset])
+		if price, exists := o.prices[asset]; exists {
+			status.Prices = append(status.Prices, copyOraclePrice(price))
+		}
+	}
+	for validator, misses := range o.misses {
+		status.Misses[validator] = misses
+	}
+	return status
+}
+
+// tracks reports whether asset is priced by the oracle
+func (o *PriceOracle) tracks(asset string) bool {
+	for _, a := range o.assets {
+		if a == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// isActive returns true for active validators
+func (o *PriceOracle) isActive(validator string) bool {
+	v, err := o.engine.GetValidator(validator)
+	return err == nil && v.Active
+}
+
+func copyOraclePrice(p *OraclePrice) *OraclePrice {
+	c := *p
+	c.Voters = append([]string(nil), p.Voters...)
+	return &c
+}
+
+// Price oracle errors
+var (
+	ErrNotOracleVoter     = &ValidatorError{"only active validators vote on oracle prices"}
+	ErrOracleUnknownAsset = &ValidatorError{"asset is not priced by the oracle"}
+	ErrOracleWrongWindow  = &ValidatorError{"oracle vote outside its window"}
+	ErrOracleDuplicate    = &ValidatorError{"oracle vote already recorded for this window"}
+)
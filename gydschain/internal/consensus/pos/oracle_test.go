@@ -0,0 +1,56 @@
+package pos
+
+import "testing"
+
+func TestPriceOracle(t *testing.T) {
+	engine := newTestEngine(t, "gyds1validator1", "gyds1validator2", "gyds1validator3")
+	slashing := NewSlashingKeeper(engine, nil)
+	oracle := NewPriceOracle(engine, slashing, 10, []string{"GYDS"})
+
+	if err := oracle.Vote("gyds1nobody", "GYDS", 0, 2e8, 1); err != ErrNotOracleVoter {
+		t.Fatalf("expected ErrNotOracleVoter, got %v", err)
+	}
+	if err := oracle.Vote("gyds1validator1", "GYDS", 1, 2e8, 1); err != ErrOracleWrongWindow {
+		t.Fatalf("expected ErrOracleWrongWindow, got %v", err)
+	}
+	if err := oracle.Vote("gyds1validator1", "GYD", 0, 1e8, 1); err != ErrOracleUnknownAsset {
+		t.Fatalf("expected ErrOracleUnknownAsset, got %v", err)
+	}
+
+	// The median ignores one validator reporting an outlier
+	for v, price := range map[string]uint64{"gyds1validator1": 2e8, "gyds1validator2": 21e7, "gyds1validator3": 9e8} {
+		if err := oracle.Vote(v, "GYDS", 0, price, 5); err != nil {
+			t.Fatalf("vote failed: %v", err)
+		}
+	}
+	if err := oracle.Vote("gyds1validator1", "GYDS", 0, 3e8, 6); err != ErrOracleDuplicate {
+		t.Fatalf("expected ErrOracleDuplicate, got %v", err)
+	}
+	if committed := oracle.EndBlock(8); committed != nil {
+		t.Fatalf("expected nothing committed mid-window, got %+v", committed)
+	}
+	oracle.EndBlock(9)
+	if price := oracle.Price("GYDS"); price == nil || price.Price != 21e7 || len(price.Voters) != 3 {
+		t.Fatalf("expected a median of 2.1 from 3 voters, got %+v", price)
+	}
+
+	// Validator 3 stops voting and is slashed on its third missed window
+	for window := uint64(1); window <= 3; window++ {
+		for _, v := range []string{"gyds1validator1", "gyds1validator2"} {
+			if err := oracle.Vote(v, "GYDS", window, 2e8, window*10); err != nil {
+				t.Fatalf("vote failed: %v", err)
+			}
+		}
+		oracle.EndBlock(window*10 + 9)
+	}
+	events := slashing.GetSlashingEvents(10)
+	if len(events) != 1 || events[0].ValidatorAddress != "gyds1validator3" || events[0].Reason != SlashReasonOracleMiss {
+		t.Fatalf("expected validator 3 slashed for missed votes, got %+v", events)
+	}
+	if v, _ := engine.GetValidator("gyds1validator3"); v.Active {
+		t.Errorf("expected validator 3 jailed")
+	}
+	if price := oracle.Price("GYDS"); price.Price != 2e8 || price.Window != 3 {
+		t.Errorf("expected window 3 to commit 2.0, got %+v", price)
+	}
+}
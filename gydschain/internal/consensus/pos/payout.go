@@ -0,0 +1,124 @@
+package pos
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// MaxPayoutBeneficiaries bounds how many addresses a payout split may name
+const MaxPayoutBeneficiaries = 10
+
+// ErrInvalidPayoutSplit is returned for a split whose shares are not
+// positive, name an address twice or do not add up to 100%
+var ErrInvalidPayoutSplit = errors.New("payout split shares must be positive, to distinct addresses and sum to 100%")
+
+// PayoutShare directs Share basis points of a validator's own rewards to
+// Address
+type PayoutShare struct {
+	Address string `json:"address"`
+	Share   uint64 `json:"share"` // basis points (100 = 1%)
+}
+
+// Payout is an amount withdrawn to one address
+type Payout struct {
+	Address string    `json:"address"`
+	Amount  *util.Big `json:"amount"`
+}
+
+// ValidatePayoutSplit checks a split; an empty split is valid and pays
+// everything to the validator
+func ValidatePayoutSplit(split []PayoutShare) error {
+	if len(split) > MaxPayoutBeneficiaries {
+		return ErrInvalidPayoutSplit
+	}
+	seen := make(map[string]bool, len(split))
+	var total uint64
+	for _, s := range split {
+		if s.Address == "" || s.Share == 0 || seen[s.Address] {
+			return ErrInvalidPayoutSplit
+		}
+		seen[s.Address] = true
+		total += s.Share
+	}
+	if len(split) > 0 && total != 10000 {
+		return ErrInvalidPayoutSplit
+	}
+	return nil
+}
+
+// SplitPayout divides amount by split. Rounding dust goes to the first
+// beneficiary so the payouts always add up to amount.
+func SplitPayout(amount *big.Int, split []PayoutShare) []*Payout {
+	payouts := make([]*Payout, len(split))
+	rest := util.CopyBig(amount)
+	for i, s := range split {
+		part := new(big.Int).Mul(amount, new(big.Int).SetUint64(s.Share))
+		part.Quo(part, big.NewInt(10000))
+		rest.Sub(rest, part)
+		payouts[i] = &Payout{Address: s.Address, Amount: (*util.Big)(part)}
+	}
+	if len(payouts) > 0 {
+		payouts[0].Amount = (*util.Big)(new(big.Int).Add(payouts[0].Amount.Int(), rest))
+	}
+	return payouts
+}
+
+// SetPayoutSplit sets how validator's own rewards (commission and self-stake
+// rewards) are paid out when it withdraws them; an empty split clears it
+func (e *Engine) SetPayoutSplit(validator string, split []PayoutShare) error {
+	if err := ValidatePayoutSplit(split); err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	v, exists := e.validators[validator]
+	e.mu.RUnlock()
+	if !exists {
+		return ErrValidatorNotFound
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(split) == 0 {
+		v.PayoutSplit = nil
+	} else {
+		v.PayoutSplit = append([]PayoutShare(nil), split...)
+	}
+	return nil
+}
+
+// PayoutSplit returns validator's payout split, or nil if it has none
+func (e *Engine) PayoutSplit(validator string) ([]PayoutShare, error) {
+	v, err := e.GetValidator(validator)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return append([]PayoutShare(nil), v.PayoutSplit...), nil
+}
+
+// WithdrawPayouts withdraws like WithdrawRewards and returns who gets paid.
+// A validator withdrawing its own rewards is paid by its payout split;
+// delegators are always paid in full.
+func (e *Engine) WithdrawPayouts(delegator, validator string) ([]*Payout, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	amount, err := e.withdrawRewards(delegator, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	v := e.validators[validator]
+	v.mu.RLock()
+	split := v.PayoutSplit
+	v.mu.RUnlock()
+	if delegator != validator || len(split) == 0 {
+		return []*Payout{{Address: delegator, Amount: (*util.Big)(amount)}}, nil
+	}
+	return SplitPayout(amount, split), nil
+}
@@ -0,0 +1,40 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestValidatorPayoutSplit(t *testing.T) {
+	engine := newTestEngine(t, "gyds1validator1")
+	if err := engine.Delegate("gyds1user1", "gyds1validator1", big.NewInt(1000)); err != nil {
+		t.Fatalf("delegate failed: %v", err)
+	}
+
+	bad := []PayoutShare{{Address: "gyds1ops", Share: 7000}, {Address: "gyds1team", Share: 2000}}
+	if err := engine.SetPayoutSplit("gyds1validator1", bad); err != ErrInvalidPayoutSplit {
+		t.Fatalf("expected ErrInvalidPayoutSplit for a 90%% split, got %v", err)
+	}
+	split := []PayoutShare{{Address: "gyds1ops", Share: 7000}, {Address: "gyds1team", Share: 3000}}
+	if err := engine.SetPayoutSplit("gyds1validator1", split); err != nil {
+		t.Fatalf("set split failed: %v", err)
+	}
+
+	// Validator keeps half of 10001 plus 5% commission on the other half
+	engine.ProcessRewards(big.NewInt(10001))
+
+	payouts, err := engine.WithdrawPayouts("gyds1validator1", "gyds1validator1")
+	if err != nil || len(payouts) != 2 {
+		t.Fatalf("expected two payouts, got %+v (%v)", payouts, err)
+	}
+	total := new(big.Int).Add(payouts[0].Amount.Int(), payouts[1].Amount.Int())
+	if total.Cmp(big.NewInt(5251)) != 0 || payouts[1].Amount.Int().Cmp(big.NewInt(1575)) != 0 {
+		t.Errorf("expected 5251 split 3676/1575, got %v/%v", payouts[0].Amount, payouts[1].Amount)
+	}
+
+	// Delegators are paid in full to themselves
+	payouts, err = engine.WithdrawPayouts("gyds1user1", "gyds1validator1")
+	if err != nil || len(payouts) != 1 || payouts[0].Address != "gyds1user1" {
+		t.Errorf("expected one payout to the delegator, got %+v (%v)", payouts, err)
+	}
+}
@@ -1,10 +1,16 @@
 package pos
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
+	"math/big"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // PoS consensus engine errors
@@ -15,6 +21,7 @@ var (
 	ErrValidatorNotFound  = errors.New("validator not found")
 	ErrAlreadyValidator   = errors.New("already a validator")
 	ErrInvalidSignature   = errors.New("invalid block signature")
+	ErrClockDrift         = errors.New("local clock drift exceeds threshold")
 )
 
 // Engine represents the PoS consensus engine
@@ -22,27 +29,90 @@ type Engine struct {
 	mu            sync.RWMutex
 	validators    map[string]*Validator
 	validatorList []*Validator
-	totalStake    uint64
-	minStake      uint64
+	totalStake    *big.Int
+	minStake      *big.Int
 	maxValidators uint32
 	blockTime     time.Duration
 	currentRound  uint64
 	currentLeader string
+	clock         *util.ClockMonitor
+	setListeners  []func(*ValidatorSetChange)
+	leaderSeed    []byte // latest beacon output, mixed into leader selection
+	unbonding     *UnbondingQueue
+	rewards       *rewardLedger
+}
+
+// ValidatorSetChange describes a change in active validator membership
+type ValidatorSetChange struct {
+	Round      uint64    `json:"round"`
+	Added      []string  `json:"added"`
+	Removed    []string  `json:"removed"`
+	Active     []string  `json:"active"`
+	TotalStake *util.Big `json:"total_stake"`
 }
 
 // NewEngine creates a new PoS consensus engine
-func NewEngine(minStake uint64, maxValidators uint32, blockTime time.Duration) *Engine {
+func NewEngine(minStake *big.Int, maxValidators uint32, blockTime time.Duration) *Engine {
 	return &Engine{
 		validators:    make(map[string]*Validator),
 		validatorList: make([]*Validator, 0),
-		minStake:      minStake,
+		totalStake:    new(big.Int),
+		minStake:      util.CopyBig(minStake),
 		maxValidators: maxValidators,
 		blockTime:     blockTime,
+		unbonding:     NewUnbondingQueue(UnbondingBlocks(DefaultUnbondingTime, blockTime)),
+		rewards:       newRewardLedger(),
 	}
 }
 
+// SetUnbondingTime sets how long undelegated stake stays locked
+func (e *Engine) SetUnbondingTime(d time.Duration) {
+	e.unbonding.SetPeriod(UnbondingBlocks(d, e.blockTime))
+}
+
+// Unbonding returns the queue of stake waiting out the unbonding period
+func (e *Engine) Unbonding() *UnbondingQueue {
+	return e.unbonding
+}
+
+// SetClockMonitor attaches the clock drift monitor consulted before validating
+func (e *Engine) SetClockMonitor(clock *util.ClockMonitor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clock = clock
+}
+
+// CanValidate reports whether this node may propose or sign blocks; a node
+// whose clock has drifted past the threshold must not produce timestamps
+func (e *Engine) CanValidate() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.clock != nil && e.clock.Exceeded() {
+		return ErrClockDrift
+	}
+	return nil
+}
+
+// ClockStatus returns the last clock drift check, if a monitor is attached
+func (e *Engine) ClockStatus() *util.ClockStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.clock == nil {
+		return nil
+	}
+	return e.clock.Status()
+}
+
+// OnValidatorSetChange registers a listener for active set membership
+// changes; listeners run with the engine locked and must not block
+func (e *Engine) OnValidatorSetChange(fn func(*ValidatorSetChange)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.setListeners = append(e.setListeners, fn)
+}
+
 // RegisterValidator registers a new validator
-func (e *Engine) RegisterValidator(address, pubKey string, stake uint64) error {
+func (e *Engine) RegisterValidator(address, pubKey string, stake *big.Int) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
@@ -50,7 +120,7 @@ func (e *Engine) RegisterValidator(address, pubKey string, stake uint64) error {
 		return ErrAlreadyValidator
 	}
 	
-	if stake < e.minStake {
+	if stake.Cmp(e.minStake) < 0 {
 		return ErrInsufficientStake
 	}
 	
@@ -58,7 +128,7 @@ func (e *Engine) RegisterValidator(address, pubKey string, stake uint64) error {
 		// Check if new stake is higher than lowest
 		if len(e.validatorList) > 0 {
 			lowest := e.validatorList[len(e.validatorList)-1]
-			if stake <= lowest.TotalStake {
+			if stake.Cmp(lowest.TotalStake) <= 0 {
 				return errors.New("stake too low to join validator set")
 			}
 		}
@@ -66,7 +136,7 @@ func (e *Engine) RegisterValidator(address, pubKey string, stake uint64) error {
 	
 	validator := NewValidator(address, pubKey, stake)
 	e.validators[address] = validator
-	e.totalStake += stake
+	e.totalStake = new(big.Int).Add(e.totalStake, stake)
 	
 	e.updateValidatorList()
 	
@@ -83,7 +153,7 @@ func (e *Engine) UnregisterValidator(address string) error {
 		return ErrValidatorNotFound
 	}
 	
-	e.totalStake -= validator.TotalStake
+	e.totalStake = new(big.Int).Sub(e.totalStake, validator.TotalStake)
 	delete(e.validators, address)
 	e.updateValidatorList()
 	
@@ -91,7 +161,7 @@ func (e *Engine) UnregisterValidator(address string) error {
 }
 
 // Delegate adds stake delegation to a validator
-func (e *Engine) Delegate(delegator, validator string, amount uint64) error {
+func (e *Engine) Delegate(delegator, validator string, amount *big.Int) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
@@ -100,31 +170,35 @@ func (e *Engine) Delegate(delegator, validator string, amount uint64) error {
 		return ErrValidatorNotFound
 	}
 	
+	e.rewards.settle(v, delegator)
 	v.AddDelegation(delegator, amount)
-	e.totalStake += amount
+	e.totalStake = new(big.Int).Add(e.totalStake, amount)
 	e.updateValidatorList()
 	
 	return nil
 }
 
-// Undelegate removes stake delegation from a validator
-func (e *Engine) Undelegate(delegator, validator string, amount uint64) error {
+// Undelegate removes stake delegation from a validator at height. The stake
+// stops counting toward the validator at once but is only released to the
+// delegator when the returned unbonding entry matures.
+func (e *Engine) Undelegate(delegator, validator string, amount *big.Int, height uint64) (*UnbondingEntry, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
 	v, exists := e.validators[validator]
 	if !exists {
-		return ErrValidatorNotFound
+		return nil, ErrValidatorNotFound
 	}
 	
+	e.rewards.settle(v, delegator)
 	if err := v.RemoveDelegation(delegator, amount); err != nil {
-		return err
+		return nil, err
 	}
 	
-	e.totalStake -= amount
+	e.totalStake = new(big.Int).Sub(e.totalStake, amount)
 	e.updateValidatorList()
 	
-	return nil
+	return e.unbonding.Add(delegator, validator, amount, height), nil
 }
 
 // SelectLeader selects the block proposer for a round
@@ -132,28 +206,71 @@ func (e *Engine) SelectLeader(round uint64) (*Validator, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
-	if len(e.validatorList) == 0 {
+	if len(e.validatorList) == 0 || e.totalStake.Sign() == 0 {
 		return nil, ErrNoValidators
 	}
 	
 	e.currentRound = round
 	
 	// Weighted random selection based on stake
-	totalWeight := e.totalStake
-	target := round % totalWeight
+	e.currentLeader = e.leaderAt(round)
+	return e.validators[e.currentLeader], nil
+}
+
+// LeaderAt returns the leader of round without making it the current
+// round, so a block proposed for it can be checked
+func (e *Engine) LeaderAt(round uint64) (string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	
+	if len(e.validatorList) == 0 || e.totalStake.Sign() == 0 {
+		return "", ErrNoValidators
+	}
+	return e.leaderAt(round), nil
+}
+
+// IsActive reports whether address is in the active validator set; jailed,
+// unbonding and under-staked validators are not
+func (e *Engine) IsActive(address string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	
-	var cumulative uint64
 	for _, v := range e.validatorList {
-		cumulative += v.TotalStake
-		if cumulative > target {
-			e.currentLeader = v.Address
-			return v, nil
+		if v.Address == address {
+			return true
 		}
 	}
-	
-	// Fallback to first validator
-	e.currentLeader = e.validatorList[0].Address
-	return e.validatorList[0], nil
+	return false
+}
+
+// leaderAt computes the leader for a round; callers must hold e.mu and
+// check there is stake to select from
+func (e *Engine) leaderAt(round uint64) string {
+	target := new(big.Int).SetUint64(round)
+	if len(e.leaderSeed) > 0 {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], round)
+		digest := sha256.Sum256(append(append([]byte(nil), e.leaderSeed...), buf[:]...))
+		target.SetBytes(digest[:])
+	}
+	target.Mod(target, e.totalStake)
+
+	cumulative := new(big.Int)
+	for _, v := range e.validatorList {
+		cumulative.Add(cumulative, v.TotalStake)
+		if cumulative.Cmp(target) > 0 {
+			return v.Address
+		}
+	}
+	return e.validatorList[0].Address
+}
+
+// SetLeaderSeed sets the beacon randomness mixed into leader selection so
+// future leaders cannot be predicted more than an epoch ahead
+func (e *Engine) SetLeaderSeed(seed []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leaderSeed = append([]byte(nil), seed...)
 }
 
 // VerifyBlock verifies a block was produced by a valid validator
@@ -187,6 +304,20 @@ func (e *Engine) GetValidator(address string) (*Validator, error) {
 	return v.Copy(), nil
 }
 
+// ValidatorPubKey returns a validator's public key whether or not it is
+// active, so evidence against a jailed validator can still be checked
+func (e *Engine) ValidatorPubKey(address string) ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	
+	v, exists := e.validators[address]
+	if !exists {
+		return nil, ErrValidatorNotFound
+	}
+	
+	return crypto.ParsePublicKey(v.PubKey)
+}
+
 // GetValidators returns all active validators
 func (e *Engine) GetValidators() []*Validator {
 	e.mu.RLock()
@@ -201,46 +332,104 @@ func (e *Engine) GetValidators() []*Validator {
 }
 
 // GetTotalStake returns the total staked amount
-func (e *Engine) GetTotalStake() uint64 {
+func (e *Engine) GetTotalStake() *big.Int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return util.CopyBig(e.totalStake)
+}
+
+// ValidatorStake returns the total stake of an active validator, or nil if
+// address is not in the active set
+func (e *Engine) ValidatorStake(address string) *big.Int {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return e.totalStake
+
+	for _, v := range e.validatorList {
+		if v.Address == address {
+			return util.CopyBig(v.TotalStake)
+		}
+	}
+	return nil
 }
 
 // updateValidatorList updates and sorts the validator list
 func (e *Engine) updateValidatorList() {
+	previous := make(map[string]bool, len(e.validatorList))
+	for _, v := range e.validatorList {
+		previous[v.Address] = true
+	}
+	
 	e.validatorList = make([]*Validator, 0, len(e.validators))
 	
 	for _, v := range e.validators {
-		if v.Active && v.TotalStake >= e.minStake {
+		if v.Active && v.TotalStake.Cmp(e.minStake) >= 0 {
 			e.validatorList = append(e.validatorList, v)
 		}
 	}
 	
 	// Sort by stake (descending)
 	sort.Slice(e.validatorList, func(i, j int) bool {
-		return e.validatorList[i].TotalStake > e.validatorList[j].TotalStake
+		return e.validatorList[i].TotalStake.Cmp(e.validatorList[j].TotalStake) > 0
 	})
 	
 	// Limit to max validators
 	if uint32(len(e.validatorList)) > e.maxValidators {
 		e.validatorList = e.validatorList[:e.maxValidators]
 	}
+	
+	e.notifySetChange(previous)
+}
+
+// notifySetChange tells listeners which validators joined or left the
+// active set; callers must hold e.mu
+func (e *Engine) notifySetChange(previous map[string]bool) {
+	if len(e.setListeners) == 0 {
+		return
+	}
+	
+	change := &ValidatorSetChange{
+		Round:   e.currentRound,
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Active:  make([]string, 0, len(e.validatorList)),
+	}
+	total := new(big.Int)
+	change.TotalStake = (*util.Big)(total)
+	for _, v := range e.validatorList {
+		change.Active = append(change.Active, v.Address)
+		total.Add(total, v.TotalStake)
+		if !previous[v.Address] {
+			change.Added = append(change.Added, v.Address)
+		}
+		delete(previous, v.Address)
+	}
+	for addr := range previous {
+		change.Removed = append(change.Removed, addr)
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return
+	}
+	sort.Strings(change.Removed)
+	
+	for _, fn := range e.setListeners {
+		fn(change)
+	}
 }
 
-// ProcessRewards distributes block rewards
-func (e *Engine) ProcessRewards(blockReward uint64) {
+// ProcessRewards distributes block rewards across validators by stake. Each
+// validator's delegators share in its part after its commission.
+func (e *Engine) ProcessRewards(blockReward *big.Int) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
-	if len(e.validatorList) == 0 || e.totalStake == 0 {
+	if len(e.validatorList) == 0 || e.totalStake.Sign() == 0 {
 		return
 	}
 	
 	for _, v := range e.validatorList {
 		// Proportional reward based on stake
-		reward := (blockReward * v.TotalStake) / e.totalStake
-		v.AddReward(reward)
+		reward := new(big.Int).Mul(blockReward, v.TotalStake)
+		e.rewards.distribute(v, reward.Quo(reward, e.totalStake))
 	}
 }
 
@@ -251,6 +440,35 @@ func (e *Engine) CurrentLeader() string {
 	return e.currentLeader
 }
 
+// BlockTime returns the target time between blocks
+func (e *Engine) BlockTime() time.Duration {
+	return e.blockTime
+}
+
+// MinStake returns the stake a validator needs to register
+func (e *Engine) MinStake() *big.Int {
+	return util.CopyBig(e.minStake)
+}
+
+// SetCommission sets a validator's commission in basis points
+func (e *Engine) SetCommission(address string, commission uint64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	
+	v, exists := e.validators[address]
+	if !exists {
+		return ErrValidatorNotFound
+	}
+	return v.SetCommission(commission)
+}
+
+// CurrentRound returns the most recent round a leader was selected for
+func (e *Engine) CurrentRound() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.currentRound
+}
+
 // ValidatorCount returns the number of active validators
 func (e *Engine) ValidatorCount() int {
 	e.mu.RLock()
@@ -0,0 +1,30 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestEngine returns an engine with 5 second blocks and a minimum stake
+// of 100, with validators registered at a stake of 1000 each
+func newTestEngine(t *testing.T, validators ...string) *Engine {
+	t.Helper()
+	engine := NewEngine(big.NewInt(100), 10, 5*time.Second)
+	for _, v := range validators {
+		if err := engine.RegisterValidator(v, "", big.NewInt(1000)); err != nil {
+			t.Fatalf("register %s: %v", v, err)
+		}
+	}
+	return engine
+}
+
+func TestSelectLeaderWithoutStake(t *testing.T) {
+	engine := NewEngine(new(big.Int), 10, 5*time.Second)
+	if err := engine.RegisterValidator("gyds1validator1", "", new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.SelectLeader(1); err != ErrNoValidators {
+		t.Errorf("expected ErrNoValidators with no stake, got %v", err)
+	}
+}
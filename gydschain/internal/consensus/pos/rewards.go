@@ -0,0 +1,176 @@
+package pos
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// ErrNoRewards is returned when a withdrawal finds nothing accrued
+var ErrNoRewards = errors.New("no rewards to withdraw")
+
+// rewardPrecision scales the per-stake reward index so small rewards on
+// large delegations are not lost to integer division
+var rewardPrecision = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// DelegatorReward is the reward a delegator has accrued with one validator
+// and not yet withdrawn. For a validator's own entry Delegation is its self
+// stake and Pending includes its commission.
+type DelegatorReward struct {
+	Delegator  string    `json:"delegator"`
+	Validator  string    `json:"validator"`
+	Delegation *util.Big `json:"delegation"`
+	Pending    *util.Big `json:"pending"`
+}
+
+// rewardLedger tracks delegator rewards F1-style: each validator keeps a
+// running reward per unit of delegated stake, and a delegation is owed its
+// stake times the growth of that index since it last changed. Distributing
+// a reward is then constant time however many delegators a validator has.
+// The engine lock guards it.
+type rewardLedger struct {
+	index   map[string]*big.Int            // validator -> reward per delegated unit, scaled by rewardPrecision
+	start   map[string]map[string]*big.Int // validator -> delegator -> index when last settled
+	pending map[string]map[string]*big.Int // validator -> delegator -> settled, not yet withdrawn
+}
+
+func newRewardLedger() *rewardLedger {
+	return &rewardLedger{
+		index:   make(map[string]*big.Int),
+		start:   make(map[string]map[string]*big.Int),
+		pending: make(map[string]map[string]*big.Int),
+	}
+}
+
+// distribute splits reward between v and its delegators. Delegators share
+// in proportion to stake after v's commission on their part; v keeps the
+// rest, including whatever the index cannot represent exactly.
+func (l *rewardLedger) distribute(v *Validator, reward *big.Int) {
+	v.mu.RLock()
+	total := util.CopyBig(v.TotalStake)
+	commission := v.Commission
+	delegated := new(big.Int)
+	for _, amount := range v.Delegations {
+		delegated.Add(delegated, amount)
+	}
+	v.mu.RUnlock()
+
+	distributed := new(big.Int)
+	if delegated.Sign() > 0 && total.Sign() > 0 {
+		share := new(big.Int).Mul(reward, delegated)
+		share.Quo(share, total)
+		fee := new(big.Int).Mul(share, new(big.Int).SetUint64(commission))
+		share.Sub(share, fee.Quo(fee, big.NewInt(10000)))
+
+		step := new(big.Int).Mul(share, rewardPrecision)
+		step.Quo(step, delegated)
+		l.index[v.Address] = new(big.Int).Add(util.CopyBig(l.index[v.Address]), step)
+
+		distributed.Mul(step, delegated)
+		distributed.Quo(distributed, rewardPrecision)
+	}
+
+	v.AddReward(new(big.Int).Sub(reward, distributed))
+}
+
+// settle moves what delegator's current stake with v has earned since it
+// last settled into pending. It must run before the delegation changes.
+func (l *rewardLedger) settle(v *Validator, delegator string) {
+	index := util.CopyBig(l.index[v.Address])
+	starts := l.start[v.Address]
+	if starts == nil {
+		starts = make(map[string]*big.Int)
+		l.start[v.Address] = starts
+	}
+
+	owed := new(big.Int).Sub(index, util.CopyBig(starts[delegator]))
+	owed.Mul(owed, v.GetDelegation(delegator))
+	owed.Quo(owed, rewardPrecision)
+	starts[delegator] = index
+
+	if owed.Sign() > 0 {
+		pending := l.pending[v.Address]
+		if pending == nil {
+			pending = make(map[string]*big.Int)
+			l.pending[v.Address] = pending
+		}
+		pending[delegator] = new(big.Int).Add(util.CopyBig(pending[delegator]), owed)
+	}
+}
+
+// take settles and clears delegator's pending reward with v
+func (l *rewardLedger) take(v *Validator, delegator string) *big.Int {
+	l.settle(v, delegator)
+	amount := util.CopyBig(l.pending[v.Address][delegator])
+	delete(l.pending[v.Address], delegator)
+	if v.GetDelegation(delegator).Sign() == 0 {
+		delete(l.start[v.Address], delegator)
+	}
+	return amount
+}
+
+// PendingRewards returns delegator's unwithdrawn rewards with every
+// validator, ordered by validator
+func (e *Engine) PendingRewards(delegator string) []*DelegatorReward {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rewards := make([]*DelegatorReward, 0)
+	for _, v := range e.validators {
+		delegation := v.GetDelegation(delegator)
+		pending := util.CopyBig(e.rewards.pending[v.Address][delegator])
+		if delegation.Sign() > 0 {
+			owed := new(big.Int).Sub(util.CopyBig(e.rewards.index[v.Address]), util.CopyBig(e.rewards.start[v.Address][delegator]))
+			owed.Mul(owed, delegation)
+			pending.Add(pending, owed.Quo(owed, rewardPrecision))
+		}
+		if v.Address == delegator {
+			v.mu.RLock()
+			delegation.Add(delegation, v.SelfStake)
+			pending.Add(pending, util.CopyBig(v.Rewards))
+			v.mu.RUnlock()
+		}
+		if delegation.Sign() == 0 && pending.Sign() == 0 {
+			continue
+		}
+		rewards = append(rewards, &DelegatorReward{
+			Delegator:  delegator,
+			Validator:  v.Address,
+			Delegation: (*util.Big)(delegation),
+			Pending:    (*util.Big)(pending),
+		})
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Validator < rewards[j].Validator
+	})
+	return rewards
+}
+
+// WithdrawRewards pays out delegator's rewards accrued with validator; a
+// validator withdrawing from itself also collects its commission and
+// self-stake rewards
+func (e *Engine) WithdrawRewards(delegator, validator string) (*big.Int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.withdrawRewards(delegator, validator)
+}
+
+// withdrawRewards is WithdrawRewards for callers holding e.mu
+func (e *Engine) withdrawRewards(delegator, validator string) (*big.Int, error) {
+	v, exists := e.validators[validator]
+	if !exists {
+		return nil, ErrValidatorNotFound
+	}
+
+	amount := e.rewards.take(v, delegator)
+	if delegator == validator {
+		amount.Add(amount, v.WithdrawRewards())
+	}
+	if amount.Sign() == 0 {
+		return nil, ErrNoRewards
+	}
+	return amount, nil
+}
@@ -0,0 +1,46 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDelegatorRewards(t *testing.T) {
+	engine := newTestEngine(t, "gyds1validator1")
+	if err := engine.Delegate("gyds1user1", "gyds1validator1", big.NewInt(3000)); err != nil {
+		t.Fatalf("delegate failed: %v", err)
+	}
+
+	// Delegators earn 3/4 of the reward less the 5% default commission
+	engine.ProcessRewards(big.NewInt(10000))
+
+	rewards := engine.PendingRewards("gyds1user1")
+	if len(rewards) != 1 || rewards[0].Pending.Int().Cmp(big.NewInt(7125)) != 0 {
+		t.Fatalf("expected 7125 pending for the delegator, got %+v", rewards)
+	}
+
+	// A later delegation earns nothing from earlier rewards
+	if err := engine.Delegate("gyds1user2", "gyds1validator1", big.NewInt(4000)); err != nil {
+		t.Fatalf("delegate failed: %v", err)
+	}
+	engine.ProcessRewards(big.NewInt(8000))
+
+	amount, err := engine.WithdrawRewards("gyds1user1", "gyds1validator1")
+	if err != nil || amount.Cmp(big.NewInt(7125+2850)) != 0 {
+		t.Errorf("expected delegator 1 to withdraw 9975, got %v (%v)", amount, err)
+	}
+	amount, err = engine.WithdrawRewards("gyds1user2", "gyds1validator1")
+	if err != nil || amount.Cmp(big.NewInt(3800)) != 0 {
+		t.Errorf("expected delegator 2 to withdraw 3800, got %v (%v)", amount, err)
+	}
+
+	// The validator keeps the rest: its own share plus commission
+	amount, err = engine.WithdrawRewards("gyds1validator1", "gyds1validator1")
+	if err != nil || amount.Cmp(big.NewInt(18000-9975-3800)) != 0 {
+		t.Errorf("expected the validator to withdraw 4225, got %v (%v)", amount, err)
+	}
+
+	if _, err := engine.WithdrawRewards("gyds1user1", "gyds1validator1"); err != ErrNoRewards {
+		t.Errorf("expected ErrNoRewards on a second withdrawal, got %v", err)
+	}
+}
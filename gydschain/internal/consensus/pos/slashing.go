@@ -1,8 +1,11 @@
 package pos
 
 import (
+	"encoding/json"
+	"math/big"
 	"sync"
-	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // SlashingReason defines why a validator was slashed
@@ -13,6 +16,7 @@ const (
 	SlashReasonDowntime      SlashingReason = "downtime"
 	SlashReasonMisbehavior   SlashingReason = "misbehavior"
 	SlashReasonInvalidBlock  SlashingReason = "invalid_block"
+	SlashReasonOracleMiss    SlashingReason = "oracle_miss"
 )
 
 // SlashingParams defines slashing parameters
@@ -20,10 +24,12 @@ type SlashingParams struct {
 	DoubleSignPenalty   uint64        `json:"double_sign_penalty"`   // basis points
 	DowntimePenalty     uint64        `json:"downtime_penalty"`      // basis points
 	MisbehaviorPenalty  uint64        `json:"misbehavior_penalty"`   // basis points
+	OracleMissPenalty   uint64        `json:"oracle_miss_penalty"`   // basis points
 	MinSignedPerWindow  uint64        `json:"min_signed_per_window"` // minimum blocks to sign
 	SignedBlocksWindow  uint64        `json:"signed_blocks_window"`  // window size
-	DowntimeJailDuration time.Duration `json:"downtime_jail_duration"`
-	DoubleSignJailDuration time.Duration `json:"double_sign_jail_duration"`
+	DowntimeJailBlocks   uint64        `json:"downtime_jail_blocks"`
+	DoubleSignJailBlocks uint64        `json:"double_sign_jail_blocks"`
+	AppealWindow         uint64        `json:"appeal_window"` // blocks slashed funds stay in escrow
 }
 
 // DefaultSlashingParams returns default slashing parameters
@@ -32,10 +38,12 @@ func DefaultSlashingParams() *SlashingParams {
 		DoubleSignPenalty:      500,  // 5%
 		DowntimePenalty:        100,  // 1%
 		MisbehaviorPenalty:     200,  // 2%
+		OracleMissPenalty:      100,  // 1%
 		MinSignedPerWindow:     50,   // 50%
 		SignedBlocksWindow:     1000,
-		DowntimeJailDuration:   time.Hour * 24,
-		DoubleSignJailDuration: time.Hour * 24 * 30, // 30 days
+		DowntimeJailBlocks:     24 * 60 * 12,      // 1 day of 5s blocks
+		DoubleSignJailBlocks:   30 * 24 * 60 * 12, // 30 days of 5s blocks
+		AppealWindow:           7 * 24 * 60 * 12,  // 7 days of 5s blocks
 	}
 }
 
@@ -46,6 +54,10 @@ type SlashingKeeper struct {
 	engine            *Engine
 	signingInfo       map[string]*ValidatorSigningInfo
 	slashingEvents    []SlashingEvent
+	escrows           map[string]*SlashEscrow
+	windowVotes       map[string]uint64 // voter -> proposed appeal window
+	escrowSeq         uint64            // escrows opened, numbering their IDs
+	blockTime         int64             // timestamp of the block being applied
 }
 
 // ValidatorSigningInfo tracks validator signing history
@@ -53,9 +65,11 @@ type ValidatorSigningInfo struct {
 	Address             string `json:"address"`
 	StartHeight         uint64 `json:"start_height"`
 	IndexOffset         uint64 `json:"index_offset"`
-	JailedUntil         int64  `json:"jailed_until"`
+	JailedUntil         uint64 `json:"jailed_until"` // first height it may unjail
 	Tombstoned          bool   `json:"tombstoned"`
 	MissedBlocksCounter uint64 `json:"missed_blocks_counter"`
+	MissedStreak        uint64 `json:"missed_streak"` // consecutive blocks missed up to the last one seen
+	LastHeight          uint64 `json:"last_height"`
 	SignedBlocksBitmap  []bool `json:"signed_blocks_bitmap"`
 }
 
@@ -64,8 +78,32 @@ type SlashingEvent struct {
 	ValidatorAddress string         `json:"validator_address"`
 	Height           uint64         `json:"height"`
 	Reason           SlashingReason `json:"reason"`
-	Amount           uint64         `json:"amount"`
+	Amount           *big.Int       `json:"amount"`
 	Timestamp        int64          `json:"timestamp"`
+	EscrowID         string         `json:"escrow_id,omitempty"`
+}
+
+// MarshalJSON encodes the slashed amount as a decimal string
+func (e SlashingEvent) MarshalJSON() ([]byte, error) {
+	type plain SlashingEvent
+	return json.Marshal(struct {
+		*plain
+		Amount *util.Big `json:"amount"`
+	}{(*plain)(&e), (*util.Big)(e.Amount)})
+}
+
+// UnmarshalJSON decodes the slashed amount from a string or number
+func (e *SlashingEvent) UnmarshalJSON(data []byte) error {
+	type plain SlashingEvent
+	dec := struct {
+		*plain
+		Amount *util.Big `json:"amount"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	e.Amount = dec.Amount.Int()
+	return nil
 }
 
 // NewSlashingKeeper creates a new slashing keeper
@@ -79,43 +117,29 @@ func NewSlashingKeeper(engine *Engine, params *SlashingParams) *SlashingKeeper {
 		engine:         engine,
 		signingInfo:    make(map[string]*ValidatorSigningInfo),
 		slashingEvents: make([]SlashingEvent, 0),
+		escrows:        make(map[string]*SlashEscrow),
+		windowVotes:    make(map[string]uint64),
 	}
 }
 
-// HandleDoubleSign processes a double signing infraction
-func (k *SlashingKeeper) HandleDoubleSign(address string, height uint64) error {
+// HandleDoubleSign processes a double signing infraction at infraction,
+// proven by evidence in the block at height
+func (k *SlashingKeeper) HandleDoubleSign(address string, infraction, height uint64) error {
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
-	validator, err := k.engine.GetValidator(address)
-	if err != nil {
-		return err
-	}
-
 	// Check if already tombstoned
 	info := k.getOrCreateSigningInfo(address)
 	if info.Tombstoned {
 		return nil // Already permanently jailed
 	}
 
-	// Slash
-	slashAmount := validator.Slash(k.params.DoubleSignPenalty/100, string(SlashReasonDoubleSign), height)
-
-	// Jail
-	validator.Jail(k.params.DoubleSignJailDuration)
+	if err := k.jailAndEscrow(address, k.params.DoubleSignPenalty, SlashReasonDoubleSign, infraction, height, k.params.DoubleSignJailBlocks); err != nil {
+		return err
+	}
 
 	// Tombstone (permanent)
 	info.Tombstoned = true
-
-	// Record event
-	k.slashingEvents = append(k.slashingEvents, SlashingEvent{
-		ValidatorAddress: address,
-		Height:           height,
-		Reason:           SlashReasonDoubleSign,
-		Amount:           slashAmount,
-		Timestamp:        time.Now().Unix(),
-	})
-
 	return nil
 }
 
@@ -123,36 +147,37 @@ func (k *SlashingKeeper) HandleDoubleSign(address string, height uint64) error {
 func (k *SlashingKeeper) HandleDowntime(address string, height uint64) error {
 	k.mu.Lock()
 	defer k.mu.Unlock()
+	return k.handleDowntime(address, height)
+}
 
-	validator, err := k.engine.GetValidator(address)
-	if err != nil {
-		return err
+// handleDowntime jails a validator for downtime unless it is already
+// jailed; callers must hold k.mu
+func (k *SlashingKeeper) handleDowntime(address string, height uint64) error {
+	if height < k.getOrCreateSigningInfo(address).JailedUntil {
+		return nil
 	}
+	return k.jailAndEscrow(address, k.params.DowntimePenalty, SlashReasonDowntime, height, height, k.params.DowntimeJailBlocks)
+}
 
-	info := k.getOrCreateSigningInfo(address)
+// HandleOracleMiss processes a validator that kept missing oracle votes.
+// Like downtime it is jailed, so the penalty is not repeated until it
+// unjails.
+func (k *SlashingKeeper) HandleOracleMiss(address string, height uint64) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
 
-	// Check if already jailed
-	if info.JailedUntil > time.Now().Unix() {
+	if height < k.getOrCreateSigningInfo(address).JailedUntil {
 		return nil
 	}
+	return k.jailAndEscrow(address, k.params.OracleMissPenalty, SlashReasonOracleMiss, height, height, k.params.DowntimeJailBlocks)
+}
 
-	// Slash
-	slashAmount := validator.Slash(k.params.DowntimePenalty/100, string(SlashReasonDowntime), height)
-
-	// Jail
-	validator.Jail(k.params.DowntimeJailDuration)
-	info.JailedUntil = time.Now().Add(k.params.DowntimeJailDuration).Unix()
-
-	// Record event
-	k.slashingEvents = append(k.slashingEvents, SlashingEvent{
-		ValidatorAddress: address,
-		Height:           height,
-		Reason:           SlashReasonDowntime,
-		Amount:           slashAmount,
-		Timestamp:        time.Now().Unix(),
-	})
-
-	return nil
+// SetBlockTime records the timestamp of the block being applied, which
+// slashing events it triggers are stamped with
+func (k *SlashingKeeper) SetBlockTime(timestamp int64) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.blockTime = timestamp
 }
 
 // SignBlock records a validator signing a block
@@ -180,12 +205,16 @@ func (k *SlashingKeeper) SignBlock(address string, height uint64, signed bool) {
 
 	if !signed {
 		info.MissedBlocksCounter++
+		info.MissedStreak++
+	} else {
+		info.MissedStreak = 0
 	}
+	info.LastHeight = height
 
 	// Check for downtime
 	minSigned := (k.params.SignedBlocksWindow * k.params.MinSignedPerWindow) / 100
 	if info.MissedBlocksCounter > k.params.SignedBlocksWindow-minSigned {
-		go k.HandleDowntime(address, height)
+		k.handleDowntime(address, height)
 	}
 }
 
@@ -234,6 +263,20 @@ func (k *SlashingKeeper) GetSlashingEvents(limit int) []SlashingEvent {
 	return events
 }
 
+// SlashesBetween returns slashing events at heights from..to inclusive
+func (k *SlashingKeeper) SlashesBetween(from, to uint64) []SlashingEvent {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	events := make([]SlashingEvent, 0)
+	for _, event := range k.slashingEvents {
+		if event.Height >= from && event.Height <= to {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
 // IsTombstoned returns true if validator is permanently jailed
 func (k *SlashingKeeper) IsTombstoned(address string) bool {
 	k.mu.RLock()
@@ -247,8 +290,8 @@ func (k *SlashingKeeper) IsTombstoned(address string) bool {
 	return info.Tombstoned
 }
 
-// Unjail attempts to unjail a validator
-func (k *SlashingKeeper) Unjail(address string) error {
+// Unjail attempts to unjail a validator at height
+func (k *SlashingKeeper) Unjail(address string, height uint64) error {
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
@@ -261,16 +304,11 @@ func (k *SlashingKeeper) Unjail(address string) error {
 		return &SlashingError{"validator is tombstoned"}
 	}
 
-	if info.JailedUntil > time.Now().Unix() {
+	if height < info.JailedUntil {
 		return ErrStillJailed
 	}
 
-	validator, err := k.engine.GetValidator(address)
-	if err != nil {
-		return err
-	}
-
-	return validator.Unjail()
+	return k.engine.unjailValidator(address, height)
 }
 
 // UpdateParams updates slashing parameters
@@ -0,0 +1,68 @@
+package pos
+
+import "testing"
+
+func TestOracleMissJailsForBlocks(t *testing.T) {
+	engine := newTestEngine(t, "gyds1validator1", "gyds1validator2")
+	keeper := NewSlashingKeeper(engine, &SlashingParams{
+		OracleMissPenalty:  100,
+		SignedBlocksWindow: 100,
+		DowntimeJailBlocks: 20,
+		AppealWindow:       10,
+	})
+	keeper.SetBlockTime(1700000000)
+
+	if err := keeper.HandleOracleMiss("gyds1validator1", 10); err != nil {
+		t.Fatal(err)
+	}
+	if until := keeper.GetSigningInfo("gyds1validator1").JailedUntil; until != 30 {
+		t.Errorf("expected jail until height 30, got %d", until)
+	}
+	events := keeper.GetSlashingEvents(0)
+	if len(events) != 1 || events[0].Timestamp != 1700000000 {
+		t.Fatalf("expected one event stamped with the block time, got %+v", events)
+	}
+
+	// A validator still in jail is not slashed again
+	if err := keeper.HandleOracleMiss("gyds1validator1", 11); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(keeper.GetSlashingEvents(0)); n != 1 {
+		t.Errorf("expected no slash while jailed, got %d events", n)
+	}
+
+	if err := keeper.Unjail("gyds1validator1", 29); err != ErrStillJailed {
+		t.Errorf("expected ErrStillJailed before the jail ends, got %v", err)
+	}
+	if err := keeper.Unjail("gyds1validator1", 30); err != nil {
+		t.Fatalf("unjail: %v", err)
+	}
+	if !engine.IsActive("gyds1validator1") {
+		t.Error("expected the validator active after unjailing")
+	}
+}
+
+func TestEscrowIDsAreUnique(t *testing.T) {
+	engine := newTestEngine(t, "gyds1validator1", "gyds1validator2")
+	keeper := NewSlashingKeeper(engine, nil)
+
+	// A reversed slash repeated for the same infraction opens a new escrow
+	// rather than overwriting the reversed one
+	if err := keeper.HandleDoubleSign("gyds1validator1", 5, 5); err != nil {
+		t.Fatal(err)
+	}
+	first := keeper.GetPendingEscrows()[0].ID
+	if err := keeper.ReverseSlash(first, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := keeper.HandleDoubleSign("gyds1validator1", 5, 5); err != nil {
+		t.Fatal(err)
+	}
+	second := keeper.GetPendingEscrows()[0].ID
+	if first == second {
+		t.Fatalf("expected distinct escrow IDs, got %s twice", first)
+	}
+	if escrow, _ := keeper.GetEscrow(first); escrow.Status != EscrowReversed {
+		t.Errorf("expected the first escrow to stay reversed, got %s", escrow.Status)
+	}
+}
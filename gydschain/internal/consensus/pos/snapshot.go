@@ -0,0 +1,252 @@
+package pos
+
+import (
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// The Snapshot methods below capture a component's state and return a
+// function restoring it. The chain takes them before applying a block so a
+// block that fails partway leaves no votes, stake or escrows behind.
+
+// Snapshot captures the validator set, stake and reward ledger. Validators
+// are restored in place since other components hold pointers to them.
+func (e *Engine) Snapshot() func() {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	live := make(map[string]*Validator, len(e.validators))
+	saved := make(map[string]*Validator, len(e.validators))
+	for addr, v := range e.validators {
+		live[addr] = v
+		saved[addr] = v.Copy()
+	}
+	list := append([]*Validator(nil), e.validatorList...)
+	totalStake := util.CopyBig(e.totalStake)
+	leaderSeed := e.leaderSeed
+	rewards := e.rewards.copy()
+
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		for addr, v := range live {
+			v.restore(saved[addr])
+		}
+		e.validators = live
+		e.validatorList = list
+		e.totalStake = totalStake
+		e.leaderSeed = leaderSeed
+		e.rewards = rewards
+	}
+}
+
+// restore overwrites v with the fields of a copy taken earlier
+func (v *Validator) restore(from *Validator) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.PubKey = from.PubKey
+	v.SelfStake = from.SelfStake
+	v.TotalStake = from.TotalStake
+	v.Delegations = from.Delegations
+	v.Commission = from.Commission
+	v.Rewards = from.Rewards
+	v.RewardsEarned = from.RewardsEarned
+	v.Status = from.Status
+	v.Active = from.Active
+	v.JailedUntil = from.JailedUntil
+	v.UnbondingEnd = from.UnbondingEnd
+	v.SlashEvents = from.SlashEvents
+	v.PayoutSplit = from.PayoutSplit
+	v.CreatedAt = from.CreatedAt
+	v.UpdatedAt = from.UpdatedAt
+	v.BlocksProduced = from.BlocksProduced
+	v.BlocksMissed = from.BlocksMissed
+	v.Uptime = from.Uptime
+	v.Name, v.Website, v.Description = from.Name, from.Website, from.Description
+}
+
+// copy copies the ledger; its amounts are replaced rather than modified, so
+// they are shared
+func (l *rewardLedger) copy() *rewardLedger {
+	copy := &rewardLedger{
+		index:   make(map[string]*big.Int, len(l.index)),
+		start:   copyNestedBigMap(l.start),
+		pending: copyNestedBigMap(l.pending),
+	}
+	for k, v := range l.index {
+		copy.index[k] = v
+	}
+	return copy
+}
+
+func copyNestedBigMap(m map[string]map[string]*big.Int) map[string]map[string]*big.Int {
+	copy := make(map[string]map[string]*big.Int, len(m))
+	for k, inner := range m {
+		copy[k] = make(map[string]*big.Int, len(inner))
+		for k2, v := range inner {
+			copy[k][k2] = v
+		}
+	}
+	return copy
+}
+
+// Snapshot captures the queued unbondings
+func (q *UnbondingQueue) Snapshot() func() {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	entries := make(map[string][]*UnbondingEntry, len(q.entries))
+	for delegator, queued := range q.entries {
+		entries[delegator] = append([]*UnbondingEntry(nil), queued...)
+	}
+
+	return func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.entries = entries
+	}
+}
+
+// Snapshot captures the halt state and outstanding votes
+func (cb *CircuitBreaker) Snapshot() func() {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	halted, reason, haltedAt, haltedSince := cb.halted, cb.reason, cb.haltedAt, cb.haltedSince
+	haltVotes := make(map[string]string, len(cb.haltVotes))
+	for voter, r := range cb.haltVotes {
+		haltVotes[voter] = r
+	}
+	resumeVotes := make(map[string]bool, len(cb.resumeVotes))
+	for voter, vote := range cb.resumeVotes {
+		resumeVotes[voter] = vote
+	}
+
+	return func() {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+		cb.halted, cb.reason, cb.haltedAt, cb.haltedSince = halted, reason, haltedAt, haltedSince
+		cb.haltVotes = haltVotes
+		cb.resumeVotes = resumeVotes
+	}
+}
+
+// Snapshot captures the commitments, reveals and finalized epochs
+func (b *RandomnessBeacon) Snapshot() func() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	commits := make(map[uint64]map[string]string, len(b.commits))
+	for epoch, byValidator := range b.commits {
+		commits[epoch] = make(map[string]string, len(byValidator))
+		for v, c := range byValidator {
+			commits[epoch][v] = c
+		}
+	}
+	reveals := make(map[uint64]map[string][]byte, len(b.reveals))
+	for epoch, byValidator := range b.reveals {
+		reveals[epoch] = make(map[string][]byte, len(byValidator))
+		for v, secret := range byValidator {
+			reveals[epoch][v] = secret
+		}
+	}
+	finalized := make(map[uint64]*BeaconEpoch, len(b.finalized))
+	for epoch, f := range b.finalized {
+		finalized[epoch] = f
+	}
+	latest := b.latest
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.commits, b.reveals, b.finalized, b.latest = commits, reveals, finalized, latest
+	}
+}
+
+// Snapshot captures the price votes, committed prices and missed windows
+func (o *PriceOracle) Snapshot() func() {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	votes := make(map[uint64]map[string]map[string]uint64, len(o.votes))
+	for window, byAsset := range o.votes {
+		votes[window] = make(map[string]map[string]uint64, len(byAsset))
+		for asset, byValidator := range byAsset {
+			votes[window][asset] = make(map[string]uint64, len(byValidator))
+			for v, price := range byValidator {
+				votes[window][asset][v] = price
+			}
+		}
+	}
+	prices := make(map[string]*OraclePrice, len(o.prices))
+	for asset, p := range o.prices {
+		prices[asset] = p
+	}
+	misses := make(map[string]uint64, len(o.misses))
+	for v, n := range o.misses {
+		misses[v] = n
+	}
+
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.votes, o.prices, o.misses = votes, prices, misses
+	}
+}
+
+// Snapshot captures the epoch baseline and summaries
+func (t *EpochTracker) Snapshot() func() {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	baseline := make(map[string]validatorCounters, len(t.baseline))
+	for v, counters := range t.baseline {
+		baseline[v] = counters
+	}
+	summaries := make(map[uint64]*EpochSummary, len(t.summaries))
+	for epoch, s := range t.summaries {
+		summaries[epoch] = s
+	}
+	latest := t.latest
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.baseline, t.summaries, t.latest = baseline, summaries, latest
+	}
+}
+
+// Snapshot captures signing info, slashing events, escrows and the appeal
+// window and its votes. The engine the keeper slashes is captured
+// separately.
+func (k *SlashingKeeper) Snapshot() func() {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	signingInfo := make(map[string]*ValidatorSigningInfo, len(k.signingInfo))
+	for addr, info := range k.signingInfo {
+		copy := *info
+		copy.SignedBlocksBitmap = append([]bool(nil), info.SignedBlocksBitmap...)
+		signingInfo[addr] = &copy
+	}
+	events := append([]SlashingEvent(nil), k.slashingEvents...)
+	escrows := make(map[string]*SlashEscrow, len(k.escrows))
+	for id, escrow := range k.escrows {
+		escrows[id] = escrow.Copy()
+	}
+	params, escrowSeq := k.params, k.escrowSeq
+	windowVotes := make(map[string]uint64, len(k.windowVotes))
+	for voter, blocks := range k.windowVotes {
+		windowVotes[voter] = blocks
+	}
+
+	return func() {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		k.signingInfo, k.slashingEvents, k.escrows = signingInfo, events, escrows
+		k.params, k.windowVotes, k.escrowSeq = params, windowVotes, escrowSeq
+	}
+}
@@ -0,0 +1,124 @@
+package pos
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// DefaultUnbondingTime is how long undelegated stake stays locked
+const DefaultUnbondingTime = 21 * 24 * time.Hour
+
+// UnbondingEntry is stake that has left a validator and is waiting out the
+// unbonding period before it returns to the delegator's balance
+type UnbondingEntry struct {
+	Delegator        string    `json:"delegator"`
+	Validator        string    `json:"validator"`
+	Amount           *util.Big `json:"amount"`
+	CreationHeight   uint64    `json:"creation_height"`
+	CompletionHeight uint64    `json:"completion_height"`
+}
+
+// UnbondingQueue holds pending unbondings per delegator. Maturity is counted
+// in blocks rather than wall time so every node releases stake at the same
+// height.
+type UnbondingQueue struct {
+	mu      sync.RWMutex
+	period  uint64 // blocks
+	entries map[string][]*UnbondingEntry
+}
+
+// NewUnbondingQueue creates a queue releasing stake period blocks after it
+// was undelegated
+func NewUnbondingQueue(period uint64) *UnbondingQueue {
+	return &UnbondingQueue{
+		period:  period,
+		entries: make(map[string][]*UnbondingEntry),
+	}
+}
+
+// UnbondingBlocks converts an unbonding time into blocks at blockTime,
+// rounding up so stake is never released early
+func UnbondingBlocks(unbonding, blockTime time.Duration) uint64 {
+	if blockTime <= 0 {
+		return 0
+	}
+	return uint64((unbonding + blockTime - 1) / blockTime)
+}
+
+// SetPeriod changes the unbonding period for entries added from now on
+func (q *UnbondingQueue) SetPeriod(period uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.period = period
+}
+
+// Period returns the unbonding period in blocks
+func (q *UnbondingQueue) Period() uint64 {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.period
+}
+
+// Add queues amount undelegated from validator at height
+func (q *UnbondingQueue) Add(delegator, validator string, amount *big.Int, height uint64) *UnbondingEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry := &UnbondingEntry{
+		Delegator:        delegator,
+		Validator:        validator,
+		Amount:           (*util.Big)(util.CopyBig(amount)),
+		CreationHeight:   height,
+		CompletionHeight: height + q.period,
+	}
+	q.entries[delegator] = append(q.entries[delegator], entry)
+	return entry
+}
+
+// Mature removes and returns every entry completing at or before height,
+// ordered by delegator and then by the order they were queued
+func (q *UnbondingQueue) Mature(height uint64) []*UnbondingEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var matured []*UnbondingEntry
+	for delegator, entries := range q.entries {
+		pending := entries[:0]
+		for _, entry := range entries {
+			if entry.CompletionHeight <= height {
+				matured = append(matured, entry)
+			} else {
+				pending = append(pending, entry)
+			}
+		}
+		if len(pending) == 0 {
+			delete(q.entries, delegator)
+		} else {
+			q.entries[delegator] = pending
+		}
+	}
+
+	// Stable on delegator keeps each delegator's entries in queue order
+	sort.SliceStable(matured, func(i, j int) bool {
+		return matured[i].Delegator < matured[j].Delegator
+	})
+	return matured
+}
+
+// Delegations returns copies of delegator's pending unbondings, oldest first
+func (q *UnbondingQueue) Delegations(delegator string) []*UnbondingEntry {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	entries := make([]*UnbondingEntry, 0, len(q.entries[delegator]))
+	for _, entry := range q.entries[delegator] {
+		e := *entry
+		e.Amount = (*util.Big)(util.CopyBig(entry.Amount.Int()))
+		entries = append(entries, &e)
+	}
+	return entries
+}
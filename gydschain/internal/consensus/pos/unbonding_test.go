@@ -0,0 +1,37 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestUnbondingQueue(t *testing.T) {
+	// 21 days of 5 second blocks
+	period := UnbondingBlocks(DefaultUnbondingTime, 5*time.Second)
+	if period != 362880 {
+		t.Fatalf("expected 362880 unbonding blocks, got %d", period)
+	}
+
+	queue := NewUnbondingQueue(100)
+	queue.Add("gyds1user1", "gyds1validator1", big.NewInt(500), 10)
+	queue.Add("gyds1user1", "gyds1validator2", big.NewInt(700), 50)
+
+	entries := queue.Delegations("gyds1user1")
+	if len(entries) != 2 || entries[0].CompletionHeight != 110 {
+		t.Fatalf("expected two entries with the first completing at 110, got %+v", entries)
+	}
+
+	// Nothing is released before the period has passed
+	if matured := queue.Mature(109); len(matured) != 0 {
+		t.Errorf("expected no matured entries at 109, got %d", len(matured))
+	}
+
+	matured := queue.Mature(110)
+	if len(matured) != 1 || matured[0].Amount.Int().Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("expected the 500 entry to mature at 110, got %+v", matured)
+	}
+	if left := queue.Delegations("gyds1user1"); len(left) != 1 || left[0].CompletionHeight != 150 {
+		t.Errorf("expected one entry left completing at 150, got %+v", left)
+	}
+}
@@ -3,8 +3,12 @@ package pos
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"math/big"
 	"sync"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // ValidatorStatus represents validator state
@@ -20,25 +24,27 @@ const (
 // Validator represents a network validator
 type Validator struct {
 	mu           sync.RWMutex
-	Address      string            `json:"address"`
-	PubKey       string            `json:"pub_key"`
-	SelfStake    uint64            `json:"self_stake"`
-	TotalStake   uint64            `json:"total_stake"`
-	Delegations  map[string]uint64 `json:"delegations"`
-	Commission   uint64            `json:"commission"` // basis points (100 = 1%)
-	Rewards      uint64            `json:"rewards"`
-	Status       ValidatorStatus   `json:"status"`
-	Active       bool              `json:"active"`
-	JailedUntil  int64             `json:"jailed_until,omitempty"`
-	UnbondingEnd int64             `json:"unbonding_end,omitempty"`
-	SlashEvents  []SlashEvent      `json:"slash_events,omitempty"`
-	CreatedAt    int64             `json:"created_at"`
-	UpdatedAt    int64             `json:"updated_at"`
+	Address      string              `json:"address"`
+	PubKey       string              `json:"pub_key"`
+	SelfStake    *big.Int            `json:"self_stake"`
+	TotalStake   *big.Int            `json:"total_stake"`
+	Delegations  map[string]*big.Int `json:"delegations"`
+	Commission   uint64              `json:"commission"` // basis points (100 = 1%)
+	Rewards      *big.Int            `json:"rewards"`
+	Status       ValidatorStatus     `json:"status"`
+	Active       bool                `json:"active"`
+	JailedUntil  uint64              `json:"jailed_until,omitempty"` // first height it may unjail
+	UnbondingEnd int64               `json:"unbonding_end,omitempty"`
+	SlashEvents  []SlashEvent        `json:"slash_events,omitempty"`
+	PayoutSplit  []PayoutShare       `json:"payout_split,omitempty"`
+	CreatedAt    int64               `json:"created_at"`
+	UpdatedAt    int64               `json:"updated_at"`
 	
 	// Performance metrics
-	BlocksProduced   uint64 `json:"blocks_produced"`
-	BlocksMissed     uint64 `json:"blocks_missed"`
-	Uptime           float64 `json:"uptime"`
+	BlocksProduced   uint64   `json:"blocks_produced"`
+	BlocksMissed     uint64   `json:"blocks_missed"`
+	Uptime           float64  `json:"uptime"`
+	RewardsEarned    *big.Int `json:"rewards_earned"` // lifetime total, not reduced by withdrawals
 	
 	// Metadata
 	Name        string `json:"name,omitempty"`
@@ -48,53 +54,125 @@ type Validator struct {
 
 // SlashEvent records a slashing incident
 type SlashEvent struct {
-	Height    uint64 `json:"height"`
-	Reason    string `json:"reason"`
-	Amount    uint64 `json:"amount"`
-	Timestamp int64  `json:"timestamp"`
+	Height    uint64   `json:"height"`
+	Reason    string   `json:"reason"`
+	Amount    *big.Int `json:"amount"`
+	Timestamp int64    `json:"timestamp"`
+}
+
+// MarshalJSON encodes the slashed amount as a decimal string
+func (e SlashEvent) MarshalJSON() ([]byte, error) {
+	type plain SlashEvent
+	return json.Marshal(struct {
+		*plain
+		Amount *util.Big `json:"amount"`
+	}{(*plain)(&e), (*util.Big)(e.Amount)})
+}
+
+// UnmarshalJSON decodes the slashed amount from a string or number
+func (e *SlashEvent) UnmarshalJSON(data []byte) error {
+	type plain SlashEvent
+	dec := struct {
+		*plain
+		Amount *util.Big `json:"amount"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	e.Amount = dec.Amount.Int()
+	return nil
 }
 
 // NewValidator creates a new validator
-func NewValidator(address, pubKey string, stake uint64) *Validator {
+func NewValidator(address, pubKey string, stake *big.Int) *Validator {
 	return &Validator{
-		Address:     address,
-		PubKey:      pubKey,
-		SelfStake:   stake,
-		TotalStake:  stake,
-		Delegations: make(map[string]uint64),
-		Commission:  500, // 5% default
-		Status:      StatusActive,
-		Active:      true,
-		CreatedAt:   time.Now().Unix(),
-		UpdatedAt:   time.Now().Unix(),
-		Uptime:      100.0,
+		Address:       address,
+		PubKey:        pubKey,
+		SelfStake:     util.CopyBig(stake),
+		TotalStake:    util.CopyBig(stake),
+		Delegations:   make(map[string]*big.Int),
+		Commission:    500, // 5% default
+		Rewards:       new(big.Int),
+		RewardsEarned: new(big.Int),
+		Status:        StatusActive,
+		Active:        true,
+		CreatedAt:     time.Now().Unix(),
+		UpdatedAt:     time.Now().Unix(),
+		Uptime:        100.0,
+	}
+}
+
+// MarshalJSON encodes stakes and rewards as decimal strings. Callers
+// sharing the validator must hold its lock.
+func (v *Validator) MarshalJSON() ([]byte, error) {
+	type plain Validator
+	return json.Marshal(struct {
+		*plain
+		SelfStake     *util.Big   `json:"self_stake"`
+		TotalStake    *util.Big   `json:"total_stake"`
+		Delegations   util.BigMap `json:"delegations"`
+		Rewards       *util.Big   `json:"rewards"`
+		RewardsEarned *util.Big   `json:"rewards_earned"`
+	}{
+		(*plain)(v),
+		(*util.Big)(v.SelfStake),
+		(*util.Big)(v.TotalStake),
+		util.BigMap(v.Delegations),
+		(*util.Big)(v.Rewards),
+		(*util.Big)(v.RewardsEarned),
+	})
+}
+
+// UnmarshalJSON decodes stakes and rewards from strings or numbers
+func (v *Validator) UnmarshalJSON(data []byte) error {
+	type plain Validator
+	dec := struct {
+		*plain
+		SelfStake     *util.Big   `json:"self_stake"`
+		TotalStake    *util.Big   `json:"total_stake"`
+		Delegations   util.BigMap `json:"delegations"`
+		Rewards       *util.Big   `json:"rewards"`
+		RewardsEarned *util.Big   `json:"rewards_earned"`
+	}{plain: (*plain)(v)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	v.SelfStake, v.TotalStake = dec.SelfStake.Int(), dec.TotalStake.Int()
+	v.Rewards, v.RewardsEarned = dec.Rewards.Int(), dec.RewardsEarned.Int()
+	v.Delegations = dec.Delegations
+	if v.Delegations == nil {
+		v.Delegations = make(map[string]*big.Int)
 	}
+	return nil
 }
 
 // AddDelegation adds a delegation to the validator
-func (v *Validator) AddDelegation(delegator string, amount uint64) {
+func (v *Validator) AddDelegation(delegator string, amount *big.Int) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	
-	v.Delegations[delegator] += amount
-	v.TotalStake += amount
+	v.Delegations[delegator] = new(big.Int).Add(util.CopyBig(v.Delegations[delegator]), amount)
+	v.TotalStake = new(big.Int).Add(v.TotalStake, amount)
 	v.UpdatedAt = time.Now().Unix()
 }
 
 // RemoveDelegation removes a delegation
-func (v *Validator) RemoveDelegation(delegator string, amount uint64) error {
+func (v *Validator) RemoveDelegation(delegator string, amount *big.Int) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	
-	if v.Delegations[delegator] < amount {
+	delegated := util.CopyBig(v.Delegations[delegator])
+	if amount.Sign() < 0 || delegated.Cmp(amount) < 0 {
 		return ErrInsufficientStake
 	}
 	
-	v.Delegations[delegator] -= amount
-	v.TotalStake -= amount
+	delegated.Sub(delegated, amount)
+	v.TotalStake = new(big.Int).Sub(v.TotalStake, amount)
 	
-	if v.Delegations[delegator] == 0 {
+	if delegated.Sign() == 0 {
 		delete(v.Delegations, delegator)
+	} else {
+		v.Delegations[delegator] = delegated
 	}
 	
 	v.UpdatedAt = time.Now().Unix()
@@ -102,51 +180,54 @@ func (v *Validator) RemoveDelegation(delegator string, amount uint64) error {
 }
 
 // GetDelegation returns delegation amount for an address
-func (v *Validator) GetDelegation(delegator string) uint64 {
+func (v *Validator) GetDelegation(delegator string) *big.Int {
 	v.mu.RLock()
 	defer v.mu.RUnlock()
-	return v.Delegations[delegator]
+	return util.CopyBig(v.Delegations[delegator])
 }
 
 // AddReward adds rewards to the validator
-func (v *Validator) AddReward(amount uint64) {
+func (v *Validator) AddReward(amount *big.Int) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
-	v.Rewards += amount
+	v.Rewards = new(big.Int).Add(util.CopyBig(v.Rewards), amount)
+	v.RewardsEarned = new(big.Int).Add(util.CopyBig(v.RewardsEarned), amount)
 }
 
 // WithdrawRewards withdraws accumulated rewards
-func (v *Validator) WithdrawRewards() uint64 {
+func (v *Validator) WithdrawRewards() *big.Int {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	
-	rewards := v.Rewards
-	v.Rewards = 0
+	rewards := util.CopyBig(v.Rewards)
+	v.Rewards = new(big.Int)
 	return rewards
 }
 
 // Slash reduces validator stake as penalty
-func (v *Validator) Slash(percentage uint64, reason string, height uint64) uint64 {
+func (v *Validator) Slash(percentage uint64, reason string, height uint64) *big.Int {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	
-	slashAmount := (v.TotalStake * percentage) / 100
+	slashAmount := new(big.Int).Mul(v.TotalStake, new(big.Int).SetUint64(percentage))
+	slashAmount.Quo(slashAmount, big.NewInt(100))
 	
 	// Slash from self-stake first
-	if v.SelfStake >= slashAmount {
-		v.SelfStake -= slashAmount
+	if v.SelfStake.Cmp(slashAmount) >= 0 {
+		v.SelfStake = new(big.Int).Sub(v.SelfStake, slashAmount)
 	} else {
-		remaining := slashAmount - v.SelfStake
-		v.SelfStake = 0
+		remaining := new(big.Int).Sub(slashAmount, v.SelfStake)
+		v.SelfStake = new(big.Int)
 		
 		// Slash proportionally from delegations
 		for delegator, amount := range v.Delegations {
-			delegatorSlash := (amount * remaining) / (v.TotalStake - v.SelfStake)
-			v.Delegations[delegator] -= delegatorSlash
+			delegatorSlash := new(big.Int).Mul(amount, remaining)
+			delegatorSlash.Quo(delegatorSlash, v.TotalStake)
+			v.Delegations[delegator] = new(big.Int).Sub(amount, delegatorSlash)
 		}
 	}
 	
-	v.TotalStake -= slashAmount
+	v.TotalStake = new(big.Int).Sub(v.TotalStake, slashAmount)
 	v.SlashEvents = append(v.SlashEvents, SlashEvent{
 		Height:    height,
 		Reason:    reason,
@@ -158,19 +239,19 @@ func (v *Validator) Slash(percentage uint64, reason string, height uint64) uint6
 	return slashAmount
 }
 
-// Jail puts the validator in jail
-func (v *Validator) Jail(duration time.Duration) {
+// Jail puts the validator in jail until the given height
+func (v *Validator) Jail(until uint64) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	
 	v.Status = StatusJailed
 	v.Active = false
-	v.JailedUntil = time.Now().Add(duration).Unix()
+	v.JailedUntil = until
 	v.UpdatedAt = time.Now().Unix()
 }
 
-// Unjail releases the validator from jail
-func (v *Validator) Unjail() error {
+// Unjail releases the validator from jail at height
+func (v *Validator) Unjail(height uint64) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	
@@ -178,7 +259,7 @@ func (v *Validator) Unjail() error {
 		return nil
 	}
 	
-	if time.Now().Unix() < v.JailedUntil {
+	if height < v.JailedUntil {
 		return ErrStillJailed
 	}
 	
@@ -268,9 +349,10 @@ func (v *Validator) Copy() *Validator {
 		PubKey:         v.PubKey,
 		SelfStake:      v.SelfStake,
 		TotalStake:     v.TotalStake,
-		Delegations:    make(map[string]uint64),
+		Delegations:    make(map[string]*big.Int),
 		Commission:     v.Commission,
 		Rewards:        v.Rewards,
+		RewardsEarned:  v.RewardsEarned,
 		Status:         v.Status,
 		Active:         v.Active,
 		JailedUntil:    v.JailedUntil,
@@ -290,6 +372,7 @@ func (v *Validator) Copy() *Validator {
 	}
 	
 	copy.SlashEvents = append(copy.SlashEvents, v.SlashEvents...)
+	copy.PayoutSplit = append(copy.PayoutSplit, v.PayoutSplit...)
 	
 	return copy
 }
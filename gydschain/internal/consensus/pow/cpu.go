@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -18,6 +19,10 @@ type CPUMiner struct {
 	workers     int
 	stopChan    chan struct{}
 	resultChan  chan *MiningResult
+	workerRates []uint64 // hash rate of each worker
+	totalHashes uint64
+	blocksFound uint64
+	started     time.Time
 }
 
 // MiningResult contains the result of a successful mining operation
@@ -40,6 +45,7 @@ func NewCPUMiner(workers int) *CPUMiner {
 		difficulty: big.NewInt(1),
 		stopChan:   make(chan struct{}),
 		resultChan: make(chan *MiningResult, 1),
+		started:    time.Now(),
 	}
 }
 
@@ -56,6 +62,7 @@ func (m *CPUMiner) Start(blockData []byte, target *big.Int) <-chan *MiningResult
 	m.difficulty = target
 	m.stopChan = make(chan struct{})
 	m.resultChan = make(chan *MiningResult, 1)
+	m.workerRates = make([]uint64, m.workers)
 	
 	// Start worker goroutines
 	var wg sync.WaitGroup
@@ -116,6 +123,7 @@ func (m *CPUMiner) mine(blockData []byte, target *big.Int, workerID uint64, wg *
 						WorkerID:   int(workerID),
 					}
 					m.running = false
+					m.blocksFound++
 					close(m.stopChan)
 				}
 				m.mu.Unlock()
@@ -127,7 +135,12 @@ func (m *CPUMiner) mine(blockData []byte, target *big.Int, workerID uint64, wg *
 				elapsed := time.Since(startTime).Seconds()
 				if elapsed > 0 {
 					m.mu.Lock()
-					m.hashRate = uint64(float64(hashes) / elapsed)
+					m.workerRates[workerID] = uint64(float64(hashes) / elapsed)
+					m.hashRate = 0
+					for _, rate := range m.workerRates {
+						m.hashRate += rate
+					}
+					m.totalHashes += 10000
 					m.mu.Unlock()
 				}
 			}
@@ -167,6 +180,31 @@ func (m *CPUMiner) IsRunning() bool {
 	return m.running
 }
 
+// Stats returns mining statistics with each worker thread as a device
+func (m *CPUMiner) Stats() *MinerStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &MinerStats{
+		HashRate:    m.hashRate,
+		BlocksFound: m.blocksFound,
+		TotalHashes: m.totalHashes,
+		Workers:     m.workers,
+		Uptime:      time.Since(m.started).Hours(),
+	}
+	if m.workerRates != nil {
+		stats.Difficulty = targetDifficulty(m.difficulty)
+	}
+	for i, rate := range m.workerRates {
+		stats.Devices = append(stats.Devices, &DeviceStats{
+			ID:       fmt.Sprintf("cpu%d", i),
+			Name:     "CPU worker",
+			HashRate: rate,
+		})
+	}
+	return stats
+}
+
 // SetWorkers updates the number of workers
 func (m *CPUMiner) SetWorkers(workers int) {
 	m.mu.Lock()
@@ -232,13 +270,14 @@ func DifficultyAdjustment(currentDiff uint64, blockTime, targetTime time.Duratio
 
 // MinerStats contains mining statistics
 type MinerStats struct {
-	HashRate     uint64  `json:"hash_rate"`
-	BlocksFound  uint64  `json:"blocks_found"`
-	TotalHashes  uint64  `json:"total_hashes"`
-	Workers      int     `json:"workers"`
-	Difficulty   uint64  `json:"difficulty"`
-	Uptime       float64 `json:"uptime_hours"`
-	AvgBlockTime float64 `json:"avg_block_time"`
+	HashRate     uint64         `json:"hash_rate"`
+	BlocksFound  uint64         `json:"blocks_found"`
+	TotalHashes  uint64         `json:"total_hashes"`
+	Workers      int            `json:"workers"`
+	Difficulty   uint64         `json:"difficulty"`
+	Uptime       float64        `json:"uptime_hours"`
+	AvgBlockTime float64        `json:"avg_block_time"`
+	Devices      []*DeviceStats `json:"devices,omitempty"` // per CPU worker, GPU or external miner
 }
 
 // Argon2Config for memory-hard hashing (optional alternative)
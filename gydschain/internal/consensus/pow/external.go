@@ -0,0 +1,202 @@
+package pow
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// deviceTimeout drops an external device that has not reported for a while
+// from the hash rate
+const deviceTimeout = 2 * time.Minute
+
+// ExternalMiner bridges to miners running outside the node, such as GPU
+// rigs speaking getwork or stratum. It publishes the current work, checks
+// the nonces they submit and tracks the hash rate each device reports.
+type ExternalMiner struct {
+	mu          sync.RWMutex
+	running     bool
+	blockData   []byte
+	target      *big.Int
+	resultChan  chan *MiningResult
+	devices     map[string]*DeviceStats
+	blocksFound uint64
+	started     time.Time
+}
+
+// NewExternalMiner creates an external miner bridge
+func NewExternalMiner() *ExternalMiner {
+	return &ExternalMiner{
+		devices: make(map[string]*DeviceStats),
+		started: time.Now(),
+	}
+}
+
+// Start publishes new work, replacing any earlier work
+func (m *ExternalMiner) Start(blockData []byte, target *big.Int) <-chan *MiningResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		close(m.resultChan)
+	}
+	m.running = true
+	m.blockData = append([]byte(nil), blockData...)
+	m.target = new(big.Int).Set(target)
+	m.resultChan = make(chan *MiningResult, 1)
+	return m.resultChan
+}
+
+// Stop withdraws the current work
+func (m *ExternalMiner) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopLocked()
+}
+
+// stopLocked withdraws the work; the caller holds m.mu
+func (m *ExternalMiner) stopLocked() {
+	if !m.running {
+		return
+	}
+	m.running = false
+	close(m.resultChan)
+}
+
+// Work returns the current block data and target for external miners
+func (m *ExternalMiner) Work() ([]byte, *big.Int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.running {
+		return nil, nil, ErrNoWork
+	}
+	return append([]byte(nil), m.blockData...), new(big.Int).Set(m.target), nil
+}
+
+// Submit checks a nonce found by device against the current work. A nonce
+// meeting the target is delivered to the Start channel and ends the work.
+func (m *ExternalMiner) Submit(device string, nonce uint64) (*MiningResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return nil, ErrStaleWork
+	}
+	hash := hashNonce(m.blockData, nonce)
+	if new(big.Int).SetBytes(hash).Cmp(m.target) >= 0 {
+		return nil, ErrInvalidPoW
+	}
+
+	d := m.device(device)
+	d.Shares++
+	d.LastSeen = time.Now().Unix()
+	m.blocksFound++
+
+	result := &MiningResult{
+		Nonce:      nonce,
+		Hash:       hex.EncodeToString(hash),
+		Difficulty: targetDifficulty(m.target),
+		Timestamp:  time.Now().Unix(),
+	}
+	m.resultChan <- result
+	m.stopLocked()
+	return result, nil
+}
+
+// ReportHashRate records the hash rate and hash count a device reports
+func (m *ExternalMiner) ReportHashRate(device string, hashRate, totalHashes uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := m.device(device)
+	d.HashRate = hashRate
+	d.TotalHashes = totalHashes
+	d.LastSeen = time.Now().Unix()
+}
+
+// device returns the stats of a device, adding it on first sight; the
+// caller holds m.mu
+func (m *ExternalMiner) device(id string) *DeviceStats {
+	d, ok := m.devices[id]
+	if !ok {
+		d = &DeviceStats{ID: id}
+		m.devices[id] = d
+	}
+	return d
+}
+
+// GetHashRate returns the combined hash rate of devices reporting recently
+func (m *ExternalMiner) GetHashRate() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var total uint64
+	for _, d := range m.activeDevices() {
+		total += d.HashRate
+	}
+	return total
+}
+
+// IsRunning returns true while work is published
+func (m *ExternalMiner) IsRunning() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.running
+}
+
+// Stats returns mining statistics for the devices reporting recently
+func (m *ExternalMiner) Stats() *MinerStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &MinerStats{
+		BlocksFound: m.blocksFound,
+		Uptime:      time.Since(m.started).Hours(),
+	}
+	if m.target != nil {
+		stats.Difficulty = targetDifficulty(m.target)
+	}
+	for _, d := range m.activeDevices() {
+		device := *d
+		stats.HashRate += d.HashRate
+		stats.TotalHashes += d.TotalHashes
+		stats.Devices = append(stats.Devices, &device)
+	}
+	stats.Workers = len(stats.Devices)
+	return stats
+}
+
+// activeDevices returns the devices seen within deviceTimeout sorted by
+// ID; the caller holds m.mu
+func (m *ExternalMiner) activeDevices() []*DeviceStats {
+	cutoff := time.Now().Add(-deviceTimeout).Unix()
+	devices := make([]*DeviceStats, 0, len(m.devices))
+	for _, d := range m.devices {
+		if d.LastSeen >= cutoff {
+			devices = append(devices, d)
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	return devices
+}
+
+// hashNonce is the double SHA256 of blockData followed by the big-endian
+// nonce, as checked by ValidatePoW
+func hashNonce(blockData []byte, nonce uint64) []byte {
+	data := make([]byte, len(blockData)+8)
+	copy(data, blockData)
+	binary.BigEndian.PutUint64(data[len(blockData):], nonce)
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// targetDifficulty is the inverse of CalculateTarget
+func targetDifficulty(target *big.Int) uint64 {
+	if target.Sign() <= 0 {
+		return 0
+	}
+	return new(big.Int).Div(CalculateTarget(1), target).Uint64()
+}
@@ -1,38 +1,41 @@
 package pow
 
 import (
+	"math/big"
 	"sync"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // RewardDistributor handles mining reward distribution
 type RewardDistributor struct {
 	mu            sync.RWMutex
-	baseReward    uint64
+	baseReward    *big.Int
 	halving       uint64 // blocks between halvings
 	halvingCount  uint64
-	minReward     uint64
+	minReward     *big.Int
 	minerShare    uint64 // basis points (e.g., 2000 = 20%)
 	validatorShare uint64
-	totalDistributed uint64
+	totalDistributed *big.Int
 	lastHeight    uint64
 }
 
 // RewardConfig contains reward configuration
 type RewardConfig struct {
-	BaseReward     uint64 `json:"base_reward"`
-	HalvingBlocks  uint64 `json:"halving_blocks"`
-	MinReward      uint64 `json:"min_reward"`
-	MinerShare     uint64 `json:"miner_share"`     // basis points
-	ValidatorShare uint64 `json:"validator_share"` // basis points
+	BaseReward     *util.Big `json:"base_reward"`
+	HalvingBlocks  uint64    `json:"halving_blocks"`
+	MinReward      *util.Big `json:"min_reward"`
+	MinerShare     uint64    `json:"miner_share"`     // basis points
+	ValidatorShare uint64    `json:"validator_share"` // basis points
 }
 
 // DefaultRewardConfig returns default reward configuration
 func DefaultRewardConfig() *RewardConfig {
 	return &RewardConfig{
-		BaseReward:     10 * 1e8,    // 10 GYDS
-		HalvingBlocks:  2100000,     // ~4 years at 1 block/minute
-		MinReward:      1e6,         // 0.01 GYDS
+		BaseReward:     (*util.Big)(big.NewInt(10 * 1e8)), // 10 GYDS
+		HalvingBlocks:  2100000,                           // ~4 years at 1 block/minute
+		MinReward:      (*util.Big)(big.NewInt(1e6)),      // 0.01 GYDS
 		MinerShare:     2000,        // 20%
 		ValidatorShare: 8000,        // 80%
 	}
@@ -45,104 +48,109 @@ func NewRewardDistributor(config *RewardConfig) *RewardDistributor {
 	}
 	
 	return &RewardDistributor{
-		baseReward:     config.BaseReward,
+		baseReward:     util.CopyBig(config.BaseReward.Int()),
 		halving:        config.HalvingBlocks,
-		minReward:      config.MinReward,
+		minReward:      util.CopyBig(config.MinReward.Int()),
 		minerShare:     config.MinerShare,
 		validatorShare: config.ValidatorShare,
+		totalDistributed: new(big.Int),
 	}
 }
 
 // CalculateBlockReward calculates the block reward for a given height
-func (d *RewardDistributor) CalculateBlockReward(height uint64) uint64 {
+func (d *RewardDistributor) CalculateBlockReward(height uint64) *big.Int {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	
+	return d.blockReward(height)
+}
+
+// blockReward computes the reward at height; callers must hold d.mu
+func (d *RewardDistributor) blockReward(height uint64) *big.Int {
 	halvings := height / d.halving
-	reward := d.baseReward
+	reward := new(big.Int).Set(d.baseReward)
 	
-	for i := uint64(0); i < halvings && reward > d.minReward; i++ {
-		reward /= 2
+	for i := uint64(0); i < halvings && reward.Cmp(d.minReward) > 0; i++ {
+		reward.Rsh(reward, 1)
 	}
 	
-	if reward < d.minReward {
-		reward = d.minReward
+	if reward.Cmp(d.minReward) < 0 {
+		reward.Set(d.minReward)
 	}
 	
 	return reward
 }
 
 // DistributeReward calculates reward distribution
-func (d *RewardDistributor) DistributeReward(height uint64, fees uint64) *BlockReward {
+func (d *RewardDistributor) DistributeReward(height uint64, fees *big.Int) *BlockReward {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	
-	blockReward := d.CalculateBlockReward(height)
-	totalReward := blockReward + fees
+	blockReward := d.blockReward(height)
+	totalReward := new(big.Int).Add(blockReward, fees)
 	
-	minerReward := (totalReward * d.minerShare) / 10000
-	validatorReward := totalReward - minerReward
+	minerReward := new(big.Int).Mul(totalReward, new(big.Int).SetUint64(d.minerShare))
+	minerReward.Quo(minerReward, big.NewInt(10000))
+	validatorReward := new(big.Int).Sub(totalReward, minerReward)
 	
-	d.totalDistributed += totalReward
+	d.totalDistributed = new(big.Int).Add(d.totalDistributed, totalReward)
 	d.lastHeight = height
 	
 	return &BlockReward{
 		Height:          height,
-		BlockReward:     blockReward,
-		Fees:            fees,
-		TotalReward:     totalReward,
-		MinerReward:     minerReward,
-		ValidatorReward: validatorReward,
+		BlockReward:     (*util.Big)(blockReward),
+		Fees:            (*util.Big)(util.CopyBig(fees)),
+		TotalReward:     (*util.Big)(totalReward),
+		MinerReward:     (*util.Big)(minerReward),
+		ValidatorReward: (*util.Big)(validatorReward),
 		Timestamp:       time.Now().Unix(),
 	}
 }
 
 // BlockReward contains reward distribution details
 type BlockReward struct {
-	Height          uint64 `json:"height"`
-	BlockReward     uint64 `json:"block_reward"`
-	Fees            uint64 `json:"fees"`
-	TotalReward     uint64 `json:"total_reward"`
-	MinerReward     uint64 `json:"miner_reward"`
-	ValidatorReward uint64 `json:"validator_reward"`
-	Timestamp       int64  `json:"timestamp"`
+	Height          uint64    `json:"height"`
+	BlockReward     *util.Big `json:"block_reward"`
+	Fees            *util.Big `json:"fees"`
+	TotalReward     *util.Big `json:"total_reward"`
+	MinerReward     *util.Big `json:"miner_reward"`
+	ValidatorReward *util.Big `json:"validator_reward"`
+	Timestamp       int64     `json:"timestamp"`
 }
 
 // GetTotalDistributed returns total rewards distributed
-func (d *RewardDistributor) GetTotalDistributed() uint64 {
+func (d *RewardDistributor) GetTotalDistributed() *big.Int {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return d.totalDistributed
+	return util.CopyBig(d.totalDistributed)
 }
 
 // GetCurrentReward returns the current block reward
-func (d *RewardDistributor) GetCurrentReward(height uint64) uint64 {
+func (d *RewardDistributor) GetCurrentReward(height uint64) *big.Int {
 	return d.CalculateBlockReward(height)
 }
 
 // NextHalving returns the block height of the next halving
 func (d *RewardDistributor) NextHalving(currentHeight uint64) uint64 {
-	d.mu.RLock()
-	defer d.mu.RUnlock()
-	
 	currentHalving := currentHeight / d.halving
 	return (currentHalving + 1) * d.halving
 }
 
 // HalvingsOccurred returns the number of halvings that have occurred
 func (d *RewardDistributor) HalvingsOccurred(height uint64) uint64 {
-	d.mu.RLock()
-	defer d.mu.RUnlock()
 	return height / d.halving
 }
 
 // EstimatedSupply calculates estimated GYDS supply at a given height
-func (d *RewardDistributor) EstimatedSupply(height uint64) uint64 {
+func (d *RewardDistributor) EstimatedSupply(height uint64) *big.Int {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	
-	var supply uint64
-	reward := d.baseReward
+	return d.estimatedSupply(height)
+}
+
+// estimatedSupply sums rewards up to height; callers must hold d.mu
+func (d *RewardDistributor) estimatedSupply(height uint64) *big.Int {
+	supply := new(big.Int)
+	reward := new(big.Int).Set(d.baseReward)
 	
 	for h := uint64(0); h < height; {
 		blocksUntilHalving := d.halving - (h % d.halving)
@@ -152,11 +160,11 @@ func (d *RewardDistributor) EstimatedSupply(height uint64) uint64 {
 			blocksToCount = height - h
 		}
 		
-		supply += reward * blocksToCount
+		supply.Add(supply, new(big.Int).Mul(reward, new(big.Int).SetUint64(blocksToCount)))
 		h += blocksToCount
 		
-		if h%d.halving == 0 && reward > d.minReward {
-			reward /= 2
+		if h%d.halving == 0 && reward.Cmp(d.minReward) > 0 {
+			reward.Rsh(reward, 1)
 		}
 	}
 	
@@ -179,13 +187,13 @@ func (d *RewardDistributor) UpdateShares(minerShare, validatorShare uint64) erro
 
 // Stats returns reward distribution statistics
 type RewardStats struct {
-	TotalDistributed uint64  `json:"total_distributed"`
-	CurrentReward    uint64  `json:"current_reward"`
-	NextHalving      uint64  `json:"next_halving"`
-	Halvings         uint64  `json:"halvings"`
-	EstimatedSupply  uint64  `json:"estimated_supply"`
-	MinerShare       float64 `json:"miner_share_percent"`
-	ValidatorShare   float64 `json:"validator_share_percent"`
+	TotalDistributed *util.Big `json:"total_distributed"`
+	CurrentReward    *util.Big `json:"current_reward"`
+	NextHalving      uint64    `json:"next_halving"`
+	Halvings         uint64    `json:"halvings"`
+	EstimatedSupply  *util.Big `json:"estimated_supply"`
+	MinerShare       float64   `json:"miner_share_percent"`
+	ValidatorShare   float64   `json:"validator_share_percent"`
 }
 
 // GetStats returns current reward statistics
@@ -194,11 +202,11 @@ func (d *RewardDistributor) GetStats(height uint64) *RewardStats {
 	defer d.mu.RUnlock()
 	
 	return &RewardStats{
-		TotalDistributed: d.totalDistributed,
-		CurrentReward:    d.CalculateBlockReward(height),
+		TotalDistributed: (*util.Big)(util.CopyBig(d.totalDistributed)),
+		CurrentReward:    (*util.Big)(d.blockReward(height)),
 		NextHalving:      d.NextHalving(height),
 		Halvings:         height / d.halving,
-		EstimatedSupply:  d.EstimatedSupply(height),
+		EstimatedSupply:  (*util.Big)(d.estimatedSupply(height)),
 		MinerShare:       float64(d.minerShare) / 100,
 		ValidatorShare:   float64(d.validatorShare) / 100,
 	}
@@ -206,11 +214,11 @@ func (d *RewardDistributor) GetStats(height uint64) *RewardStats {
 
 // MinerPayout represents a payout to a miner
 type MinerPayout struct {
-	Address   string `json:"address"`
-	Amount    uint64 `json:"amount"`
-	BlockHash string `json:"block_hash"`
-	Height    uint64 `json:"height"`
-	Timestamp int64  `json:"timestamp"`
+	Address   string    `json:"address"`
+	Amount    *util.Big `json:"amount"`
+	BlockHash string    `json:"block_hash"`
+	Height    uint64    `json:"height"`
+	Timestamp int64     `json:"timestamp"`
 }
 
 // RewardError represents a reward calculation error
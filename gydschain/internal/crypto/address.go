@@ -103,12 +103,7 @@ func DecodeAddress(address string) ([]byte, error) {
 	}
 	
 	// Convert from 5-bit to 8-bit
-	result, err := convertBits(decoded, 5, 8, false)
-	if err != nil {
-		return nil, err
-	}
-	
-	return result, nil
+	return convertBits(decoded, 5, 8, false), nil
 }
 
 // convertBits converts between bit sizes
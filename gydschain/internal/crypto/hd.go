@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CoinType is the BIP-44 coin type GYDS keys are derived under
+const CoinType uint32 = 8888
+
+// HardenedOffset marks a hardened child index
+const HardenedOffset uint32 = 0x80000000
+
+// slip10Curve is the SLIP-0010 master key salt for ed25519
+const slip10Curve = "ed25519 seed"
+
+// DerivationPath is a sequence of child indexes below the master key
+type DerivationPath []uint32
+
+// AccountPath returns the BIP-44 path of an address:
+// m/44'/coin'/account'/0'/index'. ed25519 has no public derivation
+// (SLIP-0010), so the change and index levels are hardened too.
+func AccountPath(account, index uint32) DerivationPath {
+	return DerivationPath{
+		44 | HardenedOffset,
+		CoinType | HardenedOffset,
+		account | HardenedOffset,
+		0 | HardenedOffset,
+		index | HardenedOffset,
+	}
+}
+
+// ParsePath parses a path such as m/44'/8888'/0'/0'/3'. Levels without a
+// hardened marker are accepted and hardened, since ed25519 derivation only
+// supports hardened children.
+func ParsePath(path string) (DerivationPath, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, ErrInvalidPath
+	}
+
+	parsed := make(DerivationPath, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		part = strings.TrimRight(part, "'hH")
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, ErrInvalidPath
+		}
+		parsed = append(parsed, uint32(index)|HardenedOffset)
+	}
+	return parsed, nil
+}
+
+// String formats the path with every level marked hardened
+func (p DerivationPath) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range p {
+		fmt.Fprintf(&b, "/%d'", index&^HardenedOffset)
+	}
+	return b.String()
+}
+
+// DeriveKeyPair derives the ed25519 key at path from seed following
+// SLIP-0010
+func DeriveKeyPair(seed []byte, path DerivationPath) (*KeyPair, error) {
+	if len(seed) == 0 {
+		return nil, ErrMissingSeed
+	}
+
+	key, chainCode := hmacSHA512([]byte(slip10Curve), seed)
+	for _, index := range path {
+		data := make([]byte, 0, 1+len(key)+4)
+		data = append(data, 0)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, index|HardenedOffset)
+		key, chainCode = hmacSHA512(chainCode, data)
+	}
+	return NewKeyPairFromSeed(key)
+}
+
+// hmacSHA512 returns the two 32-byte halves of HMAC-SHA512(key, data)
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+// DeriveAccount returns the wallet for address index of the first account
+// of the mnemonic this wallet was created from
+func (w *Wallet) DeriveAccount(index uint32) (*Wallet, error) {
+	return w.DerivePath(AccountPath(0, index))
+}
+
+// DerivePath returns the wallet for the key at path below the wallet's
+// seed. Only wallets created from a mnemonic can derive.
+func (w *Wallet) DerivePath(path DerivationPath) (*Wallet, error) {
+	kp, err := DeriveKeyPair(w.seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{KeyPair: kp, Name: w.Name, Path: path.String(), seed: w.seed}, nil
+}
+
+// HD derivation errors
+var (
+	ErrInvalidPath = errors.New("invalid derivation path")
+	ErrMissingSeed = errors.New("wallet has no seed to derive from")
+)
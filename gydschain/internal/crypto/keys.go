@@ -164,10 +164,13 @@ func MnemonicToSeed(mnemonic, password string) []byte {
 	return Hash256(data)
 }
 
-// Wallet represents a simple wallet
+// Wallet represents a simple wallet. A wallet created from a mnemonic keeps
+// its seed so further accounts can be derived with DeriveAccount.
 type Wallet struct {
 	KeyPair *KeyPair
 	Name    string
+	Path    string // derivation path, empty for the mnemonic's root key
+	seed    []byte
 }
 
 // NewWallet creates a new wallet with a new key pair
@@ -183,7 +186,9 @@ func NewWallet(name string) (*Wallet, error) {
 	}, nil
 }
 
-// NewWalletFromMnemonic creates a wallet from a mnemonic
+// NewWalletFromMnemonic creates a wallet from a mnemonic. Its own key is
+// the mnemonic's root key, so existing addresses are unchanged; derived
+// accounts come from DeriveAccount.
 func NewWalletFromMnemonic(name, mnemonic, password string) (*Wallet, error) {
 	seed := MnemonicToSeed(mnemonic, password)
 	
@@ -195,6 +200,7 @@ func NewWalletFromMnemonic(name, mnemonic, password string) (*Wallet, error) {
 	return &Wallet{
 		KeyPair: kp,
 		Name:    name,
+		seed:    seed,
 	}, nil
 }
 
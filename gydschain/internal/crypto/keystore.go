@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeystoreVersion is the keystore file format version. The layout follows
+// the Ethereum v3 keystore, with AES-256-GCM in place of AES-CTR plus MAC.
+const KeystoreVersion = 3
+
+// Scrypt cost parameters. Standard is for keys at rest; Light trades
+// brute-force resistance for speed and suits tests and low-power devices.
+const (
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	LightScryptN    = 1 << 12
+	LightScryptP    = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+)
+
+// Keystore is a private key encrypted under a password
+type Keystore struct {
+	Version int            `json:"version"`
+	ID      string         `json:"id"`
+	Address string         `json:"address"`
+	Name    string         `json:"name,omitempty"`
+	Path    string         `json:"path,omitempty"` // HD derivation path of the key, if any
+	Crypto  KeystoreCrypto `json:"crypto"`
+}
+
+// KeystoreCrypto holds the ciphertext and how to derive its key
+type KeystoreCrypto struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams CipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    ScryptParams `json:"kdfparams"`
+}
+
+// CipherParams are the AES-GCM parameters
+type CipherParams struct {
+	Nonce string `json:"nonce"`
+}
+
+// ScryptParams are the scrypt key derivation parameters
+type ScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// EncryptKey encrypts kp's private key under password with scrypt cost n
+// and p. The address is authenticated along with the key, so a file whose
+// address was edited fails to decrypt.
+func EncryptKey(kp *KeyPair, name, password string, n, p int) (*Keystore, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := ScryptParams{N: n, R: scryptR, P: p, DKLen: scryptDKLen, Salt: hex.EncodeToString(salt)}
+	gcm, err := keystoreCipher(password, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	address := kp.Address()
+	return &Keystore{
+		Version: KeystoreVersion,
+		ID:      hex.EncodeToString(id),
+		Address: address,
+		Name:    name,
+		Crypto: KeystoreCrypto{
+			Cipher:       "aes-256-gcm",
+			CipherText:   hex.EncodeToString(gcm.Seal(nil, nonce, kp.PrivateKey, []byte(address))),
+			CipherParams: CipherParams{Nonce: hex.EncodeToString(nonce)},
+			KDF:          "scrypt",
+			KDFParams:    params,
+		},
+	}, nil
+}
+
+// DecryptKey recovers the key pair stored in ks
+func DecryptKey(ks *Keystore, password string) (*KeyPair, error) {
+	if ks.Version != KeystoreVersion || ks.Crypto.Cipher != "aes-256-gcm" || ks.Crypto.KDF != "scrypt" {
+		return nil, ErrKeystoreFormat
+	}
+	gcm, err := keystoreCipher(password, ks.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(ks.Crypto.CipherParams.Nonce)
+	if err != nil || len(nonce) != gcm.NonceSize() {
+		return nil, ErrKeystoreFormat
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+
+	privateKey, err := gcm.Open(nil, nonce, ciphertext, []byte(ks.Address))
+	if err != nil {
+		return nil, ErrKeystorePassword
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrKeystoreFormat
+	}
+	return NewKeyPairFromPrivateKey(privateKey)
+}
+
+// keystoreCipher derives the AES-256-GCM cipher for password
+func keystoreCipher(password string, params ScryptParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || params.DKLen != scryptDKLen {
+		return nil, ErrKeystoreFormat
+	}
+	key, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Keystore errors
+var (
+	ErrKeystoreFormat   = errors.New("unsupported or corrupt keystore file")
+	ErrKeystorePassword = errors.New("could not decrypt key with given password")
+)
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// MessagePrefix is prepended to every signed message so a message
+// signature can never be replayed as a transaction signature
+const MessagePrefix = "\x19GYDS Signed Message:\n"
+
+// Message signature errors
+var (
+	ErrInvalidMessageSignature = errors.New("invalid message signature encoding")
+	ErrMessageSignerMismatch   = errors.New("message not signed by address")
+)
+
+// MessageHash returns the digest signed for message: the prefix and the
+// message length, then the message itself
+func MessageHash(message []byte) []byte {
+	data := append([]byte(MessagePrefix+strconv.Itoa(len(message))), message...)
+	return Hash256(data)
+}
+
+// SignMessage signs message with kp. The result is the base64 encoding of
+// the public key followed by the signature, so a verifier holding only the
+// address can recover and check the key.
+func SignMessage(kp *KeyPair, message []byte) (string, error) {
+	sig, err := kp.Sign(MessageHash(message))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(append([]byte{}, kp.PublicKey...), sig...)), nil
+}
+
+// VerifyMessage checks that signature, as produced by SignMessage, signs
+// message with the key behind address
+func VerifyMessage(address string, message []byte, signature string) error {
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(raw) != ed25519.PublicKeySize+ed25519.SignatureSize {
+		return ErrInvalidMessageSignature
+	}
+	publicKey, sig := raw[:ed25519.PublicKeySize], raw[ed25519.PublicKeySize:]
+	if DeriveAddress(publicKey) != address || !VerifySignature(publicKey, MessageHash(message), sig) {
+		return ErrMessageSignerMismatch
+	}
+	return nil
+}
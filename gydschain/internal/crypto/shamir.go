@@ -0,0 +1,502 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// SLIP-39 parameters
+const (
+	slip39RadixBits       = 10
+	slip39ChecksumWords   = 3
+	slip39HeaderWords     = 4 // identifier, flags, group and member parameters
+	slip39MinWords        = slip39HeaderWords + 13 + slip39ChecksumWords
+	slip39MaxShares       = 16
+	slip39DigestLength    = 4
+	slip39DigestIndex     = 254
+	slip39SecretIndex     = 255
+	slip39BaseIterations  = 10000
+	slip39RoundCount      = 4
+	slip39MinSecretLength = 16
+
+	// DefaultIterationExponent sets the PBKDF2 work for encrypting a
+	// shared secret to 10000 << 1 iterations
+	DefaultIterationExponent = 1
+)
+
+// Shamir share errors
+var (
+	ErrInvalidShare       = errors.New("invalid share mnemonic")
+	ErrShareChecksum      = errors.New("share mnemonic checksum failed")
+	ErrShareMismatch      = errors.New("shares belong to different backups")
+	ErrInsufficientShares = errors.New("not enough shares to recover the secret")
+	ErrShareDigest        = errors.New("shares do not recover a valid secret")
+	ErrInvalidSharePolicy = errors.New("invalid share thresholds")
+)
+
+// ShareGroup is a group of share holders of whom Threshold out of Count
+// must join to reconstruct the group's part of the secret
+type ShareGroup struct {
+	Threshold int `json:"threshold"`
+	Count     int `json:"count"`
+}
+
+// share is one decoded SLIP-39 share
+type share struct {
+	identifier        uint16
+	extendable        bool
+	iterationExponent int
+	groupIndex        int
+	groupThreshold    int
+	groupCount        int
+	index             int
+	memberThreshold   int
+	value             []byte
+}
+
+// SplitSecret splits secret into SLIP-39 share mnemonics. groupThreshold of
+// the groups must each reach their member threshold to recover it. The
+// secret is encrypted with passphrase first, so shares recombined with a
+// different passphrase yield a different, equally valid secret.
+func SplitSecret(secret []byte, passphrase string, groupThreshold int, groups []ShareGroup, iterationExponent int) ([][]string, error) {
+	if len(secret) < slip39MinSecretLength || len(secret)%2 != 0 {
+		return nil, errors.New("secret must be an even number of bytes, at least 16")
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) || len(groups) > slip39MaxShares {
+		return nil, ErrInvalidSharePolicy
+	}
+	for _, g := range groups {
+		if g.Threshold < 1 || g.Threshold > g.Count || g.Count > slip39MaxShares {
+			return nil, ErrInvalidSharePolicy
+		}
+		if g.Threshold == 1 && g.Count > 1 {
+			return nil, errors.New("a group with threshold 1 must have a single share")
+		}
+	}
+	if iterationExponent < 0 || iterationExponent > 15 {
+		return nil, errors.New("iteration exponent must be between 0 and 15")
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	identifier := binary.BigEndian.Uint16(id[:]) & 0x7fff
+	encrypted := slip39Encrypt(secret, []byte(passphrase), iterationExponent, identifier, true)
+
+	groupShares, err := shamirSplit(groupThreshold, len(groups), encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics := make([][]string, len(groups))
+	for i, g := range groups {
+		memberShares, err := shamirSplit(g.Threshold, g.Count, groupShares[i])
+		if err != nil {
+			return nil, err
+		}
+		for j, value := range memberShares {
+			s := &share{
+				identifier:        identifier,
+				extendable:        true,
+				iterationExponent: iterationExponent,
+				groupIndex:        i,
+				groupThreshold:    groupThreshold,
+				groupCount:        len(groups),
+				index:             j,
+				memberThreshold:   g.Threshold,
+				value:             value,
+			}
+			mnemonics[i] = append(mnemonics[i], s.mnemonic())
+		}
+	}
+	return mnemonics, nil
+}
+
+// CombineShares recovers the secret from enough SLIP-39 share mnemonics.
+// Extra shares beyond a group's threshold are ignored.
+func CombineShares(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, ErrInsufficientShares
+	}
+
+	groups := make(map[int][]*share)
+	var first *share
+	for _, m := range mnemonics {
+		s, err := decodeShare(m)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = s
+		} else if s.identifier != first.identifier || s.extendable != first.extendable ||
+			s.iterationExponent != first.iterationExponent || s.groupThreshold != first.groupThreshold ||
+			s.groupCount != first.groupCount || len(s.value) != len(first.value) {
+			return nil, ErrShareMismatch
+		}
+
+		members := groups[s.groupIndex]
+		if len(members) > 0 && members[0].memberThreshold != s.memberThreshold {
+			return nil, ErrShareMismatch
+		}
+		duplicate := false
+		for _, m := range members {
+			if m.index == s.index {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			groups[s.groupIndex] = append(members, s)
+		}
+	}
+
+	indexes := make([]int, 0, len(groups))
+	for index, members := range groups {
+		if len(members) >= members[0].memberThreshold {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) < first.groupThreshold {
+		return nil, ErrInsufficientShares
+	}
+	sort.Ints(indexes)
+
+	groupShares := make(map[int][]byte, first.groupThreshold)
+	for _, index := range indexes[:first.groupThreshold] {
+		members := groups[index]
+		points := make(map[int][]byte, members[0].memberThreshold)
+		for _, m := range members[:members[0].memberThreshold] {
+			points[m.index] = m.value
+		}
+		value, err := shamirRecover(members[0].memberThreshold, points)
+		if err != nil {
+			return nil, err
+		}
+		groupShares[index] = value
+	}
+
+	encrypted, err := shamirRecover(first.groupThreshold, groupShares)
+	if err != nil {
+		return nil, err
+	}
+	return slip39Decrypt(encrypted, []byte(passphrase), first.iterationExponent, first.identifier, first.extendable), nil
+}
+
+// SplitMnemonic splits a wallet mnemonic into share mnemonics for
+// trustees. The shares recover the mnemonic itself; a wallet passphrase is
+// still needed on top of it to derive the keys.
+func SplitMnemonic(mnemonic string, groupThreshold int, groups []ShareGroup) ([][]string, error) {
+	entropy, err := hex.DecodeString(strings.TrimSpace(mnemonic))
+	if err != nil {
+		return nil, errors.New("mnemonic is not a wallet mnemonic")
+	}
+	return SplitSecret(entropy, "", groupThreshold, groups, DefaultIterationExponent)
+}
+
+// CombineMnemonic recovers a wallet mnemonic from shares made by
+// SplitMnemonic
+func CombineMnemonic(shares []string) (string, error) {
+	entropy, err := CombineShares(shares, "")
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(entropy), nil
+}
+
+// mnemonic encodes the share as words: header, padded value, checksum
+func (s *share) mnemonic() string {
+	flags := uint64(s.identifier)<<5 | uint64(s.iterationExponent)
+	if s.extendable {
+		flags |= 1 << 4
+	}
+	data := intToWords(new(big.Int).SetUint64(flags), 2)
+	data = append(data, (s.groupIndex<<6|(s.groupThreshold-1)<<2|(s.groupCount-1)>>2)&0x3ff)
+	data = append(data, ((s.groupCount-1)&3)<<8|s.index<<4|(s.memberThreshold-1))
+
+	valueWords := (len(s.value)*8 + slip39RadixBits - 1) / slip39RadixBits
+	data = append(data, intToWords(new(big.Int).SetBytes(s.value), valueWords)...)
+	data = append(data, rs1024Checksum(customization(s.extendable), data)...)
+
+	words := make([]string, len(data))
+	for i, w := range data {
+		words[i] = slip39Words[w]
+	}
+	return strings.Join(words, " ")
+}
+
+// decodeShare parses and checks one share mnemonic
+func decodeShare(mnemonic string) (*share, error) {
+	fields := strings.Fields(strings.ToLower(mnemonic))
+	if len(fields) < slip39MinWords {
+		return nil, ErrInvalidShare
+	}
+	data := make([]int, len(fields))
+	for i, word := range fields {
+		index := wordIndex(word)
+		if index < 0 {
+			return nil, ErrInvalidShare
+		}
+		data[i] = index
+	}
+
+	padding := (slip39RadixBits * (len(data) - slip39HeaderWords - slip39ChecksumWords)) % 16
+	if padding > 8 {
+		return nil, ErrInvalidShare
+	}
+
+	flags := data[0]<<10 | data[1]
+	s := &share{
+		identifier:        uint16(flags >> 5),
+		extendable:        flags>>4&1 == 1,
+		iterationExponent: flags & 0xf,
+		groupIndex:        data[2] >> 6,
+		groupThreshold:    data[2]>>2&0xf + 1,
+		groupCount:        (data[2]&3)<<2 | data[3]>>8 + 1,
+		index:             data[3] >> 4 & 0xf,
+		memberThreshold:   data[3]&0xf + 1,
+	}
+	if rs1024Polymod(customization(s.extendable), data) != 1 {
+		return nil, ErrShareChecksum
+	}
+	if s.groupThreshold > s.groupCount {
+		return nil, ErrInvalidShare
+	}
+
+	valueData := data[slip39HeaderWords : len(data)-slip39ChecksumWords]
+	value := new(big.Int)
+	for _, w := range valueData {
+		value.Lsh(value, slip39RadixBits)
+		value.Or(value, big.NewInt(int64(w)))
+	}
+	length := (slip39RadixBits*len(valueData) - padding) / 8
+	if value.BitLen() > length*8 {
+		return nil, ErrInvalidShare
+	}
+	s.value = value.FillBytes(make([]byte, length))
+	return s, nil
+}
+
+// wordIndex looks a word up by its unique four-letter prefix
+func wordIndex(word string) int {
+	i := sort.SearchStrings(slip39Words[:], word)
+	if i < len(slip39Words) && slip39Words[i] == word {
+		return i
+	}
+	return -1
+}
+
+// intToWords renders v as count 10-bit words, most significant first
+func intToWords(v *big.Int, count int) []int {
+	words := make([]int, count)
+	mask := big.NewInt(1<<slip39RadixBits - 1)
+	rest := new(big.Int).Set(v)
+	for i := count - 1; i >= 0; i-- {
+		words[i] = int(new(big.Int).And(rest, mask).Int64())
+		rest.Rsh(rest, slip39RadixBits)
+	}
+	return words
+}
+
+func customization(extendable bool) string {
+	if extendable {
+		return "shamir_extendable"
+	}
+	return "shamir"
+}
+
+// rs1024Generator is the generator of SLIP-39's Reed-Solomon checksum code
+var rs1024Generator = [10]uint32{
+	0xE0E040, 0x1C1C080, 0x3838100, 0x7070200, 0xE0E0009,
+	0x1C0C2412, 0x38086C24, 0x3090FC48, 0x21B1F890, 0x3F3F120,
+}
+
+func rs1024Polymod(custom string, data []int) uint32 {
+	chk := uint32(1)
+	step := func(v uint32) {
+		b := chk >> 20
+		chk = (chk&0xFFFFF)<<10 ^ v
+		for i := 0; i < 10; i++ {
+			if b>>uint(i)&1 == 1 {
+				chk ^= rs1024Generator[i]
+			}
+		}
+	}
+	for _, c := range []byte(custom) {
+		step(uint32(c))
+	}
+	for _, v := range data {
+		step(uint32(v))
+	}
+	return chk
+}
+
+func rs1024Checksum(custom string, data []int) []int {
+	padded := append(append([]int{}, data...), 0, 0, 0)
+	chk := rs1024Polymod(custom, padded) ^ 1
+	return []int{int(chk >> 20 & 0x3ff), int(chk >> 10 & 0x3ff), int(chk & 0x3ff)}
+}
+
+// slip39Encrypt runs the four-round Feistel cipher keyed by passphrase
+func slip39Encrypt(secret, passphrase []byte, exponent int, identifier uint16, extendable bool) []byte {
+	half := len(secret) / 2
+	l, r := append([]byte{}, secret[:half]...), append([]byte{}, secret[half:]...)
+	salt := slip39Salt(identifier, extendable)
+	for i := 0; i < slip39RoundCount; i++ {
+		l, r = r, xorBytes(l, slip39Round(i, passphrase, exponent, salt, r))
+	}
+	return append(r, l...)
+}
+
+func slip39Decrypt(encrypted, passphrase []byte, exponent int, identifier uint16, extendable bool) []byte {
+	half := len(encrypted) / 2
+	l, r := append([]byte{}, encrypted[:half]...), append([]byte{}, encrypted[half:]...)
+	salt := slip39Salt(identifier, extendable)
+	for i := slip39RoundCount - 1; i >= 0; i-- {
+		l, r = r, xorBytes(l, slip39Round(i, passphrase, exponent, salt, r))
+	}
+	return append(r, l...)
+}
+
+func slip39Round(i int, passphrase []byte, exponent int, salt, r []byte) []byte {
+	password := append([]byte{byte(i)}, passphrase...)
+	iterations := (slip39BaseIterations << uint(exponent)) / slip39RoundCount
+	return pbkdf2.Key(password, append(append([]byte{}, salt...), r...), iterations, len(r), sha256.New)
+}
+
+// slip39Salt binds non-extendable backups to their identifier
+func slip39Salt(identifier uint16, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+	return append([]byte("shamir"), byte(identifier>>8), byte(identifier))
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+// GF(256) tables over the Rijndael polynomial with generator 3
+var gfExp, gfLog [256]int
+
+func init() {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = poly
+		gfLog[poly] = i
+		poly = poly<<1 ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11B
+		}
+	}
+}
+
+// shamirSplit splits secret into count shares, any threshold of which
+// recover it. Besides the secret at x=255, a digest of it sits at x=254 so
+// recovery with wrong shares is detected.
+func shamirSplit(threshold, count int, secret []byte) ([][]byte, error) {
+	if threshold == 1 {
+		shares := make([][]byte, count)
+		for i := range shares {
+			shares[i] = append([]byte{}, secret...)
+		}
+		return shares, nil
+	}
+
+	randomCount := threshold - 2
+	points := make(map[int][]byte, threshold)
+	shares := make([][]byte, count)
+	for i := 0; i < randomCount; i++ {
+		value := make([]byte, len(secret))
+		if _, err := rand.Read(value); err != nil {
+			return nil, err
+		}
+		points[i] = value
+		shares[i] = value
+	}
+
+	randomPart := make([]byte, len(secret)-slip39DigestLength)
+	if _, err := rand.Read(randomPart); err != nil {
+		return nil, err
+	}
+	points[slip39DigestIndex] = append(shareDigest(randomPart, secret), randomPart...)
+	points[slip39SecretIndex] = secret
+
+	for i := randomCount; i < count; i++ {
+		shares[i] = interpolate(points, i)
+	}
+	return shares, nil
+}
+
+// shamirRecover recovers a secret from threshold points and checks its digest
+func shamirRecover(threshold int, points map[int][]byte) ([]byte, error) {
+	if len(points) < threshold {
+		return nil, ErrInsufficientShares
+	}
+	if threshold == 1 {
+		for _, value := range points {
+			return value, nil
+		}
+	}
+
+	secret := interpolate(points, slip39SecretIndex)
+	digest := interpolate(points, slip39DigestIndex)
+	if !hmac.Equal(digest[:slip39DigestLength], shareDigest(digest[slip39DigestLength:], secret)) {
+		return nil, ErrShareDigest
+	}
+	return secret, nil
+}
+
+func shareDigest(randomPart, secret []byte) []byte {
+	mac := hmac.New(sha256.New, randomPart)
+	mac.Write(secret)
+	return mac.Sum(nil)[:slip39DigestLength]
+}
+
+// interpolate evaluates at x the polynomial through points using Lagrange
+// interpolation in GF(256)
+func interpolate(points map[int][]byte, x int) []byte {
+	if value, ok := points[x]; ok {
+		return append([]byte{}, value...)
+	}
+
+	logProd := 0
+	for px := range points {
+		logProd += gfLog[px^x]
+	}
+
+	var length int
+	for _, value := range points {
+		length = len(value)
+		break
+	}
+	result := make([]byte, length)
+	for px, value := range points {
+		logBasis := logProd - gfLog[px^x]
+		for other := range points {
+			if other != px {
+				logBasis -= gfLog[px^other]
+			}
+		}
+		logBasis = ((logBasis % 255) + 255) % 255
+		for i, v := range value {
+			if v != 0 {
+				result[i] ^= byte(gfExp[(gfLog[v]+logBasis)%255])
+			}
+		}
+	}
+	return result
+}
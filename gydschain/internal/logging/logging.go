@@ -0,0 +1,131 @@
+// Package logging provides leveled, structured logging shared by every
+// module. Module loggers are created once per package and follow the
+// output, level and format chosen later by Setup.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	level = new(slog.LevelVar)
+	root  atomic.Pointer[slog.Handler]
+)
+
+func init() {
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	root.Store(&h)
+}
+
+// Setup directs every logger to w at the named level (debug, info, warn or
+// error) in text or JSON format
+func Setup(levelName, format string, w io.Writer) error {
+	lvl, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		h = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	level.Set(lvl)
+	root.Store(&h)
+	slog.SetDefault(slog.New(&moduleHandler{}))
+	return nil
+}
+
+// ParseLevel parses a level name; empty means info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, name)
+}
+
+// SetLevel changes the level of every logger at runtime
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+// Module returns the logger for a module; its records carry module=name
+func Module(name string) *slog.Logger {
+	return slog.New(&moduleHandler{attrs: []slog.Attr{slog.String("module", name)}})
+}
+
+// moduleHandler forwards records to the handler installed by Setup at the
+// time they are logged, so loggers made before Setup are not left behind
+type moduleHandler struct {
+	attrs []slog.Attr
+	group string
+}
+
+func (h *moduleHandler) target() slog.Handler {
+	t := *root.Load()
+	if len(h.attrs) > 0 {
+		t = t.WithAttrs(h.attrs)
+	}
+	if h.group != "" {
+		t = t.WithGroup(h.group)
+	}
+	return t
+}
+
+func (h *moduleHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return lvl >= level.Level()
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.target().Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if h.group != "" {
+		// Attributes after a group belong inside it; fix the target now
+		return h.target().WithAttrs(attrs)
+	}
+	return &moduleHandler{attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	if h.group != "" {
+		return h.target().WithGroup(name)
+	}
+	return &moduleHandler{attrs: h.attrs, group: name}
+}
+
+// Logging errors
+var (
+	ErrUnknownLevel  = errors.New("unknown log level")
+	ErrUnknownFormat = errors.New("unknown log format")
+)
@@ -0,0 +1,249 @@
+package miner
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/p2p"
+	"github.com/gydschain/gydschain/internal/state"
+)
+
+// Pool access defaults
+const (
+	DefaultMaxConnsPerIP      = 8
+	DefaultMaxInvalidRatio    = 0.5 // invalid share fraction that gets a miner banned
+	DefaultInvalidShareSample = 20  // shares a miner submits before its ratio is judged
+	DefaultMinerBanDuration   = time.Hour
+)
+
+// Pool access errors
+var (
+	ErrMinerBanned      = errors.New("miner address is banned")
+	ErrTooManyConns     = errors.New("too many connections from this address")
+	ErrMinerNotFound    = errors.New("miner not connected")
+	ErrUnknownAccount   = errors.New("payout address has no account on chain")
+	ErrAdminUnavailable = errors.New("pool admin endpoints are disabled")
+)
+
+// AddressChecker vets a payout address a miner authorizes with
+type AddressChecker func(address string) error
+
+// RequireAccount accepts only addresses with an account in stateDB
+func RequireAccount(stateDB *state.StateDB) AddressChecker {
+	return func(address string) error {
+		if stateDB.GetAccount(address) == nil {
+			return ErrUnknownAccount
+		}
+		return nil
+	}
+}
+
+// SetAddressChecker adds a chain check to the address validation applied
+// when miners authorize
+func (p *Pool) SetAddressChecker(check AddressChecker) {
+	p.accessMu.Lock()
+	defer p.accessMu.Unlock()
+	p.checkAddress = check
+}
+
+// authorizeAddress checks a miner's payout address
+func (p *Pool) authorizeAddress(address string) error {
+	if err := crypto.ValidateAddress(address); err != nil {
+		return err
+	}
+	p.accessMu.Lock()
+	check := p.checkAddress
+	p.accessMu.Unlock()
+	if check != nil {
+		return check(address)
+	}
+	return nil
+}
+
+// admit registers a connection from ip, refusing banned addresses and
+// addresses over the per-IP connection limit
+func (p *Pool) admit(ip string) error {
+	if entry, banned := p.bans.Check(ip); banned {
+		return fmt.Errorf("%w: %s", ErrMinerBanned, entry.Reason)
+	}
+
+	limit := p.config.MaxConnsPerIP
+	if limit <= 0 {
+		limit = DefaultMaxConnsPerIP
+	}
+	p.accessMu.Lock()
+	defer p.accessMu.Unlock()
+	if p.conns[ip] >= limit {
+		return ErrTooManyConns
+	}
+	p.conns[ip]++
+	return nil
+}
+
+// release unregisters a connection admitted from ip
+func (p *Pool) release(ip string) {
+	p.accessMu.Lock()
+	defer p.accessMu.Unlock()
+	if p.conns[ip]--; p.conns[ip] <= 0 {
+		delete(p.conns, ip)
+	}
+}
+
+// checkInvalidShares bans a miner whose invalid shares exceed the allowed
+// fraction once it has submitted enough to judge; it reports a ban
+func (p *Pool) checkInvalidShares(miner *PoolMiner) bool {
+	maxRatio := p.config.MaxInvalidRatio
+	if maxRatio <= 0 {
+		maxRatio = DefaultMaxInvalidRatio
+	}
+
+	miner.mu.Lock()
+	total := miner.SharesValid + miner.SharesInvalid
+	invalid := miner.SharesInvalid
+	miner.mu.Unlock()
+	if total < DefaultInvalidShareSample || float64(invalid)/float64(total) <= maxRatio {
+		return false
+	}
+
+	reason := fmt.Sprintf("%d of %d shares invalid", invalid, total)
+	p.BanMiner(miner.ID, reason, 0)
+	return true
+}
+
+// Kick disconnects a miner
+func (p *Pool) Kick(id string) error {
+	p.minersMu.RLock()
+	miner, exists := p.miners[id]
+	p.minersMu.RUnlock()
+	if !exists {
+		return ErrMinerNotFound
+	}
+	miner.disconnect()
+	return nil
+}
+
+// BanMiner bans the IP a miner connects from for duration
+// (DefaultMinerBanDuration if zero) and disconnects every miner using it
+func (p *Pool) BanMiner(id, reason string, duration time.Duration) (*p2p.BanEntry, error) {
+	p.minersMu.RLock()
+	miner, exists := p.miners[id]
+	p.minersMu.RUnlock()
+	if !exists {
+		return nil, ErrMinerNotFound
+	}
+	if duration <= 0 {
+		duration = DefaultMinerBanDuration
+	}
+
+	entry := p.bans.Ban(miner.IP, id, reason, duration)
+	logger.Warn("miner banned", "miner", id, "ip", miner.IP, "reason", reason)
+
+	p.minersMu.RLock()
+	for _, m := range p.miners {
+		if m.IP == miner.IP {
+			m.disconnect()
+		}
+	}
+	p.minersMu.RUnlock()
+	return entry, nil
+}
+
+// Unban lifts the ban on an IP
+func (p *Pool) Unban(ip string) {
+	p.bans.Unban(ip)
+}
+
+// Bans returns the banned miner IPs
+func (p *Pool) Bans() []*p2p.BanEntry {
+	return p.bans.Entries()
+}
+
+// disconnect closes the miner's connection, ending its session
+func (m *PoolMiner) disconnect() {
+	if m.stratum != nil {
+		m.stratum.conn.Close()
+	} else if m.Conn != nil {
+		m.Conn.Close()
+	}
+}
+
+// remoteIP returns the host part of a remote address
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// requireAdmin guards admin endpoints with the pool's admin token
+func (p *Pool) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if p.config.AdminToken == "" {
+			http.Error(w, ErrAdminUnavailable.Error(), http.StatusForbidden)
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(p.config.AdminToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleKick disconnects a miner
+func (p *Pool) handleKick(w http.ResponseWriter, r *http.Request) {
+	if err := p.Kick(mux.Vars(r)["id"]); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBan bans a miner's IP; the body may give a reason and a duration
+// in seconds
+func (p *Pool) handleBan(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason   string `json:"reason"`
+		Duration int64  `json:"duration"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "banned by pool operator"
+	}
+
+	entry, err := p.BanMiner(mux.Vars(r)["id"], req.Reason, time.Duration(req.Duration)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// handleBans lists banned miner IPs
+func (p *Pool) handleBans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.Bans())
+}
+
+// handleUnban lifts the ban on an IP
+func (p *Pool) handleUnban(w http.ResponseWriter, r *http.Request) {
+	p.Unban(mux.Vars(r)["ip"])
+	w.WriteHeader(http.StatusNoContent)
+}
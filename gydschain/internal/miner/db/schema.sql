@@ -0,0 +1,43 @@
+-- GYDS Chain Mining Pool Database Schema
+-- PostgreSQL
+
+-- Valid shares, the window PPLNS pays a found block over
+CREATE TABLE IF NOT EXISTS pool_shares (
+    id BIGSERIAL PRIMARY KEY,
+    address VARCHAR(66) NOT NULL,
+    difficulty BIGINT NOT NULL,
+    height BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_shares_address ON pool_shares(address);
+
+-- Blocks found by the pool and how their reward was shared out
+CREATE TABLE IF NOT EXISTS pool_blocks (
+    height BIGINT PRIMARY KEY,
+    hash VARCHAR(66) NOT NULL,
+    found_by VARCHAR(66) NOT NULL,
+    reward VARCHAR(78) NOT NULL,
+    fee VARCHAR(78) NOT NULL,
+    scheme VARCHAR(10) NOT NULL CHECK (scheme IN ('pplns', 'pps')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Credited but unpaid earnings and lifetime payouts per miner address
+CREATE TABLE IF NOT EXISTS pool_balances (
+    address VARCHAR(66) PRIMARY KEY,
+    pending VARCHAR(78) NOT NULL DEFAULT '0',
+    paid VARCHAR(78) NOT NULL DEFAULT '0',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Payout transactions sent to miners
+CREATE TABLE IF NOT EXISTS pool_payouts (
+    id BIGSERIAL PRIMARY KEY,
+    address VARCHAR(66) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_payouts_address ON pool_payouts(address);
@@ -0,0 +1,336 @@
+package miner
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Payout schemes
+const (
+	SchemePPLNS = "pplns" // a found block pays the last N difficulty of shares
+	SchemePPS   = "pps"   // every share pays its expected value at once
+)
+
+// Payout defaults
+const (
+	DefaultPPLNSWindow    = 2.0 // PPLNS window in multiples of the block difficulty
+	DefaultPayoutInterval = 10 * time.Minute
+)
+
+// Payout errors
+var (
+	ErrUnknownScheme = errors.New("payout scheme must be pplns or pps")
+	ErrNoPayer       = errors.New("no payout sender configured")
+)
+
+// PayoutSender sends amount to address in a transaction and returns its hash
+type PayoutSender func(address string, amount *big.Int) (string, error)
+
+// Balance is a miner's unpaid and paid earnings
+type Balance struct {
+	Address string    `json:"address"`
+	Pending *util.Big `json:"pending"`
+	Paid    *util.Big `json:"paid"`
+}
+
+// Payout is a payout transaction sent to a miner
+type Payout struct {
+	Address string    `json:"address"`
+	Amount  *util.Big `json:"amount"`
+	TxHash  string    `json:"tx_hash"`
+}
+
+// ShareLedger persists pool shares and credits miners under the PPLNS or
+// PPS scheme. Amounts are stored as decimal strings and summed in Go.
+type ShareLedger struct {
+	db        *sql.DB
+	scheme    string
+	window    float64  // PPLNS window in multiples of the block difficulty
+	reward    *big.Int // block reward shared among miners
+	fee       float64  // pool fee in percent
+	threshold *big.Int // pending balance that triggers a payout
+}
+
+// NewShareLedger creates a share ledger from the pool config
+func NewShareLedger(db *sql.DB, config PoolConfig) (*ShareLedger, error) {
+	scheme := config.PayoutScheme
+	if scheme == "" {
+		scheme = SchemePPLNS
+	}
+	if scheme != SchemePPLNS && scheme != SchemePPS {
+		return nil, ErrUnknownScheme
+	}
+	window := config.PPLNSWindow
+	if window <= 0 {
+		window = DefaultPPLNSWindow
+	}
+	reward, err := util.ParseBig(config.BlockReward)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := util.ParseBig(config.PayoutThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &ShareLedger{
+		db:        db,
+		scheme:    scheme,
+		window:    window,
+		reward:    reward,
+		fee:       config.PoolFee,
+		threshold: threshold,
+	}, nil
+}
+
+// Scheme returns the payout scheme in use
+func (l *ShareLedger) Scheme() string {
+	return l.scheme
+}
+
+// RecordShare stores a valid share. Under PPS it is credited right away
+// with its share of the block reward: shareDiff / blockDiff of it.
+func (l *ShareLedger) RecordShare(address string, shareDiff, blockDiff, height uint64) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"INSERT INTO pool_shares (address, difficulty, height) VALUES ($1, $2, $3)",
+		address, int64(shareDiff), int64(height),
+	); err != nil {
+		return err
+	}
+	if l.scheme == SchemePPS && blockDiff > 0 {
+		amount := new(big.Int).Mul(l.minersReward(), new(big.Int).SetUint64(shareDiff))
+		amount.Div(amount, new(big.Int).SetUint64(blockDiff))
+		if err := credit(tx, map[string]*big.Int{address: amount}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RecordBlock stores a block found by the pool. Under PPLNS its reward, less
+// the pool fee, is split over the latest shares adding up to the window,
+// in proportion to their difficulty. It returns the credited amounts.
+func (l *ShareLedger) RecordBlock(height uint64, hash, foundBy string, blockDiff uint64) (map[string]*big.Int, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	fee := new(big.Int).Sub(l.reward, l.minersReward())
+	if _, err := tx.Exec(`
+		INSERT INTO pool_blocks (height, hash, found_by, reward, fee, scheme)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, int64(height), hash, foundBy, l.reward.String(), fee.String(), l.scheme); err != nil {
+		return nil, err
+	}
+
+	var credits map[string]*big.Int
+	if l.scheme == SchemePPLNS {
+		weights, err := l.pplnsWeights(tx, uint64(float64(blockDiff)*l.window))
+		if err != nil {
+			return nil, err
+		}
+		credits = split(l.minersReward(), weights)
+		if err := credit(tx, credits); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return credits, nil
+}
+
+// pplnsWeights sums share difficulty per address over the newest shares
+// until their total reaches size
+func (l *ShareLedger) pplnsWeights(tx *sql.Tx, size uint64) (map[string]uint64, error) {
+	rows, err := tx.Query("SELECT address, difficulty FROM pool_shares ORDER BY id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[string]uint64)
+	var total uint64
+	for (size == 0 || total < size) && rows.Next() {
+		var address string
+		var difficulty int64
+		if err := rows.Scan(&address, &difficulty); err != nil {
+			return nil, err
+		}
+		weights[address] += uint64(difficulty)
+		total += uint64(difficulty)
+	}
+	return weights, rows.Err()
+}
+
+// minersReward is the block reward less the pool fee
+func (l *ShareLedger) minersReward() *big.Int {
+	basisPoints := int64(l.fee * 100)
+	reward := new(big.Int).Mul(l.reward, big.NewInt(10000-basisPoints))
+	return reward.Div(reward, big.NewInt(10000))
+}
+
+// split divides amount by weight; rounding dust stays with the pool
+func split(amount *big.Int, weights map[string]uint64) map[string]*big.Int {
+	var total uint64
+	for _, w := range weights {
+		total += w
+	}
+	shares := make(map[string]*big.Int, len(weights))
+	if total == 0 {
+		return shares
+	}
+	for address, w := range weights {
+		share := new(big.Int).Mul(amount, new(big.Int).SetUint64(w))
+		shares[address] = share.Div(share, new(big.Int).SetUint64(total))
+	}
+	return shares
+}
+
+// credit adds amounts to the miners' pending balances
+func credit(tx *sql.Tx, amounts map[string]*big.Int) error {
+	for address, amount := range amounts {
+		if amount.Sign() <= 0 {
+			continue
+		}
+		balance, err := loadBalance(tx, address)
+		if err != nil {
+			return err
+		}
+		pending := new(big.Int).Add(balance.Pending.Int(), amount)
+		if err := saveBalance(tx, address, pending, balance.Paid.Int()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBalance reads an address's balance; unknown addresses have none
+func loadBalance(tx *sql.Tx, address string) (*Balance, error) {
+	b := &Balance{Address: address, Pending: new(util.Big), Paid: new(util.Big)}
+	err := tx.QueryRow(
+		"SELECT pending, paid FROM pool_balances WHERE address = $1", address,
+	).Scan(b.Pending, b.Paid)
+	if err == sql.ErrNoRows {
+		return b, nil
+	}
+	return b, err
+}
+
+// saveBalance writes an address's balance
+func saveBalance(tx *sql.Tx, address string, pending, paid *big.Int) error {
+	_, err := tx.Exec(`
+		INSERT INTO pool_balances (address, pending, paid, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (address) DO UPDATE
+		SET pending = EXCLUDED.pending, paid = EXCLUDED.paid, updated_at = NOW()
+	`, address, pending.String(), paid.String())
+	return err
+}
+
+// Balance returns a miner's pending and paid earnings
+func (l *ShareLedger) Balance(address string) (*Balance, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return loadBalance(tx, address)
+}
+
+// PayDue sends every pending balance at or above the payout threshold.
+// A balance is debited only once its transaction is sent, so a failed
+// payout is retried on the next run.
+func (l *ShareLedger) PayDue(send PayoutSender) ([]*Payout, error) {
+	if send == nil {
+		return nil, ErrNoPayer
+	}
+
+	rows, err := l.db.Query("SELECT address, pending FROM pool_balances")
+	if err != nil {
+		return nil, err
+	}
+	due := make(map[string]*big.Int)
+	for rows.Next() {
+		var address string
+		pending := new(util.Big)
+		if err := rows.Scan(&address, pending); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if amount := pending.Int(); amount.Sign() > 0 && amount.Cmp(l.threshold) >= 0 {
+			due[address] = amount
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(due))
+	for address := range due {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	var payouts []*Payout
+	var errs []error
+	for _, address := range addresses {
+		payout, err := l.pay(address, due[address], send)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		payouts = append(payouts, payout)
+	}
+	return payouts, errors.Join(errs...)
+}
+
+// pay sends amount to address and moves it from pending to paid
+func (l *ShareLedger) pay(address string, amount *big.Int, send PayoutSender) (*Payout, error) {
+	txHash, err := send(address, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	balance, err := loadBalance(tx, address)
+	if err != nil {
+		return nil, err
+	}
+	pending := new(big.Int).Sub(balance.Pending.Int(), amount)
+	if pending.Sign() < 0 {
+		pending.SetUint64(0)
+	}
+	paid := new(big.Int).Add(balance.Paid.Int(), amount)
+	if err := saveBalance(tx, address, pending, paid); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO pool_payouts (address, amount, tx_hash) VALUES ($1, $2, $3)",
+		address, amount.String(), txHash,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &Payout{Address: address, Amount: (*util.Big)(amount), TxHash: txHash}, nil
+}
@@ -3,14 +3,21 @@ package miner
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+
+	"github.com/gydschain/gydschain/internal/logging"
+	"github.com/gydschain/gydschain/internal/p2p"
 )
 
+// logger is the miner module logger
+var logger = logging.Module("miner")
+
 // Pool represents a mining pool server
 type Pool struct {
 	addr     string
@@ -36,6 +43,22 @@ type Pool struct {
 	newJobs  chan *Job
 	shares   chan *Share
 	stop     chan struct{}
+	
+	// Stratum over TCP
+	stratumListener net.Listener
+	onBlock         func(*BlockSubmission)
+	stratumMu       sync.Mutex
+	extraNonce      uint32 // last extranonce1 assigned
+	
+	// Share accounting and payouts
+	ledger   *ShareLedger
+	payer    PayoutSender
+	
+	// Access control
+	bans         *p2p.BanList
+	conns        map[string]int // open connections per IP
+	checkAddress AddressChecker
+	accessMu     sync.Mutex
 }
 
 // PoolConfig contains pool configuration
@@ -47,12 +70,19 @@ type PoolConfig struct {
 	PayoutThreshold  string  `json:"payout_threshold"`
 	PoolFee          float64 `json:"pool_fee"`          // Percentage
 	BlockReward      string  `json:"block_reward"`
+	StratumAddr      string  `json:"stratum_addr"`      // TCP Stratum listen address; empty disables it
+	PayoutScheme     string  `json:"payout_scheme"`     // pplns (default) or pps
+	PPLNSWindow      float64 `json:"pplns_window"`      // PPLNS window in multiples of the block difficulty
+	MaxConnsPerIP    int     `json:"max_conns_per_ip"`
+	MaxInvalidRatio  float64 `json:"max_invalid_ratio"` // invalid share fraction that gets a miner banned
+	AdminToken       string  `json:"admin_token"`       // bearer token for kick/ban endpoints; empty disables them
 }
 
 // PoolMiner represents a connected miner
 type PoolMiner struct {
 	ID            string
 	Address       string
+	IP            string
 	Conn          *websocket.Conn
 	Difficulty    uint64
 	Hashrate      float64
@@ -60,6 +90,7 @@ type PoolMiner struct {
 	SharesInvalid uint64
 	LastShare     time.Time
 	ConnectedAt   time.Time
+	stratum       *stratumConn // set for miners connected over TCP
 	mu            sync.Mutex
 }
 
@@ -94,6 +125,8 @@ func NewPool(addr string, config PoolConfig) *Pool {
 		newJobs:  make(chan *Job, 10),
 		shares:   make(chan *Share, 1000),
 		stop:     make(chan struct{}),
+		bans:     p2p.NewBanList(),
+		conns:    make(map[string]int),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -109,6 +142,11 @@ func (p *Pool) setupRoutes() {
 	p.router.HandleFunc("/", p.handleMiner)
 	p.router.HandleFunc("/stats", p.handleStats).Methods("GET")
 	p.router.HandleFunc("/miners", p.handleMiners).Methods("GET")
+	p.router.HandleFunc("/balances/{address}", p.handleBalance).Methods("GET")
+	p.router.HandleFunc("/miners/{id}", p.requireAdmin(p.handleKick)).Methods("DELETE")
+	p.router.HandleFunc("/miners/{id}/ban", p.requireAdmin(p.handleBan)).Methods("POST")
+	p.router.HandleFunc("/bans", p.requireAdmin(p.handleBans)).Methods("GET")
+	p.router.HandleFunc("/bans/{ip}", p.requireAdmin(p.handleUnban)).Methods("DELETE")
 }
 
 // Start starts the pool server
@@ -119,18 +157,75 @@ func (p *Pool) Start() error {
 	// Start vardiff adjuster
 	go p.adjustDifficulty()
 	
+	// Start paying out miners' balances
+	if p.ledger != nil {
+		go p.payoutLoop()
+	}
+	
+	// Start the TCP Stratum listener
+	if p.config.StratumAddr != "" {
+		if err := p.StartStratum(p.config.StratumAddr); err != nil {
+			return err
+		}
+	}
+	
 	// Start HTTP server
-	fmt.Printf("Mining pool starting on %s\n", p.addr)
+	logger.Info("mining pool starting", "addr", p.addr)
 	return http.ListenAndServe(p.addr, p.router)
 }
 
 // Stop stops the pool server
 func (p *Pool) Stop() {
 	close(p.stop)
+	
+	p.stratumMu.Lock()
+	if p.stratumListener != nil {
+		p.stratumListener.Close()
+	}
+	p.stratumMu.Unlock()
+}
+
+// SetLedger credits valid shares and found blocks to ledger and pays due
+// balances with send; call before Start
+func (p *Pool) SetLedger(ledger *ShareLedger, send PayoutSender) {
+	p.ledger = ledger
+	p.payer = send
+}
+
+// payoutLoop pays balances over the payout threshold periodically
+func (p *Pool) payoutLoop() {
+	ticker := time.NewTicker(DefaultPayoutInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C:
+			payouts, err := p.ledger.PayDue(p.payer)
+			for _, payout := range payouts {
+				logger.Info("pool payout sent", "address", payout.Address, "amount", payout.Amount.Int().String(), "tx", payout.TxHash)
+			}
+			if err != nil {
+				logger.Warn("pool payouts failed", "err", err)
+			}
+		case <-p.stop:
+			return
+		}
+	}
 }
 
 // handleMiner handles WebSocket connections from miners
 func (p *Pool) handleMiner(w http.ResponseWriter, r *http.Request) {
+	ip := remoteIP(r.RemoteAddr)
+	if err := p.admit(ip); err != nil {
+		status := http.StatusForbidden
+		if err == ErrTooManyConns {
+			status = http.StatusTooManyRequests
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	defer p.release(ip)
+	
 	conn, err := p.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
@@ -139,6 +234,7 @@ func (p *Pool) handleMiner(w http.ResponseWriter, r *http.Request) {
 	
 	miner := &PoolMiner{
 		ID:          generateMinerID(),
+		IP:          ip,
 		Conn:        conn,
 		Difficulty:  p.config.MinDifficulty,
 		ConnectedAt: time.Now(),
@@ -197,9 +293,21 @@ func (p *Pool) handleAuthorize(miner *PoolMiner, msg StratumMessage) {
 	var params []string
 	json.Unmarshal(msg.Params, &params)
 	
+	var address string
 	if len(params) > 0 {
-		miner.Address = params[0]
+		address = params[0]
+	}
+	if err := p.authorizeAddress(address); err != nil {
+		miner.Conn.WriteJSON(map[string]interface{}{
+			"id":     msg.ID,
+			"result": nil,
+			"error":  []interface{}{stratumErrUnauthorized, err.Error(), nil},
+		})
+		return
 	}
+	miner.mu.Lock()
+	miner.Address = address
+	miner.mu.Unlock()
 	
 	response := map[string]interface{}{
 		"id":     msg.ID,
@@ -211,6 +319,18 @@ func (p *Pool) handleAuthorize(miner *PoolMiner, msg StratumMessage) {
 
 // handleSubmit handles share submission
 func (p *Pool) handleSubmit(miner *PoolMiner, msg StratumMessage) {
+	miner.mu.Lock()
+	authorized := miner.Address != ""
+	miner.mu.Unlock()
+	if !authorized {
+		miner.Conn.WriteJSON(map[string]interface{}{
+			"id":     msg.ID,
+			"result": nil,
+			"error":  []interface{}{stratumErrUnauthorized, "unauthorized worker", nil},
+		})
+		return
+	}
+	
 	var params []interface{}
 	json.Unmarshal(msg.Params, &params)
 	
@@ -249,6 +369,10 @@ func (p *Pool) sendJob(miner *PoolMiner) {
 	if job == nil {
 		return
 	}
+	if miner.stratum != nil {
+		miner.stratum.notify(job, true)
+		return
+	}
 	
 	notification := map[string]interface{}{
 		"id":     nil,
@@ -383,6 +507,22 @@ func (p *Pool) handleMiners(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(miners)
 }
 
+// handleBalance returns a miner's pending and paid earnings
+func (p *Pool) handleBalance(w http.ResponseWriter, r *http.Request) {
+	if p.ledger == nil {
+		http.Error(w, "share accounting not enabled", http.StatusNotFound)
+		return
+	}
+	balance, err := p.ledger.Balance(mux.Vars(r)["address"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
 // generateMinerID generates a unique miner ID
 func generateMinerID() string {
 	return fmt.Sprintf("miner_%d", time.Now().UnixNano())
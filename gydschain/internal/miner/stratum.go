@@ -0,0 +1,385 @@
+package miner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// Stratum limits
+const (
+	ExtraNonce1Size = 4 // bytes the pool assigns to each connection
+	ExtraNonce2Size = 4 // bytes each miner rolls itself
+
+	maxStratumLine   = 16 * 1024
+	stratumIdleLimit = 10 * time.Minute
+)
+
+// Stratum error codes, as understood by common mining software
+const (
+	stratumErrOther         = 20
+	stratumErrJobNotFound   = 21
+	stratumErrDuplicate     = 22
+	stratumErrLowDiff       = 23
+	stratumErrUnauthorized  = 24
+	stratumErrNotSubscribed = 25
+)
+
+// stratumConn is a miner connected over TCP. Messages are newline-delimited
+// JSON-RPC as in the Stratum protocol spoken by off-the-shelf miners.
+type stratumConn struct {
+	conn        net.Conn
+	wmu         sync.Mutex
+	extraNonce1 []byte
+	subscribed  bool
+	authorized  bool
+	seenJob     string
+	seen        map[string]bool // shares submitted for seenJob
+}
+
+// send writes one message followed by a newline
+func (c *stratumConn) send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err = c.conn.Write(append(data, '\n'))
+	return err
+}
+
+// reply answers request id with a result or a Stratum error triple
+func (c *stratumConn) reply(id interface{}, result interface{}, code int, msg string) {
+	var stratumErr interface{}
+	if code != 0 {
+		stratumErr = []interface{}{code, msg, nil}
+		result = nil
+	}
+	c.send(map[string]interface{}{"id": id, "result": result, "error": stratumErr})
+}
+
+// StartStratum listens for miners speaking Stratum over plain TCP at addr.
+// It returns once listening; connections are served until Stop.
+func (p *Pool) StartStratum(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	p.stratumMu.Lock()
+	p.stratumListener = l
+	p.stratumMu.Unlock()
+
+	logger.Info("stratum listener starting", "addr", l.Addr().String())
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				select {
+				case <-p.stop:
+				default:
+					logger.Warn("stratum accept failed", "err", err)
+				}
+				return
+			}
+			go p.serveStratum(conn)
+		}
+	}()
+	return nil
+}
+
+// StratumAddr returns the address of the Stratum listener, or nil
+func (p *Pool) StratumAddr() net.Addr {
+	p.stratumMu.Lock()
+	defer p.stratumMu.Unlock()
+	if p.stratumListener == nil {
+		return nil
+	}
+	return p.stratumListener.Addr()
+}
+
+// OnBlock registers a callback for shares that meet the block target
+func (p *Pool) OnBlock(fn func(block *BlockSubmission)) {
+	p.stratumMu.Lock()
+	defer p.stratumMu.Unlock()
+	p.onBlock = fn
+}
+
+// serveStratum handles one TCP miner until it disconnects
+func (p *Pool) serveStratum(conn net.Conn) {
+	defer conn.Close()
+
+	ip := remoteIP(conn.RemoteAddr().String())
+	if err := p.admit(ip); err != nil {
+		return
+	}
+	defer p.release(ip)
+
+	extraNonce1 := make([]byte, ExtraNonce1Size)
+	binary.BigEndian.PutUint32(extraNonce1, atomic.AddUint32(&p.extraNonce, 1))
+	sc := &stratumConn{conn: conn, extraNonce1: extraNonce1}
+
+	miner := &PoolMiner{
+		ID:          generateMinerID(),
+		IP:          ip,
+		Difficulty:  p.config.MinDifficulty,
+		ConnectedAt: time.Now(),
+		stratum:     sc,
+	}
+	p.minersMu.Lock()
+	p.miners[miner.ID] = miner
+	p.minersMu.Unlock()
+	defer func() {
+		p.minersMu.Lock()
+		delete(p.miners, miner.ID)
+		p.minersMu.Unlock()
+	}()
+
+	reader := bufio.NewReaderSize(conn, maxStratumLine)
+	for {
+		conn.SetReadDeadline(time.Now().Add(stratumIdleLimit))
+		line, err := reader.ReadSlice('\n')
+		if err != nil {
+			if err == bufio.ErrBufferFull {
+				logger.Warn("stratum message too long", "miner", miner.ID)
+			}
+			return
+		}
+		var msg StratumMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			sc.reply(nil, nil, stratumErrOther, "parse error")
+			continue
+		}
+
+		switch msg.Method {
+		case "mining.subscribe":
+			p.stratumSubscribe(miner, sc, msg)
+		case "mining.authorize":
+			p.stratumAuthorize(miner, sc, msg)
+		case "mining.submit":
+			p.stratumSubmit(miner, sc, msg)
+		case "mining.extranonce.subscribe":
+			sc.reply(msg.ID, true, 0, "")
+		default:
+			sc.reply(msg.ID, nil, stratumErrOther, "unknown method "+msg.Method)
+		}
+	}
+}
+
+// stratumSubscribe assigns the connection's extranonce. The result lists
+// the subscriptions, extranonce1 and the size of extranonce2.
+func (p *Pool) stratumSubscribe(miner *PoolMiner, sc *stratumConn, msg StratumMessage) {
+	sc.subscribed = true
+	sc.reply(msg.ID, []interface{}{
+		[]interface{}{
+			[]interface{}{"mining.set_difficulty", miner.ID},
+			[]interface{}{"mining.notify", miner.ID},
+		},
+		hex.EncodeToString(sc.extraNonce1),
+		ExtraNonce2Size,
+	}, 0, "")
+}
+
+// stratumAuthorize records the worker's payout address, which is the
+// worker name up to the first dot, then sends the difficulty and work
+func (p *Pool) stratumAuthorize(miner *PoolMiner, sc *stratumConn, msg StratumMessage) {
+	var params []string
+	json.Unmarshal(msg.Params, &params)
+	if len(params) == 0 || params[0] == "" {
+		sc.reply(msg.ID, nil, stratumErrUnauthorized, "worker name required")
+		return
+	}
+	address := strings.SplitN(params[0], ".", 2)[0]
+	if err := p.authorizeAddress(address); err != nil {
+		sc.reply(msg.ID, nil, stratumErrUnauthorized, err.Error())
+		return
+	}
+
+	miner.mu.Lock()
+	miner.Address = address
+	difficulty := miner.Difficulty
+	miner.mu.Unlock()
+	sc.authorized = true
+	sc.reply(msg.ID, true, 0, "")
+
+	sc.send(map[string]interface{}{
+		"id":     nil,
+		"method": "mining.set_difficulty",
+		"params": []interface{}{difficulty},
+	})
+	p.sendJob(miner)
+}
+
+// notify sends job in the standard nine-parameter mining.notify layout.
+// The header up to its time and nonce travels as coinbase1; there is no
+// coinbase2 or merkle branch. A share hashes that prefix, ntime, nonce,
+// extranonce1 and extranonce2 in that order.
+func (sc *stratumConn) notify(job *Job, clean bool) error {
+	prefix := job.BlockHeader
+	if len(prefix) >= 16 {
+		prefix = prefix[:len(prefix)-16] // time and nonce are filled in by the miner
+	}
+	return sc.send(map[string]interface{}{
+		"id":     nil,
+		"method": "mining.notify",
+		"params": []interface{}{
+			job.ID,
+			hex.EncodeToString(job.PrevHash),
+			hex.EncodeToString(prefix),
+			"",
+			[]string{},
+			"00000001",
+			hex.EncodeToString(job.Target),
+			fmt.Sprintf("%016x", job.Timestamp),
+			clean,
+		},
+	})
+}
+
+// stratumSubmit checks a share: params are worker, job ID, extranonce2,
+// ntime and nonce, the last three in hex
+func (p *Pool) stratumSubmit(miner *PoolMiner, sc *stratumConn, msg StratumMessage) {
+	if !sc.subscribed {
+		sc.reply(msg.ID, nil, stratumErrNotSubscribed, "not subscribed")
+		return
+	}
+	if !sc.authorized {
+		sc.reply(msg.ID, nil, stratumErrUnauthorized, "unauthorized worker")
+		return
+	}
+
+	var params []string
+	if err := json.Unmarshal(msg.Params, &params); err != nil || len(params) < 5 {
+		sc.reply(msg.ID, nil, stratumErrOther, "expected worker, job_id, extranonce2, ntime and nonce")
+		return
+	}
+	extraNonce2, err1 := hex.DecodeString(params[2])
+	ntime, err2 := strconv.ParseUint(params[3], 16, 64)
+	nonce, err3 := strconv.ParseUint(params[4], 16, 64)
+	if err := errors.Join(err1, err2, err3); err != nil || len(extraNonce2) != ExtraNonce2Size {
+		sc.reply(msg.ID, nil, stratumErrOther, "malformed share")
+		return
+	}
+
+	p.jobMu.RLock()
+	job := p.currentJob
+	p.jobMu.RUnlock()
+	if job == nil || job.ID != params[1] {
+		p.recordShare(miner, false)
+		sc.reply(msg.ID, nil, stratumErrJobNotFound, "job not found")
+		return
+	}
+
+	key := params[2] + "/" + params[3] + "/" + params[4]
+	if sc.seenJob != job.ID {
+		sc.seenJob, sc.seen = job.ID, make(map[string]bool)
+	}
+	if sc.seen[key] {
+		p.recordShare(miner, false)
+		sc.reply(msg.ID, nil, stratumErrDuplicate, "duplicate share")
+		return
+	}
+	sc.seen[key] = true
+
+	extraNonce := append(append([]byte{}, sc.extraNonce1...), extraNonce2...)
+	hash := shareHash(job, extraNonce, ntime, nonce)
+
+	miner.mu.Lock()
+	shareTarget := difficultyToTarget(miner.Difficulty)
+	miner.mu.Unlock()
+	if !compareHash(hash, shareTarget) {
+		p.recordShare(miner, false)
+		sc.reply(msg.ID, nil, stratumErrLowDiff, "low difficulty share")
+		return
+	}
+	p.recordShare(miner, true)
+	sc.reply(msg.ID, true, 0, "")
+	if p.ledger != nil {
+		miner.mu.Lock()
+		address, shareDiff := miner.Address, miner.Difficulty
+		miner.mu.Unlock()
+		if err := p.ledger.RecordShare(address, shareDiff, job.Difficulty, job.Height); err != nil {
+			logger.Warn("recording share failed", "miner", miner.ID, "err", err)
+		}
+	}
+
+	if compareHash(hash, job.Target) {
+		p.statsMu.Lock()
+		p.stats.BlocksFound++
+		p.stats.LastBlockTime = uint64(time.Now().Unix())
+		p.statsMu.Unlock()
+
+		if p.ledger != nil {
+			miner.mu.Lock()
+			address := miner.Address
+			miner.mu.Unlock()
+			if _, err := p.ledger.RecordBlock(job.Height, hex.EncodeToString(hash), address, job.Difficulty); err != nil {
+				logger.Warn("recording block reward failed", "height", job.Height, "err", err)
+			}
+		}
+
+		p.stratumMu.Lock()
+		onBlock := p.onBlock
+		p.stratumMu.Unlock()
+		if onBlock != nil {
+			go onBlock(&BlockSubmission{
+				JobID:     job.ID,
+				Height:    job.Height,
+				Nonce:     nonce,
+				Timestamp: ntime,
+				Hash:      hash,
+				MinerID:   miner.ID,
+				FoundAt:   time.Now(),
+			})
+		}
+	}
+}
+
+// recordShare counts a share for the miner and the pool, banning the
+// miner when too many of its shares are invalid
+func (p *Pool) recordShare(miner *PoolMiner, valid bool) {
+	miner.mu.Lock()
+	if valid {
+		miner.SharesValid++
+		miner.LastShare = time.Now()
+	} else {
+		miner.SharesInvalid++
+	}
+	miner.mu.Unlock()
+
+	p.statsMu.Lock()
+	if valid {
+		p.stats.SharesValid++
+	} else {
+		p.stats.SharesInvalid++
+	}
+	p.statsMu.Unlock()
+
+	if !valid {
+		p.checkInvalidShares(miner)
+	}
+}
+
+// shareHash hashes the job's header with the miner's time and nonce in the
+// trailing placeholders and the extranonce appended
+func shareHash(job *Job, extraNonce []byte, ntime, nonce uint64) []byte {
+	header := make([]byte, len(job.BlockHeader), len(job.BlockHeader)+len(extraNonce))
+	copy(header, job.BlockHeader)
+	if n := len(header); n >= 16 {
+		copy(header[n-16:], uint64ToBytes(ntime))
+		copy(header[n-8:], uint64ToBytes(nonce))
+	}
+	return crypto.Hash256(append(header, extraNonce...))
+}
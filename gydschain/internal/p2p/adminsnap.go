@@ -0,0 +1,108 @@
+package p2p
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+)
+
+// adminSnapshotTimeout bounds a snapshot download from the admin server
+const adminSnapshotTimeout = 30 * time.Minute
+
+// AdminSnapshot describes a snapshot the admin server publishes for new
+// nodes to cold start from. The admin server vouches for the checksum; the
+// snapshot is still checked against its block hash and state root.
+type AdminSnapshot struct {
+	Height    uint64 `json:"height"`
+	BlockHash string `json:"block_hash"`
+	StateRoot string `json:"state_root"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+}
+
+// LoadAdminSnapshot reads a snapshot descriptor saved from the admin server
+func LoadAdminSnapshot(path string) (*AdminSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap AdminSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.URL == "" || snap.SHA256 == "" {
+		return nil, fmt.Errorf("snapshot descriptor missing url or sha256")
+	}
+	return &snap, nil
+}
+
+// Download fetches the snapshot into dir and returns it once it matches the
+// descriptor's checksum, height, block hash and state root and its state
+// hashes to that root
+func (a *AdminSnapshot) Download(dir string) (*chain.ChainSnapshot, error) {
+	client := &http.Client{Timeout: adminSnapshotTimeout}
+	resp, err := client.Get(a.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("snapshot download failed: %s", resp.Status)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	// Download to a temp file so a partial or tampered file is never used
+	tmpPath := filepath.Join(dir, "snapshot.tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, h), resp.Body)
+	f.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != a.SHA256 {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("snapshot checksum mismatch: got %s, want %s", sum, a.SHA256)
+	}
+	if a.Size > 0 && size != a.Size {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("snapshot size mismatch: got %d, want %d", size, a.Size)
+	}
+
+	snap, err := chain.LoadSnapshot(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if snap.Height != a.Height || snap.BlockHash != a.BlockHash || snap.StateRoot != a.StateRoot {
+		os.Remove(tmpPath)
+		return nil, ErrSnapshotMismatch
+	}
+	if err := snap.Verify(); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	finalPath := filepath.Join(dir, fmt.Sprintf("snapshot-%d.json.gz", a.Height))
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
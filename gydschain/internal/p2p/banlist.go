@@ -0,0 +1,297 @@
+package p2p
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBanDuration is how long a local ban lasts when none is given
+const DefaultBanDuration = 24 * time.Hour
+
+// Ban sources
+const (
+	BanSourceLocal    = "local"    // banned by this node
+	BanSourceGreylist = "greylist" // distributed by the admin server
+)
+
+// Ban errors
+var (
+	ErrPeerBanned   = errors.New("peer address is banned")
+	ErrPeerNotFound = errors.New("peer not connected")
+	ErrNoBanList    = errors.New("ban list not configured")
+)
+
+// BanEntry records why and until when a peer address is refused
+type BanEntry struct {
+	Address  string    `json:"address"` // host without port
+	PeerID   string    `json:"peer_id,omitempty"`
+	Reason   string    `json:"reason"`
+	BannedAt time.Time `json:"banned_at"`
+	Expires  time.Time `json:"expires"`
+	Source   string    `json:"source"`
+	Reported bool      `json:"reported,omitempty"` // sent to the admin server
+}
+
+func (e *BanEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+// BanList holds this node's own bans, the network greylist pulled from the
+// admin server, and local overrides exempting addresses from the greylist.
+// Local bans always win; an override only ever lifts a greylist entry.
+type BanList struct {
+	mu      sync.RWMutex
+	local   map[string]*BanEntry
+	grey    map[string]*BanEntry
+	allowed map[string]bool
+	path    string
+}
+
+// banListFile is the persisted form of a BanList
+type banListFile struct {
+	Bans     []*BanEntry `json:"bans"`
+	Greylist []*BanEntry `json:"greylist"`
+	Allowed  []string    `json:"allowed"`
+}
+
+// NewBanList creates an empty, in-memory ban list
+func NewBanList() *BanList {
+	return &BanList{
+		local:   make(map[string]*BanEntry),
+		grey:    make(map[string]*BanEntry),
+		allowed: make(map[string]bool),
+	}
+}
+
+// Load reads bans saved at path, if any, and saves every later change there
+func (b *BanList) Load(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.path = path
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file banListFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, e := range file.Bans {
+		if !e.expired(now) {
+			b.local[e.Address] = e
+		}
+	}
+	for _, e := range file.Greylist {
+		if !e.expired(now) {
+			b.grey[e.Address] = e
+		}
+	}
+	for _, addr := range file.Allowed {
+		b.allowed[addr] = true
+	}
+	return nil
+}
+
+// save writes the list to its path; callers must hold b.mu
+func (b *BanList) save() error {
+	if b.path == "" {
+		return nil
+	}
+
+	file := banListFile{
+		Bans:     sortedEntries(b.local),
+		Greylist: sortedEntries(b.grey),
+		Allowed:  make([]string, 0, len(b.allowed)),
+	}
+	for addr := range b.allowed {
+		file.Allowed = append(file.Allowed, addr)
+	}
+	sort.Strings(file.Allowed)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(b.path, data, 0644)
+}
+
+// Ban refuses address for duration (DefaultBanDuration if zero)
+func (b *BanList) Ban(address, peerID, reason string, duration time.Duration) *BanEntry {
+	if duration <= 0 {
+		duration = DefaultBanDuration
+	}
+	now := time.Now()
+	entry := &BanEntry{
+		Address:  peerHost(address),
+		PeerID:   peerID,
+		Reason:   reason,
+		BannedAt: now,
+		Expires:  now.Add(duration),
+		Source:   BanSourceLocal,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.local[entry.Address] = entry
+	b.save()
+
+	c := *entry
+	return &c
+}
+
+// Unban lifts a local ban and, if the address is greylisted, overrides the
+// greylist for it
+func (b *BanList) Unban(address string) {
+	host := peerHost(address)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.local, host)
+	if _, exists := b.grey[host]; exists {
+		b.allowed[host] = true
+	}
+	b.save()
+}
+
+// Check returns the ban refusing address, if any
+func (b *BanList) Check(address string) (*BanEntry, bool) {
+	host := peerHost(address)
+	now := time.Now()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if e, exists := b.local[host]; exists && !e.expired(now) {
+		c := *e
+		return &c, true
+	}
+	if b.allowed[host] {
+		return nil, false
+	}
+	if e, exists := b.grey[host]; exists && !e.expired(now) {
+		c := *e
+		return &c, true
+	}
+	return nil, false
+}
+
+// SetGreylist replaces the network greylist. Overrides for addresses no
+// longer greylisted are dropped.
+func (b *BanList) SetGreylist(entries []*BanEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.grey = make(map[string]*BanEntry, len(entries))
+	for _, e := range entries {
+		c := *e
+		c.Address = peerHost(e.Address)
+		c.Source = BanSourceGreylist
+		b.grey[c.Address] = &c
+	}
+	for addr := range b.allowed {
+		if _, exists := b.grey[addr]; !exists {
+			delete(b.allowed, addr)
+		}
+	}
+	b.save()
+}
+
+// Unreported returns active local bans not yet sent to the admin server
+func (b *BanList) Unreported() []*BanEntry {
+	now := time.Now()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var entries []*BanEntry
+	for _, e := range b.local {
+		if !e.Reported && !e.expired(now) {
+			c := *e
+			entries = append(entries, &c)
+		}
+	}
+	return entries
+}
+
+// MarkReported records that bans on addresses reached the admin server
+func (b *BanList) MarkReported(addresses []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, addr := range addresses {
+		if e, exists := b.local[addr]; exists {
+			e.Reported = true
+		}
+	}
+	b.save()
+}
+
+// Entries returns the bans in force: local bans, then greylist entries
+// without a local override
+func (b *BanList) Entries() []*BanEntry {
+	now := time.Now()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entries := make([]*BanEntry, 0, len(b.local)+len(b.grey))
+	for _, e := range sortedEntries(b.local) {
+		if !e.expired(now) {
+			c := *e
+			entries = append(entries, &c)
+		}
+	}
+	for _, e := range sortedEntries(b.grey) {
+		if l, banned := b.local[e.Address]; banned && !l.expired(now) {
+			continue
+		}
+		if b.allowed[e.Address] || e.expired(now) {
+			continue
+		}
+		c := *e
+		entries = append(entries, &c)
+	}
+	return entries
+}
+
+// Allowed returns greylisted addresses this node has overridden
+func (b *BanList) Allowed() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	allowed := make([]string, 0, len(b.allowed))
+	for addr := range b.allowed {
+		allowed = append(allowed, addr)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+func sortedEntries(m map[string]*BanEntry) []*BanEntry {
+	entries := make([]*BanEntry, 0, len(m))
+	for _, e := range m {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Address < entries[j].Address })
+	return entries
+}
+
+// peerHost strips the port from a peer address; bans apply to the whole host
+func peerHost(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
+}
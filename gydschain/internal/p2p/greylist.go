@@ -0,0 +1,144 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/logging"
+)
+
+// logger is the p2p module logger
+var logger = logging.Module("p2p")
+
+// DefaultGreylistInterval is how often bans are exchanged with the admin server
+const DefaultGreylistInterval = 5 * time.Minute
+
+// GreylistSync reports this node's bans to the admin server and pulls the
+// greylist it aggregates from reports across the network
+type GreylistSync struct {
+	node     *Node
+	bans     *BanList
+	adminURL string
+	nodeID   string
+	interval time.Duration
+	client   *http.Client
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// banReport is the body posted to the admin server's /peers/bans
+type banReport struct {
+	NodeID string      `json:"node_id"`
+	Bans   []*BanEntry `json:"bans"`
+}
+
+// NewGreylistSync creates a sync for node's ban list against the admin
+// server at adminURL; zero interval selects the default
+func NewGreylistSync(node *Node, adminURL, nodeID string, interval time.Duration) *GreylistSync {
+	if interval <= 0 {
+		interval = DefaultGreylistInterval
+	}
+	return &GreylistSync{
+		node:     node,
+		bans:     node.BanList(),
+		adminURL: strings.TrimSuffix(adminURL, "/"),
+		nodeID:   nodeID,
+		interval: interval,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start syncs immediately and then every interval until Stop
+func (g *GreylistSync) Start() {
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := g.Sync(); err != nil {
+				logger.Warn("greylist sync failed", "admin", g.adminURL, "err", err)
+			}
+			select {
+			case <-g.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends periodic syncing
+func (g *GreylistSync) Stop() {
+	g.stopOnce.Do(func() { close(g.stopChan) })
+}
+
+// Sync reports new local bans, then refreshes the greylist and drops peers
+// it now covers
+func (g *GreylistSync) Sync() error {
+	if g.bans == nil {
+		return ErrNoBanList
+	}
+	if err := g.report(); err != nil {
+		return err
+	}
+
+	greylist, err := g.fetch()
+	if err != nil {
+		return err
+	}
+	g.bans.SetGreylist(greylist)
+	g.node.EnforceBans()
+	return nil
+}
+
+func (g *GreylistSync) report() error {
+	bans := g.bans.Unreported()
+	if len(bans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(&banReport{NodeID: g.nodeID, Bans: bans})
+	if err != nil {
+		return err
+	}
+	resp, err := g.client.Post(g.adminURL+"/peers/bans", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ban report rejected: %s", resp.Status)
+	}
+
+	addresses := make([]string, len(bans))
+	for i, e := range bans {
+		addresses[i] = e.Address
+	}
+	g.bans.MarkReported(addresses)
+	return nil
+}
+
+func (g *GreylistSync) fetch() ([]*BanEntry, error) {
+	resp, err := g.client.Get(g.adminURL + "/peers/greylist")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("greylist unavailable: %s", resp.Status)
+	}
+
+	var greylist []*BanEntry
+	if err := json.NewDecoder(resp.Body).Decode(&greylist); err != nil {
+		return nil, err
+	}
+	return greylist, nil
+}
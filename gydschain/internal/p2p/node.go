@@ -1,8 +1,10 @@
 package p2p
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"net"
 	"sync"
 	"time"
@@ -19,6 +21,9 @@ type NodeConfig struct {
 	NetworkID     uint64        `json:"network_id"`
 }
 
+// maxMessageSize bounds one incoming message; longer lines drop the peer
+const maxMessageSize = 1024 * 1024
+
 // DefaultNodeConfig returns default P2P configuration
 func DefaultNodeConfig() *NodeConfig {
 	return &NodeConfig{
@@ -39,6 +44,8 @@ type Node struct {
 	peers       map[string]*Peer
 	running     bool
 	stopChan    chan struct{}
+	bans        *BanList
+	stakes      StakeSource
 	
 	// Callbacks
 	onPeerConnect    func(*Peer)
@@ -60,8 +67,13 @@ type Peer struct {
 	Inbound    bool      `json:"inbound"`
 	MessagesSent uint64  `json:"messages_sent"`
 	MessagesRecv uint64  `json:"messages_recv"`
+	MessagesDropped uint64 `json:"messages_dropped"` // shed under bandwidth pressure
 	BytesSent  uint64    `json:"bytes_sent"`
 	BytesRecv  uint64    `json:"bytes_recv"`
+	Latency    time.Duration `json:"latency"` // last ping round trip
+	pingSent   time.Time
+	queue      *sendQueue
+	reader     *bufio.Reader // frames incoming newline-terminated messages
 }
 
 // Message represents a P2P message
@@ -84,6 +96,12 @@ const (
 	MsgTypeBlockRequest
 	MsgTypeTxRequest
 	MsgTypePeers
+	MsgTypeProposal
+	MsgTypeVote
+	MsgTypeEvidence
+	MsgTypeSnapshotOffer
+	MsgTypeSnapshotRequest
+	MsgTypeSnapshotChunk
 )
 
 // NewNode creates a new P2P node
@@ -172,9 +190,15 @@ func (n *Node) acceptLoop() {
 
 // handleConnection handles a new connection
 func (n *Node) handleConnection(conn net.Conn, inbound bool) {
+	if n.isBanned(conn.RemoteAddr().String()) {
+		conn.Close()
+		return
+	}
+	
 	peer := &Peer{
 		Address:   conn.RemoteAddr().String(),
 		Conn:      conn,
+		reader:    bufio.NewReaderSize(conn, maxMessageSize),
 		Connected: time.Now(),
 		LastSeen:  time.Now(),
 		Inbound:   inbound,
@@ -186,6 +210,9 @@ func (n *Node) handleConnection(conn net.Conn, inbound bool) {
 		return
 	}
 	
+	// Messages go through the peer's send queue once it is published
+	peer.queue = newSendQueue()
+	
 	n.mu.Lock()
 	if len(n.peers) >= n.config.MaxPeers {
 		n.mu.Unlock()
@@ -195,6 +222,8 @@ func (n *Node) handleConnection(conn net.Conn, inbound bool) {
 	n.peers[peer.ID] = peer
 	n.mu.Unlock()
 	
+	go n.writeLoop(peer)
+	
 	if n.onPeerConnect != nil {
 		n.onPeerConnect(peer)
 	}
@@ -243,6 +272,60 @@ func (n *Node) handshake(peer *Peer) error {
 	return nil
 }
 
+// SetBanList attaches the list consulted before accepting or dialing peers
+func (n *Node) SetBanList(bans *BanList) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.bans = bans
+}
+
+// BanList returns the attached ban list, or nil
+func (n *Node) BanList() *BanList {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.bans
+}
+
+func (n *Node) isBanned(address string) bool {
+	bans := n.BanList()
+	if bans == nil {
+		return false
+	}
+	_, banned := bans.Check(address)
+	return banned
+}
+
+// BanPeer bans a connected peer's address and disconnects it
+func (n *Node) BanPeer(peerID, reason string, duration time.Duration) (*BanEntry, error) {
+	n.mu.RLock()
+	peer, exists := n.peers[peerID]
+	bans := n.bans
+	n.mu.RUnlock()
+	
+	if !exists {
+		return nil, ErrPeerNotFound
+	}
+	if bans == nil {
+		return nil, ErrNoBanList
+	}
+	
+	entry := bans.Ban(peer.Address, peer.ID, reason, duration)
+	n.disconnectPeer(peer)
+	return entry, nil
+}
+
+// EnforceBans disconnects connected peers whose address is now banned
+func (n *Node) EnforceBans() int {
+	dropped := 0
+	for _, peer := range n.GetPeers() {
+		if n.isBanned(peer.Address) {
+			n.disconnectPeer(peer)
+			dropped++
+		}
+	}
+	return dropped
+}
+
 // Handshake message
 type Handshake struct {
 	Version   string `json:"version"`
@@ -261,6 +344,10 @@ func (n *Node) connectToSeeds() {
 
 // Connect connects to a peer by address
 func (n *Node) Connect(address string) error {
+	if n.isBanned(address) {
+		return ErrPeerBanned
+	}
+	
 	conn, err := net.DialTimeout("tcp", address, n.config.DialTimeout)
 	if err != nil {
 		return err
@@ -288,6 +375,9 @@ func (n *Node) pingLoop() {
 			n.mu.RUnlock()
 			
 			for _, peer := range peers {
+				peer.mu.Lock()
+				peer.pingSent = time.Now()
+				peer.mu.Unlock()
 				n.sendMessage(peer, MsgTypePing, nil)
 			}
 		}
@@ -323,7 +413,13 @@ func (n *Node) handleMessage(peer *Peer, msg *Message) {
 	case MsgTypePing:
 		n.sendMessage(peer, MsgTypePong, nil)
 	case MsgTypePong:
-		// Update last seen (already done)
+		// Last seen is already updated; record the round trip
+		peer.mu.Lock()
+		if !peer.pingSent.IsZero() {
+			peer.Latency = time.Since(peer.pingSent)
+			peer.pingSent = time.Time{}
+		}
+		peer.mu.Unlock()
 	default:
 		if n.onMessage != nil {
 			n.onMessage(peer, msg)
@@ -331,55 +427,101 @@ func (n *Node) handleMessage(peer *Peer, msg *Message) {
 	}
 }
 
-// sendMessage sends a message to a peer
+// sendMessage queues a message for a peer
 func (n *Node) sendMessage(peer *Peer, msgType MessageType, payload interface{}) error {
+	data, err := encodeMessage(msgType, payload)
+	if err != nil {
+		return err
+	}
+	return n.enqueue(peer, msgType, data, nil)
+}
+
+// encodeMessage serializes a message as one newline-terminated line
+func encodeMessage(msgType MessageType, payload interface{}) ([]byte, error) {
 	var payloadBytes json.RawMessage
 	if payload != nil {
 		var err error
 		payloadBytes, err = json.Marshal(payload)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	
+
 	msg := &Message{
 		Type:      msgType,
 		Payload:   payloadBytes,
 		Timestamp: time.Now().Unix(),
 	}
-	
+
 	data, err := json.Marshal(msg)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
+	return append(data, '\n'), nil
+}
+
+// enqueue adds encoded data to the peer's send queue, ranked by the stake of
+// the validator that originated it. Handshakes happen before the queue
+// exists and are written directly.
+func (n *Node) enqueue(peer *Peer, msgType MessageType, data []byte, stake *big.Int) error {
+	if peer.queue == nil {
+		return n.writeMessage(peer, data)
+	}
+
+	evicted, err := peer.queue.push(classOf(msgType), data, stake)
+	if evicted || err == ErrSendQueueFull {
+		peer.mu.Lock()
+		peer.MessagesDropped++
+		peer.mu.Unlock()
+	}
+	return err
+}
+
+// writeMessage writes encoded data to the peer connection
+func (n *Node) writeMessage(peer *Peer, data []byte) error {
 	peer.mu.Lock()
-	_, err = peer.Conn.Write(append(data, '\n'))
+	defer peer.mu.Unlock()
+
+	peer.Conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := peer.Conn.Write(data)
 	if err == nil {
 		peer.MessagesSent++
 		peer.BytesSent += uint64(len(data))
 	}
-	peer.mu.Unlock()
-	
 	return err
 }
 
+// writeLoop sends queued messages, most urgent first, until the peer
+// disconnects. A failed write closes the connection so readLoop removes it.
+func (n *Node) writeLoop(peer *Peer) {
+	for {
+		data, ok := peer.queue.pop()
+		if !ok {
+			return
+		}
+		if err := n.writeMessage(peer, data); err != nil {
+			peer.Disconnect()
+			return
+		}
+	}
+}
+
 // readMessage reads a message from a peer
 func (n *Node) readMessage(peer *Peer) (*Message, error) {
-	buf := make([]byte, 1024*1024) // 1MB max
-	
+	// A message may span several reads and one read may hold several
+	// messages, so read up to the newline that ends each one
 	peer.Conn.SetReadDeadline(time.Now().Add(time.Minute))
-	num, err := peer.Conn.Read(buf)
+	line, err := peer.reader.ReadSlice('\n')
 	if err != nil {
 		return nil, err
 	}
 	
 	peer.mu.Lock()
-	peer.BytesRecv += uint64(num)
+	peer.BytesRecv += uint64(len(line))
 	peer.mu.Unlock()
 	
 	var msg Message
-	if err := json.Unmarshal(buf[:num], &msg); err != nil {
+	if err := json.Unmarshal(line, &msg); err != nil {
 		return nil, err
 	}
 	
@@ -408,6 +550,9 @@ func (p *Peer) Disconnect() {
 	if p.Conn != nil {
 		p.Conn.Close()
 	}
+	if p.queue != nil {
+		p.queue.close()
+	}
 }
 
 // GetPeers returns all connected peers
@@ -431,16 +576,62 @@ func (n *Node) PeerCount() int {
 
 // Broadcast sends a message to all peers
 func (n *Node) Broadcast(msgType MessageType, payload interface{}) {
+	n.broadcast(msgType, payload, "", "")
+}
+
+// BroadcastExcept sends a message to all peers except the one with peerID
+func (n *Node) BroadcastExcept(msgType MessageType, payload interface{}, peerID string) {
+	n.broadcast(msgType, payload, "", peerID)
+}
+
+// BroadcastFrom relays a message originated by validator to all peers except
+// the one with peerID. Messages from higher-stake validators are sent first
+// and shed last under bandwidth pressure; callers must have verified the
+// origin, e.g. the proposal or vote signature, before relaying.
+func (n *Node) BroadcastFrom(msgType MessageType, payload interface{}, validator, peerID string) {
+	n.broadcast(msgType, payload, validator, peerID)
+}
+
+// broadcast encodes a message once and queues it for every peer but
+// exceptID, if set
+func (n *Node) broadcast(msgType MessageType, payload interface{}, validator, exceptID string) {
+	data, err := encodeMessage(msgType, payload)
+	if err != nil {
+		return
+	}
+	stake := n.stakeOf(validator)
+
 	n.mu.RLock()
 	peers := make([]*Peer, 0, len(n.peers))
-	for _, p := range n.peers {
-		peers = append(peers, p)
+	for id, p := range n.peers {
+		if exceptID == "" || id != exceptID {
+			peers = append(peers, p)
+		}
 	}
 	n.mu.RUnlock()
-	
+
 	for _, peer := range peers {
-		go n.sendMessage(peer, msgType, payload)
+		n.enqueue(peer, msgType, data, stake)
+	}
+}
+
+// SetStakeSource attaches the validator stakes used to rank relayed messages
+func (n *Node) SetStakeSource(stakes StakeSource) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stakes = stakes
+}
+
+// stakeOf returns the stake of an active validator, or nil for unknown origins
+func (n *Node) stakeOf(validator string) *big.Int {
+	n.mu.RLock()
+	stakes := n.stakes
+	n.mu.RUnlock()
+
+	if validator == "" || stakes == nil {
+		return nil
 	}
+	return stakes.ValidatorStake(validator)
 }
 
 // SetMessageHandler sets the message handler callback
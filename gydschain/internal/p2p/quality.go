@@ -0,0 +1,72 @@
+package p2p
+
+import (
+	"sort"
+	"time"
+)
+
+// Peer latency thresholds used to classify peer quality
+const (
+	GoodPeerLatency = 150 * time.Millisecond
+	FairPeerLatency = 500 * time.Millisecond
+)
+
+// PeerQuality summarizes the distribution of connected peers by quality
+type PeerQuality struct {
+	Total         int   `json:"total"`
+	Inbound       int   `json:"inbound"`
+	Outbound      int   `json:"outbound"`
+	Good          int   `json:"good"`
+	Fair          int   `json:"fair"`
+	Poor          int   `json:"poor"`
+	Stale         int   `json:"stale"` // nothing heard for three ping intervals
+	Lagging       int   `json:"lagging"`
+	MedianLatency int64 `json:"median_latency_ms"`
+}
+
+// PeerQuality classifies peers by ping latency and liveness. Peers more than
+// maxLag blocks behind localHeight are also counted as lagging.
+func (n *Node) PeerQuality(localHeight, maxLag uint64) *PeerQuality {
+	peers := n.GetPeers()
+	staleAfter := 3 * n.config.PingInterval
+
+	quality := &PeerQuality{Total: len(peers)}
+	latencies := make([]time.Duration, 0, len(peers))
+
+	for _, p := range peers {
+		p.mu.RLock()
+		inbound, latency, lastSeen, height := p.Inbound, p.Latency, p.LastSeen, p.Height
+		p.mu.RUnlock()
+
+		if inbound {
+			quality.Inbound++
+		} else {
+			quality.Outbound++
+		}
+		if height+maxLag < localHeight {
+			quality.Lagging++
+		}
+
+		switch {
+		case time.Since(lastSeen) > staleAfter:
+			quality.Stale++
+			continue
+		case latency > FairPeerLatency:
+			quality.Poor++
+		case latency == 0 || latency > GoodPeerLatency:
+			quality.Fair++ // not yet measured peers count as fair
+		default:
+			quality.Good++
+		}
+		if latency > 0 {
+			latencies = append(latencies, latency)
+		}
+	}
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		quality.MedianLatency = latencies[len(latencies)/2].Milliseconds()
+	}
+
+	return quality
+}
@@ -0,0 +1,159 @@
+package p2p
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Send path errors
+var (
+	ErrSendQueueFull    = errors.New("peer send queue full")
+	ErrPeerDisconnected = errors.New("peer disconnected")
+)
+
+// StakeSource reports the stake of active validators so messages they
+// originate are relayed first; unknown addresses return nil
+type StakeSource interface {
+	ValidatorStake(address string) *big.Int
+}
+
+// sendClass groups message types into per-peer send queues, drained in
+// order so consensus traffic is never stuck behind transaction gossip
+type sendClass int
+
+const (
+	classControl   sendClass = iota // handshakes, pings and pongs
+	classConsensus                  // block proposals and votes
+	classBlock                      // blocks and block requests
+	classGossip                     // transactions, tx requests and peer lists
+	numSendClasses
+)
+
+// writeTimeout bounds a single write so a stalled peer is dropped instead of
+// holding its queue forever
+const writeTimeout = 30 * time.Second
+
+// sendQueueLimits bounds each class's queue per peer. A full class drops its
+// lowest ranked message, so under bandwidth pressure unknown sources go first.
+var sendQueueLimits = [numSendClasses]int{64, 512, 128, 1024}
+
+// classOf returns the send queue a message type uses
+func classOf(msgType MessageType) sendClass {
+	switch msgType {
+	case MsgTypePing, MsgTypePong, MsgTypeHandshake:
+		return classControl
+	case MsgTypeProposal, MsgTypeVote, MsgTypeEvidence:
+		return classConsensus
+	case MsgTypeBlock, MsgTypeBlockRequest, MsgTypeSnapshotRequest, MsgTypeSnapshotChunk:
+		return classBlock
+	default:
+		return classGossip
+	}
+}
+
+// outbound is an encoded message waiting to be written to a peer
+type outbound struct {
+	data  []byte
+	stake *big.Int // stake of the originating validator; nil if unknown
+	seq   uint64
+}
+
+// outranks orders messages within a class: higher origin stake first, then
+// oldest first
+func (o *outbound) outranks(other *outbound) bool {
+	if c := stakeOrZero(o.stake).Cmp(stakeOrZero(other.stake)); c != 0 {
+		return c > 0
+	}
+	return o.seq < other.seq
+}
+
+func stakeOrZero(stake *big.Int) *big.Int {
+	if stake == nil {
+		return new(big.Int)
+	}
+	return stake
+}
+
+// sendQueue holds a peer's outbound messages until its write loop sends them
+type sendQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	classes [numSendClasses][]*outbound
+	seq     uint64
+	closed  bool
+}
+
+func newSendQueue() *sendQueue {
+	q := &sendQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues data in class, ranked by stake. It returns ErrSendQueueFull if
+// data itself was dropped, and reports whether a queued message was evicted
+// to make room.
+func (q *sendQueue) push(class sendClass, data []byte, stake *big.Int) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false, ErrPeerDisconnected
+	}
+
+	q.seq++
+	item := &outbound{data: data, stake: stake, seq: q.seq}
+	queue := q.classes[class]
+
+	evicted := false
+	if len(queue) >= sendQueueLimits[class] {
+		if !item.outranks(queue[len(queue)-1]) {
+			return false, ErrSendQueueFull
+		}
+		queue = queue[:len(queue)-1]
+		evicted = true
+	}
+
+	i := sort.Search(len(queue), func(i int) bool { return item.outranks(queue[i]) })
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = item
+	q.classes[class] = queue
+
+	q.cond.Signal()
+	return evicted, nil
+}
+
+// pop blocks until a message is queued and returns the highest ranked one
+// from the most urgent class, or false once the queue is closed
+func (q *sendQueue) pop() ([]byte, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if q.closed {
+			return nil, false
+		}
+		for class := range q.classes {
+			if queue := q.classes[class]; len(queue) > 0 {
+				item := queue[0]
+				queue[0] = nil
+				q.classes[class] = queue[1:]
+				return item.data, true
+			}
+		}
+		q.cond.Wait()
+	}
+}
+
+// close drops queued messages and wakes the write loop
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.classes = [numSendClasses][]*outbound{}
+	q.cond.Broadcast()
+}
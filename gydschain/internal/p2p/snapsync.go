@@ -0,0 +1,427 @@
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+)
+
+// Snapshot sync tuning
+const (
+	SnapshotChunkSize       = 64 * 1024        // bytes of encoded snapshot per chunk message
+	DefaultSnapshotPeers    = 2                // peers that must offer the same snapshot before it is used
+	snapshotOfferWait       = 10 * time.Second // offers collected before one is chosen
+	snapshotChunkTimeout    = 30 * time.Second // a chunk not received in time is asked of another peer
+	snapshotChunkRetries    = 5                // requests per chunk before the download is abandoned
+	snapshotChunkWindow     = 16               // chunk requests in flight at once
+	blockSyncBatch          = 64               // blocks asked for per request after the snapshot
+	blockSyncTimeout        = 15 * time.Second // wait for a batch before the chain counts as caught up
+	maxSnapshotChunks       = 1 << 16          // bounds the offers a peer can make us act on
+	snapshotChunkBufferSize = snapshotChunkWindow * 2
+)
+
+// Snapshot sync errors
+var (
+	ErrNoSnapshotOffer   = errors.New("no snapshot offered by enough peers")
+	ErrSnapshotDownload  = errors.New("snapshot chunk could not be downloaded")
+	ErrSnapshotMismatch  = errors.New("downloaded snapshot does not match its offer")
+	ErrSnapSyncCancelled = errors.New("snapshot sync cancelled")
+)
+
+// SnapshotOffer advertises the snapshot a peer serves. Chunks lists the
+// SHA-256 of every chunk so each is checked as it arrives; the assembled
+// snapshot is then checked against the state root when restored.
+type SnapshotOffer struct {
+	Height    uint64   `json:"height"`
+	BlockHash string   `json:"block_hash"`
+	StateRoot string   `json:"state_root"`
+	Chunks    []string `json:"chunks"`
+}
+
+// key identifies offers of the same snapshot, so peers can be counted
+// agreeing on it
+func (o *SnapshotOffer) key() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d/%s/%s", o.Height, o.BlockHash, o.StateRoot)
+	for _, chunk := range o.Chunks {
+		h.Write([]byte(chunk))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SnapshotChunkRequest asks a peer for one chunk of its offered snapshot
+type SnapshotChunkRequest struct {
+	Height uint64 `json:"height"`
+	Index  int    `json:"index"`
+}
+
+// SnapshotChunk is one chunk of an offered snapshot
+type SnapshotChunk struct {
+	Height uint64 `json:"height"`
+	Index  int    `json:"index"`
+	Data   []byte `json:"data"`
+}
+
+// BlockRequest asks a peer for up to Count canonical blocks from height
+// From; they are answered as ordinary block messages
+type BlockRequest struct {
+	From  uint64 `json:"from"`
+	Count int    `json:"count"`
+}
+
+// SnapSync serves this node's latest chain snapshot to peers and lets a
+// new node restore one from them instead of replaying every block. After
+// the restore, the blocks since the snapshot are requested in batches and
+// the node follows the chain as usual.
+type SnapSync struct {
+	mu      sync.Mutex
+	node    *Node
+	chain   *chain.Chain
+	genesis *chain.GenesisConfig
+	quorum  int
+
+	// The snapshot served to peers
+	offer  *SnapshotOffer
+	chunks [][]byte
+
+	// Offers heard from peers, by offer key and peer ID
+	offers map[string]map[string]*Peer
+	heard  map[string]*SnapshotOffer
+
+	// Chunks arriving for an active download
+	download uint64
+	received chan *SnapshotChunk
+}
+
+// NewSnapSync creates a snapshot sync for blockchain over node. quorum
+// peers must offer the same snapshot before it is restored; 0 uses
+// DefaultSnapshotPeers.
+func NewSnapSync(node *Node, blockchain *chain.Chain, genesis *chain.GenesisConfig, quorum int) *SnapSync {
+	if quorum <= 0 {
+		quorum = DefaultSnapshotPeers
+	}
+	return &SnapSync{
+		node:     node,
+		chain:    blockchain,
+		genesis:  genesis,
+		quorum:   quorum,
+		offers:   make(map[string]map[string]*Peer),
+		heard:    make(map[string]*SnapshotOffer),
+		received: make(chan *SnapshotChunk, snapshotChunkBufferSize),
+	}
+}
+
+// Publish snapshots the chain head, splits it into chunks and advertises
+// it to every peer, replacing the snapshot served before
+func (s *SnapSync) Publish() error {
+	snap, err := s.chain.Snapshot()
+	if err != nil {
+		return err
+	}
+	if snap.Height == 0 {
+		return nil
+	}
+	data, err := snap.Encode()
+	if err != nil {
+		return err
+	}
+
+	offer := &SnapshotOffer{Height: snap.Height, BlockHash: snap.BlockHash, StateRoot: snap.StateRoot}
+	var chunks [][]byte
+	for len(data) > 0 {
+		n := SnapshotChunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		sum := sha256.Sum256(data[:n])
+		offer.Chunks = append(offer.Chunks, hex.EncodeToString(sum[:]))
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+
+	s.mu.Lock()
+	s.offer, s.chunks = offer, chunks
+	s.mu.Unlock()
+
+	s.node.Broadcast(MsgTypeSnapshotOffer, offer)
+	logger.Info("snapshot published", "height", offer.Height, "chunks", len(chunks))
+	return nil
+}
+
+// PeerConnected advertises the served snapshot to a new peer
+func (s *SnapSync) PeerConnected(peer *Peer) {
+	s.mu.Lock()
+	offer := s.offer
+	s.mu.Unlock()
+	if offer != nil {
+		s.node.sendMessage(peer, MsgTypeSnapshotOffer, offer)
+	}
+}
+
+// HandleMessage processes snapshot sync and block request messages from
+// peer. It reports whether msg was one of them.
+func (s *SnapSync) HandleMessage(peer *Peer, msg *Message) bool {
+	switch msg.Type {
+	case MsgTypeSnapshotOffer:
+		var offer SnapshotOffer
+		if err := json.Unmarshal(msg.Payload, &offer); err != nil || len(offer.Chunks) == 0 || len(offer.Chunks) > maxSnapshotChunks {
+			return true
+		}
+		s.recordOffer(peer, &offer)
+
+	case MsgTypeSnapshotRequest:
+		var req SnapshotChunkRequest
+		if err := json.Unmarshal(msg.Payload, &req); err != nil {
+			return true
+		}
+		s.mu.Lock()
+		var chunk *SnapshotChunk
+		if s.offer != nil && s.offer.Height == req.Height && req.Index >= 0 && req.Index < len(s.chunks) {
+			chunk = &SnapshotChunk{Height: req.Height, Index: req.Index, Data: s.chunks[req.Index]}
+		}
+		s.mu.Unlock()
+		if chunk != nil {
+			s.node.sendMessage(peer, MsgTypeSnapshotChunk, chunk)
+		}
+
+	case MsgTypeSnapshotChunk:
+		var chunk SnapshotChunk
+		if err := json.Unmarshal(msg.Payload, &chunk); err != nil {
+			return true
+		}
+		s.mu.Lock()
+		downloading := s.download != 0 && s.download == chunk.Height
+		s.mu.Unlock()
+		if downloading {
+			select {
+			case s.received <- &chunk:
+			default:
+				// The downloader is behind; the chunk is asked for again
+			}
+		}
+
+	case MsgTypeBlockRequest:
+		var req BlockRequest
+		if err := json.Unmarshal(msg.Payload, &req); err != nil {
+			return true
+		}
+		if req.Count <= 0 || req.Count > blockSyncBatch {
+			req.Count = blockSyncBatch
+		}
+		for height := req.From; height < req.From+uint64(req.Count); height++ {
+			block, err := s.chain.GetBlockByHeight(height)
+			if err != nil {
+				break
+			}
+			s.node.sendMessage(peer, MsgTypeBlock, block)
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
+// recordOffer notes that peer serves offer, dropping its earlier offer
+func (s *SnapSync) recordOffer(peer *Peer, offer *SnapshotOffer) {
+	key := offer.key()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, peers := range s.offers {
+		delete(peers, peer.ID)
+		if len(peers) == 0 {
+			delete(s.offers, k)
+			delete(s.heard, k)
+		}
+	}
+	if s.offers[key] == nil {
+		s.offers[key] = make(map[string]*Peer)
+		s.heard[key] = offer
+	}
+	s.offers[key][peer.ID] = peer
+}
+
+// bestOffer returns the highest snapshot offered by at least quorum peers,
+// and those peers
+func (s *SnapSync) bestOffer() (*SnapshotOffer, []*Peer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best *SnapshotOffer
+	var bestPeers []*Peer
+	for key, peers := range s.offers {
+		offer := s.heard[key]
+		if len(peers) < s.quorum || (best != nil && offer.Height <= best.Height) {
+			continue
+		}
+		best, bestPeers = offer, make([]*Peer, 0, len(peers))
+		for _, peer := range peers {
+			bestPeers = append(bestPeers, peer)
+		}
+	}
+	sort.Slice(bestPeers, func(i, j int) bool { return bestPeers[i].ID < bestPeers[j].ID })
+	return best, bestPeers
+}
+
+// Sync waits for peers to offer snapshots, restores the best one ahead of
+// the chain and then catches up on the blocks since. It does nothing if
+// no offered snapshot is ahead of the chain.
+func (s *SnapSync) Sync(ctx context.Context) error {
+	select {
+	case <-time.After(snapshotOfferWait):
+	case <-ctx.Done():
+		return ErrSnapSyncCancelled
+	}
+
+	offer, peers := s.bestOffer()
+	if offer == nil {
+		return ErrNoSnapshotOffer
+	}
+	if offer.Height <= s.chain.Height() {
+		return nil
+	}
+	logger.Info("snapshot sync started", "height", offer.Height, "chunks", len(offer.Chunks), "peers", len(peers))
+
+	data, err := s.fetch(ctx, offer, peers)
+	if err != nil {
+		return err
+	}
+	snap, err := chain.DecodeSnapshot(data)
+	if err != nil {
+		return err
+	}
+	if snap.Height != offer.Height || snap.BlockHash != offer.BlockHash || snap.StateRoot != offer.StateRoot {
+		return ErrSnapshotMismatch
+	}
+	if err := s.chain.RestoreSnapshot(s.genesis, snap); err != nil {
+		return err
+	}
+	logger.Info("snapshot restored", "height", snap.Height, "state_root", snap.StateRoot)
+
+	return s.catchUp(ctx, peers)
+}
+
+// fetch downloads every chunk of offer from peers, spreading requests over
+// them and asking another peer when one does not answer in time
+func (s *SnapSync) fetch(ctx context.Context, offer *SnapshotOffer, peers []*Peer) ([]byte, error) {
+	s.mu.Lock()
+	s.download = offer.Height
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.download = 0
+		s.mu.Unlock()
+	}()
+
+	chunks := make([][]byte, len(offer.Chunks))
+	requested := make(map[int]time.Time)
+	attempts := make([]int, len(offer.Chunks))
+	request := func(index int) {
+		peer := peers[(index+attempts[index])%len(peers)]
+		attempts[index]++
+		requested[index] = time.Now()
+		s.node.sendMessage(peer, MsgTypeSnapshotRequest, &SnapshotChunkRequest{Height: offer.Height, Index: index})
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	next, missing := 0, len(chunks)
+	for missing > 0 {
+		for len(requested) < snapshotChunkWindow && next < len(chunks) {
+			request(next)
+			next++
+		}
+
+		select {
+		case chunk := <-s.received:
+			if chunk.Height != offer.Height || chunk.Index < 0 || chunk.Index >= len(chunks) || chunks[chunk.Index] != nil {
+				continue
+			}
+			// A chunk that fails its hash stays requested and is asked of
+			// another peer when it times out
+			sum := sha256.Sum256(chunk.Data)
+			if hex.EncodeToString(sum[:]) != offer.Chunks[chunk.Index] {
+				logger.Warn("snapshot chunk hash mismatch", "height", offer.Height, "index", chunk.Index)
+				continue
+			}
+			chunks[chunk.Index] = chunk.Data
+			delete(requested, chunk.Index)
+			missing--
+
+		case <-ticker.C:
+			for index, at := range requested {
+				if time.Since(at) < snapshotChunkTimeout {
+					continue
+				}
+				if attempts[index] >= snapshotChunkRetries {
+					return nil, fmt.Errorf("%w: chunk %d", ErrSnapshotDownload, index)
+				}
+				request(index)
+			}
+
+		case <-ctx.Done():
+			return nil, ErrSnapSyncCancelled
+		}
+	}
+
+	var data []byte
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+// catchUp requests the blocks after the restored snapshot in batches until
+// a batch brings no new blocks; from then on blocks arrive by gossip
+func (s *SnapSync) catchUp(ctx context.Context, peers []*Peer) error {
+	for round := 0; ; round++ {
+		start := s.chain.Height()
+		peer := peers[round%len(peers)]
+		s.node.sendMessage(peer, MsgTypeBlockRequest, &BlockRequest{From: start + 1, Count: blockSyncBatch})
+
+		// The batch is done when it is complete or blocks stop arriving
+		deadline := time.NewTimer(blockSyncTimeout)
+		poll := time.NewTicker(200 * time.Millisecond)
+		last, idle := start, 0
+	wait:
+		for {
+			select {
+			case <-poll.C:
+				height := s.chain.Height()
+				if height >= start+blockSyncBatch {
+					break wait
+				}
+				if height == last {
+					idle++
+				} else {
+					last, idle = height, 0
+				}
+				if height > start && idle >= 10 {
+					break wait
+				}
+			case <-deadline.C:
+				break wait
+			case <-ctx.Done():
+				deadline.Stop()
+				poll.Stop()
+				return ErrSnapSyncCancelled
+			}
+		}
+		deadline.Stop()
+		poll.Stop()
+
+		if s.chain.Height() == start {
+			logger.Info("block sync caught up", "height", start)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package p2p
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// DefaultSeenTxCache is how many recent transaction hashes are remembered
+// so a tx relayed back by a peer is not gossiped again
+const DefaultSeenTxCache = 20000
+
+// TxGossip moves transactions between the local mempool and peers
+type TxGossip struct {
+	mu      sync.Mutex
+	node    *Node
+	mempool *tx.Mempool
+	seen    map[string]struct{}
+	order   []string
+	limit   int
+}
+
+// NewTxGossip creates a relay for node and mempool
+func NewTxGossip(node *Node, mempool *tx.Mempool) *TxGossip {
+	return &TxGossip{
+		node:    node,
+		mempool: mempool,
+		seen:    make(map[string]struct{}),
+		limit:   DefaultSeenTxCache,
+	}
+}
+
+// markSeen records hash and reports whether it was new
+func (g *TxGossip) markSeen(hash string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.seen[hash]; exists {
+		return false
+	}
+	g.seen[hash] = struct{}{}
+	g.order = append(g.order, hash)
+	if len(g.order) > g.limit {
+		delete(g.seen, g.order[0])
+		g.order = g.order[1:]
+	}
+	return true
+}
+
+// Submit adds a locally received transaction to the mempool and gossips it
+// to all peers, returning its hash
+func (g *TxGossip) Submit(t *tx.Transaction) (string, error) {
+	hash, err := t.HashHex()
+	if err != nil {
+		return "", err
+	}
+	if err := g.mempool.AddTx(t); err != nil {
+		return "", err
+	}
+
+	g.markSeen(hash)
+	g.node.Broadcast(MsgTypeTransaction, t)
+	return hash, nil
+}
+
+// HandleMessage processes a transaction gossiped by peer and relays it to
+// the other peers if it was new and accepted. It reports whether msg was a
+// transaction message.
+func (g *TxGossip) HandleMessage(peer *Peer, msg *Message) bool {
+	if msg.Type != MsgTypeTransaction {
+		return false
+	}
+
+	var t tx.Transaction
+	if err := json.Unmarshal(msg.Payload, &t); err != nil {
+		return true
+	}
+	hash, err := t.HashHex()
+	if err != nil || !g.markSeen(hash) {
+		return true
+	}
+
+	// Invalid or already pending txs stop here
+	if err := g.mempool.AddTx(&t); err != nil {
+		return true
+	}
+	g.node.BroadcastExcept(MsgTypeTransaction, &t, peer.ID)
+	return true
+}
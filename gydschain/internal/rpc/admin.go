@@ -0,0 +1,197 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrExportsDisabled is returned by the export methods when the node has
+// no export directory
+var ErrExportsDisabled = errors.New("chain exports are disabled on this node")
+
+// ErrInvalidRegistration is returned when a signed registration is not a
+// stake by the address to itself naming the validator's key
+var ErrInvalidRegistration = errors.New("signed transaction does not register the address with the key")
+
+// ExportFile describes a compressed chain export or snapshot written by
+// the node. A snapshot's description can be published to the admin
+// server once the file is copied to its snapshot directory.
+type ExportFile struct {
+	File      string `json:"file"`
+	Height    uint64 `json:"height"`
+	BlockHash string `json:"block_hash"`
+	StateRoot string `json:"state_root,omitempty"` // snapshots only
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+}
+
+// flushMempool drops every pending transaction, e.g. after a bad batch of
+// transactions was admitted under a misconfigured policy
+func (m *Methods) flushMempool(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Mempool == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Mempool.Flush(), nil
+}
+
+// registerValidator submits the operator's signed registration on behalf
+// of the admin server: a stake of the address's own GYDS naming pub_key,
+// which bonds the stake and registers the validator when a block applies
+// it. It returns the transaction hash. Registering an address that already
+// validates with the same key submits nothing and returns the hash again,
+// so a retried onboarding is harmless.
+func (m *Methods) registerValidator(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address  string `json:"address"`
+		PubKey   string `json:"pub_key"`
+		SignedTx string `json:"signed_tx"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+	if err := crypto.ValidateAddress(args.Address); err != nil {
+		return nil, err
+	}
+	if _, err := crypto.ParsePublicKey(args.PubKey); err != nil {
+		return nil, err
+	}
+	t, err := decodeSignedTx(args.SignedTx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRegistration(t, args.Address, args.PubKey); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Engine == nil {
+		return nil, ErrBackendUnavailable
+	}
+	if v, err := backend.Engine.GetValidator(args.Address); err == nil {
+		if v.PubKey != args.PubKey {
+			return nil, pos.ErrAlreadyValidator
+		}
+		return t.HashHex()
+	}
+	if t.Amount.Cmp(backend.Engine.MinStake()) < 0 {
+		return nil, pos.ErrInsufficientStake
+	}
+	return submitTx(backend, t)
+}
+
+// checkRegistration checks t registers address as a validator with pubKey
+func checkRegistration(t *tx.Transaction, address, pubKey string) error {
+	if t.Type != tx.TxTypeStake || t.From != address || t.To != address || t.Amount == nil {
+		return ErrInvalidRegistration
+	}
+	payload, err := tx.DecodePayload(t)
+	if err != nil {
+		return err
+	}
+	if p, ok := payload.(*tx.StakePayload); !ok || p.PubKey != pubKey {
+		return ErrInvalidRegistration
+	}
+	return nil
+}
+
+// exportChain writes every canonical block and receipt to a compressed
+// file in the export directory, for `gydsnode chain import` on another node
+func (m *Methods) exportChain(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	if backend.ExportDir == "" {
+		return nil, ErrExportsDisabled
+	}
+
+	head, err := backend.Chain.LatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	data, err := backend.Chain.Export()
+	if err != nil {
+		return nil, err
+	}
+	// The export may run past head if a block lands meanwhile; it is named
+	// and described by the head it was requested at
+	path := filepath.Join(backend.ExportDir, fmt.Sprintf("chain-%d.json.gz", head.Header.Height))
+	if err := writeExport(backend.ExportDir, path, data); err != nil {
+		return nil, err
+	}
+	hash, _ := head.Hash()
+	return describeExport(path, head.Header.Height, hash, "")
+}
+
+// createSnapshot writes a compressed snapshot of the chain head and its
+// state to the export directory, for new nodes to bootstrap from
+func (m *Methods) createSnapshot(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	if backend.ExportDir == "" {
+		return nil, ErrExportsDisabled
+	}
+
+	snap, err := backend.Chain.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(backend.ExportDir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(backend.ExportDir, fmt.Sprintf("snapshot-%d.json.gz", snap.Height))
+	if err := snap.Save(path); err != nil {
+		return nil, err
+	}
+	return describeExport(path, snap.Height, snap.BlockHash, snap.StateRoot)
+}
+
+// writeExport compresses data to path, creating the export directory
+func writeExport(dir, path string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return chain.WriteCompressed(path, data)
+}
+
+// describeExport checksums a written export file
+func describeExport(path string, height uint64, blockHash, stateRoot string) (*ExportFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return &ExportFile{
+		File:      path,
+		Height:    height,
+		BlockHash: blockHash,
+		StateRoot: stateRoot,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      int64(len(data)),
+	}, nil
+}
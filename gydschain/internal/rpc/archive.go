@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// accountAt returns the current account, or the account as of height when
+// one is given. Missing accounts are returned as empty accounts.
+func (m *Methods) accountAt(address string, height *uint64) (*state.Account, error) {
+	backend, err := m.getBackend()
+	if err != nil || backend.State == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	var account *state.Account
+	if height == nil {
+		account = backend.State.GetAccount(address)
+	} else if account, err = backend.State.AccountAt(address, *height); err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		account = state.NewAccount(address)
+	}
+	return account, nil
+}
+
+// blockTimeAt returns the timestamp of the block at height, or of the
+// latest block when none is given; vesting is measured against it
+func (m *Methods) blockTimeAt(height *uint64) int64 {
+	backend, err := m.getBackend()
+	if err != nil || backend.Chain == nil {
+		return time.Now().Unix()
+	}
+
+	var block *chain.Block
+	if height == nil {
+		block, err = backend.Chain.LatestBlock()
+	} else {
+		block, err = backend.Chain.GetBlockByHeight(*height)
+	}
+	if err != nil || block == nil {
+		return time.Now().Unix()
+	}
+	return block.Header.Timestamp
+}
+
+// assetAt returns the current asset, or the asset as of height when one is given
+func (m *Methods) assetAt(id string, height *uint64) (*state.Asset, error) {
+	backend, err := m.getBackend()
+	if err != nil || backend.State == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	var asset *state.Asset
+	if height == nil {
+		asset = backend.State.GetAsset(id)
+	} else if asset, err = backend.State.AssetAt(id, *height); err != nil {
+		return nil, err
+	}
+
+	if asset == nil {
+		return nil, state.ErrAssetNotFound
+	}
+	return asset.Copy(), nil
+}
+
+// newAccountResponse converts an account for RPC output
+func newAccountResponse(account *state.Account) *AccountResponse {
+	resp := &AccountResponse{
+		Address:  account.Address,
+		Nonce:    account.Nonce,
+		Balances: make(map[string]string, len(account.Balances)),
+		Contract: account.IsContract(),
+	}
+	for asset, balance := range account.Balances {
+		resp.Balances[asset] = balance.String()
+	}
+	if multisig := account.GetMultisig(); multisig != nil {
+		resp.Multisig = &MultisigResponse{PubKeys: multisig.PubKeys, Threshold: multisig.Threshold}
+	}
+	return resp
+}
+
+// newVestingResponse converts a vesting status for RPC output
+func newVestingResponse(status *state.VestingStatus) *VestingResponse {
+	return &VestingResponse{
+		Total:     util.CopyBig(status.Schedule.Total).String(),
+		Initial:   util.CopyBig(status.Schedule.Initial).String(),
+		Start:     status.Schedule.Start,
+		Cliff:     status.Schedule.Cliff,
+		End:       status.Schedule.End,
+		Locked:    status.Locked.Int().String(),
+		Vested:    status.Vested.Int().String(),
+		Spendable: status.Spendable.Int().String(),
+		Time:      status.Time,
+	}
+}
+
+// newAssetResponse converts an asset for RPC output
+func newAssetResponse(asset *state.Asset) *AssetResponse {
+	resp := &AssetResponse{
+		ID:           asset.ID,
+		Symbol:       asset.Symbol,
+		Name:         asset.Name,
+		Decimals:     asset.Decimals,
+		TotalSupply:  util.CopyBig(asset.TotalSupply).String(),
+		Mintable:     asset.Mintable,
+		Burnable:     asset.Burnable,
+		Creator:      asset.Owner,
+		IsStablecoin: asset.Type == state.AssetTypeStablecoin,
+	}
+	if asset.MaxSupply != nil && asset.MaxSupply.Sign() > 0 {
+		resp.MaxSupply = asset.MaxSupply.String()
+	}
+	return resp
+}
@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// jwtLeeway is the clock skew tolerated when checking JWT exp and nbf
+const jwtLeeway = 60 * time.Second
+
+// Authenticator checks the credentials a caller presents: a static API key
+// in X-API-Key or as a bearer token, or a bearer JWT signed with HS256
+type Authenticator struct {
+	apiKeys   [][]byte
+	jwtSecret []byte
+	required  bool // refuse unauthenticated callers for every method
+}
+
+// NewAuthenticator creates an authenticator accepting apiKeys and JWTs
+// signed with jwtSecret; either may be empty
+func NewAuthenticator(apiKeys []string, jwtSecret []byte, required bool) *Authenticator {
+	a := &Authenticator{jwtSecret: jwtSecret, required: required}
+	for _, key := range apiKeys {
+		if key != "" {
+			a.apiKeys = append(a.apiKeys, []byte(key))
+		}
+	}
+	return a
+}
+
+// Required returns true if every method needs an authenticated caller
+func (a *Authenticator) Required() bool {
+	return a != nil && a.required
+}
+
+// Authenticate reports whether r carries valid credentials. A request
+// without credentials is unauthenticated; one with bad credentials fails
+// with ErrAuthFailed so a typo is not silently downgraded.
+func (a *Authenticator) Authenticate(r *http.Request) (bool, error) {
+	token := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); token == "" && auth != "" {
+		if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+			return false, ErrAuthFailed
+		}
+		token = strings.TrimSpace(auth[7:])
+	}
+	if token == "" {
+		return false, nil
+	}
+	if a == nil {
+		return false, ErrAuthFailed
+	}
+
+	for _, key := range a.apiKeys {
+		if subtle.ConstantTimeCompare(key, []byte(token)) == 1 {
+			return true, nil
+		}
+	}
+	if len(a.jwtSecret) > 0 && strings.Count(token, ".") == 2 {
+		if err := verifyJWT(token, a.jwtSecret, time.Now()); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, ErrAuthFailed
+}
+
+// verifyJWT checks an HS256 JWT's signature and its exp and nbf claims
+func verifyJWT(token string, secret []byte, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrAuthFailed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return ErrAuthFailed
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrAuthFailed
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrAuthFailed
+	}
+
+	var claims struct {
+		Exp *int64 `json:"exp"`
+		Nbf *int64 `json:"nbf"`
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return ErrAuthFailed
+	}
+	if claims.Exp != nil && now.After(time.Unix(*claims.Exp, 0).Add(jwtLeeway)) {
+		return ErrAuthExpired
+	}
+	if claims.Nbf != nil && now.Add(jwtLeeway).Before(time.Unix(*claims.Nbf, 0)) {
+		return ErrAuthExpired
+	}
+	return nil
+}
+
+// decodeJWTPart decodes a base64url JSON segment of a JWT into v
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SignJWT returns an HS256 JWT carrying claims, for tools calling a node
+// configured with secret
+func SignJWT(claims map[string]interface{}, secret []byte) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// SetAuth sets how callers are authenticated; nil accepts no credentials,
+// leaving admin methods unreachable over the network
+func (s *Server) SetAuth(auth *Authenticator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = auth
+}
+
+// SetEnabledAPIs limits the namespaces served to apis; empty serves all
+func (s *Server) SetEnabledAPIs(apis []string) {
+	s.methods.SetEnabledAPIs(apis)
+}
+
+// authenticate resolves the caller of r, refusing unauthenticated callers
+// when the server requires credentials
+func (s *Server) authenticate(r *http.Request) (bool, error) {
+	s.mu.RLock()
+	auth := s.auth
+	s.mu.RUnlock()
+
+	authed, err := auth.Authenticate(r)
+	if err != nil {
+		return false, err
+	}
+	if !authed && auth.Required() {
+		return false, ErrAuthRequired
+	}
+	return authed, nil
+}
+
+// Authentication errors
+var (
+	ErrAuthRequired = errors.New("unauthorized: method requires an API key or JWT")
+	ErrAuthFailed   = errors.New("unauthorized: invalid API key or JWT")
+	ErrAuthExpired  = errors.New("unauthorized: JWT expired or not yet valid")
+)
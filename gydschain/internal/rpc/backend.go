@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/consensus/pow"
+	"github.com/gydschain/gydschain/internal/p2p"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrBackendUnavailable is returned when a method needs a node component that isn't attached
+var ErrBackendUnavailable = errors.New("backend not available")
+
+// Backend holds the node components that RPC methods read from
+type Backend struct {
+	Chain    *chain.Chain
+	State    *state.StateDB
+	Engine   *pos.Engine
+	Slashing *pos.SlashingKeeper
+	P2P      *p2p.Node
+	Mempool  *tx.Mempool
+	Gossip   *p2p.TxGossip // relays submitted txs to peers
+	Miner    pow.Miner     // nil when mining is disabled
+
+	ExportDir string // where admin chain exports and snapshots are written; disabled if empty
+}
+
+// SetBackend attaches node components to the RPC methods
+func (m *Methods) SetBackend(backend *Backend) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backend = backend
+}
+
+// getBackend returns the attached backend or an error if none is set
+func (m *Methods) getBackend() (*Backend, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.backend == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return m.backend, nil
+}
+
+// SetBackend attaches node components to the server's RPC methods
+func (s *Server) SetBackend(backend *Backend) {
+	s.methods.SetBackend(backend)
+	s.attachEvents(backend)
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/p2p"
+)
+
+// ErrMissingBanTarget is returned when neither a peer ID nor an address is given
+var ErrMissingBanTarget = errors.New("peer_id or address required")
+
+// banList returns the P2P node's ban list
+func (m *Methods) banList() (*p2p.Node, *p2p.BanList, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+	if backend.P2P == nil || backend.P2P.BanList() == nil {
+		return nil, nil, p2p.ErrNoBanList
+	}
+	return backend.P2P, backend.P2P.BanList(), nil
+}
+
+func (m *Methods) getBanList(params json.RawMessage) (interface{}, error) {
+	_, bans, err := m.banList()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"bans":    bans.Entries(),
+		"allowed": bans.Allowed(),
+	}, nil
+}
+
+func (m *Methods) banPeer(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		PeerID   string `json:"peer_id"`
+		Address  string `json:"address"`
+		Reason   string `json:"reason"`
+		Duration uint64 `json:"duration"` // seconds
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	node, bans, err := m.banList()
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Duration(args.Duration) * time.Second
+
+	switch {
+	case args.PeerID != "":
+		return node.BanPeer(args.PeerID, args.Reason, duration)
+	case args.Address != "":
+		entry := bans.Ban(args.Address, "", args.Reason, duration)
+		node.EnforceBans()
+		return entry, nil
+	}
+	return nil, ErrMissingBanTarget
+}
+
+func (m *Methods) unbanPeer(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+	if args.Address == "" {
+		return nil, ErrMissingBanTarget
+	}
+
+	_, bans, err := m.banList()
+	if err != nil {
+		return nil, err
+	}
+	bans.Unban(args.Address)
+	return true, nil
+}
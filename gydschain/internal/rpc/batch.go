@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Batch request limits
+const (
+	DefaultMaxBatchSize = 100
+	batchConcurrency    = 16 // batch items executed at once
+)
+
+// SetMaxBatchSize limits the number of requests accepted in one batch;
+// zero or less selects the default
+func (s *Server) SetMaxBatchSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 {
+		n = DefaultMaxBatchSize
+	}
+	s.maxBatchSize = n
+}
+
+// handleBatch executes a JSON-RPC 2.0 batch concurrently and writes the
+// responses in request order. Notifications (no id) get no response.
+func (s *Server) handleBatch(ctx context.Context, w http.ResponseWriter, body []byte, authed bool) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		s.writeError(w, nil, ParseError, "Parse error")
+		return
+	}
+	if len(items) == 0 {
+		s.writeError(w, nil, InvalidRequest, "Invalid Request: empty batch")
+		return
+	}
+
+	s.mu.RLock()
+	maxBatch := s.maxBatchSize
+	s.mu.RUnlock()
+	if len(items) > maxBatch {
+		s.writeError(w, nil, InvalidRequest, "Invalid Request: batch exceeds max size")
+		return
+	}
+
+	responses := make([]*Response, len(items))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		var req Request
+		if err := json.Unmarshal(item, &req); err != nil || req.Method == "" {
+			responses[i] = &Response{
+				JSONRPC: "2.0",
+				Error:   &RPCError{Code: InvalidRequest, Message: "Invalid Request"},
+			}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, req Request) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp := s.call(ctx, req, authed)
+			if req.ID != nil {
+				responses[i] = &resp
+			}
+		}(i, req)
+	}
+	wg.Wait()
+
+	out := make([]*Response, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil {
+			out = append(out, resp)
+		}
+	}
+
+	// A batch of only notifications gets no body
+	if len(out) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// call executes one request and builds its response
+func (s *Server) call(ctx context.Context, req Request, authed bool) Response {
+	resp := Response{JSONRPC: "2.0", ID: req.ID}
+	start := time.Now()
+	result, err := s.methods.CallAs(req.Method, req.Params, authed)
+	logCall(ctx, req.Method, start, err)
+	if err != nil {
+		resp.Error = &RPCError{Code: errorCode(err), Message: err.Error()}
+	} else {
+		resp.Result = result
+	}
+	return resp
+}
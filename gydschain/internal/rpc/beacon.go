@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+)
+
+// ErrBeaconUnavailable is returned when no randomness beacon is attached
+var ErrBeaconUnavailable = errors.New("randomness beacon not configured")
+
+// getBeacon returns the beacon attached to the chain
+func (m *Methods) getBeacon() (*pos.RandomnessBeacon, *Backend, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+	if backend.Chain == nil || backend.Chain.Beacon() == nil {
+		return nil, nil, ErrBeaconUnavailable
+	}
+	return backend.Chain.Beacon(), backend, nil
+}
+
+func (m *Methods) getRandomness(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Epoch *uint64 `json:"epoch"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+	}
+
+	beacon, _, err := m.getBeacon()
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Epoch != nil {
+		return beacon.Randomness(*args.Epoch)
+	}
+	latest := beacon.Latest()
+	if latest == nil {
+		return nil, pos.ErrBeaconNotFinalized
+	}
+	return latest, nil
+}
+
+func (m *Methods) getBeaconStatus(params json.RawMessage) (interface{}, error) {
+	beacon, backend, err := m.getBeacon()
+	if err != nil {
+		return nil, err
+	}
+	// Contributions sent now land in the next block
+	return beacon.Status(backend.Chain.Height() + 1), nil
+}
@@ -0,0 +1,300 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// NodeClient is a JSON-RPC client for a node, used by the indexer and tooling
+type NodeClient struct {
+	url    string
+	http   *http.Client
+	nextID uint64
+	token  string // API key or JWT sent as a bearer token
+}
+
+// NewNodeClient creates a client for the node RPC endpoint at url
+func NewNodeClient(url string) *NodeClient {
+	return &NodeClient{
+		url:  url,
+		http: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// URL returns the endpoint the client calls
+func (c *NodeClient) URL() string {
+	return c.url
+}
+
+// SetToken sets the API key or JWT presented to the node, needed for admin
+// methods or on nodes that require authentication
+func (c *NodeClient) SetToken(token string) {
+	c.token = token
+}
+
+// Call invokes method with params and decodes its result into result,
+// which may be nil to discard it
+func (c *NodeClient) Call(method string, params interface{}, result interface{}) error {
+	var raw json.RawMessage
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		raw = data
+	}
+
+	body, err := json.Marshal(&Request{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  raw,
+		ID:      atomic.AddUint64(&c.nextID, 1),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// Error lets an RPCError returned by Call be used as an error
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// GetBlockHeight returns the node's latest block height
+func (c *NodeClient) GetBlockHeight() (uint64, error) {
+	var height uint64
+	err := c.Call("chain_getBlockHeight", nil, &height)
+	return height, err
+}
+
+// GetBlockByNumber returns the block at number with its transaction bodies
+func (c *NodeClient) GetBlockByNumber(number uint64) (*BlockResponse, error) {
+	var block BlockResponse
+	params := map[string]interface{}{"number": number, "full": true}
+	if err := c.Call("chain_getBlockByNumber", params, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetHeaders returns up to count raw headers starting at from, fewer if
+// the chain tip is reached
+func (c *NodeClient) GetHeaders(from, count uint64) ([]*HeaderResponse, error) {
+	var headers []*HeaderResponse
+	params := map[string]uint64{"from": from, "count": count}
+	if err := c.Call("chain_getHeaders", params, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// GetTransactionReceipt returns the receipt of an included transaction, or
+// nil if the node has none for hash
+func (c *NodeClient) GetTransactionReceipt(hash string) (*TransactionReceiptResponse, error) {
+	var receipt *TransactionReceiptResponse
+	if err := c.Call("tx_getTransactionReceipt", map[string]string{"hash": hash}, &receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetProof returns a Merkle proof of an account against the node's
+// committed state root
+func (c *NodeClient) GetProof(address string) (*AccountProofResponse, error) {
+	var proof AccountProofResponse
+	if err := c.Call("state_getProof", map[string]string{"address": address}, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// GetTxProof returns an inclusion proof for an executed transaction, or
+// nil if the node has no receipt for hash
+func (c *NodeClient) GetTxProof(hash string) (*TxProofResponse, error) {
+	var proof *TxProofResponse
+	if err := c.Call("tx_getProof", map[string]string{"hash": hash}, &proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// GetBalance returns an address's balance of asset, GYDS if empty, at
+// the node's latest state
+func (c *NodeClient) GetBalance(address, asset string) (*big.Int, error) {
+	var balance string
+	params := map[string]string{"address": address, "asset": asset}
+	if err := c.Call("account_getBalance", params, &balance); err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance %q", balance)
+	}
+	return amount, nil
+}
+
+// RegisterValidator submits address's signed registration as a validator
+// with its consensus public key and returns the transaction hash; the
+// client needs an admin token
+func (c *NodeClient) RegisterValidator(address, pubKey, signedTx string) (string, error) {
+	var hash string
+	params := map[string]string{"address": address, "pub_key": pubKey, "signed_tx": signedTx}
+	if err := c.Call("admin_registerValidator", params, &hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// GetEpoch returns the node's summary of a closed epoch
+func (c *NodeClient) GetEpoch(epoch uint64) (*pos.EpochSummary, error) {
+	var summary pos.EpochSummary
+	if err := c.Call("chain_getEpoch", map[string]uint64{"epoch": epoch}, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// GetLatestCheckpoint returns the node's highest checkpoint
+func (c *NodeClient) GetLatestCheckpoint() (*chain.Checkpoint, error) {
+	var cp chain.Checkpoint
+	if err := c.Call("chain_getCheckpoint", nil, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// SendTransaction submits a signed transaction and returns its hash
+func (c *NodeClient) SendTransaction(t *tx.Transaction) (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+
+	var hash string
+	params := map[string]string{"signedTx": hex.EncodeToString(data)}
+	if err := c.Call("tx_sendTransaction", params, &hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// GetPendingTransactions returns the transactions in the node's mempool
+func (c *NodeClient) GetPendingTransactions() ([]*TransactionResponse, error) {
+	var txs []*TransactionResponse
+	if err := c.Call("tx_getPendingTransactions", nil, &txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// SubscribePendingTransactions streams transactions entering the node's
+// mempool to fn over the node's WebSocket endpoint. It blocks until ctx is
+// done or the connection fails, returning the error that ended it.
+func (c *NodeClient) SubscribePendingTransactions(ctx context.Context, fn func(*TransactionResponse)) error {
+	wsURL := strings.TrimSuffix(c.url, "/") + "/ws"
+	if strings.HasPrefix(wsURL, "http") {
+		wsURL = "ws" + strings.TrimPrefix(wsURL, "http")
+	}
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read loop when ctx ends
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	params, _ := json.Marshal([]interface{}{SubPendingTransactions, PendingFilter{Full: true}})
+	err = conn.WriteJSON(&Request{
+		JSONRPC: "2.0",
+		Method:  "subscribe",
+		Params:  params,
+		ID:      atomic.AddUint64(&c.nextID, 1),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		var msg struct {
+			Method string    `json:"method"`
+			Error  *RPCError `json:"error"`
+			Params struct {
+				Result json.RawMessage `json:"result"`
+			} `json:"params"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if msg.Method != "subscription" {
+			continue // the subscribe response or a ping
+		}
+		var t TransactionResponse
+		if err := json.Unmarshal(msg.Params.Result, &t); err != nil || t.Hash == "" {
+			continue
+		}
+		fn(&t)
+	}
+}
@@ -0,0 +1,354 @@
+// Package client is a JSON-RPC client for GYDS Chain nodes. It pools
+// connections, retries transient failures with backoff and fails over
+// between node endpoints. methods_gen.go, generated by rpcgen from
+// api/rpc/methods.json, adds a typed wrapper for every node method.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// codeRateLimited is the node's rate limit error code (rpc.ErrRateLimited)
+const codeRateLimited = -32014
+
+// Client errors
+var (
+	ErrNoEndpoints = errors.New("no node endpoints configured")
+	ErrBadResponse = errors.New("malformed JSON-RPC response")
+)
+
+// request is a JSON-RPC 2.0 request
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      uint64          `json:"id"`
+}
+
+// Error is an error returned by the node itself. Apart from rate limiting
+// it is never retried: the node understood the request and refused it.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// statusError is a non-JSON HTTP failure, such as a proxy's 502
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("http status %d", e.code)
+}
+
+// decodeError is a result that does not match the expected type; asking
+// another endpoint would not change it
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return "decode result: " + e.err.Error()
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// Config configures a Client
+type Config struct {
+	Endpoints    []string      `json:"endpoints"`      // node RPC URLs in order of preference
+	Token        string        `json:"token"`          // API key or JWT sent as a bearer token
+	Timeout      time.Duration `json:"timeout"`        // per attempt; the caller's context bounds the whole call
+	MaxRetries   int           `json:"max_retries"`    // attempts after the first for transient failures
+	RetryBackoff time.Duration `json:"retry_backoff"`  // first retry delay, doubled per attempt
+	MaxBackoff   time.Duration `json:"max_backoff"`    // cap on the retry delay
+	MaxIdleConns int           `json:"max_idle_conns"` // pooled connections kept per endpoint
+}
+
+// DefaultConfig returns the default client configuration for endpoints
+func DefaultConfig(endpoints ...string) Config {
+	return Config{
+		Endpoints:    endpoints,
+		Timeout:      10 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: 250 * time.Millisecond,
+		MaxBackoff:   5 * time.Second,
+		MaxIdleConns: 16,
+	}
+}
+
+// EndpointStatus reports one endpoint's recent failures
+type EndpointStatus struct {
+	URL         string    `json:"url"`
+	Active      bool      `json:"active"`
+	Failures    uint64    `json:"failures"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastFailure time.Time `json:"last_failure,omitempty"`
+}
+
+// Client calls node methods over HTTP. Calls go to the active endpoint; a
+// transient failure moves the client to the next endpoint and the call is
+// retried there after a backoff. It is safe for concurrent use.
+type Client struct {
+	mu        sync.RWMutex
+	config    Config
+	http      *http.Client
+	endpoints []*EndpointStatus
+	active    int
+	nextID    uint64
+}
+
+// New creates a client for the configured endpoints
+func New(config Config) (*Client, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	defaults := DefaultConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+	if config.MaxBackoff < config.RetryBackoff {
+		config.MaxBackoff = config.RetryBackoff
+	}
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = defaults.MaxIdleConns
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: config.Timeout, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:        config.MaxIdleConns * len(config.Endpoints),
+		MaxIdleConnsPerHost: config.MaxIdleConns,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	c := &Client{
+		config: config,
+		http:   &http.Client{Transport: transport},
+	}
+	for _, url := range config.Endpoints {
+		c.endpoints = append(c.endpoints, &EndpointStatus{URL: url})
+	}
+	return c, nil
+}
+
+// SetToken sets the API key or JWT presented to every endpoint
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.Token = token
+}
+
+// Endpoints returns the state of every endpoint
+func (c *Client) Endpoints() []EndpointStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := make([]EndpointStatus, len(c.endpoints))
+	for i, endpoint := range c.endpoints {
+		status[i] = *endpoint
+		status[i].Active = i == c.active
+	}
+	return status
+}
+
+// Close releases pooled connections
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
+}
+
+// Call invokes method with params and decodes its result into result,
+// which may be nil to discard it. Transport failures, 5xx responses and
+// rate limiting are retried with backoff on the next endpoint until
+// MaxRetries is spent or ctx ends.
+func (c *Client) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	var raw json.RawMessage
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		raw = data
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt, lastErr); err != nil {
+				return err
+			}
+		}
+
+		index, url, token := c.current()
+		err := c.do(ctx, url, token, method, raw, result)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable(err) {
+			return err
+		}
+		c.failed(index, err)
+		lastErr = err
+	}
+	return fmt.Errorf("%s: %w", method, lastErr)
+}
+
+// do sends one request to url
+func (c *Client) do(ctx context.Context, url, token, method string, params json.RawMessage, result interface{}) error {
+	body, err := json.Marshal(&request{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      atomic.AddUint64(&c.nextID, 1),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &rpcResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &statusError{code: resp.StatusCode}
+		}
+		return ErrBadResponse
+	}
+	if rpcResp.Error != nil {
+		if rpcResp.Error.Code == codeRateLimited && rpcResp.Error.Data == nil {
+			// The HTTP limiter reports the delay in a header only
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				rpcResp.Error.Data, _ = json.Marshal(map[string]int{"retry_after": secs})
+			}
+		}
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return &decodeError{err: err}
+	}
+	return nil
+}
+
+// retryable reports whether a failed attempt may succeed on a retry
+func retryable(err error) bool {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == codeRateLimited
+	}
+	var decode *decodeError
+	if errors.As(err, &decode) {
+		return false
+	}
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code >= 500 || status.code == http.StatusTooManyRequests
+	}
+	// Transport failures and per-attempt timeouts; a malformed response
+	// from one endpoint may not recur on another
+	return true
+}
+
+// wait sleeps before a retry: exponential backoff with jitter, or the
+// node's requested delay when it rate limited the last attempt
+func (c *Client) wait(ctx context.Context, attempt int, lastErr error) error {
+	delay := c.config.RetryBackoff << uint(attempt-1)
+	if delay > c.config.MaxBackoff || delay <= 0 {
+		delay = c.config.MaxBackoff
+	}
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	var rpcErr *Error
+	if errors.As(lastErr, &rpcErr) && rpcErr.Code == codeRateLimited {
+		var data struct {
+			RetryAfter int64 `json:"retry_after"`
+		}
+		if json.Unmarshal(rpcErr.Data, &data) == nil && data.RetryAfter > 0 {
+			if after := time.Duration(data.RetryAfter) * time.Second; after > delay {
+				delay = after
+			}
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// current returns the active endpoint
+func (c *Client) current() (int, string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.active, c.endpoints[c.active].URL, c.config.Token
+}
+
+// failed records a failure against endpoint index and moves the client to
+// the next endpoint, unless another call already moved it
+func (c *Client) failed(index int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endpoint := c.endpoints[index]
+	endpoint.Failures++
+	endpoint.LastError = err.Error()
+	endpoint.LastFailure = time.Now()
+	if c.active == index {
+		c.active = (index + 1) % len(c.endpoints)
+	}
+}
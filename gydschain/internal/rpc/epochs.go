@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+)
+
+// ErrEpochsUnavailable is returned when no epoch tracker is attached
+var ErrEpochsUnavailable = errors.New("epoch summaries not configured")
+
+func (m *Methods) getEpoch(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Epoch *uint64 `json:"epoch"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil || backend.Chain.EpochTracker() == nil {
+		return nil, ErrEpochsUnavailable
+	}
+	epochs := backend.Chain.EpochTracker()
+
+	if args.Epoch != nil {
+		return epochs.Summary(*args.Epoch)
+	}
+	latest := epochs.Latest()
+	if latest == nil {
+		return nil, pos.ErrEpochNotFound
+	}
+	return latest, nil
+}
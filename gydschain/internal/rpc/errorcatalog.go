@@ -0,0 +1,39 @@
+package rpc
+
+import "encoding/json"
+
+//go:generate go run ../../cmd/errgen -out errorcatalog_gen.go
+
+// ErrorCode describes a JSON-RPC error code the node may return
+type ErrorCode struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ErrorMessage describes a chain error by the message text it carries in
+// an RPC error, for clients that map messages to localized strings
+type ErrorMessage struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+// ErrorCatalog lists every error code and chain error message
+type ErrorCatalog struct {
+	Codes    []ErrorCode    `json:"codes"`
+	Messages []ErrorMessage `json:"messages"`
+}
+
+// ErrorCodes returns the catalog generated from the error code constants
+// and internal/util/errors.go
+func ErrorCodes() *ErrorCatalog {
+	return &ErrorCatalog{
+		Codes:    append([]ErrorCode(nil), errorCatalog.Codes...),
+		Messages: append([]ErrorMessage(nil), errorCatalog.Messages...),
+	}
+}
+
+func (m *Methods) errorCodes(params json.RawMessage) (interface{}, error) {
+	return ErrorCodes(), nil
+}
@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"encoding/hex"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// newHeadResponse converts a block header for newHeads notifications
+func newHeadResponse(block *chain.Block, hash string) *BlockResponse {
+	resp := &BlockResponse{
+		Number:           block.Header.Height,
+		Hash:             hash,
+		ParentHash:       block.Header.ParentHash,
+		Timestamp:        uint64(block.Header.Timestamp),
+		Validator:        block.Validator,
+		StateRoot:        block.Header.StateRoot,
+		TransactionsRoot: block.Header.TxRoot,
+		ReceiptsRoot:     block.Header.ReceiptRoot,
+		Transactions:     make([]string, 0, len(block.Transactions)),
+		Size:             uint64(block.Size()),
+		GasUsed:          block.Header.GasUsed,
+		GasLimit:         block.Header.GasLimit,
+		BaseFee:          block.Header.BaseFee,
+		Burned:           util.CopyBig(block.Header.Burned).String(),
+	}
+	for _, t := range block.Transactions {
+		if txHash, err := t.HashHex(); err == nil {
+			resp.Transactions = append(resp.Transactions, txHash)
+		}
+	}
+	return resp
+}
+
+// newBlockResponse converts a block for RPC output, including transaction
+// bodies when full is set
+func newBlockResponse(block *chain.Block, full bool) (*BlockResponse, error) {
+	hash, err := block.Hash()
+	if err != nil {
+		return nil, err
+	}
+	resp := newHeadResponse(block, hash)
+	if full {
+		resp.FullTransactions = make([]TransactionResponse, 0, len(block.Transactions))
+		for _, t := range block.Transactions {
+			resp.FullTransactions = append(resp.FullTransactions, *newTransactionResponse(t))
+		}
+	}
+	return resp, nil
+}
+
+// newLogResponse converts an indexed log for RPC output
+func newLogResponse(l *chain.IndexedLog) *LogResponse {
+	return &LogResponse{
+		Address:     l.Address,
+		Topics:      l.Topics,
+		Data:        hex.EncodeToString(l.Data),
+		BlockNumber: l.BlockHeight,
+		TxHash:      l.TxHash,
+		TxIndex:     uint64(l.TxIndex),
+		BlockHash:   l.BlockHash,
+		LogIndex:    uint64(l.LogIndex),
+	}
+}
+
+// newReceiptResponse converts a transaction receipt for RPC output
+func newReceiptResponse(r *tx.TransactionReceipt) *TransactionReceiptResponse {
+	resp := &TransactionReceiptResponse{
+		TransactionHash: r.TxHash,
+		BlockHash:       r.BlockHash,
+		BlockNumber:     r.BlockHeight,
+		TxIndex:         uint64(r.Index),
+		From:            r.From,
+		To:              r.To,
+		Status:          uint64(r.Status),
+		GasUsed:         r.GasUsed,
+		Logs:            make([]LogResponse, 0, len(r.Logs)),
+	}
+	for i, l := range r.Logs {
+		resp.Logs = append(resp.Logs, LogResponse{
+			Address:     l.Address,
+			Topics:      l.Topics,
+			Data:        hex.EncodeToString(l.Data),
+			BlockNumber: r.BlockHeight,
+			TxHash:      r.TxHash,
+			TxIndex:     uint64(r.Index),
+			BlockHash:   r.BlockHash,
+			LogIndex:    uint64(i),
+		})
+	}
+	return resp
+}
+
+// attachEvents feeds chain, mempool and validator set events from the
+// backend into WebSocket subscriptions
+func (s *Server) attachEvents(backend *Backend) {
+	if backend.Chain != nil {
+		backend.Chain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+			s.subs.Broadcast(SubNewHeads, newHeadResponse(block, hash))
+			s.subs.BroadcastLogs(logs)
+		})
+	}
+	if backend.Mempool != nil {
+		backend.Mempool.OnAdd(func(t *tx.Transaction, hash string) {
+			s.subs.BroadcastPending(t, hash)
+		})
+	}
+	if backend.Engine != nil {
+		backend.Engine.OnValidatorSetChange(func(change *pos.ValidatorSetChange) {
+			s.subs.Broadcast(SubValidatorSet, change)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gydschain/gydschain/internal/chain"
+)
+
+// Log filter limits
+const (
+	DefaultFilterTimeout = 5 * time.Minute // a filter not polled for this long is removed
+	MaxLogFilters        = 1024
+)
+
+// Filter errors
+var (
+	ErrLogIndexDisabled = errors.New("log index not enabled")
+	ErrFilterNotFound   = errors.New("filter not found")
+	ErrTooManyFilters   = errors.New("too many installed filters")
+)
+
+// filterPrunedError reports that a filter fell behind the log index's
+// retention; its logs up to the oldest indexed block are lost and the
+// filter resumes from there
+type filterPrunedError struct {
+	resume uint64
+}
+
+func (e *filterPrunedError) Error() string {
+	return fmt.Sprintf("filter fell behind log index retention; logs before block %d were skipped and the filter resumes there", e.resume)
+}
+
+// logFilter is a filter installed by chain_newFilter. next is the first
+// block chain_getFilterChanges has not finished returning and skip the
+// matches in it already returned.
+type logFilter struct {
+	mu       sync.Mutex
+	filter   chain.LogFilter
+	next     uint64
+	skip     int
+	lastPoll time.Time
+}
+
+// FilterManager keeps the log filters of clients that poll over HTTP
+// instead of subscribing over WebSocket
+type FilterManager struct {
+	mu      sync.Mutex
+	filters map[string]*logFilter
+	timeout time.Duration
+}
+
+// NewFilterManager creates a filter manager removing filters idle for
+// timeout
+func NewFilterManager(timeout time.Duration) *FilterManager {
+	if timeout <= 0 {
+		timeout = DefaultFilterTimeout
+	}
+	return &FilterManager{
+		filters: make(map[string]*logFilter),
+		timeout: timeout,
+	}
+}
+
+// install adds a filter and returns its ID
+func (fm *FilterManager) install(lf *logFilter) (string, error) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	fm.expire()
+	if len(fm.filters) >= MaxLogFilters {
+		return "", ErrTooManyFilters
+	}
+
+	id := uuid.New().String()
+	lf.lastPoll = time.Now()
+	fm.filters[id] = lf
+	return id, nil
+}
+
+// get returns a filter and marks it polled
+func (fm *FilterManager) get(id string) (*logFilter, error) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	fm.expire()
+	lf, exists := fm.filters[id]
+	if !exists {
+		return nil, ErrFilterNotFound
+	}
+	lf.lastPoll = time.Now()
+	return lf, nil
+}
+
+// uninstall removes a filter, reporting whether it existed
+func (fm *FilterManager) uninstall(id string) bool {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	_, exists := fm.filters[id]
+	delete(fm.filters, id)
+	return exists
+}
+
+// expire removes idle filters; callers must hold fm.mu
+func (fm *FilterManager) expire() {
+	cutoff := time.Now().Add(-fm.timeout)
+	for id, lf := range fm.filters {
+		if lf.lastPoll.Before(cutoff) {
+			delete(fm.filters, id)
+		}
+	}
+}
+
+// logIndex returns the chain and its log index
+func (m *Methods) logIndex() (*chain.Chain, *chain.LogIndex, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+	if backend.Chain == nil || backend.Chain.LogIndex() == nil {
+		return nil, nil, ErrLogIndexDisabled
+	}
+	return backend.Chain, backend.Chain.LogIndex(), nil
+}
+
+// newLogResponses converts indexed logs for RPC output
+func newLogResponses(logs []*chain.IndexedLog) []LogResponse {
+	result := make([]LogResponse, 0, len(logs))
+	for _, l := range logs {
+		result = append(result, *newLogResponse(l))
+	}
+	return result
+}
+
+func (m *Methods) newFilter(params json.RawMessage) (interface{}, error) {
+	var filter chain.LogFilter
+	if err := json.Unmarshal(params, &filter); err != nil {
+		return nil, err
+	}
+	if filter.ToBlock != 0 && filter.ToBlock < filter.FromBlock {
+		return nil, chain.ErrLogRangeInvalid
+	}
+
+	c, _, err := m.logIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Changes are reported block by block, so a result limit would drop
+	// logs; without a start block the filter reports new blocks only
+	filter.Limit = 0
+	if filter.FromBlock == 0 {
+		filter.FromBlock = c.Height() + 1
+	}
+	return m.filters.install(&logFilter{filter: filter, next: filter.FromBlock})
+}
+
+func (m *Methods) getFilterChanges(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	lf, err := m.filters.get(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, index, err := m.logIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	// A filter polled too late to have its blocks still indexed skips to
+	// the oldest one, so the next poll succeeds
+	if oldest, _ := index.Range(); lf.next < oldest {
+		lf.next, lf.skip = oldest, 0
+		return nil, &filterPrunedError{resume: oldest}
+	}
+
+	to := c.Height()
+	if lf.filter.ToBlock != 0 && lf.filter.ToBlock < to {
+		to = lf.filter.ToBlock
+	}
+	if lf.next > to {
+		return []LogResponse{}, nil
+	}
+
+	// Each poll returns one page; a client that fell behind catches up
+	// over several polls
+	query := lf.filter
+	query.FromBlock, query.ToBlock = lf.next, to
+	logs, next, skip, err := index.Page(&query, lf.skip)
+	if err != nil {
+		return nil, err
+	}
+	lf.next, lf.skip = next, skip
+	return newLogResponses(logs), nil
+}
+
+func (m *Methods) getFilterLogs(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	lf, err := m.filters.get(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, index, err := m.logIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	query := lf.filter
+	if query.ToBlock == 0 {
+		query.ToBlock = c.Height()
+	}
+	if query.ToBlock < query.FromBlock {
+		return []LogResponse{}, nil
+	}
+
+	logs, err := index.Query(&query)
+	if err != nil {
+		return nil, err
+	}
+	return newLogResponses(logs), nil
+}
+
+func (m *Methods) uninstallFilter(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+	return m.filters.uninstall(args.ID), nil
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/gydschain/gydschain/internal/chain"
+)
+
+// MaxHeaderBatch caps the headers returned by one chain_getHeaders call
+const MaxHeaderBatch = 500
+
+// HeaderResponse is a raw block header with its proposer's signature, enough
+// for a light client to recompute the block hash and check the signature
+type HeaderResponse struct {
+	Hash      string        `json:"hash"`
+	Header    *chain.Header `json:"header"`
+	Validator string        `json:"validator"`
+	Signature string        `json:"signature"`
+}
+
+func (m *Methods) getHeaders(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		From  uint64 `json:"from"`
+		Count uint64 `json:"count"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+	if args.Count == 0 || args.Count > MaxHeaderBatch {
+		args.Count = MaxHeaderBatch
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	// Stop at the chain tip; an empty result means from is past it
+	headers := make([]*HeaderResponse, 0)
+	for height := args.From; height < args.From+args.Count; height++ {
+		block, err := backend.Chain.GetBlockByHeight(height)
+		if err == chain.ErrBlockNotFound {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		hash, err := block.Hash()
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, &HeaderResponse{
+			Hash:      hash,
+			Header:    block.Header,
+			Validator: block.Validator,
+			Signature: hex.EncodeToString(block.Signature),
+		})
+	}
+	return headers, nil
+}
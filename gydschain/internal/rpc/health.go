@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/p2p"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Node health states
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+)
+
+// Health check tuning
+const (
+	healthBlockWindow   = 100 // blocks used for the average block time
+	healthStallFactor   = 10  // last block older than this many targets means stalled
+	healthSlowFactor    = 2   // average above this many targets means slow
+	healthMaxPeerLag    = 5   // blocks behind before a peer counts as lagging
+	healthBacklogFactor = 0.8 // mempool fill ratio considered a backlog
+)
+
+// MempoolHealth describes the pending transaction backlog
+type MempoolHealth struct {
+	Pending       int     `json:"pending"`
+	Capacity      int     `json:"capacity"`
+	Bytes         int     `json:"bytes"`
+	FillRatio     float64 `json:"fill_ratio"`
+	OldestAgeSecs int64   `json:"oldest_age_secs"`
+
+	InclusionLatency *util.LatencyPercentiles `json:"inclusion_latency,omitempty"`
+}
+
+// NodeHealth is the node_healthDetail response
+type NodeHealth struct {
+	Status          string                   `json:"status"`
+	Problems        []string                 `json:"problems"`
+	Height          uint64                   `json:"height"`
+	FinalizedHeight uint64                   `json:"finalized_height"`
+	Halted          bool                     `json:"halted"`
+	BlockTime       *chain.BlockTimeStats    `json:"block_time,omitempty"`
+	Peers           *p2p.PeerQuality         `json:"peers,omitempty"`
+	Mempool         *MempoolHealth           `json:"mempool,omitempty"`
+	DBLatency       *util.LatencyPercentiles `json:"db_latency,omitempty"`
+	Clock           *util.ClockStatus        `json:"clock,omitempty"`
+}
+
+// healthDetail gathers diagnostics from whichever components are attached
+func (m *Methods) healthDetail() *NodeHealth {
+	health := &NodeHealth{Status: HealthHealthy, Problems: make([]string, 0)}
+	degrade := func(status, problem string) {
+		health.Problems = append(health.Problems, problem)
+		if status == HealthUnhealthy || health.Status == HealthHealthy {
+			health.Status = status
+		}
+	}
+
+	backend, err := m.getBackend()
+	if err != nil || backend.Chain == nil {
+		degrade(HealthUnhealthy, "chain not attached")
+		return health
+	}
+
+	c := backend.Chain
+	health.Height = c.Height()
+	health.FinalizedHeight = c.FinalizedHeight()
+	health.BlockTime = c.BlockTimeStats(healthBlockWindow)
+	health.DBLatency = c.ApplyLatency()
+
+	if breaker := c.CircuitBreaker(); breaker != nil && breaker.IsHalted() {
+		health.Halted = true
+		degrade(HealthUnhealthy, "chain halted by circuit breaker")
+	}
+
+	if target := health.BlockTime.TargetSecs; target > 0 && health.BlockTime.LastBlockTime > 0 {
+		if health.BlockTime.LastBlockAge > int64(target*healthStallFactor) {
+			degrade(HealthUnhealthy, "no new block recently; chain may be stalled")
+		} else if health.BlockTime.AverageSecs > float64(target*healthSlowFactor) {
+			degrade(HealthDegraded, "average block time well above target")
+		}
+	}
+
+	if backend.P2P != nil {
+		health.Peers = backend.P2P.PeerQuality(health.Height, healthMaxPeerLag)
+		if health.Peers.Total == 0 {
+			degrade(HealthDegraded, "no connected peers")
+		} else if health.Peers.Stale == health.Peers.Total {
+			degrade(HealthDegraded, "all peers are unresponsive")
+		}
+	}
+
+	if backend.Mempool != nil {
+		mp := &MempoolHealth{
+			Pending:       backend.Mempool.Size(),
+			Capacity:      backend.Mempool.Capacity(),
+			Bytes:         backend.Mempool.TotalBytes(),
+			OldestAgeSecs: int64(backend.Mempool.OldestAge().Seconds()),
+
+			InclusionLatency: backend.Mempool.InclusionStats().Latency,
+		}
+		if mp.Capacity > 0 {
+			mp.FillRatio = float64(mp.Pending) / float64(mp.Capacity)
+		}
+		health.Mempool = mp
+		if mp.FillRatio >= healthBacklogFactor {
+			degrade(HealthDegraded, "mempool backlog near capacity")
+		}
+	}
+
+	if backend.Engine != nil {
+		health.Clock = backend.Engine.ClockStatus()
+		if health.Clock != nil && health.Clock.Exceeded {
+			degrade(HealthDegraded, "local clock drift exceeds threshold; validation disabled")
+		}
+	}
+
+	return health
+}
+
+func (m *Methods) getHealthDetail(params json.RawMessage) (interface{}, error) {
+	return m.healthDetail(), nil
+}
+
+// handleHealth returns node health; unhealthy nodes answer 503 so load
+// balancers take them out of rotation
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	health := s.methods.healthDetail()
+
+	w.Header().Set("Content-Type", "application/json")
+	if health.Status == HealthUnhealthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
@@ -1,9 +1,20 @@
 package rpc
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	"sync"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pow"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
 )
 
 // MethodHandler is a function that handles an RPC method call
@@ -12,13 +23,26 @@ type MethodHandler func(params json.RawMessage) (interface{}, error)
 // Methods manages registered RPC methods
 type Methods struct {
 	handlers map[string]MethodHandler
+	writes   map[string]bool // methods that submit transactions or change node state
+	admin    map[string]bool // methods reserved to authenticated callers
+	readOnly bool
+	features tx.Features     // experimental namespaces served; others are hidden
+	enabled  map[string]bool // namespaces served; nil serves all
+	filters  *FilterManager  // log filters polled by chain_getFilterChanges
+	backend  *Backend
 	mu       sync.RWMutex
 }
 
+// ErrReadOnly is returned for state-changing methods on a read-only node
+var ErrReadOnly = errors.New("method disabled: node is in read-only mode")
+
 // NewMethods creates a new Methods instance
 func NewMethods() *Methods {
 	m := &Methods{
 		handlers: make(map[string]MethodHandler),
+		writes:   make(map[string]bool),
+		admin:    make(map[string]bool),
+		filters:  NewFilterManager(DefaultFilterTimeout),
 	}
 	m.registerBuiltins()
 	return m
@@ -31,58 +55,227 @@ func (m *Methods) Register(name string, handler MethodHandler) {
 	m.handlers[name] = handler
 }
 
-// Call calls a registered method
+// RegisterWrite registers a method that submits transactions, stakes, mines
+// or changes peer bans; such methods are refused when the node is read-only
+func (m *Methods) RegisterWrite(name string, handler MethodHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[name] = handler
+	m.writes[name] = true
+}
+
+// RegisterAdmin registers a method of the admin API. Admin methods change
+// node state, are served only to authenticated callers and belong to the
+// "admin" namespace whatever their prefix.
+func (m *Methods) RegisterAdmin(name string, handler MethodHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[name] = handler
+	m.writes[name] = true
+	m.admin[name] = true
+}
+
+// SetEnabledAPIs limits the namespaces served to apis; methods of other
+// namespaces are reported as not found. An empty list serves all.
+func (m *Methods) SetEnabledAPIs(apis []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(apis) == 0 {
+		m.enabled = nil
+		return
+	}
+	m.enabled = make(map[string]bool, len(apis))
+	for _, api := range apis {
+		m.enabled[strings.ToLower(strings.TrimSpace(api))] = true
+	}
+}
+
+// SetReadOnly enables or disables read-only mode
+func (m *Methods) SetReadOnly(readOnly bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readOnly = readOnly
+}
+
+// IsReadOnly returns true if write methods are disabled
+func (m *Methods) IsReadOnly() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.readOnly
+}
+
+// SetFeatures sets the experimental features whose RPC namespaces are served
+func (m *Methods) SetFeatures(features tx.Features) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.features = features
+}
+
+// Features returns the enabled experimental features
+func (m *Methods) Features() tx.Features {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.features
+}
+
+// exposed returns true unless the method belongs to a disabled
+// experimental namespace or to one left out of the enabled APIs; callers
+// must hold m.mu
+func (m *Methods) exposed(name string) bool {
+	namespace := name
+	if i := strings.Index(name, "_"); i >= 0 {
+		namespace = name[:i]
+	}
+	if !m.features.AllowsNamespace(namespace) {
+		return false
+	}
+	if m.admin[name] {
+		namespace = "admin"
+	}
+	return m.enabled == nil || m.enabled[namespace]
+}
+
+// Call calls a registered method on behalf of a trusted in-process caller
 func (m *Methods) Call(name string, params json.RawMessage) (interface{}, error) {
+	return m.CallAs(name, params, true)
+}
+
+// CallAs calls a registered method for a remote caller; admin methods are
+// refused unless the caller is authenticated
+func (m *Methods) CallAs(name string, params json.RawMessage, authenticated bool) (interface{}, error) {
 	m.mu.RLock()
 	handler, exists := m.handlers[name]
+	exists = exists && m.exposed(name)
+	disabled := m.readOnly && m.writes[name]
+	restricted := m.admin[name] && !authenticated
 	m.mu.RUnlock()
 
 	if !exists {
 		return nil, errors.New("method not found: " + name)
 	}
+	if restricted {
+		return nil, ErrAuthRequired
+	}
+	if disabled {
+		return nil, ErrReadOnly
+	}
 
 	return handler(params)
 }
 
+// List returns the names of all registered methods in sorted order
+func (m *Methods) List() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.handlers))
+	for name := range m.handlers {
+		if m.exposed(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // registerBuiltins registers built-in RPC methods
 func (m *Methods) registerBuiltins() {
 	// Chain methods
 	m.Register("chain_getBlockByNumber", m.getBlockByNumber)
 	m.Register("chain_getBlockByHash", m.getBlockByHash)
 	m.Register("chain_getLatestBlock", m.getLatestBlock)
+	m.Register("chain_getHeaders", m.getHeaders)
 	m.Register("chain_getBlockHeight", m.getBlockHeight)
 	m.Register("chain_getChainInfo", m.getChainInfo)
+	m.Register("chain_getLogs", m.getLogs)
+	m.Register("chain_getHaltStatus", m.getHaltStatus)
+	m.Register("chain_getEpoch", m.getEpoch)
+	m.Register("chain_getGasInfo", m.getGasInfo)
+	m.Register("chain_getFinalizedHead", m.getFinalizedHead)
+	m.Register("chain_getCheckpoint", m.getCheckpoint)
+	m.Register("chain_newFilter", m.newFilter)
+	m.Register("chain_getFilterChanges", m.getFilterChanges)
+	m.Register("chain_getFilterLogs", m.getFilterLogs)
+	m.Register("chain_uninstallFilter", m.uninstallFilter)
+	m.Register("chain_getDustPolicy", m.getDustPolicy)
 
 	// Account methods
 	m.Register("account_getBalance", m.getBalance)
 	m.Register("account_getNonce", m.getNonce)
+	m.Register("account_getPendingNonce", m.getPendingNonce)
+	m.Register("account_getPendingTransactions", m.getAccountPending)
 	m.Register("account_getAccount", m.getAccount)
+	m.Register("account_getStorageAt", m.getStorageAt)
+	m.Register("account_getCode", m.getCode)
+	m.Register("account_verifyMessage", m.verifyMessage)
+
+	// State methods
+	m.Register("state_getProof", m.getProof)
 
 	// Transaction methods
-	m.Register("tx_sendTransaction", m.sendTransaction)
+	m.RegisterWrite("tx_sendTransaction", m.sendTransaction)
 	m.Register("tx_getTransaction", m.getTransaction)
 	m.Register("tx_getTransactionReceipt", m.getTransactionReceipt)
+	m.Register("tx_getProof", m.getTxProof)
 	m.Register("tx_estimateFee", m.estimateFee)
+	m.Register("tx_feeHistory", m.feeHistory)
 	m.Register("tx_getPendingTransactions", m.getPendingTransactions)
+	m.Register("tx_getInclusionStats", m.getInclusionStats)
+	m.Register("tx_getInclusionInfo", m.getInclusionInfo)
+
+	// Randomness beacon methods
+	m.Register("beacon_getRandomness", m.getRandomness)
+	m.Register("beacon_getStatus", m.getBeaconStatus)
+
+	// Price oracle methods
+	m.Register("oracle_getStatus", m.getOracleStatus)
 
 	// Validator methods
 	m.Register("validator_getValidators", m.getValidators)
 	m.Register("validator_getValidator", m.getValidator)
-	m.Register("validator_stake", m.stake)
-	m.Register("validator_unstake", m.unstake)
+	m.Register("validator_getPayoutSplit", m.getPayoutSplit)
+	m.RegisterWrite("validator_stake", m.stake)
+	m.RegisterWrite("validator_unstake", m.unstake)
+
+	// Staking methods
+	m.Register("staking_getUnbondingDelegations", m.getUnbondingDelegations)
+	m.Register("staking_getPendingRewards", m.getPendingRewards)
+
+	// Stablecoin methods
+	m.Register("stablecoin_getStatus", m.getStablecoinStatus)
+	m.Register("stablecoin_getVault", m.getVault)
 
 	// Asset methods
 	m.Register("asset_getAsset", m.getAsset)
 	m.Register("asset_getAssetBalance", m.getAssetBalance)
-	m.Register("asset_transfer", m.transferAsset)
+	m.RegisterWrite("asset_transfer", m.transferAsset)
+
+	// NFT methods
+	m.Register("nft_getOwner", m.getNFTOwner)
+	m.Register("nft_getTokenURI", m.getNFTTokenURI)
 
 	// Network methods
 	m.Register("net_getPeers", m.getPeers)
 	m.Register("net_getNodeInfo", m.getNodeInfo)
+	m.Register("net_getBanList", m.getBanList)
+	m.RegisterAdmin("net_banPeer", m.banPeer)
+	m.RegisterAdmin("net_unbanPeer", m.unbanPeer)
+
+	// Node methods
+	m.Register("node_healthDetail", m.getHealthDetail)
+
+	// RPC metadata methods
+	m.Register("rpc_errorCodes", m.errorCodes)
+
+	// Admin methods
+	m.RegisterAdmin("admin_flushMempool", m.flushMempool)
+	m.RegisterAdmin("admin_registerValidator", m.registerValidator)
+	m.RegisterAdmin("admin_exportChain", m.exportChain)
+	m.RegisterAdmin("admin_createSnapshot", m.createSnapshot)
 
 	// Mining methods
-	m.Register("mining_getWork", m.getWork)
-	m.Register("mining_submitWork", m.submitWork)
+	m.RegisterWrite("mining_getWork", m.getWork)
+	m.RegisterWrite("mining_submitWork", m.submitWork)
 	m.Register("mining_getMiningInfo", m.getMiningInfo)
 }
 
@@ -90,12 +283,25 @@ func (m *Methods) registerBuiltins() {
 func (m *Methods) getBlockByNumber(params json.RawMessage) (interface{}, error) {
 	var args struct {
 		Number uint64 `json:"number"`
+		Full   bool   `json:"full,omitempty"`
 	}
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement block retrieval
-	return nil, errors.New("not implemented")
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	block, err := backend.Chain.GetBlockByHeight(args.Number)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockResponse(block, args.Full)
 }
 
 func (m *Methods) getBlockByHash(params json.RawMessage) (interface{}, error) {
@@ -115,29 +321,144 @@ func (m *Methods) getLatestBlock(params json.RawMessage) (interface{}, error) {
 }
 
 func (m *Methods) getBlockHeight(params json.RawMessage) (interface{}, error) {
-	// TODO: Implement block height retrieval
-	return nil, errors.New("not implemented")
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Chain.Height(), nil
 }
 
 func (m *Methods) getChainInfo(params json.RawMessage) (interface{}, error) {
+	halted := false
+	if backend, err := m.getBackend(); err == nil && backend.Chain != nil {
+		if breaker := backend.Chain.CircuitBreaker(); breaker != nil {
+			halted = breaker.IsHalted()
+		}
+	}
+
 	return map[string]interface{}{
 		"chainId":   "gydschain-1",
 		"networkId": 1,
 		"name":      "GYDS Chain",
+		"halted":    halted,
 	}, nil
 }
 
+func (m *Methods) getHaltStatus(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil || backend.Chain.CircuitBreaker() == nil {
+		return nil, errors.New("circuit breaker not configured")
+	}
+	return backend.Chain.CircuitBreaker().Status(), nil
+}
+
+func (m *Methods) getFinalizedHead(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Chain.FinalizedHead()
+}
+
+func (m *Methods) getCheckpoint(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Height *uint64 `json:"height"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	if args.Height != nil {
+		return backend.Chain.GetCheckpoint(*args.Height)
+	}
+	return backend.Chain.LatestCheckpoint()
+}
+
+func (m *Methods) getDustPolicy(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Chain.Dust().Status(), nil
+}
+
+func (m *Methods) getGasInfo(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Chain.Gas().Status(), nil
+}
+
+func (m *Methods) getLogs(params json.RawMessage) (interface{}, error) {
+	var filter chain.LogFilter
+	if err := json.Unmarshal(params, &filter); err != nil {
+		return nil, err
+	}
+
+	c, index, err := m.logIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Default to the latest block when no range is given
+	if filter.ToBlock == 0 {
+		filter.ToBlock = c.Height()
+		if filter.FromBlock == 0 {
+			filter.FromBlock = filter.ToBlock
+		}
+	}
+
+	logs, err := index.Query(&filter)
+	if err != nil {
+		return nil, err
+	}
+	return newLogResponses(logs), nil
+}
+
 // Account method implementations
 func (m *Methods) getBalance(params json.RawMessage) (interface{}, error) {
 	var args struct {
-		Address string `json:"address"`
-		Asset   string `json:"asset,omitempty"`
+		Address string  `json:"address"`
+		Asset   string  `json:"asset,omitempty"`
+		Height  *uint64 `json:"height,omitempty"`
 	}
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement balance retrieval
-	return nil, errors.New("not implemented")
+	if args.Asset == "" {
+		args.Asset = "GYDS"
+	}
+
+	account, err := m.accountAt(args.Address, args.Height)
+	if err != nil {
+		return nil, err
+	}
+	return account.GetBalance(args.Asset).String(), nil
 }
 
 func (m *Methods) getNonce(params json.RawMessage) (interface{}, error) {
@@ -147,25 +468,147 @@ func (m *Methods) getNonce(params json.RawMessage) (interface{}, error) {
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement nonce retrieval
-	return nil, errors.New("not implemented")
+
+	account, err := m.accountAt(args.Address, nil)
+	if err != nil {
+		return nil, err
+	}
+	return account.GetNonce(), nil
 }
 
-func (m *Methods) getAccount(params json.RawMessage) (interface{}, error) {
+func (m *Methods) getPendingNonce(params json.RawMessage) (interface{}, error) {
 	var args struct {
 		Address string `json:"address"`
 	}
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement account retrieval
-	return nil, errors.New("not implemented")
+
+	account, err := m.accountAt(args.Address, nil)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := m.getBackend()
+	if err != nil || backend.Mempool == nil {
+		return account.GetNonce(), nil
+	}
+	return backend.Mempool.PendingNonce(args.Address, account.GetNonce()), nil
+}
+
+func (m *Methods) getAccountPending(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil || backend.Mempool == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	pending := backend.Mempool.GetPending(args.Address)
+	txs := make([]*TransactionResponse, 0, len(pending))
+	for _, t := range pending {
+		txs = append(txs, newTransactionResponse(t))
+	}
+	return txs, nil
+}
+
+func (m *Methods) getAccount(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string  `json:"address"`
+		Height  *uint64 `json:"height,omitempty"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	account, err := m.accountAt(args.Address, args.Height)
+	if err != nil {
+		return nil, err
+	}
+	resp := newAccountResponse(account)
+	if status := account.VestingStatus(m.blockTimeAt(args.Height)); status != nil {
+		resp.Vesting = newVestingResponse(status)
+	}
+	return resp, nil
+}
+
+func (m *Methods) getStorageAt(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string  `json:"address"`
+		Key     string  `json:"key"`
+		Height  *uint64 `json:"height,omitempty"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	account, err := m.accountAt(args.Address, args.Height)
+	if err != nil {
+		return nil, err
+	}
+	return hex.EncodeToString(account.GetStorage(args.Key)), nil
+}
+
+func (m *Methods) getCode(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string  `json:"address"`
+		Height  *uint64 `json:"height,omitempty"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	account, err := m.accountAt(args.Address, args.Height)
+	if err != nil {
+		return nil, err
+	}
+	return hex.EncodeToString(account.GetCode()), nil
+}
+
+// verifyMessage checks a signed message offline, so it needs no chain state
+func (m *Methods) verifyMessage(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address   string `json:"address"`
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	err := crypto.VerifyMessage(args.Address, []byte(args.Message), args.Signature)
+	if err == crypto.ErrMessageSignerMismatch {
+		return false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return true, nil
 }
 
 // Transaction method implementations
 func (m *Methods) sendTransaction(params json.RawMessage) (interface{}, error) {
-	// TODO: Implement transaction sending
-	return nil, errors.New("not implemented")
+	var args struct {
+		SignedTx string `json:"signedTx"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := decodeSignedTx(args.SignedTx)
+	if err != nil {
+		return nil, err
+	}
+	return submitTx(backend, t)
 }
 
 func (m *Methods) getTransaction(params json.RawMessage) (interface{}, error) {
@@ -186,8 +629,24 @@ func (m *Methods) getTransactionReceipt(params json.RawMessage) (interface{}, er
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement receipt retrieval
-	return nil, errors.New("not implemented")
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	// Transactions not yet in a block have no receipt and return null
+	receipt, err := backend.Chain.GetReceipt(args.Hash)
+	if err == chain.ErrReceiptNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newReceiptResponse(receipt), nil
 }
 
 func (m *Methods) estimateFee(params json.RawMessage) (interface{}, error) {
@@ -195,9 +654,72 @@ func (m *Methods) estimateFee(params json.RawMessage) (interface{}, error) {
 	return nil, errors.New("not implemented")
 }
 
+// defaultFeeHistoryBlocks is how many blocks tx_feeHistory covers by default
+const defaultFeeHistoryBlocks = 20
+
+func (m *Methods) feeHistory(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Blocks int `json:"blocks"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+	}
+	if args.Blocks <= 0 {
+		args.Blocks = defaultFeeHistoryBlocks
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Chain.Gas().FeeHistory(args.Blocks), nil
+}
+
 func (m *Methods) getPendingTransactions(params json.RawMessage) (interface{}, error) {
-	// TODO: Implement pending tx retrieval
-	return nil, errors.New("not implemented")
+	backend, err := m.getBackend()
+	if err != nil || backend.Mempool == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	pending := backend.Mempool.GetAll()
+	txs := make([]*TransactionResponse, 0, len(pending))
+	for _, t := range pending {
+		txs = append(txs, newTransactionResponse(t))
+	}
+	return txs, nil
+}
+
+func (m *Methods) getInclusionStats(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil || backend.Mempool == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Mempool.InclusionStats(), nil
+}
+
+func (m *Methods) getInclusionInfo(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil || backend.Mempool == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	record, ok := backend.Mempool.InclusionInfo(args.Hash)
+	if !ok {
+		return nil, errors.New("transaction not tracked by mempool")
+	}
+	return record, nil
 }
 
 // Validator method implementations
@@ -217,6 +739,24 @@ func (m *Methods) getValidator(params json.RawMessage) (interface{}, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *Methods) getPayoutSplit(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Engine == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Engine.PayoutSplit(args.Address)
+}
+
 func (m *Methods) stake(params json.RawMessage) (interface{}, error) {
 	// TODO: Implement staking
 	return nil, errors.New("not implemented")
@@ -227,28 +767,105 @@ func (m *Methods) unstake(params json.RawMessage) (interface{}, error) {
 	return nil, errors.New("not implemented")
 }
 
+// Staking method implementations
+func (m *Methods) getUnbondingDelegations(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Delegator string `json:"delegator"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil || backend.Chain.Unbonding() == nil {
+		return nil, errors.New("unbonding queue not configured")
+	}
+	return backend.Chain.Unbonding().Delegations(args.Delegator), nil
+}
+
+func (m *Methods) getPendingRewards(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Delegator string `json:"delegator"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Engine == nil {
+		return nil, ErrBackendUnavailable
+	}
+	return backend.Engine.PendingRewards(args.Delegator), nil
+}
+
+// Stablecoin method implementations
+func (m *Methods) getStablecoinStatus(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil || backend.Chain.Stablecoin() == nil {
+		return nil, chain.ErrStablecoinNotConfigured
+	}
+	return backend.Chain.Stablecoin().Status(backend.Chain.Height()), nil
+}
+
+func (m *Methods) getVault(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Owner string `json:"owner"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil || backend.Chain.Stablecoin() == nil {
+		return nil, chain.ErrStablecoinNotConfigured
+	}
+	return backend.Chain.Stablecoin().Vault(args.Owner, backend.Chain.Height())
+}
+
 // Asset method implementations
 func (m *Methods) getAsset(params json.RawMessage) (interface{}, error) {
 	var args struct {
-		AssetID string `json:"assetId"`
+		AssetID string  `json:"assetId"`
+		Height  *uint64 `json:"height,omitempty"`
 	}
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement asset retrieval
-	return nil, errors.New("not implemented")
+
+	asset, err := m.assetAt(args.AssetID, args.Height)
+	if err != nil {
+		return nil, err
+	}
+	return newAssetResponse(asset), nil
 }
 
 func (m *Methods) getAssetBalance(params json.RawMessage) (interface{}, error) {
 	var args struct {
-		Address string `json:"address"`
-		AssetID string `json:"assetId"`
+		Address string  `json:"address"`
+		AssetID string  `json:"assetId"`
+		Height  *uint64 `json:"height,omitempty"`
 	}
 	if err := json.Unmarshal(params, &args); err != nil {
 		return nil, err
 	}
-	// TODO: Implement asset balance retrieval
-	return nil, errors.New("not implemented")
+
+	account, err := m.accountAt(args.Address, args.Height)
+	if err != nil {
+		return nil, err
+	}
+	return account.GetBalance(args.AssetID).String(), nil
 }
 
 func (m *Methods) transferAsset(params json.RawMessage) (interface{}, error) {
@@ -266,21 +883,97 @@ func (m *Methods) getNodeInfo(params json.RawMessage) (interface{}, error) {
 	return map[string]interface{}{
 		"version":  "0.1.0",
 		"protocol": "gyds/1",
+		"readOnly": m.IsReadOnly(),
+		"features": m.Features().Names(),
 	}, nil
 }
 
 // Mining method implementations
+// ErrNoExternalMiner is returned by getwork methods unless the node runs
+// the external miner backend
+var ErrNoExternalMiner = errors.New("mining backend does not serve external miners")
+
+// externalMiner returns the node's external miner bridge
+func (m *Methods) externalMiner() (*Backend, *pow.ExternalMiner, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+	if backend.Chain == nil {
+		return nil, nil, ErrBackendUnavailable
+	}
+	bridge, ok := backend.Miner.(*pow.ExternalMiner)
+	if !ok {
+		return nil, nil, ErrNoExternalMiner
+	}
+	return backend, bridge, nil
+}
+
 func (m *Methods) getWork(params json.RawMessage) (interface{}, error) {
-	// TODO: Implement mining work retrieval
-	return nil, errors.New("not implemented")
+	backend, bridge, err := m.externalMiner()
+	if err != nil {
+		return nil, err
+	}
+	blockData, target, err := bridge.Work()
+	if err != nil {
+		return nil, err
+	}
+	return &WorkResponse{
+		BlockHeader: hex.EncodeToString(blockData),
+		Target:      fmt.Sprintf("%064x", target),
+		Height:      backend.Chain.Height() + 1,
+	}, nil
 }
 
 func (m *Methods) submitWork(params json.RawMessage) (interface{}, error) {
-	// TODO: Implement work submission
-	return nil, errors.New("not implemented")
+	var args struct {
+		Height uint64 `json:"height"`
+		Nonce  uint64 `json:"nonce"`
+		Hash   string `json:"hash"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, bridge, err := m.externalMiner()
+	if err != nil {
+		return nil, err
+	}
+	if args.Height != backend.Chain.Height()+1 {
+		return nil, pow.ErrStaleWork
+	}
+	result, err := bridge.Submit("getwork", args.Nonce)
+	if err == pow.ErrInvalidPoW {
+		return false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return args.Hash == "" || strings.EqualFold(strings.TrimPrefix(args.Hash, "0x"), result.Hash), nil
 }
 
 func (m *Methods) getMiningInfo(params json.RawMessage) (interface{}, error) {
-	// TODO: Implement mining info retrieval
-	return nil, errors.New("not implemented")
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	difficulty := backend.Chain.Difficulty()
+	info := &MiningInfoResponse{
+		Difficulty:     strconv.FormatUint(difficulty.Difficulty, 10),
+		NextDifficulty: strconv.FormatUint(difficulty.NextDifficulty, 10),
+		CurrentBlock:   difficulty.Height,
+		RewardPerBlock: pow.NewRewardDistributor(pow.DefaultRewardConfig()).CalculateBlockReward(difficulty.Height + 1).String(),
+	}
+	if backend.Mempool != nil {
+		info.PendingTxCount = uint64(backend.Mempool.Size())
+	}
+	if backend.Miner != nil {
+		info.Mining = backend.Miner.IsRunning()
+		info.Hashrate = backend.Miner.GetHashRate()
+	}
+	return info, nil
 }
@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+)
+
+// MonitoringResponse is served at /monitoring/validators for external
+// validator-alerting tools
+type MonitoringResponse struct {
+	Height     uint64                `json:"height"`
+	Round      uint64                `json:"round"`
+	Halted     bool                  `json:"halted"`
+	Validators []pos.ValidatorReport `json:"validators"`
+}
+
+// handleMonitoringValidators reports missed-block streaks, jail status and
+// the next proposal slot for every validator. An optional ?address= filter
+// limits the output to one validator, and ?lookahead= bounds the proposal search
+// up to pos.MaxProposalLookahead rounds.
+func (s *Server) handleMonitoringValidators(w http.ResponseWriter, r *http.Request) {
+	backend, err := s.methods.getBackend()
+	if err != nil || backend.Slashing == nil {
+		http.Error(w, ErrBackendUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	var lookahead uint64
+	if v := r.URL.Query().Get("lookahead"); v != "" {
+		lookahead, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || lookahead > pos.MaxProposalLookahead {
+			http.Error(w, "invalid lookahead: must be at most "+strconv.Itoa(pos.MaxProposalLookahead), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := MonitoringResponse{Validators: backend.Slashing.MonitoringReport(lookahead)}
+	if backend.Chain != nil {
+		resp.Height = backend.Chain.Height()
+		if breaker := backend.Chain.CircuitBreaker(); breaker != nil {
+			resp.Halted = breaker.IsHalted()
+		}
+	}
+	if backend.Engine != nil {
+		resp.Round = backend.Engine.CurrentRound()
+	}
+
+	if address := r.URL.Query().Get("address"); address != "" {
+		filtered := make([]pos.ValidatorReport, 0, 1)
+		for _, v := range resp.Validators {
+			if v.Address == address {
+				filtered = append(filtered, v)
+			}
+		}
+		resp.Validators = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
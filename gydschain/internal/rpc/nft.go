@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"encoding/json"
+
+	"github.com/gydschain/gydschain/internal/state"
+)
+
+// nftArgs identifies a token, optionally as of a past height
+type nftArgs struct {
+	Collection string  `json:"collection"`
+	TokenID    string  `json:"tokenId"`
+	Height     *uint64 `json:"height,omitempty"`
+}
+
+// nftAt returns a token as of height, or the latest state if height is nil
+func (m *Methods) nftAt(params json.RawMessage) (*state.Asset, error) {
+	var args nftArgs
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	token, err := m.assetAt(state.NFTAssetID(args.Collection, args.TokenID), args.Height)
+	if err == state.ErrAssetNotFound || (err == nil && token.Type != state.AssetTypeNFT) {
+		return nil, state.ErrNFTNotFound
+	}
+	return token, err
+}
+
+func (m *Methods) getNFTOwner(params json.RawMessage) (interface{}, error) {
+	token, err := m.nftAt(params)
+	if err != nil {
+		return nil, err
+	}
+	return token.Owner, nil
+}
+
+func (m *Methods) getNFTTokenURI(params json.RawMessage) (interface{}, error) {
+	token, err := m.nftAt(params)
+	if err != nil {
+		return nil, err
+	}
+	if token.Metadata == nil {
+		return "", nil
+	}
+	return token.Metadata.TokenURI, nil
+}
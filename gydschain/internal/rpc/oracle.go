@@ -0,0 +1,21 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrOracleUnavailable is returned when no price oracle is attached
+var ErrOracleUnavailable = errors.New("price oracle not configured")
+
+func (m *Methods) getOracleStatus(params json.RawMessage) (interface{}, error) {
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil || backend.Chain.Oracle() == nil {
+		return nil, ErrOracleUnavailable
+	}
+	// Votes sent now land in the next block
+	return backend.Chain.Oracle().Status(backend.Chain.Height() + 1), nil
+}
@@ -0,0 +1,36 @@
+package rpc
+
+import (
+	"encoding/hex"
+
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// newTransactionResponse converts a transaction for RPC output, decoding
+// its typed payload so clients don't have to parse Data themselves
+func newTransactionResponse(t *tx.Transaction) *TransactionResponse {
+	hash, _ := t.HashHex()
+	resp := &TransactionResponse{
+		Hash:      hash,
+		Nonce:     t.Nonce,
+		From:      t.From,
+		To:        t.To,
+		Value:     util.CopyBig(t.Amount).String(),
+		Asset:     t.Asset,
+		Fee:       util.CopyBig(t.Fee).String(),
+		Memo:      t.Memo,
+		Signature: hex.EncodeToString(t.Signature),
+		Type:      t.Type,
+	}
+	for _, sig := range t.Signatures {
+		resp.Signatures = append(resp.Signatures, MultisigSignatureResponse{PubKey: sig.PubKey, Signature: sig.Signature})
+	}
+	if len(t.Data) > 0 {
+		resp.Data = hex.EncodeToString(t.Data)
+	}
+	if payload, err := tx.DecodePayload(t); err == nil && payload != nil {
+		resp.Payload = payload
+	}
+	return resp
+}
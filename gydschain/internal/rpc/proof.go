@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/gydschain/gydschain/internal/state"
+)
+
+// getProof returns a Merkle proof of an account against the committed state
+func (m *Methods) getProof(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil || backend.State == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	proof, err := backend.State.GetProof(args.Address)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountProofResponse(proof), nil
+}
+
+// newAccountProofResponse converts an account proof for RPC output
+func newAccountProofResponse(proof *state.AccountStateProof) *AccountProofResponse {
+	resp := &AccountProofResponse{
+		Address: proof.Address,
+		Key:     proof.Proof.Key,
+		Value:   hex.EncodeToString(proof.Proof.Value),
+		Proof:   make([]string, len(proof.Proof.Proof)),
+		Root:    proof.Proof.Root,
+		Height:  proof.Proof.Height,
+	}
+	if proof.Account != nil {
+		resp.Account = newAccountResponse(proof.Account)
+	}
+	for i, node := range proof.Proof.Proof {
+		resp.Proof[i] = hex.EncodeToString(node)
+	}
+	return resp
+}
+
+// StateProof converts a proof received over RPC back into the form
+// state.VerifyAccountProof checks
+func (r *AccountProofResponse) StateProof() (*state.AccountStateProof, error) {
+	proof := &state.StateProof{
+		Key:    r.Key,
+		Proof:  make([][]byte, len(r.Proof)),
+		Root:   r.Root,
+		Height: r.Height,
+	}
+	var err error
+	if r.Value != "" {
+		if proof.Value, err = hex.DecodeString(r.Value); err != nil {
+			return nil, state.ErrInvalidProof
+		}
+	}
+	for i, node := range r.Proof {
+		if proof.Proof[i], err = hex.DecodeString(node); err != nil {
+			return nil, state.ErrInvalidProof
+		}
+	}
+	return &state.AccountStateProof{Address: r.Address, Proof: proof}, nil
+}
@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request limits
+const (
+	DefaultMaxBodySize = 1 << 20 // bytes of one HTTP body or WebSocket message
+
+	idleBucket = 10 * time.Minute // how long an unused bucket is kept before it is swept
+)
+
+// bucket is a token bucket for one client IP
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps a token bucket per client IP
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens added per second; zero or less disables limiting
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &rateLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token from the client's bucket. If it is empty it returns
+// false and how long until the next token.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l == nil || l.rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > idleBucket {
+		for id, b := range l.buckets {
+			if now.Sub(b.last) > idleBucket {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// SetRateLimit limits each client IP to rate requests per second with
+// bursts of up to burst; a rate of zero or less disables limiting. A batch
+// counts as one request, its size being capped separately.
+func (s *Server) SetRateLimit(rate float64, burst int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limiter = newRateLimiter(rate, burst)
+}
+
+// SetMaxBodySize limits the bytes of an HTTP request body or WebSocket
+// message; zero or less selects the default
+func (s *Server) SetMaxBodySize(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 {
+		n = DefaultMaxBodySize
+	}
+	s.maxBodySize = n
+}
+
+// SetTrustProxy identifies clients by the first X-Forwarded-For address;
+// only enable it behind a proxy that sets the header
+func (s *Server) SetTrustProxy(trust bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trustProxy = trust
+}
+
+// limits returns the current rate limiter and body size limit
+func (s *Server) limits() (*rateLimiter, int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.limiter, s.maxBodySize
+}
+
+// limitMiddleware applies the per-IP rate limit to every request but health
+// checks, answering 429 with Retry-After when a client's bucket is empty
+func (s *Server) limitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limiter, _ := s.limits()
+		if ok, wait := limiter.allow(s.clientIP(r)); !ok {
+			w.Header().Set("Retry-After", retryAfter(wait))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			s.writeError(w, nil, ErrRateLimited, ErrRateLimitExceeded.Error())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP identifies a caller, using the first X-Forwarded-For address
+// when the server runs behind a trusted proxy
+func (s *Server) clientIP(r *http.Request) string {
+	s.mu.RLock()
+	trustProxy := s.trustProxy
+	s.mu.RUnlock()
+
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// retryAfter formats wait as whole seconds for the Retry-After header
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+// ErrRateLimitExceeded is returned when a client's request bucket is empty
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
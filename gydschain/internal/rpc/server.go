@@ -1,16 +1,29 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
+	"math"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/logging"
+	"github.com/gydschain/gydschain/internal/tx"
 )
 
+// logger is the rpc module logger
+var logger = logging.Module("rpc")
+
 // Server represents the JSON-RPC server
 type Server struct {
 	addr       string
@@ -19,16 +32,24 @@ type Server struct {
 	methods    *Methods
 	subs       *SubscriptionManager
 	upgrader   websocket.Upgrader
+	auth       *Authenticator
+	limiter    *rateLimiter // per-IP request rate; nil is unlimited
+	trustProxy bool         // identify clients by X-Forwarded-For
 	mu         sync.RWMutex
+
+	maxBatchSize int   // requests accepted in one JSON-RPC batch
+	maxBodySize  int64 // bytes of an HTTP body or WebSocket message
 }
 
 // NewServer creates a new RPC server
 func NewServer(addr string) *Server {
 	s := &Server{
-		addr:    addr,
-		router:  mux.NewRouter(),
-		methods: NewMethods(),
-		subs:    NewSubscriptionManager(),
+		addr:         addr,
+		router:       mux.NewRouter(),
+		methods:      NewMethods(),
+		subs:         NewSubscriptionManager(),
+		maxBatchSize: DefaultMaxBatchSize,
+		maxBodySize:  DefaultMaxBodySize,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true // Allow all origins for now
@@ -44,34 +65,70 @@ func (s *Server) setupRoutes() {
 	s.router.HandleFunc("/", s.handleRPC).Methods("POST")
 	s.router.HandleFunc("/ws", s.handleWebSocket)
 	s.router.HandleFunc("/health", s.handleHealth).Methods("GET")
+	s.router.HandleFunc("/monitoring/validators", s.handleMonitoringValidators).Methods("GET")
 }
 
 // Start starts the RPC server
 func (s *Server) Start() error {
 	s.httpServer = &http.Server{
 		Addr:    s.addr,
-		Handler: s.router,
+		Handler: s.Handler(),
 	}
-	fmt.Printf("RPC server starting on %s\n", s.addr)
+	logger.Info("RPC server starting", "addr", s.addr)
 	return s.httpServer.ListenAndServe()
 }
 
+// Handler returns the HTTP handler serving the RPC routes, with the
+// per-IP rate limit applied and each request tagged with a request ID
+func (s *Server) Handler() http.Handler {
+	return logging.RequestIDMiddleware(s.limitMiddleware(s.router))
+}
+
 // Stop gracefully stops the server
 func (s *Server) Stop(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
 
-// handleRPC handles JSON-RPC requests
+// handleRPC handles JSON-RPC requests, single or batched
 func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	_, maxBody := s.limits()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			s.writeError(w, nil, InvalidRequest, "Invalid Request: body exceeds max size")
+			return
+		}
+		s.writeError(w, nil, ParseError, "Parse error")
+		return
+	}
+
+	authed, err := s.authenticate(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		s.writeError(w, nil, ErrUnauthorized, err.Error())
+		return
+	}
+
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		s.handleBatch(r.Context(), w, trimmed, authed)
+		return
+	}
+
 	var req Request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, nil, -32700, "Parse error")
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, nil, ParseError, "Parse error")
 		return
 	}
 
-	result, err := s.methods.Call(req.Method, req.Params)
+	start := time.Now()
+	result, err := s.methods.CallAs(req.Method, req.Params, authed)
+	logCall(r.Context(), req.Method, start, err)
 	if err != nil {
-		s.writeError(w, req.ID, -32601, err.Error())
+		s.writeError(w, req.ID, errorCode(err), err.Error())
 		return
 	}
 
@@ -80,6 +137,12 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 
 // handleWebSocket handles WebSocket connections for subscriptions
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	authed, err := s.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
@@ -89,53 +152,145 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	clientID := s.subs.AddClient(conn)
 	defer s.subs.RemoveClient(clientID)
 
+	limiter, maxBody := s.limits()
+	client := s.clientIP(r)
+	conn.SetReadLimit(maxBody)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
 	for {
 		var req Request
 		if err := conn.ReadJSON(&req); err != nil {
 			break
 		}
 
+		resp := Response{JSONRPC: "2.0", ID: req.ID}
+		if ok, wait := limiter.allow(client); !ok {
+			resp.Error = &RPCError{
+				Code:    ErrRateLimited,
+				Message: ErrRateLimitExceeded.Error(),
+				Data:    map[string]int64{"retry_after": int64(math.Ceil(wait.Seconds()))},
+			}
+			if !s.subs.Send(clientID, resp) {
+				break
+			}
+			continue
+		}
+
+		start := time.Now()
+		var result interface{}
 		switch req.Method {
 		case "subscribe":
-			s.handleSubscribe(clientID, req)
+			result, err = s.handleSubscribe(clientID, req)
 		case "unsubscribe":
-			s.handleUnsubscribe(clientID, req)
+			result, err = s.handleUnsubscribe(clientID, req)
 		default:
-			result, err := s.methods.Call(req.Method, req.Params)
-			if err != nil {
-				conn.WriteJSON(Response{
-					JSONRPC: "2.0",
-					ID:      req.ID,
-					Error:   &RPCError{Code: -32601, Message: err.Error()},
-				})
-			} else {
-				conn.WriteJSON(Response{
-					JSONRPC: "2.0",
-					ID:      req.ID,
-					Result:  result,
-				})
-			}
+			result, err = s.methods.CallAs(req.Method, req.Params, authed)
 		}
+		logCall(r.Context(), req.Method, start, err)
+
+		if err != nil {
+			resp.Error = &RPCError{Code: errorCode(err), Message: err.Error()}
+		} else {
+			resp.Result = result
+		}
+		if !s.subs.Send(clientID, resp) {
+			break
+		}
+	}
+}
+
+// handleSubscribe handles subscription requests. Params follow the
+// eth_subscribe shape: ["logs", {"addresses": [...], "topics": [...]}] or
+// ["pendingTransactions", {"full": true}]
+func (s *Server) handleSubscribe(clientID string, req Request) (interface{}, error) {
+	var args []json.RawMessage
+	if err := json.Unmarshal(req.Params, &args); err != nil || len(args) == 0 {
+		return nil, errInvalidSubscribeParams
+	}
+
+	var subType SubscriptionType
+	if err := json.Unmarshal(args[0], &subType); err != nil {
+		return nil, errInvalidSubscribeParams
+	}
+
+	var filter interface{}
+	if subType == SubLogs && len(args) > 1 {
+		logFilter := &chain.LogFilter{}
+		if err := json.Unmarshal(args[1], logFilter); err != nil {
+			return nil, errInvalidSubscribeParams
+		}
+		filter = logFilter
+	}
+	if subType == SubPendingTransactions && len(args) > 1 {
+		pendingFilter := &PendingFilter{}
+		if err := json.Unmarshal(args[1], pendingFilter); err != nil {
+			return nil, errInvalidSubscribeParams
+		}
+		filter = pendingFilter
 	}
+
+	return s.subs.Subscribe(clientID, subType, filter)
 }
 
-// handleSubscribe handles subscription requests
-func (s *Server) handleSubscribe(clientID string, req Request) {
-	// Parse subscription type from params
-	// Add subscription for client
+// handleUnsubscribe handles unsubscription requests: ["<subscription id>"]
+func (s *Server) handleUnsubscribe(clientID string, req Request) (interface{}, error) {
+	var args []string
+	if err := json.Unmarshal(req.Params, &args); err != nil || len(args) != 1 {
+		return nil, errInvalidSubscribeParams
+	}
+	return s.subs.Unsubscribe(clientID, args[0]), nil
 }
 
-// handleUnsubscribe handles unsubscription requests
-func (s *Server) handleUnsubscribe(clientID string, req Request) {
-	// Remove subscription for client
+// SetReadOnly disables transaction submission, staking and mining methods
+// for every transport served by this server
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.methods.SetReadOnly(readOnly)
 }
 
-// handleHealth returns server health status
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-	})
+// SetFeatures sets the experimental features whose RPC namespaces are
+// served; methods of disabled features are reported as not found
+func (s *Server) SetFeatures(features tx.Features) {
+	s.methods.SetFeatures(features)
+}
+
+// logCall logs a method call at debug level, tagged with the request ID
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	if err != nil {
+		logger.DebugContext(ctx, "rpc call failed", "method", method, "duration", time.Since(start), "err", err)
+		return
+	}
+	logger.DebugContext(ctx, "rpc call", "method", method, "duration", time.Since(start))
+}
+
+// errorCode maps a method error to a JSON-RPC error code
+func errorCode(err error) int {
+	switch err {
+	case ErrReadOnly:
+		return ErrMethodDisabled
+	case ErrAuthRequired, ErrAuthFailed, ErrAuthExpired:
+		return ErrUnauthorized
+	case chain.ErrBlockNotFound:
+		return ErrBlockNotFound
+	case pos.ErrInsufficientStake:
+		return ErrMinimumStake
+	case pos.ErrValidatorNotFound:
+		return ErrValidatorNotFound
+	case errInvalidSubscribeParams, ErrUnknownSubscription, ErrTooManySubscriptions, ErrMissingBanTarget, crypto.ErrInvalidMessageSignature:
+		return InvalidParams
+	case ErrFilterNotFound, ErrTooManyFilters, chain.ErrLogRangeInvalid, chain.ErrLogRangePruned, chain.ErrLogRangeTooLarge, chain.ErrTooManyLogs:
+		return InvalidParams
+	}
+	if isTxRejection(err) {
+		return InvalidParams
+	}
+	var pruned *filterPrunedError
+	if errors.As(err, &pruned) {
+		return InvalidParams
+	}
+	return MethodNotFound
 }
 
 // writeResult writes a successful response
@@ -165,10 +320,10 @@ func (s *Server) RegisterMethod(name string, handler MethodHandler) {
 
 // BroadcastBlock broadcasts a new block to subscribers
 func (s *Server) BroadcastBlock(block interface{}) {
-	s.subs.Broadcast("newBlock", block)
+	s.subs.Broadcast(SubNewHeads, block)
 }
 
 // BroadcastTransaction broadcasts a new transaction to subscribers
 func (s *Server) BroadcastTransaction(tx interface{}) {
-	s.subs.Broadcast("newTransaction", tx)
+	s.subs.Broadcast(SubPendingTransactions, tx)
 }
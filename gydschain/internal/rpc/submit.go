@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// ErrInvalidSignedTx is returned when signedTx is not a hex encoded transaction
+var ErrInvalidSignedTx = errors.New("invalid signed transaction encoding")
+
+// decodeSignedTx decodes the hex encoded JSON transaction sent by clients
+func decodeSignedTx(signed string) (*tx.Transaction, error) {
+	data, err := hex.DecodeString(signed)
+	if err != nil {
+		return nil, ErrInvalidSignedTx
+	}
+	var t tx.Transaction
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, ErrInvalidSignedTx
+	}
+	return &t, nil
+}
+
+// txRejectedError marks a transaction refused by validation or the mempool
+type txRejectedError struct {
+	err error
+}
+
+func (e *txRejectedError) Error() string { return e.err.Error() }
+func (e *txRejectedError) Unwrap() error { return e.err }
+
+// submitTx validates t against current state, adds it to the mempool and
+// gossips it to peers when a relay is attached
+func submitTx(backend *Backend, t *tx.Transaction) (string, error) {
+	if backend.Mempool == nil {
+		return "", ErrBackendUnavailable
+	}
+	if err := t.Verify(); err != nil {
+		return "", &txRejectedError{err}
+	}
+	if backend.State != nil {
+		if account := backend.State.GetAccount(t.From); account != nil && t.Nonce < account.Nonce {
+			return "", &txRejectedError{tx.ErrNonceTooLow}
+		}
+		if t.Asset != "GYDS" && t.Asset != "GYD" && backend.State.GetAsset(t.Asset) == nil {
+			return "", &txRejectedError{tx.ErrInvalidAsset}
+		}
+	}
+
+	var hash string
+	var err error
+	if backend.Gossip != nil {
+		hash, err = backend.Gossip.Submit(t)
+	} else if err = backend.Mempool.AddTx(t); err == nil {
+		hash, err = t.HashHex()
+	}
+	if err != nil {
+		return "", &txRejectedError{err}
+	}
+	return hash, nil
+}
+
+// isTxRejection reports whether err means the submitted tx itself was bad
+func isTxRejection(err error) bool {
+	var rejected *txRejectedError
+	return err == ErrInvalidSignedTx || errors.As(err, &rejected)
+}
@@ -1,21 +1,43 @@
 package rpc
 
 import (
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/tx"
 )
 
 // SubscriptionType represents different types of subscriptions
 type SubscriptionType string
 
 const (
-	SubNewBlock       SubscriptionType = "newBlock"
-	SubNewTransaction SubscriptionType = "newTransaction"
-	SubPendingTx      SubscriptionType = "pendingTransaction"
-	SubLogs           SubscriptionType = "logs"
-	SubSyncing        SubscriptionType = "syncing"
+	SubNewHeads            SubscriptionType = "newHeads"
+	SubPendingTransactions SubscriptionType = "pendingTransactions"
+	SubLogs                SubscriptionType = "logs"
+	SubValidatorSet        SubscriptionType = "validatorSetChanges"
+)
+
+// WebSocket connection limits
+const (
+	DefaultSendQueue        = 256 // queued messages per client before it is dropped
+	MaxSubscriptionsPerConn = 32
+	wsWriteWait             = 10 * time.Second
+	wsPongWait              = 60 * time.Second
+	wsPingPeriod            = wsPongWait * 9 / 10
+)
+
+// Subscription errors
+var (
+	ErrUnknownSubscription  = errors.New("unknown subscription type")
+	ErrTooManySubscriptions = errors.New("too many subscriptions on this connection")
+	ErrClientNotFound       = errors.New("websocket client not found")
+
+	errInvalidSubscribeParams = errors.New("invalid subscription params")
 )
 
 // Subscription represents an active subscription
@@ -26,40 +48,59 @@ type Subscription struct {
 	Filter   interface{} // Optional filter criteria
 }
 
-// Client represents a connected WebSocket client
+// Client represents a connected WebSocket client. All writes go through
+// the send queue so a slow reader never blocks the broadcaster.
 type Client struct {
 	ID            string
 	Conn          *websocket.Conn
 	Subscriptions map[string]*Subscription
+	send          chan interface{}
+	done          chan struct{}
+	closeOnce     sync.Once
 	mu            sync.RWMutex
 }
 
 // SubscriptionManager manages WebSocket subscriptions
 type SubscriptionManager struct {
-	clients map[string]*Client
-	subs    map[SubscriptionType]map[string]*Subscription // type -> subID -> sub
-	mu      sync.RWMutex
+	clients   map[string]*Client
+	subs      map[SubscriptionType]map[string]*Subscription // type -> subID -> sub
+	queueSize int
+	mu        sync.RWMutex
 }
 
 // NewSubscriptionManager creates a new subscription manager
 func NewSubscriptionManager() *SubscriptionManager {
 	return &SubscriptionManager{
-		clients: make(map[string]*Client),
-		subs:    make(map[SubscriptionType]map[string]*Subscription),
+		clients:   make(map[string]*Client),
+		subs:      make(map[SubscriptionType]map[string]*Subscription),
+		queueSize: DefaultSendQueue,
 	}
 }
 
-// AddClient adds a new WebSocket client
+// IsValidSubscription reports whether subType can be subscribed to
+func IsValidSubscription(subType SubscriptionType) bool {
+	switch subType {
+	case SubNewHeads, SubPendingTransactions, SubLogs, SubValidatorSet:
+		return true
+	}
+	return false
+}
+
+// AddClient adds a new WebSocket client and starts its writer
 func (sm *SubscriptionManager) AddClient(conn *websocket.Conn) string {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	clientID := uuid.New().String()
-	sm.clients[clientID] = &Client{
+	client := &Client{
 		ID:            clientID,
 		Conn:          conn,
 		Subscriptions: make(map[string]*Subscription),
+		send:          make(chan interface{}, sm.queueSize),
+		done:          make(chan struct{}),
 	}
+	sm.clients[clientID] = client
+	go client.writeLoop()
 
 	return clientID
 }
@@ -81,17 +122,22 @@ func (sm *SubscriptionManager) RemoveClient(clientID string) {
 		}
 	}
 
+	client.close()
 	delete(sm.clients, clientID)
 }
 
 // Subscribe creates a new subscription
 func (sm *SubscriptionManager) Subscribe(clientID string, subType SubscriptionType, filter interface{}) (string, error) {
+	if !IsValidSubscription(subType) {
+		return "", ErrUnknownSubscription
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	client, exists := sm.clients[clientID]
 	if !exists {
-		return "", nil
+		return "", ErrClientNotFound
 	}
 
 	subID := uuid.New().String()
@@ -104,6 +150,10 @@ func (sm *SubscriptionManager) Subscribe(clientID string, subType SubscriptionTy
 
 	// Add to client's subscriptions
 	client.mu.Lock()
+	if len(client.Subscriptions) >= MaxSubscriptionsPerConn {
+		client.mu.Unlock()
+		return "", ErrTooManySubscriptions
+	}
 	client.Subscriptions[subID] = sub
 	client.mu.Unlock()
 
@@ -146,11 +196,19 @@ func (sm *SubscriptionManager) Unsubscribe(clientID string, subID string) bool {
 }
 
 // Broadcast sends data to all subscribers of a specific type
-func (sm *SubscriptionManager) Broadcast(subType string, data interface{}) {
+func (sm *SubscriptionManager) Broadcast(subType SubscriptionType, data interface{}) {
+	sm.BroadcastFunc(subType, func(sub *Subscription) []interface{} {
+		return []interface{}{data}
+	})
+}
+
+// BroadcastFunc sends each subscriber of a type the results selected for
+// it, so filtered subscriptions only receive what they asked for
+func (sm *SubscriptionManager) BroadcastFunc(subType SubscriptionType, selectFn func(sub *Subscription) []interface{}) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	typeSubs, exists := sm.subs[SubscriptionType(subType)]
+	typeSubs, exists := sm.subs[subType]
 	if !exists {
 		return
 	}
@@ -161,40 +219,67 @@ func (sm *SubscriptionManager) Broadcast(subType string, data interface{}) {
 			continue
 		}
 
-		// Send notification
-		notification := map[string]interface{}{
-			"jsonrpc": "2.0",
-			"method":  "subscription",
-			"params": map[string]interface{}{
-				"subscription": sub.ID,
-				"result":       data,
-			},
+		for _, result := range selectFn(sub) {
+			client.enqueue(notification(sub.ID, result))
 		}
+	}
+}
 
-		client.Conn.WriteJSON(notification)
+// BroadcastLogs sends each logs subscriber the entries matching its filter
+func (sm *SubscriptionManager) BroadcastLogs(logs []*chain.IndexedLog) {
+	if len(logs) == 0 {
+		return
 	}
+
+	sm.BroadcastFunc(SubLogs, func(sub *Subscription) []interface{} {
+		filter, _ := sub.Filter.(*chain.LogFilter)
+		var matched []interface{}
+		for _, l := range logs {
+			if filter == nil || chain.MatchLog(l, filter) {
+				matched = append(matched, newLogResponse(l))
+			}
+		}
+		return matched
+	})
+}
+
+// PendingFilter selects what pendingTransactions subscribers receive
+type PendingFilter struct {
+	Full bool `json:"full"` // whole transactions instead of hashes
+}
+
+// BroadcastPending sends each pendingTransactions subscriber the hash of a
+// new mempool transaction, or the transaction itself if it asked for full
+// bodies
+func (sm *SubscriptionManager) BroadcastPending(t *tx.Transaction, hash string) {
+	var full *TransactionResponse
+	sm.BroadcastFunc(SubPendingTransactions, func(sub *Subscription) []interface{} {
+		if filter, _ := sub.Filter.(*PendingFilter); filter == nil || !filter.Full {
+			return []interface{}{hash}
+		}
+		if full == nil {
+			full = newTransactionResponse(t)
+		}
+		return []interface{}{full}
+	})
 }
 
 // BroadcastToClient sends data to a specific client
 func (sm *SubscriptionManager) BroadcastToClient(clientID string, subID string, data interface{}) {
+	sm.Send(clientID, notification(subID, data))
+}
+
+// Send queues a message for a client; it reports false if the client is
+// gone or was dropped for falling behind
+func (sm *SubscriptionManager) Send(clientID string, msg interface{}) bool {
 	sm.mu.RLock()
 	client, exists := sm.clients[clientID]
 	sm.mu.RUnlock()
 
 	if !exists {
-		return
-	}
-
-	notification := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "subscription",
-		"params": map[string]interface{}{
-			"subscription": subID,
-			"result":       data,
-		},
+		return false
 	}
-
-	client.Conn.WriteJSON(notification)
+	return client.enqueue(msg)
 }
 
 // GetSubscriptionCount returns the number of active subscriptions
@@ -215,3 +300,66 @@ func (sm *SubscriptionManager) GetClientCount() int {
 	defer sm.mu.RUnlock()
 	return len(sm.clients)
 }
+
+// notification builds a subscription notification message
+func notification(subID string, result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "subscription",
+		"params": map[string]interface{}{
+			"subscription": subID,
+			"result":       result,
+		},
+	}
+}
+
+// enqueue queues a message without blocking; a client whose queue is full
+// is disconnected rather than allowed to stall other subscribers
+func (c *Client) enqueue(msg interface{}) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		c.close()
+		return false
+	}
+}
+
+// close stops the writer and closes the connection, unblocking the reader
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.Conn.Close()
+	})
+}
+
+// writeLoop is the only goroutine that writes to the connection
+func (c *Client) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.Conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.Conn.WriteJSON(msg); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
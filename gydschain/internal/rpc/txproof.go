@@ -0,0 +1,100 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// TxProofResponse is a Merkle proof that a transaction is committed by the
+// TxRoot of the block at BlockNumber. The raw transaction is included so a
+// light client can recompute its hash.
+type TxProofResponse struct {
+	Hash        string          `json:"hash"`
+	BlockHash   string          `json:"blockHash"`
+	BlockNumber uint64          `json:"blockNumber"`
+	TxIndex     uint64          `json:"transactionIndex"`
+	Transaction *tx.Transaction `json:"transaction"`
+	Proof       []string        `json:"proof"`  // hex-encoded sibling hashes, leaf first
+	Status      uint64          `json:"status"` // from the receipt, not covered by the proof
+}
+
+// getTxProof returns an inclusion proof for an executed transaction, or
+// null if the node has no receipt for it
+func (m *Methods) getTxProof(params json.RawMessage) (interface{}, error) {
+	var args struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	backend, err := m.getBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Chain == nil {
+		return nil, ErrBackendUnavailable
+	}
+
+	receipt, err := backend.Chain.GetReceipt(args.Hash)
+	if err == chain.ErrReceiptNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, err := backend.Chain.GetBlockByHeight(receipt.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	index := int(receipt.Index)
+	transaction := block.GetTransaction(index)
+	if transaction == nil {
+		return nil, chain.ErrTxNotInBlock
+	}
+	if hash, err := transaction.HashHex(); err != nil || hash != receipt.TxHash {
+		return nil, chain.ErrTxNotInBlock
+	}
+	proof, err := block.TxProof(index)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TxProofResponse{
+		Hash:        receipt.TxHash,
+		BlockHash:   receipt.BlockHash,
+		BlockNumber: receipt.BlockHeight,
+		TxIndex:     uint64(receipt.Index),
+		Transaction: transaction,
+		Proof:       make([]string, len(proof)),
+		Status:      uint64(receipt.Status),
+	}
+	for i, sibling := range proof {
+		resp.Proof[i] = hex.EncodeToString(sibling)
+	}
+	return resp, nil
+}
+
+// Verify checks the proof against a verified header's TxRoot and that the
+// included transaction hashes to Hash
+func (r *TxProofResponse) Verify(txRoot string) error {
+	if r.Transaction == nil {
+		return chain.ErrInvalidTxProof
+	}
+	hash, err := r.Transaction.Hash()
+	if err != nil || hex.EncodeToString(hash) != r.Hash {
+		return chain.ErrInvalidTxProof
+	}
+
+	proof := make([][]byte, len(r.Proof))
+	for i, sibling := range r.Proof {
+		if proof[i], err = hex.DecodeString(sibling); err != nil {
+			return chain.ErrInvalidTxProof
+		}
+	}
+	return chain.VerifyTxProof(hash, int(r.TxIndex), proof, txRoot)
+}
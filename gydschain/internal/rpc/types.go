@@ -25,29 +25,33 @@ type RPCError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// Standard JSON-RPC error codes
+// Standard JSON-RPC error codes. The line comments here and on the custom
+// codes below are served by rpc_errorCodes; run go generate after editing.
 const (
-	ParseError     = -32700
-	InvalidRequest = -32600
-	MethodNotFound = -32601
-	InvalidParams  = -32602
-	InternalError  = -32603
+	ParseError     = -32700 // The request body is not valid JSON
+	InvalidRequest = -32600 // The request is not a valid JSON-RPC 2.0 request
+	MethodNotFound = -32601 // The method does not exist or failed without a more specific code
+	InvalidParams  = -32602 // The parameters are invalid or the transaction was rejected
+	InternalError  = -32603 // The node failed to process the request
 )
 
 // Custom error codes (application-specific)
 const (
-	ErrBlockNotFound       = -32000
-	ErrTxNotFound          = -32001
-	ErrAccountNotFound     = -32002
-	ErrInsufficientBalance = -32003
-	ErrInvalidSignature    = -32004
-	ErrNonceTooLow         = -32005
-	ErrNonceTooHigh        = -32006
-	ErrTxPoolFull          = -32007
-	ErrValidatorNotFound   = -32008
-	ErrAlreadyStaked       = -32009
-	ErrNotStaked           = -32010
-	ErrMinimumStake        = -32011
+	ErrBlockNotFound       = -32000 // No block exists at the requested height or hash
+	ErrTxNotFound          = -32001 // No transaction exists with the requested hash
+	ErrAccountNotFound     = -32002 // The account does not exist
+	ErrInsufficientBalance = -32003 // The sender cannot cover the amount and fee
+	ErrInvalidSignature    = -32004 // The transaction signature does not verify
+	ErrNonceTooLow         = -32005 // The nonce has already been used by the sender
+	ErrNonceTooHigh        = -32006 // The nonce is too far ahead of the sender's next nonce
+	ErrTxPoolFull          = -32007 // The mempool is full and the fee is too low to evict
+	ErrValidatorNotFound   = -32008 // No validator is registered at the address
+	ErrAlreadyStaked       = -32009 // The address is already staked with the validator
+	ErrNotStaked           = -32010 // The address has no stake with the validator
+	ErrMinimumStake        = -32011 // The stake is below the network minimum
+	ErrMethodDisabled      = -32012 // The method is disabled on this node, e.g. in read-only mode
+	ErrUnauthorized        = -32013 // The method needs an API key or JWT, or the credentials are invalid
+	ErrRateLimited         = -32014 // The client exceeded the node's request rate; retry after the given delay
 )
 
 // BlockResponse represents a block in RPC responses
@@ -65,23 +69,35 @@ type BlockResponse struct {
 	Size             uint64              `json:"size"`
 	GasUsed          uint64              `json:"gasUsed"`
 	GasLimit         uint64              `json:"gasLimit"`
+	BaseFee          uint64              `json:"baseFee"`
+	Burned           string              `json:"burned"` // GYDS burned by base fees
 }
 
 // TransactionResponse represents a transaction in RPC responses
 type TransactionResponse struct {
-	Hash        string `json:"hash"`
-	Nonce       uint64 `json:"nonce"`
-	BlockHash   string `json:"blockHash,omitempty"`
-	BlockNumber uint64 `json:"blockNumber,omitempty"`
-	TxIndex     uint64 `json:"transactionIndex,omitempty"`
-	From        string `json:"from"`
-	To          string `json:"to,omitempty"`
-	Value       string `json:"value"`
-	Asset       string `json:"asset"`
-	Fee         string `json:"fee"`
-	Data        string `json:"data,omitempty"`
-	Signature   string `json:"signature"`
-	Type        string `json:"type"`
+	Hash        string                      `json:"hash"`
+	Nonce       uint64                      `json:"nonce"`
+	BlockHash   string                      `json:"blockHash,omitempty"`
+	BlockNumber uint64                      `json:"blockNumber,omitempty"`
+	TxIndex     uint64                      `json:"transactionIndex,omitempty"`
+	From        string                      `json:"from"`
+	To          string                      `json:"to,omitempty"`
+	Value       string                      `json:"value"`
+	Asset       string                      `json:"asset"`
+	Fee         string                      `json:"fee"`
+	Data        string                      `json:"data,omitempty"`
+	Memo        string                      `json:"memo,omitempty"`
+	Payload     interface{}                 `json:"payload,omitempty"` // decoded typed payload
+	Signature   string                      `json:"signature"`
+	Signatures  []MultisigSignatureResponse `json:"signatures,omitempty"` // multisig partial signatures
+	Type        string                      `json:"type"`
+}
+
+// MultisigSignatureResponse is one signer's signature on a multisig
+// transaction
+type MultisigSignatureResponse struct {
+	PubKey    string `json:"pub_key"`
+	Signature string `json:"signature"`
 }
 
 // TransactionReceiptResponse represents a transaction receipt
@@ -114,6 +130,41 @@ type AccountResponse struct {
 	Address  string            `json:"address"`
 	Nonce    uint64            `json:"nonce"`
 	Balances map[string]string `json:"balances"` // asset -> balance
+	Contract bool              `json:"contract"`
+	Vesting  *VestingResponse  `json:"vesting,omitempty"`
+	Multisig *MultisigResponse `json:"multisig,omitempty"`
+}
+
+// MultisigResponse lists the keys controlling a multisig account
+type MultisigResponse struct {
+	PubKeys   []string `json:"pubKeys"`
+	Threshold int      `json:"threshold"`
+}
+
+// VestingResponse reports an account's vesting schedule and progress at
+// Time, the timestamp of the block the account was read at
+type VestingResponse struct {
+	Total     string `json:"total"`
+	Initial   string `json:"initial"`
+	Start     int64  `json:"start"`
+	Cliff     int64  `json:"cliff"`
+	End       int64  `json:"end"`
+	Locked    string `json:"locked"`
+	Vested    string `json:"vested"`
+	Spendable string `json:"spendable"`
+	Time      int64  `json:"time"`
+}
+
+// AccountProofResponse is a Merkle proof of an account against the state
+// root committed at Height. Account is null when the proof shows absence.
+type AccountProofResponse struct {
+	Address string           `json:"address"`
+	Account *AccountResponse `json:"account"`
+	Key     string           `json:"key"`
+	Value   string           `json:"value,omitempty"` // hex-encoded leaf
+	Proof   []string         `json:"proof"`           // hex-encoded trie nodes, root first
+	Root    string           `json:"root"`
+	Height  uint64           `json:"height"`
 }
 
 // ValidatorResponse represents a validator in RPC responses
@@ -167,6 +218,7 @@ type MiningInfoResponse struct {
 	Mining          bool   `json:"mining"`
 	Hashrate        uint64 `json:"hashrate"`
 	Difficulty      string `json:"difficulty"`
+	NextDifficulty  string `json:"nextDifficulty"`
 	CurrentBlock    uint64 `json:"currentBlock"`
 	PendingTxCount  uint64 `json:"pendingTxCount"`
 	MinerAddress    string `json:"minerAddress,omitempty"`
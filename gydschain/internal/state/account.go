@@ -2,64 +2,99 @@ package state
 
 import (
 	"encoding/json"
+	"math/big"
 	"sync"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // Account represents a blockchain account
 type Account struct {
 	mu        sync.RWMutex
-	Address   string            `json:"address"`
-	Nonce     uint64            `json:"nonce"`
-	Balances  map[string]uint64 `json:"balances"`
-	Staked    uint64            `json:"staked"`
-	Delegated map[string]uint64 `json:"delegated"`
-	Code      []byte            `json:"code,omitempty"`
-	Storage   map[string][]byte `json:"storage,omitempty"`
-	CreatedAt int64             `json:"created_at"`
-	UpdatedAt int64             `json:"updated_at"`
+	Address   string              `json:"address"`
+	Nonce     uint64              `json:"nonce"`
+	Balances  map[string]*big.Int `json:"balances"`
+	Staked    *big.Int            `json:"staked"`
+	Delegated map[string]*big.Int `json:"delegated"`
+	Code      []byte              `json:"code,omitempty"`
+	Storage   map[string][]byte   `json:"storage,omitempty"`
+	Vesting   *VestingSchedule    `json:"vesting,omitempty"`
+	Multisig  *MultisigConfig     `json:"multisig,omitempty"`
+	CreatedAt int64               `json:"created_at"`
+	UpdatedAt int64               `json:"updated_at"`
 }
 
 // NewAccount creates a new account
 func NewAccount(address string) *Account {
 	return &Account{
 		Address:   address,
-		Balances:  make(map[string]uint64),
-		Delegated: make(map[string]uint64),
+		Balances:  make(map[string]*big.Int),
+		Staked:    new(big.Int),
+		Delegated: make(map[string]*big.Int),
 		Storage:   make(map[string][]byte),
 	}
 }
 
+// MarshalJSON encodes balances and stakes as decimal strings. Callers
+// sharing the account must hold its lock.
+func (a *Account) MarshalJSON() ([]byte, error) {
+	type plain Account
+	return json.Marshal(struct {
+		*plain
+		Balances  util.BigMap `json:"balances"`
+		Staked    *util.Big   `json:"staked"`
+		Delegated util.BigMap `json:"delegated"`
+	}{(*plain)(a), util.BigMap(a.Balances), (*util.Big)(a.Staked), util.BigMap(a.Delegated)})
+}
+
+// UnmarshalJSON decodes balances and stakes from strings or numbers
+func (a *Account) UnmarshalJSON(data []byte) error {
+	type plain Account
+	dec := struct {
+		*plain
+		Balances  util.BigMap `json:"balances"`
+		Staked    *util.Big   `json:"staked"`
+		Delegated util.BigMap `json:"delegated"`
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	a.Balances, a.Staked, a.Delegated = dec.Balances, dec.Staked.Int(), dec.Delegated
+	return nil
+}
+
 // GetBalance returns the balance for a specific asset
-func (a *Account) GetBalance(asset string) uint64 {
+func (a *Account) GetBalance(asset string) *big.Int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.Balances[asset]
+	return util.CopyBig(a.Balances[asset])
 }
 
 // SetBalance sets the balance for a specific asset
-func (a *Account) SetBalance(asset string, amount uint64) {
+func (a *Account) SetBalance(asset string, amount *big.Int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.Balances[asset] = amount
+	a.Balances[asset] = util.CopyBig(amount)
 }
 
 // AddBalance adds to the balance for a specific asset
-func (a *Account) AddBalance(asset string, amount uint64) {
+func (a *Account) AddBalance(asset string, amount *big.Int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.Balances[asset] += amount
+	a.Balances[asset] = addAmount(a.Balances[asset], amount)
 }
 
 // SubBalance subtracts from the balance for a specific asset
-func (a *Account) SubBalance(asset string, amount uint64) bool {
+func (a *Account) SubBalance(asset string, amount *big.Int) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	
-	if a.Balances[asset] < amount {
+	balance, ok := subAmount(a.Balances[asset], amount)
+	if !ok {
 		return false
 	}
 	
-	a.Balances[asset] -= amount
+	a.Balances[asset] = balance
 	return true
 }
 
@@ -78,83 +113,102 @@ func (a *Account) IncrementNonce() {
 }
 
 // Stake locks tokens for staking
-func (a *Account) Stake(amount uint64) bool {
+func (a *Account) Stake(amount *big.Int) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	
-	if a.Balances["GYDS"] < amount {
+	balance, ok := subAmount(a.Balances["GYDS"], amount)
+	if !ok {
 		return false
 	}
 	
-	a.Balances["GYDS"] -= amount
-	a.Staked += amount
+	a.Balances["GYDS"] = balance
+	a.Staked = addAmount(a.Staked, amount)
 	return true
 }
 
 // Unstake unlocks tokens from staking
-func (a *Account) Unstake(amount uint64) bool {
+func (a *Account) Unstake(amount *big.Int) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	
-	if a.Staked < amount {
+	staked, ok := subAmount(a.Staked, amount)
+	if !ok {
 		return false
 	}
 	
-	a.Staked -= amount
-	a.Balances["GYDS"] += amount
+	a.Staked = staked
+	a.Balances["GYDS"] = addAmount(a.Balances["GYDS"], amount)
 	return true
 }
 
 // GetStaked returns the staked amount
-func (a *Account) GetStaked() uint64 {
+func (a *Account) GetStaked() *big.Int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.Staked
+	return util.CopyBig(a.Staked)
 }
 
 // Delegate delegates stake to a validator
-func (a *Account) Delegate(validator string, amount uint64) bool {
+func (a *Account) Delegate(validator string, amount *big.Int) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	
-	if a.Balances["GYDS"] < amount {
+	balance, ok := subAmount(a.Balances["GYDS"], amount)
+	if !ok {
 		return false
 	}
 	
-	a.Balances["GYDS"] -= amount
-	a.Delegated[validator] += amount
+	a.Balances["GYDS"] = balance
+	a.Delegated[validator] = addAmount(a.Delegated[validator], amount)
 	return true
 }
 
 // Undelegate removes delegation from a validator
-func (a *Account) Undelegate(validator string, amount uint64) bool {
+func (a *Account) Undelegate(validator string, amount *big.Int) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	delegated, ok := subAmount(a.Delegated[validator], amount)
+	if !ok {
+		return false
+	}
+	
+	a.Delegated[validator] = delegated
+	a.Balances["GYDS"] = addAmount(a.Balances["GYDS"], amount)
+	return true
+}
+
+// Unbond removes delegation from a validator without returning it to the
+// balance; the chain credits it once the unbonding period has passed
+func (a *Account) Unbond(validator string, amount *big.Int) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	
-	if a.Delegated[validator] < amount {
+	delegated, ok := subAmount(a.Delegated[validator], amount)
+	if !ok {
 		return false
 	}
 	
-	a.Delegated[validator] -= amount
-	a.Balances["GYDS"] += amount
+	a.Delegated[validator] = delegated
 	return true
 }
 
 // GetDelegation returns the delegated amount to a validator
-func (a *Account) GetDelegation(validator string) uint64 {
+func (a *Account) GetDelegation(validator string) *big.Int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.Delegated[validator]
+	return util.CopyBig(a.Delegated[validator])
 }
 
 // TotalDelegated returns total delegated amount
-func (a *Account) TotalDelegated() uint64 {
+func (a *Account) TotalDelegated() *big.Int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	
-	var total uint64
+	total := new(big.Int)
 	for _, amount := range a.Delegated {
-		total += amount
+		total.Add(total, amount)
 	}
 	return total
 }
@@ -203,13 +257,14 @@ func (a *Account) Copy() *Account {
 		Address:   a.Address,
 		Nonce:     a.Nonce,
 		Staked:    a.Staked,
-		Balances:  make(map[string]uint64),
-		Delegated: make(map[string]uint64),
+		Balances:  make(map[string]*big.Int),
+		Delegated: make(map[string]*big.Int),
 		Storage:   make(map[string][]byte),
 		CreatedAt: a.CreatedAt,
 		UpdatedAt: a.UpdatedAt,
 	}
 	
+	// Amounts are replaced, never mutated in place, so they can be shared
 	for k, v := range a.Balances {
 		copy.Balances[k] = v
 	}
@@ -226,6 +281,14 @@ func (a *Account) Copy() *Account {
 		copy.Code = append([]byte{}, a.Code...)
 	}
 	
+	if a.Vesting != nil {
+		copy.Vesting = a.Vesting.Copy()
+	}
+	
+	if a.Multisig != nil {
+		copy.Multisig = a.Multisig.Copy()
+	}
+	
 	return copy
 }
 
@@ -244,10 +307,10 @@ func Deserialize(data []byte) (*Account, error) {
 	}
 	
 	if account.Balances == nil {
-		account.Balances = make(map[string]uint64)
+		account.Balances = make(map[string]*big.Int)
 	}
 	if account.Delegated == nil {
-		account.Delegated = make(map[string]uint64)
+		account.Delegated = make(map[string]*big.Int)
 	}
 	if account.Storage == nil {
 		account.Storage = make(map[string][]byte)
@@ -255,3 +318,25 @@ func Deserialize(data []byte) (*Account, error) {
 	
 	return &account, nil
 }
+
+// addAmount returns balance + amount in a new big.Int
+func addAmount(balance, amount *big.Int) *big.Int {
+	sum := util.CopyBig(balance)
+	if amount != nil {
+		sum.Add(sum, amount)
+	}
+	return sum
+}
+
+// subAmount returns balance - amount, or false if amount is negative or
+// exceeds balance
+func subAmount(balance, amount *big.Int) (*big.Int, bool) {
+	balance = util.CopyBig(balance)
+	if amount == nil {
+		return balance, true
+	}
+	if amount.Sign() < 0 || balance.Cmp(amount) < 0 {
+		return nil, false
+	}
+	return balance.Sub(balance, amount), true
+}
@@ -0,0 +1,167 @@
+package state
+
+import (
+	"sort"
+	"sync"
+)
+
+// DefaultStateHistory is how many recent heights a non-archive node keeps
+const DefaultStateHistory = 128
+
+// accountVersion is an account as of the height it was last changed; a nil
+// account records a deletion
+type accountVersion struct {
+	height  uint64
+	account *Account
+}
+
+type assetVersion struct {
+	height uint64
+	asset  *Asset
+}
+
+// Archive retains the state written at each committed height so balances
+// can be queried as of a past block. With retain set to 0 every height is
+// kept (archive mode); otherwise only the most recent retain heights are.
+type Archive struct {
+	mu       sync.RWMutex
+	retain   uint64
+	accounts map[string][]accountVersion
+	assets   map[string][]assetVersion
+	roots    map[uint64]string
+	latest   uint64
+	oldest   uint64
+	started  bool
+}
+
+// NewArchive creates a state archive; retain 0 keeps full history
+func NewArchive(retain uint64) *Archive {
+	return &Archive{
+		retain:   retain,
+		accounts: make(map[string][]accountVersion),
+		assets:   make(map[string][]assetVersion),
+		roots:    make(map[uint64]string),
+	}
+}
+
+// IsFullArchive returns true if no history is pruned
+func (a *Archive) IsFullArchive() bool {
+	return a.retain == 0
+}
+
+// record stores the changed accounts and assets committed at height
+func (a *Archive) record(height uint64, root string, accounts map[string]*Account, assets map[string]*Asset) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for addr, account := range accounts {
+		a.accounts[addr] = append(a.accounts[addr], accountVersion{height: height, account: account})
+	}
+	for id, asset := range assets {
+		a.assets[id] = append(a.assets[id], assetVersion{height: height, asset: asset})
+	}
+	a.roots[height] = root
+
+	if !a.started {
+		a.oldest = height
+		a.started = true
+	}
+	if height > a.latest {
+		a.latest = height
+	}
+
+	if a.retain > 0 && a.latest >= a.oldest+a.retain {
+		a.prune(a.latest - a.retain + 1)
+	}
+}
+
+// prune drops history below height while keeping, for every key, the last
+// version at or before it so queries at height still resolve
+func (a *Archive) prune(height uint64) {
+	for addr, versions := range a.accounts {
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].height > height })
+		if i > 1 {
+			a.accounts[addr] = append([]accountVersion(nil), versions[i-1:]...)
+		}
+	}
+	for id, versions := range a.assets {
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].height > height })
+		if i > 1 {
+			a.assets[id] = append([]assetVersion(nil), versions[i-1:]...)
+		}
+	}
+	for h := a.oldest; h < height; h++ {
+		delete(a.roots, h)
+	}
+	a.oldest = height
+}
+
+// checkHeight returns an error if height is outside the retained range
+func (a *Archive) checkHeight(height uint64) error {
+	if !a.started || height > a.latest {
+		return ErrHeightNotCommitted
+	}
+	if height < a.oldest {
+		return ErrHeightPruned
+	}
+	return nil
+}
+
+// AccountAt returns the account as of height, or nil if it did not exist
+func (a *Archive) AccountAt(address string, height uint64) (*Account, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.checkHeight(height); err != nil {
+		return nil, err
+	}
+
+	versions := a.accounts[address]
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].height > height })
+	if i == 0 || versions[i-1].account == nil {
+		return nil, nil
+	}
+	return versions[i-1].account.Copy(), nil
+}
+
+// AssetAt returns the asset as of height, or nil if it did not exist
+func (a *Archive) AssetAt(id string, height uint64) (*Asset, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.checkHeight(height); err != nil {
+		return nil, err
+	}
+
+	versions := a.assets[id]
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].height > height })
+	if i == 0 || versions[i-1].asset == nil {
+		return nil, nil
+	}
+	return versions[i-1].asset.Copy(), nil
+}
+
+// RootAt returns the state root committed at height
+func (a *Archive) RootAt(height uint64) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.checkHeight(height); err != nil {
+		return "", err
+	}
+	return a.roots[height], nil
+}
+
+// Range returns the oldest and latest queryable heights
+func (a *Archive) Range() (uint64, uint64) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.oldest, a.latest
+}
+
+// Archive errors
+var (
+	ErrHeightPruned       = &StateError{"state at height has been pruned; query an archive node"}
+	ErrHeightNotCommitted = &StateError{"state at height not committed yet"}
+	ErrArchiveDisabled    = &StateError{"historical state not retained by this node"}
+)
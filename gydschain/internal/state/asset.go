@@ -2,7 +2,10 @@ package state
 
 import (
 	"encoding/json"
+	"math/big"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // AssetType represents the type of asset
@@ -21,39 +24,68 @@ type Asset struct {
 	Name        string    `json:"name"`
 	Symbol      string    `json:"symbol"`
 	Decimals    uint8     `json:"decimals"`
-	TotalSupply uint64    `json:"total_supply"`
-	MaxSupply   uint64    `json:"max_supply"`
+	TotalSupply *big.Int  `json:"total_supply"`
+	MaxSupply   *big.Int  `json:"max_supply"` // zero for no cap
 	Owner       string    `json:"owner"`
 	Mintable    bool      `json:"mintable"`
 	Burnable    bool      `json:"burnable"`
 	Pausable    bool      `json:"pausable"`
 	Paused      bool      `json:"paused"`
 	Metadata    *AssetMetadata `json:"metadata,omitempty"`
+	Policy      *TransferPolicy `json:"policy,omitempty"`
 	CreatedAt   int64     `json:"created_at"`
 	UpdatedAt   int64     `json:"updated_at"`
 }
 
+// MarshalJSON encodes the supplies as decimal strings
+func (a Asset) MarshalJSON() ([]byte, error) {
+	type plain Asset
+	return json.Marshal(struct {
+		*plain
+		TotalSupply *util.Big `json:"total_supply"`
+		MaxSupply   *util.Big `json:"max_supply"`
+	}{(*plain)(&a), (*util.Big)(a.TotalSupply), (*util.Big)(a.MaxSupply)})
+}
+
+// UnmarshalJSON decodes the supplies from strings or numbers
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	type plain Asset
+	dec := struct {
+		*plain
+		TotalSupply *util.Big `json:"total_supply"`
+		MaxSupply   *util.Big `json:"max_supply"`
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	a.TotalSupply, a.MaxSupply = dec.TotalSupply.Int(), dec.MaxSupply.Int()
+	return nil
+}
+
 // AssetMetadata contains additional asset information
 type AssetMetadata struct {
 	Description string            `json:"description,omitempty"`
 	Image       string            `json:"image,omitempty"`
 	ExternalURL string            `json:"external_url,omitempty"`
+	TokenURI    string            `json:"token_uri,omitempty"`
 	Properties  map[string]string `json:"properties,omitempty"`
 }
 
 // NewFungibleAsset creates a new fungible token
 func NewFungibleAsset(id, name, symbol string, decimals uint8, owner string) *Asset {
 	return &Asset{
-		ID:        id,
-		Type:      AssetTypeFungible,
-		Name:      name,
-		Symbol:    symbol,
-		Decimals:  decimals,
-		Owner:     owner,
-		Mintable:  true,
-		Burnable:  true,
-		CreatedAt: time.Now().Unix(),
-		UpdatedAt: time.Now().Unix(),
+		ID:          id,
+		Type:        AssetTypeFungible,
+		Name:        name,
+		Symbol:      symbol,
+		Decimals:    decimals,
+		Owner:       owner,
+		TotalSupply: new(big.Int),
+		MaxSupply:   new(big.Int),
+		Mintable:    true,
+		Burnable:    true,
+		CreatedAt:   time.Now().Unix(),
+		UpdatedAt:   time.Now().Unix(),
 	}
 }
 
@@ -65,8 +97,8 @@ func NewNFT(id, name, owner string, metadata *AssetMetadata) *Asset {
 		Name:        name,
 		Symbol:      "NFT",
 		Decimals:    0,
-		TotalSupply: 1,
-		MaxSupply:   1,
+		TotalSupply: big.NewInt(1),
+		MaxSupply:   big.NewInt(1),
 		Owner:       owner,
 		Mintable:    false,
 		Burnable:    true,
@@ -79,22 +111,24 @@ func NewNFT(id, name, owner string, metadata *AssetMetadata) *Asset {
 // NewStablecoin creates a new stablecoin asset
 func NewStablecoin(id, name, symbol string, owner string) *Asset {
 	return &Asset{
-		ID:        id,
-		Type:      AssetTypeStablecoin,
-		Name:      name,
-		Symbol:    symbol,
-		Decimals:  8,
-		Owner:     owner,
-		Mintable:  true,
-		Burnable:  true,
-		Pausable:  true,
-		CreatedAt: time.Now().Unix(),
-		UpdatedAt: time.Now().Unix(),
+		ID:          id,
+		Type:        AssetTypeStablecoin,
+		Name:        name,
+		Symbol:      symbol,
+		Decimals:    8,
+		Owner:       owner,
+		TotalSupply: new(big.Int),
+		MaxSupply:   new(big.Int),
+		Mintable:    true,
+		Burnable:    true,
+		Pausable:    true,
+		CreatedAt:   time.Now().Unix(),
+		UpdatedAt:   time.Now().Unix(),
 	}
 }
 
 // Mint increases the total supply
-func (a *Asset) Mint(amount uint64) error {
+func (a *Asset) Mint(amount *big.Int) error {
 	if !a.Mintable {
 		return ErrNotMintable
 	}
@@ -103,17 +137,22 @@ func (a *Asset) Mint(amount uint64) error {
 		return ErrAssetPaused
 	}
 	
-	if a.MaxSupply > 0 && a.TotalSupply+amount > a.MaxSupply {
+	if amount.Sign() < 0 {
+		return ErrInvalidAmount
+	}
+	
+	supply := new(big.Int).Add(util.CopyBig(a.TotalSupply), amount)
+	if a.MaxSupply != nil && a.MaxSupply.Sign() > 0 && supply.Cmp(a.MaxSupply) > 0 {
 		return ErrExceedsMaxSupply
 	}
 	
-	a.TotalSupply += amount
+	a.TotalSupply = supply
 	a.UpdatedAt = time.Now().Unix()
 	return nil
 }
 
 // Burn decreases the total supply
-func (a *Asset) Burn(amount uint64) error {
+func (a *Asset) Burn(amount *big.Int) error {
 	if !a.Burnable {
 		return ErrNotBurnable
 	}
@@ -122,11 +161,16 @@ func (a *Asset) Burn(amount uint64) error {
 		return ErrAssetPaused
 	}
 	
-	if a.TotalSupply < amount {
+	if amount.Sign() < 0 {
+		return ErrInvalidAmount
+	}
+	
+	supply, ok := subAmount(a.TotalSupply, amount)
+	if !ok {
 		return ErrInsufficientSupply
 	}
 	
-	a.TotalSupply -= amount
+	a.TotalSupply = supply
 	a.UpdatedAt = time.Now().Unix()
 	return nil
 }
@@ -193,6 +237,9 @@ func (a *Asset) Copy() *Asset {
 		}
 		copy.Metadata = &metadata
 	}
+	if a.Policy != nil {
+		copy.Policy = a.Policy.Copy()
+	}
 	return &copy
 }
 
@@ -218,6 +265,8 @@ var (
 	ErrAssetPaused       = &AssetError{"asset is paused"}
 	ErrExceedsMaxSupply  = &AssetError{"exceeds max supply"}
 	ErrInsufficientSupply = &AssetError{"insufficient supply"}
+	ErrInvalidAmount     = &AssetError{"amount must be non-negative"}
+	ErrAssetExists       = &AssetError{"asset already exists"}
 )
 
 type AssetError struct {
@@ -248,10 +297,12 @@ func NewStablecoinOracle(assetID, pegCurrency string) *StablecoinOracle {
 	}
 }
 
-// UpdatePrice updates the oracle price
-func (o *StablecoinOracle) UpdatePrice(price float64) {
+// UpdatePrice records a price the validator oracle committed from the
+// votes of sources in a block at timestamp
+func (o *StablecoinOracle) UpdatePrice(price float64, sources []string, timestamp int64) {
 	o.Price = price
-	o.LastUpdate = time.Now().Unix()
+	o.Sources = append([]string(nil), sources...)
+	o.LastUpdate = timestamp
 }
 
 // IsStale returns true if the price is stale
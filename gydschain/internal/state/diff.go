@@ -0,0 +1,254 @@
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// FieldChange is one field that differs between two versions of an
+// account or asset. Map entries are named like "balances.GYDS".
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// EntryDiff lists the changed fields of one account or asset
+type EntryDiff struct {
+	ID      string        `json:"id"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// StateDiff is the difference between two states. Node-local timestamps
+// are ignored, and a missing balance equals a zero one.
+type StateDiff struct {
+	RootA           string       `json:"root_a"`
+	RootB           string       `json:"root_b"`
+	AccountsAdded   []string     `json:"accounts_added"`
+	AccountsRemoved []string     `json:"accounts_removed"`
+	AccountsChanged []*EntryDiff `json:"accounts_changed"`
+	AssetsAdded     []string     `json:"assets_added"`
+	AssetsRemoved   []string     `json:"assets_removed"`
+	AssetsChanged   []*EntryDiff `json:"assets_changed"`
+}
+
+// Empty returns true if the two states hold the same accounts and assets
+func (d *StateDiff) Empty() bool {
+	return len(d.AccountsAdded) == 0 && len(d.AccountsRemoved) == 0 && len(d.AccountsChanged) == 0 &&
+		len(d.AssetsAdded) == 0 && len(d.AssetsRemoved) == 0 && len(d.AssetsChanged) == 0
+}
+
+// Diff compares state a against state b; entries only in b are added and
+// entries only in a are removed. Results are sorted by address and asset id.
+func Diff(a, b *StateDB) *StateDiff {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a != b {
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+	}
+
+	diff := &StateDiff{RootA: a.root, RootB: b.root}
+
+	for _, addr := range unionKeys(accountKeys(a.accounts), accountKeys(b.accounts)) {
+		before, after := a.accounts[addr], b.accounts[addr]
+		switch {
+		case before == nil:
+			diff.AccountsAdded = append(diff.AccountsAdded, addr)
+		case after == nil:
+			diff.AccountsRemoved = append(diff.AccountsRemoved, addr)
+		default:
+			if changes := diffAccounts(before, after); len(changes) > 0 {
+				diff.AccountsChanged = append(diff.AccountsChanged, &EntryDiff{ID: addr, Changes: changes})
+			}
+		}
+	}
+
+	for _, id := range unionKeys(assetKeys(a.assets), assetKeys(b.assets)) {
+		before, after := a.assets[id], b.assets[id]
+		switch {
+		case before == nil:
+			diff.AssetsAdded = append(diff.AssetsAdded, id)
+		case after == nil:
+			diff.AssetsRemoved = append(diff.AssetsRemoved, id)
+		default:
+			if changes := diffAssets(before, after); len(changes) > 0 {
+				diff.AssetsChanged = append(diff.AssetsChanged, &EntryDiff{ID: id, Changes: changes})
+			}
+		}
+	}
+
+	return diff
+}
+
+// diffAccounts compares the portable fields of two accounts
+func diffAccounts(a, b *Account) []FieldChange {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var changes []FieldChange
+	if a.Nonce != b.Nonce {
+		changes = append(changes, FieldChange{"nonce", fmt.Sprint(a.Nonce), fmt.Sprint(b.Nonce)})
+	}
+	changes = append(changes, diffAmounts("balances", a.Balances, b.Balances)...)
+	if change, ok := diffAmount("staked", a.Staked, b.Staked); ok {
+		changes = append(changes, change)
+	}
+	changes = append(changes, diffAmounts("delegated", a.Delegated, b.Delegated)...)
+	if !bytes.Equal(a.Code, b.Code) {
+		changes = append(changes, FieldChange{"code", codeSummary(a.Code), codeSummary(b.Code)})
+	}
+	if from, to := vestingSummary(a.Vesting), vestingSummary(b.Vesting); from != to {
+		changes = append(changes, FieldChange{"vesting", from, to})
+	}
+	if from, to := multisigSummary(a.Multisig), multisigSummary(b.Multisig); from != to {
+		changes = append(changes, FieldChange{"multisig", from, to})
+	}
+
+	storageKeys := make([]string, 0, len(a.Storage)+len(b.Storage))
+	for key := range a.Storage {
+		storageKeys = append(storageKeys, key)
+	}
+	for key := range b.Storage {
+		storageKeys = append(storageKeys, key)
+	}
+	for _, key := range sortedUnique(storageKeys) {
+		if !bytes.Equal(a.Storage[key], b.Storage[key]) {
+			changes = append(changes, FieldChange{"storage." + key, hex.EncodeToString(a.Storage[key]), hex.EncodeToString(b.Storage[key])})
+		}
+	}
+	return changes
+}
+
+// vestingSummary describes a vesting schedule for a diff
+func vestingSummary(v *VestingSchedule) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s initial) %d-%d, cliff %d", util.CopyBig(v.Total), util.CopyBig(v.Initial), v.Start, v.End, v.Cliff)
+}
+
+// multisigSummary describes a multisig config for a diff
+func multisigSummary(m *MultisigConfig) string {
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-of-%d %s", m.Threshold, len(m.PubKeys), strings.Join(m.PubKeys, ","))
+}
+
+// diffAssets compares the fields of two assets other than timestamps
+func diffAssets(a, b *Asset) []FieldChange {
+	var changes []FieldChange
+	compare := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, FieldChange{field, fmt.Sprint(from), fmt.Sprint(to)})
+		}
+	}
+	compare("type", a.Type, b.Type)
+	compare("name", a.Name, b.Name)
+	compare("symbol", a.Symbol, b.Symbol)
+	compare("decimals", a.Decimals, b.Decimals)
+	if change, ok := diffAmount("total_supply", a.TotalSupply, b.TotalSupply); ok {
+		changes = append(changes, change)
+	}
+	if change, ok := diffAmount("max_supply", a.MaxSupply, b.MaxSupply); ok {
+		changes = append(changes, change)
+	}
+	compare("owner", a.Owner, b.Owner)
+	compare("mintable", a.Mintable, b.Mintable)
+	compare("burnable", a.Burnable, b.Burnable)
+	compare("pausable", a.Pausable, b.Pausable)
+	compare("paused", a.Paused, b.Paused)
+	compare("metadata", jsonString(a.Metadata), jsonString(b.Metadata))
+	compare("policy", jsonString(a.Policy), jsonString(b.Policy))
+	return changes
+}
+
+// diffAmounts compares two amount maps entry by entry
+func diffAmounts(field string, a, b map[string]*big.Int) []FieldChange {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		keys = append(keys, key)
+	}
+
+	var changes []FieldChange
+	for _, key := range sortedUnique(keys) {
+		if change, ok := diffAmount(field+"."+key, a[key], b[key]); ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// diffAmount compares two amounts, treating nil as zero
+func diffAmount(field string, a, b *big.Int) (FieldChange, bool) {
+	from, to := util.CopyBig(a), util.CopyBig(b)
+	if from.Cmp(to) == 0 {
+		return FieldChange{}, false
+	}
+	return FieldChange{field, from.String(), to.String()}, true
+}
+
+// codeSummary identifies contract code without printing all of it
+func codeSummary(code []byte) string {
+	if len(code) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(code)
+	return fmt.Sprintf("%d bytes sha256:%s", len(code), hex.EncodeToString(sum[:8]))
+}
+
+// jsonString renders a nested value for comparison
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+func accountKeys(m map[string]*Account) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func assetKeys(m map[string]*Asset) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// unionKeys returns the sorted union of two key sets
+func unionKeys(a, b []string) []string {
+	return sortedUnique(append(a, b...))
+}
+
+// sortedUnique sorts keys and drops duplicates
+func sortedUnique(keys []string) []string {
+	sort.Strings(keys)
+	out := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			out = append(out, key)
+		}
+	}
+	return out
+}
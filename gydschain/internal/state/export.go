@@ -0,0 +1,260 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// AccountExportFormat is the only supported bulk account format: one
+// AccountRecord per line, sorted by address
+const AccountExportFormat = "jsonl"
+
+// maxRecordSize bounds a single JSONL line; contract storage can be large
+const maxRecordSize = 64 * 1024 * 1024
+
+// AccountRecord is the portable form of an account. Node-local timestamps
+// are left out so two operators exporting the same state produce
+// byte-identical files.
+type AccountRecord struct {
+	Address   string              `json:"address"`
+	Nonce     uint64              `json:"nonce"`
+	Balances  map[string]*big.Int `json:"balances"`
+	Staked    *big.Int            `json:"staked,omitempty"`
+	Delegated map[string]*big.Int `json:"delegated,omitempty"`
+	Code      []byte              `json:"code,omitempty"`
+	Storage   map[string][]byte   `json:"storage,omitempty"`
+	Vesting   *VestingSchedule    `json:"vesting,omitempty"`
+	Multisig  *MultisigConfig     `json:"multisig,omitempty"`
+}
+
+// MarshalJSON encodes amounts as decimal strings, leaving out a zero stake
+func (r AccountRecord) MarshalJSON() ([]byte, error) {
+	type plain AccountRecord
+	var staked *util.Big
+	if r.Staked != nil && r.Staked.Sign() > 0 {
+		staked = (*util.Big)(r.Staked)
+	}
+	var delegated util.BigMap
+	if len(r.Delegated) > 0 {
+		delegated = r.Delegated
+	}
+	return json.Marshal(struct {
+		*plain
+		Balances  util.BigMap `json:"balances"`
+		Staked    *util.Big   `json:"staked,omitempty"`
+		Delegated util.BigMap `json:"delegated,omitempty"`
+	}{(*plain)(&r), util.BigMap(r.Balances), staked, delegated})
+}
+
+// UnmarshalJSON decodes amounts from strings or numbers
+func (r *AccountRecord) UnmarshalJSON(data []byte) error {
+	type plain AccountRecord
+	dec := struct {
+		*plain
+		Balances  util.BigMap `json:"balances"`
+		Staked    *util.Big   `json:"staked,omitempty"`
+		Delegated util.BigMap `json:"delegated,omitempty"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	r.Balances, r.Staked, r.Delegated = dec.Balances, dec.Staked.Int(), dec.Delegated
+	return nil
+}
+
+// ExportFilter selects which accounts are exported
+type ExportFilter struct {
+	MinBalance       *big.Int // skip accounts holding less than this of Asset
+	Asset            string   // asset MinBalance applies to; defaults to GYDS
+	ExcludeContracts bool
+}
+
+// matches reports whether an account passes the filter
+func (f *ExportFilter) matches(account *Account) bool {
+	if f == nil {
+		return true
+	}
+	if f.ExcludeContracts && len(account.Code) > 0 {
+		return false
+	}
+	if f.MinBalance == nil {
+		return true
+	}
+	asset := f.Asset
+	if asset == "" {
+		asset = "GYDS"
+	}
+	return util.CopyBig(account.Balances[asset]).Cmp(f.MinBalance) >= 0
+}
+
+// newAccountRecord converts an account for export; callers must hold the
+// account's lock or own the account
+func newAccountRecord(account *Account) *AccountRecord {
+	record := &AccountRecord{
+		Address:  account.Address,
+		Nonce:    account.Nonce,
+		Balances: account.Balances,
+		Staked:   account.Staked,
+		Code:     account.Code,
+		Vesting:  account.Vesting,
+		Multisig: account.Multisig,
+	}
+	if len(account.Delegated) > 0 {
+		record.Delegated = account.Delegated
+	}
+	if len(account.Storage) > 0 {
+		record.Storage = account.Storage
+	}
+	if record.Balances == nil {
+		record.Balances = make(map[string]*big.Int)
+	}
+	return record
+}
+
+// toAccount converts an imported record back into an account
+func (r *AccountRecord) toAccount() *Account {
+	account := NewAccount(r.Address)
+	account.Nonce = r.Nonce
+	account.Staked = util.CopyBig(r.Staked)
+	account.Code = r.Code
+	if r.Vesting != nil {
+		account.Vesting = r.Vesting.Copy()
+	}
+	if r.Multisig != nil {
+		account.Multisig = r.Multisig.Copy()
+	}
+	for asset, amount := range r.Balances {
+		account.Balances[asset] = amount
+	}
+	for validator, amount := range r.Delegated {
+		account.Delegated[validator] = amount
+	}
+	for key, value := range r.Storage {
+		account.Storage[key] = value
+	}
+	return account
+}
+
+// ExportAccounts writes accounts passing the filter as JSONL in address
+// order and returns how many were written
+func (s *StateDB) ExportAccounts(w io.Writer, filter *ExportFilter) (int, error) {
+	s.mu.RLock()
+	addresses := make([]string, 0, len(s.accounts))
+	accounts := make(map[string]*Account, len(s.accounts))
+	for addr, account := range s.accounts {
+		addresses = append(addresses, addr)
+		accounts[addr] = account.Copy()
+	}
+	s.mu.RUnlock()
+	sort.Strings(addresses)
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	count := 0
+	for _, addr := range addresses {
+		account := accounts[addr]
+		if !filter.matches(account) {
+			continue
+		}
+		// Encoder sorts map keys and ends each record with a newline
+		if err := enc.Encode(newAccountRecord(account)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, bw.Flush()
+}
+
+// ImportAccounts reads JSONL account records written by ExportAccounts and
+// stores them; records must be in strictly increasing address order so a
+// truncated or concatenated file is rejected. It returns the number imported.
+func (s *StateDB) ImportAccounts(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	var records []*AccountRecord
+	previous := ""
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record AccountRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return 0, ErrInvalidAccountRecord
+		}
+		if record.Address == "" {
+			return 0, ErrInvalidAccountRecord
+		}
+		if len(records) > 0 && record.Address <= previous {
+			return 0, ErrAccountsOutOfOrder
+		}
+		previous = record.Address
+		records = append(records, &record)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	// Apply only after the whole file parsed so a bad line leaves state untouched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range records {
+		s.accounts[record.Address] = record.toAccount()
+		s.dirty[record.Address] = true
+	}
+	return len(records), nil
+}
+
+// LoadExport rebuilds a state database from the output of Export, such as
+// a published snapshot file
+func LoadExport(data []byte) (*StateDB, error) {
+	var export stateExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	return export.load()
+}
+
+// load builds a state database holding the exported accounts and assets
+func (e *stateExport) load() (*StateDB, error) {
+	s := NewStateDB()
+	for addr, account := range e.Accounts {
+		if account == nil {
+			continue
+		}
+		if account.Balances == nil {
+			account.Balances = make(map[string]*big.Int)
+		}
+		if account.Delegated == nil {
+			account.Delegated = make(map[string]*big.Int)
+		}
+		if account.Storage == nil {
+			account.Storage = make(map[string][]byte)
+		}
+		s.accounts[addr] = account
+	}
+	for id, asset := range e.Assets {
+		if asset != nil {
+			s.assets[id] = asset
+		}
+	}
+	s.root = e.Root
+	s.height = e.Height
+	if err := s.rebuildTrie(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Account import errors
+var (
+	ErrInvalidAccountRecord = &StateError{"invalid account record"}
+	ErrAccountsOutOfOrder   = &StateError{"account records not in ascending address order"}
+)
@@ -1,8 +1,11 @@
 package state
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
+	"io"
 	"sort"
 )
 
@@ -163,12 +166,17 @@ type AccountStateProof struct {
 	Proof      *StateProof   `json:"proof"`
 }
 
-// PatriciaTrie represents a Patricia Merkle Trie for efficient state storage
+// PatriciaTrie is a path-compressed radix trie whose root hash commits to
+// every key and value it holds. The same set of entries always produces
+// the same root, whatever order they were inserted or deleted in. Hashes
+// are computed lazily, so a batch of updates costs one rehash of the
+// paths it touched.
 type PatriciaTrie struct {
 	root *TrieNode
 }
 
-// TrieNode represents a node in the Patricia Trie
+// TrieNode is a node of the trie. Key is the edge segment from the parent;
+// a nil Hash marks a node changed since the last RootHash.
 type TrieNode struct {
 	Key      []byte
 	Value    []byte
@@ -179,110 +187,328 @@ type TrieNode struct {
 // NewPatriciaTrie creates a new Patricia Trie
 func NewPatriciaTrie() *PatriciaTrie {
 	return &PatriciaTrie{
-		root: &TrieNode{
-			Children: make(map[byte]*TrieNode),
-		},
+		root: newTrieNode(nil, nil),
 	}
 }
 
-// Insert adds a key-value pair to the trie
+func newTrieNode(key, value []byte) *TrieNode {
+	return &TrieNode{
+		Key:      key,
+		Value:    value,
+		Children: make(map[byte]*TrieNode),
+	}
+}
+
+// Insert adds or replaces the value stored under key
 func (t *PatriciaTrie) Insert(key, value []byte) {
-	node := t.root
-	
-	for _, b := range key {
-		if node.Children[b] == nil {
-			node.Children[b] = &TrieNode{
-				Children: make(map[byte]*TrieNode),
-			}
-		}
-		node = node.Children[b]
+	// A nil value marks an empty node, so stored values are never nil
+	t.insert(t.root, append([]byte(nil), key...), append([]byte{}, value...))
+}
+
+func (t *PatriciaTrie) insert(node *TrieNode, key, value []byte) {
+	node.Hash = nil
+	if len(key) == 0 {
+		node.Value = value
+		return
 	}
-	
-	node.Key = key
-	node.Value = value
-	t.updateHashes(t.root)
+
+	child := node.Children[key[0]]
+	if child == nil {
+		node.Children[key[0]] = newTrieNode(key, value)
+		return
+	}
+
+	common := commonPrefix(child.Key, key)
+	if common < len(child.Key) {
+		// Split the edge where the keys diverge
+		split := newTrieNode(child.Key[:common], nil)
+		child.Key = child.Key[common:]
+		split.Children[child.Key[0]] = child
+		node.Children[key[0]] = split
+		child = split
+	}
+	t.insert(child, key[common:], value)
 }
 
-// Get retrieves a value by key
+// Get retrieves a value by key, or nil if it is not present
 func (t *PatriciaTrie) Get(key []byte) []byte {
 	node := t.root
-	
-	for _, b := range key {
-		if node.Children[b] == nil {
+	for len(key) > 0 {
+		child := node.Children[key[0]]
+		if child == nil || !bytes.HasPrefix(key, child.Key) {
 			return nil
 		}
-		node = node.Children[b]
+		key = key[len(child.Key):]
+		node = child
 	}
-	
 	return node.Value
 }
 
-// Delete removes a key from the trie
+// Delete removes a key from the trie and reports whether it was present
 func (t *PatriciaTrie) Delete(key []byte) bool {
-	return t.deleteRecursive(t.root, key, 0)
+	return t.delete(t.root, key)
 }
 
-func (t *PatriciaTrie) deleteRecursive(node *TrieNode, key []byte, depth int) bool {
-	if depth == len(key) {
+func (t *PatriciaTrie) delete(node *TrieNode, key []byte) bool {
+	if len(key) == 0 {
 		if node.Value == nil {
 			return false
 		}
 		node.Value = nil
-		return len(node.Children) == 0
+		node.Hash = nil
+		return true
 	}
-	
-	b := key[depth]
+
+	b := key[0]
 	child := node.Children[b]
-	if child == nil {
+	if child == nil || !bytes.HasPrefix(key, child.Key) {
 		return false
 	}
-	
-	shouldDelete := t.deleteRecursive(child, key, depth+1)
-	if shouldDelete {
-		delete(node.Children, b)
-		return len(node.Children) == 0 && node.Value == nil
+	if !t.delete(child, key[len(child.Key):]) {
+		return false
 	}
-	
-	return false
+	node.Hash = nil
+
+	// Drop emptied nodes and merge a valueless node into its only child so
+	// the shape depends only on the keys present
+	if child.Value == nil {
+		switch len(child.Children) {
+		case 0:
+			delete(node.Children, b)
+		case 1:
+			for _, grandchild := range child.Children {
+				grandchild.Key = append(append([]byte(nil), child.Key...), grandchild.Key...)
+				grandchild.Hash = nil
+				node.Children[b] = grandchild
+			}
+		}
+	}
+	return true
 }
 
-// RootHash returns the root hash of the trie
+// Copy returns an independent copy of the trie
+func (t *PatriciaTrie) Copy() *PatriciaTrie {
+	return &PatriciaTrie{root: copyTrieNode(t.root)}
+}
+
+func copyTrieNode(node *TrieNode) *TrieNode {
+	copied := &TrieNode{
+		Key:      node.Key,
+		Value:    node.Value,
+		Hash:     node.Hash,
+		Children: make(map[byte]*TrieNode, len(node.Children)),
+	}
+	for b, child := range node.Children {
+		copied.Children[b] = copyTrieNode(child)
+	}
+	return copied
+}
+
+// RootHash returns the root hash of the trie; an empty trie hashes to zeros
 func (t *PatriciaTrie) RootHash() []byte {
-	if t.root == nil {
+	if t.root == nil || (t.root.Value == nil && len(t.root.Children) == 0) {
 		return make([]byte, 32)
 	}
-	return t.root.Hash
+	return t.hash(t.root)
 }
 
-// updateHashes updates hashes from a node to root
-func (t *PatriciaTrie) updateHashes(node *TrieNode) {
-	if node == nil {
-		return
+// RootHashHex returns the hex-encoded root hash
+func (t *PatriciaTrie) RootHashHex() string {
+	return hex.EncodeToString(t.RootHash())
+}
+
+// hash returns a node's hash, recomputing it if the node changed
+func (t *PatriciaTrie) hash(node *TrieNode) []byte {
+	if node.Hash == nil {
+		sum := sha256.Sum256(t.encode(node))
+		node.Hash = sum[:]
 	}
-	
-	// Collect child hashes
-	var childHashes [][]byte
-	
-	// Sort keys for deterministic ordering
+	return node.Hash
+}
+
+// encode returns the preimage of a node's hash: the length-prefixed edge,
+// a value flag and length-prefixed value, then each child's branch byte
+// and hash in byte order. Proofs carry these encodings.
+func (t *PatriciaTrie) encode(node *TrieNode) []byte {
 	keys := make([]byte, 0, len(node.Children))
 	for k := range node.Children {
 		keys = append(keys, k)
 	}
 	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
-	
+
+	var buf bytes.Buffer
+	writeLengthPrefixed(&buf, node.Key)
+	if node.Value != nil {
+		buf.WriteByte(1)
+		writeLengthPrefixed(&buf, node.Value)
+	} else {
+		buf.WriteByte(0)
+	}
 	for _, k := range keys {
-		t.updateHashes(node.Children[k])
-		childHashes = append(childHashes, node.Children[k].Hash)
+		buf.WriteByte(k)
+		buf.Write(t.hash(node.Children[k]))
 	}
-	
-	// Calculate node hash
-	var data []byte
-	data = append(data, node.Key...)
-	data = append(data, node.Value...)
-	for _, h := range childHashes {
-		data = append(data, h...)
+	return buf.Bytes()
+}
+
+// Prove returns the encodings of the nodes on the path to key, root
+// first. If key is absent the path ends where it leaves the trie, which
+// proves the absence. An empty trie has an empty proof.
+func (t *PatriciaTrie) Prove(key []byte) [][]byte {
+	if t.root == nil || (t.root.Value == nil && len(t.root.Children) == 0) {
+		return nil
 	}
-	
-	hash := sha256.Sum256(data)
-	node.Hash = hash[:]
+
+	node := t.root
+	proof := [][]byte{t.encode(node)}
+	for len(key) > 0 {
+		child := node.Children[key[0]]
+		if child == nil {
+			break
+		}
+		proof = append(proof, t.encode(child))
+		if !bytes.HasPrefix(key, child.Key) {
+			break
+		}
+		key = key[len(child.Key):]
+		node = child
+	}
+	return proof
+}
+
+// VerifyTrieProof checks a proof from Prove against a trusted root hash.
+// It returns the value stored under key, or nil if the proof shows the key
+// is absent; an error means the proof does not match the root.
+func VerifyTrieProof(root, key []byte, proof [][]byte) ([]byte, error) {
+	if len(proof) == 0 {
+		if bytes.Equal(root, make([]byte, 32)) {
+			return nil, nil
+		}
+		return nil, ErrInvalidProof
+	}
+
+	expected := root
+	for i, encoded := range proof {
+		sum := sha256.Sum256(encoded)
+		if !bytes.Equal(sum[:], expected) {
+			return nil, ErrInvalidProof
+		}
+		node, err := decodeTrieNode(encoded)
+		if err != nil {
+			return nil, err
+		}
+		last := i == len(proof)-1
+
+		if i == 0 && len(node.key) != 0 {
+			return nil, ErrInvalidProof
+		}
+		if i > 0 && (len(node.key) == 0 || node.key[0] != key[0]) {
+			return nil, ErrInvalidProof
+		}
+		if !bytes.HasPrefix(key, node.key) {
+			// The path leaves the trie inside this node's edge
+			if !last {
+				return nil, ErrInvalidProof
+			}
+			return nil, nil
+		}
+		key = key[len(node.key):]
+
+		if len(key) == 0 {
+			if !last {
+				return nil, ErrInvalidProof
+			}
+			return node.value, nil
+		}
+		child, ok := node.children[key[0]]
+		if !ok {
+			// No branch for the next key byte
+			if !last {
+				return nil, ErrInvalidProof
+			}
+			return nil, nil
+		}
+		if last {
+			return nil, ErrInvalidProof
+		}
+		expected = child
+	}
+	return nil, ErrInvalidProof
+}
+
+// decodedTrieNode is a node parsed from its encoding
+type decodedTrieNode struct {
+	key      []byte
+	value    []byte
+	children map[byte][]byte
 }
+
+// decodeTrieNode parses the output of encode
+func decodeTrieNode(data []byte) (*decodedTrieNode, error) {
+	r := bytes.NewReader(data)
+	node := &decodedTrieNode{children: make(map[byte][]byte)}
+
+	key, err := readLengthPrefixed(r)
+	if err != nil {
+		return nil, err
+	}
+	node.key = key
+
+	flag, err := r.ReadByte()
+	if err != nil {
+		return nil, ErrInvalidProof
+	}
+	switch flag {
+	case 0:
+	case 1:
+		if node.value, err = readLengthPrefixed(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidProof
+	}
+
+	for r.Len() > 0 {
+		if r.Len() < 1+sha256.Size {
+			return nil, ErrInvalidProof
+		}
+		b, _ := r.ReadByte()
+		hash := make([]byte, sha256.Size)
+		r.Read(hash)
+		node.children[b] = hash
+	}
+	return node, nil
+}
+
+// writeLengthPrefixed writes data preceded by its length
+func writeLengthPrefixed(w io.Writer, data []byte) {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(data)))
+	w.Write(length[:n])
+	w.Write(data)
+}
+
+// readLengthPrefixed reads data written by writeLengthPrefixed
+func readLengthPrefixed(r *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil || length > uint64(r.Len()) {
+		return nil, ErrInvalidProof
+	}
+	data := make([]byte, length)
+	r.Read(data)
+	return data, nil
+}
+
+// commonPrefix returns the length of the shared prefix of a and b
+func commonPrefix(a, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// Proof errors
+var (
+	ErrInvalidProof = &StateError{"invalid state proof"}
+)
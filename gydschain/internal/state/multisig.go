@@ -0,0 +1,39 @@
+package state
+
+// MultisigConfig makes an account spendable only by transactions carrying
+// Threshold signatures from distinct PubKeys
+type MultisigConfig struct {
+	PubKeys   []string `json:"pub_keys"` // hex ed25519 keys
+	Threshold int      `json:"threshold"`
+}
+
+// Copy returns a deep copy of the config
+func (m *MultisigConfig) Copy() *MultisigConfig {
+	return &MultisigConfig{PubKeys: append([]string(nil), m.PubKeys...), Threshold: m.Threshold}
+}
+
+// SetMultisig makes the account a multisig account; nil removes the config
+func (a *Account) SetMultisig(config *MultisigConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if config != nil {
+		config = config.Copy()
+	}
+	a.Multisig = config
+}
+
+// GetMultisig returns a copy of the account's multisig config, or nil for
+// an ordinary account
+func (a *Account) GetMultisig() *MultisigConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.Multisig == nil {
+		return nil
+	}
+	return a.Multisig.Copy()
+}
+
+// Multisig errors
+var (
+	ErrMultisigExists = &StateError{"multisig account already exists"}
+)
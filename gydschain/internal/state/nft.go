@@ -0,0 +1,61 @@
+package state
+
+import "math/big"
+
+// NFTs live in the asset table. A collection is an AssetTypeNFT asset whose
+// supply counts its minted tokens; each token is a further AssetTypeNFT
+// asset, ID "<collection>/<token>", whose Owner holds it with a balance of
+// one. Tokens therefore share the asset trie, archive and snapshots.
+
+// NFTAssetID returns the asset ID of a token in collection
+func NFTAssetID(collection, tokenID string) string {
+	return collection + "/" + tokenID
+}
+
+// NewNFTCollection creates an empty, uncapped NFT collection
+func NewNFTCollection(id, name, owner string) *Asset {
+	collection := NewNFT(id, name, owner, nil)
+	collection.Symbol = id
+	collection.TotalSupply = new(big.Int)
+	collection.MaxSupply = new(big.Int)
+	collection.Mintable = true
+	collection.Burnable = false
+	return collection
+}
+
+// TransferNFT moves a token from its owner to another address
+func (a *Asset) TransferNFT(from, to string) error {
+	if a.Type != AssetTypeNFT {
+		return ErrNotNFT
+	}
+	if a.Owner != from {
+		return ErrNotNFTOwner
+	}
+	a.Owner = to
+	return nil
+}
+
+// GetNFT returns a token of collection, or nil if it was never minted
+func (s *StateDB) GetNFT(collection, tokenID string) *Asset {
+	token := s.GetAsset(NFTAssetID(collection, tokenID))
+	if token == nil || token.Type != AssetTypeNFT {
+		return nil
+	}
+	return token
+}
+
+// NFTOwner returns the address holding a token
+func (s *StateDB) NFTOwner(collection, tokenID string) (string, error) {
+	token := s.GetNFT(collection, tokenID)
+	if token == nil {
+		return "", ErrNFTNotFound
+	}
+	return token.Owner, nil
+}
+
+// NFT errors
+var (
+	ErrNotNFT      = &AssetError{"asset is not an NFT"}
+	ErrNotNFTOwner = &AssetError{"sender does not own the NFT"}
+	ErrNFTNotFound = &AssetError{"NFT not found"}
+)
@@ -0,0 +1,131 @@
+package state
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// MaxTransferFeeBasisPoints caps the transfer tax an asset can charge (25%)
+const MaxTransferFeeBasisPoints = 2500
+
+// TransferPolicy is a declarative rule set enforced on every transfer of an asset
+type TransferPolicy struct {
+	FeeBasisPoints uint64   `json:"fee_basis_points"` // transfer tax, 100 = 1%
+	FeeSink        string   `json:"fee_sink,omitempty"`
+	WhitelistOnly  bool     `json:"whitelist_only"`
+	Whitelist      []string `json:"whitelist,omitempty"`
+}
+
+func init() {
+	// Empty data on a set-policy transaction clears the policy
+	tx.RegisterPayload(tx.TxTypeSetPolicy, false, func() tx.Payload { return &TransferPolicy{} })
+}
+
+// DecodeTransferPolicy parses a policy from transaction data
+func DecodeTransferPolicy(data []byte) (*TransferPolicy, error) {
+	var policy TransferPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, ErrInvalidPolicy
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks the policy parameters
+func (p *TransferPolicy) Validate() error {
+	if p.FeeBasisPoints > MaxTransferFeeBasisPoints {
+		return ErrPolicyFeeTooHigh
+	}
+	if p.FeeBasisPoints > 0 && p.FeeSink == "" {
+		return ErrPolicyMissingSink
+	}
+	return nil
+}
+
+// IsAllowed returns true if the address may hold the asset
+func (p *TransferPolicy) IsAllowed(address string) bool {
+	if !p.WhitelistOnly {
+		return true
+	}
+	for _, allowed := range p.Whitelist {
+		if allowed == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Fee returns the transfer tax for an amount
+func (p *TransferPolicy) Fee(amount *big.Int) *big.Int {
+	fee := new(big.Int).Mul(amount, new(big.Int).SetUint64(p.FeeBasisPoints))
+	return fee.Quo(fee, big.NewInt(10000))
+}
+
+// Copy creates a deep copy of the policy
+func (p *TransferPolicy) Copy() *TransferPolicy {
+	copy := *p
+	copy.Whitelist = append([]string(nil), p.Whitelist...)
+	return &copy
+}
+
+// SetTransferPolicy replaces the asset's transfer policy; only the owner may do so.
+// A nil policy removes all restrictions. now is the time of the transaction
+// setting it, so every node stamps the asset alike.
+func (a *Asset) SetTransferPolicy(caller string, policy *TransferPolicy, now int64) error {
+	if caller != a.Owner {
+		return ErrNotAssetOwner
+	}
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	a.Policy = policy
+	a.UpdatedAt = now
+	return nil
+}
+
+// ApplyTransferPolicy checks a transfer against the asset's policy and
+// returns the tax to route to the fee sink. The owner and the fee sink
+// are always allowed to hold the asset and are never taxed.
+func (a *Asset) ApplyTransferPolicy(from, to string, amount *big.Int) (*big.Int, error) {
+	if a.Paused {
+		return nil, ErrAssetPaused
+	}
+
+	p := a.Policy
+	if p == nil {
+		return new(big.Int), nil
+	}
+
+	exempt := func(addr string) bool {
+		return addr == a.Owner || addr == p.FeeSink
+	}
+
+	if !exempt(from) && !p.IsAllowed(from) {
+		return nil, ErrSenderNotWhitelisted
+	}
+	if !exempt(to) && !p.IsAllowed(to) {
+		return nil, ErrRecipientNotWhitelisted
+	}
+
+	if exempt(from) || exempt(to) {
+		return new(big.Int), nil
+	}
+	return p.Fee(amount), nil
+}
+
+// Policy errors
+var (
+	ErrInvalidPolicy           = &AssetError{"invalid transfer policy"}
+	ErrPolicyFeeTooHigh        = &AssetError{"transfer fee exceeds maximum"}
+	ErrPolicyMissingSink       = &AssetError{"transfer fee requires a fee sink"}
+	ErrNotAssetOwner           = &AssetError{"caller is not the asset owner"}
+	ErrSenderNotWhitelisted    = &AssetError{"sender not whitelisted for asset"}
+	ErrRecipientNotWhitelisted = &AssetError{"recipient not whitelisted for asset"}
+)
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// GetProof returns an inclusion proof for an account against the committed
+// state root. Changes made since the last commit are not reflected. If the
+// account does not exist the proof shows its absence and Account is nil.
+func (s *StateDB) GetProof(address string) (*AccountStateProof, error) {
+	// Hashing is lazy and caches into trie nodes, so take the write lock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := accountTriePrefix + address
+	value := s.trie.Get([]byte(key))
+
+	proof := &AccountStateProof{
+		Address: address,
+		Proof: &StateProof{
+			Key:    key,
+			Value:  value,
+			Proof:  s.trie.Prove([]byte(key)),
+			Root:   s.trie.RootHashHex(),
+			Height: s.height,
+		},
+	}
+	if value != nil {
+		account, err := decodeAccountLeaf(value)
+		if err != nil {
+			return nil, err
+		}
+		proof.Account = account
+	}
+	return proof, nil
+}
+
+// VerifyAccountProof checks an account proof against a trusted state root,
+// such as the StateRoot of a verified block header. It returns the proven
+// account, or nil if the proof shows the account does not exist. The
+// Account and Root fields of the proof are not trusted.
+func VerifyAccountProof(proof *AccountStateProof, trustedRoot string) (*Account, error) {
+	if proof == nil || proof.Proof == nil || proof.Proof.Key != accountTriePrefix+proof.Address {
+		return nil, ErrInvalidProof
+	}
+	root, err := hex.DecodeString(trustedRoot)
+	if err != nil || len(root) != 32 {
+		return nil, ErrInvalidProof
+	}
+
+	value, err := VerifyTrieProof(root, []byte(proof.Proof.Key), proof.Proof.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return decodeAccountLeaf(value)
+}
+
+// decodeAccountLeaf converts a trie leaf back into an account
+func decodeAccountLeaf(value []byte) (*Account, error) {
+	var record AccountRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, ErrInvalidProof
+	}
+	return record.toAccount(), nil
+}
@@ -0,0 +1,217 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultSnapshotKeep is how many on-disk state snapshots are kept
+const DefaultSnapshotKeep = 2
+
+// snapshotPrefix and snapshotSuffix frame the height in snapshot file names
+const (
+	snapshotPrefix = "state-"
+	snapshotSuffix = ".json"
+)
+
+// Snapshotter writes the full state to disk every interval heights so it
+// survives restarts and can be exported offline. Only the newest keep
+// snapshots are retained; older files are removed after each write.
+type Snapshotter struct {
+	mu       sync.Mutex
+	dir      string
+	interval uint64
+	keep     int
+}
+
+// NewSnapshotter creates a snapshotter writing to dir; interval 0 disables
+// periodic snapshots and keep 0 uses DefaultSnapshotKeep
+func NewSnapshotter(dir string, interval uint64, keep int) *Snapshotter {
+	if keep <= 0 {
+		keep = DefaultSnapshotKeep
+	}
+	return &Snapshotter{
+		dir:      dir,
+		interval: interval,
+		keep:     keep,
+	}
+}
+
+// Due returns true if a snapshot should be taken at height
+func (sn *Snapshotter) Due(height uint64) bool {
+	return sn.interval > 0 && height > 0 && height%sn.interval == 0
+}
+
+// MaybeWrite snapshots the state if height falls on the interval. It
+// returns the path written, or "" if no snapshot was due.
+func (sn *Snapshotter) MaybeWrite(s *StateDB, height uint64) (string, error) {
+	if !sn.Due(height) {
+		return "", nil
+	}
+	return sn.Write(s, height)
+}
+
+// Write snapshots the committed state as of height. The file is written
+// under a temporary name and renamed so a crash never leaves a partial
+// snapshot behind.
+func (sn *Snapshotter) Write(s *StateDB, height uint64) (string, error) {
+	data, err := s.exportAt(height)
+	if err != nil {
+		return "", err
+	}
+
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	if err := os.MkdirAll(sn.dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := sn.path(height)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return path, sn.prune()
+}
+
+// Heights returns the heights of the snapshots on disk, oldest first
+func (sn *Snapshotter) Heights() ([]uint64, error) {
+	entries, err := ioutil.ReadDir(sn.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var heights []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+		height, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+// Latest returns the path and height of the newest snapshot on disk
+func (sn *Snapshotter) Latest() (string, uint64, error) {
+	heights, err := sn.Heights()
+	if err != nil {
+		return "", 0, err
+	}
+	if len(heights) == 0 {
+		return "", 0, ErrNoSnapshot
+	}
+	height := heights[len(heights)-1]
+	return sn.path(height), height, nil
+}
+
+// path returns the file a snapshot at height is written to
+func (sn *Snapshotter) path(height uint64) string {
+	return filepath.Join(sn.dir, fmt.Sprintf("%s%d%s", snapshotPrefix, height, snapshotSuffix))
+}
+
+// prune removes all but the newest keep snapshots
+func (sn *Snapshotter) prune() error {
+	heights, err := sn.Heights()
+	if err != nil {
+		return err
+	}
+	for len(heights) > sn.keep {
+		if err := os.Remove(sn.path(heights[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		heights = heights[1:]
+	}
+	return nil
+}
+
+// LoadSnapshot rebuilds a state database from a snapshot file and returns
+// the height it was taken at
+func LoadSnapshot(path string) (*StateDB, uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var export stateExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, 0, ErrInvalidSnapshot
+	}
+	s, err := export.load()
+	if err != nil {
+		return nil, 0, err
+	}
+	return s, export.Height, nil
+}
+
+// Restore replaces the state with an export, such as one carried in a
+// chain snapshot, and commits it at height so history starts there. The
+// exported accounts and assets must hash to the export's recorded root.
+func (s *StateDB) Restore(data []byte, height uint64) error {
+	loaded, err := LoadExport(data)
+	if err != nil {
+		return ErrInvalidSnapshot
+	}
+	if loaded.trie.RootHashHex() != loaded.root {
+		return ErrSnapshotRoot
+	}
+
+	s.mu.Lock()
+	s.accounts = loaded.accounts
+	s.assets = loaded.assets
+	s.trie = loaded.trie
+	s.dirty = make(map[string]bool, len(s.accounts))
+	for addr := range s.accounts {
+		s.dirty[addr] = true
+	}
+	s.dirtyAssets = make(map[string]bool, len(s.assets))
+	for id := range s.assets {
+		s.dirtyAssets[id] = true
+	}
+	s.mu.Unlock()
+
+	_, err = s.CommitAt(height)
+	return err
+}
+
+// exportAt exports the entire state tagged with the height it was committed at
+func (s *StateDB) exportAt(height uint64) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return json.Marshal(stateExport{
+		Height:   height,
+		Accounts: s.accounts,
+		Assets:   s.assets,
+		Root:     s.root,
+	})
+}
+
+// Snapshot errors
+var (
+	ErrNoSnapshot      = &StateError{"no state snapshot found"}
+	ErrInvalidSnapshot = &StateError{"invalid state snapshot"}
+	ErrSnapshotRoot    = &StateError{"state snapshot does not match its state root"}
+)
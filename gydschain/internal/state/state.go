@@ -2,24 +2,37 @@ package state
 
 import (
 	"encoding/json"
+	"math/big"
 	"sync"
 )
 
+// State trie key prefixes; accounts and assets share one trie
+const (
+	accountTriePrefix = "account/"
+	assetTriePrefix   = "asset/"
+)
+
 // StateDB manages the world state
 type StateDB struct {
-	mu       sync.RWMutex
-	accounts map[string]*Account
-	assets   map[string]*Asset
-	dirty    map[string]bool
-	root     string
+	mu          sync.RWMutex
+	accounts    map[string]*Account
+	assets      map[string]*Asset
+	dirty       map[string]bool
+	dirtyAssets map[string]bool
+	root        string
+	height      uint64
+	trie        *PatriciaTrie
+	archive     *Archive
 }
 
 // NewStateDB creates a new state database
 func NewStateDB() *StateDB {
 	return &StateDB{
-		accounts: make(map[string]*Account),
-		assets:   make(map[string]*Asset),
-		dirty:    make(map[string]bool),
+		accounts:    make(map[string]*Account),
+		assets:      make(map[string]*Asset),
+		dirty:       make(map[string]bool),
+		dirtyAssets: make(map[string]bool),
+		trie:        NewPatriciaTrie(),
 	}
 }
 
@@ -55,16 +68,16 @@ func (s *StateDB) DeleteAccount(address string) {
 }
 
 // GetBalance returns the balance for an address and asset
-func (s *StateDB) GetBalance(address, asset string) uint64 {
+func (s *StateDB) GetBalance(address, asset string) *big.Int {
 	account := s.GetAccount(address)
 	if account == nil {
-		return 0
+		return new(big.Int)
 	}
 	return account.GetBalance(asset)
 }
 
 // Transfer moves tokens between accounts
-func (s *StateDB) Transfer(from, to, asset string, amount uint64) error {
+func (s *StateDB) Transfer(from, to, asset string, amount *big.Int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
@@ -80,14 +93,11 @@ func (s *StateDB) Transfer(from, to, asset string, amount uint64) error {
 		s.accounts[to] = receiver
 	}
 	
-	// Check balance
-	if sender.Balances[asset] < amount {
+	// Check balance and transfer
+	if !sender.SubBalance(asset, amount) {
 		return ErrInsufficientBalance
 	}
-	
-	// Transfer
-	sender.Balances[asset] -= amount
-	receiver.Balances[asset] += amount
+	receiver.AddBalance(asset, amount)
 	
 	s.dirty[from] = true
 	s.dirty[to] = true
@@ -107,6 +117,7 @@ func (s *StateDB) SetAsset(id string, asset *Asset) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.assets[id] = asset
+	s.dirtyAssets[id] = true
 }
 
 // Commit finalizes state changes
@@ -122,10 +133,90 @@ func (s *StateDB) Commit() (string, error) {
 	
 	s.root = root
 	s.dirty = make(map[string]bool)
+	s.dirtyAssets = make(map[string]bool)
+	
+	return root, nil
+}
+
+// IntermediateRoot returns the root of the current uncommitted state
+// without committing it, for tracing state between transactions
+func (s *StateDB) IntermediateRoot() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calculateRoot()
+}
+
+// CommitAt finalizes state changes for a block height, recording the
+// changed accounts and assets in the archive if one is attached
+func (s *StateDB) CommitAt(height uint64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	root, err := s.calculateRoot()
+	if err != nil {
+		return "", err
+	}
+	
+	if s.archive != nil {
+		accounts := make(map[string]*Account, len(s.dirty))
+		for addr := range s.dirty {
+			if account, exists := s.accounts[addr]; exists {
+				accounts[addr] = account.Copy()
+			} else {
+				accounts[addr] = nil
+			}
+		}
+		assets := make(map[string]*Asset, len(s.dirtyAssets))
+		for id := range s.dirtyAssets {
+			if asset, exists := s.assets[id]; exists {
+				assets[id] = asset.Copy()
+			} else {
+				assets[id] = nil
+			}
+		}
+		s.archive.record(height, root, accounts, assets)
+	}
+	
+	s.root = root
+	s.height = height
+	s.dirty = make(map[string]bool)
+	s.dirtyAssets = make(map[string]bool)
 	
 	return root, nil
 }
 
+// SetArchive attaches a history archive; commits made before this are not retained
+func (s *StateDB) SetArchive(archive *Archive) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archive = archive
+}
+
+// Archive returns the attached history archive, if any
+func (s *StateDB) Archive() *Archive {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.archive
+}
+
+// AccountAt returns an account as of a past height
+func (s *StateDB) AccountAt(address string, height uint64) (*Account, error) {
+	archive := s.Archive()
+	if archive == nil {
+		return nil, ErrArchiveDisabled
+	}
+	return archive.AccountAt(address, height)
+}
+
+// AssetAt returns an asset as of a past height
+func (s *StateDB) AssetAt(id string, height uint64) (*Asset, error) {
+	archive := s.Archive()
+	if archive == nil {
+		return nil, ErrArchiveDisabled
+	}
+	return archive.AssetAt(id, height)
+}
+
 // Root returns the current state root
 func (s *StateDB) Root() string {
 	s.mu.RLock()
@@ -148,7 +239,16 @@ func (s *StateDB) Snapshot() *StateDB {
 		snapshot.assets[id] = asset.Copy()
 	}
 	
+	for addr := range s.dirty {
+		snapshot.dirty[addr] = true
+	}
+	for id := range s.dirtyAssets {
+		snapshot.dirtyAssets[id] = true
+	}
+	
 	snapshot.root = s.root
+	snapshot.height = s.height
+	snapshot.trie = s.trie.Copy()
 	
 	return snapshot
 }
@@ -161,19 +261,81 @@ func (s *StateDB) Revert(snapshot *StateDB) {
 	s.accounts = snapshot.accounts
 	s.assets = snapshot.assets
 	s.root = snapshot.root
-	s.dirty = make(map[string]bool)
+	s.height = snapshot.height
+	s.trie = snapshot.trie
+	s.dirty = snapshot.dirty
+	s.dirtyAssets = snapshot.dirtyAssets
 }
 
-// calculateRoot computes the state root hash
+// calculateRoot applies changed accounts and assets to the state trie and
+// returns its root. Leaves hold each entry's portable encoding, so nodes
+// holding the same state agree on the root regardless of local timestamps.
 func (s *StateDB) calculateRoot() (string, error) {
-	// Serialize accounts
-	data, err := json.Marshal(s.accounts)
+	for addr := range s.dirty {
+		if err := s.updateAccountLeaf(addr); err != nil {
+			return "", err
+		}
+	}
+	for id := range s.dirtyAssets {
+		if err := s.updateAssetLeaf(id); err != nil {
+			return "", err
+		}
+	}
+	return s.trie.RootHashHex(), nil
+}
+
+// rebuildTrie recomputes the state trie from every account and asset
+func (s *StateDB) rebuildTrie() error {
+	s.trie = NewPatriciaTrie()
+	for addr := range s.accounts {
+		if err := s.updateAccountLeaf(addr); err != nil {
+			return err
+		}
+	}
+	for id := range s.assets {
+		if err := s.updateAssetLeaf(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateAccountLeaf writes an account's leaf, or removes it if deleted
+func (s *StateDB) updateAccountLeaf(address string) error {
+	key := []byte(accountTriePrefix + address)
+	account, exists := s.accounts[address]
+	if !exists {
+		s.trie.Delete(key)
+		return nil
+	}
+	
+	account.mu.RLock()
+	data, err := json.Marshal(newAccountRecord(account))
+	account.mu.RUnlock()
 	if err != nil {
-		return "", err
+		return err
+	}
+	s.trie.Insert(key, data)
+	return nil
+}
+
+// updateAssetLeaf writes an asset's leaf, or removes it if deleted
+func (s *StateDB) updateAssetLeaf(id string) error {
+	key := []byte(assetTriePrefix + id)
+	asset, exists := s.assets[id]
+	if !exists {
+		s.trie.Delete(key)
+		return nil
 	}
 	
-	// Calculate merkle root (simplified)
-	return CalculateMerkleRoot(data), nil
+	portable := *asset
+	portable.CreatedAt, portable.UpdatedAt = 0, 0
+	data, err := json.Marshal(portable)
+	if err != nil {
+		return err
+	}
+	s.trie.Insert(key, data)
+	return nil
 }
 
 // AccountCount returns the number of accounts
@@ -203,18 +365,16 @@ func (s *StateDB) AllAccounts() []string {
 }
 
 // TotalSupply calculates total supply of an asset
-func (s *StateDB) TotalSupply(asset string) uint64 {
+func (s *StateDB) TotalSupply(asset string) *big.Int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
-	var total uint64
+	total := new(big.Int)
 	for _, account := range s.accounts {
-		total += account.Balances[asset]
+		total.Add(total, account.GetBalance(asset))
 		if asset == "GYDS" {
-			total += account.Staked
-			for _, delegated := range account.Delegated {
-				total += delegated
-			}
+			total.Add(total, account.GetStaked())
+			total.Add(total, account.TotalDelegated())
 		}
 	}
 	return total
@@ -225,17 +385,20 @@ func (s *StateDB) Export() ([]byte, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
-	export := struct {
-		Accounts map[string]*Account `json:"accounts"`
-		Assets   map[string]*Asset   `json:"assets"`
-		Root     string              `json:"root"`
-	}{
+	return json.Marshal(stateExport{
 		Accounts: s.accounts,
 		Assets:   s.assets,
 		Root:     s.root,
-	}
-	
-	return json.Marshal(export)
+	})
+}
+
+// stateExport is the on-disk form of the full state; snapshots also
+// record the height they were taken at
+type stateExport struct {
+	Height   uint64              `json:"height,omitempty"`
+	Accounts map[string]*Account `json:"accounts"`
+	Assets   map[string]*Asset   `json:"assets"`
+	Root     string              `json:"root"`
 }
 
 // Errors
@@ -0,0 +1,144 @@
+package state
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// VestingAsset is the asset vesting schedules lock
+const VestingAsset = "GYDS"
+
+// VestingSchedule locks part of an account's GYDS balance until it vests.
+// Initial is spendable from the start; the rest vests linearly from Start
+// to End, with nothing released before Cliff.
+type VestingSchedule struct {
+	Total   *big.Int `json:"total"`
+	Initial *big.Int `json:"initial"`
+	Start   int64    `json:"start"`
+	Cliff   int64    `json:"cliff"`
+	End     int64    `json:"end"`
+}
+
+// MarshalJSON encodes the amounts as decimal strings
+func (v VestingSchedule) MarshalJSON() ([]byte, error) {
+	type plain VestingSchedule
+	return json.Marshal(struct {
+		*plain
+		Total   *util.Big `json:"total"`
+		Initial *util.Big `json:"initial"`
+	}{(*plain)(&v), (*util.Big)(v.Total), (*util.Big)(v.Initial)})
+}
+
+// UnmarshalJSON decodes the amounts from strings or numbers
+func (v *VestingSchedule) UnmarshalJSON(data []byte) error {
+	type plain VestingSchedule
+	dec := struct {
+		*plain
+		Total   *util.Big `json:"total"`
+		Initial *util.Big `json:"initial"`
+	}{plain: (*plain)(v)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	v.Total, v.Initial = dec.Total.Int(), dec.Initial.Int()
+	return nil
+}
+
+// Validate checks the schedule's amounts and times are consistent
+func (v *VestingSchedule) Validate() error {
+	total, initial := util.CopyBig(v.Total), util.CopyBig(v.Initial)
+	if total.Sign() <= 0 || initial.Sign() < 0 || initial.Cmp(total) > 0 {
+		return ErrInvalidVesting
+	}
+	if v.End <= v.Start || v.Cliff < v.Start || v.Cliff > v.End {
+		return ErrInvalidVesting
+	}
+	return nil
+}
+
+// Locked returns the amount still unvested at time now
+func (v *VestingSchedule) Locked(now int64) *big.Int {
+	vesting := new(big.Int).Sub(util.CopyBig(v.Total), util.CopyBig(v.Initial))
+	switch {
+	case vesting.Sign() <= 0, now >= v.End:
+		return new(big.Int)
+	case now < v.Cliff, now <= v.Start:
+		return vesting
+	}
+	remaining := big.NewInt(v.End - now)
+	locked := vesting.Mul(vesting, remaining)
+	return locked.Quo(locked, big.NewInt(v.End-v.Start))
+}
+
+// Copy returns a deep copy of the schedule
+func (v *VestingSchedule) Copy() *VestingSchedule {
+	c := *v
+	c.Total, c.Initial = util.CopyBig(v.Total), util.CopyBig(v.Initial)
+	return &c
+}
+
+// VestingStatus reports an account's vesting progress at a point in time
+type VestingStatus struct {
+	Schedule  *VestingSchedule `json:"schedule"`
+	Locked    *util.Big        `json:"locked"`
+	Vested    *util.Big        `json:"vested"`
+	Spendable *util.Big        `json:"spendable"`
+	Time      int64            `json:"time"`
+}
+
+// SetVesting attaches a vesting schedule; nil removes it
+func (a *Account) SetVesting(schedule *VestingSchedule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if schedule != nil {
+		schedule = schedule.Copy()
+	}
+	a.Vesting = schedule
+}
+
+// Spendable returns the balance of asset the account may move at time
+// now; unvested GYDS stays locked
+func (a *Account) Spendable(asset string, now int64) *big.Int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.spendable(asset, now)
+}
+
+func (a *Account) spendable(asset string, now int64) *big.Int {
+	balance := util.CopyBig(a.Balances[asset])
+	if a.Vesting == nil || asset != VestingAsset {
+		return balance
+	}
+	balance.Sub(balance, a.Vesting.Locked(now))
+	if balance.Sign() < 0 {
+		balance.SetUint64(0)
+	}
+	return balance
+}
+
+// VestingStatus returns the account's vesting progress at time now, or nil
+// without a schedule
+func (a *Account) VestingStatus(now int64) *VestingStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.Vesting == nil {
+		return nil
+	}
+	locked := a.Vesting.Locked(now)
+	return &VestingStatus{
+		Schedule:  a.Vesting.Copy(),
+		Locked:    (*util.Big)(locked),
+		Vested:    (*util.Big)(new(big.Int).Sub(util.CopyBig(a.Vesting.Total), locked)),
+		Spendable: (*util.Big)(a.spendable(VestingAsset, now)),
+		Time:      now,
+	}
+}
+
+// Vesting errors
+var (
+	ErrInvalidVesting = &StateError{"invalid vesting schedule"}
+	ErrVestingLocked  = &StateError{"amount exceeds spendable balance: tokens still vesting"}
+)
@@ -0,0 +1,182 @@
+package tx
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// stakingAuthorizationDomain separates authorization signatures from any
+// other message the cold key might sign
+const stakingAuthorizationDomain = "gyds-staking-authorization:"
+
+// StakingAuthorization is signed offline by a cold address to let a hot key
+// delegate and undelegate the cold address's stake. It grants no transfers:
+// staked funds always return to the cold address.
+type StakingAuthorization struct {
+	Cold       string   `json:"cold"`
+	ColdPubKey string   `json:"cold_pub_key"` // hex ed25519 key the cold address derives from
+	Hot        string   `json:"hot"`
+	Validators []string `json:"validators,omitempty"` // empty allows any validator
+	MaxAmount  *big.Int `json:"max_amount,omitempty"` // per-transaction cap, nil or 0 for none
+	Expiry     uint64   `json:"expiry"`               // last valid block height, 0 for none
+	Signature  string   `json:"signature,omitempty"`  // hex ed25519 over SigningBytes
+}
+
+// MarshalJSON encodes the amount cap as a decimal string
+func (a StakingAuthorization) MarshalJSON() ([]byte, error) {
+	type plain StakingAuthorization
+	var maxAmount *util.Big
+	if a.MaxAmount != nil && a.MaxAmount.Sign() > 0 {
+		maxAmount = (*util.Big)(a.MaxAmount)
+	}
+	return json.Marshal(struct {
+		*plain
+		MaxAmount *util.Big `json:"max_amount,omitempty"`
+	}{(*plain)(&a), maxAmount})
+}
+
+// UnmarshalJSON decodes the amount cap from a string or number
+func (a *StakingAuthorization) UnmarshalJSON(data []byte) error {
+	type plain StakingAuthorization
+	dec := struct {
+		*plain
+		MaxAmount *util.Big `json:"max_amount,omitempty"`
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	a.MaxAmount = nil
+	if dec.MaxAmount != nil {
+		a.MaxAmount = dec.MaxAmount.Int()
+	}
+	return nil
+}
+
+// SigningBytes returns the message the cold key signs
+func (a *StakingAuthorization) SigningBytes() ([]byte, error) {
+	unsigned := *a
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(stakingAuthorizationDomain), data...), nil
+}
+
+// Sign sets the authorization's cold key and signature
+func (a *StakingAuthorization) Sign(coldKey ed25519.PrivateKey) error {
+	a.ColdPubKey = hex.EncodeToString(coldKey.Public().(ed25519.PublicKey))
+	msg, err := a.SigningBytes()
+	if err != nil {
+		return err
+	}
+	a.Signature = hex.EncodeToString(ed25519.Sign(coldKey, msg))
+	return nil
+}
+
+// Validate checks the authorization is well formed and signed by the key
+// behind the cold address
+func (a *StakingAuthorization) Validate() error {
+	if a.Cold == "" || a.Hot == "" {
+		return ErrInvalidAuthorization
+	}
+	if a.Cold == a.Hot {
+		return ErrSelfAuthorization
+	}
+
+	pubKey, err := hex.DecodeString(a.ColdPubKey)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return ErrInvalidAuthorization
+	}
+	if crypto.DeriveAddress(pubKey) != a.Cold {
+		return ErrAuthorizationKeyMismatch
+	}
+
+	sig, err := hex.DecodeString(a.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return ErrInvalidAuthorizationSig
+	}
+	msg, err := a.SigningBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pubKey, msg, sig) {
+		return ErrInvalidAuthorizationSig
+	}
+	return nil
+}
+
+// Permits checks that the authorization covers a hot key staking amount
+// with validator at height
+func (a *StakingAuthorization) Permits(hot, validator string, amount *big.Int, height uint64) error {
+	if hot != a.Hot {
+		return ErrUnauthorizedHotKey
+	}
+	if a.Expiry > 0 && height > a.Expiry {
+		return ErrAuthorizationExpired
+	}
+	if a.MaxAmount != nil && a.MaxAmount.Sign() > 0 && amount.Cmp(a.MaxAmount) > 0 {
+		return ErrAuthorizationAmount
+	}
+	if len(a.Validators) == 0 {
+		return nil
+	}
+	for _, v := range a.Validators {
+		if v == validator {
+			return nil
+		}
+	}
+	return ErrAuthorizationValidator
+}
+
+// ColdStakePayload carries the cold address's authorization on a hot-key
+// delegate or undelegate; the tx To is the validator and Amount the stake
+type ColdStakePayload struct {
+	Authorization StakingAuthorization `json:"authorization"`
+}
+
+// Validate checks the embedded authorization
+func (p *ColdStakePayload) Validate() error {
+	return p.Authorization.Validate()
+}
+
+// NewColdStake creates a hot-key delegation of the cold address's funds
+func NewColdStake(auth *StakingAuthorization, validatorAddr string, amount *big.Int) (*Transaction, error) {
+	t := NewTransaction(TxTypeColdStake, auth.Hot, validatorAddr, amount, "GYDS")
+	if err := t.SetPayload(&ColdStakePayload{Authorization: *auth}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewColdUnstake creates a hot-key undelegation back to the cold address
+func NewColdUnstake(auth *StakingAuthorization, validatorAddr string, amount *big.Int) (*Transaction, error) {
+	t := NewTransaction(TxTypeColdUnstake, auth.Hot, validatorAddr, amount, "GYDS")
+	if err := t.SetPayload(&ColdStakePayload{Authorization: *auth}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func init() {
+	RegisterPayload(TxTypeColdStake, true, func() Payload { return &ColdStakePayload{} })
+	RegisterPayload(TxTypeColdUnstake, true, func() Payload { return &ColdStakePayload{} })
+}
+
+// Authorization errors
+var (
+	ErrInvalidAuthorization     = errors.New("malformed staking authorization")
+	ErrSelfAuthorization        = errors.New("cold and hot addresses must differ")
+	ErrAuthorizationKeyMismatch = errors.New("authorization key does not match cold address")
+	ErrInvalidAuthorizationSig  = errors.New("invalid staking authorization signature")
+	ErrUnauthorizedHotKey       = errors.New("sender is not the authorized hot key")
+	ErrAuthorizationExpired     = errors.New("staking authorization expired")
+	ErrAuthorizationAmount      = errors.New("amount exceeds staking authorization limit")
+	ErrAuthorizationValidator   = errors.New("validator not covered by staking authorization")
+)
@@ -0,0 +1,29 @@
+package tx
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrDustTransfer is returned for transfers below the asset's network minimum
+var ErrDustTransfer = errors.New("transfer amount below the network minimum for the asset")
+
+// DustPolicy reports the smallest amount of an asset a transfer may move,
+// or nil if the asset has no minimum. Minimums keep 1-unit spam transfers
+// from bloating state with dust accounts.
+type DustPolicy interface {
+	MinTransfer(asset string) *big.Int
+}
+
+// CheckDust returns ErrDustTransfer if t is a transfer below policy's
+// minimum for its asset; a nil policy allows everything
+func CheckDust(policy DustPolicy, t *Transaction) error {
+	if policy == nil || !t.IsTransfer() {
+		return nil
+	}
+	min := policy.MinTransfer(t.Asset)
+	if min != nil && t.Amount.Cmp(min) < 0 {
+		return ErrDustTransfer
+	}
+	return nil
+}
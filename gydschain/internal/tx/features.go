@@ -0,0 +1,89 @@
+package tx
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Experimental features ship disabled and are enabled per network. Each
+// feature owns the transaction types and RPC namespace named after it, so
+// "dex_place_order" and "dex_getOrderBook" both belong to FeatureDEX.
+const (
+	FeatureGovernance = "governance"
+	FeatureDEX        = "dex"
+	FeatureWASM       = "wasm"
+)
+
+// ExperimentalFeatures lists every known experimental feature
+var ExperimentalFeatures = []string{FeatureGovernance, FeatureDEX, FeatureWASM}
+
+// ErrFeatureDisabled is returned for transactions of a feature that is not
+// enabled on this network
+var ErrFeatureDisabled = errors.New("transaction type disabled: experimental feature not enabled")
+
+// Features is the set of experimental features enabled on a node. The zero
+// value enables none.
+type Features map[string]bool
+
+// NewFeatures enables the named features
+func NewFeatures(names ...string) Features {
+	f := make(Features, len(names))
+	for _, name := range names {
+		f[name] = true
+	}
+	return f
+}
+
+// Enabled returns true if the named feature is enabled
+func (f Features) Enabled(name string) bool {
+	return f[name]
+}
+
+// Names returns the enabled features in sorted order
+func (f Features) Names() []string {
+	names := make([]string, 0, len(f))
+	for name, enabled := range f {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllowsNamespace returns true unless namespace belongs to a disabled
+// experimental feature
+func (f Features) AllowsNamespace(namespace string) bool {
+	feature := featureOf(namespace)
+	return feature == "" || f[feature]
+}
+
+// Check returns ErrFeatureDisabled if the transaction's type belongs to a
+// disabled experimental feature
+func (f Features) Check(t *Transaction) error {
+	if feature := FeatureOf(t.Type); feature != "" && !f[feature] {
+		return ErrFeatureDisabled
+	}
+	return nil
+}
+
+// FeatureOf returns the experimental feature a transaction type belongs
+// to, or "" for stable types
+func FeatureOf(txType string) string {
+	i := strings.Index(txType, "_")
+	if i < 0 {
+		return ""
+	}
+	return featureOf(txType[:i])
+}
+
+// featureOf returns namespace if it names an experimental feature
+func featureOf(namespace string) string {
+	for _, feature := range ExperimentalFeatures {
+		if namespace == feature {
+			return feature
+		}
+	}
+	return ""
+}
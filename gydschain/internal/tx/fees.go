@@ -1,7 +1,10 @@
 package tx
 
 import (
+	"math/big"
 	"sync"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // FeeConfig contains fee configuration
@@ -54,12 +57,13 @@ func NewFeeEstimator(config *FeeConfig) *FeeEstimator {
 }
 
 // EstimateFee estimates the fee for a transaction
-func (e *FeeEstimator) EstimateFee(tx *Transaction) uint64 {
+func (e *FeeEstimator) EstimateFee(tx *Transaction) *big.Int {
+	gas := e.EstimateGas(tx)
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	gas := e.EstimateGas(tx)
-	return gas * e.avgGasPrice
+	return FeeForGas(gas, e.avgGasPrice)
 }
 
 // EstimateGas estimates gas needed for a transaction
@@ -67,27 +71,32 @@ func (e *FeeEstimator) EstimateGas(tx *Transaction) uint64 {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	return e.config.Gas(tx)
+}
+
+// Gas returns the gas a transaction consumes under this configuration
+func (c *FeeConfig) Gas(tx *Transaction) uint64 {
 	var gas uint64
 
 	// Base gas by transaction type
 	switch tx.Type {
 	case TxTypeTransfer:
-		gas = e.config.TransferGas
-	case TxTypeStake:
-		gas = e.config.StakeGas
-	case TxTypeUnstake:
-		gas = e.config.UnstakeGas
+		gas = c.TransferGas
+	case TxTypeStake, TxTypeColdStake:
+		gas = c.StakeGas
+	case TxTypeUnstake, TxTypeColdUnstake:
+		gas = c.UnstakeGas
 	case TxTypeCreateAsset:
-		gas = e.config.CreateAssetGas
+		gas = c.CreateAssetGas
 	default:
-		gas = e.config.TransferGas
+		gas = c.TransferGas
 	}
 
 	// Add gas for data
-	gas += uint64(len(tx.Data)) * e.config.GasPerByte
+	gas += uint64(len(tx.Data)) * c.GasPerByte
 
 	// Add gas for signature
-	gas += e.config.GasPerSignature
+	gas += c.GasPerSignature
 
 	return gas
 }
@@ -117,7 +126,7 @@ func (e *FeeEstimator) SuggestGasPrice(priority string) uint64 {
 }
 
 // RecordFee records a fee from a confirmed transaction
-func (e *FeeEstimator) RecordFee(fee, gasUsed uint64) {
+func (e *FeeEstimator) RecordFee(fee *big.Int, gasUsed uint64) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -125,7 +134,7 @@ func (e *FeeEstimator) RecordFee(fee, gasUsed uint64) {
 		return
 	}
 
-	gasPrice := fee / gasUsed
+	gasPrice := GasPrice(fee, gasUsed)
 
 	e.recentFees = append(e.recentFees, gasPrice)
 
@@ -187,19 +196,19 @@ func (e *FeeEstimator) GetConfig() *FeeConfig {
 
 // FeeEstimate contains fee estimate details
 type FeeEstimate struct {
-	GasUsed     uint64 `json:"gas_used"`
-	GasPrice    uint64 `json:"gas_price"`
-	TotalFee    uint64 `json:"total_fee"`
-	GYDSFee     uint64 `json:"gyds_fee"` // Fee in GYDS
-	Priority    string `json:"priority"`
-	EstimatedTime string `json:"estimated_time"`
+	GasUsed       uint64    `json:"gas_used"`
+	GasPrice      uint64    `json:"gas_price"`
+	TotalFee      *util.Big `json:"total_fee"`
+	GYDSFee       *util.Big `json:"gyds_fee"` // Fee in GYDS
+	Priority      string    `json:"priority"`
+	EstimatedTime string    `json:"estimated_time"`
 }
 
 // GetFeeEstimate returns a detailed fee estimate
 func (e *FeeEstimator) GetFeeEstimate(tx *Transaction, priority string) *FeeEstimate {
 	gas := e.EstimateGas(tx)
 	gasPrice := e.SuggestGasPrice(priority)
-	totalFee := gas * gasPrice
+	totalFee := (*util.Big)(FeeForGas(gas, gasPrice))
 
 	var estimatedTime string
 	switch priority {
@@ -225,13 +234,33 @@ func (e *FeeEstimator) GetFeeEstimate(tx *Transaction, priority string) *FeeEsti
 	}
 }
 
+// FeeForGas returns the fee for gas units at gasPrice
+func FeeForGas(gas, gasPrice uint64) *big.Int {
+	fee := new(big.Int).SetUint64(gas)
+	return fee.Mul(fee, new(big.Int).SetUint64(gasPrice))
+}
+
+// GasPrice returns the price per unit of gas a fee pays, saturating at the
+// largest uint64
+func GasPrice(fee *big.Int, gas uint64) uint64 {
+	if fee == nil || gas == 0 {
+		return 0
+	}
+	price := new(big.Int).Quo(fee, new(big.Int).SetUint64(gas))
+	if !price.IsUint64() {
+		return ^uint64(0)
+	}
+	return price.Uint64()
+}
+
 // CalculateBurnAmount calculates the amount to burn from fees
-func CalculateBurnAmount(totalFees, burnRate uint64) uint64 {
-	return (totalFees * burnRate) / 10000 // burnRate in basis points
+func CalculateBurnAmount(totalFees *big.Int, burnRate uint64) *big.Int {
+	burn := new(big.Int).Mul(totalFees, new(big.Int).SetUint64(burnRate))
+	return burn.Quo(burn, big.NewInt(10000)) // burnRate in basis points
 }
 
 // CalculateValidatorShare calculates validator's share of fees
-func CalculateValidatorShare(totalFees, burnRate uint64) uint64 {
+func CalculateValidatorShare(totalFees *big.Int, burnRate uint64) *big.Int {
 	burnAmount := CalculateBurnAmount(totalFees, burnRate)
-	return totalFees - burnAmount
+	return new(big.Int).Sub(totalFees, burnAmount)
 }
@@ -0,0 +1,112 @@
+package tx
+
+import (
+	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// DefaultInclusionHistory is how many included transactions keep their
+// per-tx inclusion record for lookups
+const DefaultInclusionHistory = 10000
+
+// InclusionRecord tracks a transaction from mempool entry to block inclusion
+type InclusionRecord struct {
+	Hash       string `json:"hash"`
+	FirstSeen  int64  `json:"first_seen"`            // unix ms when it entered the mempool
+	IncludedAt int64  `json:"included_at,omitempty"` // unix ms when its block was applied
+	Height     uint64 `json:"height,omitempty"`
+	LatencyMs  int64  `json:"latency_ms,omitempty"`
+	GasPrice   uint64 `json:"gas_price"`
+	Pending    bool   `json:"pending"`
+}
+
+// InclusionStats aggregates time-to-inclusion over recent transactions
+type InclusionStats struct {
+	Latency  *util.LatencyPercentiles `json:"latency"`
+	Included uint64                   `json:"included"`
+	Expired  uint64                   `json:"expired"`  // dropped after MaxTxAge without inclusion
+	Replaced uint64                   `json:"replaced"` // superseded by a fee bump
+}
+
+// inclusionLog holds recent inclusion records in a bounded FIFO
+type inclusionLog struct {
+	latency  *util.LatencyTracker
+	records  map[string]*InclusionRecord
+	order    []string
+	limit    int
+	included uint64
+	expired  uint64
+	replaced uint64
+}
+
+func newInclusionLog(limit int) *inclusionLog {
+	if limit <= 0 {
+		limit = DefaultInclusionHistory
+	}
+	return &inclusionLog{
+		latency: util.NewLatencyTracker(util.DefaultLatencySamples),
+		records: make(map[string]*InclusionRecord),
+		limit:   limit,
+	}
+}
+
+// include records a pending tx landing in a block
+func (l *inclusionLog) include(mtx *MempoolTx, height uint64, now time.Time) {
+	latency := now.Sub(mtx.AddedAt)
+	l.latency.Record(latency)
+	l.included++
+
+	l.records[mtx.Hash] = &InclusionRecord{
+		Hash:       mtx.Hash,
+		FirstSeen:  mtx.AddedAt.UnixMilli(),
+		IncludedAt: now.UnixMilli(),
+		Height:     height,
+		LatencyMs:  latency.Milliseconds(),
+		GasPrice:   mtx.GasPrice,
+	}
+	l.order = append(l.order, mtx.Hash)
+	if len(l.order) > l.limit {
+		delete(l.records, l.order[0])
+		l.order = l.order[1:]
+	}
+}
+
+// pendingRecord describes a tx still waiting in the mempool
+func pendingRecord(mtx *MempoolTx) *InclusionRecord {
+	return &InclusionRecord{
+		Hash:      mtx.Hash,
+		FirstSeen: mtx.AddedAt.UnixMilli(),
+		GasPrice:  mtx.GasPrice,
+		Pending:   true,
+	}
+}
+
+// InclusionInfo returns the inclusion record for a pending or recently
+// included transaction
+func (mp *Mempool) InclusionInfo(hash string) (*InclusionRecord, bool) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	if mtx, exists := mp.txs[hash]; exists {
+		return pendingRecord(mtx), true
+	}
+	if record, exists := mp.inclusion.records[hash]; exists {
+		copy := *record
+		return &copy, true
+	}
+	return nil, false
+}
+
+// InclusionStats returns time-to-inclusion percentiles and counters
+func (mp *Mempool) InclusionStats() *InclusionStats {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	return &InclusionStats{
+		Latency:  mp.inclusion.latency.Percentiles(),
+		Included: mp.inclusion.included,
+		Expired:  mp.inclusion.expired,
+		Replaced: mp.inclusion.replaced,
+	}
+}
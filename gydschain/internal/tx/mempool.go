@@ -3,17 +3,23 @@ package tx
 import (
 	"container/heap"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 )
 
+// DefaultMaxMemoSize is the default limit on a transaction memo, in bytes
+const DefaultMaxMemoSize = 256
+
 // MempoolConfig contains mempool configuration
 type MempoolConfig struct {
 	MaxSize       int           `json:"max_size"`
 	MaxTxSize     int           `json:"max_tx_size"`
+	MaxMemoSize   int           `json:"max_memo_size"` // bytes; 0 disables memos
 	MaxTxAge      time.Duration `json:"max_tx_age"`
 	MinGasPrice   uint64        `json:"min_gas_price"`
 	ReapInterval  time.Duration `json:"reap_interval"`
+	ReplaceBump   uint64        `json:"replace_bump"` // min % gas price increase to replace a tx (RBF)
 }
 
 // DefaultMempoolConfig returns default configuration
@@ -21,20 +27,27 @@ func DefaultMempoolConfig() *MempoolConfig {
 	return &MempoolConfig{
 		MaxSize:      10000,
 		MaxTxSize:    1024 * 1024, // 1MB
+		MaxMemoSize:  DefaultMaxMemoSize,
 		MaxTxAge:     time.Hour,
 		MinGasPrice:  1,
 		ReapInterval: time.Minute,
+		ReplaceBump:  10,
 	}
 }
 
 // Mempool manages pending transactions
 type Mempool struct {
-	mu       sync.RWMutex
-	config   *MempoolConfig
-	txs      map[string]*MempoolTx
-	queue    *TxQueue
-	nonces   map[string]uint64 // address -> highest nonce
-	stopChan chan struct{}
+	mu        sync.RWMutex
+	config    *MempoolConfig
+	txs       map[string]*MempoolTx
+	queue     *TxQueue
+	accounts  map[string]map[uint64]*MempoolTx // sender -> nonce -> pending tx
+	nonces    map[string]uint64                // sender -> nonce after its last confirmed tx
+	stopChan  chan struct{}
+	onAdd     []func(tx *Transaction, hash string)
+	inclusion *inclusionLog
+	features  Features
+	dust      DustPolicy
 }
 
 // MempoolTx wraps a transaction with metadata
@@ -53,11 +66,13 @@ func NewMempool(config *MempoolConfig) *Mempool {
 	}
 	
 	mp := &Mempool{
-		config:   config,
-		txs:      make(map[string]*MempoolTx),
-		queue:    &TxQueue{},
-		nonces:   make(map[string]uint64),
-		stopChan: make(chan struct{}),
+		config:    config,
+		txs:       make(map[string]*MempoolTx),
+		queue:     &TxQueue{},
+		accounts:  make(map[string]map[uint64]*MempoolTx),
+		nonces:    make(map[string]uint64),
+		stopChan:  make(chan struct{}),
+		inclusion: newInclusionLog(DefaultInclusionHistory),
 	}
 	
 	heap.Init(mp.queue)
@@ -78,13 +93,27 @@ func (mp *Mempool) AddTx(tx *Transaction) error {
 		return err
 	}
 	
+	// Refuse types of experimental features this network has not enabled
+	if err := mp.features.Check(tx); err != nil {
+		return err
+	}
+	
+	// Refuse transfers below the network's minimum for the asset
+	if err := CheckDust(mp.dust, tx); err != nil {
+		return err
+	}
+	
 	// Check size
 	if tx.Size() > mp.config.MaxTxSize {
 		return ErrTxTooLarge
 	}
 	
+	if len(tx.Memo) > mp.config.MaxMemoSize {
+		return ErrMemoTooLarge
+	}
+	
 	// Check gas price
-	gasPrice := tx.Fee / uint64(tx.Size())
+	gasPrice := GasPrice(tx.Fee, uint64(tx.Size()))
 	if gasPrice < mp.config.MinGasPrice {
 		return ErrGasPriceTooLow
 	}
@@ -100,20 +129,27 @@ func (mp *Mempool) AddTx(tx *Transaction) error {
 		return ErrDuplicateTx
 	}
 	
-	// Check mempool size
-	if len(mp.txs) >= mp.config.MaxSize {
+	// Check nonce against the sender's last confirmed tx
+	if confirmed, known := mp.nonces[tx.From]; known && tx.Nonce < confirmed {
+		return ErrNonceTooLow
+	}
+	
+	// Replace-by-fee: a pending tx with the same sender and nonce may be
+	// replaced by one paying at least ReplaceBump percent more gas price
+	if existing := mp.accounts[tx.From][tx.Nonce]; existing != nil {
+		if gasPrice*100 < existing.GasPrice*(100+mp.config.ReplaceBump) {
+			return ErrReplacementUnderpriced
+		}
+		mp.remove(existing)
+		mp.inclusion.replaced++
+		mp.rebuildQueue()
+	} else if len(mp.txs) >= mp.config.MaxSize {
 		// Try to evict lowest priority tx
 		if !mp.evictLowest(gasPrice) {
 			return ErrMempoolFull
 		}
 	}
 	
-	// Check nonce
-	currentNonce := mp.nonces[tx.From]
-	if tx.Nonce < currentNonce {
-		return ErrNonceTooLow
-	}
-	
 	// Add to mempool
 	mtx := &MempoolTx{
 		Tx:       tx,
@@ -126,20 +162,126 @@ func (mp *Mempool) AddTx(tx *Transaction) error {
 	mp.txs[hash] = mtx
 	heap.Push(mp.queue, mtx)
 	
-	// Update nonce tracking
-	if tx.Nonce >= mp.nonces[tx.From] {
-		mp.nonces[tx.From] = tx.Nonce + 1
+	pending := mp.accounts[tx.From]
+	if pending == nil {
+		pending = make(map[uint64]*MempoolTx)
+		mp.accounts[tx.From] = pending
+	}
+	pending[tx.Nonce] = mtx
+	
+	for _, fn := range mp.onAdd {
+		fn(tx, hash)
 	}
 	
 	return nil
 }
 
+// SetFeatures sets the experimental features whose transactions are admitted
+func (mp *Mempool) SetFeatures(features Features) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.features = features
+}
+
+// SetDustPolicy sets the per-asset transfer minimums enforced at admission
+func (mp *Mempool) SetDustPolicy(policy DustPolicy) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.dust = policy
+}
+
+// OnAdd registers a listener for newly accepted transactions; listeners run
+// with the mempool locked and must not block
+func (mp *Mempool) OnAdd(fn func(tx *Transaction, hash string)) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.onAdd = append(mp.onAdd, fn)
+}
+
 // RemoveTx removes a transaction from the mempool
 func (mp *Mempool) RemoveTx(hash string) {
 	mp.mu.Lock()
 	defer mp.mu.Unlock()
 	
-	delete(mp.txs, hash)
+	if mtx, exists := mp.txs[hash]; exists {
+		mp.remove(mtx)
+		mp.rebuildQueue()
+	}
+}
+
+// remove drops mtx from the pool and its sender's queue without touching the
+// priority queue; callers must hold mp.mu and rebuild the queue
+func (mp *Mempool) remove(mtx *MempoolTx) {
+	delete(mp.txs, mtx.Hash)
+	
+	pending := mp.accounts[mtx.Tx.From]
+	if pending[mtx.Tx.Nonce] == mtx {
+		delete(pending, mtx.Tx.Nonce)
+	}
+	if len(pending) == 0 {
+		delete(mp.accounts, mtx.Tx.From)
+	}
+}
+
+// nextNonce returns the nonce sender's next executable tx must carry: the one
+// after its last confirmed tx, or its lowest pending nonce if none has been
+// seen in a block yet; callers must hold mp.mu
+func (mp *Mempool) nextNonce(sender string) uint64 {
+	if confirmed, known := mp.nonces[sender]; known {
+		return confirmed
+	}
+	
+	var lowest uint64
+	first := true
+	for nonce := range mp.accounts[sender] {
+		if first || nonce < lowest {
+			lowest, first = nonce, false
+		}
+	}
+	return lowest
+}
+
+// reap walks executable transactions, highest gas price first. Each sender's
+// transactions are released in nonce order from its next nonce; a sender
+// stops at its first nonce gap or at the first tx take declines. Expired
+// transactions are dropped. Callers must hold mp.mu.
+func (mp *Mempool) reap(take func(mtx *MempoolTx) bool) {
+	heads := &TxQueue{}
+	for sender, pending := range mp.accounts {
+		if mtx, exists := pending[mp.nextNonce(sender)]; exists {
+			*heads = append(*heads, mtx)
+		}
+	}
+	heap.Init(heads)
+	
+	expired := false
+	for heads.Len() > 0 {
+		mtx := heap.Pop(heads).(*MempoolTx)
+		
+		// Check if still valid
+		if time.Since(mtx.AddedAt) > mp.config.MaxTxAge {
+			mp.remove(mtx)
+			mp.inclusion.expired++
+			expired = true
+			continue
+		}
+		
+		// Minimums may have been raised since the tx was admitted
+		if CheckDust(mp.dust, mtx.Tx) != nil {
+			continue
+		}
+		
+		if !take(mtx) {
+			continue
+		}
+		if next, exists := mp.accounts[mtx.Tx.From][mtx.Tx.Nonce+1]; exists {
+			heap.Push(heads, next)
+		}
+	}
+	
+	if expired {
+		mp.rebuildQueue()
+	}
 }
 
 // GetTx returns a transaction by hash
@@ -160,7 +302,9 @@ func (mp *Mempool) HasTx(hash string) bool {
 	return mp.txs[hash] != nil
 }
 
-// ReapMaxTxs returns up to maxTxs transactions for block inclusion
+// ReapMaxTxs returns up to maxTxs executable transactions for block
+// inclusion, in an order that keeps each sender's nonces contiguous.
+// They stay pending until Update confirms them.
 func (mp *Mempool) ReapMaxTxs(maxTxs int) []*Transaction {
 	mp.mu.Lock()
 	defer mp.mu.Unlock()
@@ -170,41 +314,70 @@ func (mp *Mempool) ReapMaxTxs(maxTxs int) []*Transaction {
 	}
 	
 	txs := make([]*Transaction, 0, maxTxs)
-	
-	for len(txs) < maxTxs && mp.queue.Len() > 0 {
-		mtx := heap.Pop(mp.queue).(*MempoolTx)
-		
-		// Check if still valid
-		if time.Since(mtx.AddedAt) > mp.config.MaxTxAge {
-			delete(mp.txs, mtx.Hash)
-			continue
+	mp.reap(func(mtx *MempoolTx) bool {
+		if len(txs) >= maxTxs {
+			return false
 		}
-		
 		txs = append(txs, mtx.Tx)
-	}
+		return true
+	})
 	
-	// Re-add to queue (they'll be removed after block is confirmed)
-	for _, tx := range txs {
-		hash, _ := tx.HashHex()
-		if mtx, exists := mp.txs[hash]; exists {
-			heap.Push(mp.queue, mtx)
+	return txs
+}
+
+// ReapGas returns the highest priority executable transactions that fit in
+// gasLimit, skipping those paying less than baseFee per unit of gas. A
+// skipped tx holds back its sender's later nonces.
+func (mp *Mempool) ReapGas(gasLimit, baseFee uint64, gasOf func(*Transaction) uint64) []*Transaction {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	txs := make([]*Transaction, 0)
+	var used uint64
+
+	mp.reap(func(mtx *MempoolTx) bool {
+		gas := gasOf(mtx.Tx)
+		if gas == 0 || GasPrice(mtx.Tx.Fee, gas) < baseFee || used+gas > gasLimit {
+			return false
 		}
-	}
-	
+		txs = append(txs, mtx.Tx)
+		used += gas
+		return true
+	})
+
 	return txs
 }
 
-// Update removes confirmed transactions
-func (mp *Mempool) Update(confirmedTxs []*Transaction) {
+// Update removes transactions confirmed at height, recording how long
+// each waited in the mempool
+func (mp *Mempool) Update(height uint64, confirmedTxs []*Transaction) {
 	mp.mu.Lock()
 	defer mp.mu.Unlock()
 	
+	now := time.Now()
 	for _, tx := range confirmedTxs {
+		if confirmed, known := mp.nonces[tx.From]; !known || tx.Nonce >= confirmed {
+			mp.nonces[tx.From] = tx.Nonce + 1
+		}
+		
 		hash, err := tx.HashHex()
 		if err != nil {
 			continue
 		}
-		delete(mp.txs, hash)
+		if mtx, exists := mp.txs[hash]; exists {
+			mp.inclusion.include(mtx, height, now)
+			mp.remove(mtx)
+		}
+	}
+	
+	// Drop pending txs whose nonce a confirmed tx already used
+	for _, tx := range confirmedTxs {
+		for nonce, mtx := range mp.accounts[tx.From] {
+			if nonce < mp.nonces[tx.From] {
+				mp.remove(mtx)
+				mp.inclusion.replaced++
+			}
+		}
 	}
 	
 	// Rebuild queue
@@ -233,7 +406,7 @@ func (mp *Mempool) evictLowest(minGasPrice uint64) bool {
 		return false
 	}
 	
-	delete(mp.txs, lowest.Hash)
+	mp.remove(lowest)
 	mp.rebuildQueue()
 	return true
 }
@@ -259,15 +432,28 @@ func (mp *Mempool) cleanup() {
 	defer mp.mu.Unlock()
 	
 	now := time.Now()
-	for hash, mtx := range mp.txs {
+	for _, mtx := range mp.txs {
 		if now.Sub(mtx.AddedAt) > mp.config.MaxTxAge {
-			delete(mp.txs, hash)
+			mp.remove(mtx)
+			mp.inclusion.expired++
 		}
 	}
 	
 	mp.rebuildQueue()
 }
 
+// Flush drops every pending transaction and returns how many were dropped
+func (mp *Mempool) Flush() int {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	n := len(mp.txs)
+	mp.txs = make(map[string]*MempoolTx)
+	mp.accounts = make(map[string]map[uint64]*MempoolTx)
+	mp.rebuildQueue()
+	return n
+}
+
 // Size returns the number of transactions
 func (mp *Mempool) Size() int {
 	mp.mu.RLock()
@@ -275,6 +461,25 @@ func (mp *Mempool) Size() int {
 	return len(mp.txs)
 }
 
+// Capacity returns the maximum number of transactions the mempool holds
+func (mp *Mempool) Capacity() int {
+	return mp.config.MaxSize
+}
+
+// OldestAge returns how long the oldest pending transaction has waited
+func (mp *Mempool) OldestAge() time.Duration {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	
+	var oldest time.Duration
+	for _, mtx := range mp.txs {
+		if age := time.Since(mtx.AddedAt); age > oldest {
+			oldest = age
+		}
+	}
+	return oldest
+}
+
 // TotalBytes returns approximate total size
 func (mp *Mempool) TotalBytes() int {
 	mp.mu.RLock()
@@ -287,16 +492,45 @@ func (mp *Mempool) TotalBytes() int {
 	return total
 }
 
-// GetPending returns all pending transactions for an address
+// GetPending returns all pending transactions for an address in nonce order
 func (mp *Mempool) GetPending(address string) []*Transaction {
 	mp.mu.RLock()
 	defer mp.mu.RUnlock()
 	
-	var txs []*Transaction
+	pending := mp.accounts[address]
+	txs := make([]*Transaction, 0, len(pending))
+	for _, mtx := range pending {
+		txs = append(txs, mtx.Tx)
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+	return txs
+}
+
+// PendingNonce returns the nonce address's next transaction should carry
+// given stateNonce, its nonce in the latest state: the first nonce from
+// there not already taken by a pending transaction
+func (mp *Mempool) PendingNonce(address string, stateNonce uint64) uint64 {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	
+	nonce := stateNonce
+	if confirmed := mp.nonces[address]; confirmed > nonce {
+		nonce = confirmed
+	}
+	for mp.accounts[address][nonce] != nil {
+		nonce++
+	}
+	return nonce
+}
+
+// GetAll returns every pending transaction
+func (mp *Mempool) GetAll() []*Transaction {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	
+	txs := make([]*Transaction, 0, len(mp.txs))
 	for _, mtx := range mp.txs {
-		if mtx.Tx.From == address {
-			txs = append(txs, mtx.Tx)
-		}
+		txs = append(txs, mtx.Tx)
 	}
 	return txs
 }
@@ -331,9 +565,11 @@ func (q *TxQueue) Pop() interface{} {
 
 // Mempool errors
 var (
-	ErrTxTooLarge     = errors.New("transaction too large")
-	ErrGasPriceTooLow = errors.New("gas price too low")
-	ErrDuplicateTx    = errors.New("duplicate transaction")
-	ErrMempoolFull    = errors.New("mempool full")
-	ErrNonceTooLow    = errors.New("nonce too low")
+	ErrTxTooLarge             = errors.New("transaction too large")
+	ErrMemoTooLarge           = errors.New("transaction memo too large")
+	ErrGasPriceTooLow         = errors.New("gas price too low")
+	ErrDuplicateTx            = errors.New("duplicate transaction")
+	ErrMempoolFull            = errors.New("mempool full")
+	ErrNonceTooLow            = errors.New("nonce too low")
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
 )
@@ -0,0 +1,155 @@
+package tx
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+// MaxMultisigKeys caps the keys a multisig account may register
+const MaxMultisigKeys = 16
+
+// multisigAddressDomain separates multisig addresses from key-derived ones
+const multisigAddressDomain = "gyds-multisig:"
+
+// CreateMultisigPayload registers the tx recipient as a multisig account
+// controlled by PubKeys; transactions from it need Threshold signatures
+type CreateMultisigPayload struct {
+	PubKeys   []string `json:"pub_keys"` // hex ed25519 keys
+	Threshold int      `json:"threshold"`
+}
+
+// Validate checks the keys are distinct ed25519 keys and the threshold is
+// reachable
+func (p *CreateMultisigPayload) Validate() error {
+	if len(p.PubKeys) == 0 || len(p.PubKeys) > MaxMultisigKeys {
+		return ErrInvalidMultisigKeys
+	}
+	seen := make(map[string]bool, len(p.PubKeys))
+	for _, key := range p.PubKeys {
+		key = strings.ToLower(key)
+		raw, err := hex.DecodeString(key)
+		if err != nil || len(raw) != ed25519.PublicKeySize || seen[key] {
+			return ErrInvalidMultisigKeys
+		}
+		seen[key] = true
+	}
+	if p.Threshold < 1 || p.Threshold > len(p.PubKeys) {
+		return ErrInvalidMultisigThreshold
+	}
+	return nil
+}
+
+// MultisigAddress derives the address of the multisig account for keys and
+// threshold; key order does not matter
+func MultisigAddress(pubKeys []string, threshold int) string {
+	keys := make([]string, len(pubKeys))
+	for i, key := range pubKeys {
+		keys[i] = strings.ToLower(key)
+	}
+	sort.Strings(keys)
+	preimage := multisigAddressDomain + strconv.Itoa(threshold) + ":" + strings.Join(keys, ",")
+	return crypto.AddressFromHash(crypto.Hash160([]byte(preimage)))
+}
+
+// NewCreateMultisig creates a transaction registering the multisig account
+// for pubKeys and threshold, funded with amount GYDS from the sender
+func NewCreateMultisig(from string, pubKeys []string, threshold int, amount *big.Int) (*Transaction, error) {
+	t := NewTransaction(TxTypeCreateMultisig, from, MultisigAddress(pubKeys, threshold), amount, "GYDS")
+	if err := t.SetPayload(&CreateMultisigPayload{PubKeys: pubKeys, Threshold: threshold}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MultisigSignature is one signer's signature over a transaction hash
+type MultisigSignature struct {
+	PubKey    string `json:"pub_key"`   // hex ed25519 key
+	Signature string `json:"signature"` // hex ed25519 signature
+}
+
+// SignPartial signs the transaction hash with one multisig key. The
+// transaction itself is left unchanged so signers can work offline from the
+// same unsigned copy and combine their results with AddSignatures.
+func (t *Transaction) SignPartial(kp *crypto.KeyPair) (*MultisigSignature, error) {
+	hash, err := t.Hash()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := kp.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &MultisigSignature{PubKey: kp.PublicKeyHex(), Signature: hex.EncodeToString(sig)}, nil
+}
+
+// AddSignatures attaches partial signatures, replacing any earlier one from
+// the same key
+func (t *Transaction) AddSignatures(sigs ...*MultisigSignature) {
+	for _, sig := range sigs {
+		replaced := false
+		for i := range t.Signatures {
+			if strings.EqualFold(t.Signatures[i].PubKey, sig.PubKey) {
+				t.Signatures[i], replaced = *sig, true
+				break
+			}
+		}
+		if !replaced {
+			t.Signatures = append(t.Signatures, *sig)
+		}
+	}
+}
+
+// VerifyMultisig checks that at least threshold of the attached signatures
+// are valid signatures of the transaction hash by distinct keys in pubKeys
+func (t *Transaction) VerifyMultisig(pubKeys []string, threshold int) error {
+	hash, err := t.Hash()
+	if err != nil {
+		return err
+	}
+
+	allowed := make(map[string]bool, len(pubKeys))
+	for _, key := range pubKeys {
+		allowed[strings.ToLower(key)] = true
+	}
+
+	signed := make(map[string]bool)
+	for _, s := range t.Signatures {
+		key := strings.ToLower(s.PubKey)
+		if !allowed[key] || signed[key] {
+			continue
+		}
+		pubKey, err := hex.DecodeString(key)
+		if err != nil {
+			continue
+		}
+		sig, err := hex.DecodeString(s.Signature)
+		if err != nil || !crypto.VerifySignature(pubKey, hash, sig) {
+			return ErrInvalidMultisigSignature
+		}
+		signed[key] = true
+	}
+
+	if len(signed) < threshold {
+		return ErrMultisigThreshold
+	}
+	return nil
+}
+
+func init() {
+	RegisterPayload(TxTypeCreateMultisig, true, func() Payload { return &CreateMultisigPayload{} })
+}
+
+// Multisig errors
+var (
+	ErrInvalidMultisigKeys      = errors.New("multisig needs 1-16 distinct hex ed25519 public keys")
+	ErrInvalidMultisigThreshold = errors.New("multisig threshold must be between 1 and the number of keys")
+	ErrInvalidMultisigSignature = errors.New("invalid multisig signature")
+	ErrMultisigThreshold        = errors.New("not enough multisig signatures")
+)
@@ -0,0 +1,86 @@
+package tx
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Maximum lengths of NFT identifiers
+const (
+	MaxNFTCollectionLength = 12
+	MaxNFTTokenIDLength    = 64
+)
+
+// NFTMintPayload mints token TokenID of Collection to the tx recipient. The
+// first mint of a collection creates it, owned by the sender; later mints
+// must come from that owner.
+type NFTMintPayload struct {
+	Collection  string            `json:"collection"`
+	TokenID     string            `json:"token_id"`
+	Name        string            `json:"name,omitempty"`
+	TokenURI    string            `json:"token_uri,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+}
+
+// Validate checks the token identifiers
+func (p *NFTMintPayload) Validate() error {
+	return validateNFTID(p.Collection, p.TokenID)
+}
+
+// NFTTransferPayload names the token moved from the sender to the tx
+// recipient
+type NFTTransferPayload struct {
+	Collection string `json:"collection"`
+	TokenID    string `json:"token_id"`
+}
+
+// Validate checks the token identifiers
+func (p *NFTTransferPayload) Validate() error {
+	return validateNFTID(p.Collection, p.TokenID)
+}
+
+// validateNFTID checks a collection and token ID; neither may contain the
+// separator that joins them into the token's asset ID
+func validateNFTID(collection, tokenID string) error {
+	if collection == "" || len(collection) > MaxNFTCollectionLength || strings.Contains(collection, "/") {
+		return ErrInvalidNFTCollection
+	}
+	if tokenID == "" || len(tokenID) > MaxNFTTokenIDLength || strings.Contains(tokenID, "/") {
+		return ErrInvalidNFTTokenID
+	}
+	return nil
+}
+
+// NewNFTMint creates a transaction minting a token to recipient; the fee is
+// paid in GYDS
+func NewNFTMint(from, recipient string, token *NFTMintPayload) (*Transaction, error) {
+	t := NewTransaction(TxTypeNFTMint, from, recipient, new(big.Int), "GYDS")
+	if err := t.SetPayload(token); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewNFTTransfer creates a transaction moving the sender's token to
+// recipient
+func NewNFTTransfer(from, recipient, collection, tokenID string) (*Transaction, error) {
+	t := NewTransaction(TxTypeNFTTransfer, from, recipient, new(big.Int), "GYDS")
+	if err := t.SetPayload(&NFTTransferPayload{Collection: collection, TokenID: tokenID}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func init() {
+	RegisterPayload(TxTypeNFTMint, true, func() Payload { return &NFTMintPayload{} })
+	RegisterPayload(TxTypeNFTTransfer, true, func() Payload { return &NFTTransferPayload{} })
+}
+
+// NFT payload errors
+var (
+	ErrInvalidNFTCollection = errors.New("NFT collection must be 1-12 characters without '/'")
+	ErrInvalidNFTTokenID    = errors.New("NFT token ID must be 1-64 characters without '/'")
+)
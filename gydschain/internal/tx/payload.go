@@ -0,0 +1,328 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// Payload is the typed content carried in a transaction's Data field
+type Payload interface {
+	Validate() error
+}
+
+// payloadSpec describes the payload schema registered for a tx type
+type payloadSpec struct {
+	required bool
+	factory  func() Payload
+}
+
+var (
+	payloadMu       sync.RWMutex
+	payloadRegistry = make(map[string]payloadSpec)
+)
+
+// RegisterPayload declares the payload type for a transaction type. Required
+// payloads must be present; optional ones may be omitted (empty Data).
+func RegisterPayload(txType string, required bool, factory func() Payload) {
+	payloadMu.Lock()
+	defer payloadMu.Unlock()
+	payloadRegistry[txType] = payloadSpec{required: required, factory: factory}
+}
+
+// HasPayload returns true if a payload schema is registered for the tx type
+func HasPayload(txType string) bool {
+	payloadMu.RLock()
+	defer payloadMu.RUnlock()
+	_, exists := payloadRegistry[txType]
+	return exists
+}
+
+// EncodePayload returns the canonical encoding of a payload: compact JSON
+// with fields in declaration order
+func EncodePayload(p Payload) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(p)
+}
+
+// DecodePayload parses and validates the payload of a transaction. It returns
+// nil for types without a registered schema and for omitted optional
+// payloads. Data must be in canonical encoding so equal payloads always hash
+// the same.
+func DecodePayload(t *Transaction) (Payload, error) {
+	payloadMu.RLock()
+	spec, exists := payloadRegistry[t.Type]
+	payloadMu.RUnlock()
+
+	if !exists {
+		return nil, nil
+	}
+	if len(t.Data) == 0 {
+		if spec.required {
+			return nil, ErrMissingPayload
+		}
+		return nil, nil
+	}
+
+	p := spec.factory()
+	dec := json.NewDecoder(bytes.NewReader(t.Data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	canonical, err := json.Marshal(p)
+	if err != nil || !bytes.Equal(canonical, t.Data) {
+		return nil, ErrNonCanonicalPayload
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPayload validates a payload and stores its canonical encoding in Data
+func (t *Transaction) SetPayload(p Payload) error {
+	data, err := EncodePayload(p)
+	if err != nil {
+		return err
+	}
+	t.Data = data
+	return nil
+}
+
+// OraclePriceDecimals is the fixed-point precision of oracle prices
+const OraclePriceDecimals = 8
+
+// StakePayload optionally registers the staker as a validator
+type StakePayload struct {
+	PubKey     string `json:"pub_key,omitempty"`
+	Commission uint64 `json:"commission,omitempty"` // basis points
+	Moniker    string `json:"moniker,omitempty"`
+}
+
+// Validate checks the stake payload
+func (p *StakePayload) Validate() error {
+	if p.Commission > 10000 {
+		return ErrInvalidCommission
+	}
+	if p.Commission > 0 && p.PubKey == "" {
+		return ErrMissingPubKey
+	}
+	return nil
+}
+
+// MaxAssetSymbolLength bounds asset symbols, which are also asset IDs
+const MaxAssetSymbolLength = 12
+
+// CreateAssetPayload defines a new asset; the tx Amount is the initial supply
+type CreateAssetPayload struct {
+	Symbol     string    `json:"symbol"`
+	Name       string    `json:"name"`
+	Decimals   uint8     `json:"decimals"`
+	MaxSupply  *util.Big `json:"max_supply,omitempty"`
+	Mintable   bool      `json:"mintable"`
+	Burnable   bool      `json:"burnable"`
+	Pausable   bool      `json:"pausable"`
+	Stablecoin bool      `json:"stablecoin,omitempty"`
+	Peg        string    `json:"peg,omitempty"`
+}
+
+// Validate checks the asset definition
+func (p *CreateAssetPayload) Validate() error {
+	if len(p.Symbol) == 0 || len(p.Symbol) > MaxAssetSymbolLength {
+		return ErrInvalidSymbol
+	}
+	if p.Name == "" {
+		return ErrMissingName
+	}
+	if p.Decimals > 18 {
+		return ErrInvalidDecimals
+	}
+	if p.Stablecoin && p.Peg == "" {
+		return ErrMissingPeg
+	}
+	return nil
+}
+
+// OracleUpdatePayload is a validator's price vote for an asset in one
+// oracle voting window
+type OracleUpdatePayload struct {
+	AssetID string `json:"asset_id"`
+	Price   uint64 `json:"price"` // fixed point, OraclePriceDecimals
+	Window  uint64 `json:"window"`
+}
+
+// Validate checks the oracle vote
+func (p *OracleUpdatePayload) Validate() error {
+	if p.AssetID == "" {
+		return ErrMissingAsset
+	}
+	if p.Price == 0 {
+		return ErrInvalidPrice
+	}
+	return nil
+}
+
+// HaltVotePayload carries the reason for an emergency halt vote
+type HaltVotePayload struct {
+	Reason string `json:"reason"`
+}
+
+// Validate checks the halt vote
+func (p *HaltVotePayload) Validate() error {
+	if p.Reason == "" {
+		return ErrMissingReason
+	}
+	return nil
+}
+
+// BeaconCommitPayload publishes a validator's randomness commitment,
+// hex(sha256(secret || validator address))
+type BeaconCommitPayload struct {
+	Epoch      uint64 `json:"epoch"`
+	Commitment string `json:"commitment"`
+}
+
+// Validate checks the commitment is a hex sha256 digest
+func (p *BeaconCommitPayload) Validate() error {
+	if b, err := hex.DecodeString(p.Commitment); err != nil || len(b) != 32 {
+		return ErrInvalidCommitment
+	}
+	return nil
+}
+
+// BeaconRevealPayload reveals the secret behind an earlier commitment
+type BeaconRevealPayload struct {
+	Epoch  uint64 `json:"epoch"`
+	Secret string `json:"secret"` // hex, 32 bytes
+}
+
+// Validate checks the secret is 32 hex-encoded bytes
+func (p *BeaconRevealPayload) Validate() error {
+	if b, err := hex.DecodeString(p.Secret); err != nil || len(b) != 32 {
+		return ErrInvalidSecret
+	}
+	return nil
+}
+
+// DustVotePayload proposes a new minimum transfer amount for an asset; zero
+// removes the minimum
+type DustVotePayload struct {
+	Asset     string    `json:"asset"`
+	MinAmount *util.Big `json:"min_amount"`
+}
+
+// Validate checks the asset and amount
+func (p *DustVotePayload) Validate() error {
+	if p.Asset == "" {
+		return ErrMissingAsset
+	}
+	if p.MinAmount == nil || p.MinAmount.Int().Sign() < 0 {
+		return ErrInvalidMinAmount
+	}
+	return nil
+}
+
+// SlashAppealPayload backs reversing an escrowed slash
+type SlashAppealPayload struct {
+	EscrowID string `json:"escrow_id"`
+}
+
+// Validate checks the escrow is named
+func (p *SlashAppealPayload) Validate() error {
+	if p.EscrowID == "" {
+		return ErrMissingEscrowID
+	}
+	return nil
+}
+
+// AppealWindowPayload proposes how many blocks slashes stay reversible
+type AppealWindowPayload struct {
+	Blocks uint64 `json:"blocks"`
+}
+
+// Validate checks the window is positive
+func (p *AppealWindowPayload) Validate() error {
+	if p.Blocks == 0 {
+		return ErrInvalidAppealWindow
+	}
+	return nil
+}
+
+// MaxPayoutBeneficiaries bounds how many addresses a payout split may name
+const MaxPayoutBeneficiaries = 10
+
+// PayoutBeneficiary receives Share basis points of a validator's rewards
+type PayoutBeneficiary struct {
+	Address string `json:"address"`
+	Share   uint64 `json:"share"` // basis points
+}
+
+// PayoutSplitPayload sets a validator's reward payout split
+type PayoutSplitPayload struct {
+	Beneficiaries []PayoutBeneficiary `json:"beneficiaries"`
+}
+
+// Validate checks the shares are positive, to distinct addresses and sum to
+// 100%; an empty list is valid and clears the split
+func (p *PayoutSplitPayload) Validate() error {
+	if len(p.Beneficiaries) > MaxPayoutBeneficiaries {
+		return ErrTooManyBeneficiaries
+	}
+	seen := make(map[string]bool, len(p.Beneficiaries))
+	var total uint64
+	for _, b := range p.Beneficiaries {
+		if b.Address == "" || b.Share == 0 || seen[b.Address] {
+			return ErrInvalidPayoutSplit
+		}
+		seen[b.Address] = true
+		total += b.Share
+	}
+	if len(p.Beneficiaries) > 0 && total != 10000 {
+		return ErrInvalidPayoutSplit
+	}
+	return nil
+}
+
+func init() {
+	RegisterPayload(TxTypeStake, false, func() Payload { return &StakePayload{} })
+	RegisterPayload(TxTypeCreateAsset, true, func() Payload { return &CreateAssetPayload{} })
+	RegisterPayload(TxTypeUpdateOracle, true, func() Payload { return &OracleUpdatePayload{} })
+	RegisterPayload(TxTypeHaltVote, true, func() Payload { return &HaltVotePayload{} })
+	RegisterPayload(TxTypeBeaconCommit, true, func() Payload { return &BeaconCommitPayload{} })
+	RegisterPayload(TxTypeBeaconReveal, true, func() Payload { return &BeaconRevealPayload{} })
+	RegisterPayload(TxTypeDustVote, true, func() Payload { return &DustVotePayload{} })
+	RegisterPayload(TxTypeSetPayoutSplit, true, func() Payload { return &PayoutSplitPayload{} })
+	RegisterPayload(TxTypeSlashAppeal, true, func() Payload { return &SlashAppealPayload{} })
+	RegisterPayload(TxTypeAppealWindow, true, func() Payload { return &AppealWindowPayload{} })
+}
+
+// Payload errors
+var (
+	ErrMissingPayload       = errors.New("transaction type requires a payload")
+	ErrInvalidPayload       = errors.New("malformed transaction payload")
+	ErrNonCanonicalPayload  = errors.New("transaction payload not canonically encoded")
+	ErrInvalidCommission    = errors.New("commission exceeds 100%")
+	ErrMissingPubKey        = errors.New("validator registration requires a public key")
+	ErrInvalidSymbol        = errors.New("asset symbol must be 1-12 characters")
+	ErrMissingName          = errors.New("asset name required")
+	ErrInvalidDecimals      = errors.New("asset decimals exceed 18")
+	ErrMissingPeg           = errors.New("stablecoin requires a peg")
+	ErrInvalidPrice         = errors.New("oracle price must be positive")
+	ErrMissingReason        = errors.New("halt vote requires a reason")
+	ErrInvalidCommitment    = errors.New("beacon commitment must be a hex sha256 digest")
+	ErrInvalidSecret        = errors.New("beacon secret must be 32 hex-encoded bytes")
+	ErrInvalidMinAmount     = errors.New("minimum transfer amount must not be negative")
+	ErrInvalidPayoutSplit   = errors.New("payout split shares must be positive, to distinct addresses and sum to 100%")
+	ErrTooManyBeneficiaries = errors.New("payout split names too many beneficiaries")
+	ErrMissingEscrowID      = errors.New("slash appeal requires an escrow id")
+	ErrInvalidAppealWindow  = errors.New("appeal window must be at least one block")
+)
@@ -5,68 +5,154 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"time"
+
+	"github.com/gydschain/gydschain/internal/util"
 )
 
 // Transaction types
 const (
-	TxTypeTransfer     = "transfer"
-	TxTypeStake        = "stake"
-	TxTypeUnstake      = "unstake"
-	TxTypeMint         = "mint"
-	TxTypeBurn         = "burn"
-	TxTypeCreateAsset  = "create_asset"
-	TxTypeUpdateOracle = "update_oracle"
+	TxTypeTransfer        = "transfer"
+	TxTypeStake           = "stake"
+	TxTypeUnstake         = "unstake"
+	TxTypeMint            = "mint"
+	TxTypeBurn            = "burn"
+	TxTypeCreateAsset     = "create_asset"
+	TxTypeUpdateOracle    = "update_oracle"
+	TxTypeSetPolicy       = "set_transfer_policy"
+	TxTypeHaltVote        = "halt_vote"
+	TxTypeResumeVote      = "resume_vote"
+	TxTypeBeaconCommit    = "beacon_commit"
+	TxTypeBeaconReveal    = "beacon_reveal"
+	TxTypeColdStake       = "cold_stake"
+	TxTypeColdUnstake     = "cold_unstake"
+	TxTypeDustVote        = "dust_vote"
+	TxTypeWithdrawRewards = "withdraw_rewards"
+	TxTypeSetPayoutSplit  = "set_payout_split"
+	TxTypeVaultLock       = "vault_lock"
+	TxTypeVaultUnlock     = "vault_unlock"
+	TxTypeVaultLiquidate  = "vault_liquidate"
+	TxTypeNFTMint         = "nft_mint"
+	TxTypeNFTTransfer     = "nft_transfer"
+	TxTypeCreateMultisig  = "create_multisig"
+	TxTypeSlashAppeal     = "slash_appeal"
+	TxTypeAppealWindow    = "appeal_window_vote"
 )
 
 // Transaction represents a blockchain transaction
 type Transaction struct {
-	Type      string `json:"type"`
-	From      string `json:"from"`
-	To        string `json:"to"`
-	Amount    uint64 `json:"amount"`
-	Asset     string `json:"asset"`
-	Fee       uint64 `json:"fee"`
-	Nonce     uint64 `json:"nonce"`
-	Timestamp int64  `json:"timestamp"`
-	Data      []byte `json:"data,omitempty"`
-	Signature []byte `json:"signature"`
-	PubKey    []byte `json:"pub_key"`
+	Type       string              `json:"type"`
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Amount     *big.Int            `json:"amount"`
+	Asset      string              `json:"asset"`
+	Fee        *big.Int            `json:"fee"`
+	Nonce      uint64              `json:"nonce"`
+	Timestamp  int64               `json:"timestamp"`
+	Data       []byte              `json:"data,omitempty"`
+	Memo       string              `json:"memo,omitempty"` // free-form note, e.g. an exchange deposit tag
+	Signature  []byte              `json:"signature"`
+	Signatures []MultisigSignature `json:"signatures,omitempty"` // partial signatures for a multisig sender
+	PubKey     []byte              `json:"pub_key"`
+}
+
+// MarshalJSON encodes the amount and fee as decimal strings
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	type plain Transaction
+	return json.Marshal(struct {
+		*plain
+		Amount *util.Big `json:"amount"`
+		Fee    *util.Big `json:"fee"`
+	}{(*plain)(&t), (*util.Big)(t.Amount), (*util.Big)(t.Fee)})
+}
+
+// UnmarshalJSON decodes the amount and fee from strings or numbers
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	dec := struct {
+		*plain
+		Amount *util.Big `json:"amount"`
+		Fee    *util.Big `json:"fee"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	t.Amount, t.Fee = dec.Amount.Int(), dec.Fee.Int()
+	return nil
 }
 
 // NewTransaction creates a new transaction
-func NewTransaction(txType, from, to string, amount uint64, asset string) *Transaction {
+func NewTransaction(txType, from, to string, amount *big.Int, asset string) *Transaction {
 	return &Transaction{
 		Type:      txType,
 		From:      from,
 		To:        to,
-		Amount:    amount,
+		Amount:    util.CopyBig(amount),
 		Asset:     asset,
+		Fee:       new(big.Int),
 		Timestamp: time.Now().Unix(),
 	}
 }
 
 // NewTransfer creates a new transfer transaction
-func NewTransfer(from, to string, amount uint64, asset string) *Transaction {
+func NewTransfer(from, to string, amount *big.Int, asset string) *Transaction {
 	return NewTransaction(TxTypeTransfer, from, to, amount, asset)
 }
 
 // NewStake creates a new staking transaction
-func NewStake(from string, amount uint64, validatorAddr string) *Transaction {
+func NewStake(from string, amount *big.Int, validatorAddr string) *Transaction {
 	tx := NewTransaction(TxTypeStake, from, validatorAddr, amount, "GYDS")
 	return tx
 }
 
+// NewRegisterValidator creates a stake of the sender's own GYDS that
+// registers it as a validator with its consensus public key
+func NewRegisterValidator(from, pubKey string, stake *big.Int) (*Transaction, error) {
+	t := NewStake(from, stake, from)
+	if err := t.SetPayload(&StakePayload{PubKey: pubKey}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
 // NewUnstake creates a new unstaking transaction
-func NewUnstake(from string, amount uint64, validatorAddr string) *Transaction {
+func NewUnstake(from string, amount *big.Int, validatorAddr string) *Transaction {
 	return NewTransaction(TxTypeUnstake, from, validatorAddr, amount, "GYDS")
 }
 
+// NewWithdrawRewards creates a transaction withdrawing the sender's staking
+// rewards accrued with a validator
+func NewWithdrawRewards(from, validatorAddr string) *Transaction {
+	return NewTransaction(TxTypeWithdrawRewards, from, validatorAddr, new(big.Int), "GYDS")
+}
+
+// NewSetPayoutSplit creates a transaction setting how a validator's own
+// rewards are split across beneficiaries; no beneficiaries clears the split
+func NewSetPayoutSplit(validatorAddr string, beneficiaries []PayoutBeneficiary) (*Transaction, error) {
+	t := NewTransaction(TxTypeSetPayoutSplit, validatorAddr, validatorAddr, new(big.Int), "GYDS")
+	if err := t.SetPayload(&PayoutSplitPayload{Beneficiaries: beneficiaries}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewCreateAsset creates a transaction defining a new asset with supply
+// credited to its creator; the fee is paid in GYDS
+func NewCreateAsset(from string, asset *CreateAssetPayload, supply *big.Int) (*Transaction, error) {
+	t := NewTransaction(TxTypeCreateAsset, from, from, supply, "GYDS")
+	if err := t.SetPayload(asset); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
 // Hash computes the transaction hash
 func (t *Transaction) Hash() ([]byte, error) {
-	// Create a copy without signature for hashing
+	// Create a copy without signatures for hashing
 	hashTx := *t
 	hashTx.Signature = nil
+	hashTx.Signatures = nil
 	
 	data, err := json.Marshal(hashTx)
 	if err != nil {
@@ -87,8 +173,8 @@ func (t *Transaction) HashHex() (string, error) {
 }
 
 // SetFee sets the transaction fee
-func (t *Transaction) SetFee(fee uint64) {
-	t.Fee = fee
+func (t *Transaction) SetFee(fee *big.Int) {
+	t.Fee = util.CopyBig(fee)
 }
 
 // SetNonce sets the transaction nonce
@@ -96,6 +182,11 @@ func (t *Transaction) SetNonce(nonce uint64) {
 	t.Nonce = nonce
 }
 
+// SetMemo sets the transaction memo; it is covered by the hash and signature
+func (t *Transaction) SetMemo(memo string) {
+	t.Memo = memo
+}
+
 // SetData sets additional transaction data
 func (t *Transaction) SetData(data []byte) {
 	t.Data = data
@@ -127,7 +218,11 @@ func (t *Transaction) Verify() error {
 		return ErrMissingTo
 	}
 	
-	if t.Amount == 0 && t.Type == TxTypeTransfer {
+	if t.Amount == nil || t.Amount.Sign() < 0 || (t.Fee != nil && t.Fee.Sign() < 0) {
+		return ErrNegativeAmount
+	}
+	
+	if t.Amount.Sign() == 0 && t.Type == TxTypeTransfer {
 		return ErrZeroAmount
 	}
 	
@@ -135,11 +230,18 @@ func (t *Transaction) Verify() error {
 		return ErrMissingAsset
 	}
 	
-	if t.Asset != "GYDS" && t.Asset != "GYD" {
+	// Assets are created on chain, so whether one exists is checked against
+	// state when the transaction executes; here only its ID is checked
+	if len(t.Asset) > MaxAssetSymbolLength {
 		return ErrInvalidAsset
 	}
 	
-	if len(t.Signature) == 0 {
+	// Check the typed payload against its registered schema
+	if _, err := DecodePayload(t); err != nil {
+		return err
+	}
+	
+	if len(t.Signature) == 0 && len(t.Signatures) == 0 {
 		return ErrMissingSignature
 	}
 	
@@ -162,7 +264,16 @@ func (t *Transaction) IsTransfer() bool {
 
 // IsStaking returns true if this is a staking-related transaction
 func (t *Transaction) IsStaking() bool {
-	return t.Type == TxTypeStake || t.Type == TxTypeUnstake
+	switch t.Type {
+	case TxTypeStake, TxTypeUnstake, TxTypeColdStake, TxTypeColdUnstake, TxTypeWithdrawRewards, TxTypeSetPayoutSplit:
+		return true
+	}
+	return false
+}
+
+// IsSystem returns true for governance transactions still accepted while the chain is halted
+func (t *Transaction) IsSystem() bool {
+	return t.Type == TxTypeHaltVote || t.Type == TxTypeResumeVote
 }
 
 // Errors
@@ -170,18 +281,27 @@ var (
 	ErrMissingFrom      = errors.New("missing sender address")
 	ErrMissingTo        = errors.New("missing recipient address")
 	ErrZeroAmount       = errors.New("amount cannot be zero")
+	ErrNegativeAmount   = errors.New("amount and fee must be non-negative")
 	ErrMissingAsset     = errors.New("missing asset type")
 	ErrInvalidAsset     = errors.New("invalid asset type")
 	ErrMissingSignature = errors.New("missing signature")
 	ErrInvalidSignature = errors.New("invalid signature")
 )
 
+// Receipt statuses
+const (
+	ReceiptFailed  uint8 = 0
+	ReceiptSuccess uint8 = 1
+)
+
 // TransactionReceipt represents a transaction receipt
 type TransactionReceipt struct {
 	TxHash      string `json:"tx_hash"`
 	BlockHash   string `json:"block_hash"`
 	BlockHeight uint64 `json:"block_height"`
 	Index       uint32 `json:"index"`
+	From        string `json:"from"`
+	To          string `json:"to,omitempty"`
 	Status      uint8  `json:"status"` // 0 = failed, 1 = success
 	GasUsed     uint64 `json:"gas_used"`
 	Logs        []Log  `json:"logs"`
@@ -0,0 +1,84 @@
+package tx
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// VaultLockPayload optionally mints stablecoin against a vault after its
+// collateral top-up
+type VaultLockPayload struct {
+	Mint *util.Big `json:"mint,omitempty"`
+}
+
+// Validate checks the mint amount
+func (p *VaultLockPayload) Validate() error {
+	if p.Mint != nil && p.Mint.Int().Sign() < 0 {
+		return ErrNegativeVaultAmount
+	}
+	return nil
+}
+
+// VaultUnlockPayload optionally repays vault debt before collateral is
+// withdrawn
+type VaultUnlockPayload struct {
+	Repay *util.Big `json:"repay,omitempty"`
+}
+
+// Validate checks the repay amount
+func (p *VaultUnlockPayload) Validate() error {
+	if p.Repay != nil && p.Repay.Int().Sign() < 0 {
+		return ErrNegativeVaultAmount
+	}
+	return nil
+}
+
+// NewVaultLock creates a transaction locking collateral GYDS in the
+// sender's vault and minting GYD against it
+func NewVaultLock(from string, collateral, mint *big.Int) (*Transaction, error) {
+	t := NewTransaction(TxTypeVaultLock, from, from, collateral, "GYDS")
+	if mint != nil && mint.Sign() > 0 {
+		if err := t.SetPayload(&VaultLockPayload{Mint: (*util.Big)(mint)}); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// NewVaultUnlock creates a transaction repaying GYD debt and withdrawing
+// collateral GYDS from the sender's vault
+func NewVaultUnlock(from string, withdraw, repay *big.Int) (*Transaction, error) {
+	t := NewTransaction(TxTypeVaultUnlock, from, from, withdraw, "GYDS")
+	if repay != nil && repay.Sign() > 0 {
+		if err := t.SetPayload(&VaultUnlockPayload{Repay: (*util.Big)(repay)}); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// NewVaultLiquidate creates a transaction repaying an undercollateralized
+// vault's debt in exchange for its collateral
+func NewVaultLiquidate(from, owner string) *Transaction {
+	return NewTransaction(TxTypeVaultLiquidate, from, owner, new(big.Int), "GYDS")
+}
+
+// NewOracleUpdate creates a validator's price vote for an asset in window,
+// priced in the stablecoin's peg currency at OraclePriceDecimals
+func NewOracleUpdate(from, assetID string, price, window uint64) (*Transaction, error) {
+	t := NewTransaction(TxTypeUpdateOracle, from, assetID, new(big.Int), "GYDS")
+	if err := t.SetPayload(&OracleUpdatePayload{AssetID: assetID, Price: price, Window: window}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func init() {
+	RegisterPayload(TxTypeVaultLock, false, func() Payload { return &VaultLockPayload{} })
+	RegisterPayload(TxTypeVaultUnlock, false, func() Payload { return &VaultUnlockPayload{} })
+}
+
+// ErrNegativeVaultAmount is returned for a negative mint or repay amount
+var ErrNegativeVaultAmount = errors.New("vault amounts must not be negative")
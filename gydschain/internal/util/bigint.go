@@ -0,0 +1,122 @@
+package util
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidAmount is returned for amounts that are not non-negative integers
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Big is a big.Int that encodes to JSON as a decimal string, so amounts
+// survive JavaScript and other float64 decoders. It decodes from either a
+// string or a bare number.
+//
+// Amounts held as *big.Int are treated as immutable: arithmetic always
+// allocates the result, so values may be shared between copies.
+type Big big.Int
+
+// MarshalJSON encodes b as a decimal string
+func (b *Big) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte(`"0"`), nil
+	}
+	return json.Marshal((*big.Int)(b).String())
+}
+
+// UnmarshalJSON decodes a decimal string or number
+func (b *Big) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" || len(data) == 0 {
+		(*big.Int)(b).SetUint64(0)
+		return nil
+	}
+	v, err := ParseBig(string(data))
+	if err != nil {
+		return err
+	}
+	(*big.Int)(b).Set(v)
+	return nil
+}
+
+// Scan reads a decimal amount from a database column
+func (b *Big) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		(*big.Int)(b).SetUint64(0)
+		return nil
+	case int64:
+		(*big.Int)(b).SetInt64(v)
+		return nil
+	case []byte:
+		return b.UnmarshalJSON(v)
+	case string:
+		return b.UnmarshalJSON([]byte(v))
+	}
+	return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+}
+
+// Value stores the amount as a decimal string
+func (b *Big) Value() (driver.Value, error) {
+	if b == nil {
+		return "0", nil
+	}
+	return (*big.Int)(b).String(), nil
+}
+
+// Int returns b as a *big.Int, or zero if b is nil
+func (b *Big) Int() *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(b)
+}
+
+// BigMap is a map of amounts that encodes its values as decimal strings
+type BigMap map[string]*big.Int
+
+// MarshalJSON encodes each amount as a decimal string
+func (m BigMap) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	out := make(map[string]*Big, len(m))
+	for k, v := range m {
+		out[k] = (*Big)(v)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes amounts from strings or numbers
+func (m *BigMap) UnmarshalJSON(data []byte) error {
+	var in map[string]*Big
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*m = make(BigMap, len(in))
+	for k, v := range in {
+		(*m)[k] = v.Int()
+	}
+	return nil
+}
+
+// ParseBig parses a non-negative base-10 integer
+func ParseBig(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	return v, nil
+}
+
+// CopyBig returns a copy of v, or zero if v is nil
+func CopyBig(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(v)
+}
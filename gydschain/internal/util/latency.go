@@ -0,0 +1,77 @@
+package util
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencySamples is the number of recent samples kept for percentiles
+const DefaultLatencySamples = 512
+
+// LatencyTracker keeps a ring buffer of recent operation durations
+type LatencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// LatencyPercentiles summarizes tracked durations in milliseconds
+type LatencyPercentiles struct {
+	Samples int     `json:"samples"`
+	P50     float64 `json:"p50_ms"`
+	P95     float64 `json:"p95_ms"`
+	P99     float64 `json:"p99_ms"`
+	Max     float64 `json:"max_ms"`
+}
+
+// NewLatencyTracker creates a tracker holding up to size samples
+func NewLatencyTracker(size int) *LatencyTracker {
+	if size <= 0 {
+		size = DefaultLatencySamples
+	}
+	return &LatencyTracker{samples: make([]time.Duration, size)}
+}
+
+// Record adds a sample, overwriting the oldest once full
+func (t *LatencyTracker) Record(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples[t.next] = d
+	t.next = (t.next + 1) % len(t.samples)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// Percentiles returns the p50/p95/p99/max of the recorded samples
+func (t *LatencyTracker) Percentiles() *LatencyPercentiles {
+	t.mu.Lock()
+	n := t.next
+	if t.full {
+		n = len(t.samples)
+	}
+	sorted := append([]time.Duration(nil), t.samples[:n]...)
+	t.mu.Unlock()
+
+	result := &LatencyPercentiles{Samples: n}
+	if n == 0 {
+		return result
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(p int) float64 {
+		return millis(sorted[(n-1)*p/100])
+	}
+	result.P50 = at(50)
+	result.P95 = at(95)
+	result.P99 = at(99)
+	result.Max = millis(sorted[n-1])
+	return result
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
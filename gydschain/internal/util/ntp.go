@@ -0,0 +1,211 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/logging"
+)
+
+// logger is the util module logger
+var logger = logging.Module("util")
+
+// SNTP defaults
+const (
+	DefaultMaxClockDrift   = 500 * time.Millisecond
+	DefaultClockCheckEvery = 10 * time.Minute
+
+	ntpEpochOffset = 2208988800 // seconds between 1900 and 1970
+	ntpTimeout     = 5 * time.Second
+)
+
+// DefaultNTPServers are queried in order until one answers
+var DefaultNTPServers = []string{
+	"pool.ntp.org",
+	"time.google.com",
+	"time.cloudflare.com",
+}
+
+// ClockStatus is the result of the last clock drift check
+type ClockStatus struct {
+	OffsetMs   int64  `json:"offset_ms"`
+	MaxDriftMs int64  `json:"max_drift_ms"`
+	Server     string `json:"server,omitempty"`
+	CheckedAt  int64  `json:"checked_at"`
+	Exceeded   bool   `json:"exceeded"`
+	Error      string `json:"error,omitempty"`
+}
+
+// QueryNTP returns the local clock offset against an SNTP server; a positive
+// offset means the local clock is behind
+func QueryNTP(server string) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(server, "123"), ntpTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ntpTimeout))
+
+	req := make([]byte, 48)
+	req[0] = 0x1B // LI=0, VN=3, Mode=3 (client)
+	sent := time.Now()
+	putNTPTime(req[40:], sent)
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+	if n < 48 || resp[0]&0x07 != 4 {
+		return 0, ErrBadNTPResponse
+	}
+	if resp[1] == 0 {
+		return 0, ErrNTPKissOfDeath
+	}
+
+	serverRecv := ntpTime(resp[32:])
+	serverSent := ntpTime(resp[40:])
+	return (serverRecv.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
+
+// putNTPTime writes t as a 64-bit NTP timestamp
+func putNTPTime(b []byte, t time.Time) {
+	nsec := uint64(t.UnixNano())
+	secs := nsec/1e9 + ntpEpochOffset
+	frac := (nsec % 1e9) << 32 / 1e9
+	binary.BigEndian.PutUint32(b[0:], uint32(secs))
+	binary.BigEndian.PutUint32(b[4:], uint32(frac))
+}
+
+// ntpTime reads a 64-bit NTP timestamp
+func ntpTime(b []byte) time.Time {
+	secs := uint64(binary.BigEndian.Uint32(b[0:])) - ntpEpochOffset
+	frac := uint64(binary.BigEndian.Uint32(b[4:]))
+	return time.Unix(int64(secs), int64(frac*1e9>>32))
+}
+
+// ClockMonitor periodically measures local clock drift against SNTP servers
+type ClockMonitor struct {
+	mu       sync.RWMutex
+	servers  []string
+	maxDrift time.Duration
+	interval time.Duration
+	status   *ClockStatus
+	stop     chan struct{}
+}
+
+// NewClockMonitor creates a clock monitor; zero values select the defaults
+func NewClockMonitor(servers []string, maxDrift, interval time.Duration) *ClockMonitor {
+	if len(servers) == 0 {
+		servers = DefaultNTPServers
+	}
+	if maxDrift <= 0 {
+		maxDrift = DefaultMaxClockDrift
+	}
+	if interval <= 0 {
+		interval = DefaultClockCheckEvery
+	}
+	return &ClockMonitor{
+		servers:  servers,
+		maxDrift: maxDrift,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Check queries the configured servers and records the first answer
+func (cm *ClockMonitor) Check() *ClockStatus {
+	status := &ClockStatus{
+		MaxDriftMs: cm.maxDrift.Milliseconds(),
+		CheckedAt:  time.Now().Unix(),
+	}
+
+	var lastErr error
+	for _, server := range cm.servers {
+		offset, err := QueryNTP(server)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		status.Server = server
+		status.OffsetMs = offset.Milliseconds()
+		status.Exceeded = offset > cm.maxDrift || offset < -cm.maxDrift
+		lastErr = nil
+		break
+	}
+	if lastErr != nil {
+		status.Error = lastErr.Error()
+	}
+
+	cm.mu.Lock()
+	// An unreachable server keeps the last known offset so a network blip
+	// does not clear a real drift warning
+	if status.Error != "" && cm.status != nil {
+		status.OffsetMs = cm.status.OffsetMs
+		status.Server = cm.status.Server
+		status.Exceeded = cm.status.Exceeded
+	}
+	cm.status = status
+	cm.mu.Unlock()
+
+	if status.Exceeded {
+		logger.Warn("local clock is off; validation is disabled until it is fixed",
+			"offset_ms", status.OffsetMs, "max_ms", status.MaxDriftMs)
+	} else if status.Error != "" {
+		logger.Warn("clock drift check failed", "err", status.Error)
+	}
+	return status
+}
+
+// Start checks the clock now and then every interval
+func (cm *ClockMonitor) Start() {
+	cm.Check()
+	go func() {
+		ticker := time.NewTicker(cm.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cm.Check()
+			case <-cm.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops periodic checks
+func (cm *ClockMonitor) Stop() {
+	close(cm.stop)
+}
+
+// Status returns a copy of the last check, or nil before the first one
+func (cm *ClockMonitor) Status() *ClockStatus {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.status == nil {
+		return nil
+	}
+	status := *cm.status
+	return &status
+}
+
+// Exceeded reports whether the last measured drift is above the threshold
+func (cm *ClockMonitor) Exceeded() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.status != nil && cm.status.Exceeded
+}
+
+// SNTP errors
+var (
+	ErrBadNTPResponse = errors.New("malformed NTP response")
+	ErrNTPKissOfDeath = errors.New("NTP server refused the request")
+)
@@ -0,0 +1,33 @@
+#!/bin/bash
+
+# GYDS Chain RPC Client Generator
+# Regenerates the error catalog served by rpc_errorCodes and the
+# TypeScript/Python clients from api/rpc/methods.json, and copies the
+# clients into the frontend so it stays in sync with the node.
+
+set -e
+
+# Configuration
+SCHEMA_FILE="${SCHEMA_FILE:-./api/rpc/methods.json}"
+OUT_DIR="${OUT_DIR:-./api/rpc/clients}"
+FRONTEND_DIR="${FRONTEND_DIR:-/var/www/gydschain}"
+FRONTEND_LIB="${FRONTEND_LIB:-$FRONTEND_DIR/src/lib}"
+
+# Colors
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m' # No Color
+
+echo -e "${GREEN}Generating the RPC error catalog${NC}"
+go generate ./internal/rpc
+
+echo -e "${GREEN}Generating RPC clients from $SCHEMA_FILE${NC}"
+go run ./cmd/rpcgen -schema "$SCHEMA_FILE" -out "$OUT_DIR"
+
+if [ -d "$FRONTEND_DIR" ]; then
+    mkdir -p "$FRONTEND_LIB"
+    cp "$OUT_DIR/gydschain.ts" "$FRONTEND_LIB/gydschain.ts"
+    echo -e "${GREEN}Copied TypeScript client to $FRONTEND_LIB${NC}"
+else
+    echo -e "${YELLOW}Frontend not found at $FRONTEND_DIR, skipping copy${NC}"
+fi
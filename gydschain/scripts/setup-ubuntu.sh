@@ -57,6 +57,8 @@ install_base_packages() {
         curl \
         wget \
         jq \
+        openssl \
+        xxd \
         make \
         gcc \
         g++ \
@@ -311,7 +313,9 @@ ExecStart=$INSTALL_DIR/bin/gydschain-litenode \\
     --datadir $INSTALL_DIR/data/lite \\
     --config $CONFIG_DIR/litenode.json \\
     --sync-mode light \\
-    --bootstrap-nodes $CONFIG_DIR/bootstrap.json
+    --bootstrap-nodes $CONFIG_DIR/bootstrap.json \\
+    --checkpoints $CONFIG_DIR/checkpoints.json \\
+    --snapshot $CONFIG_DIR/snapshot.json
 Restart=on-failure
 RestartSec=10
 StandardOutput=append:$LOG_DIR/litenode.log
@@ -384,11 +388,19 @@ EOF
 }
 
 # ============ LITE NODE REGISTRATION ============
+# Print the hex ed25519 public key (the node ID) of a PEM identity key
+node_identity_id() {
+    sudo openssl pkey -in "$1" -pubout -outform DER | tail -c 32 | xxd -p -c 64
+}
+
 register_litenode() {
     echo -e "${BLUE}Registering Lite Node with Admin...${NC}"
     
-    # Generate node keypair
-    NODE_ID=$(cat /dev/urandom | tr -dc 'a-f0-9' | fold -w 64 | head -n 1)
+    # Generate node keypair; the node ID is its ed25519 public key, so the
+    # node can later sign a request to rotate it
+    sudo openssl genpkey -algorithm ed25519 -out $CONFIG_DIR/node_identity.pem
+    sudo chmod 600 $CONFIG_DIR/node_identity.pem
+    NODE_ID=$(node_identity_id $CONFIG_DIR/node_identity.pem)
     HOSTNAME=$(hostname)
     PUBLIC_IP=$(curl -s ifconfig.me)
     
@@ -457,6 +469,25 @@ activate_litenode() {
         # Save bootstrap nodes
         echo "$BOOTSTRAP_NODES" | sudo tee $CONFIG_DIR/bootstrap.json > /dev/null
         
+        # Pin the admin signing key; the lite node refreshes peers from the signed list
+        BOOTSTRAP_KEY=$(echo "$RESPONSE" | jq -r '.bootstrap_key')
+        echo -e "${YELLOW}Pinning admin bootstrap key $BOOTSTRAP_KEY - confirm it with the admin.${NC}"
+        jq -n --arg url "$ADMIN_URL/admin-api/bootstrap" --arg key "$BOOTSTRAP_KEY" \
+            '{bootstrap_url: $url, bootstrap_key: $key, bootstrap_refresh: 300}' \
+            | sudo tee $CONFIG_DIR/litenode.json > /dev/null
+        
+        # Save snapshot descriptor (relative links are served by the admin API)
+        SNAPSHOT=$(echo "$RESPONSE" | jq --arg base "$ADMIN_URL/admin-api" \
+            '.snapshot | if . != null and (.url | startswith("http") | not) then .url = $base + .url else . end')
+        if [ "$SNAPSHOT" != "null" ]; then
+            echo "$SNAPSHOT" | sudo tee $CONFIG_DIR/snapshot.json > /dev/null
+        fi
+        
+        # Apply the sync mode from the node's provisioning profile
+        SYNC_MODE=$(echo "$RESPONSE" | jq -r '.profile.sync_mode // "light"')
+        sudo sed -i "s/--sync-mode [a-z]*/--sync-mode $SYNC_MODE/" /etc/systemd/system/gydschain-litenode.service
+        sudo systemctl daemon-reload
+        
         # Start WireGuard and Lite Node
         sudo systemctl enable wg-quick@wg0
         sudo systemctl start wg-quick@wg0
@@ -469,6 +500,60 @@ activate_litenode() {
     fi
 }
 
+# Replace a compromised node identity and WireGuard key. The request is
+# signed with the current identity key; the node keeps its approval and VPN
+# address.
+rotate_litenode() {
+    echo -e "${BLUE}Rotating Lite Node identity...${NC}"
+    
+    if [ ! -f "$CONFIG_DIR/node_identity.pem" ]; then
+        echo -e "${RED}No identity key to sign with. Register the node again instead.${NC}"
+        return 1
+    fi
+    
+    read -p "Enter Admin Server URL: " ADMIN_URL
+    
+    OLD_ID=$(node_identity_id $CONFIG_DIR/node_identity.pem)
+    sudo openssl genpkey -algorithm ed25519 -out $CONFIG_DIR/node_identity.pem.new
+    sudo chmod 600 $CONFIG_DIR/node_identity.pem.new
+    NEW_ID=$(node_identity_id $CONFIG_DIR/node_identity.pem.new)
+    PRIVATE_KEY=$(wg genkey)
+    PUBLIC_KEY=$(echo $PRIVATE_KEY | wg pubkey)
+    TIMESTAMP=$(date +%s)
+    
+    # ed25519 signs the whole message at once, so it must come from a file
+    MESSAGE_FILE=$(mktemp)
+    printf '%s' "gydschain-node-rotation:$OLD_ID:$NEW_ID:$PUBLIC_KEY:$TIMESTAMP" > $MESSAGE_FILE
+    SIGNATURE=$(sudo openssl pkeyutl -sign -rawin -inkey $CONFIG_DIR/node_identity.pem -in $MESSAGE_FILE \
+        | xxd -p -c 128)
+    rm -f $MESSAGE_FILE
+    
+    REQUEST=$(jq -n --arg old "$OLD_ID" --arg new "$NEW_ID" --arg wg "$PUBLIC_KEY" \
+        --argjson ts "$TIMESTAMP" --arg sig "$SIGNATURE" \
+        '{node_id: $old, new_node_id: $new, new_wireguard_public_key: $wg, timestamp: $ts, signature: $sig}')
+    
+    RESPONSE=$(curl -s -X POST "$ADMIN_URL/admin-api/nodes/rotate" \
+        -H "Content-Type: application/json" \
+        -d "$REQUEST")
+    
+    if echo "$RESPONSE" | grep -q "success"; then
+        # Switch to the new keys only once the admin accepted them
+        sudo mv $CONFIG_DIR/node_identity.pem.new $CONFIG_DIR/node_identity.pem
+        echo "$PRIVATE_KEY" | sudo tee $CONFIG_DIR/wireguard_private.key > /dev/null
+        jq --arg id "$NEW_ID" --arg wg "$PUBLIC_KEY" '.node_id = $id | .wireguard_public_key = $wg' \
+            $CONFIG_DIR/node_info.json | sudo tee $CONFIG_DIR/node_info.json.new > /dev/null
+        sudo mv $CONFIG_DIR/node_info.json.new $CONFIG_DIR/node_info.json
+        if [ -f /etc/wireguard/wg0.conf ]; then
+            sudo sed -i "s|^PrivateKey = .*|PrivateKey = $PRIVATE_KEY|" /etc/wireguard/wg0.conf
+            sudo systemctl restart wg-quick@wg0
+        fi
+        echo -e "${GREEN}✅ Node identity rotated. New Node ID: $NEW_ID${NC}"
+    else
+        sudo rm -f $CONFIG_DIR/node_identity.pem.new
+        echo -e "${RED}❌ Rotation failed: $RESPONSE${NC}"
+    fi
+}
+
 # ============ INSTALLATION FUNCTIONS ============
 install_full_node() {
     echo -e "${GREEN}Installing Full Node...${NC}"
@@ -606,6 +691,11 @@ if [ "$1" == "--activate-litenode" ]; then
     exit 0
 fi
 
+if [ "$1" == "--rotate-litenode" ]; then
+    rotate_litenode
+    exit 0
+fi
+
 if [ "$1" == "--update" ]; then
     update_and_rebuild
     exit 0
@@ -0,0 +1,256 @@
+package test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// beyondUint64 returns 2^64 + n, an amount that overflows uint64
+func beyondUint64(n int64) *big.Int {
+	v := new(big.Int).Lsh(big.NewInt(1), 64)
+	return v.Add(v, big.NewInt(n))
+}
+
+func TestTransactionAmountJSON(t *testing.T) {
+	amount := beyondUint64(7)
+	transaction := tx.NewTransfer("gyds1from", "gyds1to", amount, "GYDS")
+	transaction.SetFee(beyondUint64(1))
+
+	data, err := json.Marshal(transaction)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"amount":"18446744073709551623"`) {
+		t.Errorf("amount not encoded as a decimal string: %s", data)
+	}
+	if !strings.Contains(string(data), `"fee":"18446744073709551617"`) {
+		t.Errorf("fee not encoded as a decimal string: %s", data)
+	}
+
+	var decoded tx.Transaction
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Amount.Cmp(amount) != 0 {
+		t.Errorf("expected amount %s, got %s", amount, decoded.Amount)
+	}
+	if decoded.Fee.Cmp(beyondUint64(1)) != 0 {
+		t.Errorf("expected fee %s, got %s", beyondUint64(1), decoded.Fee)
+	}
+
+	before, _ := transaction.HashHex()
+	after, _ := decoded.HashHex()
+	if before != after {
+		t.Errorf("hash changed across JSON round trip: %s != %s", before, after)
+	}
+}
+
+func TestTransactionAmountFromNumber(t *testing.T) {
+	var decoded tx.Transaction
+	if err := json.Unmarshal([]byte(`{"type":"transfer","amount":1000,"fee":21000}`), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Amount.Cmp(big.NewInt(1000)) != 0 || decoded.Fee.Cmp(big.NewInt(21000)) != 0 {
+		t.Errorf("expected amount 1000 fee 21000, got %s %s", decoded.Amount, decoded.Fee)
+	}
+
+	for _, bad := range []string{`{"amount":"-1"}`, `{"amount":"1.5"}`, `{"fee":"0x10"}`} {
+		if err := json.Unmarshal([]byte(bad), &decoded); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestTransactionNegativeAmount(t *testing.T) {
+	transaction := tx.NewTransfer("gyds1from", "gyds1to", big.NewInt(-1), "GYDS")
+	if err := transaction.Verify(); err == nil {
+		t.Error("expected negative amount to fail verification")
+	}
+}
+
+func TestAccountBalanceBeyondUint64(t *testing.T) {
+	acc := state.NewAccount("gyds1test123")
+	acc.SetBalance("GYDS", new(big.Int).SetUint64(^uint64(0)))
+	acc.AddBalance("GYDS", big.NewInt(1))
+
+	if acc.GetBalance("GYDS").Cmp(beyondUint64(0)) != 0 {
+		t.Errorf("expected balance 2^64, got %s", acc.GetBalance("GYDS"))
+	}
+
+	if acc.SubBalance("GYDS", beyondUint64(1)) {
+		t.Error("expected subtracting more than the balance to fail")
+	}
+	if !acc.SubBalance("GYDS", big.NewInt(1)) {
+		t.Error("expected subtracting within the balance to succeed")
+	}
+	if acc.GetBalance("GYDS").Uint64() != ^uint64(0) {
+		t.Errorf("expected balance 2^64-1, got %s", acc.GetBalance("GYDS"))
+	}
+
+	// Returned balances are copies
+	acc.GetBalance("GYDS").SetInt64(0)
+	if acc.GetBalance("GYDS").Sign() == 0 {
+		t.Error("mutating a returned balance changed the account")
+	}
+}
+
+func TestAccountStakeBeyondUint64(t *testing.T) {
+	acc := state.NewAccount("gyds1test123")
+	acc.SetBalance("GYDS", beyondUint64(1000))
+
+	if !acc.Stake(beyondUint64(0)) {
+		t.Fatal("expected stake to succeed")
+	}
+	if !acc.Delegate("gyds1validator", big.NewInt(600)) {
+		t.Fatal("expected delegation to succeed")
+	}
+	if acc.GetStaked().Cmp(beyondUint64(0)) != 0 {
+		t.Errorf("expected stake 2^64, got %s", acc.GetStaked())
+	}
+	if acc.GetBalance("GYDS").Cmp(big.NewInt(400)) != 0 {
+		t.Errorf("expected balance 400, got %s", acc.GetBalance("GYDS"))
+	}
+	if acc.Unstake(beyondUint64(1)) {
+		t.Error("expected unstaking more than the stake to fail")
+	}
+}
+
+func TestAccountJSON(t *testing.T) {
+	acc := state.NewAccount("gyds1test123")
+	acc.SetBalance("GYDS", beyondUint64(5))
+	acc.SetBalance("GYD", big.NewInt(42))
+	acc.Stake(big.NewInt(5))
+	acc.Delegate("gyds1validator", big.NewInt(3))
+
+	data, err := acc.Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if !strings.Contains(string(data), `"GYDS":"18446744073709551613"`) {
+		t.Errorf("balance not encoded as a decimal string: %s", data)
+	}
+
+	decoded, err := state.Deserialize(data)
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	if decoded.GetBalance("GYDS").Cmp(acc.GetBalance("GYDS")) != 0 {
+		t.Errorf("expected GYDS %s, got %s", acc.GetBalance("GYDS"), decoded.GetBalance("GYDS"))
+	}
+	if decoded.GetBalance("GYD").Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("expected GYD 42, got %s", decoded.GetBalance("GYD"))
+	}
+	if decoded.GetStaked().Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("expected stake 5, got %s", decoded.GetStaked())
+	}
+	if decoded.GetDelegation("gyds1validator").Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("expected delegation 3, got %s", decoded.GetDelegation("gyds1validator"))
+	}
+}
+
+func TestStateTransferBeyondUint64(t *testing.T) {
+	db := state.NewStateDB()
+	sender := state.NewAccount("gyds1alice")
+	sender.SetBalance("GYDS", beyondUint64(100))
+	db.SetAccount(sender.Address, sender)
+
+	if err := db.Transfer("gyds1alice", "gyds1bob", "GYDS", beyondUint64(0)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if db.GetBalance("gyds1bob", "GYDS").Cmp(beyondUint64(0)) != 0 {
+		t.Errorf("expected bob 2^64, got %s", db.GetBalance("gyds1bob", "GYDS"))
+	}
+	if db.GetBalance("gyds1alice", "GYDS").Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("expected alice 100, got %s", db.GetBalance("gyds1alice", "GYDS"))
+	}
+	if err := db.Transfer("gyds1alice", "gyds1bob", "GYDS", big.NewInt(101)); err == nil {
+		t.Error("expected insufficient balance error")
+	}
+}
+
+func TestAssetSupplyBeyondUint64(t *testing.T) {
+	asset := state.NewFungibleAsset("BIG", "Big Token", "BIG", 18, "gyds1creator")
+	asset.MaxSupply = beyondUint64(10)
+
+	if err := asset.Mint(beyondUint64(0)); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if err := asset.Mint(big.NewInt(11)); err == nil {
+		t.Error("expected max supply error")
+	}
+	if err := asset.Burn(big.NewInt(1)); err != nil {
+		t.Fatalf("burn: %v", err)
+	}
+
+	data, err := json.Marshal(asset)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"total_supply":"18446744073709551615"`) {
+		t.Errorf("supply not encoded as a decimal string: %s", data)
+	}
+
+	var decoded state.Asset
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.TotalSupply.Uint64() != ^uint64(0) || decoded.MaxSupply.Cmp(beyondUint64(10)) != 0 {
+		t.Errorf("supplies changed across JSON round trip: %s / %s", decoded.TotalSupply, decoded.MaxSupply)
+	}
+}
+
+func TestValidatorStakeBeyondUint64(t *testing.T) {
+	v := pos.NewValidator("gyds1validator", "pubkey", beyondUint64(0))
+	v.AddDelegation("gyds1delegator", beyondUint64(0))
+
+	want := new(big.Int).Lsh(big.NewInt(1), 65)
+	if v.TotalStake.Cmp(want) != 0 {
+		t.Errorf("expected total stake 2^65, got %s", v.TotalStake)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"total_stake":"36893488147419103232"`) {
+		t.Errorf("stake not encoded as a decimal string: %s", data)
+	}
+
+	var decoded pos.Validator
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.TotalStake.Cmp(want) != 0 || decoded.GetDelegation("gyds1delegator").Cmp(beyondUint64(0)) != 0 {
+		t.Errorf("stake changed across JSON round trip: %s", data)
+	}
+}
+
+func TestFeeBurnBeyondUint64(t *testing.T) {
+	fee := beyondUint64(0)
+	burned := tx.CalculateBurnAmount(fee, 5000)
+	share := tx.CalculateValidatorShare(fee, 5000)
+
+	if new(big.Int).Add(burned, share).Cmp(fee) != 0 {
+		t.Errorf("burn %s and validator share %s don't add up to fee %s", burned, share, fee)
+	}
+	if burned.Cmp(new(big.Int).Lsh(big.NewInt(1), 63)) != 0 {
+		t.Errorf("expected half the fee burned, got %s", burned)
+	}
+}
+
+func TestBigMapJSON(t *testing.T) {
+	var m util.BigMap
+	if err := json.Unmarshal([]byte(`{"GYDS":"12345678901234567890123","GYD":7}`), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if m["GYDS"].String() != "12345678901234567890123" || m["GYD"].Int64() != 7 {
+		t.Errorf("unexpected map %v", m)
+	}
+}
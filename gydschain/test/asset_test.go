@@ -0,0 +1,226 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+// newAssetChain starts a chain funding addrs and returns it with a function
+// that includes a transaction in a block of its own
+func newAssetChain(t *testing.T, addrs ...string) (*state.StateDB, func(*tx.Transaction, error) error) {
+	genesis := chain.DefaultGenesis()
+	genesis.Alloc = nil
+	for _, addr := range addrs {
+		genesis.Alloc = append(genesis.Alloc, chain.AllocConfig{Address: addr, GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)})
+	}
+	return newGenesisChain(t, genesis)
+}
+
+// newGenesisChain is newAssetChain for a caller-built genesis
+func newGenesisChain(t *testing.T, genesis *chain.GenesisConfig) (*state.StateDB, func(*tx.Transaction, error) error) {
+	genesis.Params.MinTransfer = nil
+
+	stateDB := state.NewStateDB()
+	c, err := chain.NewChain(nil, stateDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+
+	mempool := tx.NewMempool(nil)
+	t.Cleanup(mempool.Stop)
+
+	nonces := make(map[string]uint64)
+	submit := func(transaction *tx.Transaction, err error) error {
+		if err != nil {
+			return err
+		}
+		transaction.Nonce = nonces[transaction.From]
+		transaction.Fee = big.NewInt(1e9)
+		transaction.Sign([]byte("key"))
+		if err := mempool.AddTx(transaction); err != nil {
+			return err
+		}
+		block := c.ProposeBlock(mempool, "gyds1validator")
+		if err := c.AddBlock(block); err != nil {
+			hash, _ := transaction.HashHex()
+			mempool.RemoveTx(hash)
+			return err
+		}
+		mempool.Update(block.Header.Height, block.Transactions)
+		nonces[transaction.From]++
+		return nil
+	}
+	return stateDB, submit
+}
+
+func TestCreateAssetTransaction(t *testing.T) {
+	stateDB, submit := newAssetChain(t, "gyds1creator")
+	create := func(p *tx.CreateAssetPayload, supply int64) error {
+		return submit(tx.NewCreateAsset("gyds1creator", p, big.NewInt(supply)))
+	}
+
+	token := &tx.CreateAssetPayload{
+		Symbol:    "TEST",
+		Name:      "Test Token",
+		Decimals:  6,
+		MaxSupply: (*util.Big)(big.NewInt(1e12)),
+		Mintable:  true,
+	}
+	if err := create(token, 5e11); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+
+	asset := stateDB.GetAsset("TEST")
+	if asset == nil {
+		t.Fatal("asset not stored")
+	}
+	if asset.Name != "Test Token" || asset.Decimals != 6 || asset.Owner != "gyds1creator" {
+		t.Errorf("unexpected asset %+v", asset)
+	}
+	if !asset.Mintable || asset.Burnable {
+		t.Errorf("expected mintable, non-burnable asset, got %v/%v", asset.Mintable, asset.Burnable)
+	}
+	if asset.TotalSupply.Cmp(big.NewInt(5e11)) != 0 || asset.MaxSupply.Cmp(big.NewInt(1e12)) != 0 {
+		t.Errorf("unexpected supply %s/%s", asset.TotalSupply, asset.MaxSupply)
+	}
+	if got := stateDB.GetAccount("gyds1creator").GetBalance("TEST"); got.Cmp(big.NewInt(5e11)) != 0 {
+		t.Errorf("expected creator balance 500000000000, got %s", got)
+	}
+
+	// The fee of a transfer is paid in the asset sent
+	if err := submit(tx.NewTransfer("gyds1creator", "gyds1holder", big.NewInt(2e8), "TEST"), nil); err != nil {
+		t.Fatalf("transfer created asset: %v", err)
+	}
+	if got := stateDB.GetBalance("gyds1holder", "TEST"); got.Cmp(big.NewInt(2e8)) != 0 {
+		t.Errorf("expected holder balance 200000000, got %s", got)
+	}
+
+	if err := create(token, 1); err != state.ErrAssetExists {
+		t.Errorf("expected ErrAssetExists, got %v", err)
+	}
+	if err := create(&tx.CreateAssetPayload{Symbol: "GYD", Name: "Fake"}, 1); err != state.ErrAssetExists {
+		t.Errorf("expected ErrAssetExists for native symbol, got %v", err)
+	}
+
+	capped := &tx.CreateAssetPayload{Symbol: "CAP", Name: "Capped", MaxSupply: (*util.Big)(big.NewInt(10))}
+	if err := create(capped, 11); err != state.ErrExceedsMaxSupply {
+		t.Errorf("expected ErrExceedsMaxSupply, got %v", err)
+	}
+
+	if _, err := tx.NewCreateAsset("gyds1creator", &tx.CreateAssetPayload{Symbol: "USDX", Name: "Dollar", Stablecoin: true}, big.NewInt(0)); err != tx.ErrMissingPeg {
+		t.Errorf("expected ErrMissingPeg, got %v", err)
+	}
+}
+
+func TestNFTTransactions(t *testing.T) {
+	stateDB, submit := newAssetChain(t, "gyds1artist", "gyds1alice", "gyds1bob")
+
+	mint := func(from, to, tokenID string) error {
+		return submit(tx.NewNFTMint(from, to, &tx.NFTMintPayload{
+			Collection: "ART",
+			TokenID:    tokenID,
+			Name:       "Piece " + tokenID,
+			TokenURI:   "ipfs://art/" + tokenID,
+		}))
+	}
+	if err := mint("gyds1artist", "gyds1alice", "1"); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if err := mint("gyds1artist", "gyds1alice", "1"); err != state.ErrAssetExists {
+		t.Errorf("expected ErrAssetExists for a minted token, got %v", err)
+	}
+	if err := mint("gyds1alice", "gyds1alice", "2"); err != state.ErrNotAssetOwner {
+		t.Errorf("expected ErrNotAssetOwner for a mint by a non-owner, got %v", err)
+	}
+
+	collection := stateDB.GetAsset("ART")
+	if collection == nil || collection.Owner != "gyds1artist" || collection.TotalSupply.Int64() != 1 {
+		t.Fatalf("unexpected collection %+v", collection)
+	}
+	token := stateDB.GetNFT("ART", "1")
+	if token == nil || token.Metadata == nil || token.Metadata.TokenURI != "ipfs://art/1" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+	if owner, _ := stateDB.NFTOwner("ART", "1"); owner != "gyds1alice" {
+		t.Errorf("expected gyds1alice to own the token, got %s", owner)
+	}
+
+	if err := submit(tx.NewNFTTransfer("gyds1bob", "gyds1bob", "ART", "1")); err != state.ErrNotNFTOwner {
+		t.Errorf("expected ErrNotNFTOwner, got %v", err)
+	}
+	if err := submit(tx.NewNFTTransfer("gyds1alice", "gyds1bob", "ART", "1")); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if owner, _ := stateDB.NFTOwner("ART", "1"); owner != "gyds1bob" {
+		t.Errorf("expected gyds1bob to own the token, got %s", owner)
+	}
+	id := state.NFTAssetID("ART", "1")
+	if stateDB.GetBalance("gyds1alice", id).Sign() != 0 || stateDB.GetBalance("gyds1bob", id).Int64() != 1 {
+		t.Error("token balance did not move with ownership")
+	}
+	if err := submit(tx.NewNFTTransfer("gyds1bob", "gyds1alice", "ART", "2")); err != state.ErrNFTNotFound {
+		t.Errorf("expected ErrNFTNotFound, got %v", err)
+	}
+}
+
+func TestTransferPolicyExecution(t *testing.T) {
+	stateDB, submit := newAssetChain(t, "gyds1issuer", "gyds1alice", "gyds1bob", "gyds1carol")
+	token := &tx.CreateAssetPayload{Symbol: "POL", Name: "Policy Token"}
+	if err := submit(tx.NewCreateAsset("gyds1issuer", token, big.NewInt(1e14))); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+	for _, holder := range []string{"gyds1alice", "gyds1bob", "gyds1carol"} {
+		if err := submit(tx.NewTransfer("gyds1issuer", holder, big.NewInt(1e12), "POL"), nil); err != nil {
+			t.Fatalf("fund %s: %v", holder, err)
+		}
+	}
+
+	setPolicy := tx.NewTransaction(tx.TxTypeSetPolicy, "gyds1issuer", "POL", new(big.Int), "GYDS")
+	policy := &state.TransferPolicy{
+		FeeBasisPoints: 100,
+		FeeSink:        "gyds1sink",
+		WhitelistOnly:  true,
+		Whitelist:      []string{"gyds1alice", "gyds1bob"},
+	}
+	if err := setPolicy.SetPayload(policy); err != nil {
+		t.Fatal(err)
+	}
+	if err := submit(setPolicy, nil); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	if asset := stateDB.GetAsset("POL"); asset.Policy == nil || asset.UpdatedAt != setPolicy.Timestamp {
+		t.Fatalf("policy not stored at the transaction time: %+v", asset)
+	}
+
+	if err := submit(tx.NewTransfer("gyds1alice", "gyds1carol", big.NewInt(1e10), "POL"), nil); err != state.ErrRecipientNotWhitelisted {
+		t.Errorf("expected ErrRecipientNotWhitelisted, got %v", err)
+	}
+	if err := submit(tx.NewTransfer("gyds1carol", "gyds1alice", big.NewInt(1e10), "POL"), nil); err != state.ErrSenderNotWhitelisted {
+		t.Errorf("expected ErrSenderNotWhitelisted, got %v", err)
+	}
+
+	if err := submit(tx.NewTransfer("gyds1alice", "gyds1bob", big.NewInt(1e10), "POL"), nil); err != nil {
+		t.Fatalf("whitelisted transfer: %v", err)
+	}
+	if got := stateDB.GetBalance("gyds1bob", "POL"); got.Cmp(big.NewInt(1e12+99e8)) != 0 {
+		t.Errorf("expected the recipient to get the amount less 1%% tax, got %s", got)
+	}
+	if got := stateDB.GetBalance("gyds1sink", "POL"); got.Cmp(big.NewInt(1e8)) != 0 {
+		t.Errorf("expected the fee sink to get 100000000, got %s", got)
+	}
+}
+
+func TestTransferUnknownAsset(t *testing.T) {
+	_, submit := newAssetChain(t, "gyds1alice")
+	if err := submit(tx.NewTransfer("gyds1alice", "gyds1bob", big.NewInt(1), "NOPE"), nil); err != tx.ErrInvalidAsset {
+		t.Errorf("expected ErrInvalidAsset, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestFailedBlockLeavesStateUnchanged(t *testing.T) {
+	genesis := chain.DefaultGenesis()
+	genesis.Params.MinTransfer = nil
+	genesis.Alloc = []chain.AllocConfig{
+		{Address: "gyds1guardian1", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1guardian2", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1poor", GYDSBalance: big.NewInt(1e10), GYDBalance: new(big.Int)},
+	}
+
+	stateDB := state.NewStateDB()
+	c, err := chain.NewChain(nil, stateDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	breaker := pos.NewCircuitBreaker(engine, []string{"gyds1guardian1", "gyds1guardian2"}, 2)
+	c.SetCircuitBreaker(breaker)
+
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+	propose := func(txs ...*tx.Transaction) *chain.Block {
+		for _, transaction := range txs {
+			transaction.Sign([]byte("key"))
+			if err := mempool.AddTx(transaction); err != nil {
+				t.Fatal(err)
+			}
+		}
+		block := c.ProposeBlock(mempool, "gyds1validator")
+		for i, transaction := range block.Transactions {
+			if i >= len(txs) || transaction != txs[i] {
+				t.Fatalf("proposed transactions out of order")
+			}
+		}
+		return block
+	}
+
+	create, err := tx.NewCreateAsset("gyds1guardian2", &tx.CreateAssetPayload{Symbol: "TKN", Name: "Token"}, big.NewInt(1e12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	create.Fee = big.NewInt(1e9)
+	block := propose(create)
+	if err := c.AddBlock(block); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+	mempool.Update(block.Header.Height, block.Transactions)
+
+	// Higher fees run first: the halt vote and the token transfer succeed,
+	// burning part of their fees, before the overdrawn transfer fails
+	vote := tx.NewTransaction(tx.TxTypeHaltVote, "gyds1guardian1", "gyds1guardian1", new(big.Int), "GYDS")
+	if err := vote.SetPayload(&tx.HaltVotePayload{Reason: "incident"}); err != nil {
+		t.Fatal(err)
+	}
+	vote.Fee = big.NewInt(3e9)
+	send := tx.NewTransfer("gyds1guardian2", "gyds1holder", big.NewInt(1e9), "TKN")
+	send.Nonce, send.Fee = 1, big.NewInt(2e9)
+	overdraw := tx.NewTransfer("gyds1poor", "gyds1rich", big.NewInt(1e12), "GYDS")
+	overdraw.Fee = big.NewInt(1e9)
+
+	root := stateDB.Root()
+	supply := stateDB.GetAsset("TKN").TotalSupply.String()
+	tip := stateDB.GetBalance("gyds1validator", "GYDS")
+
+	block = propose(vote, send, overdraw)
+	if len(block.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got %d", len(block.Transactions))
+	}
+	if err := c.AddBlock(block); err == nil {
+		t.Fatal("expected the block to fail")
+	}
+
+	if got := stateDB.Root(); got != root {
+		t.Errorf("state root changed from %s to %s", root, got)
+	}
+	if got := stateDB.GetBalance("gyds1guardian1", "GYDS"); got.Cmp(big.NewInt(1e12)) != 0 {
+		t.Errorf("expected the voter's fee refunded, got balance %s", got)
+	}
+	if account := stateDB.GetAccount("gyds1guardian1"); account.Nonce != 0 {
+		t.Errorf("expected the voter's nonce unchanged, got %d", account.Nonce)
+	}
+	if got := stateDB.GetBalance("gyds1holder", "TKN"); got.Sign() != 0 {
+		t.Errorf("expected the token transfer undone, got balance %s", got)
+	}
+	if got := stateDB.GetBalance("gyds1validator", "GYDS"); got.Cmp(tip) != 0 {
+		t.Errorf("expected no tip paid, got %s", got)
+	}
+	if got := stateDB.GetAsset("TKN").TotalSupply.String(); got != supply {
+		t.Errorf("expected TKN supply %s, got %s", supply, got)
+	}
+	if votes := breaker.Status().HaltVotes; len(votes) != 0 {
+		t.Errorf("expected the halt vote undone, got %v", votes)
+	}
+	if c.Height() != 1 {
+		t.Errorf("expected height 1, got %d", c.Height())
+	}
+}
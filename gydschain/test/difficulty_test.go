@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestExpectedDifficulty(t *testing.T) {
+	blockTime := 5 * time.Second
+	grandparent := &chain.Header{Timestamp: 1000, Difficulty: 800}
+
+	for _, tc := range []struct {
+		interval int64
+		want     uint64
+	}{
+		{5, 800},   // on target
+		{10, 400},  // slow blocks ease difficulty
+		{1, 3200},  // fast blocks raise it, capped at 4x
+		{100, 200}, // and ease it by at most 4x
+		{0, 800},   // a zero interval leaves it unchanged
+	} {
+		parent := &chain.Header{Timestamp: grandparent.Timestamp + tc.interval, Difficulty: 800}
+		if got := chain.ExpectedDifficulty(parent, grandparent, blockTime); got != tc.want {
+			t.Errorf("interval %ds: got %d, want %d", tc.interval, got, tc.want)
+		}
+	}
+
+	genesis := &chain.Header{Difficulty: 1}
+	if got := chain.ExpectedDifficulty(genesis, nil, blockTime); got != 1 {
+		t.Errorf("after genesis: got %d, want 1", got)
+	}
+}
+
+func TestAddBlockRejectsWrongDifficulty(t *testing.T) {
+	c, err := chain.NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(chain.DefaultGenesis()); err != nil {
+		t.Fatal(err)
+	}
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+
+	block := c.ProposeBlock(mempool, "gyds1validator")
+	if block.Header.Difficulty != c.Difficulty().NextDifficulty {
+		t.Fatalf("proposed difficulty %d, want %d", block.Header.Difficulty, c.Difficulty().NextDifficulty)
+	}
+	block.Header.Difficulty++
+	if err := c.AddBlock(block); err != chain.ErrInvalidDifficulty {
+		t.Fatalf("wrong difficulty: got %v", err)
+	}
+	block.Header.Difficulty--
+	if err := c.AddBlock(block); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+func TestDustThresholds(t *testing.T) {
+	genesis := chain.DefaultGenesis()
+	genesis.Params.MinTransfer = map[string]*util.Big{"GYDS": (*util.Big)(big.NewInt(1000))}
+	genesis.Alloc = []chain.AllocConfig{
+		{Address: "gyds1guardian1", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1guardian2", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+	}
+
+	c, err := chain.NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	c.SetCircuitBreaker(pos.NewCircuitBreaker(engine, []string{"gyds1guardian1", "gyds1guardian2"}, 2))
+
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+	mempool.SetDustPolicy(c.Dust())
+
+	nonces := make(map[string]uint64)
+	newTx := func(from string, txType string, amount int64, data []byte) *tx.Transaction {
+		transaction := tx.NewTransaction(txType, from, "gyds1recipient", big.NewInt(amount), "GYDS")
+		transaction.Nonce = nonces[from]
+		transaction.Fee = big.NewInt(1e8)
+		transaction.Data = data
+		transaction.Sign([]byte("key"))
+		nonces[from]++
+		return transaction
+	}
+	addBlock := func(txs ...*tx.Transaction) error {
+		for _, transaction := range txs {
+			if err := mempool.AddTx(transaction); err != nil {
+				return err
+			}
+		}
+		block := c.ProposeBlock(mempool, "gyds1validator")
+		if len(block.Transactions) != len(txs) {
+			t.Fatalf("proposed %d of %d transactions", len(block.Transactions), len(txs))
+		}
+		if err := c.AddBlock(block); err != nil {
+			return err
+		}
+		mempool.Update(block.Header.Height, block.Transactions)
+		return nil
+	}
+
+	// Genesis minimum applies at admission
+	if err := mempool.AddTx(newTx("gyds1guardian1", tx.TxTypeTransfer, 999, nil)); err != tx.ErrDustTransfer {
+		t.Fatalf("expected ErrDustTransfer, got %v", err)
+	}
+	nonces["gyds1guardian1"]--
+	if err := addBlock(newTx("gyds1guardian1", tx.TxTypeTransfer, 1000, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Raising the minimum takes both guardians
+	vote, _ := tx.EncodePayload(&tx.DustVotePayload{Asset: "GYDS", MinAmount: (*util.Big)(big.NewInt(5000))})
+	if err := addBlock(newTx("gyds1guardian1", tx.TxTypeDustVote, 0, vote)); err != nil {
+		t.Fatal(err)
+	}
+	if min := c.Dust().MinTransfer("GYDS"); min.Int64() != 1000 {
+		t.Fatalf("minimum changed to %s before quorum", min)
+	}
+	if status := c.Dust().Status(); len(status.Proposals) != 1 || len(status.Proposals[0].Voters) != 1 {
+		t.Fatalf("expected one pending proposal with one voter, got %+v", status.Proposals)
+	}
+	if err := addBlock(newTx("gyds1guardian2", tx.TxTypeDustVote, 0, vote)); err != nil {
+		t.Fatal(err)
+	}
+	if min := c.Dust().MinTransfer("GYDS"); min.Int64() != 5000 {
+		t.Fatalf("expected minimum 5000, got %s", min)
+	}
+
+	// The new minimum applies at execution as well as admission
+	dust := newTx("gyds1guardian1", tx.TxTypeTransfer, 4000, nil)
+	if err := mempool.AddTx(dust); err != tx.ErrDustTransfer {
+		t.Fatalf("expected ErrDustTransfer, got %v", err)
+	}
+	unfiltered := tx.NewMempool(nil)
+	defer unfiltered.Stop()
+	if err := unfiltered.AddTx(dust); err != nil {
+		t.Fatal(err)
+	}
+	block := c.ProposeBlock(unfiltered, "gyds1validator")
+	if err := c.AddBlock(block); err != tx.ErrDustTransfer {
+		t.Fatalf("expected block with a dust transfer rejected, got %v", err)
+	}
+
+	data, _ := json.Marshal(c.Dust().Status())
+	if string(data) != `{"minimums":{"GYDS":"5000"},"proposals":[]}` {
+		t.Fatalf("unexpected status %s", data)
+	}
+}
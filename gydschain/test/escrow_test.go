@@ -0,0 +1,162 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestSlashAppeal(t *testing.T) {
+	genesis := chain.DefaultGenesis()
+	genesis.Params.MinTransfer = nil
+	genesis.Alloc = []chain.AllocConfig{
+		{Address: "gyds1guardian1", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1guardian2", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1beta", GYDSBalance: big.NewInt(1000), GYDBalance: new(big.Int)},
+	}
+	db := state.NewStateDB()
+	c, err := chain.NewChain(nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	for _, v := range []string{"gyds1alpha", "gyds1beta"} {
+		if err := engine.RegisterValidator(v, v+"_pubkey", big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The beta validator's stake is bonded from its balance
+	beta := db.GetAccount("gyds1beta")
+	beta.Delegate("gyds1beta", big.NewInt(1000))
+	db.SetAccount("gyds1beta", beta)
+	keeper := pos.NewSlashingKeeper(engine, nil)
+	c.SetSlashingKeeper(keeper)
+	c.SetCircuitBreaker(pos.NewCircuitBreaker(engine, []string{"gyds1guardian1", "gyds1guardian2"}, 2))
+
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+	nonces := make(map[string]uint64)
+	// include adds a block with the given transactions
+	include := func(txs ...*tx.Transaction) error {
+		for _, transaction := range txs {
+			transaction.Nonce = nonces[transaction.From]
+			transaction.Fee = big.NewInt(1e9)
+			transaction.Sign([]byte("key"))
+			if err := mempool.AddTx(transaction); err != nil {
+				return err
+			}
+		}
+		block := c.ProposeBlock(mempool, "gyds1validator")
+		if err := c.AddBlock(block); err != nil {
+			for _, transaction := range txs {
+				hash, _ := transaction.HashHex()
+				mempool.RemoveTx(hash)
+			}
+			return err
+		}
+		mempool.Update(block.Header.Height, block.Transactions)
+		for _, transaction := range txs {
+			nonces[transaction.From]++
+		}
+		return nil
+	}
+	vote := func(voter, txType string, payload tx.Payload) *tx.Transaction {
+		transaction := tx.NewTransaction(txType, voter, voter, new(big.Int), "GYDS")
+		if err := transaction.SetPayload(payload); err != nil {
+			t.Fatal(err)
+		}
+		return transaction
+	}
+	stake := func(address string) *big.Int {
+		v, err := engine.GetValidator(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.TotalStake
+	}
+
+	// Validators and guardians shorten the appeal window to three blocks
+	for _, guardian := range []string{"gyds1guardian1", "gyds1guardian2"} {
+		if err := include(vote(guardian, tx.TxTypeAppealWindow, &tx.AppealWindowPayload{Blocks: 3})); err != nil {
+			t.Fatalf("window vote: %v", err)
+		}
+	}
+	if window := keeper.GetParams().AppealWindow; window != 3 {
+		t.Fatalf("expected an appeal window of 3 blocks, got %d", window)
+	}
+
+	// A slash reversed by quorum within its window returns the stake and
+	// lifts the jail and tombstone
+	height := c.Height()
+	if err := keeper.HandleDoubleSign("gyds1alpha", height, height); err != nil {
+		t.Fatal(err)
+	}
+	escrow := keeper.GetPendingEscrows()[0]
+	if escrow.ReleaseHeight != height+3 {
+		t.Errorf("expected release at height %d, got %d", height+3, escrow.ReleaseHeight)
+	}
+	if got := stake("gyds1alpha"); got.Cmp(big.NewInt(950)) != 0 {
+		t.Fatalf("expected stake 950 after the slash, got %s", got)
+	}
+	appeal := &tx.SlashAppealPayload{EscrowID: escrow.ID}
+	if err := include(vote("gyds1guardian1", tx.TxTypeSlashAppeal, appeal)); err != nil {
+		t.Fatalf("first appeal: %v", err)
+	}
+	if pending, _ := keeper.GetEscrow(escrow.ID); pending.Status != pos.EscrowPending {
+		t.Fatalf("expected one appeal to leave the slash pending, got %s", pending.Status)
+	}
+	if err := include(vote("gyds1guardian2", tx.TxTypeSlashAppeal, appeal)); err != nil {
+		t.Fatalf("second appeal: %v", err)
+	}
+	if reversed, _ := keeper.GetEscrow(escrow.ID); reversed.Status != pos.EscrowReversed {
+		t.Errorf("expected the slash reversed, got %s", reversed.Status)
+	}
+	if got := stake("gyds1alpha"); got.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected stake 1000 restored, got %s", got)
+	}
+	if keeper.IsTombstoned("gyds1alpha") || !engine.IsActive("gyds1alpha") {
+		t.Error("expected the validator unjailed and un-tombstoned")
+	}
+
+	// A slash nobody appeals is settled by the block its window ends at
+	height = c.Height()
+	if err := keeper.HandleDoubleSign("gyds1beta", height, height); err != nil {
+		t.Fatal(err)
+	}
+	escrow = keeper.GetPendingEscrows()[0]
+	if err := keeper.ReverseSlash(escrow.ID, height+3); err != pos.ErrAppealWindowClosed {
+		t.Errorf("expected ErrAppealWindowClosed, got %v", err)
+	}
+	for c.Height() < height+2 {
+		if err := include(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pending, _ := keeper.GetEscrow(escrow.ID); pending.Status != pos.EscrowPending {
+		t.Fatalf("expected the slash pending before its release height, got %s", pending.Status)
+	}
+	if err := include(); err != nil {
+		t.Fatal(err)
+	}
+	if settled, _ := keeper.GetEscrow(escrow.ID); settled.Status != pos.EscrowSettled {
+		t.Errorf("expected the slash settled at its release height, got %s", settled.Status)
+	}
+	if bonded := db.GetAccount("gyds1beta").GetDelegation("gyds1beta"); bonded.Cmp(big.NewInt(950)) != 0 {
+		t.Errorf("expected the settled slash burned from the bonded stake, got %s", bonded)
+	}
+	appeal = &tx.SlashAppealPayload{EscrowID: escrow.ID}
+	if err := include(vote("gyds1guardian1", tx.TxTypeSlashAppeal, appeal)); err != pos.ErrEscrowClosed {
+		t.Errorf("expected ErrEscrowClosed, got %v", err)
+	}
+	if got := stake("gyds1beta"); got.Cmp(big.NewInt(950)) != 0 {
+		t.Errorf("expected the settled slash to stand, got stake %s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestDoubleSignEvidence(t *testing.T) {
+	key, err := crypto.NewKeyPair()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	validator := key.Address()
+
+	c, err := chain.NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(chain.DefaultGenesis()); err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	if err := engine.RegisterValidator(validator, key.PublicKeyHex(), big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	keeper := pos.NewSlashingKeeper(engine, nil)
+	pool := chain.NewEvidencePool(engine, keeper)
+	c.SetEvidencePool(pool)
+
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+	propose := func() *chain.Block {
+		block := c.ProposeBlock(mempool, validator)
+		if err := block.Sign(key); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return block
+	}
+
+	block := propose()
+	if err := c.AddBlock(block); err != nil {
+		t.Fatalf("block 1: %v", err)
+	}
+
+	// The validator signs a second header at height 1
+	competing := *block.Header
+	competing.Timestamp++
+	digest, _ := competing.Hash()
+	signature := signHex(t, key, digest)
+	pool.Observe(&competing, signature, validator)
+	if pending := pool.Pending(chain.MaxBlockEvidence); len(pending) != 1 {
+		t.Fatalf("expected one piece of evidence, got %d", len(pending))
+	}
+
+	// A forged signature is not evidence
+	forged, _ := chain.NewEvidence(validator, block.Header, block.Signature, &competing, block.Signature)
+	if _, err := pool.Add(forged); err != chain.ErrInvalidEvidence {
+		t.Errorf("expected forged evidence to be rejected, got %v", err)
+	}
+
+	// The next block includes the evidence and slashes the validator
+	next := propose()
+	if len(next.Evidence) != 1 {
+		t.Fatalf("expected the proposal to include evidence, got %d", len(next.Evidence))
+	}
+	if err := c.AddBlock(next); err != nil {
+		t.Fatalf("block 2: %v", err)
+	}
+	if !keeper.IsTombstoned(validator) {
+		t.Error("expected the double signer to be tombstoned")
+	}
+	if v, _ := engine.GetValidator(validator); v.Active {
+		t.Error("expected the double signer to be jailed")
+	}
+	if pending := pool.Pending(chain.MaxBlockEvidence); len(pending) != 0 {
+		t.Errorf("expected committed evidence to leave the pool, got %d", len(pending))
+	}
+
+	// Committed evidence cannot be included again
+	again := c.ProposeBlock(mempool, validator)
+	again.Evidence = next.Evidence
+	again.Header.EvidenceRoot = again.CalculateEvidenceRoot()
+	if err := c.AddBlock(again); err != chain.ErrEvidenceCommitted {
+		t.Errorf("expected ErrEvidenceCommitted, got %v", err)
+	}
+}
+
+// signHex signs a hex digest the way blocks sign their hash
+func signHex(t *testing.T, key *crypto.KeyPair, digest string) []byte {
+	raw, err := hex.DecodeString(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := key.Sign(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signature
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestExperimentalTxTypesGated(t *testing.T) {
+	features := tx.NewFeatures(tx.FeatureDEX)
+
+	for txType, want := range map[string]error{
+		tx.TxTypeTransfer: nil,
+		tx.TxTypeHaltVote: nil,
+		"dex_place_order": nil,
+		"governance_vote": tx.ErrFeatureDisabled,
+		"wasm_deploy":     tx.ErrFeatureDisabled,
+		"dexterity_thing": nil,
+	} {
+		if err := features.Check(&tx.Transaction{Type: txType}); err != want {
+			t.Errorf("%s: got %v, want %v", txType, err, want)
+		}
+	}
+
+	var none tx.Features
+	if err := none.Check(&tx.Transaction{Type: "dex_place_order"}); err != tx.ErrFeatureDisabled {
+		t.Errorf("zero features admitted an experimental type: %v", err)
+	}
+}
+
+func TestExperimentalNamespacesHidden(t *testing.T) {
+	methods := rpc.NewMethods()
+	methods.Register("wasm_ping", func(params json.RawMessage) (interface{}, error) {
+		return "pong", nil
+	})
+
+	if _, err := methods.Call("wasm_ping", nil); err == nil {
+		t.Error("disabled namespace served")
+	}
+	for _, name := range methods.List() {
+		if name == "wasm_ping" {
+			t.Error("disabled namespace listed")
+		}
+	}
+
+	methods.SetFeatures(tx.NewFeatures(tx.FeatureWASM))
+	if result, err := methods.Call("wasm_ping", nil); err != nil || result != "pong" {
+		t.Errorf("enabled namespace: got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+func TestHDDerivation(t *testing.T) {
+	// SLIP-0010 ed25519 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	vectors := map[string]string{
+		"m":          "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"m/0'":       "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"m/0'/1'/2'": "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+	}
+	for path, want := range vectors {
+		parsed, err := crypto.ParsePath(path)
+		if err != nil {
+			t.Fatalf("parse %s: %v", path, err)
+		}
+		kp, err := crypto.DeriveKeyPair(seed, parsed)
+		if err != nil {
+			t.Fatalf("derive %s: %v", path, err)
+		}
+		if got := hex.EncodeToString(kp.Seed()); got != want {
+			t.Errorf("%s: key %s, want %s", path, got, want)
+		}
+	}
+
+	if _, err := crypto.ParsePath("44'/0'"); err != crypto.ErrInvalidPath {
+		t.Fatalf("path without m: got %v", err)
+	}
+	if got := crypto.AccountPath(1, 3).String(); got != "m/44'/8888'/1'/0'/3'" {
+		t.Fatalf("account path = %s", got)
+	}
+
+	// Accounts are distinct, reproducible and leave the root address alone
+	root, err := crypto.NewWalletFromMnemonic("main", "test mnemonic", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := crypto.DeriveAddress(root.KeyPair.PublicKey)
+	first, _ := root.DeriveAccount(0)
+	second, _ := root.DeriveAccount(1)
+	again, _ := root.DeriveAccount(1)
+	if first.Address() == second.Address() || first.Address() == legacy {
+		t.Fatal("derived accounts are not distinct")
+	}
+	if second.Address() != again.Address() || root.Address() != legacy {
+		t.Fatal("derivation is not deterministic")
+	}
+
+	bare, _ := crypto.NewWallet("bare")
+	if _, err := bare.DeriveAccount(0); err != crypto.ErrMissingSeed {
+		t.Fatalf("derive without seed: got %v", err)
+	}
+}
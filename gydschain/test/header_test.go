@@ -0,0 +1,124 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestBlockSignatureVerifiesHeader(t *testing.T) {
+	key, err := crypto.NewKeyPair()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	other, _ := crypto.NewKeyPair()
+
+	block := chain.NewBlock("parent", 1, nil, key.Address())
+	if err := block.Sign(key); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := chain.VerifyHeaderSignature(block.Header, block.Signature, key.PublicKey); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if err := chain.VerifyHeaderSignature(block.Header, block.Signature, other.PublicKey); err != chain.ErrInvalidBlockSignature {
+		t.Errorf("wrong key: got %v", err)
+	}
+	block.Header.StateRoot = "forged"
+	if err := chain.VerifyHeaderSignature(block.Header, block.Signature, key.PublicKey); err != chain.ErrInvalidBlockSignature {
+		t.Errorf("modified header: got %v", err)
+	}
+}
+
+func TestBlockProposerMustLeadRound(t *testing.T) {
+	c, err := chain.NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(chain.DefaultGenesis()); err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	keys := make(map[string]*crypto.KeyPair)
+	for _, stake := range []int64{1000, 2000} {
+		key, err := crypto.NewKeyPair()
+		if err != nil {
+			t.Fatalf("key: %v", err)
+		}
+		if err := engine.RegisterValidator(key.Address(), key.PublicKeyHex(), big.NewInt(stake)); err != nil {
+			t.Fatal(err)
+		}
+		keys[key.Address()] = key
+	}
+	c.SetProposerSchedule(engine)
+
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+
+	// Blocks are stamped inside the slot of the round they are proposed in
+	propose := func(round uint64, proposer string, signer *crypto.KeyPair) *chain.Block {
+		block := c.ProposeBlock(mempool, proposer)
+		block.Header.Round = round
+		block.Header.Timestamp = int64(round) * 5
+		if signer != nil {
+			if err := block.Sign(signer); err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+		}
+		return block
+	}
+	leaderOf := func(round uint64) (string, string) {
+		leader, err := engine.LeaderAt(round)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for addr := range keys {
+			if addr != leader {
+				return leader, addr
+			}
+		}
+		return leader, ""
+	}
+
+	round := uint64(time.Now().Unix()-60) / 5
+	leader, other := leaderOf(round)
+
+	if err := c.AddBlock(propose(round, leader, nil)); err != chain.ErrUnsignedBlock {
+		t.Errorf("unsigned block: expected ErrUnsignedBlock, got %v", err)
+	}
+	if err := c.AddBlock(propose(round, leader, keys[other])); err != chain.ErrInvalidBlockSignature {
+		t.Errorf("wrong signer: expected ErrInvalidBlockSignature, got %v", err)
+	}
+	if err := c.AddBlock(propose(round, other, keys[other])); err != chain.ErrNotLeader {
+		t.Errorf("non-leader: expected ErrNotLeader, got %v", err)
+	}
+	mismatched := propose(round, leader, nil)
+	mismatched.Header.Timestamp += 60
+	if err := mismatched.Sign(keys[leader]); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddBlock(mismatched); err != chain.ErrInvalidRound {
+		t.Errorf("timestamp outside round: expected ErrInvalidRound, got %v", err)
+	}
+	if err := c.AddBlock(propose(round, leader, keys[leader])); err != nil {
+		t.Fatalf("leader's block: %v", err)
+	}
+	if err := c.AddBlock(propose(round, leader, keys[leader])); err != chain.ErrInvalidRound {
+		t.Errorf("repeated round: expected ErrInvalidRound, got %v", err)
+	}
+
+	// A jailed validator may not propose, even for a round it led before
+	keeper := pos.NewSlashingKeeper(engine, nil)
+	if err := keeper.HandleDoubleSign(leader, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddBlock(propose(round+1, leader, keys[leader])); err != chain.ErrInactiveProposer {
+		t.Errorf("jailed proposer: expected ErrInactiveProposer, got %v", err)
+	}
+}
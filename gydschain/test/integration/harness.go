@@ -0,0 +1,467 @@
+//go:build integration
+
+// Package integration boots a dev node, the indexer (on SQLite) and the
+// mining pool in-process and drives them end to end. Run with:
+//
+//	go test -tags=integration ./test/integration/...
+package integration
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/gydschain/gydschain/indexer/api"
+	"github.com/gydschain/gydschain/indexer/service"
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/miner"
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+// waitTimeout bounds every poll for a component to catch up
+const waitTimeout = 10 * time.Second
+
+//go:embed testdata/schema_sqlite.sql
+var sqliteSchema string
+
+// sqliteDriver is go-sqlite3 with the Postgres functions the indexer's
+// queries use
+const sqliteDriver = "sqlite3_gyds"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("now", func() string {
+				return time.Now().UTC().Format("2006-01-02 15:04:05")
+			}, false)
+		},
+	})
+}
+
+// DevAccount is a key on the dev chain
+type DevAccount struct {
+	Address string
+	Key     *crypto.KeyPair
+}
+
+// NewDevAccount generates a fresh account
+func NewDevAccount(t *testing.T) *DevAccount {
+	t.Helper()
+	key, err := crypto.NewKeyPair()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return &DevAccount{Address: crypto.DeriveAddress(key.PublicKey), Key: key}
+}
+
+// DevNode is a single-validator node whose blocks are produced on demand
+type DevNode struct {
+	State     *state.StateDB
+	Chain     *chain.Chain
+	Mempool   *tx.Mempool
+	Server    *rpc.Server
+	Client    *rpc.NodeClient
+	URL       string
+	Validator *DevAccount
+}
+
+// StartDevNode boots a node with alloc as its genesis balances and serves
+// its RPC on a loopback port until the test ends
+func StartDevNode(t *testing.T, alloc []chain.AllocConfig) *DevNode {
+	t.Helper()
+
+	stateDB := state.NewStateDB()
+	blockchain, err := chain.NewChain(chain.DefaultConfig(), stateDB)
+	if err != nil {
+		t.Fatalf("create chain: %v", err)
+	}
+	genesis := &chain.GenesisConfig{
+		ChainID:   "gydschain-dev",
+		Timestamp: time.Now().Unix(),
+		Alloc:     alloc,
+	}
+	if err := blockchain.InitGenesis(genesis); err != nil {
+		t.Fatalf("init genesis: %v", err)
+	}
+
+	mempool := tx.NewMempool(nil)
+	t.Cleanup(mempool.Stop)
+	blockchain.OnBlock(func(block *chain.Block, hash string, logs []*chain.IndexedLog) {
+		mempool.Update(block.Header.Height, block.Transactions)
+	})
+
+	addr := freeAddr(t)
+	server := rpc.NewServer(addr)
+	server.SetBackend(&rpc.Backend{
+		Chain:   blockchain,
+		State:   stateDB,
+		Mempool: mempool,
+	})
+	go server.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	})
+
+	url := "http://" + addr
+	waitHTTP(t, url+"/health")
+
+	return &DevNode{
+		State:     stateDB,
+		Chain:     blockchain,
+		Mempool:   mempool,
+		Server:    server,
+		Client:    rpc.NewNodeClient(url + "/"),
+		URL:       url,
+		Validator: NewDevAccount(t),
+	}
+}
+
+// Transfer signs a GYDS transfer paying twice the current base fee
+func (n *DevNode) Transfer(from *DevAccount, to string, amount, nonce uint64) *tx.Transaction {
+	t := tx.NewTransfer(from.Address, to, new(big.Int).SetUint64(amount), "GYDS")
+	t.SetNonce(nonce)
+	gas := n.Chain.Gas()
+	t.SetFee(tx.FeeForGas(gas.TxGas(t), gas.BaseFee()*2))
+	t.Sign(from.Key.PrivateKey)
+	return t
+}
+
+// Submit sends t through the node's RPC and returns its hash
+func (n *DevNode) Submit(t *testing.T, transaction *tx.Transaction) string {
+	t.Helper()
+	hash, err := n.Client.SendTransaction(transaction)
+	if err != nil {
+		t.Fatalf("send transaction: %v", err)
+	}
+	return hash
+}
+
+// ProduceBlock proposes a block from the mempool and applies it
+func (n *DevNode) ProduceBlock(t *testing.T) *chain.Block {
+	t.Helper()
+	block := n.Chain.ProposeBlock(n.Mempool, n.Validator.Address)
+	if err := n.Chain.AddBlock(block); err != nil {
+		t.Fatalf("add block %d: %v", block.Header.Height, err)
+	}
+	return block
+}
+
+// IndexerHarness runs the indexer and its explorer API against a DevNode
+type IndexerHarness struct {
+	DB      *sql.DB
+	Indexer *service.Indexer
+	API     *api.Server
+	URL     string
+}
+
+// StartIndexer indexes node into a fresh SQLite database and serves the
+// explorer API on a loopback port until the test ends
+func StartIndexer(t *testing.T, node *DevNode) *IndexerHarness {
+	t.Helper()
+
+	db, err := sql.Open(sqliteDriver, filepath.Join(t.TempDir(), "indexer.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1) // SQLite allows one writer
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	config := service.DefaultIndexerConfig()
+	config.PollInterval = 50 * time.Millisecond
+	config.ConfirmBlocks = 0
+	config.EpochLength = 0 // the dev node tracks no epochs
+	indexer := service.NewIndexer(db, service.NewNodePool(node.Client), config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := indexer.Start(ctx); err != nil {
+		cancel()
+		t.Fatalf("start indexer: %v", err)
+	}
+	t.Cleanup(func() {
+		indexer.Stop()
+		cancel()
+	})
+
+	addr := freeAddr(t)
+	server := api.NewServer(addr, db, indexer)
+	server.SetAnonymousLimits(service.Tier{Name: service.TierAnonymous}) // tests poll faster than the public limit
+	go server.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	})
+
+	url := "http://" + addr
+	waitHTTP(t, url+"/health")
+
+	return &IndexerHarness{DB: db, Indexer: indexer, API: server, URL: url}
+}
+
+// WaitForBlock blocks until the explorer reports height as indexed
+func (h *IndexerHarness) WaitForBlock(t *testing.T, height uint64) {
+	t.Helper()
+	waitFor(t, fmt.Sprintf("indexer to reach block %d", height), func() bool {
+		var status struct {
+			LastIndexedBlock uint64 `json:"last_indexed_block"`
+		}
+		return getJSON(h.URL+"/status", &status) == http.StatusOK && status.LastIndexedBlock >= height
+	})
+}
+
+// Get fetches an explorer API path into out, failing unless it returns 200
+func (h *IndexerHarness) Get(t *testing.T, path string, out interface{}) {
+	t.Helper()
+	if code := getJSON(h.URL+path, out); code != http.StatusOK {
+		t.Fatalf("GET %s: status %d", path, code)
+	}
+}
+
+// PoolHarness runs the mining pool on a loopback port
+type PoolHarness struct {
+	Pool *miner.Pool
+	URL  string
+}
+
+// StartPool starts a pool with difficulty 1 until the test ends
+func StartPool(t *testing.T) *PoolHarness {
+	t.Helper()
+
+	addr := freeAddr(t)
+	pool := miner.NewPool(addr, miner.PoolConfig{
+		MinDifficulty:   1,
+		MaxDifficulty:   1,
+		VarDiffTarget:   20,
+		VarDiffRetarget: 60,
+		PayoutThreshold: "0",
+		BlockReward:     "0",
+	})
+	go pool.Start()
+	t.Cleanup(pool.Stop)
+
+	url := "http://" + addr
+	waitHTTP(t, url+"/stats")
+	return &PoolHarness{Pool: pool, URL: url}
+}
+
+// BroadcastTip hands connected miners a job on top of node's latest block
+func (p *PoolHarness) BroadcastTip(t *testing.T, node *DevNode) *miner.Job {
+	t.Helper()
+
+	tip, err := node.Chain.LatestBlock()
+	if err != nil {
+		t.Fatalf("latest block: %v", err)
+	}
+	tipHash, err := tip.Hash()
+	if err != nil {
+		t.Fatalf("hash tip: %v", err)
+	}
+	prevHash, _ := hex.DecodeString(tipHash)
+
+	template := miner.NewBlockTemplate(
+		tip.Header.Height+1,
+		prevHash,
+		[]byte(tip.Header.StateRoot),
+		nil,
+		1,
+		[]byte(node.Validator.Address),
+	)
+	job := miner.NewJobManager(nil).CreateJob(template)
+	p.Pool.BroadcastJob(job)
+	return job
+}
+
+// Stats returns the pool's /stats
+func (p *PoolHarness) Stats(t *testing.T) miner.PoolStats {
+	t.Helper()
+	var stats miner.PoolStats
+	if code := getJSON(p.URL+"/stats", &stats); code != http.StatusOK {
+		t.Fatalf("GET /stats: status %d", code)
+	}
+	return stats
+}
+
+// FakeMiner speaks just enough Stratum over WebSocket to take jobs and
+// submit shares
+type FakeMiner struct {
+	conn    *websocket.Conn
+	mu      sync.Mutex
+	nextID  int
+	replies map[int]chan json.RawMessage
+	jobs    chan string // IDs from mining.notify
+}
+
+// DialMiner connects to pool, subscribes and authorizes as address
+func DialMiner(t *testing.T, pool *PoolHarness, address string) *FakeMiner {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+pool.URL[len("http"):]+"/", nil)
+	if err != nil {
+		t.Fatalf("dial pool: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m := &FakeMiner{
+		conn:    conn,
+		replies: make(map[int]chan json.RawMessage),
+		jobs:    make(chan string, 16),
+	}
+	go m.read()
+
+	m.call(t, "mining.subscribe", "fake-miner/1.0")
+	m.call(t, "mining.authorize", address, "x")
+	return m
+}
+
+// read routes replies to their callers and queues job notifications
+func (m *FakeMiner) read() {
+	for {
+		var msg struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params []interface{}   `json:"params"`
+			Result json.RawMessage `json:"result"`
+		}
+		if err := m.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		if msg.Method == "mining.notify" && len(msg.Params) > 0 {
+			if id, ok := msg.Params[0].(string); ok {
+				m.jobs <- id
+			}
+			continue
+		}
+		if msg.ID != nil {
+			m.mu.Lock()
+			reply := m.replies[*msg.ID]
+			delete(m.replies, *msg.ID)
+			m.mu.Unlock()
+			if reply != nil {
+				reply <- msg.Result
+			}
+		}
+	}
+}
+
+// call sends a Stratum request and waits for its result
+func (m *FakeMiner) call(t *testing.T, method string, params ...interface{}) json.RawMessage {
+	t.Helper()
+
+	m.mu.Lock()
+	m.nextID++
+	id := m.nextID
+	reply := make(chan json.RawMessage, 1)
+	m.replies[id] = reply
+	err := m.conn.WriteJSON(map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": params,
+	})
+	m.mu.Unlock()
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+
+	select {
+	case result := <-reply:
+		return result
+	case <-time.After(waitTimeout):
+		t.Fatalf("%s: no reply", method)
+		return nil
+	}
+}
+
+// WaitJob returns the next job the pool pushes
+func (m *FakeMiner) WaitJob(t *testing.T) string {
+	t.Helper()
+	select {
+	case id := <-m.jobs:
+		return id
+	case <-time.After(waitTimeout):
+		t.Fatal("no job from pool")
+		return ""
+	}
+}
+
+// Submit submits a share for jobID
+func (m *FakeMiner) Submit(t *testing.T, jobID, nonce string) {
+	t.Helper()
+	var accepted bool
+	if err := json.Unmarshal(m.call(t, "mining.submit", "fake-miner", jobID, nonce), &accepted); err != nil || !accepted {
+		t.Fatalf("share for job %s not accepted", jobID)
+	}
+}
+
+// freeAddr reserves a loopback port for a server that listens by address
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// waitHTTP waits until url answers
+func waitHTTP(t *testing.T, url string) {
+	t.Helper()
+	waitFor(t, url, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return true
+	})
+}
+
+// waitFor polls cond until it holds or waitTimeout passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// getJSON decodes a GET response into out and returns its status code,
+// or 0 if the request failed
+func getJSON(url string, out interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return 0
+		}
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,124 @@
+//go:build integration
+
+package integration
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/gydschain/gydschain/indexer/service"
+	"github.com/gydschain/gydschain/internal/chain"
+)
+
+func TestEndToEnd(t *testing.T) {
+	alice, bob := NewDevAccount(t), NewDevAccount(t)
+	node := StartDevNode(t, []chain.AllocConfig{
+		{Address: alice.Address, GYDSBalance: big.NewInt(1000000000000)},
+	})
+	indexer := StartIndexer(t, node)
+	pool := StartPool(t)
+	worker := DialMiner(t, pool, node.Validator.Address)
+
+	// Three transfers from alice land in one block, in nonce order
+	amounts := []uint64{1000, 2000, 3000}
+	hashes := make([]string, len(amounts))
+	for i, amount := range amounts {
+		hashes[i] = node.Submit(t, node.Transfer(alice, bob.Address, amount, uint64(i)))
+	}
+	block := node.ProduceBlock(t)
+	if len(block.Transactions) != len(amounts) {
+		t.Fatalf("block %d has %d txs, want %d", block.Header.Height, len(block.Transactions), len(amounts))
+	}
+	for i, txn := range block.Transactions {
+		if txn.Nonce != uint64(i) {
+			t.Fatalf("tx %d has nonce %d", i, txn.Nonce)
+		}
+	}
+	if node.Mempool.Size() != 0 {
+		t.Fatalf("mempool still holds %d txs", node.Mempool.Size())
+	}
+
+	// An empty block on top, so the indexer has to walk past the transfers
+	node.ProduceBlock(t)
+	height := node.Chain.Height()
+
+	// The pool hands the miner work on the new tip and accepts its share
+	job := pool.BroadcastTip(t, node)
+	if got := worker.WaitJob(t); got != job.ID {
+		t.Fatalf("miner got job %s, want %s", got, job.ID)
+	}
+	worker.Submit(t, job.ID, "00000001")
+	waitFor(t, "pool to count the share", func() bool {
+		return pool.Stats(t).SharesValid == 1
+	})
+	if miners := pool.Stats(t).TotalMiners; miners != 1 {
+		t.Fatalf("pool reports %d miners, want 1", miners)
+	}
+
+	// The explorer serves what the node produced
+	indexer.WaitForBlock(t, height)
+
+	var blocks []map[string]interface{}
+	indexer.Get(t, "/blocks?limit=10", &blocks)
+	if len(blocks) < 2 || uint64(blocks[0]["number"].(float64)) != height {
+		t.Fatalf("explorer blocks = %v, want tip %d first", blocks, height)
+	}
+
+	for i, hash := range hashes {
+		var txn service.IndexedTransaction
+		indexer.Get(t, "/transactions/"+hash, &txn)
+		if txn.From != alice.Address || txn.To == nil || *txn.To != bob.Address {
+			t.Fatalf("tx %s indexed as %s -> %v", hash, txn.From, txn.To)
+		}
+		if txn.BlockNumber != block.Header.Height || txn.Nonce != uint64(i) {
+			t.Fatalf("tx %s indexed at block %d nonce %d", hash, txn.BlockNumber, txn.Nonce)
+		}
+		if txn.Value != strconv.FormatUint(amounts[i], 10) {
+			t.Fatalf("tx %s indexed value %s, want %d", hash, txn.Value, amounts[i])
+		}
+	}
+
+	var account service.Account
+	indexer.Get(t, "/accounts/"+bob.Address, &account)
+	if account.TxCount != uint64(len(amounts)) {
+		t.Fatalf("bob has %d indexed txs, want %d", account.TxCount, len(amounts))
+	}
+	if balance := node.State.GetAccount(bob.Address).GetBalance("GYDS"); account.Balances["GYDS"] != balance.String() {
+		t.Fatalf("explorer balance %s, node balance %s", account.Balances["GYDS"], balance)
+	}
+
+	var history []service.TransactionRecord
+	indexer.Get(t, "/accounts/"+alice.Address+"/transactions", &history)
+	if len(history) != len(amounts) {
+		t.Fatalf("alice history has %d txs, want %d", len(history), len(amounts))
+	}
+}
+
+func TestReplacedTransactionIsNotIndexed(t *testing.T) {
+	alice, bob := NewDevAccount(t), NewDevAccount(t)
+	node := StartDevNode(t, []chain.AllocConfig{
+		{Address: alice.Address, GYDSBalance: big.NewInt(1000000000000)},
+	})
+	indexer := StartIndexer(t, node)
+
+	// A fee bump replaces the pending tx with the same nonce
+	original := node.Transfer(alice, bob.Address, 500, 0)
+	originalHash := node.Submit(t, original)
+	bumped := node.Transfer(alice, bob.Address, 500, 0)
+	bumped.SetFee(new(big.Int).Mul(original.Fee, big.NewInt(2)))
+	bumped.Sign(alice.Key.PrivateKey)
+	bumpedHash := node.Submit(t, bumped)
+
+	block := node.ProduceBlock(t)
+	if len(block.Transactions) != 1 {
+		t.Fatalf("block has %d txs, want 1", len(block.Transactions))
+	}
+	indexer.WaitForBlock(t, block.Header.Height)
+
+	var txn service.IndexedTransaction
+	indexer.Get(t, "/transactions/"+bumpedHash, &txn)
+	if code := getJSON(indexer.URL+"/transactions/"+originalHash, nil); code != 404 {
+		t.Fatalf("replaced tx lookup returned %d, want 404", code)
+	}
+}
@@ -0,0 +1,357 @@
+-- GYDS Chain Indexer Database Schema
+-- SQLite translation of indexer/db/schema.sql for the integration harness.
+-- Keep the two in step; views are omitted and inline indexes become
+-- CREATE INDEX statements.
+
+-- Blocks table
+CREATE TABLE IF NOT EXISTS blocks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    number BIGINT NOT NULL UNIQUE,
+    hash VARCHAR(66) NOT NULL UNIQUE,
+    parent_hash VARCHAR(66) NOT NULL,
+    state_root VARCHAR(66) NOT NULL,
+    transactions_root VARCHAR(66) NOT NULL,
+    receipts_root VARCHAR(66) NOT NULL,
+    validator VARCHAR(42) NOT NULL,
+    timestamp BIGINT NOT NULL,
+    gas_used BIGINT NOT NULL DEFAULT 0,
+    gas_limit BIGINT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    tx_count INT NOT NULL DEFAULT 0,
+    extra_data BLOB,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_blocks_timestamp ON blocks (timestamp);
+CREATE INDEX IF NOT EXISTS idx_blocks_validator ON blocks (validator);
+
+-- Transactions table
+CREATE TABLE IF NOT EXISTS transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    hash VARCHAR(66) NOT NULL UNIQUE,
+    block_number BIGINT NOT NULL REFERENCES blocks(number),
+    block_hash VARCHAR(66) NOT NULL,
+    tx_index INT NOT NULL,
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42),
+    value VARCHAR(78) NOT NULL,
+    asset VARCHAR(42) NOT NULL DEFAULT 'GYDS',
+    fee VARCHAR(78) NOT NULL,
+    nonce BIGINT NOT NULL,
+    data BLOB,
+    payload TEXT, -- decoded typed payload, NULL for types without one
+    signature VARCHAR(130) NOT NULL,
+    tx_type VARCHAR(20) NOT NULL DEFAULT 'transfer',
+    status SMALLINT NOT NULL DEFAULT 1,
+    gas_used BIGINT NOT NULL DEFAULT 0,
+    logs TEXT, -- receipt logs, NULL when the node had no receipt
+    submitted_at BIGINT, -- sender's tx timestamp (unix seconds)
+    inclusion_latency_secs BIGINT, -- block timestamp minus submitted_at
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_tx_from ON transactions (from_address);
+CREATE INDEX IF NOT EXISTS idx_tx_to ON transactions (to_address);
+CREATE INDEX IF NOT EXISTS idx_tx_block ON transactions (block_number);
+CREATE INDEX IF NOT EXISTS idx_tx_asset ON transactions (asset);
+CREATE INDEX IF NOT EXISTS idx_tx_type ON transactions (tx_type);
+
+-- Accounts table
+CREATE TABLE IF NOT EXISTS accounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address VARCHAR(42) NOT NULL UNIQUE,
+    nonce BIGINT NOT NULL DEFAULT 0,
+    tx_count BIGINT NOT NULL DEFAULT 0,
+    first_seen_block BIGINT NOT NULL,
+    last_seen_block BIGINT NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_last_seen ON accounts (last_seen_block);
+
+-- Account balances table
+CREATE TABLE IF NOT EXISTS account_balances (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address VARCHAR(42) NOT NULL,
+    asset VARCHAR(42) NOT NULL,
+    balance VARCHAR(78) NOT NULL DEFAULT '0',
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    
+    UNIQUE(address, asset)
+);
+CREATE INDEX IF NOT EXISTS idx_balances_address ON account_balances (address);
+CREATE INDEX IF NOT EXISTS idx_balances_asset ON account_balances (asset);
+
+-- Assets table
+CREATE TABLE IF NOT EXISTS assets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    asset_id VARCHAR(42) NOT NULL UNIQUE,
+    symbol VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    decimals SMALLINT NOT NULL DEFAULT 18,
+    total_supply VARCHAR(78) NOT NULL,
+    max_supply VARCHAR(78),
+    creator VARCHAR(42) NOT NULL,
+    is_native BOOLEAN NOT NULL DEFAULT FALSE,
+    is_stablecoin BOOLEAN NOT NULL DEFAULT FALSE,
+    peg_target VARCHAR(10),
+    mintable BOOLEAN NOT NULL DEFAULT FALSE,
+    burnable BOOLEAN NOT NULL DEFAULT FALSE,
+    created_block BIGINT NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_assets_symbol ON assets (symbol);
+CREATE INDEX IF NOT EXISTS idx_assets_creator ON assets (creator);
+
+-- Validators table
+CREATE TABLE IF NOT EXISTS validators (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address VARCHAR(42) NOT NULL UNIQUE,
+    moniker VARCHAR(64),
+    stake VARCHAR(78) NOT NULL,
+    commission SMALLINT NOT NULL DEFAULT 0, -- basis points
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    jailed BOOLEAN NOT NULL DEFAULT FALSE,
+    jailed_until BIGINT,
+    blocks_proposed BIGINT NOT NULL DEFAULT 0,
+    blocks_signed BIGINT NOT NULL DEFAULT 0,
+    blocks_missed BIGINT NOT NULL DEFAULT 0, -- summed from epoch summaries
+    slashing_events INT NOT NULL DEFAULT 0,
+    delegator_count INT NOT NULL DEFAULT 0,
+    total_delegations VARCHAR(78) NOT NULL DEFAULT '0',
+    created_block BIGINT NOT NULL,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_validators_active ON validators (active);
+CREATE INDEX IF NOT EXISTS idx_validators_stake ON validators (stake);
+
+-- Delegations table
+CREATE TABLE IF NOT EXISTS delegations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delegator VARCHAR(42) NOT NULL,
+    validator VARCHAR(42) NOT NULL REFERENCES validators(address),
+    amount VARCHAR(78) NOT NULL,
+    rewards VARCHAR(78) NOT NULL DEFAULT '0',
+    created_block BIGINT NOT NULL,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    
+    UNIQUE(delegator, validator)
+);
+CREATE INDEX IF NOT EXISTS idx_delegations_delegator ON delegations (delegator);
+CREATE INDEX IF NOT EXISTS idx_delegations_validator ON delegations (validator);
+
+-- Slashing events table
+CREATE TABLE IF NOT EXISTS slashing_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    validator VARCHAR(42) NOT NULL REFERENCES validators(address),
+    block_number BIGINT NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    jailed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_slashing_validator ON slashing_events (validator);
+CREATE INDEX IF NOT EXISTS idx_slashing_block ON slashing_events (block_number);
+
+-- Token transfers table (for detailed transfer history)
+CREATE TABLE IF NOT EXISTS token_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tx_hash VARCHAR(66) NOT NULL REFERENCES transactions(hash),
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    asset VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    block_number BIGINT NOT NULL,
+    log_index INT NOT NULL DEFAULT 0,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_transfers_from ON token_transfers (from_address);
+CREATE INDEX IF NOT EXISTS idx_transfers_to ON token_transfers (to_address);
+CREATE INDEX IF NOT EXISTS idx_transfers_asset ON token_transfers (asset);
+CREATE INDEX IF NOT EXISTS idx_transfers_block ON token_transfers (block_number);
+
+-- Epoch summaries table
+CREATE TABLE IF NOT EXISTS epochs (
+    epoch BIGINT PRIMARY KEY,
+    start_height BIGINT NOT NULL,
+    end_height BIGINT NOT NULL,
+    total_stake VARCHAR(78) NOT NULL DEFAULT '0',
+    rewards_minted VARCHAR(78) NOT NULL DEFAULT '0',
+    blocks_produced BIGINT NOT NULL DEFAULT 0,
+    blocks_missed BIGINT NOT NULL DEFAULT 0,
+    slashes TEXT NOT NULL DEFAULT '[]',
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Per-validator rows of each epoch summary
+CREATE TABLE IF NOT EXISTS epoch_validators (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    epoch BIGINT NOT NULL REFERENCES epochs(epoch),
+    address VARCHAR(42) NOT NULL,
+    stake VARCHAR(78) NOT NULL DEFAULT '0',
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    blocks_produced BIGINT NOT NULL DEFAULT 0,
+    blocks_missed BIGINT NOT NULL DEFAULT 0,
+    rewards VARCHAR(78) NOT NULL DEFAULT '0',
+    
+    UNIQUE(epoch, address)
+);
+CREATE INDEX IF NOT EXISTS idx_epoch_validators_address ON epoch_validators (address);
+
+-- Burns table (fee burns and explicit burn transactions)
+CREATE TABLE IF NOT EXISTS burns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    block_number BIGINT NOT NULL REFERENCES blocks(number),
+    block_timestamp BIGINT NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    asset VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('fee', 'burn')),
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_burns_asset ON burns (asset);
+CREATE INDEX IF NOT EXISTS idx_burns_block ON burns (block_number);
+
+-- Typed account events decoded from receipt logs, plus system events
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    block_number BIGINT NOT NULL,
+    block_timestamp BIGINT NOT NULL,
+    tx_hash VARCHAR(66),
+    log_index INTEGER,
+    event_type VARCHAR(30) NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    counterparty VARCHAR(42),
+    asset VARCHAR(42),
+    amount VARCHAR(78) NOT NULL DEFAULT '0',
+    data TEXT,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_events_address ON events (address, block_number);
+CREATE INDEX IF NOT EXISTS idx_events_counterparty ON events (counterparty, block_number);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events (event_type);
+CREATE INDEX IF NOT EXISTS idx_events_block ON events (block_number);
+
+-- Mining rewards table
+CREATE TABLE IF NOT EXISTS mining_rewards (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    block_number BIGINT NOT NULL REFERENCES blocks(number),
+    miner VARCHAR(42) NOT NULL,
+    reward VARCHAR(78) NOT NULL,
+    fees VARCHAR(78) NOT NULL DEFAULT '0',
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_rewards_miner ON mining_rewards (miner);
+CREATE INDEX IF NOT EXISTS idx_rewards_block ON mining_rewards (block_number);
+
+-- Stablecoin peg history
+CREATE TABLE IF NOT EXISTS stablecoin_peg_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    asset VARCHAR(42) NOT NULL,
+    block_number BIGINT NOT NULL,
+    price VARCHAR(78) NOT NULL,
+    target VARCHAR(78) NOT NULL,
+    deviation VARCHAR(78) NOT NULL,
+    supply VARCHAR(78) NOT NULL,
+    collateral_ratio VARCHAR(78),
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_peg_asset ON stablecoin_peg_history (asset);
+CREATE INDEX IF NOT EXISTS idx_peg_block ON stablecoin_peg_history (block_number);
+
+-- Address labels table (curated via the admin API)
+CREATE TABLE IF NOT EXISTS address_labels (
+    address VARCHAR(42) PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('exchange', 'foundation', 'treasury', 'bridge', 'other')),
+    description TEXT,
+    updated_by VARCHAR(100),
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_labels_category ON address_labels (category);
+
+-- Blocks the indexer skipped after repeated failures
+CREATE TABLE IF NOT EXISTS dead_letter_blocks (
+    block_number BIGINT PRIMARY KEY,
+    block_hash VARCHAR(66) NOT NULL,
+    attempts INT NOT NULL,
+    error TEXT NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+
+-- API keys for the public explorer API (issued via the admin API); only
+-- a hash of each key is stored. Zero limits fall back to the tier's.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(16) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    tier VARCHAR(20) NOT NULL CHECK (tier IN ('free', 'pro', 'enterprise')),
+    rate_limit DOUBLE PRECISION NOT NULL DEFAULT 0,
+    monthly_quota BIGINT NOT NULL DEFAULT 0,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TEXT
+);
+
+-- Requests made with each API key per calendar month (YYYY-MM)
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id INT NOT NULL REFERENCES api_keys(id),
+    month CHAR(7) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, month)
+);
+
+-- Chain statistics aggregated per day and per hour by the stats worker
+CREATE TABLE IF NOT EXISTS chain_stats (
+    period VARCHAR(4) NOT NULL CHECK (period IN ('day', 'hour')),
+    bucket TEXT NOT NULL,
+    tx_count BIGINT NOT NULL DEFAULT 0,
+    total_value VARCHAR(78) NOT NULL DEFAULT '0',
+    total_fees VARCHAR(78) NOT NULL DEFAULT '0',
+    fees_burned VARCHAR(78) NOT NULL DEFAULT '0',
+    gas_used BIGINT NOT NULL DEFAULT 0,
+    active_addresses BIGINT NOT NULL DEFAULT 0,
+    new_accounts BIGINT NOT NULL DEFAULT 0,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (period, bucket)
+);
+
+-- Pending transactions seen in the node's mempool, dropped once they are
+-- included, replaced or expire
+CREATE TABLE IF NOT EXISTS pending_transactions (
+    hash VARCHAR(66) PRIMARY KEY,
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42),
+    value VARCHAR(78) NOT NULL,
+    asset VARCHAR(42) NOT NULL DEFAULT 'GYDS',
+    fee VARCHAR(78) NOT NULL,
+    nonce BIGINT NOT NULL,
+    tx_type VARCHAR(20) NOT NULL DEFAULT 'transfer',
+    memo TEXT,
+    first_seen TEXT DEFAULT CURRENT_TIMESTAMP,
+    expires_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_pending_from ON pending_transactions (from_address);
+CREATE INDEX IF NOT EXISTS idx_pending_to ON pending_transactions (to_address);
+CREATE INDEX IF NOT EXISTS idx_pending_expires ON pending_transactions (expires_at);
+
+-- Indexer state table
+CREATE TABLE IF NOT EXISTS indexer_state (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key VARCHAR(100) NOT NULL UNIQUE,
+    value TEXT NOT NULL,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Insert native assets
+INSERT INTO assets (asset_id, symbol, name, decimals, total_supply, creator, is_native, is_stablecoin, created_block)
+VALUES 
+    ('GYDS', 'GYDS', 'GYDS Token', 18, '1000000000000000000000000000', '0x0000000000000000000000000000000000000000', TRUE, FALSE, 0),
+    ('GYD', 'GYD', 'GYD Stablecoin', 18, '0', '0x0000000000000000000000000000000000000000', TRUE, TRUE, 0)
+ON CONFLICT DO NOTHING;
+
+-- Insert initial indexer state
+INSERT INTO indexer_state (key, value)
+VALUES 
+    ('last_indexed_block', '0'),
+    ('indexer_version', '1.0.0')
+ON CONFLICT DO NOTHING;
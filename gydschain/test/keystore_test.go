@@ -0,0 +1,51 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	kp, err := crypto.NewKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := crypto.EncryptKey(kp, "savings", "correct horse", crypto.LightScryptN, crypto.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ks.Address != kp.Address() || ks.Name != "savings" {
+		t.Fatalf("keystore identifies %s/%s", ks.Name, ks.Address)
+	}
+
+	// The file survives JSON and decrypts to the same key
+	data, err := json.Marshal(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(kp.PrivateKeyHex())) {
+		t.Fatal("keystore contains the plaintext key")
+	}
+	var loaded crypto.Keystore
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	unlocked, err := crypto.DecryptKey(&loaded, "correct horse")
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !bytes.Equal(unlocked.PrivateKey, kp.PrivateKey) {
+		t.Fatal("decrypted key differs")
+	}
+
+	if _, err := crypto.DecryptKey(&loaded, "wrong"); err != crypto.ErrKeystorePassword {
+		t.Fatalf("wrong password: got %v", err)
+	}
+	loaded.Address = "gyds1someoneelse"
+	if _, err := crypto.DecryptKey(&loaded, "correct horse"); err != crypto.ErrKeystorePassword {
+		t.Fatalf("edited address: got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/logging"
+)
+
+func TestModuleLoggerFollowsSetup(t *testing.T) {
+	logger := logging.Module("test")
+	defer logging.Setup("info", logging.FormatText, os.Stderr)
+
+	var buf bytes.Buffer
+	if err := logging.Setup("warn", logging.FormatJSON, &buf); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	logger.InfoContext(ctx, "dropped")
+	logger.WarnContext(ctx, "kept", "height", 7)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want 1: %q", len(lines), buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["msg"] != "kept" || record["module"] != "test" || record["request_id"] != "req-1" || record["height"] != float64(7) {
+		t.Errorf("unexpected record: %v", record)
+	}
+
+	if err := logging.Setup("verbose", logging.FormatText, &buf); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := logging.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(logging.RequestIDHeader, "abc")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if seen != "abc" || rr.Header().Get(logging.RequestIDHeader) != "abc" {
+		t.Errorf("caller ID not propagated: saw %q, echoed %q", seen, rr.Header().Get(logging.RequestIDHeader))
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if seen == "" || rr.Header().Get(logging.RequestIDHeader) != seen {
+		t.Errorf("no request ID generated: saw %q, echoed %q", seen, rr.Header().Get(logging.RequestIDHeader))
+	}
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestTransactionMemo(t *testing.T) {
+	config := tx.DefaultMempoolConfig()
+	config.MaxMemoSize = 16
+	mempool := tx.NewMempool(config)
+	defer mempool.Stop()
+
+	newTx := func(memo string) *tx.Transaction {
+		transaction := tx.NewTransfer("gyds1sender", "gyds1exchange", big.NewInt(1000), "GYDS")
+		transaction.SetFee(big.NewInt(1e9))
+		transaction.SetMemo(memo)
+		transaction.Sign([]byte("key"))
+		return transaction
+	}
+
+	// The memo is part of what is hashed and signed
+	plain, _ := newTx("").HashHex()
+	tagged := newTx("104729")
+	taggedHash, _ := tagged.HashHex()
+	if plain == taggedHash {
+		t.Fatal("memo does not change the transaction hash")
+	}
+
+	data, err := json.Marshal(tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded tx.Transaction
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Memo != "104729" {
+		t.Fatalf("memo after round trip = %q", decoded.Memo)
+	}
+
+	if err := mempool.AddTx(newTx(strings.Repeat("x", 17))); err != tx.ErrMemoTooLarge {
+		t.Fatalf("oversized memo: got %v, want %v", err, tx.ErrMemoTooLarge)
+	}
+	if err := mempool.AddTx(tagged); err != nil {
+		t.Fatalf("memo within limit: %v", err)
+	}
+	if got := mempool.GetTx(taggedHash); got == nil || got.Memo != "104729" {
+		t.Fatal("pooled transaction lost its memo")
+	}
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+func TestSignMessage(t *testing.T) {
+	kp, err := crypto.NewKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := crypto.NewKeyPair()
+	message := []byte("I control this address")
+
+	signature, err := crypto.SignMessage(kp, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crypto.VerifyMessage(kp.Address(), message, signature); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	if err := crypto.VerifyMessage(kp.Address(), []byte("I control this address\n"), signature); err != crypto.ErrMessageSignerMismatch {
+		t.Errorf("expected ErrMessageSignerMismatch for altered message, got %v", err)
+	}
+	if err := crypto.VerifyMessage(other.Address(), message, signature); err != crypto.ErrMessageSignerMismatch {
+		t.Errorf("expected ErrMessageSignerMismatch for other address, got %v", err)
+	}
+	if err := crypto.VerifyMessage(kp.Address(), message, "not base64"); err != crypto.ErrInvalidMessageSignature {
+		t.Errorf("expected ErrInvalidMessageSignature, got %v", err)
+	}
+
+	// A message signature must not double as a signature over the raw bytes
+	raw, _ := kp.Sign(message)
+	if crypto.VerifySignature(kp.PublicKey, crypto.MessageHash(message), raw) {
+		t.Error("raw signature verified as a message signature")
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/consensus/pow"
+)
+
+func TestNewMinerBackends(t *testing.T) {
+	if m, err := pow.NewMiner("", pow.BackendConfig{Threads: 2}); err != nil {
+		t.Fatalf("default backend: %v", err)
+	} else if _, ok := m.(*pow.CPUMiner); !ok {
+		t.Errorf("default backend is %T, want *pow.CPUMiner", m)
+	}
+	if m, err := pow.NewMiner("external", pow.BackendConfig{}); err != nil {
+		t.Fatalf("external backend: %v", err)
+	} else if _, ok := m.(*pow.ExternalMiner); !ok {
+		t.Errorf("external backend is %T", m)
+	}
+	if _, err := pow.NewMiner("cuda", pow.BackendConfig{}); !errors.Is(err, pow.ErrUnknownBackend) {
+		t.Errorf("unregistered backend: got %v", err)
+	}
+
+	pow.RegisterBackend("cuda", func(cfg pow.BackendConfig) (pow.Miner, error) {
+		return pow.NewExternalMiner(), nil
+	})
+	if _, err := pow.NewMiner("CUDA", pow.BackendConfig{Devices: []int{0}}); err != nil {
+		t.Errorf("registered backend: %v", err)
+	}
+}
+
+func TestExternalMinerSubmit(t *testing.T) {
+	m := pow.NewExternalMiner()
+	if _, _, err := m.Work(); err != pow.ErrNoWork {
+		t.Fatalf("work before start: got %v", err)
+	}
+
+	// A target of one can't be met
+	m.Start([]byte("header"), big.NewInt(1))
+	if _, err := m.Submit("gpu0", 7); err != pow.ErrInvalidPoW {
+		t.Fatalf("hash above target: got %v", err)
+	}
+
+	results := m.Start([]byte("header"), pow.CalculateTarget(1))
+	result, err := m.Submit("gpu0", 7)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if found := <-results; found == nil || found.Nonce != 7 || found.Hash != result.Hash {
+		t.Errorf("result channel got %+v", found)
+	}
+	if !pow.ValidatePoW([]byte("header"), 7, pow.CalculateTarget(1)) {
+		t.Error("accepted nonce fails ValidatePoW")
+	}
+	if _, err := m.Submit("gpu0", 8); err != pow.ErrStaleWork {
+		t.Errorf("submit after the block was found: got %v", err)
+	}
+
+	m.ReportHashRate("gpu0", 1000, 5000)
+	m.ReportHashRate("gpu1", 500, 2000)
+	stats := m.Stats()
+	if stats.HashRate != 1500 || stats.Workers != 2 || stats.BlocksFound != 1 {
+		t.Errorf("stats: %+v", stats)
+	}
+	if len(stats.Devices) != 2 || stats.Devices[0].ID != "gpu0" || stats.Devices[0].Shares != 1 {
+		t.Errorf("device stats: %+v", stats.Devices)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestMultisigAccount(t *testing.T) {
+	stateDB, submit := newAssetChain(t, "gyds1funder")
+
+	var signers []*crypto.KeyPair
+	var keys []string
+	for i := 0; i < 3; i++ {
+		kp, err := crypto.NewKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, kp)
+		keys = append(keys, kp.PublicKeyHex())
+	}
+
+	// Key order does not change the address
+	multisig := tx.MultisigAddress(keys, 2)
+	if reordered := tx.MultisigAddress([]string{keys[2], keys[0], keys[1]}, 2); reordered != multisig {
+		t.Fatalf("address depends on key order: %s != %s", reordered, multisig)
+	}
+	if _, err := tx.NewCreateMultisig("gyds1funder", keys, 4, big.NewInt(1)); err != tx.ErrInvalidMultisigThreshold {
+		t.Fatalf("unreachable threshold: got %v", err)
+	}
+
+	if err := submit(tx.NewCreateMultisig("gyds1funder", keys, 2, big.NewInt(1e11))); err != nil {
+		t.Fatalf("create multisig: %v", err)
+	}
+	account := stateDB.GetAccount(multisig)
+	if account == nil || account.GetMultisig() == nil || account.GetBalance("GYDS").Cmp(big.NewInt(1e11)) != 0 {
+		t.Fatal("multisig account not created and funded")
+	}
+	if err := submit(tx.NewCreateMultisig("gyds1funder", keys, 2, new(big.Int))); err == nil {
+		t.Fatal("multisig registered twice")
+	}
+
+	// Spending needs two of the three keys; signers sign the same unsigned tx
+	spend := func(signedBy ...int) error {
+		transaction := tx.NewTransfer(multisig, "gyds1payee", big.NewInt(1e10), "GYDS")
+		transaction.SetFee(big.NewInt(1e9))
+		for _, i := range signedBy {
+			sig, err := transaction.SignPartial(signers[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			transaction.AddSignatures(sig)
+		}
+		return submit(transaction, nil)
+	}
+	if err := spend(1); err != tx.ErrMultisigThreshold {
+		t.Fatalf("one signature: got %v, want %v", err, tx.ErrMultisigThreshold)
+	}
+	if err := spend(1, 1); err != tx.ErrMultisigThreshold {
+		t.Fatalf("repeated signer: got %v, want %v", err, tx.ErrMultisigThreshold)
+	}
+	if err := spend(0, 2); err != nil {
+		t.Fatalf("two signatures: %v", err)
+	}
+	if got := stateDB.GetAccount("gyds1payee").GetBalance("GYDS"); got.Cmp(big.NewInt(1e10)) != 0 {
+		t.Fatalf("payee balance = %s", got)
+	}
+}
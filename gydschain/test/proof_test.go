@@ -0,0 +1,133 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/rpc"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestAccountProofs(t *testing.T) {
+	db := state.NewStateDB()
+	for i := 0; i < 50; i++ {
+		account := state.NewAccount(fmt.Sprintf("gyds1acct%d", i))
+		account.SetBalance("GYDS", big.NewInt(int64(1000+i)))
+		db.SetAccount(account.Address, account)
+	}
+	root, err := db.CommitAt(7)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	// Uncommitted changes are not reflected in proofs
+	pending := db.GetAccount("gyds1acct12")
+	pending.SetBalance("GYDS", big.NewInt(1))
+	db.SetAccount(pending.Address, pending)
+
+	proof, err := db.GetProof("gyds1acct12")
+	if err != nil {
+		t.Fatalf("get proof: %v", err)
+	}
+	if proof.Proof.Root != root || proof.Proof.Height != 7 {
+		t.Fatalf("proof against %s at %d, want %s at 7", proof.Proof.Root, proof.Proof.Height, root)
+	}
+	account, err := state.VerifyAccountProof(proof, root)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if account == nil || account.GetBalance("GYDS").Int64() != 1012 {
+		t.Fatalf("expected committed balance 1012, got %v", account)
+	}
+
+	// Absent accounts, including ones sharing a prefix with existing ones
+	for _, addr := range []string{"gyds1acct", "gyds1acct123", "gyds1zzz"} {
+		proof, err := db.GetProof(addr)
+		if err != nil {
+			t.Fatalf("get proof for %s: %v", addr, err)
+		}
+		account, err := state.VerifyAccountProof(proof, root)
+		if err != nil {
+			t.Fatalf("verify absence of %s: %v", addr, err)
+		}
+		if account != nil {
+			t.Errorf("expected %s to be absent", addr)
+		}
+	}
+
+	// A tampered leaf or a different root fails
+	tampered, _ := db.GetProof("gyds1acct3")
+	last := tampered.Proof.Proof[len(tampered.Proof.Proof)-1]
+	last[len(last)-2] ^= 1
+	if _, err := state.VerifyAccountProof(tampered, root); err == nil {
+		t.Error("tampered proof verified")
+	}
+	other, _ := db.GetProof("gyds1acct4")
+	if _, err := state.VerifyAccountProof(other, "00"+root[2:]); err == nil {
+		t.Error("proof verified against the wrong root")
+	}
+
+	// A proof for one account cannot be passed off as another's
+	swapped, _ := db.GetProof("gyds1acct5")
+	swapped.Address = "gyds1acct6"
+	if _, err := state.VerifyAccountProof(swapped, root); err == nil {
+		t.Error("proof verified for a different address")
+	}
+}
+
+func TestTxProofs(t *testing.T) {
+	for _, count := range []int{1, 2, 5, 8} {
+		var txs []*tx.Transaction
+		for i := 0; i < count; i++ {
+			txs = append(txs, &tx.Transaction{
+				Type:   "transfer",
+				From:   "gyds1sender",
+				To:     "gyds1recipient",
+				Amount: big.NewInt(int64(100 + i)),
+				Fee:    big.NewInt(1),
+				Nonce:  uint64(i),
+			})
+		}
+		block := chain.NewBlock("parent", 3, txs, "gyds1validator")
+
+		for i, transaction := range txs {
+			proof, err := block.TxProof(i)
+			if err != nil {
+				t.Fatalf("%d txs: proof %d: %v", count, i, err)
+			}
+			hash, _ := transaction.Hash()
+			if err := chain.VerifyTxProof(hash, i, proof, block.Header.TxRoot); err != nil {
+				t.Fatalf("%d txs: verify %d: %v", count, i, err)
+			}
+			if err := chain.VerifyTxProof(hash, i+1<<uint(len(proof)), proof, block.Header.TxRoot); err != chain.ErrInvalidTxProof {
+				t.Fatalf("%d txs: out of range index accepted", count)
+			}
+		}
+	}
+
+	// The raw transaction survives the RPC round trip and hashes the same
+	transaction := &tx.Transaction{Type: "transfer", From: "gyds1a", To: "gyds1b", Amount: big.NewInt(5), Fee: big.NewInt(1)}
+	other := &tx.Transaction{Type: "transfer", From: "gyds1a", To: "gyds1c", Amount: big.NewInt(6), Fee: big.NewInt(1), Nonce: 1}
+	block := chain.NewBlock("parent", 4, []*tx.Transaction{other, transaction}, "gyds1validator")
+	proof, _ := block.TxProof(1)
+	hash, _ := transaction.HashHex()
+	resp := &rpc.TxProofResponse{Hash: hash, TxIndex: 1, Transaction: transaction, Proof: []string{fmt.Sprintf("%x", proof[0])}}
+	data, _ := json.Marshal(resp)
+	var decoded rpc.TxProofResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if err := decoded.Verify(block.Header.TxRoot); err != nil {
+		t.Fatalf("verify decoded proof: %v", err)
+	}
+
+	// A substituted transaction no longer matches the proven hash
+	decoded.Transaction.Amount = big.NewInt(500)
+	if err := decoded.Verify(block.Header.TxRoot); err != chain.ErrInvalidTxProof {
+		t.Fatalf("tampered transaction verified: %v", err)
+	}
+}
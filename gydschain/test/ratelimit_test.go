@@ -0,0 +1,50 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+func TestRPCRateLimitPerIP(t *testing.T) {
+	server := rpc.NewServer(":0")
+	server.SetRateLimit(1, 2)
+	handler := server.Handler()
+
+	post := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"jsonrpc":"2.0","method":"rpc_errorCodes","id":1}`))
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := post("10.0.0.1:1000"); rr.Code != http.StatusOK {
+			t.Fatalf("request %d within burst: got %d", i, rr.Code)
+		}
+	}
+	rr := post("10.0.0.1:1001")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+		t.Errorf("over limit: got %d, Retry-After %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	if rr := post("10.0.0.2:1000"); rr.Code != http.StatusOK {
+		t.Errorf("other IP limited: got %d", rr.Code)
+	}
+}
+
+func TestRPCBodySizeLimit(t *testing.T) {
+	server := rpc.NewServer(":0")
+	server.SetMaxBodySize(64)
+	handler := server.Handler()
+
+	body := `{"jsonrpc":"2.0","method":"rpc_errorCodes","params":{"pad":"` + strings.Repeat("x", 64) + `"},"id":1}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body: got %d", rr.Code)
+	}
+}
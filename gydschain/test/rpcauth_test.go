@@ -0,0 +1,86 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/rpc"
+)
+
+func TestRPCAuthenticate(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	auth := rpc.NewAuthenticator([]string{"key-1"}, secret, false)
+
+	valid, _ := rpc.SignJWT(map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()}, secret)
+	expired, _ := rpc.SignJWT(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}, secret)
+	forged, _ := rpc.SignJWT(map[string]interface{}{}, []byte("other secret"))
+
+	for _, tc := range []struct {
+		header, value string
+		authed        bool
+		err           error
+	}{
+		{"", "", false, nil},
+		{"X-API-Key", "key-1", true, nil},
+		{"Authorization", "Bearer key-1", true, nil},
+		{"Authorization", "Bearer " + valid, true, nil},
+		{"X-API-Key", "key-2", false, rpc.ErrAuthFailed},
+		{"Authorization", "Basic a2V5LTE=", false, rpc.ErrAuthFailed},
+		{"Authorization", "Bearer " + expired, false, rpc.ErrAuthExpired},
+		{"Authorization", "Bearer " + forged, false, rpc.ErrAuthFailed},
+	} {
+		req := httptest.NewRequest("POST", "/", nil)
+		if tc.header != "" {
+			req.Header.Set(tc.header, tc.value)
+		}
+		authed, err := auth.Authenticate(req)
+		if authed != tc.authed || err != tc.err {
+			t.Errorf("%s %q: got %v, %v; want %v, %v", tc.header, tc.value, authed, err, tc.authed, tc.err)
+		}
+	}
+}
+
+func TestRPCAdminMethodsNeedAuth(t *testing.T) {
+	methods := rpc.NewMethods()
+	methods.RegisterAdmin("admin_ping", func(params json.RawMessage) (interface{}, error) {
+		return "pong", nil
+	})
+
+	if _, err := methods.CallAs("admin_ping", nil, false); err != rpc.ErrAuthRequired {
+		t.Errorf("unauthenticated admin call: got %v", err)
+	}
+	if result, err := methods.CallAs("admin_ping", nil, true); err != nil || result != "pong" {
+		t.Errorf("authenticated admin call: got %v, %v", result, err)
+	}
+	if _, err := methods.CallAs("net_banPeer", json.RawMessage(`{}`), false); err != rpc.ErrAuthRequired {
+		t.Errorf("net_banPeer is not an admin method: %v", err)
+	}
+}
+
+func TestRPCEnabledAPIs(t *testing.T) {
+	methods := rpc.NewMethods()
+	methods.SetEnabledAPIs([]string{"chain", "net"})
+
+	if _, err := methods.Call("account_getBalance", json.RawMessage(`{}`)); err == nil || err.Error() != "method not found: account_getBalance" {
+		t.Errorf("disabled namespace served: %v", err)
+	}
+	if _, err := methods.Call("net_banPeer", json.RawMessage(`{}`)); err == nil || err.Error() != "method not found: net_banPeer" {
+		t.Errorf("admin method served without the admin API: %v", err)
+	}
+	for _, name := range methods.List() {
+		if !strings.HasPrefix(name, "chain_") && !strings.HasPrefix(name, "net_") {
+			t.Errorf("disabled method listed: %s", name)
+		}
+		if name == "net_banPeer" || name == "admin_flushMempool" {
+			t.Errorf("admin method listed: %s", name)
+		}
+	}
+
+	methods.SetEnabledAPIs([]string{"admin"})
+	if _, err := methods.Call("net_banPeer", json.RawMessage(`{}`)); err == nil || err.Error() == "method not found: net_banPeer" {
+		t.Errorf("admin API enabled but net_banPeer hidden: %v", err)
+	}
+}
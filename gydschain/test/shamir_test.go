@@ -0,0 +1,53 @@
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/crypto"
+)
+
+func TestShamirSLIP39Vector(t *testing.T) {
+	share := "duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"
+	secret, err := crypto.CombineShares([]string{share}, "TREZOR")
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if got := hex.EncodeToString(secret); got != "bb54aac4b89dc868ba37d9cc21b2cece" {
+		t.Fatalf("master secret = %s", got)
+	}
+}
+
+func TestShamirMnemonicBackup(t *testing.T) {
+	mnemonic, err := crypto.GenerateMnemonic()
+	if err != nil {
+		t.Fatalf("mnemonic: %v", err)
+	}
+	groups, err := crypto.SplitMnemonic(mnemonic, 2, []crypto.ShareGroup{{Threshold: 2, Count: 3}, {Threshold: 1, Count: 1}, {Threshold: 3, Count: 5}})
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	got, err := crypto.CombineMnemonic([]string{groups[2][4], groups[0][2], groups[2][0], groups[0][1], groups[2][3]})
+	if err != nil || got != mnemonic {
+		t.Fatalf("combine = %s, %v", got, err)
+	}
+	got, err = crypto.CombineMnemonic([]string{groups[1][0], groups[0][0], groups[0][0], groups[0][2]})
+	if err != nil || got != mnemonic {
+		t.Fatalf("combine with duplicate = %s, %v", got, err)
+	}
+
+	if _, err := crypto.CombineMnemonic([]string{groups[1][0], groups[2][0], groups[2][1]}); err != crypto.ErrInsufficientShares {
+		t.Fatalf("expected ErrInsufficientShares, got %v", err)
+	}
+	other, _ := crypto.SplitMnemonic(mnemonic, 1, []crypto.ShareGroup{{Threshold: 1, Count: 1}})
+	if _, err := crypto.CombineMnemonic([]string{groups[1][0], other[0][0]}); err != crypto.ErrShareMismatch {
+		t.Fatalf("expected ErrShareMismatch, got %v", err)
+	}
+
+	corrupt := []byte(groups[1][0])
+	corrupt[0] ^= 'a' ^ 'b'
+	if _, err := crypto.CombineMnemonic([]string{string(corrupt)}); err == nil {
+		t.Fatal("corrupted share accepted")
+	}
+}
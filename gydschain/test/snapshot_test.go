@@ -0,0 +1,94 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/p2p"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestAdminSnapshotDownload(t *testing.T) {
+	genesis := chain.DefaultGenesis()
+	c, err := chain.NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+	for i := 0; i < 2; i++ {
+		if err := c.AddBlock(c.ProposeBlock(mempool, "gyds1validator")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snap, err := c.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// serve publishes an encoded snapshot and returns its descriptor
+	serve := func(snap *chain.ChainSnapshot) *p2p.AdminSnapshot {
+		data, err := snap.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(data)
+		}))
+		t.Cleanup(server.Close)
+		sum := sha256.Sum256(data)
+		return &p2p.AdminSnapshot{
+			Height:    snap.Height,
+			BlockHash: snap.BlockHash,
+			StateRoot: snap.StateRoot,
+			URL:       server.URL,
+			SHA256:    hex.EncodeToString(sum[:]),
+			Size:      int64(len(data)),
+		}
+	}
+
+	info := serve(snap)
+	downloaded, err := info.Download(t.TempDir())
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	restored, err := chain.NewChain(nil, state.NewStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.RestoreSnapshot(genesis, downloaded); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.Height() != 2 {
+		t.Errorf("expected height 2, got %d", restored.Height())
+	}
+
+	// A descriptor the snapshot does not match is refused
+	info.StateRoot = "bogus"
+	if _, err := info.Download(t.TempDir()); err != p2p.ErrSnapshotMismatch {
+		t.Errorf("expected ErrSnapshotMismatch, got %v", err)
+	}
+
+	// So is a snapshot whose state does not hash to its claimed root, even
+	// with a checksum and descriptor to match
+	forged := *snap
+	forged.StateRoot = "bogus"
+	if _, err := serve(&forged).Download(t.TempDir()); err != chain.ErrInvalidSnapshot {
+		t.Errorf("expected ErrInvalidSnapshot, got %v", err)
+	}
+
+	// And a file that does not match its checksum
+	info = serve(snap)
+	info.SHA256 = hex.EncodeToString(make([]byte, 32))
+	if _, err := info.Download(t.TempDir()); err == nil {
+		t.Error("expected a checksum mismatch")
+	}
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/consensus/pos"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+)
+
+func TestStablecoinVaults(t *testing.T) {
+	genesis := chain.DefaultGenesis()
+	genesis.Params.MinTransfer = nil
+	genesis.Alloc = []chain.AllocConfig{
+		{Address: "gyds1feeder", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1owner", GYDSBalance: big.NewInt(1e12), GYDBalance: new(big.Int)},
+		{Address: "gyds1keeper", GYDSBalance: big.NewInt(1e12), GYDBalance: big.NewInt(1e12)},
+	}
+
+	stateDB := state.NewStateDB()
+	c, err := chain.NewChain(nil, stateDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	engine := pos.NewEngine(big.NewInt(1), 10, 5*time.Second)
+	if err := engine.RegisterValidator("gyds1feeder", "", big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	// One-block windows commit each vote in the block that carries it
+	c.SetOracle(pos.NewPriceOracle(engine, nil, 1, []string{chain.CollateralAsset, chain.StablecoinAsset}))
+	c.SetStablecoin(chain.NewStablecoin("USD", genesis.Params.StablecoinReserve, 120))
+
+	mempool := tx.NewMempool(nil)
+	defer mempool.Stop()
+
+	nonces := make(map[string]uint64)
+	prepare := func(transaction *tx.Transaction, err error) *tx.Transaction {
+		if err != nil {
+			t.Fatal(err)
+		}
+		transaction.Nonce = nonces[transaction.From]
+		transaction.Fee = big.NewInt(1e8)
+		transaction.Sign([]byte("key"))
+		nonces[transaction.From]++
+		return transaction
+	}
+	// A rejected block leaves state untouched, so its transaction is
+	// dropped and the nonce reused
+	addBlock := func(transaction *tx.Transaction) error {
+		if err := mempool.AddTx(transaction); err != nil {
+			return err
+		}
+		block := c.ProposeBlock(mempool, "gyds1validator")
+		if err := c.AddBlock(block); err != nil {
+			hash, _ := transaction.HashHex()
+			mempool.RemoveTx(hash)
+			nonces[transaction.From]--
+			return err
+		}
+		mempool.Update(block.Header.Height, block.Transactions)
+		return nil
+	}
+	report := func(asset string, price uint64) {
+		if err := addBlock(prepare(tx.NewOracleUpdate("gyds1feeder", asset, price, c.Height()+1))); err != nil {
+			t.Fatalf("oracle update: %v", err)
+		}
+	}
+
+	// Minting needs a collateral price
+	if err := addBlock(prepare(tx.NewVaultLock("gyds1owner", big.NewInt(3e11), big.NewInt(4e11)))); err != chain.ErrNoPrice {
+		t.Fatalf("expected ErrNoPrice, got %v", err)
+	}
+	if err := addBlock(prepare(tx.NewOracleUpdate("gyds1owner", "GYDS", 2e8, c.Height()+1))); err != pos.ErrNotOracleVoter {
+		t.Fatalf("expected ErrNotOracleVoter, got %v", err)
+	}
+
+	// 3000 GYDS at $2 backs 4000 GYD at exactly 150%
+	report("GYDS", 2e8)
+	if err := addBlock(prepare(tx.NewVaultLock("gyds1owner", big.NewInt(3e11), big.NewInt(4e11)))); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	vault, err := c.Stablecoin().Vault("gyds1owner", c.Height())
+	if err != nil || vault.Ratio != 150 || vault.Debt.Int().Int64() != 4e11 {
+		t.Fatalf("expected a 150%% vault with 4000 GYD debt, got %+v (%v)", vault, err)
+	}
+	if gyd := stateDB.GetAccount("gyds1owner").GetBalance("GYD"); gyd.Int64() != 4e11 {
+		t.Fatalf("expected 4000 GYD minted, got %s", gyd)
+	}
+	if err := addBlock(prepare(tx.NewVaultLock("gyds1owner", new(big.Int), big.NewInt(1)))); err != chain.ErrUndercollateralized {
+		t.Fatalf("expected ErrUndercollateralized, got %v", err)
+	}
+	if err := addBlock(prepare(tx.NewVaultLiquidate("gyds1keeper", "gyds1owner"), nil)); err != chain.ErrVaultSafe {
+		t.Fatalf("expected ErrVaultSafe, got %v", err)
+	}
+
+	// GYD trading 2% under its peg pauses minting
+	report("GYD", 98e6)
+	if status := c.Stablecoin().Status(c.Height()); !status.MintingPaused || status.Deviation != -200 {
+		t.Fatalf("expected minting paused at -200 bps, got %+v", status)
+	}
+	if err := addBlock(prepare(tx.NewVaultLock("gyds1owner", big.NewInt(1e11), big.NewInt(1)))); err != chain.ErrPegBroken {
+		t.Fatalf("expected ErrPegBroken, got %v", err)
+	}
+
+	// At $1.80 the vault is at 135% and the keeper repays its debt for
+	// collateral worth the debt plus 13%
+	report("GYDS", 18e7)
+	if err := addBlock(prepare(tx.NewVaultLiquidate("gyds1keeper", "gyds1owner"), nil)); err != nil {
+		t.Fatalf("liquidate: %v", err)
+	}
+	keeper := stateDB.GetAccount("gyds1keeper")
+	if gyds := keeper.GetBalance("GYDS"); gyds.Int64() != 1e12-1e8+251111111111 {
+		t.Errorf("expected the keeper to seize 251111111111 GYDS, has %s", gyds)
+	}
+	if gyd := keeper.GetBalance("GYD"); gyd.Int64() != 1e12-4e11 {
+		t.Errorf("expected the keeper to repay 4000 GYD, has %s", gyd)
+	}
+	owner := stateDB.GetAccount("gyds1owner")
+	if gyds := owner.GetBalance("GYDS"); gyds.Int64() != 1e12-3e11-1e8+48888888889 {
+		t.Errorf("expected the owner to get 48888888889 GYDS back, has %s", gyds)
+	}
+	if _, err := c.Stablecoin().Vault("gyds1owner", c.Height()); err != chain.ErrVaultNotFound {
+		t.Errorf("expected the vault closed, got %v", err)
+	}
+}
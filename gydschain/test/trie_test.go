@@ -0,0 +1,124 @@
+package test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/gydschain/gydschain/internal/state"
+)
+
+func TestPatriciaTrieOrderIndependent(t *testing.T) {
+	keys := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		keys = append(keys, fmt.Sprintf("key-%d", i*7%200))
+	}
+
+	forward := state.NewPatriciaTrie()
+	for _, k := range keys {
+		forward.Insert([]byte(k), []byte("value-"+k))
+	}
+	backward := state.NewPatriciaTrie()
+	for i := len(keys) - 1; i >= 0; i-- {
+		backward.Insert([]byte(keys[i]), []byte("value-"+keys[i]))
+	}
+	if forward.RootHashHex() != backward.RootHashHex() {
+		t.Fatal("insertion order changed the root")
+	}
+
+	// Inserting and deleting extra keys, including prefixes of existing
+	// ones, returns to the same root
+	for _, k := range []string{"key", "key-1", "key-19x", "other"} {
+		backward.Insert([]byte(k+"!"), []byte("temp"))
+	}
+	if forward.RootHashHex() == backward.RootHashHex() {
+		t.Fatal("extra keys did not change the root")
+	}
+	for _, k := range []string{"key", "key-1", "key-19x", "other"} {
+		if !backward.Delete([]byte(k + "!")) {
+			t.Fatalf("expected %s! to be deleted", k)
+		}
+	}
+	if forward.RootHashHex() != backward.RootHashHex() {
+		t.Fatal("root differs after deleting the extra keys")
+	}
+
+	if got := string(forward.Get([]byte("key-42"))); got != "value-key-42" {
+		t.Errorf("expected value-key-42, got %q", got)
+	}
+	if forward.Get([]byte("key-4")) == nil {
+		t.Error("expected key-4 to be present")
+	}
+	if forward.Get([]byte("key-")) != nil {
+		t.Error("expected no value at a shared prefix")
+	}
+}
+
+func TestStateRootIgnoresLocalTimestamps(t *testing.T) {
+	a := state.NewStateDB()
+	alice := state.NewAccount("gyds1alice")
+	alice.SetBalance("GYDS", big.NewInt(100))
+	a.SetAccount(alice.Address, alice)
+	rootA, err := a.Commit()
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	// Same balances, created at a different time and via a deleted account
+	b := state.NewStateDB()
+	b.SetAccount("gyds1bob", state.NewAccount("gyds1bob"))
+	b.Commit()
+	b.DeleteAccount("gyds1bob")
+	alice2 := state.NewAccount("gyds1alice")
+	alice2.CreatedAt = alice.CreatedAt + 1000
+	alice2.SetBalance("GYDS", big.NewInt(100))
+	b.SetAccount(alice2.Address, alice2)
+	rootB, err := b.Commit()
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if rootA != rootB {
+		t.Errorf("identical state produced different roots: %s != %s", rootA, rootB)
+	}
+
+	alice.SetBalance("GYDS", big.NewInt(101))
+	a.SetAccount(alice.Address, alice)
+	if root, _ := a.Commit(); root == rootA {
+		t.Error("balance change did not change the root")
+	}
+}
+
+func TestStateRootSurvivesExport(t *testing.T) {
+	db := state.NewStateDB()
+	for i := 0; i < 10; i++ {
+		account := state.NewAccount(fmt.Sprintf("gyds1acct%d", i))
+		account.SetBalance("GYDS", big.NewInt(int64(i)))
+		db.SetAccount(account.Address, account)
+	}
+	db.SetAsset("TKN", state.NewFungibleAsset("TKN", "Token", "TKN", 18, "gyds1acct0"))
+	root, err := db.Commit()
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	data, err := db.Export()
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	loaded, err := state.LoadExport(data)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// Touch and restore an account so the loaded state recomputes its root
+	account := loaded.GetAccount("gyds1acct3")
+	loaded.SetAccount(account.Address, account)
+	recomputed, err := loaded.Commit()
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if recomputed != root {
+		t.Errorf("loaded state root %s, want %s", recomputed, root)
+	}
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gydschain/gydschain/internal/chain"
+	"github.com/gydschain/gydschain/internal/state"
+	"github.com/gydschain/gydschain/internal/tx"
+	"github.com/gydschain/gydschain/internal/util"
+)
+
+func TestVestingEnforcement(t *testing.T) {
+	now := time.Now().Unix()
+	genesis := chain.DefaultGenesis()
+	genesis.Alloc = []chain.AllocConfig{{
+		Address:     "gyds1team",
+		GYDSBalance: big.NewInt(1e12),
+		GYDBalance:  new(big.Int),
+		// 1e11 unlocked up front, the other 9e11 about half vested
+		Vesting: &chain.VestingConfig{
+			StartTime:    now - 1000,
+			EndTime:      now + 1000,
+			TotalAmount:  (*util.Big)(big.NewInt(1e12)),
+			VestedAmount: (*util.Big)(big.NewInt(1e11)),
+		},
+	}}
+	stateDB, submit := newGenesisChain(t, genesis)
+
+	status := stateDB.GetAccount("gyds1team").VestingStatus(now)
+	if status == nil {
+		t.Fatal("genesis vesting schedule not applied")
+	}
+	spendable := status.Spendable.Int()
+	if spendable.Cmp(big.NewInt(5e11)) < 0 || spendable.Cmp(big.NewInt(6e11)) > 0 {
+		t.Fatalf("spendable = %s, want about 5.5e11", spendable)
+	}
+
+	err := submit(tx.NewTransfer("gyds1team", "gyds1other", big.NewInt(8e11), "GYDS"), nil)
+	if err != state.ErrVestingLocked {
+		t.Fatalf("transfer of unvested tokens: got %v, want %v", err, state.ErrVestingLocked)
+	}
+	if err := submit(tx.NewTransfer("gyds1team", "gyds1other", big.NewInt(2e11), "GYDS"), nil); err != nil {
+		t.Fatalf("transfer of vested tokens: %v", err)
+	}
+	if got := stateDB.GetAccount("gyds1other").GetBalance("GYDS"); got.Cmp(big.NewInt(2e11)) != 0 {
+		t.Fatalf("recipient balance = %s", got)
+	}
+
+	// Once fully vested the whole remaining balance is spendable
+	remaining := stateDB.GetAccount("gyds1team").Spendable("GYDS", now+1000)
+	if want := stateDB.GetAccount("gyds1team").GetBalance("GYDS"); remaining.Cmp(want) != 0 {
+		t.Fatalf("spendable after end = %s, want %s", remaining, want)
+	}
+}